@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"time"
 
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
@@ -29,6 +30,9 @@ func InitBackendContext(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return fmt.Errorf("open database: %w", err)
 	}
+	dbx.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	dbx.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	dbx.SetConnMaxLifetime(time.Duration(cfg.DB.ConnMaxLifetime) * time.Second)
 
 	ctx = db.WithContext(ctx, dbx)
 	dbstore := database.New(ctx, dbx)
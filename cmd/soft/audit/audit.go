@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"charm.land/lipgloss/v2/table"
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/audit"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+var (
+	username string
+	repo     string
+	limit    int
+	asJSON   bool
+
+	// Command is the audit command.
+	Command = &cobra.Command{
+		Use:                "audit",
+		Short:              "Query the audit log",
+		Long:               "Show the most recent access-control decisions of the audit log, newest first.",
+		Args:               cobra.NoArgs,
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, _ []string) error {
+			ctx := c.Context()
+			be := backend.FromContext(ctx)
+
+			if limit <= 0 {
+				return fmt.Errorf("invalid limit %d", limit)
+			}
+
+			entries, err := be.AuditEntries(ctx, audit.Filter{
+				Username: username,
+				Repo:     repo,
+				Limit:    limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(c.OutOrStdout())
+				for _, e := range entries {
+					if err := enc.Encode(e); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			if len(entries) == 0 {
+				fmt.Fprintln(c.OutOrStdout(), "No entries found")
+				return nil
+			}
+
+			t := table.New().Headers("Time", "User", "Repo", "Service", "Transport", "Access", "Address", "Outcome", "Details")
+			for _, e := range entries {
+				user := e.Username
+				if user == "" {
+					user = "-"
+				}
+				t = t.Row(e.Time.Local().Format(time.DateTime),
+					user,
+					e.Repo,
+					e.Service,
+					e.Transport,
+					e.AccessLevel.String(),
+					e.RemoteAddr,
+					string(e.Outcome),
+					e.Details,
+				)
+			}
+			fmt.Fprintln(c.OutOrStdout(), t)
+			return nil
+		},
+	}
+)
+
+func init() {
+	Command.Flags().StringVarP(&username, "user", "u", "", "only show the entries of a user")
+	Command.Flags().StringVarP(&repo, "repo", "r", "", "only show the entries of a repository")
+	Command.Flags().IntVarP(&limit, "limit", "n", 50, "maximum number of entries to show")
+	Command.Flags().BoolVar(&asJSON, "json", false, "print entries as JSON lines")
+}
@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backup"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	excludeLFS bool
+	excludeDB  bool
+
+	// Command is the backup command.
+	Command = &cobra.Command{
+		Use:                "backup PATH",
+		Short:              "Back up the server to an archive",
+		Long:               "Back up the database, repositories, LFS objects, configuration, and keys of the server to a single archive.",
+		Args:               cobra.ExactArgs(1),
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, args []string) (rerr error) {
+			ctx := c.Context()
+			cfg := config.FromContext(ctx)
+			dbx := db.FromContext(ctx)
+
+			f, err := os.OpenFile(args[0], os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+			if err != nil {
+				return fmt.Errorf("create backup: %w", err)
+			}
+			defer func() {
+				if err := f.Close(); err != nil && rerr == nil {
+					rerr = fmt.Errorf("create backup: %w", err)
+				}
+				if rerr != nil {
+					os.Remove(args[0]) //nolint: errcheck
+				}
+			}()
+
+			m, err := backup.Backup(ctx, cfg, dbx, f, backup.Options{
+				ExcludeLFS: excludeLFS,
+				ExcludeDB:  excludeDB,
+			})
+			if errors.Is(err, backup.ErrUnsupportedDatabase) {
+				return fmt.Errorf("backup: %w, or leave it out with --exclude-db", err)
+			}
+			if err != nil {
+				return fmt.Errorf("backup: %w", err)
+			}
+
+			fmt.Fprintf(c.OutOrStdout(), "Backed up %d repositories to %s\n", len(m.Repos), args[0])
+			return nil
+		},
+	}
+)
+
+func init() {
+	Command.Flags().BoolVar(&excludeLFS, "exclude-lfs", false, "leave LFS objects out of the backup")
+	Command.Flags().BoolVar(&excludeDB, "exclude-db", false, "leave the database out of the backup")
+}
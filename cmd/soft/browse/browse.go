@@ -256,6 +256,16 @@ func (repository) IsHidden() bool {
 	return false
 }
 
+// IsArchived implements proto.Repository.
+func (repository) IsArchived() bool {
+	return false
+}
+
+// IsTemplate implements proto.Repository.
+func (repository) IsTemplate() bool {
+	return false
+}
+
 // IsMirror implements proto.Repository.
 func (repository) IsMirror() bool {
 	return false
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Command is the config command.
+	Command = &cobra.Command{
+		Use:   "config",
+		Short: "Manage the server configuration",
+	}
+
+	// ValidateCommand is the config validate command. It runs even if the
+	// configuration of the server is invalid.
+	ValidateCommand = &cobra.Command{
+		Use:   "validate [PATH]",
+		Short: "Validate a configuration file",
+		Long:  "Validate a configuration file without starting the server, the configuration of the server when no path is given. Environment variables are applied like they are when the server starts. Listen addresses, referenced files, SSH algorithms, conflicting options, and database connectivity are checked, and deprecated and unknown keys are reported as warnings. Exits with a non-zero status if there are errors.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			path := config.DefaultConfig().ConfigPath()
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			cfg, issues := config.Check(path)
+			var errs, warnings int
+			for _, i := range issues {
+				if i.Warning {
+					warnings++
+					fmt.Fprintf(c.OutOrStdout(), "warning: %s\n", i)
+				} else {
+					errs++
+					fmt.Fprintf(c.OutOrStdout(), "error: %s\n", i)
+				}
+			}
+
+			// Only connect to a database that's valid and configured.
+			if errs == 0 {
+				if err := checkDB(c.Context(), cfg); err != nil {
+					errs++
+					fmt.Fprintf(c.OutOrStdout(), "error: db: %v\n", err)
+				}
+			}
+
+			fmt.Fprintf(c.OutOrStdout(), "%s: %d error(s), %d warning(s)\n", path, errs, warnings)
+			if errs > 0 {
+				return fmt.Errorf("invalid configuration %s", path)
+			}
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	Command.AddCommand(ValidateCommand)
+}
+
+// checkDB checks that the database of cfg answers. SQLite databases that
+// don't exist yet are created by the server, they aren't opened to leave
+// the data path untouched.
+func checkDB(ctx context.Context, cfg *config.Config) error {
+	if strings.HasPrefix(cfg.DB.Driver, "sqlite") {
+		path, _, _ := strings.Cut(cfg.DB.DataSource, "?")
+		path = strings.TrimPrefix(path, "file:")
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	dbx, err := db.Open(ctx, cfg.DB.Driver, cfg.DB.DataSource)
+	if err != nil {
+		return err
+	}
+	defer dbx.Close() //nolint: errcheck
+
+	return dbx.PingContext(ctx)
+}
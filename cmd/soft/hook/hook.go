@@ -11,12 +11,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"charm.land/log/v2"
 	"github.com/charmbracelet/soft-serve/cmd"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -72,7 +74,6 @@ var (
 		stderr := cmd.ErrOrStderr()
 
 		cmdName := cmd.Name()
-		customHookPath := filepath.Join(cfg.DataPath, "hooks", cmdName)
 
 		var buf bytes.Buffer
 		opts := make([]hooks.HookArg, 0)
@@ -95,9 +96,18 @@ var (
 				})
 			}
 
+			// git only passes push options to the pre-receive and
+			// post-receive hooks. Custom hooks get them unchanged in
+			// their environment.
+			ctx = hooks.WithPushOptions(ctx, hooks.PushOptionsFromEnv())
+
 			switch cmdName {
 			case hooks.PreReceiveHook:
-				hks.PreReceive(ctx, stdout, stderr, repoName, opts)
+				// The error is printed to stderr, which git relays to the
+				// client, and the non-zero exit status rejects the push.
+				if err := hks.PreReceive(ctx, stdout, stderr, repoName, opts); err != nil {
+					return err
+				}
 			case hooks.PostReceiveHook:
 				hks.PostReceive(ctx, stdout, stderr, repoName, opts)
 			}
@@ -116,11 +126,17 @@ var (
 			hks.PostUpdate(ctx, stdout, stderr, repoName, args...)
 		}
 
-		// Custom hooks
-		if stat, err := os.Stat(customHookPath); err == nil && !stat.IsDir() && stat.Mode()&0o111 != 0 {
-			// If the custom hook is executable, run it
-			if err := runCommand(ctx, &buf, stdout, stderr, customHookPath, args...); err != nil {
-				logger.Error("failed to run custom hook", "err", err)
+		// Custom hooks, the global one then the repository one.
+		env := hookEnv(ctx, cfg, repoName)
+		for _, customHookPath := range []string{
+			filepath.Join(cfg.DataPath, "hooks", cmdName),
+			filepath.Join(cfg.DataPath, "repos", utils.SanitizeRepo(repoName)+".git", "custom_hooks", cmdName),
+		} {
+			if stat, err := os.Stat(customHookPath); err == nil && !stat.IsDir() && stat.Mode()&0o111 != 0 {
+				// If the custom hook is executable, run it
+				if err := runCommand(ctx, cfg, bytes.NewReader(buf.Bytes()), stdout, stderr, env, customHookPath, args...); err != nil {
+					logger.Error("failed to run custom hook", "hook", customHookPath, "err", err)
+				}
 			}
 		}
 
@@ -163,10 +179,87 @@ func init() {
 	)
 }
 
-func runCommand(ctx context.Context, in io.Reader, out io.Writer, err io.Writer, name string, args ...string) error {
+// defaultHookTimeout is how long a custom hook may run when no timeout is
+// configured.
+const defaultHookTimeout = time.Minute
+
+// hookEnvNames are the environment variables passed to custom hooks, along
+// with the GIT_ ones and the configured ones.
+var hookEnvNames = []string{
+	"HOME",
+	"LANG",
+	"PATH",
+	"TMPDIR",
+	"TZ",
+	"SOFT_SERVE_PUBLIC_KEY",
+	"SOFT_SERVE_REPO_NAME",
+	"SOFT_SERVE_USERNAME",
+}
+
+// hookEnv returns the environment of custom hooks. It's restricted to a few
+// variables so hooks don't see the server secrets, such as the database
+// data source. SOFT_SERVE_PUSHER is set to the username of the pusher, and the
+// environment variables of the repository come last.
+func hookEnv(ctx context.Context, cfg *config.Config, repo string) []string {
+	names := make(map[string]bool, len(hookEnvNames)+len(cfg.Hooks.Env))
+	for _, n := range hookEnvNames {
+		names[n] = true
+	}
+	for _, n := range cfg.Hooks.Env {
+		names[n] = true
+	}
+
+	var env []string
+	for _, kv := range os.Environ() {
+		k, _, _ := strings.Cut(kv, "=")
+		if names[k] || strings.HasPrefix(k, "GIT_") {
+			env = append(env, kv)
+		}
+	}
+
+	be := backend.FromContext(ctx)
+	if user, err := be.HookUser(ctx); err == nil {
+		env = append(env, "SOFT_SERVE_PUSHER="+user.Username())
+	} else {
+		log.FromContext(ctx).Debug("no pusher for custom hooks", "repo", repo, "err", err)
+	}
+
+	vars, err := be.HookEnv(ctx, repo)
+	if err != nil {
+		log.FromContext(ctx).Error("failed to get hook environment variables", "repo", repo, "err", err)
+	}
+	for _, v := range vars {
+		env = append(env, v.Name+"="+v.Value)
+	}
+
+	return env
+}
+
+// runCommand runs a custom hook, killing it when it takes longer than the
+// configured timeout. Its output goes to the pusher.
+func runCommand(ctx context.Context, cfg *config.Config, in io.Reader, out io.Writer, errOut io.Writer, env []string, name string, args ...string) error {
+	timeout := time.Duration(cfg.Hooks.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Stdin = in
 	cmd.Stdout = out
-	cmd.Stderr = err
-	return cmd.Run()
+	cmd.Stderr = errOut
+	cmd.Env = env
+	// Don't wait for processes the hook left behind holding the output.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("hook %s timed out after %s: %w", filepath.Base(name), timeout, err)
+			fmt.Fprintln(errOut, err) //nolint:errcheck
+		}
+		return err
+	}
+
+	return nil
 }
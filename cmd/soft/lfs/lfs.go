@@ -0,0 +1,126 @@
+package lfs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Command is the lfs command.
+	Command = &cobra.Command{
+		Use:   "lfs",
+		Short: "Manage Git LFS objects",
+	}
+
+	gcOpts backend.LFSGCOptions
+
+	gcCmd = &cobra.Command{
+		Use:                "gc [REPOSITORY...]",
+		Short:              "Delete LFS objects that aren't referenced by any ref",
+		Long:               "Delete LFS objects that aren't referenced by any ref of their repository. Objects that might belong to a push in progress are kept.",
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, args []string) error {
+			ctx := c.Context()
+			be := backend.FromContext(ctx)
+			repos, err := repositories(ctx, be, args)
+			if err != nil {
+				return err
+			}
+
+			verb := "deleted"
+			if gcOpts.DryRun {
+				verb = "would delete"
+			}
+
+			var total backend.LFSGCStats
+			for _, r := range repos {
+				stats, err := be.GarbageCollectLFS(ctx, r, gcOpts)
+				if err != nil {
+					return fmt.Errorf("%s: %w", r.Name(), err)
+				}
+				if stats.Objects == 0 {
+					continue
+				}
+
+				total.Objects += stats.Objects
+				total.Bytes += stats.Bytes
+				fmt.Fprintf(c.OutOrStdout(), "%s: %s %d objects (%s)\n", r.Name(), verb, stats.Objects, humanize.IBytes(uint64(stats.Bytes))) //nolint:gosec
+			}
+
+			fmt.Fprintf(c.OutOrStdout(), "%s %d objects (%s) in total\n", verb, total.Objects, humanize.IBytes(uint64(total.Bytes))) //nolint:gosec
+			return nil
+		},
+	}
+
+	verifyOpts backend.LFSVerifyOptions
+
+	verifyCmd = &cobra.Command{
+		Use:                "verify [REPOSITORY...]",
+		Short:              "Verify that stored LFS objects match their OID",
+		Long:               "Verify that stored LFS objects exist and match their OID and size. Use --repair to delete the failed objects so clients can upload them again.",
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, args []string) error {
+			ctx := c.Context()
+			be := backend.FromContext(ctx)
+			repos, err := repositories(ctx, be, args)
+			if err != nil {
+				return err
+			}
+
+			var total int
+			for _, r := range repos {
+				failed, err := be.VerifyLFSObjects(ctx, r, verifyOpts)
+				if err != nil {
+					return fmt.Errorf("%s: %w", r.Name(), err)
+				}
+
+				total += len(failed)
+				for _, f := range failed {
+					fmt.Fprintf(c.OutOrStdout(), "%s: %s: %v\n", r.Name(), f.Oid, f.Err)
+				}
+			}
+
+			if total > 0 && !verifyOpts.Repair {
+				return fmt.Errorf("%d objects failed verification", total)
+			}
+
+			return nil
+		},
+	}
+)
+
+// repositories returns the named repositories, or all of them if names is
+// empty.
+func repositories(ctx context.Context, be *backend.Backend, names []string) ([]proto.Repository, error) {
+	if len(names) == 0 {
+		return be.Repositories(ctx)
+	}
+
+	repos := make([]proto.Repository, 0, len(names))
+	for _, name := range names {
+		r, err := be.Repository(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		repos = append(repos, r)
+	}
+
+	return repos, nil
+}
+
+func init() {
+	gcCmd.Flags().BoolVarP(&gcOpts.DryRun, "dry-run", "n", false, "report unreferenced objects without deleting them")
+	gcCmd.Flags().DurationVar(&gcOpts.GracePeriod, "grace-period", backend.DefaultLFSGCGracePeriod, "keep objects created within this duration")
+
+	verifyCmd.Flags().BoolVar(&verifyOpts.Repair, "repair", false, "delete objects that failed verification")
+
+	Command.AddCommand(gcCmd, verifyCmd)
+}
@@ -10,9 +10,15 @@ import (
 	"charm.land/log/v2"
 	"github.com/charmbracelet/colorprofile"
 	"github.com/charmbracelet/soft-serve/cmd/soft/admin"
+	"github.com/charmbracelet/soft-serve/cmd/soft/audit"
+	"github.com/charmbracelet/soft-serve/cmd/soft/backup"
 	"github.com/charmbracelet/soft-serve/cmd/soft/browse"
+	configcmd "github.com/charmbracelet/soft-serve/cmd/soft/config"
 	"github.com/charmbracelet/soft-serve/cmd/soft/hook"
+	"github.com/charmbracelet/soft-serve/cmd/soft/lfs"
+	"github.com/charmbracelet/soft-serve/cmd/soft/restore"
 	"github.com/charmbracelet/soft-serve/cmd/soft/serve"
+	"github.com/charmbracelet/soft-serve/cmd/soft/user"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
@@ -75,7 +81,13 @@ func init() {
 		serve.Command,
 		hook.Command,
 		admin.Command,
+		audit.Command,
+		backup.Command,
+		restore.Command,
+		lfs.Command,
 		browse.Command,
+		user.Command,
+		configcmd.Command,
 	)
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
 
@@ -100,16 +112,13 @@ func init() {
 func main() {
 	ctx := context.Background()
 	cfg := config.DefaultConfig()
-	if cfg.Exist() {
-		if err := cfg.Parse(); err != nil {
+	if err := parseConfig(cfg); err != nil {
+		// Let the config validate command report what's wrong.
+		if c, _, _ := rootCmd.Find(os.Args[1:]); c != configcmd.ValidateCommand {
 			log.Fatal(err)
 		}
 	}
 
-	if err := cfg.ParseEnv(); err != nil {
-		log.Fatal(err)
-	}
-
 	ctx = config.WithContext(ctx, cfg)
 	logger, f, err := logr.NewLogger(cfg)
 	if err != nil {
@@ -139,3 +148,15 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// parseConfig parses the config file, if it exists, and the environment
+// variables.
+func parseConfig(cfg *config.Config) error {
+	if cfg.Exist() {
+		if err := cfg.Parse(); err != nil {
+			return err
+		}
+	}
+
+	return cfg.ParseEnv()
+}
@@ -0,0 +1,55 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/backup"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/migrate"
+	"github.com/spf13/cobra"
+)
+
+// Command is the restore command.
+var Command = &cobra.Command{
+	Use:   "restore PATH",
+	Short: "Restore the server from a backup",
+	Long:  "Restore the server from a backup made with the backup command. The server must be fresh, without a database or repositories.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(c *cobra.Command, args []string) error {
+		ctx := c.Context()
+		cfg := config.FromContext(ctx)
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("open backup: %w", err)
+		}
+		defer f.Close() //nolint: errcheck
+
+		m, err := backup.Restore(ctx, cfg, f)
+		if err != nil {
+			return fmt.Errorf("restore: %w", err)
+		}
+
+		// The backup may come from an older version and its hooks point at
+		// the server that made it.
+		if err := cmd.InitBackendContext(c, args); err != nil {
+			return err
+		}
+		defer cmd.CloseDBContext(c, args) //nolint: errcheck
+
+		ctx = c.Context()
+		if err := migrate.Migrate(ctx, db.FromContext(ctx)); err != nil {
+			return fmt.Errorf("migration: %w", err)
+		}
+		if err := cmd.InitializeHooks(ctx, cfg, backend.FromContext(ctx)); err != nil {
+			return fmt.Errorf("initialize hooks: %w", err)
+		}
+
+		fmt.Fprintf(c.OutOrStdout(), "Restored %d repositories from %s\n", len(m.Repos), args[0])
+		return nil
+	},
+}
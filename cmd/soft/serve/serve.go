@@ -2,12 +2,14 @@ package serve
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -17,6 +19,7 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/migrate"
+	"github.com/charmbracelet/soft-serve/pkg/git"
 	"github.com/spf13/cobra"
 )
 
@@ -115,7 +118,12 @@ var (
 					}
 				case sig := <-done:
 					if sig == syscall.SIGHUP {
-						s.logger.Info("received SIGHUP signal, reloading TLS certificates if enabled")
+						s.logger.Info("received SIGHUP signal, reloading the configuration and TLS certificates if enabled")
+						if restart, err := s.ReloadConfig(); err != nil {
+							s.logger.Error("failed to reload the configuration", "err", err)
+						} else if len(restart) > 0 {
+							s.logger.Warn("changed settings require a restart", "keys", strings.Join(restart, ", "))
+						}
 						if err := s.ReloadCertificates(); err != nil {
 							s.logger.Error("failed to reload TLS certificates", "err", err)
 						}
@@ -126,10 +134,19 @@ var (
 				break
 			}
 
-			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			// Stop accepting connections and let the running git operations
+			// finish before shutting down.
+			timeout := time.Duration(cfg.DrainTimeout) * time.Second
+			s.logger.Info("draining server", "git_operations", git.RunningServices(), "timeout", timeout)
+			ctx, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
 			if err := s.Shutdown(ctx); err != nil {
-				return err
+				if !errors.Is(err, context.DeadlineExceeded) {
+					return err
+				}
+
+				s.logger.Warn("drain timeout exceeded, closing remaining connections", "git_operations", git.RunningServices())
+				return s.Close()
 			}
 
 			return nil
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"charm.land/log/v2"
 
@@ -14,10 +15,13 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/cron"
 	"github.com/charmbracelet/soft-serve/pkg/daemon"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/git"
 	"github.com/charmbracelet/soft-serve/pkg/jobs"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	sshsrv "github.com/charmbracelet/soft-serve/pkg/ssh"
 	"github.com/charmbracelet/soft-serve/pkg/stats"
 	"github.com/charmbracelet/soft-serve/pkg/web"
+	"github.com/charmbracelet/soft-serve/pkg/webhook"
 	"github.com/charmbracelet/ssh"
 	"golang.org/x/sync/errgroup"
 )
@@ -30,10 +34,14 @@ type Server struct {
 	StatsServer *stats.StatsServer
 	CertLoader  *CertReloader
 	Cron        *cron.Scheduler
+	Webhooks    *webhook.Dispatcher
 	Config      *config.Config
 	Backend     *backend.Backend
 	DB          *db.DB
 
+	// cfg is the configuration with the settings applied by reloads.
+	cfg *config.Config
+
 	logger *log.Logger
 	ctx    context.Context
 }
@@ -52,14 +60,24 @@ func NewServer(ctx context.Context) (*Server, error) {
 		Config:  cfg,
 		Backend: be,
 		DB:      db,
+		cfg:     cfg,
 		logger:  log.FromContext(ctx).WithPrefix("server"),
 		ctx:     ctx,
 	}
 
+	if err := git.SetGitBinary(cfg.Git.BinaryPath); err != nil {
+		return nil, fmt.Errorf("set git binary: %w", err)
+	}
+
 	// Add cron jobs.
 	sched := cron.NewScheduler(ctx)
 	for n, j := range jobs.List() {
-		id, err := sched.AddFunc(j.Runner.Spec(ctx), j.Runner.Func(ctx))
+		spec := j.Runner.Spec(ctx)
+		if spec == "" {
+			continue
+		}
+
+		id, err := sched.AddFunc(spec, jobs.Windowed(ctx, n, j.Runner.Func(ctx)))
 		if err != nil {
 			logger.Warn("error adding cron job", "job", n, "err", err)
 		}
@@ -67,7 +85,14 @@ func NewServer(ctx context.Context) (*Server, error) {
 		j.ID = id
 	}
 
+	for _, n := range cfg.Jobs.MaintenanceJobs {
+		if _, ok := jobs.List()[n]; !ok {
+			logger.Warn("unknown maintenance job", "job", n)
+		}
+	}
+
 	srv.Cron = sched
+	srv.Webhooks = webhook.NewDispatcher(ctx)
 
 	srv.SSHServer, err = sshsrv.NewSSHServer(ctx)
 	if err != nil {
@@ -111,9 +136,40 @@ func (s *Server) ReloadCertificates() error {
 	return s.CertLoader.Reload()
 }
 
+// ReloadConfig reads the configuration again and applies the settings that
+// can change while the server runs, see [config.Config.Reload], without
+// restarting the listeners. Nothing is applied if the configuration is
+// invalid. It returns the keys of the changed settings that need a restart.
+func (s *Server) ReloadConfig() ([]string, error) {
+	cfg := config.DefaultConfig()
+	if cfg.Exist() {
+		if err := cfg.ParseFile(); err != nil {
+			return nil, fmt.Errorf("parse config file: %w", err)
+		}
+	}
+	if err := cfg.ParseEnv(); err != nil {
+		return nil, fmt.Errorf("parse environment variables: %w", err)
+	}
+
+	live, restart := s.cfg.Reload(cfg)
+	s.SSHServer.Reload(live)
+	s.GitDaemon.Reload(live)
+	s.Webhooks.Reload(live)
+	logr.SetLevels(logr.ConfigLevels(live))
+	s.cfg = live
+
+	return restart, nil
+}
+
 // Start starts the SSH server.
 func (s *Server) Start() error {
 	errg, _ := errgroup.WithContext(s.ctx)
+	s.logger.Print("Using database connection pool",
+		"driver", s.Config.DB.Driver,
+		"max_open_conns", s.DB.Stats().MaxOpenConnections,
+		"max_idle_conns", s.Config.DB.MaxIdleConns,
+		"conn_max_lifetime", time.Duration(s.Config.DB.ConnMaxLifetime)*time.Second,
+	)
 
 	// optionally start the SSH server
 	if s.Config.SSH.Enabled {
@@ -163,6 +219,10 @@ func (s *Server) Start() error {
 		s.Cron.Start()
 		return nil
 	})
+	errg.Go(func() error {
+		s.Webhooks.Start()
+		return nil
+	})
 	return errg.Wait()
 }
 
@@ -188,6 +248,10 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.Cron.Stop()
 		return nil
 	})
+	errg.Go(func() error {
+		s.Webhooks.Stop()
+		return nil
+	})
 	// defer s.DB.Close() // nolint: errcheck
 	return errg.Wait()
 }
@@ -203,6 +267,10 @@ func (s *Server) Close() error {
 		s.Cron.Stop()
 		return nil
 	})
+	errg.Go(func() error {
+		s.Webhooks.Stop()
+		return nil
+	})
 	// defer s.DB.Close() // nolint: errcheck
 	return errg.Wait()
 }
@@ -0,0 +1,101 @@
+package user
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// importUser is a user of an import file.
+type importUser struct {
+	Username   string   `yaml:"username"`
+	Email      string   `yaml:"email"`
+	Admin      *bool    `yaml:"admin"`
+	PublicKeys []string `yaml:"public_keys"`
+}
+
+var (
+	dryRun bool
+
+	// Command is the user command.
+	Command = &cobra.Command{
+		Use:   "user",
+		Short: "Manage users",
+	}
+
+	importCmd = &cobra.Command{
+		Use:   "import FILE",
+		Short: "Import users and their public keys",
+		Long: `Create or update the users listed in a YAML or JSON file. Existing users get
+the email, admin flag, and keys of the file; keys they already have are kept.
+Importing the same file again changes nothing.
+
+  - username: alice
+    email: alice@example.com
+    admin: true
+    public_keys:
+      - ssh-ed25519 AAAA...`,
+		Args:               cobra.ExactArgs(1),
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, args []string) error {
+			ctx := c.Context()
+			be := backend.FromContext(ctx)
+
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			// JSON is valid YAML.
+			var users []importUser
+			if err := yaml.Unmarshal(data, &users); err != nil {
+				return fmt.Errorf("parse %s: %w", args[0], err)
+			}
+
+			imports := make([]backend.UserImport, len(users))
+			for i, u := range users {
+				imports[i] = backend.UserImport{
+					Username:   u.Username,
+					Email:      u.Email,
+					Admin:      u.Admin,
+					PublicKeys: u.PublicKeys,
+				}
+			}
+
+			results, err := be.ImportUsers(ctx, imports, dryRun)
+			if err != nil {
+				return err
+			}
+
+			counts := make(map[backend.UserImportAction]int)
+			out := c.OutOrStdout()
+			for _, r := range results {
+				counts[r.Action]++
+				if len(r.Notes) > 0 {
+					fmt.Fprintf(out, "%s %s: %s\n", r.Action, r.Username, strings.Join(r.Notes, ", "))
+				} else {
+					fmt.Fprintf(out, "%s %s\n", r.Action, r.Username)
+				}
+			}
+
+			summary := fmt.Sprintf("%d created, %d updated, %d skipped",
+				counts[backend.UserCreated], counts[backend.UserUpdated], counts[backend.UserSkipped])
+			if dryRun {
+				summary += " (dry run)"
+			}
+			fmt.Fprintln(out, summary)
+			return nil
+		},
+	}
+)
+
+func init() {
+	importCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would change without importing")
+	Command.AddCommand(importCmd)
+}
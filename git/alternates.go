@@ -0,0 +1,180 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// objectsDir returns the absolute path of the objects directory of the
+// repository.
+func (r *Repository) objectsDir() (string, error) {
+	return filepath.Abs(filepath.Join(r.Path, "objects"))
+}
+
+// alternatesPath returns the path of the alternates file of the repository.
+func (r *Repository) alternatesPath() string {
+	return filepath.Join(r.Path, "objects", "info", "alternates")
+}
+
+// Alternates returns the object directories the repository borrows objects
+// from, as absolute paths. Repositories that don't borrow objects have none.
+func (r *Repository) Alternates() ([]string, error) {
+	b, err := os.ReadFile(r.alternatesPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := r.objectsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		d := strings.TrimSpace(scanner.Text())
+		if d == "" || strings.HasPrefix(d, "#") {
+			continue
+		}
+		if !filepath.IsAbs(d) {
+			d = filepath.Join(objs, d)
+		}
+		dirs = append(dirs, filepath.Clean(d))
+	}
+
+	return dirs, scanner.Err()
+}
+
+// SetAlternates makes the repository borrow the objects of the object
+// directories dirs. They're written relative to the objects directory of the
+// repository, so that moving the data directory doesn't break them. No
+// directories removes the alternates.
+func (r *Repository) SetAlternates(dirs ...string) error {
+	if len(dirs) == 0 {
+		err := os.Remove(r.alternatesPath())
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	objs, err := r.objectsDir()
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, d := range dirs {
+		d, err := filepath.Abs(d)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(objs, d)
+		if err != nil {
+			return err
+		}
+		b.WriteString(filepath.ToSlash(rel) + "\n")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.alternatesPath()), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.alternatesPath(), []byte(b.String()), 0o644) //nolint:gosec
+}
+
+// Fork makes the repository a fork of parent, killing git after timeout. It
+// borrows the objects of parent through its alternates, and gets the
+// branches, the tags, and HEAD of parent, without copying any object. The
+// parent must keep its unreachable objects from then on, see
+// [Repository.SetKeepUnreachable].
+func (r *Repository) Fork(parent *Repository, timeout time.Duration) error {
+	objs, err := parent.objectsDir()
+	if err != nil {
+		return err
+	}
+	if err := r.SetAlternates(objs); err != nil {
+		return err
+	}
+
+	// The objects of the refs are found in the alternates, so git fetch
+	// only updates the refs.
+	src, err := filepath.Abs(parent.Path)
+	if err != nil {
+		return err
+	}
+	if _, err := NewCommand("fetch", "--quiet", "--no-tags", src,
+		"+"+RefsHeads+"*:"+RefsHeads+"*",
+		"+"+RefsTags+"*:"+RefsTags+"*",
+	).RunInDirWithTimeout(timeout, r.Path); err != nil {
+		return err
+	}
+
+	head, err := parent.SymbolicRef(HEAD, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.SymbolicRef(HEAD, head)
+	return err
+}
+
+// Dissociate copies the objects the repository borrows from its alternates
+// and removes them, so that it no longer depends on the repository it was
+// forked from. It kills git repack after timeout.
+func (r *Repository) Dissociate(timeout time.Duration) error {
+	dirs, err := r.Alternates()
+	if err != nil || len(dirs) == 0 {
+		return err
+	}
+
+	// Without --local, the objects of the alternates are packed too.
+	if _, err := NewCommand("repack", "-a", "-d", "-q").RunInDirWithTimeout(timeout, r.Path); err != nil {
+		return err
+	}
+
+	return r.SetAlternates()
+}
+
+// KeepsUnreachable returns whether the unreachable objects of the repository
+// are kept, see [Repository.SetKeepUnreachable].
+func (r *Repository) KeepsUnreachable() (bool, error) {
+	out, err := NewCommand("config", "--get", "gc.pruneExpire").RunInDir(r.Path)
+	if err != nil {
+		// The option isn't set.
+		return false, nil //nolint:nilerr
+	}
+
+	return strings.TrimSpace(string(out)) == "never", nil
+}
+
+// SetKeepUnreachable sets whether git gc, including the automatic one after
+// pushes, keeps the unreachable objects of the repository instead of pruning
+// them, which forks borrowing its objects may still need. They're kept in a
+// cruft pack.
+func (r *Repository) SetKeepUnreachable(keep bool) error {
+	if keep {
+		for _, kv := range [][2]string{{"gc.pruneExpire", "never"}, {"gc.cruftPacks", "true"}} {
+			if _, err := NewCommand("config", kv[0], kv[1]).RunInDir(r.Path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, k := range []string{"gc.pruneExpire", "gc.cruftPacks"} {
+		// git config fails when the option isn't set.
+		NewCommand("config", "--unset", k).RunInDir(r.Path) //nolint:errcheck
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestFork(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	parent, err := Init(filepath.Join(dir, "parent"), true)
+	is.NoErr(err)
+
+	run := func(r *Repository, args ...string) string {
+		t.Helper()
+		out, err := NewCommand("-c", "user.name=test", "-c", "user.email=test@example.com").
+			AddArgs(args...).RunInDir(r.Path)
+		is.NoErr(err) // git args
+		return strings.TrimSpace(string(out))
+	}
+	tree := run(parent, "mktree")
+	first := run(parent, "commit-tree", tree, "-m", "first")
+	second := run(parent, "commit-tree", tree, "-p", first, "-m", "second")
+	run(parent, "update-ref", "refs/heads/master", first)
+	run(parent, "update-ref", "refs/heads/feature", second)
+	run(parent, "tag", "v1", first)
+
+	fork, err := Init(filepath.Join(dir, "forks", "fork"), true)
+	is.NoErr(err)
+	is.NoErr(fork.Fork(parent, time.Minute))
+
+	// The fork borrows the objects of its parent, with a relative path.
+	alts, err := fork.Alternates()
+	is.NoErr(err)
+	objs, _ := filepath.Abs(filepath.Join(parent.Path, "objects"))
+	is.Equal(alts, []string{objs})
+	b, err := os.ReadFile(filepath.Join(fork.Path, "objects", "info", "alternates"))
+	is.NoErr(err)
+	is.Equal(string(b), "../../../parent.git/objects\n")
+	c, err := fork.CountObjects()
+	is.NoErr(err)
+	is.Equal(c.Loose+c.Packed, int64(0)) // no objects copied
+	is.Equal(run(fork, "rev-parse", "feature", "v1"), second+"\n"+first)
+	head, err := fork.SymbolicRef(HEAD, "")
+	is.NoErr(err)
+	is.Equal(head, "refs/heads/master")
+
+	// Unreachable objects of the parent are kept for the fork.
+	keep, err := parent.KeepsUnreachable()
+	is.NoErr(err)
+	is.True(!keep)
+	is.NoErr(parent.SetKeepUnreachable(true))
+	keep, err = parent.KeepsUnreachable()
+	is.NoErr(err)
+	is.True(keep)
+	run(parent, "update-ref", "-d", "refs/heads/feature")
+	old := time.Now().AddDate(0, -1, 0)
+	is.NoErr(filepath.WalkDir(filepath.Join(parent.Path, "objects"), func(p string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Chtimes(p, old, old)
+	}))
+	run(parent, "gc", "--quiet")
+	run(fork, "fsck", "--connectivity-only")
+
+	// Dissociated forks stand on their own.
+	is.NoErr(fork.Dissociate(time.Minute))
+	alts, err = fork.Alternates()
+	is.NoErr(err)
+	is.Equal(len(alts), 0)
+	is.Equal(run(fork, "rev-parse", "feature"), second)
+	run(fork, "fsck", "--connectivity-only")
+
+	is.NoErr(parent.SetKeepUnreachable(false))
+	keep, err = parent.KeepsUnreachable()
+	is.NoErr(err)
+	is.True(!keep)
+}
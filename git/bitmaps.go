@@ -0,0 +1,58 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteBitmaps repacks the objects of the repository into a single pack with
+// a reachability bitmap with the pack options, killing git repack after
+// timeout. Bitmaps let git upload-pack find the objects to send without
+// walking the history.
+func (r *Repository) WriteBitmaps(timeout time.Duration, pack PackOptions) error {
+	args := append(pack.ConfigArgs(), "repack", "-a", "-d", "-b", "-q")
+	keep, err := r.KeepsUnreachable()
+	if err != nil {
+		return err
+	}
+	if keep {
+		// Forks may need the objects the refs don't reach anymore.
+		args = append(args, "--keep-unreachable")
+	}
+
+	_, err = NewCommand(args...).RunInDirWithTimeout(timeout, r.Path)
+	return err
+}
+
+// WriteCommitGraph writes the commit-graph file of the commits reachable
+// from the references of the repository, killing git commit-graph after
+// timeout.
+func (r *Repository) WriteCommitGraph(timeout time.Duration) error {
+	_, err := NewCommand("commit-graph", "write", "--reachable").RunInDirWithTimeout(timeout, r.Path)
+	return err
+}
+
+// HasBitmap returns whether a pack of the repository has a reachability
+// bitmap.
+func (r *Repository) HasBitmap() (bool, error) {
+	m, err := filepath.Glob(filepath.Join(r.Path, "objects", "pack", "*.bitmap"))
+	return len(m) > 0, err
+}
+
+// HasCommitGraph returns whether the repository has a commit-graph file,
+// either a single one or a split chain.
+func (r *Repository) HasCommitGraph() (bool, error) {
+	for _, p := range []string{
+		filepath.Join(r.Path, "objects", "info", "commit-graph"),
+		filepath.Join(r.Path, "objects", "info", "commit-graphs", "commit-graph-chain"),
+	} {
+		if _, err := os.Stat(p); err == nil {
+			return true, nil
+		} else if !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
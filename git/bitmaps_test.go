@@ -0,0 +1,52 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestWriteBitmaps(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	r, err := Init(dir, false)
+	is.NoErr(err)
+
+	is.NoErr(os.WriteFile(filepath.Join(dir, "README.md"), []byte("foo"), 0o600))
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "first"},
+	} {
+		_, err := NewCommand(args...).RunInDir(dir)
+		is.NoErr(err)
+	}
+
+	// Open the git directory, like the bare repositories of the server.
+	r, err = Open(filepath.Join(r.Path, ".git"))
+	is.NoErr(err)
+
+	ok, err := r.HasBitmap()
+	is.NoErr(err)
+	is.True(!ok)
+	ok, err = r.HasCommitGraph()
+	is.NoErr(err)
+	is.True(!ok)
+
+	is.NoErr(r.WriteBitmaps(time.Minute, PackOptions{Window: 50, Depth: 20, Threads: 1}))
+	is.NoErr(r.WriteCommitGraph(time.Minute))
+
+	ok, err = r.HasBitmap()
+	is.NoErr(err)
+	is.True(ok)
+	ok, err = r.HasCommitGraph()
+	is.NoErr(err)
+	is.True(ok)
+
+	c, err := r.CountObjects()
+	is.NoErr(err)
+	is.Equal(c.Loose, int64(0))
+	is.Equal(c.Packs, int64(1))
+}
@@ -0,0 +1,131 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine is a line of a file along with the commit that last changed it.
+type BlameLine struct {
+	// Number is the line number in the file, starting at 1.
+	Number  int
+	Content string
+	Commit  *BlameCommit
+}
+
+// BlameCommit is a commit as shown by blame.
+type BlameCommit struct {
+	ID          string
+	Author      string
+	AuthorEmail string
+	AuthorTime  time.Time
+	Summary     string
+}
+
+// Blame returns the lines of a file at a revision annotated with the commits
+// that last changed them.
+func (r *Repository) Blame(rev, path string) ([]BlameLine, error) {
+	if strings.HasPrefix(rev, "-") {
+		return nil, ErrRevisionNotExist
+	}
+
+	tree, err := r.LsTree(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	te, err := tree.TreeEntry(path)
+	if err != nil {
+		return nil, err
+	}
+	if te.IsTree() {
+		return nil, ErrFileNotFound
+	}
+
+	bin, err := te.File().IsBinary()
+	if err != nil {
+		return nil, err
+	}
+	if bin {
+		return nil, ErrBinaryFile
+	}
+
+	out, err := NewCommand("blame", "--porcelain", rev, "--", path).RunInDir(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBlame(out)
+}
+
+// parseBlame parses the output of git blame --porcelain. Commit details are
+// only shown the first time a commit appears.
+func parseBlame(out []byte) ([]BlameLine, error) {
+	commits := make(map[string]*BlameCommit)
+	lines := make([]BlameLine, 0)
+
+	var cur *BlameLine
+	s := bufio.NewScanner(bytes.NewReader(out))
+	s.Buffer(nil, len(out)+1)
+	for s.Scan() {
+		line := s.Text()
+		if cur == nil {
+			// Header: <sha> <orig line> <final line> [<lines in group>]
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				return nil, errors.New("invalid blame header: " + line)
+			}
+
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, errors.New("invalid blame header: " + line)
+			}
+
+			c, ok := commits[fields[0]]
+			if !ok {
+				c = &BlameCommit{ID: fields[0]}
+				commits[fields[0]] = c
+			}
+
+			cur = &BlameLine{Number: n, Commit: c}
+			continue
+		}
+
+		if content, ok := strings.CutPrefix(line, "\t"); ok {
+			cur.Content = content
+			lines = append(lines, *cur)
+			cur = nil
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			cur.Commit.Author = value
+		case "author-mail":
+			cur.Commit.AuthorEmail = strings.Trim(value, "<>")
+		case "author-time":
+			sec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, errors.New("invalid blame author time: " + value)
+			}
+			cur.Commit.AuthorTime = time.Unix(sec, 0)
+		case "author-tz":
+			if tz, err := time.Parse("-0700", value); err == nil {
+				cur.Commit.AuthorTime = cur.Commit.AuthorTime.In(tz.Location())
+			}
+		case "summary":
+			cur.Commit.Summary = value
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParseBlame(t *testing.T) {
+	is := is.New(t)
+	out := `1111111111111111111111111111111111111111 1 1 2
+author Alice
+author-mail <alice@example.com>
+author-time 1700000000
+author-tz +0200
+committer Alice
+committer-mail <alice@example.com>
+committer-time 1700000000
+committer-tz +0200
+summary first
+boundary
+filename main.go
+	package main
+1111111111111111111111111111111111111111 2 2
+	
+2222222222222222222222222222222222222222 3 3 1
+author Bob
+author-mail <bob@example.com>
+author-time 1700003600
+author-tz -0500
+committer Bob
+committer-mail <bob@example.com>
+committer-time 1700003600
+committer-tz -0500
+summary second
+previous 1111111111111111111111111111111111111111 main.go
+filename main.go
+	func main() {}
+`
+	lines, err := parseBlame([]byte(out))
+	is.NoErr(err)
+	is.Equal(len(lines), 3)
+
+	is.Equal(lines[0].Number, 1)
+	is.Equal(lines[0].Content, "package main")
+	is.Equal(lines[0].Commit.Author, "Alice")
+	is.Equal(lines[0].Commit.AuthorEmail, "alice@example.com")
+	is.Equal(lines[0].Commit.Summary, "first")
+	is.Equal(lines[0].Commit.AuthorTime.Unix(), int64(1700000000))
+	_, offset := lines[0].Commit.AuthorTime.Zone()
+	is.Equal(offset, 2*60*60)
+
+	// Commit details are shared by the lines of a commit.
+	is.Equal(lines[1].Number, 2)
+	is.Equal(lines[1].Content, "")
+	is.Equal(lines[1].Commit, lines[0].Commit)
+
+	is.Equal(lines[2].Number, 3)
+	is.Equal(lines[2].Content, "func main() {}")
+	is.Equal(lines[2].Commit.ID, "2222222222222222222222222222222222222222")
+	is.Equal(lines[2].Commit.Author, "Bob")
+}
+
+func TestParseBlameInvalid(t *testing.T) {
+	is := is.New(t)
+	_, err := parseBlame([]byte("nope\n"))
+	is.True(err != nil)
+}
@@ -0,0 +1,170 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aymanbagabas/git-module"
+)
+
+// Comparison is how the history of a head revision differs from the one of a
+// base revision.
+type Comparison struct {
+	// MergeBase is the best common ancestor of the revisions, empty when they
+	// have none.
+	MergeBase string
+	// Ahead is the number of commits of head that aren't in base.
+	Ahead int64
+	// Behind is the number of commits of base that aren't in head.
+	Behind int64
+}
+
+// FileChange is a file changed between two revisions.
+type FileChange struct {
+	// Status is one of added, modified, deleted, renamed, copied, and
+	// type_changed.
+	Status string
+	// Path is the path of the file, the new one for renames and copies.
+	Path string
+	// OldPath is the path the file was renamed or copied from.
+	OldPath string
+	// Additions and Deletions are the number of lines added and deleted. They
+	// are zero for binary files.
+	Additions int64
+	Deletions int64
+	Binary    bool
+}
+
+// fileChangeStatuses are the file statuses of git diff-tree --raw.
+var fileChangeStatuses = map[byte]string{
+	'A': "added",
+	'M': "modified",
+	'D': "deleted",
+	'R': "renamed",
+	'C': "copied",
+	'T': "type_changed",
+}
+
+// Compare compares the revisions base and head with git rev-list
+// --left-right --count.
+func (r *Repository) Compare(base, head string) (Comparison, error) {
+	// Don't let the revisions pass as options to git.
+	if strings.HasPrefix(base, "-") || strings.HasPrefix(head, "-") {
+		return Comparison{}, ErrRevisionNotExist
+	}
+
+	out, err := NewCommand("rev-list", "--left-right", "--count", base+"..."+head, "--").RunInDir(r.Path)
+	if err != nil {
+		return Comparison{}, err
+	}
+
+	var c Comparison
+	if _, err := fmt.Sscan(string(out), &c.Behind, &c.Ahead); err != nil {
+		return Comparison{}, fmt.Errorf("parse rev-list count: %w", err)
+	}
+
+	c.MergeBase, err = r.MergeBase(base, head)
+	if err != nil && !errors.Is(err, git.ErrNoMergeBase) {
+		return Comparison{}, err
+	}
+
+	return c, nil
+}
+
+// CommitsBetween returns at most limit commits of head that aren't in base,
+// newest first.
+func (r *Repository) CommitsBetween(base, head string, limit int) (Commits, error) {
+	if strings.HasPrefix(base, "-") || strings.HasPrefix(head, "-") {
+		return nil, ErrRevisionNotExist
+	}
+
+	cs, err := r.Log(base+".."+head, git.LogOptions{MaxCount: limit})
+	if err != nil {
+		return nil, err
+	}
+	commits := make(Commits, len(cs))
+	copy(commits, cs)
+	return commits, nil
+}
+
+// ChangedFiles returns the files changed between the trees of the revisions
+// from and to, with git diff-tree. Renames are detected.
+func (r *Repository) ChangedFiles(from, to string) ([]FileChange, error) {
+	if strings.HasPrefix(from, "-") || strings.HasPrefix(to, "-") {
+		return nil, ErrRevisionNotExist
+	}
+
+	out, err := NewCommand("diff-tree", "-r", "-M", "-z", "--raw", "--numstat", from, to, "--").RunInDir(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDiffTree(out)
+}
+
+// parseDiffTree parses the output of git diff-tree -z --raw --numstat. The
+// raw entries of all the files come first, then their line counts in the same
+// order.
+func parseDiffTree(out []byte) ([]FileChange, error) {
+	if len(out) == 0 {
+		return []FileChange{}, nil
+	}
+	fields := strings.Split(string(bytes.TrimSuffix(out, []byte{0})), "\x00")
+
+	invalid := errors.New("invalid diff-tree output")
+	var files []FileChange
+	i := 0
+	for i < len(fields) && strings.HasPrefix(fields[i], ":") {
+		// :old-mode new-mode old-sha new-sha status
+		raw := strings.Fields(fields[i])
+		if len(raw) != 5 || i+1 >= len(fields) {
+			return nil, invalid
+		}
+
+		f := FileChange{Status: fileChangeStatuses[raw[4][0]], Path: fields[i+1]}
+		i += 2
+		if raw[4][0] == 'R' || raw[4][0] == 'C' {
+			if i >= len(fields) {
+				return nil, invalid
+			}
+			f.OldPath, f.Path = f.Path, fields[i]
+			i++
+		}
+		files = append(files, f)
+	}
+
+	for j := range files {
+		if i >= len(fields) {
+			return nil, invalid
+		}
+
+		// additions deletions path, the path is empty for renames and copies
+		// and followed by the old and new paths.
+		stat := strings.SplitN(fields[i], "\t", 3)
+		if len(stat) != 3 {
+			return nil, invalid
+		}
+		i++
+		if stat[2] == "" {
+			i += 2
+		}
+
+		if stat[0] == "-" {
+			files[j].Binary = true
+			continue
+		}
+
+		var err error
+		if files[j].Additions, err = strconv.ParseInt(stat[0], 10, 64); err != nil {
+			return nil, invalid
+		}
+		if files[j].Deletions, err = strconv.ParseInt(stat[1], 10, 64); err != nil {
+			return nil, invalid
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCompare(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	r, err := Init(dir, false)
+	is.NoErr(err)
+
+	git := func(args ...string) string {
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		out, err := NewCommand(args...).RunInDir(dir)
+		is.NoErr(err)
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(files map[string]string) string {
+		for name, content := range files {
+			is.NoErr(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+		}
+		git("add", "-A")
+		git("commit", "-m", "commit")
+		return git("rev-parse", "HEAD")
+	}
+
+	root := commit(map[string]string{"a.txt": "one\n", "b.txt": "bee\n"})
+	git("branch", "base")
+	git("mv", "b.txt", "c.txt")
+	commit(map[string]string{"a.txt": "one\ntwo\n"})
+	head := commit(map[string]string{"d.bin": "\x00\x01"})
+	git("checkout", "base")
+	base := commit(map[string]string{"e.txt": "e\n"})
+
+	c, err := r.Compare(base, head)
+	is.NoErr(err)
+	is.Equal(c, Comparison{MergeBase: root, Ahead: 2, Behind: 1})
+
+	commits, err := r.CommitsBetween(base, head, 10)
+	is.NoErr(err)
+	is.Equal(len(commits), 2)
+	is.Equal(commits[0].ID.String(), head)
+
+	files, err := r.ChangedFiles(root, head)
+	is.NoErr(err)
+	is.Equal(files, []FileChange{
+		{Status: "modified", Path: "a.txt", Additions: 1},
+		{Status: "renamed", Path: "c.txt", OldPath: "b.txt"},
+		{Status: "added", Path: "d.bin", Binary: true},
+	})
+
+	files, err = r.ChangedFiles(head, head)
+	is.NoErr(err)
+	is.Equal(files, []FileChange{})
+
+	// Unrelated histories have no merge base.
+	git("checkout", "--orphan", "other")
+	other := commit(map[string]string{"f.txt": "f\n"})
+	c, err = r.Compare(base, other)
+	is.NoErr(err)
+	is.Equal(c, Comparison{Ahead: 1, Behind: 2})
+
+	_, err = r.Compare("--output=/tmp/nope", head)
+	is.Equal(err, ErrRevisionNotExist)
+}
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ObjectCount is the number and size of the objects of a repository, as
+// reported by git count-objects.
+type ObjectCount struct {
+	// Loose is the number of loose objects.
+	Loose int64
+	// LooseSize is the size in bytes of the loose objects.
+	LooseSize int64
+	// Packed is the number of packed objects.
+	Packed int64
+	// Packs is the number of packs.
+	Packs int64
+	// PackSize is the size in bytes of the packs.
+	PackSize int64
+}
+
+// CountObjects returns the number and size of the objects of the repository.
+func (r *Repository) CountObjects() (ObjectCount, error) {
+	out, err := NewCommand("count-objects", "-v").RunInDir(r.Path)
+	if err != nil {
+		return ObjectCount{}, err
+	}
+
+	return parseCountObjects(out)
+}
+
+// DiskSize returns the size in bytes of the objects of the repository, both
+// loose and packed.
+func (r *Repository) DiskSize() (int64, error) {
+	c, err := r.CountObjects()
+	if err != nil {
+		return 0, err
+	}
+
+	return c.LooseSize + c.PackSize, nil
+}
+
+// GC runs git gc on the repository with the pack options, killing it after
+// timeout.
+func (r *Repository) GC(timeout time.Duration, pack PackOptions) error {
+	_, err := NewCommand(append(pack.ConfigArgs(), "gc", "--quiet")...).RunInDirWithTimeout(timeout, r.Path)
+	return err
+}
+
+// parseCountObjects parses the output of git count-objects -v, where sizes
+// are in KiB.
+func parseCountObjects(out []byte) (ObjectCount, error) {
+	var c ObjectCount
+	fields := map[string]*int64{
+		"count":     &c.Loose,
+		"size":      &c.LooseSize,
+		"in-pack":   &c.Packed,
+		"packs":     &c.Packs,
+		"size-pack": &c.PackSize,
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		k, v, ok := strings.Cut(scanner.Text(), ": ")
+		field, known := fields[k]
+		if !ok || !known {
+			continue
+		}
+
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return ObjectCount{}, err
+		}
+		*field = n
+	}
+
+	c.LooseSize *= 1024
+	c.PackSize *= 1024
+	return c, scanner.Err()
+}
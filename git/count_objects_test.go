@@ -0,0 +1,26 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParseCountObjects(t *testing.T) {
+	is := is.New(t)
+	out := `count: 3
+size: 12
+in-pack: 10
+packs: 1
+size-pack: 30
+prune-packable: 0
+garbage: 0
+size-garbage: 4
+`
+	c, err := parseCountObjects([]byte(out))
+	is.NoErr(err)
+	is.Equal(c, ObjectCount{Loose: 3, LooseSize: 12 * 1024, Packed: 10, Packs: 1, PackSize: 30 * 1024})
+
+	_, err = parseCountObjects([]byte("size: x\n"))
+	is.True(err != nil)
+}
@@ -17,4 +17,6 @@ var (
 	ErrRevisionNotExist = git.ErrRevisionNotExist
 	// ErrNotAGitRepository is returned when the given path is not a Git repository.
 	ErrNotAGitRepository = errors.New("not a git repository")
+	// ErrBinaryFile is returned when blaming a binary file.
+	ErrBinaryFile = errors.New("cannot blame binary file")
 )
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/git-module"
+)
+
+// FsckResult is the outcome of git fsck.
+type FsckResult struct {
+	// Dangling are the dangling objects, as "type id". They're unreachable
+	// but harmless.
+	Dangling []string
+	// Problems are the missing, broken, and corrupt objects reported by git.
+	Problems []string
+}
+
+// Corrupt returns whether git fsck found problems.
+func (r FsckResult) Corrupt() bool {
+	return len(r.Problems) > 0
+}
+
+// Fsck runs git fsck --full on the repository, killing it after timeout. The
+// progress of git is written to progress unless it's nil. Finding a corrupt
+// repository isn't an error, the problems are in the result.
+func (r *Repository) Fsck(ctx context.Context, timeout time.Duration, progress io.Writer) (FsckResult, error) {
+	args := []string{"fsck", "--full"}
+	if progress != nil {
+		args = append(args, "--progress")
+	} else {
+		progress = io.Discard
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := NewCommand(args...).
+		AddOptions(git.CommandOptions{Context: ctx, Timeout: timeout}).
+		RunInDirPipeline(&stdout, io.MultiWriter(progress, &stderr), r.Path)
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return FsckResult{}, err
+	}
+
+	res := parseFsck(stdout.String(), stderr.String())
+	if exitErr != nil && !res.Corrupt() {
+		res.Problems = append(res.Problems, fmt.Sprintf("git fsck exited with status %d", exitErr.ExitCode()))
+	}
+
+	return res, nil
+}
+
+// parseFsck parses the output of git fsck. Objects are reported on stdout,
+// where broken links span two lines, and errors on stderr, along with the
+// progress.
+func parseFsck(stdout, stderr string) FsckResult {
+	var res FsckResult
+	for _, line := range strings.Split(stdout, "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+		case strings.HasPrefix(line, "dangling "):
+			res.Dangling = append(res.Dangling, strings.TrimPrefix(line, "dangling "))
+		case strings.HasPrefix(line, " ") && len(res.Problems) > 0:
+			// The second line of a broken link.
+			res.Problems[len(res.Problems)-1] += " " + strings.Join(strings.Fields(line), " ")
+		default:
+			res.Problems = append(res.Problems, strings.Join(strings.Fields(line), " "))
+		}
+	}
+
+	for _, line := range strings.Split(stderr, "\n") {
+		// Progress lines are overwritten with carriage returns.
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		if strings.HasPrefix(line, "error") || strings.HasPrefix(line, "fatal:") {
+			res.Problems = append(res.Problems, line)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestFsck(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	r, err := Init(dir, false)
+	is.NoErr(err)
+
+	git := func(args ...string) string {
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		out, err := NewCommand(args...).RunInDir(dir)
+		is.NoErr(err)
+		return strings.TrimSpace(string(out))
+	}
+
+	is.NoErr(os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0o600))
+	git("add", "-A")
+	git("commit", "-m", "commit")
+
+	var progress bytes.Buffer
+	res, err := r.Fsck(context.TODO(), time.Minute, &progress)
+	is.NoErr(err)
+	is.True(!res.Corrupt())
+	is.Equal(len(res.Dangling), 0)
+	is.True(strings.Contains(progress.String(), "Checking"))
+
+	// Unreachable objects are dangling.
+	is.NoErr(os.WriteFile(filepath.Join(dir, "b.txt"), []byte("two\n"), 0o600))
+	blob := git("hash-object", "-w", "b.txt")
+	res, err = r.Fsck(context.TODO(), time.Minute, nil)
+	is.NoErr(err)
+	is.True(!res.Corrupt())
+	is.Equal(res.Dangling, []string{"blob " + blob})
+
+	// Missing objects are problems.
+	tree := git("rev-parse", "HEAD^{tree}")
+	is.NoErr(os.Remove(filepath.Join(dir, ".git", "objects", tree[:2], tree[2:])))
+	res, err = r.Fsck(context.TODO(), time.Minute, nil)
+	is.NoErr(err)
+	is.True(res.Corrupt())
+	is.True(strings.Contains(strings.Join(res.Problems, "\n"), tree))
+}
+
+func TestParseFsck(t *testing.T) {
+	is := is.New(t)
+	stdout := "dangling blob 1111\n" +
+		"broken link from    tree 2222\n" +
+		"              to    blob 3333\n" +
+		"missing blob 3333\n"
+	stderr := "Checking objects:  50% (1/2)\rChecking objects: 100% (2/2), done.\n" +
+		"error: object file .git/objects/44/44 is empty\n" +
+		"warning in tree 5555: zeroPaddedFilemode: contains zero-padded file modes\n"
+
+	is.Equal(parseFsck(stdout, stderr), FsckResult{
+		Dangling: []string{"blob 1111"},
+		Problems: []string{
+			"broken link from tree 2222 to blob 3333",
+			"missing blob 3333",
+			"error: object file .git/objects/44/44 is empty",
+		},
+	})
+}
@@ -0,0 +1,297 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// languagesTimeout is how long computing the languages of a tree may take.
+const languagesTimeout = 5 * time.Minute
+
+// Language is the share of a language in a tree.
+type Language struct {
+	Name string `json:"name"`
+	// Size is the size in bytes of the files of the language.
+	Size int64 `json:"size"`
+	// Percent is the share of the size of the files of all the languages,
+	// rounded to one decimal.
+	Percent float64 `json:"percent"`
+}
+
+// languageExtensions are the languages of file extensions. Like linguist,
+// only programming and markup languages are counted, data and prose aren't.
+var languageExtensions = map[string]string{
+	".asm":     "Assembly",
+	".s":       "Assembly",
+	".c":       "C",
+	".cs":      "C#",
+	".cc":      "C++",
+	".cpp":     "C++",
+	".cxx":     "C++",
+	".hh":      "C++",
+	".hpp":     "C++",
+	".hxx":     "C++",
+	".clj":     "Clojure",
+	".cljc":    "Clojure",
+	".cljs":    "Clojure",
+	".css":     "CSS",
+	".dart":    "Dart",
+	".ex":      "Elixir",
+	".exs":     "Elixir",
+	".elm":     "Elm",
+	".el":      "Emacs Lisp",
+	".erl":     "Erlang",
+	".hrl":     "Erlang",
+	".fs":      "F#",
+	".fsx":     "F#",
+	".f90":     "Fortran",
+	".f95":     "Fortran",
+	".go":      "Go",
+	".gradle":  "Groovy",
+	".groovy":  "Groovy",
+	".hs":      "Haskell",
+	".hcl":     "HCL",
+	".tf":      "HCL",
+	".htm":     "HTML",
+	".html":    "HTML",
+	".java":    "Java",
+	".cjs":     "JavaScript",
+	".js":      "JavaScript",
+	".jsx":     "JavaScript",
+	".mjs":     "JavaScript",
+	".jl":      "Julia",
+	".kt":      "Kotlin",
+	".kts":     "Kotlin",
+	".lua":     "Lua",
+	".mk":      "Makefile",
+	".nim":     "Nim",
+	".nix":     "Nix",
+	".m":       "Objective-C",
+	".mm":      "Objective-C",
+	".ml":      "OCaml",
+	".mli":     "OCaml",
+	".pl":      "Perl",
+	".pm":      "Perl",
+	".php":     "PHP",
+	".ps1":     "PowerShell",
+	".psm1":    "PowerShell",
+	".py":      "Python",
+	".pyi":     "Python",
+	".r":       "R",
+	".gemspec": "Ruby",
+	".rake":    "Ruby",
+	".rb":      "Ruby",
+	".rs":      "Rust",
+	".sass":    "Sass",
+	".scala":   "Scala",
+	".scss":    "SCSS",
+	".bash":    "Shell",
+	".sh":      "Shell",
+	".zsh":     "Shell",
+	".svelte":  "Svelte",
+	".swift":   "Swift",
+	".tcl":     "Tcl",
+	".cts":     "TypeScript",
+	".mts":     "TypeScript",
+	".ts":      "TypeScript",
+	".tsx":     "TypeScript",
+	".vim":     "Vim Script",
+	".vb":      "Visual Basic",
+	".vue":     "Vue",
+	".zig":     "Zig",
+}
+
+// languageFilenames are the languages of files without an extension.
+var languageFilenames = map[string]string{
+	"Dockerfile":  "Dockerfile",
+	"Gemfile":     "Ruby",
+	"GNUmakefile": "Makefile",
+	"Jenkinsfile": "Groovy",
+	"Makefile":    "Makefile",
+	"makefile":    "Makefile",
+	"Rakefile":    "Ruby",
+}
+
+// vendoredDirs are the directories of vendored files, which aren't counted.
+var vendoredDirs = map[string]struct{}{
+	"bower_components": {},
+	"Carthage":         {},
+	"dist":             {},
+	"Godeps":           {},
+	"node_modules":     {},
+	"Pods":             {},
+	"third_party":      {},
+	"thirdparty":       {},
+	"vendor":           {},
+}
+
+// IsVendored returns whether a file is vendored, or minified, and isn't
+// counted in the languages of a tree.
+func IsVendored(p string) bool {
+	dirs := strings.Split(path.Dir(p), "/")
+	for _, d := range dirs {
+		if _, ok := vendoredDirs[d]; ok {
+			return true
+		}
+	}
+
+	base := path.Base(p)
+	return strings.HasSuffix(base, ".min.js") || strings.HasSuffix(base, ".min.css")
+}
+
+// DetectLanguage returns the language of a file from its name, or an empty
+// string if it isn't a programming or markup language. Headers are reported
+// as "C/C++", see [Repository.Languages].
+func DetectLanguage(p string) string {
+	base := path.Base(p)
+	if l, ok := languageFilenames[base]; ok {
+		return l
+	}
+
+	ext := strings.ToLower(path.Ext(base))
+	if ext == ".h" {
+		return headerLanguage
+	}
+
+	return languageExtensions[ext]
+}
+
+// headerLanguage is the placeholder language of .h files, which may be C,
+// C++, or Objective-C.
+const headerLanguage = "C/C++"
+
+// Languages returns the languages of the tree of a revision, sorted by size,
+// like linguist does. Files are classified by their name, and vendored and
+// binary files are left out. Headers count towards the language among C, C++,
+// and Objective-C with the most bytes.
+func (r *Repository) Languages(rev string) ([]Language, error) {
+	if strings.HasPrefix(rev, "-") {
+		return nil, ErrRevisionNotExist
+	}
+
+	out, err := NewCommand("ls-tree", "-r", "-l", "-z", rev).RunInDirWithTimeout(languagesTimeout, r.Path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := parseLsTreeSizes(out)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := r.textFiles(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := map[string]int64{}
+	for p, size := range files {
+		if _, ok := text[p]; !ok || IsVendored(p) {
+			continue
+		}
+		if l := DetectLanguage(p); l != "" {
+			sizes[l] += size
+		}
+	}
+
+	return languageShares(sizes), nil
+}
+
+// textFiles returns the paths of the text files of the tree of a revision, as
+// git grep sees them. Binary files, and files marked as such in
+// .gitattributes, are left out.
+func (r *Repository) textFiles(rev string) (map[string]struct{}, error) {
+	var stdout, stderr bytes.Buffer
+	err := NewCommand("grep", "-I", "-l", "-z", "-e", "", rev, "--").
+		RunInDirPipelineWithTimeout(languagesTimeout, &stdout, &stderr, r.Path)
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		// No text files.
+		return map[string]struct{}{}, nil
+	case err != nil:
+		return nil, errors.New(strings.TrimSpace(stderr.String()))
+	}
+
+	files := map[string]struct{}{}
+	for _, p := range strings.Split(stdout.String(), "\x00") {
+		if p != "" {
+			files[strings.TrimPrefix(p, rev+":")] = struct{}{}
+		}
+	}
+
+	return files, nil
+}
+
+// parseLsTreeSizes parses the output of git ls-tree -r -l -z into the sizes of
+// the regular files. Symbolic links and submodules are left out.
+func parseLsTreeSizes(out []byte) (map[string]int64, error) {
+	files := map[string]int64{}
+	for _, entry := range strings.Split(string(out), "\x00") {
+		if entry == "" {
+			continue
+		}
+
+		// mode type object size\tpath
+		meta, p, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 {
+			return nil, errors.New("invalid ls-tree output")
+		}
+		if fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		files[p] = size
+	}
+
+	return files, nil
+}
+
+// languageShares returns the languages of sizes sorted by size, then name,
+// with their share of the total size. The size of headers goes to the
+// language among C, C++, and Objective-C with the most bytes.
+func languageShares(sizes map[string]int64) []Language {
+	if h, ok := sizes[headerLanguage]; ok {
+		delete(sizes, headerLanguage)
+		l := "C"
+		for _, c := range []string{"C++", "Objective-C"} {
+			if sizes[c] > sizes[l] {
+				l = c
+			}
+		}
+		sizes[l] += h
+	}
+
+	var total int64
+	langs := make([]Language, 0, len(sizes))
+	for name, size := range sizes {
+		if size == 0 {
+			continue
+		}
+		total += size
+		langs = append(langs, Language{Name: name, Size: size})
+	}
+
+	for i := range langs {
+		langs[i].Percent = math.Round(float64(langs[i].Size)*1000/float64(total)) / 10
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if langs[i].Size != langs[j].Size {
+			return langs[i].Size > langs[j].Size
+		}
+		return langs[i].Name < langs[j].Name
+	})
+
+	return langs
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	is := is.New(t)
+	for p, want := range map[string]string{
+		"main.go":           "Go",
+		"cmd/tool/Makefile": "Makefile",
+		"scripts/build.SH":  "Shell",
+		"src/lib.h":         headerLanguage,
+		"README.md":         "",
+		"go.mod":            "",
+		"logo.png":          "",
+	} {
+		is.Equal(DetectLanguage(p), want) // language of p
+	}
+}
+
+func TestIsVendored(t *testing.T) {
+	is := is.New(t)
+	is.True(IsVendored("vendor/github.com/x/y.go"))
+	is.True(IsVendored("web/node_modules/react/index.js"))
+	is.True(IsVendored("static/app.min.js"))
+	is.True(!IsVendored("pkg/vendors/vendor.go"))
+	is.True(!IsVendored("main.go"))
+}
+
+func TestLanguageShares(t *testing.T) {
+	is := is.New(t)
+	langs := languageShares(map[string]int64{"Go": 600, "Shell": 100, "C++": 100, headerLanguage: 200})
+	is.Equal(langs, []Language{
+		{Name: "Go", Size: 600, Percent: 60},
+		{Name: "C++", Size: 300, Percent: 30},
+		{Name: "Shell", Size: 100, Percent: 10},
+	})
+	is.Equal(len(languageShares(map[string]int64{})), 0)
+}
+
+func TestLanguages(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	r, err := Init(dir, false)
+	is.NoErr(err)
+
+	for p, content := range map[string]string{
+		"main.go":               strings.Repeat("x", 300),
+		"build.sh":              strings.Repeat("x", 100),
+		"vendor/dep/dep.go":     strings.Repeat("x", 1000),
+		"README.md":             strings.Repeat("x", 1000),
+		"video.ts":              "\x00\x01binary",
+		"web/app.min.js":        strings.Repeat("x", 1000),
+		"web/node_modules/a.js": "x",
+	} {
+		fp := filepath.Join(dir, p)
+		is.NoErr(os.MkdirAll(filepath.Dir(fp), 0o755))
+		is.NoErr(os.WriteFile(fp, []byte(content), 0o644))
+	}
+	_, err = NewCommand("add", "-A").RunInDir(dir)
+	is.NoErr(err)
+	_, err = NewCommand("-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-m", "first").RunInDir(dir)
+	is.NoErr(err)
+
+	langs, err := r.Languages("HEAD")
+	is.NoErr(err)
+	is.Equal(langs, []Language{
+		{Name: "Go", Size: 300, Percent: 75},
+		{Name: "Shell", Size: 100, Percent: 25},
+	})
+
+	_, err = r.Languages("--output=x")
+	is.Equal(err, ErrRevisionNotExist)
+}
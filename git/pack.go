@@ -0,0 +1,70 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	// MaxPackWindow is the largest pack window, git gc --aggressive uses
+	// 250.
+	MaxPackWindow = 1000
+
+	// MaxPackDepth is the largest pack depth, git caps delta chains at 4095.
+	MaxPackDepth = 4095
+
+	// MaxPackThreads is the largest number of pack threads.
+	MaxPackThreads = 64
+)
+
+// PackOptions tune the delta compression of git pack-objects, trading CPU
+// and memory for smaller packs. Zero values use the defaults of git.
+type PackOptions struct {
+	// Window is the number of objects each object is compared to when
+	// looking for deltas, pack.window.
+	Window int
+
+	// Depth is the maximum length of delta chains, pack.depth.
+	Depth int
+
+	// Threads is the number of threads looking for deltas, pack.threads.
+	Threads int
+}
+
+// Validate returns an error if an option is out of range.
+func (o PackOptions) Validate() error {
+	for _, opt := range []struct {
+		name     string
+		val, max int
+	}{
+		{"window", o.Window, MaxPackWindow},
+		{"depth", o.Depth, MaxPackDepth},
+		{"threads", o.Threads, MaxPackThreads},
+	} {
+		if opt.val < 0 || opt.val > opt.max {
+			return fmt.Errorf("pack %s must be between 0 and %d", opt.name, opt.max)
+		}
+	}
+
+	return nil
+}
+
+// ConfigArgs returns the git -c arguments setting the options that aren't
+// zero. git passes them down to the pack-objects it runs.
+func (o PackOptions) ConfigArgs() []string {
+	var args []string
+	for _, opt := range []struct {
+		key string
+		val int
+	}{
+		{"pack.window", o.Window},
+		{"pack.depth", o.Depth},
+		{"pack.threads", o.Threads},
+	} {
+		if opt.val > 0 {
+			args = append(args, "-c", opt.key+"="+strconv.Itoa(opt.val))
+		}
+	}
+
+	return args
+}
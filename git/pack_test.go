@@ -0,0 +1,27 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestPackOptions(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(len(PackOptions{}.ConfigArgs()), 0) // git defaults
+	is.Equal(PackOptions{Window: 250, Threads: 2}.ConfigArgs(), []string{"-c", "pack.window=250", "-c", "pack.threads=2"})
+	is.Equal(PackOptions{Depth: 100}.ConfigArgs(), []string{"-c", "pack.depth=100"})
+
+	is.NoErr(PackOptions{}.Validate())
+	is.NoErr(PackOptions{Window: MaxPackWindow, Depth: MaxPackDepth, Threads: MaxPackThreads}.Validate())
+	for _, o := range []PackOptions{
+		{Window: -1},
+		{Window: MaxPackWindow + 1},
+		{Depth: MaxPackDepth + 1},
+		{Threads: -1},
+		{Threads: MaxPackThreads + 1},
+	} {
+		is.True(o.Validate() != nil) // out of range
+	}
+}
@@ -322,17 +322,29 @@ func writeFilePatchHeader(sb *strings.Builder, filePatch *DiffFile) {
 func (d *Diff) Patch() string {
 	var p strings.Builder
 	for _, f := range d.Files {
-		writeFilePatchHeader(&p, f)
-		for _, s := range f.Sections {
-			for _, l := range s.Lines {
-				p.WriteString(s.diffFor(l))
-				p.WriteString("\n")
-			}
-		}
+		f.writePatch(&p)
 	}
 	return p.String()
 }
 
+// Patch returns the file diff as a patch. Binary files only have a line
+// saying they differ.
+func (f *DiffFile) Patch() string {
+	var p strings.Builder
+	f.writePatch(&p)
+	return p.String()
+}
+
+func (f *DiffFile) writePatch(p *strings.Builder) {
+	writeFilePatchHeader(p, f)
+	for _, s := range f.Sections {
+		for _, l := range s.Lines {
+			p.WriteString(s.diffFor(l))
+			p.WriteString("\n")
+		}
+	}
+}
+
 func toDiff(ddiff *git.Diff) *Diff {
 	files := make([]*DiffFile, 0, len(ddiff.Files))
 	for _, df := range ddiff.Files {
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDiffRange(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	r, err := Init(dir, false)
+	is.NoErr(err)
+
+	commit := func(files map[string]string) string {
+		for name, content := range files {
+			is.NoErr(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+		}
+		for _, args := range [][]string{
+			{"add", "-A"},
+			{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "commit"},
+		} {
+			_, err := NewCommand(args...).RunInDir(dir)
+			is.NoErr(err)
+		}
+		id, err := NewCommand("rev-parse", "HEAD").RunInDir(dir)
+		is.NoErr(err)
+		return strings.TrimSpace(string(id))
+	}
+
+	base := commit(map[string]string{"a.txt": "one\n", "b.bin": "\x00\x01"})
+	commit(map[string]string{"a.txt": "two\n"})
+	head := commit(map[string]string{"b.bin": "\x00\x02"})
+
+	diff, err := r.DiffRange(base, head)
+	is.NoErr(err)
+	is.Equal(len(diff.Files), 2)
+
+	patches := map[string]string{}
+	for _, f := range diff.Files {
+		patches[f.Name] = f.Patch()
+	}
+	is.True(strings.Contains(patches["a.txt"], "-one\n+two\n"))
+	is.True(strings.Contains(patches["b.bin"], "Binary files a/b.bin and b/b.bin differ"))
+	is.Equal(diff.Patch(), diff.Files[0].Patch()+diff.Files[1].Patch())
+
+	_, err = r.DiffRange("--output=/tmp/nope", head)
+	is.Equal(err, ErrRevisionNotExist)
+}
@@ -48,3 +48,21 @@ func (r *Reference) IsBranch() bool {
 func (r *Reference) IsTag() bool {
 	return strings.HasPrefix(r.Refspec, git.RefsTags)
 }
+
+// RefCommit returns the commit a reference points to. Tags also return their
+// tag, which is annotated when its type is ObjectTag, and lightweight when
+// it's the commit itself.
+func (r *Repository) RefCommit(ref *Reference) (*Tag, *Commit, error) {
+	if !ref.IsTag() {
+		c, err := r.CatFileCommit(ref.ID)
+		return nil, c, err
+	}
+
+	t, err := r.Tag(ref.Name().Short())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := t.Commit()
+	return t, c, err
+}
@@ -151,6 +151,24 @@ func (r *Repository) Diff(commit *Commit) (*Diff, error) {
 	return toDiff(diff), nil
 }
 
+// DiffRange returns the diff between two revisions.
+func (r *Repository) DiffRange(base, head string) (*Diff, error) {
+	// Don't let the base pass as an option to git.
+	if strings.HasPrefix(base, "-") {
+		return nil, ErrRevisionNotExist
+	}
+	diff, err := r.Repository.Diff(head, DiffMaxFiles, DiffMaxFileLines, DiffMaxLineChars, git.DiffOptions{
+		Base: base,
+		CommandOptions: git.CommandOptions{
+			Envs: []string{"GIT_CONFIG_GLOBAL=/dev/null"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDiff(diff), nil
+}
+
 // Patch returns the patch for the given reference.
 func (r *Repository) Patch(commit *Commit) (string, error) {
 	diff, err := r.Diff(commit)
@@ -176,6 +194,22 @@ func (r *Repository) CommitsByPage(ref *Reference, page, size int) (Commits, err
 	return commits, nil
 }
 
+// CommitsAfter returns at most limit commits of the given reference, skipping
+// the first skip ones. It runs git log with --skip and --max-count so only the
+// returned commits are read.
+func (r *Repository) CommitsAfter(ref *Reference, skip, limit int) (Commits, error) {
+	cs, err := r.Log(ref.Name().String(), git.LogOptions{
+		Skip:     skip,
+		MaxCount: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	commits := make(Commits, len(cs))
+	copy(commits, cs)
+	return commits, nil
+}
+
 // SymbolicRef returns or updates the symbolic reference for the given name.
 // Both name and ref can be empty.
 func (r *Repository) SymbolicRef(name string, ref string, opts ...git.SymbolicRefOptions) (string, error) {
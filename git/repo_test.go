@@ -0,0 +1,36 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCommitsAfter(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	r, err := Init(dir, false)
+	is.NoErr(err)
+
+	for _, msg := range []string{"one", "two", "three", "four", "five"} {
+		_, err := NewCommand("-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "--allow-empty", "-m", msg).RunInDir(dir)
+		is.NoErr(err)
+	}
+	head, err := r.HEAD()
+	is.NoErr(err)
+
+	messages := func(skip, limit int) []string {
+		cc, err := r.CommitsAfter(head, skip, limit)
+		is.NoErr(err)
+		msgs := make([]string, len(cc))
+		for i, c := range cc {
+			msgs[i] = c.Summary()
+		}
+		return msgs
+	}
+	is.Equal(messages(0, 2), []string{"five", "four"})
+	is.Equal(messages(2, 2), []string{"three", "two"})
+	is.Equal(messages(4, 2), []string{"one"})
+	is.Equal(len(messages(5, 2)), 0)
+}
@@ -4,3 +4,7 @@ import "github.com/aymanbagabas/git-module"
 
 // Tag is a git tag.
 type Tag = git.Tag
+
+// ObjectTag is the type of annotated tags. The type of lightweight tags is
+// the one of the object they point to.
+const ObjectTag = git.ObjectTag
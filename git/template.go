@@ -0,0 +1,98 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CopyTree copies the tree of a revision of another repository, along with
+// the objects it references, and returns its ID. The history of the revision
+// isn't copied.
+func (r *Repository) CopyTree(src *Repository, rev string) (string, error) {
+	if strings.HasPrefix(rev, "-") {
+		return "", ErrRevisionNotExist
+	}
+
+	out, err := NewCommand("rev-parse", "--verify", rev+"^{tree}").RunInDir(src.Path)
+	if err != nil {
+		return "", ErrRevisionNotExist
+	}
+	tree := strings.TrimSpace(string(out))
+
+	// rev-list lists the objects along with their paths, pack-objects only
+	// wants the former.
+	out, err = NewCommand("rev-list", "--objects", tree).RunInDir(src.Path)
+	if err != nil {
+		return "", err
+	}
+	var ids bytes.Buffer
+	for _, line := range strings.Split(string(out), "\n") {
+		if id, _, _ := strings.Cut(line, " "); id != "" {
+			ids.WriteString(id + "\n")
+		}
+	}
+
+	pr, pw := io.Pipe()
+	errc := make(chan error, 1)
+	go func() {
+		var stderr bytes.Buffer
+		err := NewCommand("pack-objects", "--stdout", "-q").
+			WithTimeout(-1).
+			RunInDirWithOptions(src.Path, RunInDirOptions{
+				Stdin:  &ids,
+				Stdout: pw,
+				Stderr: &stderr,
+			})
+		if err != nil {
+			err = fmt.Errorf("git pack-objects: %w - %s", err, stderr.String())
+		}
+		pw.CloseWithError(err) //nolint: errcheck
+		errc <- err
+	}()
+
+	var stderr bytes.Buffer
+	if err := NewCommand("unpack-objects", "-q").
+		WithTimeout(-1).
+		RunInDirWithOptions(r.Path, RunInDirOptions{
+			Stdin:  pr,
+			Stderr: &stderr,
+		}); err != nil {
+		pr.CloseWithError(err) //nolint: errcheck
+		<-errc
+		return "", fmt.Errorf("git unpack-objects: %w - %s", err, stderr.String())
+	}
+
+	if err := <-errc; err != nil {
+		return "", err
+	}
+
+	return tree, nil
+}
+
+// CommitTree creates a commit of a tree without parents and points a branch
+// at it. It returns the ID of the commit.
+func (r *Repository) CommitTree(tree, branch, message string, author *Signature) (string, error) {
+	cmd := NewCommand("commit-tree", "-m", message, tree).AddEnvs(
+		"GIT_AUTHOR_NAME="+author.Name,
+		"GIT_AUTHOR_EMAIL="+author.Email,
+		"GIT_AUTHOR_DATE="+author.When.Format(time.RFC3339),
+		"GIT_COMMITTER_NAME="+author.Name,
+		"GIT_COMMITTER_EMAIL="+author.Email,
+		"GIT_COMMITTER_DATE="+author.When.Format(time.RFC3339),
+	)
+	out, err := cmd.RunInDir(r.Path)
+	if err != nil {
+		return "", err
+	}
+	id := strings.TrimSpace(string(out))
+
+	// Only create the branch, don't move an existing one.
+	if _, err := NewCommand("update-ref", RefsHeads+branch, id, ZeroID).RunInDir(r.Path); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestCopyTree(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	src, err := Init(dir, false)
+	is.NoErr(err)
+
+	for i, content := range []string{"first", "second"} {
+		is.NoErr(os.MkdirAll(filepath.Join(dir, "cmd"), 0o700))
+		is.NoErr(os.WriteFile(filepath.Join(dir, "README.md"), []byte(content), 0o600))
+		is.NoErr(os.WriteFile(filepath.Join(dir, "cmd", "main.go"), []byte("package main"), 0o600))
+		for _, args := range [][]string{
+			{"add", "-A"},
+			{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "commit " + string(rune('1'+i))},
+		} {
+			_, err := NewCommand(args...).RunInDir(dir)
+			is.NoErr(err)
+		}
+	}
+
+	dst, err := Init(filepath.Join(t.TempDir(), "dst"), true)
+	is.NoErr(err)
+
+	tree, err := dst.CopyTree(src, "HEAD")
+	is.NoErr(err)
+
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	id, err := dst.CommitTree(tree, "main", "Initial commit", &Signature{Name: "alice", Email: "alice@example.com", When: when})
+	is.NoErr(err)
+
+	// The new commit has the tree but none of the history.
+	out, err := NewCommand("rev-list", "--count", "refs/heads/main").RunInDir(dst.Path)
+	is.NoErr(err)
+	is.Equal(strings.TrimSpace(string(out)), "1")
+
+	out, err = NewCommand("show", "-s", "--format=%T %an <%ae> %aI %s", id).RunInDir(dst.Path)
+	is.NoErr(err)
+	is.Equal(strings.TrimSpace(string(out)), tree+" alice <alice@example.com> 2024-01-02T03:04:05+00:00 Initial commit")
+
+	out, err = NewCommand("show", "refs/heads/main:README.md").RunInDir(dst.Path)
+	is.NoErr(err)
+	is.Equal(string(out), "second")
+
+	out, err = NewCommand("show", "refs/heads/main:cmd/main.go").RunInDir(dst.Path)
+	is.NoErr(err)
+	is.Equal(string(out), "package main")
+
+	// Existing branches aren't overwritten.
+	_, err = dst.CommitTree(tree, "main", "Again", &Signature{Name: "alice", When: when})
+	is.True(err != nil)
+}
+
+func TestCopyTreeInvalidRevision(t *testing.T) {
+	is := is.New(t)
+	src, err := Init(t.TempDir(), false)
+	is.NoErr(err)
+	dst, err := Init(filepath.Join(t.TempDir(), "dst"), true)
+	is.NoErr(err)
+
+	for _, rev := range []string{"nope", "--all"} {
+		_, err := dst.CopyTree(src, rev)
+		is.Equal(err, ErrRevisionNotExist)
+	}
+}
@@ -7,3 +7,6 @@ type CommandOptions = git.CommandOptions
 
 // CloneOptions contain options for cloning a repository.
 type CloneOptions = git.CloneOptions
+
+// Signature is the author or committer of a commit.
+type Signature = git.Signature
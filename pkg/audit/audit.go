@@ -0,0 +1,112 @@
+// Package audit records the access-control decisions of git operations.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+// Outcome is the outcome of an access-control decision.
+type Outcome string
+
+const (
+	// Allowed is the outcome of an operation the user has access to.
+	Allowed Outcome = "allowed"
+
+	// Denied is the outcome of an operation the user doesn't have access to.
+	Denied Outcome = "denied"
+)
+
+// Entry is an access-control decision.
+type Entry struct {
+	// Time is when the decision was made.
+	Time time.Time `json:"time"`
+
+	// Username is the user the decision was made for, it's empty for
+	// anonymous users.
+	Username string `json:"username"`
+
+	// Repo is the repository being accessed.
+	Repo string `json:"repo"`
+
+	// Service is the git service of the operation, e.g. "git-upload-pack".
+	Service string `json:"service"`
+
+	// Transport is the transport of the operation, "ssh", "http", or "git".
+	Transport string `json:"transport"`
+
+	// AccessLevel is the access level the user was granted.
+	AccessLevel access.AccessLevel `json:"access_level"`
+
+	// RemoteAddr is the address of the client.
+	RemoteAddr string `json:"remote_addr"`
+
+	// Outcome is whether the operation was allowed.
+	Outcome Outcome `json:"outcome"`
+
+	// Details describes the change made by the operation, if any, e.g. the
+	// new owner of a transferred repository.
+	Details string `json:"details,omitempty"`
+}
+
+// Filter selects the entries of a query.
+type Filter struct {
+	// Username matches the entries of a user, all users if empty.
+	Username string
+
+	// Repo matches the entries of a repository, all repositories if empty.
+	Repo string
+
+	// Limit is the maximum number of entries returned.
+	Limit int
+}
+
+// Sink is where the audit log is written.
+type Sink interface {
+	// Record writes an entry to the audit log.
+	Record(ctx context.Context, e Entry) error
+
+	// Query returns the most recent entries matching the filter, newest
+	// first.
+	Query(ctx context.Context, f Filter) ([]Entry, error)
+}
+
+// NewSink returns the configured sink of the audit log, or nil if the audit
+// log is disabled.
+func NewSink(cfg *config.Config, dbx *db.DB, st store.AuditStore) Sink {
+	switch cfg.Audit.Sink {
+	case "file":
+		return NewFileSink(cfg.Audit.Path)
+	case "database":
+		return NewDatabaseSink(dbx, st)
+	default:
+		return nil
+	}
+}
+
+// Source is where an operation comes from.
+type Source struct {
+	// Transport is the transport of the operation, "ssh", "http", or "git".
+	Transport string
+
+	// RemoteAddr is the address of the client.
+	RemoteAddr string
+}
+
+type sourceKey struct{}
+
+// WithSource returns a context with the source of an operation.
+func WithSource(ctx context.Context, src Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, src)
+}
+
+// SourceFromContext returns the source of an operation from a context.
+func SourceFromContext(ctx context.Context) Source {
+	src, _ := ctx.Value(sourceKey{}).(Source)
+	return src
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+// DatabaseSink writes the audit log to the database.
+type DatabaseSink struct {
+	db    *db.DB
+	store store.AuditStore
+}
+
+var _ Sink = (*DatabaseSink)(nil)
+
+// NewDatabaseSink returns a sink writing to the audit_log table.
+func NewDatabaseSink(dbx *db.DB, st store.AuditStore) *DatabaseSink {
+	return &DatabaseSink{db: dbx, store: st}
+}
+
+// Record implements Sink.
+func (s *DatabaseSink) Record(ctx context.Context, e Entry) error {
+	return s.store.CreateAuditEntry(ctx, s.db, models.AuditEntry{
+		Username:    e.Username,
+		Repo:        e.Repo,
+		Service:     e.Service,
+		Transport:   e.Transport,
+		AccessLevel: e.AccessLevel,
+		RemoteAddr:  e.RemoteAddr,
+		Allowed:     e.Outcome == Allowed,
+		Details:     e.Details,
+		CreatedAt:   e.Time,
+	})
+}
+
+// Query implements Sink.
+func (s *DatabaseSink) Query(ctx context.Context, f Filter) ([]Entry, error) {
+	ms, err := s.store.GetAuditEntries(ctx, s.db, f.Username, f.Repo, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, len(ms))
+	for i, m := range ms {
+		e := Entry{
+			Time:        m.CreatedAt,
+			Username:    m.Username,
+			Repo:        m.Repo,
+			Service:     m.Service,
+			Transport:   m.Transport,
+			AccessLevel: m.AccessLevel,
+			RemoteAddr:  m.RemoteAddr,
+			Outcome:     Denied,
+			Details:     m.Details,
+		}
+		if m.Allowed {
+			e.Outcome = Allowed
+		}
+		entries[i] = e
+	}
+
+	return entries, nil
+}
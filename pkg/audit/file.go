@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSink writes the audit log to a file, one JSON entry per line.
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+var _ Sink = (*FileSink)(nil)
+
+// NewFileSink returns a sink writing to the file at path. The file is opened
+// for each entry so it can be rotated.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Record implements Sink.
+func (s *FileSink) Record(_ context.Context, e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), os.ModePerm); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close() //nolint: errcheck
+		return err
+	}
+
+	return f.Close()
+}
+
+// Query implements Sink. Lines that aren't entries are skipped.
+func (s *FileSink) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint: errcheck
+
+	// Keep the last matching entries in a ring buffer.
+	ring := make([]Entry, 0, filter.Limit)
+	var next int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if (filter.Username != "" && e.Username != filter.Username) ||
+			(filter.Repo != "" && e.Repo != filter.Repo) {
+			continue
+		}
+
+		if len(ring) < filter.Limit {
+			ring = append(ring, e)
+		} else if filter.Limit > 0 {
+			ring[next] = e
+			next = (next + 1) % filter.Limit
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(ring))
+	for i := len(ring) - 1; i >= 0; i-- {
+		entries = append(entries, ring[(next+i)%len(ring)])
+	}
+
+	return entries, nil
+}
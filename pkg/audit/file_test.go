@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+)
+
+func TestFileSink(t *testing.T) {
+	ctx := context.TODO()
+	path := filepath.Join(t.TempDir(), "log", "audit.log")
+	s := NewFileSink(path)
+
+	// Querying before anything is recorded returns nothing.
+	entries, err := s.Query(ctx, Filter{Limit: 10})
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Query() on missing file = %v, %v", entries, err)
+	}
+
+	now := time.Now().UTC()
+	for i, e := range []Entry{
+		{Username: "alice", Repo: "repo1", Service: "git-upload-pack", AccessLevel: access.ReadOnlyAccess, Outcome: Allowed},
+		{Username: "bob", Repo: "repo1", Service: "git-receive-pack", AccessLevel: access.ReadOnlyAccess, Outcome: Denied},
+		{Username: "alice", Repo: "repo2", Service: "git-receive-pack", AccessLevel: access.ReadWriteAccess, Outcome: Allowed},
+		{Username: "alice", Repo: "repo1", Service: "git-receive-pack", AccessLevel: access.ReadWriteAccess, Outcome: Allowed},
+	} {
+		e.Time = now.Add(time.Duration(i) * time.Second)
+		if err := s.Record(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Errorf("audit log mode = %v, want 0600", fi.Mode().Perm())
+	}
+
+	cases := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{Limit: 10}, []string{"alice/repo1", "alice/repo2", "bob/repo1", "alice/repo1"}},
+		{"limit", Filter{Limit: 2}, []string{"alice/repo1", "alice/repo2"}},
+		{"user", Filter{Username: "alice", Limit: 10}, []string{"alice/repo1", "alice/repo2", "alice/repo1"}},
+		{"repo", Filter{Repo: "repo1", Limit: 2}, []string{"alice/repo1", "bob/repo1"}},
+		{"user and repo", Filter{Username: "bob", Repo: "repo2", Limit: 10}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entries, err := s.Query(ctx, c.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Username+"/"+e.Repo)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("Query() = %v, want %v", got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Fatalf("Query() = %v, want %v", got, c.want)
+				}
+			}
+		})
+	}
+
+	// Entries are newest first.
+	entries, err = s.Query(ctx, Filter{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := entries[0]; !e.Time.Equal(now.Add(3*time.Second)) || e.AccessLevel != access.ReadWriteAccess || e.Outcome != Allowed {
+		t.Errorf("Query() newest entry = %+v", e)
+	}
+}
@@ -0,0 +1,66 @@
+// Package auth defines authenticators verifying the passwords of users
+// managed outside of Soft Serve, such as in an LDAP directory.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// ErrInvalidCredentials is returned when a username or password is invalid.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Identity is a user verified by an Authenticator.
+type Identity struct {
+	// Username is the name of the user.
+	Username string
+
+	// Groups are the groups the user belongs to.
+	Groups []string
+}
+
+// Authenticator verifies usernames and passwords.
+type Authenticator interface {
+	// Authenticate returns the identity of username if password is valid.
+	// It returns ErrInvalidCredentials if it isn't.
+	Authenticate(ctx context.Context, username, password string) (*Identity, error)
+}
+
+// Cache is an Authenticator remembering successful authentications for a
+// while, to avoid a round trip to the directory on every request.
+type Cache struct {
+	a     Authenticator
+	cache *expirable.LRU[[sha256.Size]byte, *Identity]
+}
+
+var _ Authenticator = (*Cache)(nil)
+
+// NewCache returns a Cache of up to size authentications of a, each kept for
+// ttl.
+func NewCache(a Authenticator, size int, ttl time.Duration) *Cache {
+	return &Cache{
+		a:     a,
+		cache: expirable.NewLRU[[sha256.Size]byte, *Identity](size, nil, ttl),
+	}
+}
+
+// Authenticate implements Authenticator.
+func (c *Cache) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	// Passwords are only kept hashed.
+	key := sha256.Sum256([]byte(username + "\x00" + password))
+	if id, ok := c.cache.Get(key); ok {
+		return id, nil
+	}
+
+	id, err := c.a.Authenticate(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.Add(key, id)
+	return id, nil
+}
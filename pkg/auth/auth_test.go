@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type countingAuthenticator struct {
+	calls int
+}
+
+func (a *countingAuthenticator) Authenticate(_ context.Context, username, password string) (*Identity, error) {
+	a.calls++
+	if password != "hunter2" {
+		return nil, ErrInvalidCredentials
+	}
+	return &Identity{Username: username}, nil
+}
+
+func TestCache(t *testing.T) {
+	a := &countingAuthenticator{}
+	c := NewCache(a, 10, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Authenticate(context.TODO(), "alice", "hunter2"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.calls != 1 {
+		t.Errorf("successful logins authenticated %d times, want 1", a.calls)
+	}
+
+	// Failures and other passwords aren't cached.
+	for i := 0; i < 2; i++ {
+		if _, err := c.Authenticate(context.TODO(), "alice", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("err = %v, want %v", err, ErrInvalidCredentials)
+		}
+	}
+	if a.calls != 3 {
+		t.Errorf("failed logins authenticated %d times, want 2", a.calls-1)
+	}
+}
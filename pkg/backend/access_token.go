@@ -5,19 +5,37 @@ import (
 	"errors"
 	"time"
 
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/utils"
 )
 
-// CreateAccessToken creates an access token for user.
-func (b *Backend) CreateAccessToken(ctx context.Context, user proto.User, name string, expiresAt time.Time) (string, error) {
+// tokenUser is a user authenticated with an access token, whose access is
+// limited to the token scope.
+type tokenUser struct {
+	proto.User
+	scope access.AccessLevel
+}
+
+// TokenScope returns the scope of the access token user was authenticated
+// with, and false if it wasn't authenticated with a scoped token.
+func TokenScope(user proto.User) (access.AccessLevel, bool) {
+	if tu, ok := user.(*tokenUser); ok {
+		return tu.scope, true
+	}
+	return access.AdminAccess, false
+}
+
+// CreateAccessToken creates an access token for user. The token grants at
+// most scope access to repositories, and never expires if expiresAt is zero.
+func (b *Backend) CreateAccessToken(ctx context.Context, user proto.User, name string, expiresAt time.Time, scope access.AccessLevel) (string, error) {
 	token := GenerateToken()
 	tokenHash := HashToken(token)
 	name = utils.Sanitize(name)
 
 	if err := b.db.TransactionContext(ctx, func(tx *db.Tx) error {
-		_, err := b.store.CreateAccessToken(ctx, tx, name, user.ID(), tokenHash, expiresAt)
+		_, err := b.store.CreateAccessToken(ctx, tx, name, user.ID(), tokenHash, expiresAt, scope)
 		if err != nil {
 			return db.WrapError(err)
 		}
@@ -67,6 +85,7 @@ func (b *Backend) ListAccessTokens(ctx context.Context, user proto.User) ([]prot
 			Name:      t.Name,
 			TokenHash: t.Token,
 			UserID:    t.UserID,
+			Scope:     t.Scope,
 			CreatedAt: t.CreatedAt,
 		}
 		if t.ExpiresAt.Valid {
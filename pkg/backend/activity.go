@@ -0,0 +1,206 @@
+package backend
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/audit"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// activityHistorySize is the number of recent activity events kept in
+// memory.
+const activityHistorySize = 100
+
+var activityDroppedCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "activity",
+	Name:      "dropped_events_total",
+	Help:      "The total number of activity events dropped for slow subscribers",
+})
+
+// ActivityKind is the kind of an activity event.
+type ActivityKind string
+
+const (
+	// ActivityStart is the kind of the events of git operations starting.
+	ActivityStart ActivityKind = "start"
+
+	// ActivityFinish is the kind of the events of git operations finishing.
+	ActivityFinish ActivityKind = "finish"
+
+	// ActivityDenied is the kind of the events of git operations refused by
+	// access control.
+	ActivityDenied ActivityKind = "denied"
+)
+
+// ActivityEvent is a git operation starting, finishing, or being denied.
+type ActivityEvent struct {
+	// Time is when the event happened.
+	Time time.Time
+
+	// Kind is the kind of the event.
+	Kind ActivityKind
+
+	// Username is the user of the operation, it's empty for anonymous
+	// users.
+	Username string
+
+	// Repo is the repository of the operation.
+	Repo string
+
+	// Service is the git service of the operation, e.g. "git-upload-pack".
+	Service string
+
+	// Transport is the transport of the operation, "ssh", "http", or "git".
+	Transport string
+
+	// RemoteAddr is the address of the client.
+	RemoteAddr string
+
+	// Duration is how long a finished operation took.
+	Duration time.Duration
+
+	// Result is the result of a finished operation, e.g. "success" or
+	// "error".
+	Result string
+}
+
+// ActivitySubscription receives the activity events published after it was
+// made. See [Backend.SubscribeActivity].
+type ActivitySubscription struct {
+	feed    *activityFeed
+	events  chan ActivityEvent
+	dropped atomic.Int64
+}
+
+// Events returns the channel the events are sent to. It's closed once the
+// subscription is closed.
+func (s *ActivitySubscription) Events() <-chan ActivityEvent {
+	return s.events
+}
+
+// Dropped returns the number of events dropped because the subscriber
+// didn't keep up.
+func (s *ActivitySubscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close stops the subscription.
+func (s *ActivitySubscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	if _, ok := s.feed.subs[s]; ok {
+		delete(s.feed.subs, s)
+		close(s.events)
+	}
+}
+
+// activityFeed fans the activity events out to the subscriptions, and keeps
+// the most recent ones.
+type activityFeed struct {
+	mu      sync.Mutex
+	subs    map[*ActivitySubscription]struct{}
+	history []ActivityEvent
+}
+
+// newActivityFeed returns a new activity feed.
+func newActivityFeed() *activityFeed {
+	return &activityFeed{
+		subs: make(map[*ActivitySubscription]struct{}),
+	}
+}
+
+// publish sends an event to the subscriptions. Subscriptions whose buffer
+// is full miss it, git operations are never blocked by slow subscribers.
+func (f *activityFeed) publish(e ActivityEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.history) == activityHistorySize {
+		f.history = f.history[1:]
+	}
+	f.history = append(f.history, e)
+
+	for s := range f.subs {
+		select {
+		case s.events <- e:
+		default:
+			s.dropped.Add(1)
+			activityDroppedCounter.Inc()
+		}
+	}
+}
+
+// SubscribeActivity returns the last events of the activity feed, oldest
+// first, and a subscription buffering up to size of the events published
+// from then on. The subscription must be closed once done with.
+func (d *Backend) SubscribeActivity(size int) ([]ActivityEvent, *ActivitySubscription) {
+	s := &ActivitySubscription{
+		feed:   d.activity,
+		events: make(chan ActivityEvent, size),
+	}
+
+	d.activity.mu.Lock()
+	defer d.activity.mu.Unlock()
+
+	d.activity.subs[s] = struct{}{}
+	return append([]ActivityEvent(nil), d.activity.history...), s
+}
+
+// TrackActivity publishes the start and finish of the git service run by
+// scmd to the activity feed, along with the callbacks scmd already has. The
+// source of the operation is read from the context, see [audit.WithSource].
+func (d *Backend) TrackActivity(ctx context.Context, scmd *git.ServiceCommand, repo string, user proto.User, service string) {
+	e := d.activityEvent(ctx, repo, user, service)
+
+	onStart := scmd.OnStart
+	scmd.OnStart = func() {
+		if onStart != nil {
+			onStart()
+		}
+
+		e := e
+		e.Time = time.Now().UTC()
+		e.Kind = ActivityStart
+		d.activity.publish(e)
+	}
+
+	onComplete := scmd.OnComplete
+	scmd.OnComplete = func(stats git.ServiceStats) {
+		if onComplete != nil {
+			onComplete(stats)
+		}
+
+		e := e
+		e.Time = time.Now().UTC()
+		e.Kind = ActivityFinish
+		e.Duration = stats.Duration
+		e.Result = git.ServiceResult(stats.Err)
+		d.activity.publish(e)
+	}
+}
+
+// activityEvent returns an activity event of an operation, without a time
+// or a kind.
+func (d *Backend) activityEvent(ctx context.Context, repo string, user proto.User, service string) ActivityEvent {
+	src := audit.SourceFromContext(ctx)
+	e := ActivityEvent{
+		Repo:       utils.SanitizeRepo(repo),
+		Service:    service,
+		Transport:  src.Transport,
+		RemoteAddr: src.RemoteAddr,
+	}
+	if user != nil {
+		e.Username = user.Username()
+	}
+
+	return e
+}
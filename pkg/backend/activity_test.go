@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/audit"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+)
+
+func TestActivityFeed(t *testing.T) {
+	d := &Backend{activity: newActivityFeed()}
+	ctx := audit.WithSource(context.Background(), audit.Source{Transport: "ssh", RemoteAddr: "127.0.0.1:1234"})
+
+	var observed bool
+	scmd := git.ServiceCommand{
+		OnComplete: func(git.ServiceStats) { observed = true },
+	}
+	d.TrackActivity(ctx, &scmd, "/repo1.git", nil, "git-upload-pack")
+	scmd.OnStart()
+
+	history, sub := d.SubscribeActivity(1)
+	defer sub.Close()
+	if len(history) != 1 || history[0].Kind != ActivityStart || history[0].Repo != "repo1" || history[0].Transport != "ssh" {
+		t.Fatalf("history = %+v, want the start of the operation", history)
+	}
+
+	// The subscriber is slow, git operations don't wait for it.
+	scmd.OnComplete(git.ServiceStats{})
+	scmd.OnComplete(git.ServiceStats{})
+	if !observed {
+		t.Error("the callback of the command wasn't called")
+	}
+	if e := <-sub.Events(); e.Kind != ActivityFinish || e.Result != "success" {
+		t.Errorf("event = %+v, want a successful finish", e)
+	}
+	if n := sub.Dropped(); n != 1 {
+		t.Errorf("Dropped() = %d, want 1", n)
+	}
+
+	sub.Close()
+	sub.Close() // closing twice is a no-op
+	if _, ok := <-sub.Events(); ok {
+		t.Error("the events of a closed subscription are still sent")
+	}
+
+	for range activityHistorySize {
+		d.activity.publish(ActivityEvent{Kind: ActivityDenied})
+	}
+	history, sub = d.SubscribeActivity(0)
+	defer sub.Close()
+	if len(history) != activityHistorySize || history[0].Kind != ActivityDenied {
+		t.Errorf("history has %d events, want the last %d", len(history), activityHistorySize)
+	}
+}
@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/audit"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// ErrAuditDisabled is returned when querying the audit log while it's
+// disabled.
+var ErrAuditDisabled = errors.New("audit log is disabled")
+
+// Authorize resolves the access level of a user for a git service on a
+// repository, and records the decision in the audit log. The service is
+// allowed when the user has at least the required access level, denials are
+// published to the activity feed too.
+//
+// The source of the operation is read from the context, see
+// [audit.WithSource].
+func (d *Backend) Authorize(ctx context.Context, repo string, user proto.User, service string, required access.AccessLevel) (access.AccessLevel, bool) {
+	level := d.AccessLevelForUser(ctx, repo, user)
+	allowed := level >= required
+	if !allowed {
+		e := d.activityEvent(ctx, repo, user, service)
+		e.Time = time.Now().UTC()
+		e.Kind = ActivityDenied
+		d.activity.publish(e)
+	}
+	if d.audit == nil {
+		return level, allowed
+	}
+
+	src := audit.SourceFromContext(ctx)
+	e := audit.Entry{
+		Time:        time.Now().UTC(),
+		Repo:        utils.SanitizeRepo(repo),
+		Service:     service,
+		Transport:   src.Transport,
+		AccessLevel: level,
+		RemoteAddr:  src.RemoteAddr,
+		Outcome:     audit.Denied,
+	}
+	if user != nil {
+		e.Username = user.Username()
+	}
+	if allowed {
+		e.Outcome = audit.Allowed
+	}
+
+	if err := d.audit.Record(ctx, e); err != nil {
+		d.logger.Error("error recording audit entry", "err", err, "repo", e.Repo, "username", e.Username)
+	}
+
+	return level, allowed
+}
+
+// AuditEntries returns the most recent entries of the audit log.
+func (d *Backend) AuditEntries(ctx context.Context, f audit.Filter) ([]audit.Entry, error) {
+	if d.audit == nil {
+		return nil, ErrAuditDisabled
+	}
+
+	return d.audit.Query(ctx, f)
+}
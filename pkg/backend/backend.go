@@ -2,12 +2,20 @@ package backend
 
 import (
 	"context"
+	"time"
 
 	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/audit"
+	"github.com/charmbracelet/soft-serve/pkg/auth"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/ldap"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
 	"github.com/charmbracelet/soft-serve/pkg/store"
 	"github.com/charmbracelet/soft-serve/pkg/task"
+	lru "github.com/hashicorp/golang-lru/v2"
 )
 
 // Backend is the Soft Serve backend that handles users, repositories, and
@@ -20,23 +28,63 @@ type Backend struct {
 	logger  *log.Logger
 	cache   *cache
 	manager *task.Manager
+
+	// ops keeps track of the git operations running against repositories.
+	ops *repoOps
+
+	// limits caps the number of git operations running concurrently.
+	limits *opLimiter
+
+	// blames caches file blames by repository, commit, and path.
+	blames *lru.Cache[string, []git.BlameLine]
+
+	// certChecker verifies SSH user certificates and revoked keys, it's nil
+	// if neither is configured.
+	certChecker *sshutils.CertChecker
+
+	// authenticator verifies directory passwords, it's nil if no directory
+	// is configured.
+	authenticator auth.Authenticator
+	groupAccess   map[string]access.AccessLevel
+
+	// audit records access-control decisions, it's nil if the audit log is
+	// disabled.
+	audit audit.Sink
+
+	// activity fans the git operations out to the activity subscribers.
+	activity *activityFeed
 }
 
 // New returns a new Soft Serve backend.
 func New(ctx context.Context, cfg *config.Config, db *db.DB, st store.Store) *Backend {
 	logger := log.FromContext(ctx).WithPrefix("backend")
 	b := &Backend{
-		ctx:     ctx,
-		cfg:     cfg,
-		db:      db,
-		store:   st,
-		logger:  logger,
-		manager: task.NewManager(ctx),
+		ctx:      ctx,
+		cfg:      cfg,
+		db:       db,
+		store:    st,
+		logger:   logger,
+		manager:  task.NewManager(ctx),
+		ops:      newRepoOps(),
+		limits:   newOpLimiter(cfg.Repo),
+		audit:    audit.NewSink(cfg, db, st),
+		activity: newActivityFeed(),
+	}
+
+	if cfg.SSH.TrustedUserCAKeys != "" || cfg.SSH.RevokedKeys != "" {
+		b.certChecker = sshutils.NewCertChecker(cfg.SSH.TrustedUserCAKeys, cfg.SSH.RevokedKeys)
+	}
+
+	if cfg.LDAP.URL != "" {
+		ttl := time.Duration(cfg.LDAP.CacheTTL) * time.Second
+		b.SetAuthenticator(auth.NewCache(ldap.NewAuthenticator(cfg.LDAP), 1000, ttl))
+		b.groupAccess, _ = cfg.LDAP.GroupAccessLevels()
 	}
 
 	// TODO: implement a proper caching interface
 	cache := newCache(b, 1000)
 	b.cache = cache
+	b.blames, _ = lru.New[string, []git.BlameLine](blameCacheSize)
 
 	return b
 }
@@ -0,0 +1,34 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// blameCacheSize is the number of blamed files kept in memory.
+const blameCacheSize = 256
+
+// Blame returns the lines of a file at a commit annotated with the commits
+// that last changed them. Blaming is expensive, so results are cached per
+// file and commit.
+func (d *Backend) Blame(_ context.Context, repo proto.Repository, commit string, path string) ([]git.BlameLine, error) {
+	key := repo.Name() + "@" + commit + ":" + path
+	if lines, ok := d.blames.Get(key); ok {
+		return lines, nil
+	}
+
+	r, err := repo.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := r.Blame(commit, path)
+	if err != nil {
+		return nil, err
+	}
+
+	d.blames.Add(key, lines)
+	return lines, nil
+}
@@ -0,0 +1,134 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// AddBranchProtection protects the branches of a repository matching the
+// glob pattern. Only users with at least level can push to them, and only
+// fast-forwards unless allowForce is set.
+func (d *Backend) AddBranchProtection(ctx context.Context, repo string, pattern string, level access.AccessLevel, allowForce bool) error {
+	pattern = strings.TrimPrefix(pattern, "refs/heads/")
+	if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+		return proto.ErrInvalidBranchPattern
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.AddBranchProtectionByRepo(ctx, tx, repo, pattern, level, allowForce)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrBranchProtectionExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// RemoveBranchProtection removes the branch protection rule of pattern from
+// a repository.
+func (d *Backend) RemoveBranchProtection(ctx context.Context, repo string, pattern string) error {
+	repo = utils.SanitizeRepo(repo)
+	pattern = strings.TrimPrefix(pattern, "refs/heads/")
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.RemoveBranchProtectionByRepo(ctx, tx, repo, pattern)
+		}),
+	)
+}
+
+// BranchProtections returns the branch protection rules of a repository.
+func (d *Backend) BranchProtections(ctx context.Context, repo string) ([]models.BranchProtection, error) {
+	repo = utils.SanitizeRepo(repo)
+	var rules []models.BranchProtection
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		rules, err = d.store.ListBranchProtectionsByRepo(ctx, tx, repo)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return rules, nil
+}
+
+// CheckBranchProtections returns an error if a user with the access level
+// isn't allowed to make the ref updates in args: updating a protected branch
+// without the access level required by its rules, or deleting or force
+// pushing it when it isn't allowed. A branch matching several rules must
+// satisfy all of them.
+func (d *Backend) CheckBranchProtections(ctx context.Context, repo string, level access.AccessLevel, args []hooks.HookArg) error {
+	rules, err := d.BranchProtections(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	rp := d.repoPath(repo)
+	for _, arg := range args {
+		branch, ok := strings.CutPrefix(arg.RefName, "refs/heads/")
+		if !ok {
+			continue
+		}
+
+		matched, allowForce := false, true
+		required := access.NoAccess
+		for _, r := range rules {
+			if m, _ := path.Match(r.Pattern, branch); m {
+				matched = true
+				required = max(required, r.AccessLevel)
+				allowForce = allowForce && r.AllowForce
+			}
+		}
+
+		if !matched {
+			continue
+		}
+
+		if level < required {
+			return fmt.Errorf("%s: %w: pushing requires %s, you have %s", arg.RefName, git.ErrProtectedBranch, required, level)
+		}
+
+		if allowForce || gitb.IsZeroHash(arg.OldSha) {
+			continue
+		}
+
+		if gitb.IsZeroHash(arg.NewSha) {
+			return fmt.Errorf("%s: %w: it can't be deleted", arg.RefName, git.ErrProtectedBranch)
+		}
+
+		ff, err := git.IsAncestor(ctx, rp, arg.OldSha, arg.NewSha)
+		if err != nil {
+			return err
+		}
+		if !ff {
+			return fmt.Errorf("%s: %w: force pushes aren't allowed", arg.RefName, git.ErrProtectedBranch)
+		}
+	}
+
+	return nil
+}
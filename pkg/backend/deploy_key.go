@@ -0,0 +1,171 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+var (
+	// ErrInvalidDeployKeyAccess is returned when adding a deploy key with an
+	// access level other than read-only or read-write.
+	ErrInvalidDeployKeyAccess = errors.New("deploy keys can only have read-only or read-write access")
+	// ErrInvalidDeployKeyTitle is returned when adding a deploy key with an
+	// invalid title.
+	ErrInvalidDeployKeyTitle = errors.New("deploy key title must not be empty or contain spaces")
+)
+
+// deployKeyUser is a connection authenticated with a deploy key. It isn't a
+// user of the server, its access is limited to the repository of the key.
+type deployKeyUser struct {
+	key models.DeployKey
+	pk  gossh.PublicKey
+}
+
+var _ proto.User = (*deployKeyUser)(nil)
+
+// ID implements proto.User. Deploy keys don't have a user ID, and never
+// match the owner of a repository.
+func (u *deployKeyUser) ID() int64 {
+	return -1
+}
+
+// Username implements proto.User.
+func (u *deployKeyUser) Username() string {
+	return "deploy-key:" + u.key.Title
+}
+
+// IsAdmin implements proto.User.
+func (u *deployKeyUser) IsAdmin() bool {
+	return false
+}
+
+// PublicKeys implements proto.User.
+func (u *deployKeyUser) PublicKeys() []gossh.PublicKey {
+	return []gossh.PublicKey{u.pk}
+}
+
+// Password implements proto.User.
+func (u *deployKeyUser) Password() string {
+	return ""
+}
+
+// Email implements proto.User.
+func (u *deployKeyUser) Email() string {
+	return ""
+}
+
+// AddDeployKey adds a deploy key to a repository. The key authenticates SSH
+// connections with level access to the repository, and no access to other
+// repositories.
+func (d *Backend) AddDeployKey(ctx context.Context, repo string, title string, pk gossh.PublicKey, level access.AccessLevel) error {
+	if title == "" || strings.ContainsAny(title, " \t\r\n") {
+		return ErrInvalidDeployKeyTitle
+	}
+	if level != access.ReadOnlyAccess && level != access.ReadWriteAccess {
+		return ErrInvalidDeployKeyAccess
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			// Users' keys authenticate as the user, they can't be deploy keys.
+			if _, err := d.store.FindUserByPublicKey(ctx, tx, pk); err == nil {
+				return proto.ErrPublicKeyInUse
+			} else if !errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+				return err
+			}
+
+			return d.store.AddDeployKeyByRepo(ctx, tx, repo, title, sshutils.MarshalAuthorizedKey(pk), level)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrDeployKeyExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// RemoveDeployKey removes the deploy key with a title from a repository.
+func (d *Backend) RemoveDeployKey(ctx context.Context, repo string, title string) error {
+	repo = utils.SanitizeRepo(repo)
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			keys, err := d.store.ListDeployKeysByRepo(ctx, tx, repo)
+			if err != nil {
+				return err
+			}
+
+			for _, k := range keys {
+				if k.Title == title {
+					return d.store.RemoveDeployKeyByRepo(ctx, tx, repo, title)
+				}
+			}
+
+			return proto.ErrDeployKeyNotFound
+		}),
+	)
+}
+
+// DeployKeys returns the deploy keys of a repository.
+func (d *Backend) DeployKeys(ctx context.Context, repo string) ([]models.DeployKey, error) {
+	repo = utils.SanitizeRepo(repo)
+	var keys []models.DeployKey
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		keys, err = d.store.ListDeployKeysByRepo(ctx, tx, repo)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return keys, nil
+}
+
+// deployKeyByPublicKey returns the deploy key user of pk.
+func (d *Backend) deployKeyByPublicKey(ctx context.Context, pk gossh.PublicKey) (proto.User, error) {
+	var m models.DeployKey
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.FindDeployKeyByPublicKey(ctx, tx, sshutils.MarshalAuthorizedKey(pk))
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, proto.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &deployKeyUser{key: m, pk: pk}, nil
+}
+
+// deployKeyAccess returns the access level of a deploy key for a repository,
+// the level of the key for its repository and no access otherwise.
+func (d *Backend) deployKeyAccess(ctx context.Context, repo string, dk *deployKeyUser) AccessExplanation {
+	r := proto.RepositoryFromContext(ctx)
+	if r == nil || r.Name() != utils.SanitizeRepo(repo) {
+		r, _ = d.Repository(ctx, repo)
+	}
+
+	if r == nil || r.ID() != dk.key.RepoID {
+		return newAccessExplanation(access.NoAccess, "deploy key of another repository")
+	}
+
+	return newAccessExplanation(dk.key.AccessLevel, "deploy key")
+}
@@ -0,0 +1,82 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/auth"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// ErrNoAuthenticator is returned when no directory is configured.
+var ErrNoAuthenticator = errors.New("no directory authenticator")
+
+// directoryUser is a user authenticated with a directory password, along
+// with the access level granted to their groups.
+type directoryUser struct {
+	proto.User
+	access access.AccessLevel
+}
+
+// WithDirectoryAccess returns user with the access level of their directory
+// groups on all repositories.
+func WithDirectoryAccess(user proto.User, level access.AccessLevel) proto.User {
+	if du, ok := user.(*directoryUser); ok {
+		user = du.User
+	}
+	return &directoryUser{User: user, access: level}
+}
+
+// DirectoryAccess returns the access level of the directory groups of user.
+func DirectoryAccess(user proto.User) access.AccessLevel {
+	if du, ok := user.(*directoryUser); ok {
+		return du.access
+	}
+	return access.NoAccess
+}
+
+// SetAuthenticator sets the authenticator verifying directory passwords.
+func (d *Backend) SetAuthenticator(a auth.Authenticator) {
+	d.authenticator = a
+}
+
+// HasAuthenticator reports whether a directory authenticator is set.
+func (d *Backend) HasAuthenticator() bool {
+	return d.authenticator != nil
+}
+
+// AuthenticatePassword verifies the directory password of username and
+// returns the user, with the access level of their groups. Users are created
+// on their first login if allowed.
+func (d *Backend) AuthenticatePassword(ctx context.Context, username, password string) (proto.User, error) {
+	if d.authenticator == nil {
+		return nil, ErrNoAuthenticator
+	}
+
+	id, err := d.authenticator.Authenticate(ctx, strings.ToLower(username), password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := d.User(ctx, id.Username)
+	if errors.Is(err, proto.ErrUserNotFound) && d.cfg.LDAP.CreateUsers {
+		user, err = d.CreateUser(ctx, id.Username, proto.UserOptions{})
+		if err == nil {
+			d.logger.Info("created user on directory login", "username", user.Username())
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	level := access.NoAccess
+	for _, g := range id.Groups {
+		if l, ok := d.groupAccess[strings.ToLower(g)]; ok && l > level {
+			level = l
+		}
+	}
+
+	return WithDirectoryAccess(user, level), nil
+}
@@ -0,0 +1,136 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+var (
+	// ErrInvalidHookEnvName is returned when setting a hook environment
+	// variable with an invalid name.
+	ErrInvalidHookEnvName = errors.New("hook environment variable names must be letters, digits, and underscores, and not start with a digit")
+	// ErrReservedHookEnvName is returned when setting a hook environment
+	// variable that Soft Serve or git sets.
+	ErrReservedHookEnvName = errors.New("SOFT_SERVE_* and GIT_* hook environment variables are reserved")
+	// ErrHookEnvNotFound is returned when removing a hook environment
+	// variable that doesn't exist.
+	ErrHookEnvNotFound = errors.New("hook environment variable not found")
+)
+
+var hookEnvNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// reservedHookEnvPrefixes are the prefixes of the variables set by Soft Serve
+// and git, which repositories can't override.
+var reservedHookEnvPrefixes = []string{"SOFT_SERVE_", "GIT_"}
+
+// ValidateHookEnvName returns an error if name can't be the name of a hook
+// environment variable.
+func ValidateHookEnvName(name string) error {
+	if !hookEnvNameRe.MatchString(name) {
+		return ErrInvalidHookEnvName
+	}
+	for _, p := range reservedHookEnvPrefixes {
+		if strings.HasPrefix(strings.ToUpper(name), p) {
+			return ErrReservedHookEnvName
+		}
+	}
+
+	return nil
+}
+
+// SetHookEnv sets an environment variable passed to the custom hooks of a
+// repository. The value is encrypted in the database. Secret values aren't
+// shown when listing the variables.
+func (d *Backend) SetHookEnv(ctx context.Context, repo string, name string, value string, secret bool) error {
+	if err := ValidateHookEnvName(name); err != nil {
+		return err
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	key, err := d.secretKey(true)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encryptSecret(key, name, value)
+	if err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.SetHookEnvByRepo(ctx, tx, repo, name, encrypted, secret)
+		}),
+	)
+}
+
+// RemoveHookEnv removes an environment variable passed to the custom hooks of
+// a repository.
+func (d *Backend) RemoveHookEnv(ctx context.Context, repo string, name string) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			vars, err := d.store.ListHookEnvByRepo(ctx, tx, repo)
+			if err != nil {
+				return err
+			}
+			for _, v := range vars {
+				if v.Name == name {
+					return d.store.RemoveHookEnvByRepo(ctx, tx, repo, name)
+				}
+			}
+
+			return ErrHookEnvNotFound
+		}),
+	)
+}
+
+// HookEnv returns the environment variables passed to the custom hooks of a
+// repository, with their values decrypted.
+func (d *Backend) HookEnv(ctx context.Context, repo string) ([]models.RepoHookEnv, error) {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	var vars []models.RepoHookEnv
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			var err error
+			vars, err = d.store.ListHookEnvByRepo(ctx, tx, repo)
+			return err
+		}),
+	); err != nil {
+		return nil, err
+	}
+	if len(vars) == 0 {
+		return vars, nil
+	}
+
+	key, err := d.secretKey(false)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range vars {
+		vars[i].Value, err = decryptSecret(key, v.Name, v.Value)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt hook environment variable %s: %w", v.Name, err)
+		}
+	}
+
+	return vars, nil
+}
@@ -0,0 +1,28 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateHookEnvName(t *testing.T) {
+	for _, name := range []string{"DEPLOY_TOKEN", "_env", "Env2"} {
+		if err := ValidateHookEnvName(name); err != nil {
+			t.Errorf("ValidateHookEnvName(%q): %v", name, err)
+		}
+	}
+
+	for name, want := range map[string]error{
+		"":                     ErrInvalidHookEnvName,
+		"2FA":                  ErrInvalidHookEnvName,
+		"DEPLOY-TOKEN":         ErrInvalidHookEnvName,
+		"A=B":                  ErrInvalidHookEnvName,
+		"SOFT_SERVE_REPO_NAME": ErrReservedHookEnvName,
+		"soft_serve_pusher":    ErrReservedHookEnvName,
+		"GIT_DIR":              ErrReservedHookEnvName,
+	} {
+		if err := ValidateHookEnvName(name); !errors.Is(err, want) {
+			t.Errorf("ValidateHookEnvName(%q) = %v, want %v", name, err, want)
+		}
+	}
+}
@@ -2,11 +2,13 @@ package backend
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"sync"
 
 	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/hooks"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/sshutils"
@@ -18,72 +20,122 @@ var _ hooks.Hooks = (*Backend)(nil)
 // PostReceive is called by the git post-receive hook.
 //
 // It implements Hooks.
-func (d *Backend) PostReceive(_ context.Context, _ io.Writer, _ io.Writer, repo string, args []hooks.HookArg) {
-	d.logger.Debug("post-receive hook called", "repo", repo, "args", args)
+func (d *Backend) PostReceive(ctx context.Context, _ io.Writer, _ io.Writer, repo string, args []hooks.HookArg) {
+	opts := hooks.PushOptionsFromContext(ctx)
+	d.logger.Debug("post-receive hook called", "repo", repo, "args", args, "push-options", opts)
+
+	// Get repo
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		d.logger.Error("error finding repository", "repo", repo, "err", err)
+		return
+	}
+
+	// Pushes are recorded whatever the push options, the activity log is
+	// meant for reviewing who changed which refs.
+	if err := d.recordPush(ctx, r, args); err != nil {
+		d.logger.Error("error recording push", "repo", repo, "err", err)
+	}
+
+	// Webhooks are sent after the refs are updated, as the update hook doesn't
+	// get the push options.
+	if !opts.Bool(hooks.PushOptionNotify, true) {
+		return
+	}
+
+	user, err := d.HookUser(ctx)
+	if err != nil {
+		d.logger.Error("error finding user", "err", err)
+		return
+	}
+
+	// TODO: run this async
+	// This would probably need something like an RPC server to communicate with the hook process.
+	for _, arg := range args {
+		if git.IsZeroHash(arg.OldSha) || git.IsZeroHash(arg.NewSha) {
+			wh, err := webhook.NewBranchTagEvent(ctx, user, r, arg.RefName, arg.OldSha, arg.NewSha)
+			if err != nil {
+				d.logger.Error("error creating branch_tag webhook", "err", err)
+			} else if err := webhook.SendEvent(ctx, wh); err != nil {
+				d.logger.Error("error sending branch_tag webhook", "err", err)
+			}
+		}
+		wh, err := webhook.NewPushEvent(ctx, user, r, arg.RefName, arg.OldSha, arg.NewSha)
+		if err != nil {
+			d.logger.Error("error creating push webhook", "err", err)
+			continue
+		}
+		wh.PushOptions = opts
+		if err := webhook.SendEvent(ctx, wh); err != nil {
+			d.logger.Error("error sending push webhook", "err", err)
+		}
+	}
 }
 
-// PreReceive is called by the git pre-receive hook.
+// PreReceive is called by the git pre-receive hook. It enforces the
+// repository push policies.
 //
 // It implements Hooks.
-func (d *Backend) PreReceive(_ context.Context, _ io.Writer, _ io.Writer, repo string, args []hooks.HookArg) {
+func (d *Backend) PreReceive(ctx context.Context, _ io.Writer, _ io.Writer, repo string, args []hooks.HookArg) error {
 	d.logger.Debug("pre-receive hook called", "repo", repo, "args", args)
+
+	// Pushes without a known user, such as anonymous ones, get the anonymous
+	// access level.
+	user, _ := d.HookUser(ctx)
+	if err := d.CheckBranchProtections(ctx, repo, d.HookAccessLevel(ctx, repo, user), args); err != nil {
+		return err
+	}
+
+	if err := d.CheckTagProtections(ctx, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkSignedCommits(ctx, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkCommitMessages(ctx, repo, args); err != nil {
+		return err
+	}
+
+	return d.checkLockedPaths(ctx, repo, args)
 }
 
 // Update is called by the git update hook.
 //
 // It implements Hooks.
-func (d *Backend) Update(ctx context.Context, _ io.Writer, _ io.Writer, repo string, arg hooks.HookArg) {
+func (d *Backend) Update(_ context.Context, _ io.Writer, _ io.Writer, repo string, arg hooks.HookArg) {
 	d.logger.Debug("update hook called", "repo", repo, "arg", arg)
+}
 
-	// Find user
-	var user proto.User
+// HookUser returns the user running the hook from the environment set by the
+// git service.
+func (d *Backend) HookUser(ctx context.Context) (proto.User, error) {
 	if pubkey := os.Getenv("SOFT_SERVE_PUBLIC_KEY"); pubkey != "" {
 		pk, _, err := sshutils.ParseAuthorizedKey(pubkey)
 		if err != nil {
-			d.logger.Error("error parsing public key", "err", err)
-			return
+			return nil, fmt.Errorf("parse public key: %w", err)
 		}
 
-		user, err = d.UserByPublicKey(ctx, pk)
-		if err != nil {
-			d.logger.Error("error finding user from public key", "key", pubkey, "err", err)
-			return
-		}
+		return d.UserByPublicKey(ctx, pk)
 	} else if username := os.Getenv("SOFT_SERVE_USERNAME"); username != "" {
-		var err error
-		user, err = d.User(ctx, username)
-		if err != nil {
-			d.logger.Error("error finding user from username", "username", username, "err", err)
-			return
-		}
-	} else {
-		d.logger.Error("error finding user")
-		return
+		return d.User(ctx, username)
 	}
 
-	// Get repo
-	r, err := d.Repository(ctx, repo)
-	if err != nil {
-		d.logger.Error("error finding repository", "repo", repo, "err", err)
-		return
-	}
+	return nil, proto.ErrUserNotFound
+}
 
-	// TODO: run this async
-	// This would probably need something like an RPC server to communicate with the hook process.
-	if git.IsZeroHash(arg.OldSha) || git.IsZeroHash(arg.NewSha) {
-		wh, err := webhook.NewBranchTagEvent(ctx, user, r, arg.RefName, arg.OldSha, arg.NewSha)
-		if err != nil {
-			d.logger.Error("error creating branch_tag webhook", "err", err)
-		} else if err := webhook.SendEvent(ctx, wh); err != nil {
-			d.logger.Error("error sending branch_tag webhook", "err", err)
-		}
-	}
-	wh, err := webhook.NewPushEvent(ctx, user, r, arg.RefName, arg.OldSha, arg.NewSha)
-	if err != nil {
-		d.logger.Error("error creating push webhook", "err", err)
-	} else if err := webhook.SendEvent(ctx, wh); err != nil {
-		d.logger.Error("error sending push webhook", "err", err)
+// HookAccessLevel returns the access level of the user running the hook. It's
+// the one the git service authorized, read from SOFT_SERVE_ACCESS_LEVEL, as
+// the user found by HookUser lacks the scope of the access token or the
+// directory groups it logged in with. Hooks run without it, such as pushes
+// to the repositories on disk, get the access level of user.
+func (d *Backend) HookAccessLevel(ctx context.Context, repo string, user proto.User) access.AccessLevel {
+	if level := access.ParseAccessLevel(os.Getenv("SOFT_SERVE_ACCESS_LEVEL")); level >= 0 {
+		return level
 	}
+
+	return d.AccessLevelForUser(ctx, repo, user)
 }
 
 // PostUpdate is called by the git post-update hook.
@@ -104,6 +156,15 @@ func (d *Backend) PostUpdate(ctx context.Context, _ io.Writer, _ io.Writer, repo
 		}
 	}()
 
+	// Record the push and refresh the repository stats.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := d.repoPushed(ctx, repo); err != nil {
+			d.logger.Error("error updating repository stats", "repo", repo, "err", err)
+		}
+	}()
+
 	wg.Wait()
 }
 
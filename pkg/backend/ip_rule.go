@@ -0,0 +1,220 @@
+package backend
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/audit"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// AddIPRule allows or denies clients connecting from an address range, given
+// as an IP address or a CIDR range. The rule applies to every connection when
+// username is empty, otherwise to the connections of the user.
+func (d *Backend) AddIPRule(ctx context.Context, username string, cidr string, allow bool) error {
+	prefix, err := ParseIPRange(cidr)
+	if err != nil {
+		return err
+	}
+
+	userID, err := d.ipRuleUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			rules, err := d.store.GetIPRules(ctx, tx, userID)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range rules {
+				if r.CIDR == prefix.String() {
+					return proto.ErrIPRuleExist
+				}
+			}
+
+			return d.store.CreateIPRule(ctx, tx, userID, prefix.String(), allow)
+		}),
+	)
+}
+
+// RemoveIPRule removes the rule of an address range, from the server-wide
+// rules when username is empty.
+func (d *Backend) RemoveIPRule(ctx context.Context, username string, cidr string) error {
+	prefix, err := ParseIPRange(cidr)
+	if err != nil {
+		return err
+	}
+
+	userID, err := d.ipRuleUserID(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			rules, err := d.store.GetIPRules(ctx, tx, userID)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range rules {
+				if r.CIDR == prefix.String() {
+					return d.store.DeleteIPRule(ctx, tx, userID, r.CIDR)
+				}
+			}
+
+			return proto.ErrIPRuleNotFound
+		}),
+	)
+}
+
+// IPRules returns the rules of a user, or the server-wide rules when
+// username is empty.
+func (d *Backend) IPRules(ctx context.Context, username string) ([]models.IPRule, error) {
+	userID, err := d.ipRuleUserID(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []models.IPRule
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		rules, err = d.store.GetIPRules(ctx, tx, userID)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return rules, nil
+}
+
+// CheckRemoteAddr returns [proto.ErrAddrDenied] if a client connecting from
+// addr isn't allowed by the server-wide rules, or by the rules of user when
+// it isn't nil. A client is denied when its address matches a deny rule, or
+// when there are allow rules and it matches none of them. Denials are
+// recorded in the audit log, and clients are denied when the rules can't be
+// read.
+//
+// The transport of the connection is read from the context, see
+// [audit.WithSource].
+func (d *Backend) CheckRemoteAddr(ctx context.Context, addr string, user proto.User) error {
+	var global, own []models.IPRule
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		global, err = d.store.GetIPRules(ctx, tx, 0)
+		if err != nil || user == nil {
+			return err
+		}
+
+		own, err = d.store.GetIPRules(ctx, tx, user.ID())
+		return err
+	}); err != nil {
+		d.logger.Error("error getting ip rules", "err", err)
+		return proto.ErrAddrDenied
+	}
+
+	if len(global) == 0 && len(own) == 0 {
+		return nil
+	}
+
+	ip, err := parseRemoteAddr(addr)
+	if err == nil && ipRulesAllow(global, ip) && ipRulesAllow(own, ip) {
+		return nil
+	}
+
+	if d.audit != nil {
+		e := audit.Entry{
+			Time:        time.Now().UTC(),
+			Service:     "auth",
+			Transport:   audit.SourceFromContext(ctx).Transport,
+			AccessLevel: access.NoAccess,
+			RemoteAddr:  addr,
+			Outcome:     audit.Denied,
+		}
+		if user != nil {
+			e.Username = user.Username()
+		}
+		if err := d.audit.Record(ctx, e); err != nil {
+			d.logger.Error("error recording audit entry", "err", err, "username", e.Username)
+		}
+	}
+
+	return proto.ErrAddrDenied
+}
+
+// ParseIPRange parses an IP address or a CIDR range. An address is a range
+// of itself.
+func ParseIPRange(s string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), nil
+	}
+
+	ip, err := netip.ParseAddr(s)
+	if err != nil || ip.Zone() != "" {
+		return netip.Prefix{}, proto.ErrInvalidCIDR
+	}
+
+	ip = ip.Unmap()
+	return netip.PrefixFrom(ip, ip.BitLen()), nil
+}
+
+// ipRuleUserID returns the ID of the user of rules, 0 for the server-wide
+// rules.
+func (d *Backend) ipRuleUserID(ctx context.Context, username string) (int64, error) {
+	if username == "" {
+		return 0, nil
+	}
+
+	user, err := d.User(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.ID(), nil
+}
+
+// parseRemoteAddr returns the IP address of a client address, with or
+// without a port.
+func parseRemoteAddr(addr string) (netip.Addr, error) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	return ip.WithZone("").Unmap(), nil
+}
+
+// ipRulesAllow returns whether ip is allowed by rules: it matches no deny
+// rule, and matches an allow rule if there are any.
+func ipRulesAllow(rules []models.IPRule, ip netip.Addr) bool {
+	var restricted, allowed bool
+	for _, r := range rules {
+		prefix, err := netip.ParsePrefix(r.CIDR)
+		if err != nil {
+			continue
+		}
+
+		match := prefix.Contains(ip)
+		if !r.Allow && match {
+			return false
+		}
+		if r.Allow {
+			restricted = true
+			allowed = allowed || match
+		}
+	}
+
+	return !restricted || allowed
+}
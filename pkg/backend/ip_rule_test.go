@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+func TestParseIPRange(t *testing.T) {
+	cases := map[string]string{
+		"10.1.2.3/8":       "10.0.0.0/8",
+		"192.168.1.1":      "192.168.1.1/32",
+		"::ffff:127.0.0.1": "127.0.0.1/32",
+		"2001:db8::1/32":   "2001:db8::/32",
+	}
+	for in, want := range cases {
+		got, err := ParseIPRange(in)
+		if err != nil {
+			t.Fatalf("ParseIPRange(%q): %v", in, err)
+		}
+		if got.String() != want {
+			t.Errorf("ParseIPRange(%q) = %s, want %s", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"", "example.com", "10.0.0.0/33", "fe80::1%eth0"} {
+		if _, err := ParseIPRange(in); err == nil {
+			t.Errorf("ParseIPRange(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestIPRulesAllow(t *testing.T) {
+	rules := []models.IPRule{
+		{CIDR: "10.0.0.0/8", Allow: true},
+		{CIDR: "10.1.0.0/16", Allow: false},
+	}
+
+	cases := []struct {
+		rules []models.IPRule
+		addr  string
+		want  bool
+	}{
+		{nil, "1.2.3.4", true},
+		{rules, "10.2.3.4", true},
+		{rules, "10.1.2.3", false},
+		{rules, "192.168.1.1", false},
+		{[]models.IPRule{{CIDR: "1.2.3.4/32", Allow: false}}, "1.2.3.5", true},
+		{[]models.IPRule{{CIDR: "1.2.3.4/32", Allow: false}}, "1.2.3.4", false},
+	}
+	for _, c := range cases {
+		if got := ipRulesAllow(c.rules, netip.MustParseAddr(c.addr)); got != c.want {
+			t.Errorf("ipRulesAllow(%v, %s) = %t, want %t", c.rules, c.addr, got, c.want)
+		}
+	}
+}
+
+func TestParseRemoteAddr(t *testing.T) {
+	cases := map[string]string{
+		"1.2.3.4:22":            "1.2.3.4",
+		"1.2.3.4":               "1.2.3.4",
+		"[::ffff:1.2.3.4]:2222": "1.2.3.4",
+		"[fe80::1%eth0]:22":     "fe80::1",
+		"[2001:db8::1]:443":     "2001:db8::1",
+	}
+	for in, want := range cases {
+		got, err := parseRemoteAddr(in)
+		if err != nil {
+			t.Fatalf("parseRemoteAddr(%q): %v", in, err)
+		}
+		if got.String() != want {
+			t.Errorf("parseRemoteAddr(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
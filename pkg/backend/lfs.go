@@ -5,8 +5,6 @@ import (
 	"errors"
 	"io"
 	"path"
-	"path/filepath"
-	"strconv"
 
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
@@ -19,11 +17,11 @@ import (
 // StoreRepoMissingLFSObjects stores missing LFS objects for a repository.
 func StoreRepoMissingLFSObjects(ctx context.Context, repo proto.Repository, dbx *db.DB, store store.Store, lfsClient lfs.Client) error {
 	cfg := config.FromContext(ctx)
-	repoID := strconv.FormatInt(repo.ID(), 10)
-	lfsRoot := filepath.Join(cfg.DataPath, "lfs", repoID)
+	strg, err := storage.NewLFSStorage(cfg, repo.ID())
+	if err != nil {
+		return err
+	}
 
-	// TODO: support S3 storage
-	strg := storage.NewLocalStorage(lfsRoot)
 	pointerChan := make(chan lfs.PointerBlob)
 	errChan := make(chan error, 1)
 	r, err := repo.Open()
@@ -45,7 +43,7 @@ func StoreRepoMissingLFSObjects(ctx context.Context, repo proto.Repository, dbx
 					return db.WrapError(err)
 				}
 
-				_, err := strg.Put(path.Join("objects", p.RelativePath()), content)
+				_, err := lfs.StoreObject(strg, p, content)
 				return err
 			})
 		})
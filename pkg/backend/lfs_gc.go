@@ -0,0 +1,175 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/lfs"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/storage"
+	"github.com/charmbracelet/soft-serve/pkg/webhook"
+)
+
+// DefaultLFSGCGracePeriod is how long a new LFS object is kept before it can
+// be garbage collected. Clients upload objects before pushing the commits
+// referencing them.
+const DefaultLFSGCGracePeriod = 24 * time.Hour
+
+// lfsObjectsPageSize is the number of stored objects fetched per query.
+const lfsObjectsPageSize = 500
+
+// LFSGCOptions are options for LFS garbage collection.
+type LFSGCOptions struct {
+	// DryRun reports the unreferenced objects without deleting them.
+	DryRun bool
+
+	// GracePeriod keeps objects created within this duration. Defaults to
+	// DefaultLFSGCGracePeriod.
+	GracePeriod time.Duration
+}
+
+// LFSGCStats are the results of LFS garbage collection.
+type LFSGCStats struct {
+	// Objects is the number of unreferenced objects.
+	Objects int
+
+	// Bytes is the total size of the unreferenced objects.
+	Bytes int64
+}
+
+// GarbageCollectLFS deletes the LFS objects of a repository that aren't
+// referenced by any of its refs.
+//
+// Objects created within the grace period, or since the oldest active lock
+// of the repository was taken, are kept since the commits referencing them
+// might not have been pushed yet.
+func (d *Backend) GarbageCollectLFS(ctx context.Context, repo proto.Repository, opts LFSGCOptions) (LFSGCStats, error) {
+	var stats LFSGCStats
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = DefaultLFSGCGracePeriod
+	}
+
+	keepAfter := time.Now().Add(-opts.GracePeriod)
+	locks, err := d.lfsLocks(ctx, repo.ID())
+	if err != nil {
+		return stats, err
+	}
+	for _, l := range locks {
+		if l.CreatedAt.Before(keepAfter) {
+			keepAfter = l.CreatedAt
+		}
+	}
+
+	reachable, err := reachableLFSObjects(ctx, repo)
+	if err != nil {
+		return stats, err
+	}
+
+	strg, err := storage.NewLFSStorage(d.cfg, repo.ID())
+	if err != nil {
+		return stats, err
+	}
+
+	err = d.eachLFSObject(ctx, repo.ID(), func(obj models.LFSObject) error {
+		if !obj.CreatedAt.Before(keepAfter) {
+			return nil
+		}
+		if key, err := hex.DecodeString(obj.Oid); err == nil && len(key) == 32 {
+			if _, ok := reachable[[32]byte(key)]; ok {
+				return nil
+			}
+		}
+
+		stats.Objects++
+		stats.Bytes += obj.Size
+		if opts.DryRun {
+			return nil
+		}
+
+		return d.deleteLFSObject(ctx, strg, repo, obj)
+	})
+
+	return stats, err
+}
+
+// eachLFSObject calls fn for each stored LFS object of a repository. The
+// objects are fetched in pages so large repositories aren't loaded in memory
+// at once.
+func (d *Backend) eachLFSObject(ctx context.Context, repoID int64, fn func(models.LFSObject) error) error {
+	var lastID int64
+	for {
+		objs, err := d.store.GetLFSObjectsAfterID(ctx, d.db, repoID, lastID, lfsObjectsPageSize)
+		if err != nil {
+			return db.WrapError(err)
+		}
+
+		for _, obj := range objs {
+			lastID = obj.ID
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+
+		if len(objs) < lfsObjectsPageSize {
+			return nil
+		}
+	}
+}
+
+// reachableLFSObjects returns the OIDs of the LFS pointers reachable from
+// the repository refs.
+func reachableLFSObjects(ctx context.Context, repo proto.Repository) (map[[32]byte]struct{}, error) {
+	r, err := repo.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	pointerChan := make(chan lfs.PointerBlob)
+	errChan := make(chan error, 1)
+	go lfs.SearchPointerBlobs(ctx, r, pointerChan, errChan)
+
+	reachable := make(map[[32]byte]struct{})
+	for p := range pointerChan {
+		if key, err := hex.DecodeString(p.Oid); err == nil && len(key) == 32 {
+			reachable[[32]byte(key)] = struct{}{}
+		}
+	}
+
+	if err, ok := <-errChan; ok {
+		return nil, err
+	}
+
+	return reachable, nil
+}
+
+// deleteLFSObject deletes an LFS object from the storage and the database.
+func (d *Backend) deleteLFSObject(ctx context.Context, strg storage.Storage, repo proto.Repository, obj models.LFSObject) error {
+	p := lfs.Pointer{Oid: obj.Oid, Size: obj.Size}
+	if p.IsValid() {
+		if err := strg.Delete(path.Join("objects", p.RelativePath())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	if err := d.store.DeleteLFSObjectByOid(ctx, d.db, repo.ID(), obj.Oid); err != nil {
+		return db.WrapError(err)
+	}
+
+	d.logger.Debug("deleted lfs object", "repo", repo.Name(), "oid", obj.Oid, "size", obj.Size)
+
+	wh, err := webhook.NewLFSEvent(ctx, proto.UserFromContext(ctx), repo, webhook.LFSEventActionDelete, obj.Oid, obj.Size)
+	if err == nil {
+		err = webhook.SendEvent(ctx, wh)
+	}
+	if err != nil {
+		d.logger.Error("error sending lfs delete webhook", "repo", repo.Name(), "oid", obj.Oid, "err", err)
+	}
+
+	return nil
+}
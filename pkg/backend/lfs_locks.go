@@ -0,0 +1,108 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+// lfsLocksPageSize is the number of locks fetched per query when checking a
+// push against the repository locks.
+const lfsLocksPageSize = 100
+
+// checkLockedPaths rejects pushes that change files locked by another user.
+func (d *Backend) checkLockedPaths(ctx context.Context, repo string, args []hooks.HookArg) error {
+	if !d.cfg.LFS.Enabled {
+		return nil
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	locks, err := d.lfsLocks(ctx, r.ID())
+	if err != nil {
+		return err
+	}
+
+	if len(locks) == 0 {
+		return nil
+	}
+
+	user, err := d.HookUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	rp := d.repoPath(repo)
+	for _, arg := range args {
+		// Deleted refs don't change any files.
+		if gitb.IsZeroHash(arg.NewSha) {
+			continue
+		}
+
+		commits, err := git.NewCommits(ctx, rp, arg.NewSha)
+		if err != nil {
+			return err
+		}
+
+		paths, err := git.ChangedPaths(ctx, rp, commits)
+		if err != nil {
+			return err
+		}
+
+		changed := make(map[string]struct{}, len(paths))
+		for _, p := range paths {
+			changed[p] = struct{}{}
+		}
+
+		for _, l := range locks {
+			if l.UserID == user.ID() {
+				continue
+			}
+			if l.Refname != "" && l.Refname != arg.RefName {
+				continue
+			}
+			if _, ok := changed[l.Path]; !ok {
+				continue
+			}
+
+			owner := fmt.Sprintf("user %d", l.UserID)
+			if u, err := d.UserByID(ctx, l.UserID); err == nil {
+				owner = u.Username()
+			}
+
+			return fmt.Errorf("%s: %w: %s is locked by %s", arg.RefName, git.ErrPathLocked, l.Path, owner)
+		}
+	}
+
+	return nil
+}
+
+// lfsLocks returns all the LFS locks of a repository.
+func (d *Backend) lfsLocks(ctx context.Context, repoID int64) ([]models.LFSLock, error) {
+	var locks []models.LFSLock
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		for page := 1; ; page++ {
+			ls, err := d.store.GetLFSLocks(ctx, tx, repoID, page, lfsLocksPageSize)
+			if err != nil {
+				return err
+			}
+
+			locks = append(locks, ls...)
+			if len(ls) < lfsLocksPageSize {
+				return nil
+			}
+		}
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return locks, nil
+}
@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/lfs"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/storage"
+)
+
+// LFSObjectError is a stored LFS object that failed verification.
+type LFSObjectError struct {
+	// Oid is the object ID.
+	Oid string
+
+	// Size is the expected size of the object.
+	Size int64
+
+	// Err is why the object failed verification.
+	Err error
+}
+
+// LFSVerifyOptions are options for LFS object verification.
+type LFSVerifyOptions struct {
+	// Repair deletes the corrupted and missing objects so they can be
+	// uploaded again.
+	Repair bool
+}
+
+// VerifyLFSObjects checks that the stored LFS objects of a repository exist
+// and match their OID and size. It returns the objects that don't.
+func (d *Backend) VerifyLFSObjects(ctx context.Context, repo proto.Repository, opts LFSVerifyOptions) ([]LFSObjectError, error) {
+	strg, err := storage.NewLFSStorage(d.cfg, repo.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []LFSObjectError
+	err = d.eachLFSObject(ctx, repo.ID(), func(obj models.LFSObject) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		p := lfs.Pointer{Oid: obj.Oid, Size: obj.Size}
+		err := lfs.VerifyObject(strg, p)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, lfs.ErrOIDMismatch),
+			errors.Is(err, lfs.ErrSizeMismatch),
+			errors.Is(err, lfs.ErrInvalidOIDFormat),
+			errors.Is(err, fs.ErrNotExist):
+		default:
+			return err
+		}
+
+		failed = append(failed, LFSObjectError{Oid: obj.Oid, Size: obj.Size, Err: err})
+		if !opts.Repair {
+			return nil
+		}
+
+		return d.deleteLFSObject(ctx, strg, repo, obj)
+	})
+
+	return failed, err
+}
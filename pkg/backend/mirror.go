@@ -0,0 +1,185 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// encryptedMirrorPrefix marks the encrypted mirror credentials, the ones
+// without it were stored in plain text by older versions.
+const encryptedMirrorPrefix = "enc:"
+
+// MirrorConfig returns the upstream configuration of a mirror repository,
+// with its credentials decrypted. Credentials stored in plain text by older
+// versions are encrypted on the way.
+func (d *Backend) MirrorConfig(ctx context.Context, repo proto.Repository) (models.MirrorConfig, error) {
+	var m models.MirrorConfig
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetMirrorConfigByRepoID(ctx, tx, repo.ID())
+		return err
+	}); err != nil {
+		return models.MirrorConfig{}, db.WrapError(err)
+	}
+
+	var plain bool
+	for _, c := range []struct {
+		name  string
+		value *sql.NullString
+	}{
+		{"password", &m.Password},
+		{"ssh_key", &m.SSHKey},
+	} {
+		enc, ok := strings.CutPrefix(c.value.String, encryptedMirrorPrefix)
+		if !ok {
+			plain = plain || c.value.String != ""
+			continue
+		}
+
+		key, err := d.secretKey(false)
+		if err != nil {
+			return models.MirrorConfig{}, err
+		}
+		c.value.String, err = decryptSecret(key, mirrorSecretName(repo, c.name), enc)
+		if err != nil {
+			return models.MirrorConfig{}, fmt.Errorf("decrypt mirror %s: %w", c.name, err)
+		}
+	}
+
+	if plain {
+		if err := d.SetMirrorConfig(ctx, repo, m.RemoteURL, time.Duration(m.SyncInterval)*time.Second,
+			m.Username.String, m.Password.String, m.SSHKey.String); err != nil {
+			d.logger.Error("error encrypting mirror credentials", "repo", repo.Name(), "err", err)
+		}
+	}
+
+	return m, nil
+}
+
+// SetMirrorConfig sets the upstream configuration of a mirror repository.
+// The repository is synced at most once every interval, a zero interval
+// means it's synced every time the mirror job runs. SSH upstreams are
+// authenticated with sshKey, or the server client key when it's empty. The
+// password and the key are encrypted in the database.
+func (d *Backend) SetMirrorConfig(ctx context.Context, repo proto.Repository, remote string, interval time.Duration, username, password, sshKey string) error {
+	var err error
+	if password, err = d.encryptMirrorSecret(repo, "password", password); err != nil {
+		return err
+	}
+	if sshKey, err = d.encryptMirrorSecret(repo, "ssh_key", sshKey); err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.SetMirrorConfigByRepoID(ctx, tx, repo.ID(), remote, int64(interval.Seconds()), username, password, sshKey)
+		}),
+	)
+}
+
+// encryptMirrorSecret encrypts a credential of a mirror repository, empty
+// ones are left empty.
+func (d *Backend) encryptMirrorSecret(repo proto.Repository, name, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	key, err := d.secretKey(true)
+	if err != nil {
+		return "", err
+	}
+	enc, err := encryptSecret(key, mirrorSecretName(repo, name), value)
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedMirrorPrefix + enc, nil
+}
+
+// mirrorSecretName returns the name authenticated with a credential of a
+// mirror repository, so it can't be used for another repository.
+func mirrorSecretName(repo proto.Repository, name string) string {
+	return fmt.Sprintf("mirror:%d:%s", repo.ID(), name)
+}
+
+// SetMirrorSynced records the time and result of a mirror sync.
+func (d *Backend) SetMirrorSynced(ctx context.Context, repo proto.Repository, syncErr error) error {
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.SetMirrorSyncedByRepoID(ctx, tx, repo.ID(), syncErr)
+		}),
+	)
+}
+
+// MirrorEnv returns the environment used to run git against a mirror
+// upstream. Credentials are only used for HTTP upstreams, and sshKey, when
+// set, replaces the server client key for SSH upstreams. The key is written
+// to a temporary file, call cleanup once git is done to remove it.
+func (d *Backend) MirrorEnv(username, password, sshKey string) (env []string, cleanup func(), err error) {
+	keyPath := d.cfg.SSH.ClientKeyPath
+	cleanup = func() {}
+	if sshKey != "" {
+		f, err := os.CreateTemp("", "soft-serve-mirror-key-*")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cleanup = func() { os.Remove(f.Name()) } //nolint: errcheck
+		if !strings.HasSuffix(sshKey, "\n") {
+			sshKey += "\n"
+		}
+		if _, err := f.WriteString(sshKey); err != nil {
+			f.Close() //nolint: errcheck
+			cleanup()
+			return nil, nil, err
+		}
+		if err := f.Close(); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+
+		keyPath = f.Name()
+	}
+
+	return append([]string{
+		fmt.Sprintf(`GIT_SSH_COMMAND=ssh -o UserKnownHostsFile="%s" -o StrictHostKeyChecking=no -o IdentitiesOnly=yes -i "%s"`,
+			filepath.Join(d.cfg.DataPath, "ssh", "known_hosts"),
+			keyPath,
+		),
+	}, git.MirrorCredentialsEnv(username, password)...), cleanup, nil
+}
+
+// MirrorDue returns whether a mirror repository should be synced at now.
+func MirrorDue(m models.MirrorConfig, now time.Time) bool {
+	if m.SyncInterval <= 0 || !m.LastSyncedAt.Valid {
+		return true
+	}
+
+	next := m.LastSyncedAt.Time.Add(time.Duration(m.SyncInterval) * time.Second)
+	return !now.Before(next)
+}
+
+// splitRemoteCredentials removes the user info from an HTTP remote URL and
+// returns it separately so it never ends up in the repository config.
+func splitRemoteCredentials(remote string) (string, string, string) {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return remote, "", ""
+	}
+
+	username := u.User.Username()
+	password, _ := u.User.Password()
+	u.User = nil
+	return u.String(), username, password
+}
@@ -0,0 +1,91 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// AllowPushCreate returns whether pushes of user to a repository that doesn't
+// exist create it. Anonymous pushes follow the configured default. Errors are
+// logged and deny the creation.
+func (d *Backend) AllowPushCreate(ctx context.Context, user proto.User) bool {
+	if user == nil {
+		return d.cfg.Repo.AllowPushCreate
+	}
+
+	allow, _, err := d.UserAllowPushCreate(ctx, user.Username())
+	if err != nil {
+		d.logger.Error("error getting push create setting", "user", user.Username(), "err", err)
+		return false
+	}
+
+	return allow
+}
+
+// UserAllowPushCreate returns whether pushes of a user can create
+// repositories, and whether it overrides the configured default.
+func (d *Backend) UserAllowPushCreate(ctx context.Context, username string) (bool, bool, error) {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return false, false, err
+	}
+
+	var allow sql.NullBool
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		m, err := d.store.FindUserByUsername(ctx, tx, username)
+		allow = m.AllowPushCreate
+		return err
+	})
+	if errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+		return false, false, proto.ErrUserNotFound
+	}
+	if err != nil {
+		return false, false, db.WrapError(err)
+	}
+
+	if allow.Valid {
+		return allow.Bool, true, nil
+	}
+
+	return d.cfg.Repo.AllowPushCreate, false, nil
+}
+
+// SetUserAllowPushCreate overrides whether pushes of a user can create
+// repositories.
+func (d *Backend) SetUserAllowPushCreate(ctx context.Context, username string, allow bool) error {
+	return d.setUserAllowPushCreate(ctx, username, sql.NullBool{Bool: allow, Valid: true})
+}
+
+// ResetUserAllowPushCreate removes the push create override of a user so that
+// the configured default applies.
+func (d *Backend) ResetUserAllowPushCreate(ctx context.Context, username string) error {
+	return d.setUserAllowPushCreate(ctx, username, sql.NullBool{})
+}
+
+func (d *Backend) setUserAllowPushCreate(ctx context.Context, username string, allow sql.NullBool) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if _, err := d.store.FindUserByUsername(ctx, tx, username); err != nil {
+				return err
+			}
+
+			return d.store.SetUserAllowPushCreateByUsername(ctx, tx, username, allow)
+		}),
+	)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return proto.ErrUserNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,83 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// PushEvent is a push to a repository, the refs it updated and the number of
+// commits it introduced.
+type PushEvent struct {
+	ID       int64
+	Username string
+	Refs     []hooks.HookArg
+	Commits  int64
+	Time     time.Time
+}
+
+// recordPush records a push to a repository that updated refs. The pushing
+// user is read from the environment set by the git service, see HookUser.
+func (d *Backend) recordPush(ctx context.Context, repo proto.Repository, refs []hooks.HookArg) error {
+	username := os.Getenv("SOFT_SERVE_USERNAME")
+	if user, err := d.HookUser(ctx); err == nil {
+		username = user.Username()
+	}
+
+	commits, err := git.PushedCommits(ctx, d.repoPath(repo.Name()), refs)
+	if err != nil {
+		return fmt.Errorf("count pushed commits: %w", err)
+	}
+
+	lines := make([]string, len(refs))
+	for i, arg := range refs {
+		lines[i] = fmt.Sprintf("%s %s %s", arg.OldSha, arg.NewSha, arg.RefName)
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.CreatePushEvent(ctx, tx, repo.ID(), username, strings.Join(lines, "\n"), commits)
+		}),
+	)
+}
+
+// PushEvents returns the last limit pushes to a repository, newest first.
+func (d *Backend) PushEvents(ctx context.Context, repo proto.Repository, limit int) ([]PushEvent, error) {
+	var events []PushEvent
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		ms, err := d.store.GetPushEventsByRepoID(ctx, tx, repo.ID(), limit)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range ms {
+			e := PushEvent{
+				ID:       m.ID,
+				Username: m.Username,
+				Commits:  m.Commits,
+				Time:     m.CreatedAt,
+			}
+			for _, line := range strings.Split(m.Refs, "\n") {
+				fields := strings.Fields(line)
+				if len(fields) != 3 {
+					continue
+				}
+				e.Refs = append(e.Refs, hooks.HookArg{OldSha: fields[0], NewSha: fields[1], RefName: fields[2]})
+			}
+			events = append(events, e)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,214 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/dustin/go-humanize"
+)
+
+// RepositoryQuota returns a quota checker that limits repositories to the
+// configured maximum size, and the repositories of each user to the user's
+// quota.
+func (d *Backend) RepositoryQuota(ctx context.Context) git.QuotaChecker {
+	quotas := quotaCheckers{
+		&userQuota{ctx: ctx, d: d, repos: map[string]userUsage{}},
+	}
+
+	if d.cfg.Repo.MaxSize > 0 {
+		quotas = append(quotas, git.NewSizeQuota(
+			func(string) (int64, error) {
+				return d.cfg.Repo.MaxSize, nil
+			},
+			func(repo string) (int64, error) {
+				return d.RepositorySize(ctx, repo)
+			},
+		))
+	}
+
+	return quotas
+}
+
+// UserQuota returns the maximum total size in bytes of the repositories a
+// user owns, 0 meaning no limit, and whether it overrides the configured
+// default.
+func (d *Backend) UserQuota(ctx context.Context, username string) (int64, bool, error) {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return 0, false, err
+	}
+
+	var quota sql.NullInt64
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		m, err := d.store.FindUserByUsername(ctx, tx, username)
+		quota = m.Quota
+		return err
+	})
+	if errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+		return 0, false, proto.ErrUserNotFound
+	}
+	if err != nil {
+		return 0, false, db.WrapError(err)
+	}
+
+	if quota.Valid {
+		return quota.Int64, true, nil
+	}
+
+	return d.cfg.Repo.MaxUserSize, false, nil
+}
+
+// SetUserQuota overrides the quota of a user. A quota of 0 means no limit.
+func (d *Backend) SetUserQuota(ctx context.Context, username string, quota int64) error {
+	if quota < 0 {
+		return fmt.Errorf("invalid quota: %d", quota)
+	}
+
+	return d.setUserQuota(ctx, username, sql.NullInt64{Int64: quota, Valid: true})
+}
+
+// ResetUserQuota removes the quota override of a user so that the configured
+// default applies.
+func (d *Backend) ResetUserQuota(ctx context.Context, username string) error {
+	return d.setUserQuota(ctx, username, sql.NullInt64{})
+}
+
+func (d *Backend) setUserQuota(ctx context.Context, username string, quota sql.NullInt64) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if _, err := d.store.FindUserByUsername(ctx, tx, username); err != nil {
+				return err
+			}
+
+			return d.store.SetUserQuotaByUsername(ctx, tx, username, quota)
+		}),
+	)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return proto.ErrUserNotFound
+	}
+
+	return err
+}
+
+// UserStorage returns the total size in bytes of the repositories a user
+// owns, as recorded in the repository statistics.
+func (d *Backend) UserStorage(ctx context.Context, user proto.User) (int64, error) {
+	repos, err := d.Repositories(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, r := range repos {
+		if r.UserID() != user.ID() {
+			continue
+		}
+
+		st, err := d.RepoStats(ctx, r)
+		if err != nil {
+			return 0, err
+		}
+		size += st.Size
+	}
+
+	return size, nil
+}
+
+// quotaCheckers is a QuotaChecker that rejects pushes rejected by any of its
+// checkers.
+type quotaCheckers []git.QuotaChecker
+
+// Allowed implements git.QuotaChecker.
+func (q quotaCheckers) Allowed(repo string, incomingBytes int64) error {
+	for _, c := range q {
+		if err := c.Allowed(repo, incomingBytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// userUsage is the quota and storage of the owner of a repository.
+type userUsage struct {
+	username string
+	limit    int64
+	used     int64
+}
+
+// userQuota rejects pushes that would grow the repositories of a user past
+// the user's quota. Repositories without an owner are not limited. The quota
+// and storage of the owner are only looked up once per repository.
+type userQuota struct {
+	ctx   context.Context
+	d     *Backend
+	mu    sync.Mutex
+	repos map[string]userUsage
+}
+
+// Allowed implements git.QuotaChecker.
+func (q *userQuota) Allowed(repo string, incomingBytes int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	u, ok := q.repos[repo]
+	if !ok {
+		var err error
+		u, err = q.usage(repo)
+		if err != nil {
+			return err
+		}
+		q.repos[repo] = u
+	}
+
+	if u.limit > 0 && u.used+incomingBytes > u.limit {
+		return fmt.Errorf("%w: the repositories of %s are limited to %s, %s are used",
+			git.ErrQuotaExceeded, u.username,
+			humanize.Bytes(uint64(u.limit)), humanize.Bytes(uint64(u.used))) //nolint:gosec
+	}
+
+	return nil
+}
+
+func (q *userQuota) usage(repo string) (userUsage, error) {
+	r, err := q.d.Repository(q.ctx, repo)
+	if err != nil {
+		return userUsage{}, err
+	}
+	if r.UserID() <= 0 {
+		return userUsage{}, nil
+	}
+
+	user, err := q.d.UserByID(q.ctx, r.UserID())
+	if errors.Is(err, proto.ErrUserNotFound) {
+		return userUsage{}, nil
+	}
+	if err != nil {
+		return userUsage{}, err
+	}
+
+	limit, _, err := q.d.UserQuota(q.ctx, user.Username())
+	if err != nil || limit <= 0 {
+		return userUsage{}, err
+	}
+
+	used, err := q.d.UserStorage(q.ctx, user)
+	if err != nil {
+		return userUsage{}, err
+	}
+
+	return userUsage{username: user.Username(), limit: limit, used: used}, nil
+}
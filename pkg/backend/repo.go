@@ -2,20 +2,24 @@ package backend
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"errors"
-	"fmt"
+	"io"
 	"io/fs"
+	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/charmbracelet/soft-serve/git"
+	gitm "github.com/aymanbagabas/git-module"
+	gitb "github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/git"
 	"github.com/charmbracelet/soft-serve/pkg/hooks"
 	"github.com/charmbracelet/soft-serve/pkg/lfs"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
@@ -23,6 +27,7 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/task"
 	"github.com/charmbracelet/soft-serve/pkg/utils"
 	"github.com/charmbracelet/soft-serve/pkg/webhook"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 func validateImportRemote(remote string) error {
@@ -34,6 +39,61 @@ func validateImportRemote(remote string) error {
 	return nil
 }
 
+// importTokenUsername is the username sent along with an import token when
+// the remote URL doesn't have one. Most servers accept any username with a
+// token.
+const importTokenUsername = "x-access-token"
+
+// isHTTPRemote returns whether a remote is an HTTP URL, rather than an SSH
+// or git one.
+func isHTTPRemote(remote string) bool {
+	u, err := url.Parse(remote)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// remoteDescriptionMaxSize is the size of the largest remote description
+// that's used.
+const remoteDescriptionMaxSize = 1 << 10 // 1 KiB
+
+// remoteDescription returns the description of an HTTP remote, served at
+// description next to the repository like git does for dumb HTTP. It
+// returns an empty string if the remote doesn't have one.
+func remoteDescription(ctx context.Context, remote, username, password string) string {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(remote, "/")+"/description", nil)
+	if err != nil {
+		return ""
+	}
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ""
+	}
+
+	defer resp.Body.Close() //nolint: errcheck
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		return ""
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, remoteDescriptionMaxSize))
+	if err != nil {
+		return ""
+	}
+
+	desc := strings.TrimSpace(utils.Sanitize(string(b)))
+	// Git fills new repositories with a placeholder description.
+	if strings.HasPrefix(desc, "Unnamed repository;") {
+		return ""
+	}
+
+	return desc
+}
+
 // CreateRepository creates a new repository.
 //
 // It implements backend.Backend.
@@ -43,6 +103,44 @@ func (d *Backend) CreateRepository(ctx context.Context, name string, user proto.
 		return nil, err
 	}
 
+	if opts.DefaultBranch != "" {
+		if err := utils.ValidateBranch(opts.DefaultBranch); err != nil {
+			return nil, err
+		}
+	}
+
+	release, err := d.ops.acquire(name)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var tmpl proto.Repository
+	if opts.Template != "" {
+		tmpl, err = d.templateRepository(ctx, opts.Template)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var parent proto.Repository
+	if opts.Fork != "" {
+		parent, err = d.Repository(ctx, opts.Fork)
+		if err != nil {
+			return nil, err
+		}
+
+		// Keep the parent from being renamed while it's forked.
+		release, err := d.ops.acquire(parent.Name())
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		// Forks of private repositories are private too.
+		opts.Private = opts.Private || parent.IsPrivate()
+	}
+
 	rp := filepath.Join(d.repoPath(name))
 
 	var userID int64
@@ -50,6 +148,7 @@ func (d *Backend) CreateRepository(ctx context.Context, name string, user proto.
 		userID = user.ID()
 	}
 
+	var created bool
 	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 		if err := d.store.CreateRepo(
 			ctx,
@@ -65,27 +164,50 @@ func (d *Backend) CreateRepository(ctx context.Context, name string, user proto.
 			return err
 		}
 
-		_, err := git.Init(rp, true)
+		_, serr := os.Stat(rp)
+		r, err := gitb.Init(rp, true)
 		if err != nil {
 			d.logger.Debug("failed to create repository", "err", err)
 			return err
 		}
+		created = os.IsNotExist(serr)
 
-		if err := os.WriteFile(filepath.Join(rp, "description"), []byte(opts.Description), fs.ModePerm); err != nil {
-			d.logger.Error("failed to write description", "repo", name, "err", err)
-			return err
+		// Forks point HEAD to the default branch of their parent.
+		if branch := cmp.Or(opts.DefaultBranch, d.cfg.Repo.DefaultBranch); created && branch != "" && parent == nil {
+			if _, err := r.SymbolicRef(gitb.HEAD, gitb.RefsHeads+branch); err != nil {
+				return err
+			}
 		}
 
-		if !opts.Private {
-			if err := os.WriteFile(filepath.Join(rp, "git-daemon-export-ok"), []byte{}, fs.ModePerm); err != nil {
-				d.logger.Error("failed to write git-daemon-export-ok", "repo", name, "err", err)
+		if tmpl != nil {
+			if err := d.initFromTemplate(r, tmpl, user, opts.DefaultBranch); err != nil {
+				d.logger.Error("failed to create repository from template", "repo", name, "template", tmpl.Name(), "err", err)
 				return err
 			}
 		}
 
+		if parent != nil {
+			if err := d.initFork(ctx, tx, name, r, parent); err != nil {
+				d.logger.Error("failed to fork repository", "repo", name, "parent", parent.Name(), "err", err)
+				return err
+			}
+		}
+
+		if err := os.WriteFile(filepath.Join(rp, "description"), []byte(opts.Description), fs.ModePerm); err != nil {
+			d.logger.Error("failed to write description", "repo", name, "err", err)
+			return err
+		}
+
 		return hooks.GenerateHooks(ctx, d.cfg, name)
 	}); err != nil {
 		d.logger.Debug("failed to create repository in database", "err", err)
+		if created {
+			// Don't leave a half created repository behind.
+			if err := os.RemoveAll(rp); err != nil {
+				d.logger.Error("failed to remove repository", "repo", name, "err", err)
+			}
+		}
+
 		err = db.WrapError(err)
 		if errors.Is(err, db.ErrDuplicateKey) {
 			return nil, proto.ErrRepoExist
@@ -94,7 +216,21 @@ func (d *Backend) CreateRepository(ctx context.Context, name string, user proto.
 		return nil, err
 	}
 
-	return d.Repository(ctx, name)
+	r, err := d.Repository(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The repository is created even if the webhooks can't be queued.
+	wh, err := webhook.NewRepositoryEvent(ctx, user, r, webhook.RepositoryEventActionCreate)
+	if err == nil {
+		err = webhook.SendEvent(ctx, wh)
+	}
+	if err != nil {
+		d.logger.Error("error sending repository create webhook", "repo", name, "err", err)
+	}
+
+	return r, nil
 }
 
 // ImportRepository imports a repository from remote.
@@ -110,6 +246,29 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 		return nil, err
 	}
 
+	// Keep credentials out of the repository config, they're passed to git
+	// through the environment instead.
+	remote, username, password := splitRemoteCredentials(remote)
+	isHTTP := isHTTPRemote(remote)
+	if opts.Token != "" {
+		if !isHTTP {
+			return nil, proto.ErrTokenRemote
+		}
+		if username == "" {
+			username = importTokenUsername
+		}
+		password = opts.Token
+	}
+
+	if opts.SSHKey != "" {
+		if isHTTP {
+			return nil, proto.ErrSSHKeyRemote
+		}
+		if _, err := gossh.ParsePrivateKey([]byte(opts.SSHKey)); err != nil {
+			return nil, proto.ErrInvalidSSHKey
+		}
+	}
+
 	rp := filepath.Join(d.repoPath(name))
 
 	tid := "import:" + name
@@ -127,23 +286,17 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 	d.manager.Add(tid, func(ctx context.Context) (err error) {
 		ctx = proto.WithUserContext(ctx, user)
 
-		copts := git.CloneOptions{
-			Bare:   true,
-			Mirror: opts.Mirror,
-			Quiet:  true,
-			CommandOptions: git.CommandOptions{
-				Timeout: -1,
-				Context: ctx,
-				Envs: []string{
-					fmt.Sprintf(`GIT_SSH_COMMAND=ssh -o UserKnownHostsFile="%s" -o StrictHostKeyChecking=no -i "%s"`,
-						filepath.Join(d.cfg.DataPath, "ssh", "known_hosts"),
-						d.cfg.SSH.ClientKeyPath,
-					),
-				},
-			},
-		}
-
-		if err := git.Clone(remote, rp, copts); err != nil {
+		env, cleanup, err := d.MirrorEnv(username, password, opts.SSHKey)
+		if err != nil {
+			return err
+		}
+
+		defer cleanup()
+
+		if err := git.Clone(ctx, remote, rp, opts.Mirror, git.ServiceCommand{
+			Env:    env,
+			Stderr: opts.Progress,
+		}); err != nil {
 			d.logger.Error("failed to clone repository", "err", err, "mirror", opts.Mirror, "remote", remote, "path", rp)
 			// Cleanup the mess!
 			if rerr := os.RemoveAll(rp); rerr != nil {
@@ -153,9 +306,17 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 			return err
 		}
 
+		if opts.Description == "" && isHTTP {
+			opts.Description = remoteDescription(ctx, remote, username, password)
+		}
+
 		r, err := d.CreateRepository(ctx, name, user, opts)
 		if err != nil {
 			d.logger.Error("failed to create repository", "err", err, "name", name)
+			if rerr := os.RemoveAll(rp); rerr != nil {
+				err = errors.Join(err, rerr)
+			}
+
 			return err
 		}
 
@@ -167,6 +328,13 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 			}
 		}()
 
+		if opts.Mirror {
+			if err := d.SetMirrorConfig(ctx, r, remote, opts.MirrorInterval, username, password, opts.SSHKey); err != nil {
+				d.logger.Error("failed to set mirror config", "err", err, "name", name)
+				return err
+			}
+		}
+
 		rr, err := r.Open()
 		if err != nil {
 			d.logger.Error("failed to open repository", "err", err, "path", rp)
@@ -218,7 +386,13 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 		d.manager.Run(tid, done)
 	}()
 
-	return <-repoc, <-done
+	// The repository is only sent once it's created, a failed import
+	// doesn't leave one behind.
+	if err := <-done; err != nil {
+		return nil, err
+	}
+
+	return <-repoc, nil
 }
 
 // DeleteRepository deletes a repository.
@@ -234,13 +408,27 @@ func (d *Backend) DeleteRepository(ctx context.Context, name string) error {
 		return err
 	}
 
-	// We create the webhook event before deleting the repository so we can
-	// send the event after deleting the repository.
+	// The webhooks are deleted along with the repository, so the event is
+	// sent right away instead of being queued.
 	wh, err := webhook.NewRepositoryEvent(ctx, user, r, webhook.RepositoryEventActionDelete)
 	if err != nil {
 		return err
 	}
 
+	if err := webhook.SendEventNow(ctx, wh); err != nil {
+		d.logger.Error("error sending repository delete webhook", "repo", name, "err", err)
+	}
+
+	// Forks borrow the objects of the repository, they get their own copy
+	// before it goes away.
+	if err := d.dissociateForks(ctx, r); err != nil {
+		return err
+	}
+	parent, err := d.RepositoryParent(ctx, r)
+	if err != nil {
+		return err
+	}
+
 	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 		// Delete repo from cache
 		defer d.cache.Delete(name)
@@ -258,8 +446,11 @@ func (d *Backend) DeleteRepository(ctx context.Context, name string) error {
 			return db.WrapError(dberr)
 		}
 
-		repoID := strconv.FormatInt(repom.ID, 10)
-		strg := storage.NewLocalStorage(filepath.Join(d.cfg.DataPath, "lfs", repoID))
+		strg, err := storage.NewLFSStorage(d.cfg, repom.ID)
+		if err != nil {
+			return err
+		}
+
 		objs, err := d.store.GetLFSObjectsByName(ctx, tx, name)
 		if err != nil {
 			return db.WrapError(err)
@@ -290,7 +481,13 @@ func (d *Backend) DeleteRepository(ctx context.Context, name string) error {
 		return db.WrapError(err)
 	}
 
-	return webhook.SendEvent(ctx, wh)
+	if parent != nil {
+		if err := d.forkRemoved(ctx, parent); err != nil {
+			d.logger.Error("error updating fork parent", "repo", name, "parent", parent.Name(), "err", err)
+		}
+	}
+
+	return nil
 }
 
 // DeleteUserRepositories deletes all user repositories.
@@ -338,6 +535,13 @@ func (d *Backend) RenameRepository(ctx context.Context, oldName string, newName
 		return nil
 	}
 
+	// Keep git operations out of both repositories during the rename.
+	done, err := d.ops.rename(oldName, newName)
+	if err != nil {
+		return err
+	}
+	defer done()
+
 	op := filepath.Join(d.repoPath(oldName))
 	np := filepath.Join(d.repoPath(newName))
 	if _, err := os.Stat(op); err != nil {
@@ -349,9 +553,6 @@ func (d *Backend) RenameRepository(ctx context.Context, oldName string, newName
 	}
 
 	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
-		// Delete cache
-		defer d.cache.Delete(oldName)
-
 		if err := d.store.SetRepoNameByName(ctx, tx, oldName, newName); err != nil {
 			return err
 		}
@@ -363,20 +564,43 @@ func (d *Backend) RenameRepository(ctx context.Context, oldName string, newName
 
 		return os.Rename(op, np)
 	}); err != nil {
-		return db.WrapError(err)
+		// Move the repository back if the transaction failed to commit
+		// after the move.
+		if _, serr := os.Stat(op); os.IsNotExist(serr) {
+			if rerr := os.Rename(np, op); rerr != nil {
+				d.logger.Error("failed to move repository back", "repo", oldName, "err", rerr)
+			}
+		}
+
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrRepoExist
+		}
+
+		return err
 	}
 
+	// Delete cache
+	d.cache.Delete(oldName)
+	d.cache.Delete(newName)
+
 	user := proto.UserFromContext(ctx)
 	repo, err := d.Repository(ctx, newName)
 	if err != nil {
 		return err
 	}
 
+	// Alternates are relative paths, they follow the repository.
+	if err := d.relinkForks(ctx, repo); err != nil {
+		return err
+	}
+
 	wh, err := webhook.NewRepositoryEvent(ctx, user, repo, webhook.RepositoryEventActionRename)
 	if err != nil {
 		return err
 	}
 
+	wh.OldName = oldName
 	return webhook.SendEvent(ctx, wh)
 }
 
@@ -522,6 +746,27 @@ func (d *Backend) IsHidden(ctx context.Context, name string) (bool, error) {
 	return hidden, nil
 }
 
+// IsArchived returns true if the repository is archived.
+//
+// It implements backend.Backend.
+func (d *Backend) IsArchived(ctx context.Context, name string) (bool, error) {
+	name = utils.SanitizeRepo(name)
+	var archived bool
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		archived, err = d.store.GetRepoIsArchivedByName(ctx, tx, name)
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return false, proto.ErrRepoNotFound
+		}
+		return false, err
+	}
+
+	return archived, nil
+}
+
 // ProjectName returns the project name of a repository.
 //
 // It implements backend.Backend.
@@ -553,6 +798,28 @@ func (d *Backend) SetHidden(ctx context.Context, name string, hidden bool) error
 	}))
 }
 
+// SetArchived sets the archived flag of a repository. Archived repositories
+// are read-only.
+//
+// It implements backend.Backend.
+func (d *Backend) SetArchived(ctx context.Context, name string, archived bool) error {
+	name = utils.SanitizeRepo(name)
+
+	// Delete cache
+	d.cache.Delete(name)
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if _, err := d.store.GetRepoByName(ctx, tx, name); err != nil {
+			if errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+				return proto.ErrRepoNotFound
+			}
+			return err
+		}
+
+		return d.store.SetRepoIsArchivedByName(ctx, tx, name, archived)
+	}))
+}
+
 // SetDescription sets the description of a repository.
 //
 // It implements backend.Backend.
@@ -584,21 +851,22 @@ func (d *Backend) SetPrivate(ctx context.Context, name string, private bool) err
 	// Delete cache
 	d.cache.Delete(name)
 
+	var changed bool
 	if err := db.WrapError(
 		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
-			fp := filepath.Join(rp, "git-daemon-export-ok")
-			if !private {
-				if err := os.WriteFile(fp, []byte{}, fs.ModePerm); err != nil {
-					d.logger.Error("failed to write git-daemon-export-ok", "repo", name, "err", err)
+			was, err := d.store.GetRepoIsPrivateByName(ctx, tx, name)
+			if err != nil {
+				return err
+			}
+			changed = was != private
+
+			// Private repositories are never exported, making the repository
+			// public again doesn't export it.
+			if private {
+				if err := d.unexport(rp); err != nil {
+					d.logger.Error("failed to remove git-daemon-export-ok", "repo", name, "err", err)
 					return err
 				}
-			} else {
-				if _, err := os.Stat(fp); err == nil {
-					if err := os.Remove(fp); err != nil {
-						d.logger.Error("failed to remove git-daemon-export-ok", "repo", name, "err", err)
-						return err
-					}
-				}
 			}
 
 			return d.store.SetRepoIsPrivateByName(ctx, tx, name, private)
@@ -613,7 +881,7 @@ func (d *Backend) SetPrivate(ctx context.Context, name string, private bool) err
 		return err
 	}
 
-	if repo.IsPrivate() != !private {
+	if changed {
 		wh, err := webhook.NewRepositoryEvent(ctx, user, repo, webhook.RepositoryEventActionVisibilityChange)
 		if err != nil {
 			return err
@@ -644,6 +912,73 @@ func (d *Backend) SetProjectName(ctx context.Context, repo string, name string)
 	)
 }
 
+// SetDefaultBranch points the HEAD of a repository to an existing branch. It
+// returns [gitb.ErrReferenceNotExist] if the branch doesn't exist.
+//
+// It implements backend.Backend.
+func (d *Backend) SetDefaultBranch(ctx context.Context, repo string, branch string) error {
+	rr, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	r, err := rr.Open()
+	if err != nil {
+		return err
+	}
+
+	branches, _ := r.Branches()
+	var exists bool
+	for _, b := range branches {
+		if branch == b {
+			exists = true
+			break
+		}
+	}
+
+	if !exists {
+		return gitb.ErrReferenceNotExist
+	}
+
+	if _, err := r.SymbolicRef(gitb.HEAD, gitb.RefsHeads+branch, gitm.SymbolicRefOptions{
+		CommandOptions: gitm.CommandOptions{
+			Context: ctx,
+		},
+	}); err != nil {
+		return err
+	}
+
+	user := proto.UserFromContext(ctx)
+	wh, err := webhook.NewRepositoryEvent(ctx, user, rr, webhook.RepositoryEventActionDefaultBranchChange)
+	if err != nil {
+		return err
+	}
+
+	return webhook.SendEvent(ctx, wh)
+}
+
+// RepositorySize returns the size in bytes of a repository on disk.
+func (d *Backend) RepositorySize(_ context.Context, name string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(d.repoPath(name), func(_ string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.Type().IsRegular() {
+			fi, err := de.Info()
+			if err != nil {
+				return err
+			}
+			size += fi.Size()
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, proto.ErrRepoNotFound
+	}
+	return size, err
+}
+
 // repoPath returns the path to a repository.
 func (d *Backend) repoPath(name string) string {
 	name = utils.SanitizeRepo(name)
@@ -709,8 +1044,8 @@ func (r *repo) Name() string {
 // Open opens the repository.
 //
 // It implements backend.Repository.
-func (r *repo) Open() (*git.Repository, error) {
-	return git.Open(r.path)
+func (r *repo) Open() (*gitb.Repository, error) {
+	return gitb.Open(r.path)
 }
 
 // ProjectName returns the repository's project name.
@@ -727,6 +1062,20 @@ func (r *repo) IsHidden() bool {
 	return r.repo.Hidden
 }
 
+// IsArchived returns whether the repository is archived.
+//
+// It implements backend.Repository.
+func (r *repo) IsArchived() bool {
+	return r.repo.Archived
+}
+
+// IsTemplate returns whether the repository is a template.
+//
+// It implements backend.Repository.
+func (r *repo) IsTemplate() bool {
+	return r.repo.Template
+}
+
 // CreatedAt returns the repository's creation time.
 func (r *repo) CreatedAt() time.Time {
 	return r.repo.CreatedAt
@@ -741,7 +1090,7 @@ func (r *repo) UpdatedAt() time.Time {
 		}
 	}
 
-	rr, err := git.Open(r.path)
+	rr, err := gitb.Open(r.path)
 	if err == nil {
 		t, err := rr.LatestCommitTime()
 		if err == nil {
@@ -0,0 +1,188 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // Register the GIF decoder for avatars.
+	_ "image/jpeg" // Register the JPEG decoder for avatars.
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// avatarFile is the file of a repository holding its avatar, re-encoded as a
+// PNG image.
+const avatarFile = "soft-serve-avatar.png"
+
+// The limits of avatars.
+const (
+	// AvatarMaxSize is the size of the largest avatar upload.
+	AvatarMaxSize = 1 << 20 // 1 MiB
+	// AvatarMaxDimension is the width and height of the largest avatar.
+	AvatarMaxDimension = 512
+)
+
+var (
+	// ErrInvalidAvatar is returned when setting an avatar that isn't a PNG,
+	// JPEG, or GIF image.
+	ErrInvalidAvatar = errors.New("avatars must be PNG, JPEG, or GIF images")
+	// ErrAvatarTooLarge is returned when setting an avatar that's too large.
+	ErrAvatarTooLarge = fmt.Errorf("avatars must be at most %d KiB and %dx%d pixels", AvatarMaxSize>>10, AvatarMaxDimension, AvatarMaxDimension)
+	// ErrAvatarNotFound is returned when removing the avatar of a repository
+	// that doesn't have one.
+	ErrAvatarNotFound = errors.New("repository has no avatar")
+)
+
+// SetAvatar sets the avatar of a repository from a PNG, JPEG, or GIF image.
+// The image is re-encoded as a PNG, which strips its metadata.
+func (d *Backend) SetAvatar(ctx context.Context, repo string, r io.Reader) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	b, err := encodeAvatar(r)
+	if err != nil {
+		return err
+	}
+
+	// Write the avatar atomically, it may be served while it's replaced.
+	rp := d.repoPath(repo)
+	f, err := os.CreateTemp(rp, avatarFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), filepath.Join(rp, avatarFile))
+}
+
+// RemoveAvatar removes the avatar of a repository, which gets its default
+// avatar back.
+func (d *Backend) RemoveAvatar(ctx context.Context, repo string) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	err := os.Remove(filepath.Join(d.repoPath(repo), avatarFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrAvatarNotFound
+	}
+
+	return err
+}
+
+// Avatar returns the PNG avatar of a repository, and whether it was set.
+// Repositories without an avatar get an identicon of their name.
+func (d *Backend) Avatar(ctx context.Context, repo string) ([]byte, bool, error) {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return nil, false, err
+	}
+
+	b, err := os.ReadFile(filepath.Join(d.repoPath(repo), avatarFile))
+	switch {
+	case err == nil:
+		return b, true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		b, err := identicon(repo)
+		return b, false, err
+	default:
+		return nil, false, err
+	}
+}
+
+// encodeAvatar decodes an avatar and re-encodes it as a PNG image. Only the
+// pixels are kept. The dimensions are checked before decoding the image so
+// that small files can't expand to huge images.
+func encodeAvatar(r io.Reader) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, AvatarMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > AvatarMaxSize {
+		return nil, ErrAvatarTooLarge
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return nil, ErrInvalidAvatar
+	}
+	if cfg.Width > AvatarMaxDimension || cfg.Height > AvatarMaxDimension {
+		return nil, ErrAvatarTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, ErrInvalidAvatar
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// The layout of identicons, a symmetric grid of cells with a margin.
+const (
+	identiconCells  = 5
+	identiconCell   = 20
+	identiconMargin = 14
+	identiconSize   = identiconCells*identiconCell + 2*identiconMargin
+)
+
+// identicon returns the PNG identicon of a repository name. The color and the
+// cells come from the hash of the name, the grid is mirrored horizontally.
+func identicon(name string) ([]byte, error) {
+	h := sha256.Sum256([]byte(name))
+	fg := color.RGBA{R: h[0]/2 + 64, G: h[1]/2 + 64, B: h[2]/2 + 64, A: 0xff}
+	bg := color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+	img := image.NewPaletted(image.Rect(0, 0, identiconSize, identiconSize), color.Palette{bg, fg})
+
+	half := (identiconCells + 1) / 2
+	for row := range identiconCells {
+		for col := range half {
+			bit := row*half + col
+			if h[3+bit/8]&(1<<(bit%8)) == 0 {
+				continue
+			}
+			for _, c := range []int{col, identiconCells - 1 - col} {
+				x, y := identiconMargin+c*identiconCell, identiconMargin+row*identiconCell
+				for py := y; py < y+identiconCell; py++ {
+					for px := x; px < x+identiconCell; px++ {
+						img.SetColorIndex(px, py, 1)
+					}
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestEncodeAvatar(t *testing.T) {
+	var src bytes.Buffer
+	if err := gif.Encode(&src, image.NewGray(image.Rect(0, 0, 16, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := encodeAvatar(&src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "png" || cfg.Width != 16 || cfg.Height != 8 {
+		t.Errorf("encodeAvatar = %s %dx%d, want png 16x8", format, cfg.Width, cfg.Height)
+	}
+
+	var large bytes.Buffer
+	if err := png.Encode(&large, image.NewGray(image.Rect(0, 0, 1, AvatarMaxDimension+1))); err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range map[string]struct {
+		data string
+		want error
+	}{
+		"text":       {"not an image", ErrInvalidAvatar},
+		"dimensions": {large.String(), ErrAvatarTooLarge},
+		"size":       {strings.Repeat("a", AvatarMaxSize+1), ErrAvatarTooLarge},
+	} {
+		if _, err := encodeAvatar(strings.NewReader(tc.data)); !errors.Is(err, tc.want) {
+			t.Errorf("encodeAvatar(%s) = %v, want %v", name, err, tc.want)
+		}
+	}
+}
+
+func TestIdenticon(t *testing.T) {
+	a, err := identicon("repo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != identiconSize || b.Dy() != identiconSize {
+		t.Errorf("identicon is %dx%d, want %dx%d", b.Dx(), b.Dy(), identiconSize, identiconSize)
+	}
+
+	// The grid is mirrored.
+	for y := range identiconSize {
+		for x := range identiconSize {
+			if img.At(x, y) != img.At(identiconSize-1-x, y) {
+				t.Fatalf("identicon isn't symmetric at %d,%d", x, y)
+			}
+		}
+	}
+
+	if again, _ := identicon("repo1"); !bytes.Equal(a, again) {
+		t.Error("identicon isn't deterministic")
+	}
+	if other, _ := identicon("repo2"); bytes.Equal(a, other) {
+		t.Error("identicons of different names are equal")
+	}
+}
@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// exportOkFile is the file that marks a repository as served by the Git
+// daemon, like git-daemon(1) does.
+const exportOkFile = "git-daemon-export-ok"
+
+// IsExported returns whether the Git daemon serves a repository.
+func (d *Backend) IsExported(ctx context.Context, name string) (bool, error) {
+	name = utils.SanitizeRepo(name)
+	if _, err := d.Repository(ctx, name); err != nil {
+		return false, err
+	}
+
+	_, err := os.Stat(filepath.Join(d.repoPath(name), exportOkFile))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// SetExported sets whether the Git daemon serves a repository. Private
+// repositories can't be exported.
+func (d *Backend) SetExported(ctx context.Context, name string, export bool) error {
+	name = utils.SanitizeRepo(name)
+	repo, err := d.Repository(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	rp := d.repoPath(name)
+	if !export {
+		return d.unexport(rp)
+	}
+
+	if repo.IsPrivate() {
+		return proto.ErrPrivateExport
+	}
+
+	if err := os.WriteFile(filepath.Join(rp, exportOkFile), []byte{}, fs.ModePerm); err != nil {
+		d.logger.Error("failed to write git-daemon-export-ok", "repo", name, "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// unexport removes the export marker of the repository at path rp.
+func (d *Backend) unexport(rp string) error {
+	if err := os.Remove(filepath.Join(rp, exportOkFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"path"
+	"path/filepath"
+	"time"
+
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/lfs"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/storage"
+)
+
+// forkTimeout is how long forking a repository, or dissociating a fork from
+// its parent, may take.
+const forkTimeout = 30 * time.Minute
+
+// RepositoryParent returns the repository a repository is a fork of, or nil
+// if it isn't a fork.
+func (d *Backend) RepositoryParent(ctx context.Context, repo proto.Repository) (proto.Repository, error) {
+	var name string
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		m, err := d.store.GetRepoForkParent(ctx, tx, repo.ID())
+		name = m.Name
+		return err
+	}); err != nil {
+		if errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, db.WrapError(err)
+	}
+
+	return d.Repository(ctx, name)
+}
+
+// RepositoryForks returns the forks of a repository, sorted by name.
+func (d *Backend) RepositoryForks(ctx context.Context, repo proto.Repository) ([]proto.Repository, error) {
+	var names []string
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		ms, err := d.store.GetRepoForks(ctx, tx, repo.ID())
+		for _, m := range ms {
+			names = append(names, m.Name)
+		}
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	forks := make([]proto.Repository, 0, len(names))
+	for _, name := range names {
+		r, err := d.Repository(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		forks = append(forks, r)
+	}
+
+	return forks, nil
+}
+
+// initFork makes a new repository a fork of parent: it gets the branches,
+// tags, and LFS objects of parent, and borrows its git objects. Parent keeps
+// its unreachable objects from then on, they may be all the fork has.
+func (d *Backend) initFork(ctx context.Context, tx *db.Tx, name string, dst *gitb.Repository, parent proto.Repository) error {
+	src, err := parent.Open()
+	if err != nil {
+		return err
+	}
+
+	if err := src.SetKeepUnreachable(true); err != nil {
+		return err
+	}
+	if err := dst.Fork(src, forkTimeout); err != nil {
+		return err
+	}
+
+	m, err := d.store.GetRepoByName(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+	if err := d.store.CreateRepoFork(ctx, tx, m.ID, parent.ID()); err != nil {
+		return err
+	}
+
+	// LFS objects are stored per repository.
+	objs, err := d.store.GetLFSObjects(ctx, tx, parent.ID())
+	if err != nil || len(objs) == 0 {
+		return err
+	}
+	srcStrg, err := storage.NewLFSStorage(d.cfg, parent.ID())
+	if err != nil {
+		return err
+	}
+	dstStrg, err := storage.NewLFSStorage(d.cfg, m.ID)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		p := path.Join("objects", lfs.Pointer{Oid: obj.Oid, Size: obj.Size}.RelativePath())
+		if err := copyLFSObject(srcStrg, dstStrg, p); err != nil {
+			return err
+		}
+		if err := d.store.CreateLFSObject(ctx, tx, m.ID, obj.Oid, obj.Size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyLFSObject copies an LFS object from a storage to another.
+func copyLFSObject(src, dst storage.Storage, p string) error {
+	obj, err := src.Open(p)
+	if err != nil {
+		return err
+	}
+	defer obj.Close() //nolint: errcheck
+
+	_, err = dst.Put(p, obj)
+	return err
+}
+
+// dissociateForks makes the forks of a repository copy the objects they
+// borrow from it, so that it can be deleted. Forks of forks keep borrowing
+// from their own parent.
+func (d *Backend) dissociateForks(ctx context.Context, repo proto.Repository) error {
+	forks, err := d.RepositoryForks(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range forks {
+		if err := d.dissociateFork(ctx, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dissociateFork makes a fork copy the objects it borrows from its parent,
+// after which it no longer is a fork.
+func (d *Backend) dissociateFork(ctx context.Context, fork proto.Repository) error {
+	release, err := d.ops.maintain(fork.Name())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	r, err := fork.Open()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := r.Dissociate(forkTimeout); err != nil {
+		return err
+	}
+	d.logger.Info("fork dissociated", "repo", fork.Name(), "duration", time.Since(start))
+
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.DeleteRepoFork(ctx, tx, fork.ID())
+	})); err != nil {
+		return err
+	}
+
+	if _, err := d.UpdateRepoStats(ctx, fork); err != nil {
+		d.logger.Error("error updating repository stats", "repo", fork.Name(), "err", err)
+	}
+
+	return nil
+}
+
+// forkRemoved lets a repository prune its unreachable objects again once it
+// has no forks left.
+func (d *Backend) forkRemoved(ctx context.Context, parent proto.Repository) error {
+	forks, err := d.RepositoryForks(ctx, parent)
+	if err != nil || len(forks) > 0 {
+		return err
+	}
+
+	r, err := parent.Open()
+	if err != nil {
+		return err
+	}
+
+	return r.SetKeepUnreachable(false)
+}
+
+// relinkForks points the alternates of a renamed repository, and of its
+// forks, to the new paths of their parents.
+func (d *Backend) relinkForks(ctx context.Context, repo proto.Repository) error {
+	parent, err := d.RepositoryParent(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if parent != nil {
+		if err := d.linkFork(repo, parent); err != nil {
+			return err
+		}
+	}
+
+	forks, err := d.RepositoryForks(ctx, repo)
+	if err != nil {
+		return err
+	}
+	for _, f := range forks {
+		if err := d.linkFork(f, repo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// linkFork points the alternates of a fork to the objects of its parent.
+func (d *Backend) linkFork(fork proto.Repository, parent proto.Repository) error {
+	r, err := fork.Open()
+	if err != nil {
+		return err
+	}
+
+	return r.SetAlternates(filepath.Join(d.repoPath(parent.Name()), "objects"))
+}
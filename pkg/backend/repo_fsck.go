@@ -0,0 +1,55 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// fsckTimeout is how long an integrity check may run.
+const fsckTimeout = 30 * time.Minute
+
+var fsckProblemsGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "soft_serve",
+	Subsystem: "repos",
+	Name:      "fsck_problems",
+	Help:      "The number of problems found by the last integrity check of a repository",
+}, []string{"repo"})
+
+// FsckRepository checks the integrity of a repository with git fsck --full,
+// writing the progress of git to progress unless it's nil. It's read-only and
+// runs alongside clones and fetches, but keeps the repository from being
+// renamed. Corrupt repositories are logged, and their number of problems is
+// exported as a metric.
+func (d *Backend) FsckRepository(ctx context.Context, repo proto.Repository, progress io.Writer) (git.FsckResult, error) {
+	release, err := d.ops.acquire(repo.Name())
+	if err != nil {
+		return git.FsckResult{}, err
+	}
+	defer release()
+
+	r, err := repo.Open()
+	if err != nil {
+		return git.FsckResult{}, err
+	}
+
+	start := time.Now()
+	res, err := r.Fsck(ctx, fsckTimeout, progress)
+	if err != nil {
+		return git.FsckResult{}, err
+	}
+
+	fsckProblemsGauge.WithLabelValues(repo.Name()).Set(float64(len(res.Problems)))
+	if res.Corrupt() {
+		d.logger.Error("repository is corrupt", "repo", repo.Name(), "problems", len(res.Problems), "first", res.Problems[0])
+	} else {
+		d.logger.Info("repository checked", "repo", repo.Name(), "duration", time.Since(start), "dangling", len(res.Dangling))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,199 @@
+package backend
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// gcTimeout is how long a garbage collection may run.
+const gcTimeout = 30 * time.Minute
+
+// GCResult is the outcome of the garbage collection of a repository.
+type GCResult struct {
+	// Duration is how long the collection took.
+	Duration time.Duration
+
+	// Reclaimed is the number of bytes freed, it's negative when the
+	// repository grew.
+	Reclaimed int64
+}
+
+// GCRepository runs git gc on a repository. It fails with
+// [proto.ErrRepoBusy] when a push is running against the repository, or when
+// it's already being collected.
+func (d *Backend) GCRepository(ctx context.Context, repo proto.Repository) (GCResult, error) {
+	release, err := d.ops.gc(repo.Name())
+	if err != nil {
+		return GCResult{}, err
+	}
+	defer release()
+
+	r, err := repo.Open()
+	if err != nil {
+		return GCResult{}, err
+	}
+
+	before, err := r.DiskSize()
+	if err != nil {
+		return GCResult{}, err
+	}
+
+	pack, err := d.PackOptions(ctx, repo.Name())
+	if err != nil {
+		return GCResult{}, err
+	}
+
+	start := time.Now()
+	if err := r.GC(gcTimeout, pack); err != nil {
+		return GCResult{}, err
+	}
+
+	after, err := r.DiskSize()
+	if err != nil {
+		return GCResult{}, err
+	}
+
+	res := GCResult{
+		Duration:  time.Since(start),
+		Reclaimed: before - after,
+	}
+	d.logger.Info("repository garbage collected", "repo", repo.Name(), "duration", res.Duration, "reclaimed", res.Reclaimed)
+
+	if err := d.setRepoSetting(ctx, repo.Name(), gcAtKey, start.UTC().Format(time.RFC3339)); err != nil {
+		return res, err
+	}
+
+	if _, err := d.UpdateRepoStats(ctx, repo); err != nil {
+		d.logger.Error("error updating repository stats", "repo", repo.Name(), "err", err)
+	}
+
+	return res, nil
+}
+
+// WriteBitmaps writes the commit-graph and the reachability bitmap of a
+// repository, repacking it into a single pack first when needed. Nothing is
+// done when they're up to date. It fails with [proto.ErrRepoBusy] when any git
+// operation is running against the repository. Fetches that start meanwhile
+// are fine, git replaces the packs and the commit-graph atomically.
+func (d *Backend) WriteBitmaps(ctx context.Context, repo proto.Repository) error {
+	r, err := repo.Open()
+	if err != nil {
+		return err
+	}
+
+	c, err := r.CountObjects()
+	if err != nil {
+		return err
+	}
+	if c.Loose == 0 && c.Packs == 0 {
+		// Empty repositories have nothing to index.
+		return nil
+	}
+
+	bitmap, err := r.HasBitmap()
+	if err != nil {
+		return err
+	}
+	graph, err := r.HasCommitGraph()
+	if err != nil {
+		return err
+	}
+
+	// Bitmaps only cover a single pack, pushes add packs. Forks don't get
+	// any, the pack would have to include the objects of their parent.
+	alts, err := r.Alternates()
+	if err != nil {
+		return err
+	}
+	repack := (!bitmap || c.Packs > 1) && len(alts) == 0
+	if !repack && graph {
+		return nil
+	}
+
+	release, err := d.ops.maintain(repo.Name())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	start := time.Now()
+	if repack {
+		pack, err := d.PackOptions(ctx, repo.Name())
+		if err != nil {
+			return err
+		}
+		if err := r.WriteBitmaps(gcTimeout, pack); err != nil {
+			return err
+		}
+	}
+	if err := r.WriteCommitGraph(gcTimeout); err != nil {
+		return err
+	}
+	d.logger.Info("repository bitmaps written", "repo", repo.Name(), "duration", time.Since(start), "repacked", repack)
+
+	if repack {
+		if _, err := d.UpdateRepoStats(ctx, repo); err != nil {
+			d.logger.Error("error updating repository stats", "repo", repo.Name(), "err", err)
+		}
+	}
+
+	return nil
+}
+
+// LastGC returns when a repository was last garbage collected, the zero time
+// if it never was.
+func (d *Backend) LastGC(ctx context.Context, repo string) (time.Time, error) {
+	v, err := d.repoSetting(ctx, repo, gcAtKey)
+	if err != nil || v == "" {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339, v)
+}
+
+// GCEnabled returns whether a repository is garbage collected by the
+// repo-gc job. It's enabled unless the repository opted out.
+func (d *Backend) GCEnabled(ctx context.Context, repo string) (bool, error) {
+	v, err := d.repoSetting(ctx, repo, gcKey)
+	if err != nil || v == "" {
+		return true, err
+	}
+
+	return strconv.ParseBool(v)
+}
+
+// SetGCEnabled opts a repository in or out of the repo-gc job. Repositories
+// that opted out can still be collected with [Backend.GCRepository].
+func (d *Backend) SetGCEnabled(ctx context.Context, repo string, enabled bool) error {
+	var v string
+	if !enabled {
+		v = strconv.FormatBool(enabled)
+	}
+
+	return d.setRepoSetting(ctx, repo, gcKey, v)
+}
+
+// BitmapsEnabled returns whether the repo-gc job maintains the commit-graph
+// and the reachability bitmap of a repository, and whether pushes write
+// bitmaps when they repack it. It's disabled unless the repository opted in.
+func (d *Backend) BitmapsEnabled(ctx context.Context, repo string) (bool, error) {
+	v, err := d.repoSetting(ctx, repo, bitmapsKey)
+	if err != nil || v == "" {
+		return false, err
+	}
+
+	return strconv.ParseBool(v)
+}
+
+// SetBitmapsEnabled opts a repository in or out of bitmaps maintenance.
+func (d *Backend) SetBitmapsEnabled(ctx context.Context, repo string, enabled bool) error {
+	var v string
+	if enabled {
+		v = strconv.FormatBool(enabled)
+	}
+
+	return d.setRepoSetting(ctx, repo, bitmapsKey, v)
+}
@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// RepoLanguages returns the language breakdown of the default branch of a
+// repository, sorted by size. It's computed when the repository has none yet,
+// and may otherwise lag behind the default branch. Repositories have no
+// languages when they're empty or the breakdown is disabled.
+func (d *Backend) RepoLanguages(ctx context.Context, repo proto.Repository) ([]git.Language, error) {
+	if !d.cfg.Repo.Languages {
+		return nil, nil
+	}
+
+	m, err := d.repoLanguages(ctx, repo)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return d.UpdateRepoLanguages(ctx, repo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeRepoLanguages(m)
+}
+
+// UpdateRepoLanguages computes and stores the language breakdown of the
+// default branch of a repository, unless it's already the one of the head of
+// the branch.
+func (d *Backend) UpdateRepoLanguages(ctx context.Context, repo proto.Repository) ([]git.Language, error) {
+	if !d.cfg.Repo.Languages {
+		return nil, nil
+	}
+
+	r, err := repo.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := r.HEAD()
+	if err != nil {
+		// Empty repository
+		return nil, nil //nolint:nilerr
+	}
+
+	m, err := d.repoLanguages(ctx, repo)
+	switch {
+	case err == nil && m.CommitID == head.ID:
+		return decodeRepoLanguages(m)
+	case err != nil && !errors.Is(err, db.ErrRecordNotFound):
+		return nil, err
+	}
+
+	langs, err := r.Languages(head.ID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(langs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.SetRepoLanguagesByRepoID(ctx, tx, repo.ID(), head.ID, string(b))
+		}),
+	); err != nil {
+		return nil, err
+	}
+
+	d.logger.Debug("repository languages updated", "repo", repo.Name(), "commit", head.ID, "languages", len(langs))
+	return langs, nil
+}
+
+// repoLanguages returns the stored language breakdown of a repository.
+func (d *Backend) repoLanguages(ctx context.Context, repo proto.Repository) (models.RepoLanguages, error) {
+	var m models.RepoLanguages
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetRepoLanguagesByRepoID(ctx, tx, repo.ID())
+		return err
+	})
+
+	return m, db.WrapError(err)
+}
+
+func decodeRepoLanguages(m models.RepoLanguages) ([]git.Language, error) {
+	var langs []git.Language
+	if err := json.Unmarshal([]byte(m.Languages), &langs); err != nil {
+		return nil, err
+	}
+
+	return langs, nil
+}
@@ -0,0 +1,156 @@
+package backend
+
+import (
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// opLimiter limits the number of git operations running concurrently against
+// each repository, with separate limits for reads and writes, and against all
+// repositories. Operations over the limits wait up to timeout for a slot.
+type opLimiter struct {
+	maxReads  int
+	maxWrites int
+	timeout   time.Duration
+
+	// global is nil if there's no server wide limit.
+	global chan struct{}
+
+	mu    sync.Mutex
+	repos map[string]*repoSlots
+}
+
+// repoSlots are the semaphores of a repository, refs counts the operations
+// holding or waiting for them.
+type repoSlots struct {
+	reads  chan struct{}
+	writes chan struct{}
+	refs   int
+}
+
+// newOpLimiter returns a new operations limiter with the concurrency limits
+// of cfg.
+func newOpLimiter(cfg config.RepoConfig) *opLimiter {
+	l := &opLimiter{
+		maxReads:  cfg.MaxConcurrentReads,
+		maxWrites: cfg.MaxConcurrentWrites,
+		timeout:   time.Duration(cfg.QueueTimeout) * time.Second,
+		repos:     make(map[string]*repoSlots),
+	}
+	if cfg.MaxConcurrentOperations > 0 {
+		l.global = make(chan struct{}, cfg.MaxConcurrentOperations)
+	}
+
+	return l
+}
+
+// acquire waits for a read or write slot of a repository and a server wide
+// slot. It fails with proto.ErrServerBusy if they aren't available within
+// the timeout.
+func (l *opLimiter) acquire(name string, write bool) (func(), error) {
+	if l.global == nil && l.maxReads <= 0 && l.maxWrites <= 0 {
+		return func() {}, nil
+	}
+
+	slots := l.slots(name)
+	sem := slots.reads
+	if write {
+		sem = slots.writes
+	}
+
+	var timeout <-chan time.Time
+	if l.timeout > 0 {
+		t := time.NewTimer(l.timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	if !acquireSlot(sem, timeout) {
+		l.unref(name)
+		return nil, proto.ErrServerBusy
+	}
+	if !acquireSlot(l.global, timeout) {
+		releaseSlot(sem)
+		l.unref(name)
+		return nil, proto.ErrServerBusy
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			releaseSlot(l.global)
+			releaseSlot(sem)
+			l.unref(name)
+		})
+	}, nil
+}
+
+// slots returns the semaphores of a repository and references them.
+func (l *opLimiter) slots(name string) *repoSlots {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s, ok := l.repos[name]
+	if !ok {
+		s = &repoSlots{}
+		if l.maxReads > 0 {
+			s.reads = make(chan struct{}, l.maxReads)
+		}
+		if l.maxWrites > 0 {
+			s.writes = make(chan struct{}, l.maxWrites)
+		}
+		l.repos[name] = s
+	}
+	s.refs++
+
+	return s
+}
+
+// unref drops a reference to the semaphores of a repository, they're
+// forgotten once no operation uses them.
+func (l *opLimiter) unref(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if s, ok := l.repos[name]; ok {
+		s.refs--
+		if s.refs <= 0 {
+			delete(l.repos, name)
+		}
+	}
+}
+
+// acquireSlot takes a slot of sem, waiting until timeout fires if it's full.
+// A nil sem always has slots available, and a nil timeout fails right away.
+func acquireSlot(sem chan struct{}, timeout <-chan time.Time) bool {
+	if sem == nil {
+		return true
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return true
+	default:
+	}
+
+	if timeout == nil {
+		return false
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-timeout:
+		return false
+	}
+}
+
+// releaseSlot gives back a slot of sem.
+func releaseSlot(sem chan struct{}) {
+	if sem != nil {
+		<-sem
+	}
+}
@@ -0,0 +1,198 @@
+package backend
+
+import (
+	"sync"
+
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// repoOps keeps track of the git operations running against repositories,
+// of the pushes among them, and of the repositories being renamed or garbage
+// collected.
+type repoOps struct {
+	mu         sync.Mutex
+	active     map[string]int
+	pushes     map[string]int
+	renaming   map[string]struct{}
+	collecting map[string]struct{}
+}
+
+// newRepoOps returns a new repository operations tracker.
+func newRepoOps() *repoOps {
+	return &repoOps{
+		active:     make(map[string]int),
+		pushes:     make(map[string]int),
+		renaming:   make(map[string]struct{}),
+		collecting: make(map[string]struct{}),
+	}
+}
+
+// acquire marks an operation as running against a repository. It fails
+// with proto.ErrRepoBusy if the repository is being renamed.
+func (o *repoOps) acquire(name string) (func(), error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.renaming[name]; ok {
+		return nil, proto.ErrRepoBusy
+	}
+
+	o.active[name]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+
+			o.active[name]--
+			if o.active[name] <= 0 {
+				delete(o.active, name)
+			}
+		})
+	}, nil
+}
+
+// acquirePush marks a push as running against a repository. Repositories
+// aren't garbage collected while pushes are running against them.
+func (o *repoOps) acquirePush(name string) (func(), error) {
+	release, err := o.acquire(name)
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	o.pushes[name]++
+	o.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			o.mu.Lock()
+			o.pushes[name]--
+			if o.pushes[name] <= 0 {
+				delete(o.pushes, name)
+			}
+			o.mu.Unlock()
+
+			release()
+		})
+	}, nil
+}
+
+// gc marks a repository as being garbage collected, which is an operation
+// running against it. It fails with proto.ErrRepoBusy if a push is running
+// against the repository, or if it's being renamed or already collected.
+func (o *repoOps) gc(name string) (func(), error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	_, renaming := o.renaming[name]
+	_, collecting := o.collecting[name]
+	if renaming || collecting || o.pushes[name] > 0 {
+		return nil, proto.ErrRepoBusy
+	}
+
+	return o.collect(name), nil
+}
+
+// maintain is gc for the maintenance that rewrites the packs of a
+// repository. It also fails with proto.ErrRepoBusy if a fetch is running
+// against the repository.
+func (o *repoOps) maintain(name string) (func(), error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	_, renaming := o.renaming[name]
+	_, collecting := o.collecting[name]
+	if renaming || collecting || o.active[name] > 0 {
+		return nil, proto.ErrRepoBusy
+	}
+
+	return o.collect(name), nil
+}
+
+// collect marks a repository as being collected, o.mu must be held.
+func (o *repoOps) collect(name string) func() {
+	o.active[name]++
+	o.collecting[name] = struct{}{}
+	return func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+
+		delete(o.collecting, name)
+		o.active[name]--
+		if o.active[name] <= 0 {
+			delete(o.active, name)
+		}
+	}
+}
+
+// rename marks repositories as being renamed. It fails with
+// proto.ErrRepoBusy if any of them is in use or already being renamed.
+func (o *repoOps) rename(names ...string) (func(), error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, name := range names {
+		if _, ok := o.renaming[name]; ok {
+			return nil, proto.ErrRepoBusy
+		}
+		if o.active[name] > 0 {
+			return nil, proto.ErrRepoBusy
+		}
+	}
+
+	for _, name := range names {
+		o.renaming[name] = struct{}{}
+	}
+
+	return func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+
+		for _, name := range names {
+			delete(o.renaming, name)
+		}
+	}, nil
+}
+
+// AcquireRepository marks a git operation as running against a repository
+// until the returned function is called. Repositories can't be renamed while
+// operations are running against them, and operations can't start while
+// they're being renamed, in which case proto.ErrRepoBusy is returned.
+//
+// Operations over the concurrency limits of the server wait briefly for
+// others to finish, proto.ErrServerBusy is returned if none do.
+func (d *Backend) AcquireRepository(name string) (release func(), err error) {
+	return d.acquireRepository(utils.SanitizeRepo(name), false)
+}
+
+// AcquireRepositoryPush is AcquireRepository for pushes. Repositories aren't
+// garbage collected while pushes are running against them.
+func (d *Backend) AcquireRepositoryPush(name string) (release func(), err error) {
+	return d.acquireRepository(utils.SanitizeRepo(name), true)
+}
+
+func (d *Backend) acquireRepository(name string, push bool) (func(), error) {
+	unlimit, err := d.limits.acquire(name, push)
+	if err != nil {
+		d.logger.Warn("git operation over the concurrency limits", "repo", name, "push", push)
+		return nil, err
+	}
+
+	acquire := d.ops.acquire
+	if push {
+		acquire = d.ops.acquirePush
+	}
+	release, err := acquire(name)
+	if err != nil {
+		unlimit()
+		return nil, err
+	}
+
+	return func() {
+		release()
+		unlimit()
+	}, nil
+}
@@ -0,0 +1,199 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+func TestRepoOps(t *testing.T) {
+	ops := newRepoOps()
+
+	release, err := ops.acquire("foo")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	// Repositories in use can't be renamed.
+	if _, err := ops.rename("foo", "bar"); !errors.Is(err, proto.ErrRepoBusy) {
+		t.Fatalf("rename in use: got %v, want %v", err, proto.ErrRepoBusy)
+	}
+
+	release()
+	release() // releasing twice is a no-op
+
+	done, err := ops.rename("foo", "bar")
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+
+	// Neither the old nor the new name can be used during the rename.
+	for _, name := range []string{"foo", "bar"} {
+		if _, err := ops.acquire(name); !errors.Is(err, proto.ErrRepoBusy) {
+			t.Errorf("acquire %q during rename: got %v, want %v", name, err, proto.ErrRepoBusy)
+		}
+	}
+	if _, err := ops.rename("bar", "baz"); !errors.Is(err, proto.ErrRepoBusy) {
+		t.Errorf("concurrent rename: got %v, want %v", err, proto.ErrRepoBusy)
+	}
+
+	done()
+
+	release, err = ops.acquire("bar")
+	if err != nil {
+		t.Fatalf("acquire after rename: %v", err)
+	}
+	release()
+
+	if len(ops.active) != 0 || len(ops.renaming) != 0 {
+		t.Errorf("leftover state: active %v, renaming %v", ops.active, ops.renaming)
+	}
+}
+
+func TestRepoOpsGC(t *testing.T) {
+	ops := newRepoOps()
+
+	// Fetches don't keep repositories from being collected, pushes do.
+	release, err := ops.acquire("foo")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	done, err := ops.gc("foo")
+	if err != nil {
+		t.Fatalf("gc during fetch: %v", err)
+	}
+	release()
+
+	// Repositories being collected can't be collected again or renamed.
+	if _, err := ops.gc("foo"); !errors.Is(err, proto.ErrRepoBusy) {
+		t.Errorf("concurrent gc: got %v, want %v", err, proto.ErrRepoBusy)
+	}
+	if _, err := ops.rename("foo", "bar"); !errors.Is(err, proto.ErrRepoBusy) {
+		t.Errorf("rename during gc: got %v, want %v", err, proto.ErrRepoBusy)
+	}
+	done()
+
+	release, err = ops.acquirePush("foo")
+	if err != nil {
+		t.Fatalf("acquire push: %v", err)
+	}
+	if _, err := ops.gc("foo"); !errors.Is(err, proto.ErrRepoBusy) {
+		t.Errorf("gc during push: got %v, want %v", err, proto.ErrRepoBusy)
+	}
+	release()
+	release() // releasing twice is a no-op
+
+	done, err = ops.gc("foo")
+	if err != nil {
+		t.Fatalf("gc after push: %v", err)
+	}
+	done()
+
+	if len(ops.active) != 0 || len(ops.pushes) != 0 || len(ops.collecting) != 0 {
+		t.Errorf("leftover state: active %v, pushes %v, collecting %v", ops.active, ops.pushes, ops.collecting)
+	}
+}
+
+func TestRepoOpsMaintain(t *testing.T) {
+	ops := newRepoOps()
+
+	// Unlike gc, fetches keep repositories from being maintained.
+	release, err := ops.acquire("foo")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if _, err := ops.maintain("foo"); !errors.Is(err, proto.ErrRepoBusy) {
+		t.Errorf("maintain during fetch: got %v, want %v", err, proto.ErrRepoBusy)
+	}
+	release()
+
+	done, err := ops.maintain("foo")
+	if err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+	if _, err := ops.gc("foo"); !errors.Is(err, proto.ErrRepoBusy) {
+		t.Errorf("gc during maintenance: got %v, want %v", err, proto.ErrRepoBusy)
+	}
+	done()
+
+	if len(ops.active) != 0 || len(ops.collecting) != 0 {
+		t.Errorf("leftover state: active %v, collecting %v", ops.active, ops.collecting)
+	}
+}
+
+func TestOpLimiter(t *testing.T) {
+	l := newOpLimiter(config.RepoConfig{
+		MaxConcurrentReads:      1,
+		MaxConcurrentWrites:     1,
+		MaxConcurrentOperations: 3,
+	})
+
+	read, err := l.acquire("foo", false)
+	if err != nil {
+		t.Fatalf("acquire read: %v", err)
+	}
+
+	// Reads and writes have separate limits per repository.
+	if _, err := l.acquire("foo", false); !errors.Is(err, proto.ErrServerBusy) {
+		t.Errorf("second read: got %v, want %v", err, proto.ErrServerBusy)
+	}
+	write, err := l.acquire("foo", true)
+	if err != nil {
+		t.Fatalf("acquire write: %v", err)
+	}
+	other, err := l.acquire("bar", false)
+	if err != nil {
+		t.Fatalf("acquire read of another repository: %v", err)
+	}
+
+	// The server wide limit applies to all repositories.
+	if _, err := l.acquire("baz", false); !errors.Is(err, proto.ErrServerBusy) {
+		t.Errorf("read over the global limit: got %v, want %v", err, proto.ErrServerBusy)
+	}
+
+	read()
+	read() // releasing twice is a no-op
+	write()
+	other()
+
+	release, err := l.acquire("foo", false)
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	release()
+
+	if len(l.repos) != 0 || len(l.global) != 0 {
+		t.Errorf("leftover state: repos %v, global %d", l.repos, len(l.global))
+	}
+}
+
+func TestOpLimiterQueue(t *testing.T) {
+	l := newOpLimiter(config.RepoConfig{
+		MaxConcurrentReads: 1,
+		QueueTimeout:       5,
+	})
+
+	release, err := l.acquire("foo", false)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	// Operations over the limit wait for a slot to be released.
+	done := make(chan error)
+	go func() {
+		release, err := l.acquire("foo", false)
+		if err == nil {
+			release()
+		}
+		done <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	release()
+	if err := <-done; err != nil {
+		t.Errorf("queued acquire: %v", err)
+	}
+}
@@ -0,0 +1,419 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// Repository setting keys.
+const (
+	commitMessagePatternKey = "commit_message_pattern"
+	commitMessageCheckKey   = "commit_message_check"
+	anonAccessKey           = "anon_access"
+	gcKey                   = "gc"
+	gcAtKey                 = "gc_at"
+	bitmapsKey              = "bitmaps"
+	signingFormatKey        = "signing_format"
+	filtersKey              = "filters"
+	sparsePatternsKey       = "sparse_patterns"
+	refCacheKey             = "ref_cache"
+	packWindowKey           = "pack_window"
+	packDepthKey            = "pack_depth"
+	packThreadsKey          = "pack_threads"
+	packUploadKey           = "pack_upload"
+)
+
+// ErrInvalidSparsePattern is returned when setting a sparse pattern that isn't
+// a directory of the repository, see [Backend.SetSparsePatterns].
+var ErrInvalidSparsePattern = errors.New("sparse patterns must be directories without wildcards")
+
+// repoSetting returns the value of a repository setting, or an empty string
+// if it isn't set.
+func (d *Backend) repoSetting(ctx context.Context, repo string, key string) (string, error) {
+	repo = utils.SanitizeRepo(repo)
+	var value string
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		value, err = d.store.GetRepoSettingByName(ctx, tx, repo, key)
+		return err
+	}); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", db.WrapError(err)
+	}
+
+	return value, nil
+}
+
+// setRepoSetting sets a repository setting. An empty value removes it.
+func (d *Backend) setRepoSetting(ctx context.Context, repo string, key string, value string) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if value == "" {
+				return d.store.DeleteRepoSettingByName(ctx, tx, repo, key)
+			}
+			return d.store.SetRepoSettingByName(ctx, tx, repo, key, value)
+		}),
+	)
+}
+
+// CommitMessagePattern returns the regular expression commit subjects pushed
+// to a repository must match.
+func (d *Backend) CommitMessagePattern(ctx context.Context, repo string) (string, error) {
+	return d.repoSetting(ctx, repo, commitMessagePatternKey)
+}
+
+// SetCommitMessagePattern sets the regular expression commit subjects pushed
+// to a repository must match. An empty pattern disables the check.
+func (d *Backend) SetCommitMessagePattern(ctx context.Context, repo string, pattern string) error {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("invalid commit message pattern: %w", err)
+	}
+
+	if pattern == "" {
+		if err := d.setRepoSetting(ctx, repo, commitMessageCheckKey, ""); err != nil {
+			return err
+		}
+	}
+
+	return d.setRepoSetting(ctx, repo, commitMessagePatternKey, pattern)
+}
+
+// CommitMessageCheck returns whether commit subjects pushed to a repository
+// are checked against its commit message pattern.
+func (d *Backend) CommitMessageCheck(ctx context.Context, repo string) (bool, error) {
+	v, err := d.repoSetting(ctx, repo, commitMessageCheckKey)
+	if err != nil || v == "" {
+		return false, err
+	}
+
+	return strconv.ParseBool(v)
+}
+
+// SetCommitMessageCheck enables or disables the commit message check of a
+// repository.
+func (d *Backend) SetCommitMessageCheck(ctx context.Context, repo string, enabled bool) error {
+	if enabled {
+		pattern, err := d.CommitMessagePattern(ctx, repo)
+		if err != nil {
+			return err
+		}
+		if pattern == "" {
+			return proto.ErrNoCommitMessagePattern
+		}
+	}
+
+	return d.setRepoSetting(ctx, repo, commitMessageCheckKey, strconv.FormatBool(enabled))
+}
+
+// SigningFormat returns the signature format commits pushed to a repository
+// with signers must be signed in.
+func (d *Backend) SigningFormat(ctx context.Context, repo string) (git.SignatureFormat, error) {
+	v, err := d.repoSetting(ctx, repo, signingFormatKey)
+	if err != nil {
+		return "", err
+	}
+
+	return git.ParseSignatureFormat(v)
+}
+
+// SetSigningFormat sets the signature format commits pushed to a repository
+// with signers must be signed in. SignatureFormatAny accepts both SSH and
+// OpenPGP signatures.
+func (d *Backend) SetSigningFormat(ctx context.Context, repo string, format git.SignatureFormat) error {
+	if format == git.SignatureFormatAny {
+		return d.setRepoSetting(ctx, repo, signingFormatKey, "")
+	}
+
+	return d.setRepoSetting(ctx, repo, signingFormatKey, string(format))
+}
+
+// RepoAnonAccess returns the access level anonymous users get to a
+// repository, and whether the repository overrides the server default.
+func (d *Backend) RepoAnonAccess(ctx context.Context, repo string) (access.AccessLevel, bool, error) {
+	level, ok, err := d.repoAnonAccess(ctx, repo)
+	if err != nil || ok {
+		return level, ok, err
+	}
+
+	return d.AnonAccess(ctx), false, nil
+}
+
+// repoAnonAccess returns the anonymous access level override of a
+// repository, if any.
+func (d *Backend) repoAnonAccess(ctx context.Context, repo string) (access.AccessLevel, bool, error) {
+	v, err := d.repoSetting(ctx, repo, anonAccessKey)
+	if err != nil || v == "" {
+		return -1, false, err
+	}
+
+	return access.ParseAccessLevel(v), true, nil
+}
+
+// SetRepoAnonAccess overrides the access level anonymous users get to a
+// repository, no-access or read-only. A negative level removes the override
+// so the server default applies.
+func (d *Backend) SetRepoAnonAccess(ctx context.Context, repo string, level access.AccessLevel) error {
+	switch {
+	case level < 0:
+		return d.setRepoSetting(ctx, repo, anonAccessKey, "")
+	case level == access.NoAccess, level == access.ReadOnlyAccess:
+		return d.setRepoSetting(ctx, repo, anonAccessKey, level.String())
+	default:
+		return access.ErrInvalidAccessLevel
+	}
+}
+
+// RepoFilters returns whether clients can use partial clone filters, like
+// blob:none, to fetch a repository, and whether the repository overrides the
+// server default.
+func (d *Backend) RepoFilters(ctx context.Context, repo string) (bool, bool, error) {
+	v, err := d.repoSetting(ctx, repo, filtersKey)
+	if err != nil || v == "" {
+		return !d.cfg.Repo.DisableFilters, false, err
+	}
+
+	enabled, err := strconv.ParseBool(v)
+	return enabled, true, err
+}
+
+// SetRepoFilters enables or disables partial clone filters for a repository,
+// overriding the server default.
+func (d *Backend) SetRepoFilters(ctx context.Context, repo string, enabled bool) error {
+	return d.setRepoSetting(ctx, repo, filtersKey, strconv.FormatBool(enabled))
+}
+
+// ResetRepoFilters removes the partial clone filters override of a
+// repository so the server default applies.
+func (d *Backend) ResetRepoFilters(ctx context.Context, repo string) error {
+	return d.setRepoSetting(ctx, repo, filtersKey, "")
+}
+
+// FiltersDisabled reports whether partial clone filters are disabled for a
+// repository. It's meant for the git transports: errors are logged and the
+// server default applies.
+func (d *Backend) FiltersDisabled(ctx context.Context, repo string) bool {
+	enabled, _, err := d.RepoFilters(ctx, repo)
+	if err != nil {
+		d.logger.Error("error reading repository filters setting", "repo", repo, "err", err)
+		return d.cfg.Repo.DisableFilters
+	}
+
+	return !enabled
+}
+
+// RefCacheEnabled returns whether the ref advertisement of a repository is
+// cached by the HTTP transport. It's disabled unless the repository opted in.
+func (d *Backend) RefCacheEnabled(ctx context.Context, repo string) (bool, error) {
+	v, err := d.repoSetting(ctx, repo, refCacheKey)
+	if err != nil || v == "" {
+		return false, err
+	}
+
+	return strconv.ParseBool(v)
+}
+
+// SetRefCacheEnabled opts a repository in or out of the ref advertisement
+// cache.
+func (d *Backend) SetRefCacheEnabled(ctx context.Context, repo string, enabled bool) error {
+	var v string
+	if enabled {
+		v = strconv.FormatBool(enabled)
+	}
+
+	return d.setRepoSetting(ctx, repo, refCacheKey, v)
+}
+
+// PackOptions returns the options tuning the packs of a repository, zero
+// values use the defaults of git.
+func (d *Backend) PackOptions(ctx context.Context, repo string) (gitb.PackOptions, error) {
+	var opts gitb.PackOptions
+	for _, opt := range []struct {
+		key string
+		val *int
+	}{
+		{packWindowKey, &opts.Window},
+		{packDepthKey, &opts.Depth},
+		{packThreadsKey, &opts.Threads},
+	} {
+		v, err := d.repoSetting(ctx, repo, opt.key)
+		if err != nil {
+			return opts, err
+		}
+		if v == "" {
+			continue
+		}
+		if *opt.val, err = strconv.Atoi(v); err != nil {
+			return opts, err
+		}
+	}
+
+	return opts, nil
+}
+
+// SetPackOptions sets the options tuning the packs of a repository, written
+// by garbage collections and after pushes.
+func (d *Backend) SetPackOptions(ctx context.Context, repo string, opts gitb.PackOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	for _, opt := range []struct {
+		key string
+		val int
+	}{
+		{packWindowKey, opts.Window},
+		{packDepthKey, opts.Depth},
+		{packThreadsKey, opts.Threads},
+	} {
+		var v string
+		if opt.val > 0 {
+			v = strconv.Itoa(opt.val)
+		}
+		if err := d.setRepoSetting(ctx, repo, opt.key, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PackUploadEnabled returns whether the pack options of a repository apply
+// to the packs sent to clones and fetches too. It's disabled unless the
+// repository opted in.
+func (d *Backend) PackUploadEnabled(ctx context.Context, repo string) (bool, error) {
+	v, err := d.repoSetting(ctx, repo, packUploadKey)
+	if err != nil || v == "" {
+		return false, err
+	}
+
+	return strconv.ParseBool(v)
+}
+
+// SetPackUploadEnabled sets whether the pack options of a repository apply
+// to the packs sent to clones and fetches.
+func (d *Backend) SetPackUploadEnabled(ctx context.Context, repo string, enabled bool) error {
+	var v string
+	if enabled {
+		v = strconv.FormatBool(enabled)
+	}
+
+	return d.setRepoSetting(ctx, repo, packUploadKey, v)
+}
+
+// ServicePackOptions returns the pack options of a git service run against a
+// repository: pushes use them for the automatic repack they trigger, and
+// fetches when the repository opted in. It's meant for the git transports:
+// errors are logged and the defaults of git apply.
+func (d *Backend) ServicePackOptions(ctx context.Context, repo string, service git.Service) gitb.PackOptions {
+	switch service {
+	case git.ReceivePackService:
+	case git.UploadPackService:
+		enabled, err := d.PackUploadEnabled(ctx, repo)
+		if err != nil {
+			d.logger.Error("error reading repository pack upload setting", "repo", repo, "err", err)
+		}
+		if !enabled {
+			return gitb.PackOptions{}
+		}
+	default:
+		return gitb.PackOptions{}
+	}
+
+	opts, err := d.PackOptions(ctx, repo)
+	if err != nil {
+		d.logger.Error("error reading repository pack options", "repo", repo, "err", err)
+		return gitb.PackOptions{}
+	}
+
+	return opts
+}
+
+// SparsePatterns returns the sparse-checkout patterns recommended to clone a
+// repository, the directories most users need.
+func (d *Backend) SparsePatterns(ctx context.Context, repo string) ([]string, error) {
+	v, err := d.repoSetting(ctx, repo, sparsePatternsKey)
+	if err != nil || v == "" {
+		return nil, err
+	}
+
+	return strings.Split(v, "\n"), nil
+}
+
+// SetSparsePatterns sets the sparse-checkout patterns recommended to clone a
+// repository. Patterns are directories in the cone mode of git
+// sparse-checkout, without wildcards or leading and trailing slashes. No
+// patterns removes the recommendation.
+func (d *Backend) SetSparsePatterns(ctx context.Context, repo string, patterns []string) error {
+	dirs := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.Trim(p, "/")
+		if p == "" || strings.ContainsAny(p, "*?[]\\!\n") || slices.Contains(strings.Split(p, "/"), "..") {
+			return fmt.Errorf("%w: %q", ErrInvalidSparsePattern, p)
+		}
+		if !slices.Contains(dirs, p) {
+			dirs = append(dirs, p)
+		}
+	}
+
+	return d.setRepoSetting(ctx, repo, sparsePatternsKey, strings.Join(dirs, "\n"))
+}
+
+// checkCommitMessages rejects the push if the subject of any of the new
+// commits doesn't match the repository commit message pattern.
+func (d *Backend) checkCommitMessages(ctx context.Context, repo string, args []hooks.HookArg) error {
+	enabled, err := d.CommitMessageCheck(ctx, repo)
+	if err != nil || !enabled {
+		return err
+	}
+
+	pattern, err := d.CommitMessagePattern(ctx, repo)
+	if err != nil || pattern == "" {
+		return err
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	rp := d.repoPath(repo)
+	for _, arg := range args {
+		// Deleted refs don't introduce any commits.
+		if gitb.IsZeroHash(arg.NewSha) {
+			continue
+		}
+
+		commits, err := git.NewCommitSubjects(ctx, rp, arg.NewSha)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range commits {
+			if !re.MatchString(c.Subject) {
+				return fmt.Errorf("%s: %w: commit %s %q, expected %s", arg.RefName, git.ErrInvalidCommitMessage, c.Hash, c.Subject, pattern)
+			}
+		}
+	}
+
+	return nil
+}
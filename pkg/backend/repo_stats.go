@@ -0,0 +1,89 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// RepoStats returns the statistics of a repository. They're computed when
+// the repository has none yet, and may otherwise lag behind the repository.
+func (d *Backend) RepoStats(ctx context.Context, repo proto.Repository) (models.RepoStats, error) {
+	var m models.RepoStats
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetRepoStatsByRepoID(ctx, tx, repo.ID())
+		return err
+	})
+	if errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+		return d.UpdateRepoStats(ctx, repo)
+	}
+	if err != nil {
+		return models.RepoStats{}, db.WrapError(err)
+	}
+
+	return m, nil
+}
+
+// UpdateRepoStats computes and stores the statistics of a repository: the
+// size of its objects, the number of commits of its default branch, and its
+// default branch.
+func (d *Backend) UpdateRepoStats(ctx context.Context, repo proto.Repository) (models.RepoStats, error) {
+	r, err := repo.Open()
+	if err != nil {
+		return models.RepoStats{}, err
+	}
+
+	size, err := r.DiskSize()
+	if err != nil {
+		return models.RepoStats{}, err
+	}
+
+	// Empty repositories have no default branch nor commits.
+	var branch string
+	var commits int64
+	if head, err := r.HEAD(); err == nil {
+		branch = head.Name().Short()
+		commits, err = r.CountCommits(head)
+		if err != nil {
+			return models.RepoStats{}, err
+		}
+	}
+
+	var m models.RepoStats
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := d.store.SetRepoStatsByRepoID(ctx, tx, repo.ID(), size, commits, branch); err != nil {
+			return err
+		}
+
+		var err error
+		m, err = d.store.GetRepoStatsByRepoID(ctx, tx, repo.ID())
+		return err
+	}); err != nil {
+		return models.RepoStats{}, db.WrapError(err)
+	}
+
+	return m, nil
+}
+
+// repoPushed records a push to a repository and refreshes its statistics.
+func (d *Backend) repoPushed(ctx context.Context, name string) error {
+	repo, err := d.Repository(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.SetRepoPushedByRepoID(ctx, tx, repo.ID())
+		}),
+	); err != nil {
+		return err
+	}
+
+	_, err = d.UpdateRepoStats(ctx, repo)
+	return err
+}
@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/audit"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/charmbracelet/soft-serve/pkg/webhook"
+)
+
+// ErrAlreadyOwner is returned when transferring a repository to its owner.
+var ErrAlreadyOwner = errors.New("user already owns the repository")
+
+// TransferRepository makes a user the owner of a repository. The collaborator
+// grant the new owner had on the repository is removed since owners have
+// admin access, and the previous owner keeps only the access granted to them
+// otherwise.
+//
+// The transfer is recorded in the audit log, the source of the operation is
+// read from the context, see [audit.WithSource].
+func (d *Backend) TransferRepository(ctx context.Context, name string, username string) error {
+	name = utils.SanitizeRepo(name)
+	repo, err := d.Repository(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	owner, err := d.User(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if repo.UserID() == owner.ID() {
+		return ErrAlreadyOwner
+	}
+
+	oldOwner := "-"
+	var old *webhook.User
+	if repo.UserID() != 0 {
+		u, err := d.UserByID(ctx, repo.UserID())
+		if err != nil {
+			return err
+		}
+		oldOwner = u.Username()
+		old = &webhook.User{ID: u.ID(), Username: u.Username()}
+	}
+
+	user := proto.UserFromContext(ctx)
+	level := d.AccessLevelForUser(ctx, name, user)
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := d.store.SetRepoUserIDByName(ctx, tx, name, owner.ID()); err != nil {
+			return err
+		}
+
+		if _, err := d.store.GetCollabByUsernameAndRepo(ctx, tx, owner.Username(), name); err != nil {
+			if errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		return d.store.RemoveCollabByUsernameAndRepo(ctx, tx, owner.Username(), name)
+	}); err != nil {
+		return db.WrapError(err)
+	}
+
+	// Delete cache
+	d.cache.Delete(name)
+
+	if d.audit != nil {
+		src := audit.SourceFromContext(ctx)
+		e := audit.Entry{
+			Time:        time.Now().UTC(),
+			Repo:        name,
+			Service:     "repo-transfer",
+			Transport:   src.Transport,
+			AccessLevel: level,
+			RemoteAddr:  src.RemoteAddr,
+			Outcome:     audit.Allowed,
+			Details:     fmt.Sprintf("owner %s -> %s", oldOwner, owner.Username()),
+		}
+		if user != nil {
+			e.Username = user.Username()
+		}
+		if err := d.audit.Record(ctx, e); err != nil {
+			d.logger.Error("error recording audit entry", "err", err, "repo", e.Repo, "username", e.Username)
+		}
+	}
+
+	repo, err = d.Repository(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	wh, err := webhook.NewRepositoryEvent(ctx, user, repo, webhook.RepositoryEventActionOwnershipChange)
+	if err != nil {
+		return err
+	}
+
+	wh.OldOwner = old
+	return webhook.SendEvent(ctx, wh)
+}
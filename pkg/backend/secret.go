@@ -0,0 +1,112 @@
+package backend
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// secretKey returns the key encrypting the secrets stored in the database,
+// such as hook environment variables and mirror credentials. When create is
+// true, a missing key is generated.
+func (d *Backend) secretKey(create bool) ([]byte, error) {
+	path := d.cfg.Hooks.SecretKeyPath
+	if path == "" {
+		return nil, errors.New("hooks secret key path isn't configured")
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && create {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return nil, err
+		}
+
+		// Another process may be creating the key too, keep the first one.
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		switch {
+		case err == nil:
+			_, err = fmt.Fprintln(f, hex.EncodeToString(key))
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return nil, err
+			}
+			d.logger.Info("generated hooks secret key", "path", path)
+			return key, nil
+		case errors.Is(err, os.ErrExist):
+			b, err = os.ReadFile(path)
+		default:
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read hooks secret key: %w", err)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("invalid hooks secret key %s, want 32 hex-encoded bytes", path)
+	}
+
+	return key, nil
+}
+
+// encryptSecret encrypts a value with AES-GCM. The name is authenticated so
+// that values can't be swapped between secrets.
+func encryptSecret(key []byte, name string, value string) (string, error) {
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), []byte(name))), nil
+}
+
+// decryptSecret decrypts a value encrypted by encryptSecret.
+func decryptSecret(key []byte, name string, value string) (string, error) {
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	if len(b) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+
+	plain, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
+
+func newSecretCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
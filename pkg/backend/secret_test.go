@@ -0,0 +1,32 @@
+package backend
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	enc, err := encryptSecret(key, "TOKEN", "s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains([]byte(enc), []byte("s3cr3t")) {
+		t.Fatalf("encrypted value %q contains the plain text", enc)
+	}
+
+	got, err := decryptSecret(key, "TOKEN", enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "s3cr3t" {
+		t.Errorf("decryptSecret = %q, want %q", got, "s3cr3t")
+	}
+
+	if _, err := decryptSecret(key, "OTHER", enc); err == nil {
+		t.Error("decrypting the value of another secret succeeded")
+	}
+	if _, err := decryptSecret(bytes.Repeat([]byte{8}, 32), "TOKEN", enc); err == nil {
+		t.Error("decrypting with another key succeeded")
+	}
+}
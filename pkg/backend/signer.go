@@ -0,0 +1,181 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// AddSigner allows the key pk to sign commits pushed to a repository.
+// Once a repository has signers, pushes containing commits that aren't
+// signed by one of them are rejected.
+func (d *Backend) AddSigner(ctx context.Context, repo string, principal string, pk gossh.PublicKey) error {
+	return d.addSigner(ctx, repo, principal, sshutils.MarshalAuthorizedKey(pk))
+}
+
+// AddGPGSigner is like AddSigner for the armored OpenPGP public key key.
+func (d *Backend) AddGPGSigner(ctx context.Context, repo string, principal string, key string) error {
+	if _, err := git.GPGKeyFingerprint(ctx, key); err != nil {
+		return err
+	}
+
+	return d.addSigner(ctx, repo, principal, strings.TrimSpace(key))
+}
+
+func (d *Backend) addSigner(ctx context.Context, repo string, principal string, key string) error {
+	if principal == "" || strings.ContainsAny(principal, " \t\r\n") {
+		return proto.ErrInvalidPrincipal
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.AddSignerByRepo(ctx, tx, repo, principal, key)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrSignerExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// RemoveSigner removes all the keys of principal from the signers of a
+// repository.
+func (d *Backend) RemoveSigner(ctx context.Context, repo string, principal string) error {
+	repo = utils.SanitizeRepo(repo)
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.RemoveSignerByRepo(ctx, tx, repo, principal)
+		}),
+	)
+}
+
+// Signers returns the keys allowed to sign commits pushed to a repository.
+func (d *Backend) Signers(ctx context.Context, repo string) ([]models.RepoSigner, error) {
+	repo = utils.SanitizeRepo(repo)
+	var signers []models.RepoSigner
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		signers, err = d.store.ListSignersByRepo(ctx, tx, repo)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return signers, nil
+}
+
+// checkSignedCommits rejects the push if any of the new commits isn't signed
+// by one of the repository signers in the repository signing format. It's a
+// no-op for repositories without signers.
+func (d *Backend) checkSignedCommits(ctx context.Context, repo string, args []hooks.HookArg) error {
+	signers, err := d.Signers(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if len(signers) == 0 {
+		return nil
+	}
+
+	format, err := d.SigningFormat(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	var sshSigners []models.RepoSigner
+	var gpgKeys []string
+	for _, s := range signers {
+		if git.IsGPGKey(s.PublicKey) {
+			gpgKeys = append(gpgKeys, s.PublicKey)
+		} else {
+			sshSigners = append(sshSigners, s)
+		}
+	}
+
+	opts := git.VerifyOptions{Format: format}
+	if len(sshSigners) > 0 {
+		f, err := os.CreateTemp("", "soft-serve-allowed-signers-*")
+		if err != nil {
+			return err
+		}
+
+		defer os.Remove(f.Name()) //nolint: errcheck
+		if err := writeAllowedSigners(f, sshSigners); err != nil {
+			f.Close() //nolint: errcheck
+			return err
+		}
+
+		if err := f.Close(); err != nil {
+			return err
+		}
+		opts.AllowedSigners = f.Name()
+	}
+
+	if len(gpgKeys) > 0 {
+		home, err := os.MkdirTemp("", "soft-serve-gnupg-*")
+		if err != nil {
+			return err
+		}
+
+		defer os.RemoveAll(home) //nolint: errcheck
+		if err := git.ImportGPGKeys(ctx, home, gpgKeys); err != nil {
+			return err
+		}
+		opts.GPGHome = home
+	}
+
+	rp := d.repoPath(repo)
+	for _, arg := range args {
+		// Deleted refs don't introduce any commits.
+		if gitb.IsZeroHash(arg.NewSha) {
+			continue
+		}
+
+		commits, err := git.NewCommits(ctx, rp, arg.NewSha)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range commits {
+			if err := git.VerifyCommit(ctx, rp, opts, c); err != nil {
+				return fmt.Errorf("%s: %w", arg.RefName, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeAllowedSigners writes signers in the ssh-keygen allowed signers
+// format, restricted to git signatures.
+func writeAllowedSigners(w io.Writer, signers []models.RepoSigner) error {
+	for _, s := range signers {
+		if _, err := fmt.Fprintf(w, "%s namespaces=\"git\" %s\n", s.Principal, s.PublicKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
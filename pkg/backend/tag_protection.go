@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// AddTagProtection protects the tags of a repository matching the glob
+// pattern. Once created, they can't be moved or deleted, by anyone.
+func (d *Backend) AddTagProtection(ctx context.Context, repo string, pattern string) error {
+	pattern = strings.TrimPrefix(pattern, "refs/tags/")
+	if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+		return proto.ErrInvalidTagPattern
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.AddTagProtectionByRepo(ctx, tx, repo, pattern)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrTagProtectionExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// RemoveTagProtection removes the tag protection rule of pattern from a
+// repository.
+func (d *Backend) RemoveTagProtection(ctx context.Context, repo string, pattern string) error {
+	repo = utils.SanitizeRepo(repo)
+	pattern = strings.TrimPrefix(pattern, "refs/tags/")
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.RemoveTagProtectionByRepo(ctx, tx, repo, pattern)
+		}),
+	)
+}
+
+// TagProtections returns the tag protection rules of a repository.
+func (d *Backend) TagProtections(ctx context.Context, repo string) ([]models.TagProtection, error) {
+	repo = utils.SanitizeRepo(repo)
+	var rules []models.TagProtection
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		rules, err = d.store.ListTagProtectionsByRepo(ctx, tx, repo)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return rules, nil
+}
+
+// CheckTagProtections returns an error if the ref updates in args move or
+// delete an existing tag matching a tag protection rule. Creating protected
+// tags is allowed. Unlike branch protections, the rules apply to every user,
+// admins included.
+func (d *Backend) CheckTagProtections(ctx context.Context, repo string, args []hooks.HookArg) error {
+	rules, err := d.TagProtections(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	for _, arg := range args {
+		tag, ok := strings.CutPrefix(arg.RefName, "refs/tags/")
+		if !ok || gitb.IsZeroHash(arg.OldSha) {
+			continue
+		}
+
+		for _, r := range rules {
+			if m, _ := path.Match(r.Pattern, tag); !m {
+				continue
+			}
+
+			if gitb.IsZeroHash(arg.NewSha) {
+				return fmt.Errorf("%s: %w by %q: it can't be deleted", arg.RefName, git.ErrProtectedTag, r.Pattern)
+			}
+			return fmt.Errorf("%s: %w by %q: it can't be moved", arg.RefName, git.ErrProtectedTag, r.Pattern)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// CreateTeam creates a team. Repositories granted to the team without an
+// access level get level.
+func (d *Backend) CreateTeam(ctx context.Context, name string, level access.AccessLevel) error {
+	name = strings.ToLower(name)
+	if err := utils.ValidateTeamName(name); err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.CreateTeam(ctx, tx, name, level)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrTeamExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTeam deletes a team, its memberships, and its repository access.
+func (d *Backend) DeleteTeam(ctx context.Context, name string) error {
+	if _, err := d.Team(ctx, name); err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.DeleteTeamByName(ctx, tx, name)
+		}),
+	)
+}
+
+// Team finds a team by name.
+func (d *Backend) Team(ctx context.Context, name string) (models.Team, error) {
+	var m models.Team
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetTeamByName(ctx, tx, name)
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return models.Team{}, proto.ErrTeamNotFound
+		}
+		return models.Team{}, err
+	}
+
+	return m, nil
+}
+
+// Teams returns all the teams.
+func (d *Backend) Teams(ctx context.Context) ([]models.Team, error) {
+	var teams []models.Team
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		teams, err = d.store.ListTeams(ctx, tx)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return teams, nil
+}
+
+// AddTeamMember adds a user to a team.
+func (d *Backend) AddTeamMember(ctx context.Context, team string, username string) error {
+	if _, err := d.Team(ctx, team); err != nil {
+		return err
+	}
+	if _, err := d.User(ctx, username); err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.AddTeamMemberByUsername(ctx, tx, team, username)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrTeamMemberExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// RemoveTeamMember removes a user from a team.
+func (d *Backend) RemoveTeamMember(ctx context.Context, team string, username string) error {
+	if _, err := d.Team(ctx, team); err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.RemoveTeamMemberByUsername(ctx, tx, team, username)
+		}),
+	)
+}
+
+// TeamMembers returns the usernames of the members of a team.
+func (d *Backend) TeamMembers(ctx context.Context, team string) ([]string, error) {
+	if _, err := d.Team(ctx, team); err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		users, err = d.store.ListTeamMembersAsUsers(ctx, tx, team)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	var usernames []string
+	for _, u := range users {
+		usernames = append(usernames, u.Username)
+	}
+
+	return usernames, nil
+}
+
+// AddTeamRepository grants a team access to a repository. A negative level
+// grants the default access level of the team.
+func (d *Backend) AddTeamRepository(ctx context.Context, repo string, team string, level access.AccessLevel) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	t, err := d.Team(ctx, team)
+	if err != nil {
+		return err
+	}
+	if level < 0 {
+		level = t.AccessLevel
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.AddTeamRepo(ctx, tx, team, repo, level)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrTeamRepoExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// RemoveTeamRepository revokes the access of a team to a repository.
+func (d *Backend) RemoveTeamRepository(ctx context.Context, repo string, team string) error {
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Team(ctx, team); err != nil {
+		return err
+	}
+
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.RemoveTeamRepo(ctx, tx, team, repo)
+		}),
+	)
+}
+
+// RepositoryTeams returns the access levels of the teams with access to a
+// repository, keyed by team name.
+func (d *Backend) RepositoryTeams(ctx context.Context, repo string) (map[string]access.AccessLevel, error) {
+	repo = utils.SanitizeRepo(repo)
+	var grants []models.TeamRepo
+	var teams []models.Team
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		grants, err = d.store.ListTeamReposByRepo(ctx, tx, repo)
+		if err != nil {
+			return err
+		}
+
+		teams, err = d.store.ListTeams(ctx, tx)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	names := make(map[int64]string, len(teams))
+	for _, t := range teams {
+		names[t.ID] = t.Name
+	}
+
+	levels := make(map[string]access.AccessLevel, len(grants))
+	for _, g := range grants {
+		levels[names[g.TeamID]] = g.AccessLevel
+	}
+
+	return levels, nil
+}
+
+// teamAccessLevel returns the highest access level the teams of a user have
+// on a repository, and true if any of them has access.
+func (d *Backend) teamAccessLevel(ctx context.Context, repo string, username string) (access.AccessLevel, bool, error) {
+	if username == "" {
+		return -1, false, nil
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	var level access.AccessLevel
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		level, err = d.store.GetTeamAccessByUsernameAndRepo(ctx, tx, username, repo)
+		return err
+	}); err != nil {
+		return -1, false, db.WrapError(err)
+	}
+
+	return level, level >= 0, nil
+}
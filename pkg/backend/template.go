@@ -0,0 +1,97 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// templateCommitMessage is the message of the initial commit of repositories
+// created from a template.
+const templateCommitMessage = "Initial commit"
+
+// IsTemplate returns true if the repository is a template.
+func (d *Backend) IsTemplate(ctx context.Context, name string) (bool, error) {
+	r, err := d.Repository(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	return r.IsTemplate(), nil
+}
+
+// SetTemplate sets whether new repositories can be created from a
+// repository.
+func (d *Backend) SetTemplate(ctx context.Context, name string, template bool) error {
+	name = utils.SanitizeRepo(name)
+	if _, err := d.Repository(ctx, name); err != nil {
+		return err
+	}
+
+	// Delete cache
+	d.cache.Delete(name)
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetRepoIsTemplateByName(ctx, tx, name, template)
+	}))
+}
+
+// templateRepository returns a template repository by name.
+func (d *Backend) templateRepository(ctx context.Context, name string) (proto.Repository, error) {
+	r, err := d.Repository(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.IsTemplate() {
+		return nil, proto.ErrNotTemplate
+	}
+
+	return r, nil
+}
+
+// initFromTemplate makes the tree of the default branch of a template the
+// initial commit of a new repository, authored by the user creating it. The
+// history of the template isn't copied. The commit is made on branch, or on
+// the same branch as the template's if it's empty. Empty templates leave the
+// repository empty.
+func (d *Backend) initFromTemplate(dst *gitb.Repository, tmpl proto.Repository, user proto.User, branch string) error {
+	src, err := tmpl.Open()
+	if err != nil {
+		return err
+	}
+
+	head, err := src.HEAD()
+	if err != nil {
+		// The template has no commits yet.
+		return nil
+	}
+
+	tree, err := dst.CopyTree(src, head.ID)
+	if err != nil {
+		return err
+	}
+
+	author := &gitb.Signature{
+		Name: d.cfg.Name,
+		When: time.Now(),
+	}
+	if user != nil {
+		author.Name = user.Username()
+	}
+
+	if branch == "" {
+		branch = head.Name().Short()
+	}
+	if _, err := dst.CommitTree(tree, branch, templateCommitMessage, author); err != nil {
+		return err
+	}
+
+	// Point HEAD to the branch of the commit.
+	_, err = dst.SymbolicRef(gitb.HEAD, gitb.RefsHeads+branch)
+	return err
+}
@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// AddRepoTopic adds a topic to a repository. Topics are lowercased.
+func (d *Backend) AddRepoTopic(ctx context.Context, repo string, topic string) error {
+	topic = strings.ToLower(topic)
+	if err := utils.ValidateTopic(topic); err != nil {
+		return err
+	}
+
+	repo = utils.SanitizeRepo(repo)
+	if _, err := d.Repository(ctx, repo); err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.AddTopicByRepo(ctx, tx, repo, topic)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrTopicExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// RemoveRepoTopic removes a topic from a repository.
+func (d *Backend) RemoveRepoTopic(ctx context.Context, repo string, topic string) error {
+	repo = utils.SanitizeRepo(repo)
+	topic = strings.ToLower(topic)
+	return db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.RemoveTopicByRepo(ctx, tx, repo, topic)
+		}),
+	)
+}
+
+// RepoTopics returns the topics of a repository sorted by name.
+func (d *Backend) RepoTopics(ctx context.Context, repo string) ([]string, error) {
+	repo = utils.SanitizeRepo(repo)
+	var topics []models.Topic
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		topics, err = d.store.ListTopicsByRepo(ctx, tx, repo)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	names := make([]string, len(topics))
+	for i, t := range topics {
+		names[i] = t.Name
+	}
+
+	return names, nil
+}
+
+// RepositoriesByTopic returns the repositories that have a topic.
+func (d *Backend) RepositoriesByTopic(ctx context.Context, topic string) ([]proto.Repository, error) {
+	topic = strings.ToLower(topic)
+	repos := make([]proto.Repository, 0)
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		ms, err := d.store.ListReposByTopic(ctx, tx, topic)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range ms {
+			r := &repo{
+				name: m.Name,
+				path: filepath.Join(d.repoPath(m.Name)),
+				repo: m,
+			}
+
+			d.cache.Set(m.Name, r)
+			repos = append(repos, r)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return repos, nil
+}
@@ -3,6 +3,10 @@ package backend
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,9 +28,15 @@ func (d *Backend) AccessLevel(ctx context.Context, repo string, username string)
 }
 
 // AccessLevelByPublicKey returns the access level of a user's public key for a repository.
+// Without a public key, the access level of the user in the context is
+// returned, such as users authenticated with keyboard interactive auth.
 //
 // It implements backend.Backend.
 func (d *Backend) AccessLevelByPublicKey(ctx context.Context, repo string, pk ssh.PublicKey) access.AccessLevel {
+	if pk == nil {
+		return d.AccessLevelForUser(ctx, repo, proto.UserFromContext(ctx))
+	}
+
 	for _, k := range d.cfg.AdminKeys() {
 		if sshutils.KeysEqual(pk, k) {
 			return access.AdminAccess
@@ -34,25 +44,130 @@ func (d *Backend) AccessLevelByPublicKey(ctx context.Context, repo string, pk ss
 	}
 
 	user, _ := d.UserByPublicKey(ctx, pk)
-	if user != nil {
-		return d.AccessLevel(ctx, repo, user.Username())
+	return d.AccessLevelForUser(ctx, repo, user)
+}
+
+// AccessReason is a rule that applied when resolving the access level of a
+// user for a repository.
+type AccessReason struct {
+	// Level is the access level the rule grants, or caps the access at.
+	Level access.AccessLevel
+	// Reason describes the rule.
+	Reason string
+}
+
+// AccessExplanation is the access level of a user for a repository and the
+// rules that resolved it.
+type AccessExplanation struct {
+	Level   access.AccessLevel
+	Reasons []AccessReason
+}
+
+func newAccessExplanation(level access.AccessLevel, reason string) AccessExplanation {
+	return AccessExplanation{
+		Level:   level,
+		Reasons: []AccessReason{{Level: level, Reason: reason}},
 	}
+}
 
-	return d.AccessLevel(ctx, repo, "")
+func (e *AccessExplanation) add(level access.AccessLevel, reason string) {
+	e.Reasons = append(e.Reasons, AccessReason{Level: level, Reason: reason})
 }
 
+// teamReason is replaced by the teams the user is in by ExplainAccess.
+const teamReason = "team member"
+
 // AccessLevelForUser returns the access level of a user for a repository.
-// TODO: user repository ownership
+// Users authenticated with a directory password have at least the access
+// level of their directory groups, and users authenticated with an access
+// token at most its scope.
 func (d *Backend) AccessLevelForUser(ctx context.Context, repo string, user proto.User) access.AccessLevel {
+	return d.explainAccess(ctx, repo, user).Level
+}
+
+// ExplainAccess returns the access level of a user for a repository, resolved
+// like AccessLevelForUser, along with the rules that led to it.
+func (d *Backend) ExplainAccess(ctx context.Context, repo string, user proto.User) AccessExplanation {
+	e := d.explainAccess(ctx, repo, user)
+	for i, r := range e.Reasons {
+		if r.Reason != teamReason {
+			continue
+		}
+
+		// Name the teams granting access rather than only their highest
+		// level.
+		teams, err := d.RepositoryTeams(ctx, repo)
+		if err != nil {
+			break
+		}
+		names := make([]string, 0, len(teams))
+		for name := range teams {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		reasons := make([]AccessReason, 0, len(e.Reasons)+len(names))
+		reasons = append(reasons, e.Reasons[:i]...)
+		for _, name := range names {
+			members, err := d.TeamMembers(ctx, name)
+			if err != nil || !slices.Contains(members, user.Username()) {
+				continue
+			}
+			reasons = append(reasons, AccessReason{
+				Level:  teams[name],
+				Reason: fmt.Sprintf("member of team %s", name),
+			})
+		}
+		e.Reasons = append(reasons, e.Reasons[i+1:]...)
+		break
+	}
+
+	return e
+}
+
+func (d *Backend) explainAccess(ctx context.Context, repo string, user proto.User) AccessExplanation {
+	var e AccessExplanation
+	if dk, ok := user.(*deployKeyUser); ok {
+		e = d.deployKeyAccess(ctx, repo, dk)
+	} else {
+		e = d.accessLevelForUser(ctx, repo, user)
+	}
+	if da := DirectoryAccess(user); da > e.Level {
+		e.Level = da
+		e.add(da, "directory groups")
+	}
+	if scope, ok := TokenScope(user); ok && scope < e.Level {
+		e.Level = scope
+		e.add(scope, "access token scope")
+	}
+
+	// Archived repositories are read-only to everyone but admins, who can
+	// unarchive them.
+	if e.Level > access.ReadOnlyAccess && e.Level < access.AdminAccess {
+		r := proto.RepositoryFromContext(ctx)
+		if r == nil || r.Name() != utils.SanitizeRepo(repo) {
+			r, _ = d.Repository(ctx, repo)
+		}
+		if r != nil && r.IsArchived() {
+			e.Level = access.ReadOnlyAccess
+			e.add(access.ReadOnlyAccess, "repository is archived")
+		}
+	}
+
+	return e
+}
+
+// TODO: user repository ownership
+func (d *Backend) accessLevelForUser(ctx context.Context, repo string, user proto.User) AccessExplanation {
 	var username string
 	anon := d.AnonAccess(ctx)
+	anonReason := "server anon-access setting"
 	if user != nil {
 		username = user.Username()
 	}
 
 	// If the user is an admin, they have admin access.
 	if user != nil && user.IsAdmin() {
-		return access.AdminAccess
+		return newAccessExplanation(access.AdminAccess, "server admin")
 	}
 
 	// If the repository exists, check if the user is a collaborator.
@@ -65,43 +180,57 @@ func (d *Backend) AccessLevelForUser(ctx context.Context, repo string, user prot
 		if user != nil {
 			// If the user is the owner, they have admin access.
 			if r.UserID() == user.ID() {
-				return access.AdminAccess
+				return newAccessExplanation(access.AdminAccess, "repository owner")
 			}
 		}
 
-		// If the user is a collaborator, they have return their access level.
+		// The anonymous access level of the repository takes precedence over
+		// the server default.
+		if level, ok, err := d.repoAnonAccess(ctx, repo); err == nil && ok {
+			anon = level
+			anonReason = "repository anonymous access"
+		}
+
+		// If the user is a collaborator or on a team with access, return the
+		// highest of their access levels.
 		collabAccess, isCollab, _ := d.IsCollaborator(ctx, repo, username)
-		if isCollab {
-			if anon > collabAccess {
-				return anon
+		teamAccess, inTeam, _ := d.teamAccessLevel(ctx, repo, username)
+		if isCollab || inTeam {
+			e := AccessExplanation{Level: max(anon, collabAccess, teamAccess)}
+			if isCollab {
+				e.add(collabAccess, "collaborator")
 			}
-			return collabAccess
+			if inTeam {
+				e.add(teamAccess, teamReason)
+			}
+			e.add(anon, anonReason)
+			return e
 		}
 
 		// If the repository is private, the user has no access.
 		if r.IsPrivate() {
-			return access.NoAccess
+			return newAccessExplanation(access.NoAccess, "private repository")
 		}
 
 		// Otherwise, the user has read-only access.
 		if user == nil {
-			return anon
+			return newAccessExplanation(anon, anonReason)
 		}
 
-		return access.ReadOnlyAccess
+		return newAccessExplanation(access.ReadOnlyAccess, "public repository")
 	}
 
 	if user != nil {
 		// If the repository doesn't exist, the user has read/write access.
 		if anon > access.ReadWriteAccess {
-			return anon
+			return newAccessExplanation(anon, anonReason)
 		}
 
-		return access.ReadWriteAccess
+		return newAccessExplanation(access.ReadWriteAccess, "repository doesn't exist, users can create it")
 	}
 
 	// If the user doesn't exist, give them the anonymous access level.
-	return anon
+	return newAccessExplanation(anon, anonReason)
 }
 
 // User finds a user by username.
@@ -139,6 +268,46 @@ func (d *Backend) User(ctx context.Context, username string) (proto.User, error)
 	}, nil
 }
 
+// UserByEmail finds the user with an email address, compared
+// case-insensitively. It fails with [proto.ErrEmailNotUnique] when several
+// users have it.
+func (d *Backend) UserByEmail(ctx context.Context, email string) (proto.User, error) {
+	if email == "" {
+		return nil, proto.ErrUserNotFound
+	}
+
+	var m models.User
+	var pks []ssh.PublicKey
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		ms, err := d.store.FindUsersByEmail(ctx, tx, email)
+		if err != nil {
+			return err
+		}
+		switch len(ms) {
+		case 0:
+			return proto.ErrUserNotFound
+		case 1:
+			m = ms[0]
+		default:
+			return proto.ErrEmailNotUnique
+		}
+
+		pks, err = d.store.ListPublicKeysByUserID(ctx, tx, m.ID)
+		return err
+	}); err != nil {
+		if errors.Is(err, proto.ErrUserNotFound) || errors.Is(err, proto.ErrEmailNotUnique) {
+			return nil, err
+		}
+		d.logger.Error("error finding user by email", "email", email, "error", err)
+		return nil, db.WrapError(err)
+	}
+
+	return &user{
+		user:       m,
+		publicKeys: pks,
+	}, nil
+}
+
 // UserByID finds a user by ID.
 func (d *Backend) UserByID(ctx context.Context, id int64) (proto.User, error) {
 	var m models.User
@@ -171,6 +340,21 @@ func (d *Backend) UserByID(ctx context.Context, id int64) (proto.User, error) {
 //
 // It implements backend.Backend.
 func (d *Backend) UserByPublicKey(ctx context.Context, pk ssh.PublicKey) (proto.User, error) {
+	if cert, ok := pk.(*ssh.Certificate); ok {
+		return d.userByCertificate(ctx, cert)
+	}
+
+	if d.certChecker != nil {
+		revoked, err := d.certChecker.IsRevoked(pk)
+		if err != nil {
+			d.logger.Error("error checking revoked keys", "err", err)
+			return nil, err
+		}
+		if revoked {
+			return nil, proto.ErrUserNotFound
+		}
+	}
+
 	var m models.User
 	var pks []ssh.PublicKey
 	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
@@ -185,7 +369,8 @@ func (d *Backend) UserByPublicKey(ctx context.Context, pk ssh.PublicKey) (proto.
 	}); err != nil {
 		err = db.WrapError(err)
 		if errors.Is(err, db.ErrRecordNotFound) {
-			return nil, proto.ErrUserNotFound
+			// Keys that aren't a user's can be deploy keys.
+			return d.deployKeyByPublicKey(ctx, pk)
 		}
 		d.logger.Error("error finding user", "pk", sshutils.MarshalAuthorizedKey(pk), "error", err)
 		return nil, err
@@ -197,11 +382,38 @@ func (d *Backend) UserByPublicKey(ctx context.Context, pk ssh.PublicKey) (proto.
 	}, nil
 }
 
+// userByCertificate finds the user of an SSH user certificate signed by a
+// trusted CA. The user is the first principal of the certificate that is an
+// existing username.
+func (d *Backend) userByCertificate(ctx context.Context, cert *ssh.Certificate) (proto.User, error) {
+	if d.certChecker == nil {
+		return nil, proto.ErrUserNotFound
+	}
+
+	principals, err := d.certChecker.Principals(cert)
+	if err != nil {
+		d.logger.Debug("rejected ssh certificate", "key-id", cert.KeyId, "serial", cert.Serial, "err", err)
+		return nil, proto.ErrUserNotFound
+	}
+
+	for _, p := range principals {
+		user, err := d.User(ctx, p)
+		if err == nil {
+			return user, nil
+		} else if !errors.Is(err, proto.ErrUserNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, proto.ErrUserNotFound
+}
+
 // UserByAccessToken finds a user by access token.
 // This also validates the token for expiration and returns proto.ErrTokenExpired.
 func (d *Backend) UserByAccessToken(ctx context.Context, token string) (proto.User, error) {
 	var m models.User
 	var pks []ssh.PublicKey
+	var scope access.AccessLevel
 	token = HashToken(token)
 
 	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
@@ -213,6 +425,7 @@ func (d *Backend) UserByAccessToken(ctx context.Context, token string) (proto.Us
 		if t.ExpiresAt.Valid && t.ExpiresAt.Time.Before(time.Now()) {
 			return proto.ErrTokenExpired
 		}
+		scope = t.Scope
 
 		m, err = d.store.FindUserByAccessToken(ctx, tx, token)
 		if err != nil {
@@ -230,10 +443,15 @@ func (d *Backend) UserByAccessToken(ctx context.Context, token string) (proto.Us
 		return nil, err
 	}
 
-	return &user{
+	u := &user{
 		user:       m,
 		publicKeys: pks,
-	}, nil
+	}
+	if scope < access.AdminAccess {
+		return &tokenUser{User: u, scope: scope}, nil
+	}
+
+	return u, nil
 }
 
 // Users returns all users.
@@ -393,6 +611,36 @@ func (d *Backend) SetPassword(ctx context.Context, username string, rawPassword
 	)
 }
 
+// SetEmail sets the email address of a user, or unsets it when email is
+// empty.
+func (d *Backend) SetEmail(ctx context.Context, username string, email string) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	if email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return proto.ErrInvalidEmail
+		}
+	}
+
+	err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			if _, err := d.store.FindUserByUsername(ctx, tx, username); err != nil {
+				return err
+			}
+
+			return d.store.SetUserEmailByUsername(ctx, tx, username, email)
+		}),
+	)
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return proto.ErrUserNotFound
+	}
+
+	return err
+}
+
 type user struct {
 	user       models.User
 	publicKeys []ssh.PublicKey
@@ -428,3 +676,12 @@ func (u *user) Password() string {
 
 	return ""
 }
+
+// Email implements proto.User.
+func (u *user) Email() string {
+	if u.user.Email.Valid {
+		return u.user.Email.String
+	}
+
+	return ""
+}
@@ -0,0 +1,186 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"golang.org/x/crypto/ssh"
+)
+
+// UserImport is a user to create or update with [Backend.ImportUsers].
+type UserImport struct {
+	// Username is the username of the user.
+	Username string
+
+	// Email is the email address of the user, it's left as is when empty.
+	Email string
+
+	// Admin is whether the user is an admin, it's left as is when nil.
+	Admin *bool
+
+	// PublicKeys are authorized keys added to the user. Existing keys of
+	// the user are kept.
+	PublicKeys []string
+}
+
+// UserImportAction is what an import did to a user.
+type UserImportAction string
+
+const (
+	// UserCreated is the action of a user that didn't exist.
+	UserCreated UserImportAction = "created"
+
+	// UserUpdated is the action of an existing user that changed.
+	UserUpdated UserImportAction = "updated"
+
+	// UserSkipped is the action of a user that was already up to date, or
+	// listed more than once.
+	UserSkipped UserImportAction = "skipped"
+)
+
+// UserImportResult is the outcome of the import of a user.
+type UserImportResult struct {
+	// Username is the username of the user.
+	Username string
+
+	// Action is what the import did to the user.
+	Action UserImportAction
+
+	// Notes explain skipped users and keys.
+	Notes []string
+}
+
+var errDryRun = errors.New("dry run")
+
+// ImportUsers creates the users that don't exist and updates the others.
+// Importing the same users again changes nothing. Users listed more than once
+// are skipped after their first entry, and so are keys that belong to
+// another user.
+//
+// Nothing is imported if any user is invalid. When dryRun is set, the results
+// are returned without making changes.
+func (d *Backend) ImportUsers(ctx context.Context, users []UserImport, dryRun bool) ([]UserImportResult, error) {
+	users = append([]UserImport(nil), users...)
+	keys := make([][]ssh.PublicKey, len(users))
+	var errs []error
+	for i, u := range users {
+		u.Username = strings.ToLower(utils.Sanitize(u.Username))
+		users[i].Username = u.Username
+		if err := utils.ValidateUsername(u.Username); err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", i+1, err))
+			continue
+		}
+
+		if u.Email != "" {
+			if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+				errs = append(errs, fmt.Errorf("user %s: %w", u.Username, proto.ErrInvalidEmail))
+			}
+		}
+
+		for _, k := range u.PublicKeys {
+			pk, _, err := sshutils.ParseAuthorizedKey(k)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("user %s: invalid public key: %w", u.Username, err))
+				continue
+			}
+			keys[i] = append(keys[i], pk)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	results := make([]UserImportResult, 0, len(users))
+	err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		seen := make(map[string]bool, len(users))
+		for i, u := range users {
+			res := UserImportResult{Username: u.Username, Action: UserSkipped}
+			if seen[u.Username] {
+				res.Notes = append(res.Notes, "duplicate entry")
+				results = append(results, res)
+				continue
+			}
+			seen[u.Username] = true
+
+			var pks []ssh.PublicKey
+			fps := make(map[string]bool, len(keys[i]))
+			for _, pk := range keys[i] {
+				if fps[ssh.FingerprintSHA256(pk)] {
+					continue
+				}
+				fps[ssh.FingerprintSHA256(pk)] = true
+
+				owner, err := d.store.FindUserByPublicKey(ctx, tx, pk)
+				if err == nil {
+					if owner.Username != u.Username {
+						res.Notes = append(res.Notes, fmt.Sprintf("key %s belongs to %s", ssh.FingerprintSHA256(pk), owner.Username))
+					}
+					continue
+				}
+				if !errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+					return err
+				}
+				pks = append(pks, pk)
+			}
+
+			m, err := d.store.FindUserByUsername(ctx, tx, u.Username)
+			switch {
+			case errors.Is(db.WrapError(err), db.ErrRecordNotFound):
+				res.Action = UserCreated
+				if err := d.store.CreateUser(ctx, tx, u.Username, u.Admin != nil && *u.Admin, pks); err != nil {
+					return err
+				}
+				if u.Email != "" {
+					if err := d.store.SetUserEmailByUsername(ctx, tx, u.Username, u.Email); err != nil {
+						return err
+					}
+				}
+			case err != nil:
+				return err
+			default:
+				if u.Admin != nil && *u.Admin != m.Admin {
+					res.Action = UserUpdated
+					if err := d.store.SetAdminByUsername(ctx, tx, u.Username, *u.Admin); err != nil {
+						return err
+					}
+				}
+				if u.Email != "" && (!m.Email.Valid || m.Email.String != u.Email) {
+					res.Action = UserUpdated
+					if err := d.store.SetUserEmailByUsername(ctx, tx, u.Username, u.Email); err != nil {
+						return err
+					}
+				}
+				for _, pk := range pks {
+					res.Action = UserUpdated
+					if err := d.store.AddPublicKeyByUsername(ctx, tx, u.Username, pk); err != nil {
+						return err
+					}
+				}
+			}
+
+			if res.Action == UserSkipped {
+				res.Notes = append([]string{"up to date"}, res.Notes...)
+			}
+			results = append(results, res)
+		}
+
+		// Roll back the changes of a dry run.
+		if dryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, db.WrapError(err)
+	}
+
+	return results, nil
+}
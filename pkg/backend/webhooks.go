@@ -2,7 +2,9 @@ package backend
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
 
 	"charm.land/log/v2"
 	"github.com/charmbracelet/soft-serve/pkg/db"
@@ -14,19 +16,57 @@ import (
 	"github.com/google/uuid"
 )
 
-// CreateWebhook creates a webhook for a repository.
-func (b *Backend) CreateWebhook(ctx context.Context, repo proto.Repository, url string, contentType webhook.ContentType, secret string, events []webhook.Event, active bool) error {
+// webhookRepoID returns the ID of the repository of webhooks, 0 for the
+// server webhooks when repo is nil.
+func webhookRepoID(repo proto.Repository) int64 {
+	if repo == nil {
+		return 0
+	}
+
+	return repo.ID()
+}
+
+// validateServerEvents returns [webhook.ErrInvalidServerEvent] if the server
+// webhooks can't subscribe to one of the events.
+func validateServerEvents(events []webhook.Event) error {
+	for _, e := range events {
+		if !slices.Contains(webhook.ServerEvents(), e) {
+			return fmt.Errorf("%w %q", webhook.ErrInvalidServerEvent, e)
+		}
+	}
+
+	return nil
+}
+
+// CreateWebhook creates a webhook for a repository, or a server webhook if
+// repo is nil. Server webhooks receive the repository events of every
+// repository.
+// A non-empty payloadTemplate replaces the payload in contentType with the
+// rendered template, sent as payloadContentType.
+func (b *Backend) CreateWebhook(ctx context.Context, repo proto.Repository, url string, contentType webhook.ContentType, payloadTemplate string, payloadContentType string, secret string, events []webhook.Event, active bool) error {
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 	url = utils.Sanitize(url)
 
+	if repo == nil {
+		if err := validateServerEvents(events); err != nil {
+			return err
+		}
+	}
+
 	// Validate webhook URL to prevent SSRF attacks
 	if err := webhook.ValidateWebhookURL(url); err != nil {
 		return err //nolint:wrapcheck
 	}
 
+	if payloadTemplate != "" {
+		if _, err := webhook.ParseTemplate(payloadTemplate); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
 	return dbx.TransactionContext(ctx, func(tx *db.Tx) error {
-		lastID, err := datastore.CreateWebhook(ctx, tx, repo.ID(), url, secret, int(contentType), active)
+		lastID, err := datastore.CreateWebhook(ctx, tx, webhookRepoID(repo), url, secret, int(contentType), payloadTemplate, payloadContentType, active)
 		if err != nil {
 			return db.WrapError(err)
 		}
@@ -43,14 +83,15 @@ func (b *Backend) CreateWebhook(ctx context.Context, repo proto.Repository, url
 	})
 }
 
-// Webhook returns a webhook for a repository.
+// Webhook returns a webhook for a repository, or a server webhook if repo is
+// nil.
 func (b *Backend) Webhook(ctx context.Context, repo proto.Repository, id int64) (webhook.Hook, error) {
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 
 	var wh webhook.Hook
 	if err := dbx.TransactionContext(ctx, func(tx *db.Tx) error {
-		h, err := datastore.GetWebhookByID(ctx, tx, repo.ID(), id)
+		h, err := datastore.GetWebhookByID(ctx, tx, webhookRepoID(repo), id)
 		if err != nil {
 			return db.WrapError(err)
 		}
@@ -76,7 +117,8 @@ func (b *Backend) Webhook(ctx context.Context, repo proto.Repository, id int64)
 	return wh, nil
 }
 
-// ListWebhooks lists webhooks for a repository.
+// ListWebhooks lists webhooks for a repository, or the server webhooks if
+// repo is nil.
 func (b *Backend) ListWebhooks(ctx context.Context, repo proto.Repository) ([]webhook.Hook, error) {
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
@@ -85,7 +127,7 @@ func (b *Backend) ListWebhooks(ctx context.Context, repo proto.Repository) ([]we
 	webhookEvents := map[int64][]models.WebhookEvent{}
 	if err := dbx.TransactionContext(ctx, func(tx *db.Tx) error {
 		var err error
-		webhooks, err = datastore.GetWebhooksByRepoID(ctx, tx, repo.ID())
+		webhooks, err = datastore.GetWebhooksByRepoID(ctx, tx, webhookRepoID(repo))
 		if err != nil {
 			return err
 		}
@@ -120,18 +162,31 @@ func (b *Backend) ListWebhooks(ctx context.Context, repo proto.Repository) ([]we
 	return hooks, nil
 }
 
-// UpdateWebhook updates a webhook.
-func (b *Backend) UpdateWebhook(ctx context.Context, repo proto.Repository, id int64, url string, contentType webhook.ContentType, secret string, updatedEvents []webhook.Event, active bool) error {
+// UpdateWebhook updates a webhook of a repository, or a server webhook if
+// repo is nil.
+func (b *Backend) UpdateWebhook(ctx context.Context, repo proto.Repository, id int64, url string, contentType webhook.ContentType, payloadTemplate string, payloadContentType string, secret string, updatedEvents []webhook.Event, active bool) error {
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 
+	if repo == nil {
+		if err := validateServerEvents(updatedEvents); err != nil {
+			return err
+		}
+	}
+
 	// Validate webhook URL to prevent SSRF attacks
 	if err := webhook.ValidateWebhookURL(url); err != nil {
 		return err
 	}
 
+	if payloadTemplate != "" {
+		if _, err := webhook.ParseTemplate(payloadTemplate); err != nil {
+			return err
+		}
+	}
+
 	return dbx.TransactionContext(ctx, func(tx *db.Tx) error {
-		if err := datastore.UpdateWebhookByID(ctx, tx, repo.ID(), id, url, secret, int(contentType), active); err != nil {
+		if err := datastore.UpdateWebhookByID(ctx, tx, webhookRepoID(repo), id, url, secret, int(contentType), payloadTemplate, payloadContentType, active); err != nil {
 			return db.WrapError(err)
 		}
 
@@ -182,17 +237,18 @@ func (b *Backend) UpdateWebhook(ctx context.Context, repo proto.Repository, id i
 	})
 }
 
-// DeleteWebhook deletes a webhook for a repository.
+// DeleteWebhook deletes a webhook for a repository, or a server webhook if
+// repo is nil.
 func (b *Backend) DeleteWebhook(ctx context.Context, repo proto.Repository, id int64) error {
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 
 	return dbx.TransactionContext(ctx, func(tx *db.Tx) error {
-		_, err := datastore.GetWebhookByID(ctx, tx, repo.ID(), id)
+		_, err := datastore.GetWebhookByID(ctx, tx, webhookRepoID(repo), id)
 		if err != nil {
 			return db.WrapError(err)
 		}
-		if err := datastore.DeleteWebhookForRepoByID(ctx, tx, repo.ID(), id); err != nil {
+		if err := datastore.DeleteWebhookForRepoByID(ctx, tx, webhookRepoID(repo), id); err != nil {
 			return db.WrapError(err)
 		}
 
@@ -200,13 +256,18 @@ func (b *Backend) DeleteWebhook(ctx context.Context, repo proto.Repository, id i
 	})
 }
 
-// ListWebhookDeliveries lists webhook deliveries for a webhook.
-func (b *Backend) ListWebhookDeliveries(ctx context.Context, id int64) ([]webhook.Delivery, error) {
+// ListWebhookDeliveries lists webhook deliveries for a webhook of a
+// repository, or a server webhook if repo is nil.
+func (b *Backend) ListWebhookDeliveries(ctx context.Context, repo proto.Repository, id int64) ([]webhook.Delivery, error) {
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 
 	var deliveries []models.WebhookDelivery
 	if err := dbx.TransactionContext(ctx, func(tx *db.Tx) error {
+		if _, err := datastore.GetWebhookByID(ctx, tx, webhookRepoID(repo), id); err != nil {
+			return db.WrapError(err)
+		}
+
 		var err error
 		deliveries, err = datastore.ListWebhookDeliveriesByWebhookID(ctx, tx, id)
 		if err != nil {
@@ -229,57 +290,58 @@ func (b *Backend) ListWebhookDeliveries(ctx context.Context, id int64) ([]webhoo
 	return ds, nil
 }
 
-// RedeliverWebhookDelivery redelivers a webhook delivery.
+// RedeliverWebhookDelivery queues a webhook delivery to be sent again with
+// a fresh set of attempts. Server webhooks have a nil repo.
 func (b *Backend) RedeliverWebhookDelivery(ctx context.Context, repo proto.Repository, id int64, delID uuid.UUID) error {
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 
-	var delivery models.WebhookDelivery
-	var wh models.Webhook
-	if err := dbx.TransactionContext(ctx, func(tx *db.Tx) error {
-		var err error
-		wh, err = datastore.GetWebhookByID(ctx, tx, repo.ID(), id)
-		if err != nil {
+	return db.WrapError(dbx.TransactionContext(ctx, func(tx *db.Tx) error {
+		if _, err := datastore.GetWebhookByID(ctx, tx, webhookRepoID(repo), id); err != nil {
 			log.Errorf("error getting webhook: %v", err)
 			return db.WrapError(err)
 		}
 
-		delivery, err = datastore.GetWebhookDeliveryByID(ctx, tx, id, delID)
+		d, err := datastore.GetWebhookDeliveryByID(ctx, tx, id, delID)
 		if err != nil {
 			return db.WrapError(err)
 		}
+		if d.RequestBody == "" && d.RequestError.Valid {
+			return webhook.ErrUnrenderedDelivery
+		}
 
-		return nil
-	}); err != nil {
-		return db.WrapError(err)
-	}
-
-	log.Infof("redelivering webhook delivery %s for webhook %d\n\n%s\n\n", delID, id, delivery.RequestBody)
-
-	var payload json.RawMessage
-	if err := json.Unmarshal([]byte(delivery.RequestBody), &payload); err != nil {
-		log.Errorf("error unmarshaling webhook payload: %v", err)
-		return err
-	}
+		log.Infof("redelivering webhook delivery %s for webhook %d", delID, id)
 
-	return webhook.SendWebhook(ctx, wh, webhook.Event(delivery.Event), payload)
+		return datastore.RequeueWebhookDeliveryByID(ctx, tx, id, delID, time.Now())
+	}))
 }
 
-// WebhookDelivery returns a webhook delivery.
-func (b *Backend) WebhookDelivery(ctx context.Context, webhookID int64, id uuid.UUID) (webhook.Delivery, error) {
+// WebhookDelivery returns a webhook delivery of a webhook of a repository, or
+// of a server webhook if repo is nil.
+func (b *Backend) WebhookDelivery(ctx context.Context, repo proto.Repository, webhookID int64, id uuid.UUID) (webhook.Delivery, error) {
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 
 	var delivery webhook.Delivery
 	if err := dbx.TransactionContext(ctx, func(tx *db.Tx) error {
+		if _, err := datastore.GetWebhookByID(ctx, tx, webhookRepoID(repo), webhookID); err != nil {
+			return db.WrapError(err)
+		}
+
 		d, err := datastore.GetWebhookDeliveryByID(ctx, tx, webhookID, id)
 		if err != nil {
 			return db.WrapError(err)
 		}
 
+		history, err := datastore.GetWebhookDeliveryAttemptsByDeliveryID(ctx, tx, id)
+		if err != nil {
+			return db.WrapError(err)
+		}
+
 		delivery = webhook.Delivery{
 			WebhookDelivery: d,
 			Event:           webhook.Event(d.Event),
+			History:         history,
 		}
 
 		return nil
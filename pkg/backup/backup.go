@@ -0,0 +1,353 @@
+// Package backup snapshots and restores the state of a Soft Serve instance.
+//
+// A backup is a gzipped tar archive made of a manifest, a snapshot of the
+// database, the configuration and keys, a git bundle of each repository
+// along with its metadata, and the LFS objects.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/log/v2"
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/lfs"
+	"github.com/charmbracelet/soft-serve/pkg/storage"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/store/database"
+	"github.com/charmbracelet/soft-serve/pkg/version"
+)
+
+// FormatVersion is the version of the backup archive format.
+const FormatVersion = 1
+
+// Paths of the archive entries.
+const (
+	manifestPath = "manifest.json"
+	databasePath = "db/soft-serve.db"
+	filesDir     = "files"
+	reposDir     = "repos"
+	lfsDir       = "lfs"
+	bundleExt    = ".bundle"
+)
+
+// repoFiles are the files of a repository kept along with its bundle. Refs
+// and objects come from the bundle.
+var repoFiles = []string{
+	"HEAD",
+	"config",
+	"description",
+	"git-daemon-export-ok",
+	"hooks",
+	"info",
+}
+
+// ErrUnsupportedDatabase is returned when the database can't be part of a
+// backup, or restored.
+var ErrUnsupportedDatabase = errors.New("only sqlite databases can be backed up, back up other databases with their own tools")
+
+// Manifest describes a backup.
+type Manifest struct {
+	// Version is the version of the archive format.
+	Version int `json:"version"`
+
+	// SoftServeVersion is the version of Soft Serve that made the backup.
+	SoftServeVersion string `json:"soft_serve_version"`
+
+	// CreatedAt is when the backup was made.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Database is the driver of the backed up database, it's empty if the
+	// database isn't part of the backup.
+	Database string `json:"database,omitempty"`
+
+	// LFS is whether the LFS objects are part of the backup.
+	LFS bool `json:"lfs"`
+
+	// Repos are the names of the backed up repositories.
+	Repos []string `json:"repos"`
+}
+
+// Options are options for a backup.
+type Options struct {
+	// ExcludeLFS leaves LFS objects out of the backup.
+	ExcludeLFS bool
+
+	// ExcludeDB leaves the database out of the backup, e.g. when it's
+	// backed up with its own tools.
+	ExcludeDB bool
+}
+
+// Backup writes a backup of the instance. The database is snapshotted first,
+// and the repositories of the snapshot are bundled, so pushes in progress
+// aren't captured halfway through.
+//
+// Only sqlite databases can be part of the backup, ErrUnsupportedDatabase is
+// returned for other databases unless the database is excluded.
+func Backup(ctx context.Context, cfg *config.Config, dbx *db.DB, w io.Writer, opts Options) (*Manifest, error) {
+	logger := log.FromContext(ctx).WithPrefix("backup")
+	if !opts.ExcludeDB && !isSqlite(cfg.DB.Driver) {
+		return nil, ErrUnsupportedDatabase
+	}
+
+	tmp, err := os.MkdirTemp("", "soft-serve-backup-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp) //nolint: errcheck
+
+	m := &Manifest{
+		Version:          FormatVersion,
+		SoftServeVersion: version.Version,
+		CreatedAt:        time.Now().UTC(),
+		LFS:              !opts.ExcludeLFS,
+	}
+
+	// Read the repositories and LFS objects from the snapshot so they match
+	// the backed up database.
+	var snapshot string
+	src := dbx
+	if !opts.ExcludeDB {
+		snapshot = filepath.Join(tmp, "soft-serve.db")
+		if _, err := dbx.ExecContext(ctx, "VACUUM INTO ?;", snapshot); err != nil {
+			return nil, fmt.Errorf("snapshot database: %w", err)
+		}
+
+		src, err = db.Open(ctx, cfg.DB.Driver, snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("open database snapshot: %w", err)
+		}
+		defer src.Close() //nolint: errcheck
+
+		m.Database = cfg.DB.Driver
+	} else {
+		logger.Warn("database isn't part of the backup", "driver", cfg.DB.Driver)
+	}
+
+	st := database.New(ctx, src)
+	repos, err := st.GetAllRepos(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("list repositories: %w", db.WrapError(err))
+	}
+	for _, r := range repos {
+		m.Repos = append(m.Repos, r.Name)
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBytes(tw, manifestPath, manifest); err != nil {
+		return nil, err
+	}
+
+	if snapshot != "" {
+		if err := writeFile(tw, databasePath, snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	// Configuration and keys are kept by their path in the data directory.
+	// The secrets in the database can't be decrypted without the hooks
+	// secret key.
+	for _, fp := range []string{cfg.ConfigPath(), cfg.SSH.KeyPath, cfg.SSH.ClientKeyPath, cfg.Hooks.SecretKeyPath} {
+		if _, err := os.Stat(fp); err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(cfg.DataPath, fp)
+		if err != nil || !filepath.IsLocal(rel) {
+			logger.Warn("file outside of the data path isn't part of the backup", "path", fp)
+			continue
+		}
+		if err := writeFile(tw, path.Join(filesDir, filepath.ToSlash(rel)), fp); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range repos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := writeRepo(tw, cfg, tmp, r.Name); err != nil {
+			return nil, fmt.Errorf("back up repository %s: %w", r.Name, err)
+		}
+
+		if m.LFS {
+			if err := writeLFSObjects(ctx, tw, cfg, st, src, r); err != nil {
+				return nil, fmt.Errorf("back up lfs objects of %s: %w", r.Name, err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// writeRepo writes the metadata files of a repository, then a bundle of all
+// its refs. Empty repositories have no bundle.
+func writeRepo(tw *tar.Writer, cfg *config.Config, tmp string, name string) error {
+	rp := filepath.Join(cfg.DataPath, reposDir, name+".git")
+	for _, f := range repoFiles {
+		if err := writeTree(tw, path.Join(reposDir, name+".git", f), filepath.Join(rp, f)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	out, err := gitb.NewCommand("for-each-ref", "--count=1").RunInDir(rp)
+	if err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(string(out))) == 0 {
+		return nil
+	}
+
+	bundle := filepath.Join(tmp, "repo"+bundleExt)
+	defer os.Remove(bundle) //nolint: errcheck
+	if _, err := gitb.NewCommand("bundle", "create", "--quiet", bundle, "--all").
+		WithTimeout(-1).
+		RunInDir(rp); err != nil {
+		return err
+	}
+
+	return writeFile(tw, path.Join(reposDir, name+".git"+bundleExt), bundle)
+}
+
+// writeLFSObjects writes the stored LFS objects of a repository. Missing
+// objects are skipped.
+func writeLFSObjects(ctx context.Context, tw *tar.Writer, cfg *config.Config, st store.Store, h db.Handler, r models.Repo) error {
+	logger := log.FromContext(ctx).WithPrefix("backup")
+	strg, err := storage.NewLFSStorage(cfg, r.ID)
+	if err != nil {
+		return err
+	}
+
+	objs, err := st.GetLFSObjects(ctx, h, r.ID)
+	if err != nil {
+		return db.WrapError(err)
+	}
+
+	for _, obj := range objs {
+		p := lfs.Pointer{Oid: obj.Oid, Size: obj.Size}
+		if !p.IsValid() {
+			continue
+		}
+
+		if err := func() error {
+			f, err := strg.Open(p.RelativePath())
+			if err != nil {
+				return err
+			}
+			defer f.Close() //nolint: errcheck
+
+			fi, err := f.Stat()
+			if err != nil {
+				return err
+			}
+
+			name := path.Join(lfsDir, fmt.Sprint(r.ID), p.RelativePath())
+			return writeReader(tw, name, fi.Size(), 0o644, f)
+		}(); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				logger.Warn("missing lfs object isn't part of the backup", "repo", r.Name, "oid", obj.Oid)
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTree writes a file, or a directory and the files in it.
+func writeTree(tw *tar.Writer, name string, fp string) error {
+	fi, err := os.Stat(fp)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return writeFile(tw, name, fp)
+	}
+
+	return filepath.WalkDir(fp, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(fp, p)
+		if err != nil {
+			return err
+		}
+
+		return writeFile(tw, path.Join(name, filepath.ToSlash(rel)), p)
+	})
+}
+
+// writeFile writes a regular file.
+func writeFile(tw *tar.Writer, name string, fp string) error {
+	f, err := os.Open(fp)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint: errcheck
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	return writeReader(tw, name, fi.Size(), fi.Mode().Perm(), f)
+}
+
+// writeBytes writes a file from memory.
+func writeBytes(tw *tar.Writer, name string, b []byte) error {
+	return writeReader(tw, name, int64(len(b)), 0o644, bytes.NewReader(b))
+}
+
+// writeReader writes a file of a known size.
+func writeReader(tw *tar.Writer, name string, size int64, mode fs.FileMode, r io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     int64(mode),
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	_, err := io.CopyN(tw, r, size)
+	return err
+}
+
+func isSqlite(driver string) bool {
+	return strings.HasPrefix(driver, "sqlite")
+}
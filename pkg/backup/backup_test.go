@@ -0,0 +1,301 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/migrate"
+	"github.com/charmbracelet/soft-serve/pkg/lfs"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/storage"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/store/database"
+)
+
+// newTestConfig returns the configuration of an instance in a new data
+// path.
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	t.Setenv("SOFT_SERVE_DATA_PATH", t.TempDir())
+	cfg := config.DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// openTestDB opens and migrates the database of an instance.
+func openTestDB(t *testing.T, ctx context.Context, cfg *config.Config) *db.DB {
+	t.Helper()
+	dbx, err := db.Open(ctx, cfg.DB.Driver, cfg.DB.DataSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbx.Close() }) //nolint: errcheck
+	if err := migrate.Migrate(ctx, dbx); err != nil {
+		t.Fatal(err)
+	}
+	return dbx
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestBackupRestore(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := config.WithContext(context.TODO(), cfg)
+	ctx = log.WithContext(ctx, log.New(io.Discard))
+	dbx := openTestDB(t, ctx, cfg)
+	st := database.New(ctx, dbx)
+	ctx = db.WithContext(ctx, dbx)
+	ctx = store.WithContext(ctx, st)
+	be := backend.New(ctx, cfg, dbx, st)
+	admin, err := be.User(ctx, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"repo1", "org/empty"} {
+		if _, err := be.CreateRepository(ctx, name, admin, proto.RepositoryOptions{Description: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	work := t.TempDir()
+	git(t, work, "init", "-b", "main")
+	if err := os.WriteFile(filepath.Join(work, "README.md"), []byte("# repo1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, work, "add", "-A")
+	git(t, work, "commit", "-m", "first")
+	git(t, work, "push", filepath.Join(cfg.DataPath, "repos", "repo1.git"), "main")
+	git(t, filepath.Join(cfg.DataPath, "repos", "repo1.git"), "symbolic-ref", "HEAD", "refs/heads/main")
+	head := git(t, work, "rev-parse", "HEAD")
+
+	if err := os.MkdirAll(filepath.Dir(cfg.SSH.KeyPath), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfg.SSH.KeyPath, []byte("key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := st.GetRepoByName(ctx, dbx, "repo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "large file"
+	p := lfs.Pointer{Oid: "ed7d0b8ba031d5dc5b87e08d8182e8bd4801aee3a2a3c0d0db2c3d3b8b9bc1d8", Size: int64(len(content))}
+	strg, err := storage.NewLFSStorage(cfg, repo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strg.Put(p.RelativePath(), strings.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.CreateLFSObject(ctx, dbx, repo.ID, p.Oid, p.Size); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	m, err := Backup(ctx, cfg, dbx, &buf, Options{})
+	if err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if len(m.Repos) != 2 || !m.LFS || m.Database == "" {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+
+	// Backups can't be restored over an existing instance.
+	if _, err := Restore(ctx, cfg, bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrNotFresh) {
+		t.Fatalf("Restore over existing instance: got %v, want %v", err, ErrNotFresh)
+	}
+
+	cfg2 := newTestConfig(t)
+	if _, err := Restore(ctx, cfg2, bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	rp := filepath.Join(cfg2.DataPath, "repos", "repo1.git")
+	if got := git(t, rp, "rev-parse", "main"); got != head {
+		t.Errorf("restored main = %q, want %q", got, head)
+	}
+	if got := git(t, rp, "symbolic-ref", "HEAD"); got != "refs/heads/main" {
+		t.Errorf("restored HEAD = %q, want refs/heads/main", got)
+	}
+	if _, err := os.Stat(filepath.Join(cfg2.DataPath, "repos", "org", "empty.git", "HEAD")); err != nil {
+		t.Errorf("empty repository wasn't restored: %v", err)
+	}
+	if _, err := os.Stat(cfg2.SSH.KeyPath); err != nil {
+		t.Errorf("host key wasn't restored: %v", err)
+	}
+
+	strg2, err := storage.NewLFSStorage(cfg2, repo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := strg2.Exists(p.RelativePath()); err != nil || !ok {
+		t.Errorf("lfs object wasn't restored: %v", err)
+	}
+
+	dbx2 := openTestDB(t, ctx, cfg2)
+	r, err := database.New(ctx, dbx2).GetRepoByName(ctx, dbx2, "repo1")
+	if err != nil {
+		t.Fatalf("restored database: %v", err)
+	}
+	if r.Description != "repo1" {
+		t.Errorf("restored description = %q, want repo1", r.Description)
+	}
+}
+
+func TestBackupRestoreHookEnv(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := config.WithContext(context.TODO(), cfg)
+	ctx = log.WithContext(ctx, log.New(io.Discard))
+	dbx := openTestDB(t, ctx, cfg)
+	st := database.New(ctx, dbx)
+	ctx = db.WithContext(ctx, dbx)
+	ctx = store.WithContext(ctx, st)
+	be := backend.New(ctx, cfg, dbx, st)
+	admin, err := be.User(ctx, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := be.CreateRepository(ctx, "repo1", admin, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.SetHookEnv(ctx, "repo1", "DEPLOY_TOKEN", "s3cr3t", true); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := Backup(ctx, cfg, dbx, &buf, Options{}); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+
+	cfg2 := newTestConfig(t)
+	if _, err := Restore(ctx, cfg2, bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	// The custom hooks of the restored repository get its variables.
+	ctx2 := config.WithContext(context.TODO(), cfg2)
+	ctx2 = log.WithContext(ctx2, log.New(io.Discard))
+	dbx2 := openTestDB(t, ctx2, cfg2)
+	st2 := database.New(ctx2, dbx2)
+	ctx2 = db.WithContext(ctx2, dbx2)
+	ctx2 = store.WithContext(ctx2, st2)
+	vars, err := backend.New(ctx2, cfg2, dbx2, st2).HookEnv(ctx2, "repo1")
+	if err != nil {
+		t.Fatalf("HookEnv: %v", err)
+	}
+	if len(vars) != 1 || vars[0].Name != "DEPLOY_TOKEN" || vars[0].Value != "s3cr3t" {
+		t.Errorf("restored hook environment = %+v", vars)
+	}
+}
+
+func TestBackupExcludeLFS(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := config.WithContext(context.TODO(), cfg)
+	ctx = log.WithContext(ctx, log.New(io.Discard))
+	dbx := openTestDB(t, ctx, cfg)
+	st := database.New(ctx, dbx)
+	ctx = db.WithContext(ctx, dbx)
+	ctx = store.WithContext(ctx, st)
+	be := backend.New(ctx, cfg, dbx, st)
+	admin, err := be.User(ctx, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := be.CreateRepository(ctx, "repo1", admin, proto.RepositoryOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := st.GetRepoByName(ctx, dbx, "repo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := lfs.Pointer{Oid: "ed7d0b8ba031d5dc5b87e08d8182e8bd4801aee3a2a3c0d0db2c3d3b8b9bc1d8", Size: 1}
+	strg, err := storage.NewLFSStorage(cfg, repo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strg.Put(p.RelativePath(), strings.NewReader("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.CreateLFSObject(ctx, dbx, repo.ID, p.Oid, p.Size); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	m, err := Backup(ctx, cfg, dbx, &buf, Options{ExcludeLFS: true})
+	if err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if m.LFS {
+		t.Error("manifest says lfs objects are part of the backup")
+	}
+
+	gr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(hdr.Name, lfsDir+"/") {
+			t.Errorf("unexpected lfs entry %q", hdr.Name)
+		}
+	}
+}
+
+func TestBackupUnsupportedDatabase(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := config.WithContext(context.TODO(), cfg)
+	ctx = log.WithContext(ctx, log.New(io.Discard))
+	dbx := openTestDB(t, ctx, cfg)
+
+	// Only the driver matters, the database is only read to list the
+	// repositories.
+	cfg.DB.Driver = "postgres"
+	var buf bytes.Buffer
+	if _, err := Backup(ctx, cfg, dbx, &buf, Options{}); !errors.Is(err, ErrUnsupportedDatabase) {
+		t.Fatalf("Backup = %v, want %v", err, ErrUnsupportedDatabase)
+	}
+
+	m, err := Backup(ctx, cfg, dbx, &buf, Options{ExcludeDB: true})
+	if err != nil {
+		t.Fatalf("Backup without the database: %v", err)
+	}
+	if m.Database != "" {
+		t.Errorf("manifest database = %q, want none", m.Database)
+	}
+}
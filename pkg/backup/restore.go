@@ -0,0 +1,226 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/storage"
+)
+
+// ErrNotFresh is returned when restoring over an instance that already has
+// a database or repositories.
+var ErrNotFresh = errors.New("backups can only be restored on a fresh instance")
+
+// Restore recreates the state of an instance from a backup. The instance
+// must be fresh, that is, without a database or repositories. Existing
+// configuration and keys are kept.
+//
+// The database is restored as it was backed up, it still has to be
+// migrated, and the repository hooks regenerated, before serving it.
+func Restore(ctx context.Context, cfg *config.Config, r io.Reader) (*Manifest, error) {
+	dbPath := cfg.DB.DataSource
+	if i := strings.IndexByte(dbPath, '?'); i >= 0 {
+		dbPath = dbPath[:i]
+	}
+
+	reposPath := filepath.Join(cfg.DataPath, reposDir)
+	if isSqlite(cfg.DB.Driver) {
+		if _, err := os.Stat(dbPath); err == nil {
+			return nil, ErrNotFresh
+		}
+	}
+	if entries, err := os.ReadDir(reposPath); err == nil && len(entries) > 0 {
+		return nil, ErrNotFresh
+	}
+
+	tmp, err := os.MkdirTemp("", "soft-serve-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp) //nolint: errcheck
+
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read backup: %w", err)
+	}
+	defer gr.Close() //nolint: errcheck
+
+	tr := tar.NewReader(gr)
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != manifestPath {
+		return nil, errors.New("read backup: missing manifest")
+	}
+
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	if m.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d", m.Version)
+	}
+	if m.Database != "" && !isSqlite(cfg.DB.Driver) {
+		return nil, ErrUnsupportedDatabase
+	}
+
+	repos := make(map[string]bool, len(m.Repos))
+	for _, name := range m.Repos {
+		repos[name] = false
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read backup: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if !filepath.IsLocal(hdr.Name) {
+			return nil, fmt.Errorf("read backup: invalid entry %q", hdr.Name)
+		}
+
+		dir, rel, _ := strings.Cut(hdr.Name, "/")
+		switch {
+		case hdr.Name == databasePath:
+			if err := restoreFile(dbPath, 0o600, tr); err != nil {
+				return nil, fmt.Errorf("restore database: %w", err)
+			}
+		case dir == filesDir:
+			fp := filepath.Join(cfg.DataPath, filepath.FromSlash(rel))
+			if _, err := os.Stat(fp); err == nil {
+				continue
+			}
+			if err := restoreFile(fp, hdr.FileInfo().Mode().Perm(), tr); err != nil {
+				return nil, fmt.Errorf("restore %s: %w", rel, err)
+			}
+		case dir == reposDir:
+			if err := restoreRepo(cfg, tmp, repos, rel, hdr, tr); err != nil {
+				return nil, err
+			}
+		case dir == lfsDir:
+			if err := restoreLFSObject(cfg, rel, tr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Repositories without any entry are still created so the database
+	// doesn't point at missing repositories.
+	for name, ok := range repos {
+		if !ok {
+			if err := initRepo(cfg, name); err != nil {
+				return nil, fmt.Errorf("restore repository %s: %w", name, err)
+			}
+		}
+	}
+
+	return &m, nil
+}
+
+// restoreRepo restores an entry of a repository, either one of its files or
+// its bundle. Repositories are initialized on their first entry.
+func restoreRepo(cfg *config.Config, tmp string, repos map[string]bool, rel string, hdr *tar.Header, r io.Reader) error {
+	name, file, isFile := strings.Cut(rel, ".git/")
+	if !isFile {
+		name = strings.TrimSuffix(rel, ".git"+bundleExt)
+	}
+
+	initialized, ok := repos[name]
+	if !ok {
+		return fmt.Errorf("read backup: unknown repository entry %q", hdr.Name)
+	}
+	if !initialized {
+		if err := initRepo(cfg, name); err != nil {
+			return fmt.Errorf("restore repository %s: %w", name, err)
+		}
+		repos[name] = true
+	}
+
+	rp := filepath.Join(cfg.DataPath, reposDir, name+".git")
+	if isFile {
+		if err := restoreFile(filepath.Join(rp, filepath.FromSlash(file)), hdr.FileInfo().Mode().Perm(), r); err != nil {
+			return fmt.Errorf("restore repository %s: %w", name, err)
+		}
+		return nil
+	}
+
+	bundle := filepath.Join(tmp, "repo"+bundleExt)
+	defer os.Remove(bundle) //nolint: errcheck
+	if err := restoreFile(bundle, 0o600, r); err != nil {
+		return fmt.Errorf("restore repository %s: %w", name, err)
+	}
+	if _, err := gitb.NewCommand("fetch", "--quiet", bundle, "refs/*:refs/*").
+		WithTimeout(-1).
+		RunInDir(rp); err != nil {
+		return fmt.Errorf("restore repository %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// restoreLFSObject puts an LFS object back in the storage of its
+// repository.
+func restoreLFSObject(cfg *config.Config, rel string, r io.Reader) error {
+	id, name, _ := strings.Cut(rel, "/")
+	repoID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("read backup: invalid lfs entry %q", path.Join(lfsDir, rel))
+	}
+
+	strg, err := storage.NewLFSStorage(cfg, repoID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := strg.Put(name, r); err != nil {
+		return fmt.Errorf("restore lfs object %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// initRepo creates an empty bare repository.
+func initRepo(cfg *config.Config, name string) error {
+	rp := filepath.Join(cfg.DataPath, reposDir, name+".git")
+	_, err := gitb.Init(rp, true)
+	return err
+}
+
+// restoreFile writes a file, creating its parent directories.
+func restoreFile(fp string, mode fs.FileMode, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(fp), os.ModePerm); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(fp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close() //nolint: errcheck
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// deprecatedKeys maps the keys of older configuration files to the keys that
+// replaced them.
+var deprecatedKeys = map[string]string{
+	"log_format": "log.format",
+}
+
+// Issue is a problem found when checking a configuration.
+type Issue struct {
+	// Key is the configuration key of the problem, it's empty when the
+	// problem isn't about a single key.
+	Key string
+
+	// Message describes the problem.
+	Message string
+
+	// Warning is true if the problem doesn't keep the server from starting,
+	// like a deprecated or unknown key.
+	Warning bool
+}
+
+// String implements fmt.Stringer.
+func (i Issue) String() string {
+	if i.Key == "" {
+		return i.Message
+	}
+	return i.Key + ": " + i.Message
+}
+
+// Check loads the configuration file at path, applies the environment
+// variables, and reports the problems of the resulting configuration without
+// starting anything. It returns the loaded configuration, which is only
+// usable if none of the issues is an error.
+func Check(path string) (*Config, []Issue) {
+	var issues []Issue
+	errorf := func(key, format string, args ...any) {
+		issues = append(issues, Issue{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	cfg := DefaultConfig()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		errorf("", "read config: %v", err)
+		return cfg, issues
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		errorf("", "parse config: %v", err)
+		return cfg, issues
+	}
+	if len(root.Content) > 0 {
+		issues = append(issues, checkKeys(root.Content[0], reflect.TypeOf(*cfg), "")...)
+		if err := root.Content[0].Decode(cfg); err != nil {
+			errorf("", "decode config: %v", err)
+			return cfg, issues
+		}
+	}
+
+	// parseEnv calls Validate, which returns the first invalid setting.
+	if err := parseEnv(cfg); err != nil {
+		errorf("", "%v", err)
+	}
+
+	issues = append(issues, checkListeners(cfg)...)
+	issues = append(issues, checkFiles(cfg)...)
+
+	return cfg, issues
+}
+
+// checkKeys reports the deprecated and unknown keys of the mapping node n,
+// decoded into a value of type t.
+func checkKeys(n *yaml.Node, t reflect.Type, prefix string) []Issue {
+	if n.Kind != yaml.MappingNode || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = f.Type
+	}
+
+	var issues []Issue
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := prefix + n.Content[i].Value
+		ft, ok := fields[n.Content[i].Value]
+		switch {
+		case ok:
+			issues = append(issues, checkKeys(n.Content[i+1], ft, key+".")...)
+		case deprecatedKeys[key] != "":
+			issues = append(issues, Issue{
+				Key:     key,
+				Message: fmt.Sprintf("deprecated and ignored, use %s instead", deprecatedKeys[key]),
+				Warning: true,
+			})
+		default:
+			issues = append(issues, Issue{
+				Key:     key,
+				Message: fmt.Sprintf("unknown key on line %d", n.Content[i].Line),
+				Warning: true,
+			})
+		}
+	}
+
+	return issues
+}
+
+// checkListeners reports invalid and conflicting listen addresses of the
+// enabled servers.
+func checkListeners(cfg *Config) []Issue {
+	var issues []Issue
+	seen := make(map[string]string)
+	for _, l := range []struct {
+		key     string
+		enabled bool
+		addr    string
+	}{
+		{"ssh.listen_addr", cfg.SSH.Enabled, cfg.SSH.ListenAddr},
+		{"git.listen_addr", cfg.Git.Enabled, cfg.Git.ListenAddr},
+		{"http.listen_addr", cfg.HTTP.Enabled, cfg.HTTP.ListenAddr},
+		{"stats.listen_addr", cfg.Stats.Enabled, cfg.Stats.ListenAddr},
+	} {
+		if !l.enabled {
+			continue
+		}
+
+		host, port, err := net.SplitHostPort(l.addr)
+		if err != nil {
+			issues = append(issues, Issue{Key: l.key, Message: fmt.Sprintf("invalid address %q: %v", l.addr, err)})
+			continue
+		}
+		if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+			issues = append(issues, Issue{Key: l.key, Message: fmt.Sprintf("invalid port %q", port)})
+			continue
+		}
+
+		addr := net.JoinHostPort(host, port)
+		if other, ok := seen[addr]; ok {
+			issues = append(issues, Issue{Key: l.key, Message: fmt.Sprintf("address %q is also used by %s", l.addr, other)})
+			continue
+		}
+		seen[addr] = l.key
+	}
+
+	return issues
+}
+
+// checkFiles reports the files referenced by the configuration that don't
+// exist, and the TLS key and certificate when only one of them is set.
+func checkFiles(cfg *Config) []Issue {
+	var issues []Issue
+	if (cfg.HTTP.TLSKeyPath == "") != (cfg.HTTP.TLSCertPath == "") {
+		issues = append(issues, Issue{
+			Key:     "http",
+			Message: "tls_key_path and tls_cert_path must be set together",
+		})
+	}
+
+	for _, f := range []struct {
+		key  string
+		path string
+	}{
+		{"http.tls_key_path", cfg.HTTP.TLSKeyPath},
+		{"http.tls_cert_path", cfg.HTTP.TLSCertPath},
+		{"ssh.trusted_user_ca_keys", cfg.SSH.TrustedUserCAKeys},
+		{"ssh.revoked_keys", cfg.SSH.RevokedKeys},
+		{"ssh.banner.path", cfg.SSH.Banner.Path},
+		{"ssh.motd.path", cfg.SSH.MOTD.Path},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if !filepath.IsAbs(f.path) {
+			f.path = filepath.Join(cfg.DataPath, f.path)
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			issues = append(issues, Issue{Key: f.key, Message: err.Error()})
+		}
+	}
+
+	if cfg.Git.BinaryPath != "" {
+		if _, err := exec.LookPath(cfg.Git.BinaryPath); err != nil {
+			issues = append(issues, Issue{Key: "git.binary_path", Message: err.Error()})
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCheckDefaultConfig(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	is.NoErr(cfg.WriteConfig())
+
+	// The generated configuration file has no unknown keys.
+	_, issues := Check(cfg.ConfigPath())
+	is.Equal(len(issues), 0)
+}
+
+func TestCheck(t *testing.T) {
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	is.NoErr(os.WriteFile(path, []byte(`log_format: json
+ssh:
+  listen_addr: ":23231"
+  ciphers: ["nope"]
+  colour: true
+git:
+  enabled: true
+  listen_addr: ":23231"
+http:
+  listen_addr: "localhost"
+  tls_key_path: /nonexistent/key.pem
+`), 0o600))
+
+	_, issues := Check(path)
+	want := map[string]bool{
+		"log_format":        true,
+		"ssh.colour":        true,
+		"git.listen_addr":   false,
+		"http.listen_addr":  false,
+		"http":              false,
+		"http.tls_key_path": false,
+	}
+	got := make(map[string]bool)
+	for _, i := range issues {
+		if i.Key == "" {
+			// Validate reports the unsupported cipher.
+			is.True(!i.Warning)
+			continue
+		}
+		got[i.Key] = i.Warning
+	}
+	is.Equal(got, want)
+}
+
+func TestCheckInvalidYAML(t *testing.T) {
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	is.NoErr(os.WriteFile(path, []byte("ssh: [\n"), 0o600))
+
+	_, issues := Check(path)
+	is.Equal(len(issues), 1)
+	is.True(!issues[0].Warning)
+}
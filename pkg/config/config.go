@@ -1,15 +1,22 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"net/netip"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"charm.land/log/v2"
 	"github.com/caarlos0/env/v11"
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
 	"golang.org/x/crypto/ssh"
 	"gopkg.in/yaml.v3"
 )
@@ -36,8 +43,96 @@ type SSHConfig struct {
 	// MaxTimeout is the maximum number of seconds a connection can take.
 	MaxTimeout int `env:"MAX_TIMEOUT" yaml:"max_timeout"`
 
-	// IdleTimeout is the number of seconds a connection can be idle before it is closed.
+	// IdleTimeout is the number of seconds a session can be idle before it is
+	// closed. Sessions running git commands are idle when no data is
+	// transferred, interactive sessions when there's no input.
 	IdleTimeout int `env:"IDLE_TIMEOUT" yaml:"idle_timeout"`
+
+	// TrustedUserCAKeys is the path to a file of CA public keys, in the
+	// authorized keys format, trusted to sign user certificates. Certificate
+	// principals are mapped to usernames.
+	TrustedUserCAKeys string `env:"TRUSTED_USER_CA_KEYS" yaml:"trusted_user_ca_keys"`
+
+	// RevokedKeys is the path to an OpenSSH key revocation list, or a file of
+	// public keys, that are refused.
+	RevokedKeys string `env:"REVOKED_KEYS" yaml:"revoked_keys"`
+
+	// RateLimit is the rate limit configuration of the SSH server.
+	RateLimit SSHRateLimitConfig `envPrefix:"RATE_LIMIT_" yaml:"rate_limit"`
+
+	// Ciphers is the list of allowed ciphers, in order of preference. An
+	// empty list uses the library defaults.
+	Ciphers []string `env:"CIPHERS" envSeparator:"," yaml:"ciphers"`
+
+	// KeyExchanges is the list of allowed key exchange algorithms, in order
+	// of preference. An empty list uses the library defaults.
+	KeyExchanges []string `env:"KEY_EXCHANGES" envSeparator:"," yaml:"key_exchanges"`
+
+	// MACs is the list of allowed message authentication codes, in order of
+	// preference. An empty list uses the library defaults.
+	MACs []string `env:"MACS" envSeparator:"," yaml:"macs"`
+
+	// KeyboardInteractive is the keyboard interactive authentication
+	// configuration of the SSH server.
+	KeyboardInteractive SSHKeyboardInteractiveConfig `envPrefix:"KEYBOARD_INTERACTIVE_" yaml:"keyboard_interactive"`
+
+	// Banner is sent to clients before they authenticate, including the
+	// ones that fail to.
+	Banner SSHMessageConfig `envPrefix:"BANNER_" yaml:"banner"`
+
+	// MOTD is the message of the day shown on the TUI welcome screen.
+	MOTD SSHMessageConfig `envPrefix:"MOTD_" yaml:"motd"`
+}
+
+// SSHMessageConfig is a message shown to SSH users. It's a Go text/template
+// executed with the server name as {{ .ServerName }} and the username as
+// {{ .Username }}.
+type SSHMessageConfig struct {
+	// Text is the message.
+	Text string `env:"TEXT" yaml:"text"`
+
+	// Path is the path of a file with the message, it's read every time the
+	// message is shown.
+	Path string `env:"PATH" yaml:"path"`
+}
+
+// SSHKeyboardInteractiveConfig is the keyboard interactive authentication
+// configuration. Clients without an accepted public key are prompted for a
+// code, which is verified by an authenticator.
+type SSHKeyboardInteractiveConfig struct {
+	// Enabled toggles keyboard interactive authentication on/off.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// Command is the command that verifies codes. It's run with the SSH
+	// username as its argument and the code on stdin, and succeeds if the
+	// code is valid. It may print the name of the user to log in as.
+	Command string `env:"COMMAND" yaml:"command"`
+
+	// Prompt is the prompt shown to clients.
+	Prompt string `env:"PROMPT" yaml:"prompt"`
+
+	// CreateUsers creates users on their first successful login.
+	CreateUsers bool `env:"CREATE_USERS" yaml:"create_users"`
+}
+
+// SSHRateLimitConfig is the per source IP rate limit configuration of the
+// SSH server. A rate of 0 disables the corresponding limit.
+type SSHRateLimitConfig struct {
+	// ConnectionRate is the number of new connections per second allowed per
+	// source IP.
+	ConnectionRate float64 `env:"CONNECTION_RATE" yaml:"connection_rate"`
+
+	// ConnectionBurst is the number of connections a source IP can open at
+	// once before ConnectionRate applies.
+	ConnectionBurst int `env:"CONNECTION_BURST" yaml:"connection_burst"`
+
+	// AuthRate is the number of authentication attempts per second allowed
+	// per source IP.
+	AuthRate float64 `env:"AUTH_RATE" yaml:"auth_rate"`
+
+	// AuthBurst is the number of authentication attempts a source IP can make
+	// at once before AuthRate applies.
+	AuthBurst int `env:"AUTH_BURST" yaml:"auth_burst"`
 }
 
 // GitConfig is the Git daemon configuration for the server.
@@ -59,6 +154,25 @@ type GitConfig struct {
 
 	// MaxConnections is the maximum number of concurrent connections.
 	MaxConnections int `env:"MAX_CONNECTIONS" yaml:"max_connections"`
+
+	// BinaryPath is the path of the git executable used to serve git
+	// services. Defaults to looking up "git" in PATH.
+	BinaryPath string `env:"BINARY_PATH" yaml:"binary_path"`
+
+	// RateLimit is the rate limit configuration of the Git daemon.
+	RateLimit GitRateLimitConfig `envPrefix:"RATE_LIMIT_" yaml:"rate_limit"`
+}
+
+// GitRateLimitConfig is the per source IP rate limit configuration of the Git
+// daemon. A rate of 0 disables the limit.
+type GitRateLimitConfig struct {
+	// ConnectionRate is the number of new connections per second allowed per
+	// source IP.
+	ConnectionRate float64 `env:"CONNECTION_RATE" yaml:"connection_rate"`
+
+	// ConnectionBurst is the number of connections a source IP can open at
+	// once before ConnectionRate applies.
+	ConnectionBurst int `env:"CONNECTION_BURST" yaml:"connection_burst"`
 }
 
 // CORSConfig is the CORS configuration for the server.
@@ -89,6 +203,65 @@ type HTTPConfig struct {
 
 	// CORS is the cross-origin configuration for the HTTP server.
 	CORS CORSConfig `envPrefix:"CORS_" yaml:"cors"`
+
+	// AllowDumbHTTP toggles serving repositories over the dumb HTTP
+	// protocol. Only repositories readable by anonymous users are served.
+	AllowDumbHTTP bool `env:"ALLOW_DUMB_HTTP" yaml:"allow_dumb_http"`
+
+	// OIDC is the OpenID Connect login configuration.
+	OIDC OIDCConfig `envPrefix:"OIDC_" yaml:"oidc"`
+
+	// TrustedProxies are the addresses or CIDR ranges of the reverse proxies
+	// in front of the HTTP server. The client address of their requests is
+	// read from the Forwarded or X-Forwarded-For header.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:"," yaml:"trusted_proxies"`
+}
+
+// TrustedProxyRanges returns the address ranges of the trusted proxies.
+func (c HTTPConfig) TrustedProxyRanges() ([]netip.Prefix, error) {
+	ranges := make([]netip.Prefix, 0, len(c.TrustedProxies))
+	for _, p := range c.TrustedProxies {
+		if prefix, err := netip.ParsePrefix(p); err == nil {
+			ranges = append(ranges, prefix.Masked())
+			continue
+		}
+
+		ip, err := netip.ParseAddr(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", p)
+		}
+		ip = ip.Unmap()
+		ranges = append(ranges, netip.PrefixFrom(ip, ip.BitLen()))
+	}
+
+	return ranges, nil
+}
+
+// OIDCConfig is the OpenID Connect login configuration of the HTTP server.
+// Logins are enabled when an issuer is set.
+type OIDCConfig struct {
+	// Issuer is the URL of the OpenID provider.
+	Issuer string `env:"ISSUER" yaml:"issuer"`
+
+	// ClientID is the client ID registered with the provider.
+	ClientID string `env:"CLIENT_ID" yaml:"client_id"`
+
+	// ClientSecret is the client secret registered with the provider.
+	ClientSecret string `env:"CLIENT_SECRET" yaml:"client_secret"`
+
+	// Scopes are the scopes requested in addition to "openid".
+	Scopes []string `env:"SCOPES" envSeparator:"," yaml:"scopes"`
+
+	// UsernameClaim is the ID token claim mapped to usernames. With "email",
+	// users are matched on their verified email address instead, and new
+	// users are named after the preferred_username claim.
+	UsernameClaim string `env:"USERNAME_CLAIM" yaml:"username_claim"`
+
+	// CreateUsers creates users on their first login.
+	CreateUsers bool `env:"CREATE_USERS" yaml:"create_users"`
+
+	// SessionLifetime is the number of seconds a login session lasts.
+	SessionLifetime int `env:"SESSION_LIFETIME" yaml:"session_lifetime"`
 }
 
 // StatsConfig is the configuration for the stats server.
@@ -113,6 +286,57 @@ type LogConfig struct {
 	// Path to a file to write logs to.
 	// If not set, logs will be written to stderr.
 	Path string `env:"PATH" yaml:"path"`
+
+	// Level is the log level, one of debug, info, warn, error, and fatal.
+	// SOFT_SERVE_DEBUG sets it to debug.
+	Level string `env:"LEVEL" yaml:"level"`
+
+	// Levels are the log levels of subsystems, see [LogSubsystems]. The
+	// subsystems that aren't listed log at Level.
+	Levels map[string]string `env:"LEVELS" envSeparator:"," envKeyValSeparator:"=" yaml:"levels"`
+}
+
+// LogSubsystems are the parts of the server whose log level can be set on
+// their own.
+var LogSubsystems = []string{"db", "git", "http", "lfs", "ssh", "webhook"}
+
+// ValidateLogSubsystem returns an error if name isn't one of LogSubsystems.
+func ValidateLogSubsystem(name string) error {
+	if !slices.Contains(LogSubsystems, name) {
+		return fmt.Errorf("unknown log subsystem %q, want one of: %s", name, strings.Join(LogSubsystems, ", "))
+	}
+
+	return nil
+}
+
+// levelsEnv returns Levels as the value of SOFT_SERVE_LOG_LEVELS.
+func (c LogConfig) levelsEnv() string {
+	levels := make([]string, 0, len(c.Levels))
+	for _, name := range slices.Sorted(maps.Keys(c.Levels)) {
+		levels = append(levels, name+"="+c.Levels[name])
+	}
+
+	return strings.Join(levels, ",")
+}
+
+// validate returns an error if a log level or subsystem is invalid.
+func (c LogConfig) validate() error {
+	if c.Level != "" {
+		if _, err := log.ParseLevel(c.Level); err != nil {
+			return fmt.Errorf("log level: %w", err)
+		}
+	}
+
+	for name, level := range c.Levels {
+		if err := ValidateLogSubsystem(name); err != nil {
+			return err
+		}
+		if _, err := log.ParseLevel(level); err != nil {
+			return fmt.Errorf("log level of %s: %w", name, err)
+		}
+	}
+
+	return nil
 }
 
 // DBConfig is the database connection configuration.
@@ -122,6 +346,18 @@ type DBConfig struct {
 
 	// DataSource is the database data source name.
 	DataSource string `env:"DATA_SOURCE" yaml:"data_source"`
+
+	// MaxOpenConns is the maximum number of open connections to the
+	// database. A value of 0 means no limit.
+	MaxOpenConns int `env:"MAX_OPEN_CONNS" yaml:"max_open_conns"`
+
+	// MaxIdleConns is the maximum number of idle connections kept in the
+	// pool. A value of 0 keeps no idle connections.
+	MaxIdleConns int `env:"MAX_IDLE_CONNS" yaml:"max_idle_conns"`
+
+	// ConnMaxLifetime is the maximum number of seconds a connection can be
+	// reused. A value of 0 means connections are reused forever.
+	ConnMaxLifetime int `env:"CONN_MAX_LIFETIME" yaml:"conn_max_lifetime"`
 }
 
 // LFSConfig is the configuration for Git LFS.
@@ -132,14 +368,360 @@ type LFSConfig struct {
 	// SSHEnabled is whether or not Git LFS over SSH is enabled.
 	// This is only used if LFS is enabled.
 	SSHEnabled bool `env:"SSH_ENABLED" yaml:"ssh_enabled"`
+
+	// Storage is where LFS objects are stored.
+	// Valid values are "local" and "s3".
+	Storage string `env:"STORAGE" yaml:"storage"`
+
+	// S3 is the S3 storage configuration.
+	// This is only used if Storage is "s3".
+	S3 S3Config `envPrefix:"S3_" yaml:"s3"`
+}
+
+// S3Config is the configuration for an S3 compatible object storage.
+type S3Config struct {
+	// Endpoint is the URL of the S3 server, e.g. "https://s3.amazonaws.com"
+	// or "http://localhost:9000".
+	Endpoint string `env:"ENDPOINT" yaml:"endpoint"`
+
+	// Bucket is the name of the bucket objects are stored in.
+	Bucket string `env:"BUCKET" yaml:"bucket"`
+
+	// Region is the region of the bucket.
+	Region string `env:"REGION" yaml:"region"`
+
+	// AccessKeyID is the access key ID used to authenticate.
+	AccessKeyID string `env:"ACCESS_KEY_ID" yaml:"access_key_id"`
+
+	// SecretAccessKey is the secret access key used to authenticate.
+	SecretAccessKey string `env:"SECRET_ACCESS_KEY" yaml:"secret_access_key"`
+
+	// UsePathStyle is whether to use path style requests. This is required by
+	// most S3 compatible servers, such as MinIO.
+	UsePathStyle bool `env:"USE_PATH_STYLE" yaml:"use_path_style"`
+}
+
+// RepoConfig is the configuration for repositories.
+type RepoConfig struct {
+	// MaxSize is the maximum size in bytes a repository can grow to through
+	// pushes. A value of 0 means no limit.
+	MaxSize int64 `env:"MAX_SIZE" yaml:"max_size"`
+
+	// MaxUserSize is the default maximum total size in bytes of the
+	// repositories a user owns. Admins can override it per user. A value of 0
+	// means no limit.
+	MaxUserSize int64 `env:"MAX_USER_SIZE" yaml:"max_user_size"`
+
+	// MaxPackBytes is the maximum number of bytes a client can send in a
+	// single push. A value of 0 means no limit.
+	MaxPackBytes int64 `env:"MAX_PACK_BYTES" yaml:"max_pack_bytes"`
+
+	// DefaultBranch is the branch HEAD of new repositories points to. An
+	// empty value uses the default of git. Existing repositories keep theirs.
+	DefaultBranch string `env:"DEFAULT_BRANCH" yaml:"default_branch"`
+
+	// AllowPushCreate is whether pushing to a repository that doesn't exist
+	// creates it. Admins can override it per user. Users still need the
+	// permission to create repositories.
+	AllowPushCreate bool `env:"ALLOW_PUSH_CREATE" yaml:"allow_push_create"`
+
+	// DenyNonFastForwards rejects force pushes to all repositories.
+	DenyNonFastForwards bool `env:"DENY_NON_FAST_FORWARDS" yaml:"deny_non_fast_forwards"`
+
+	// DisableFilters disables partial clones of the repositories that don't
+	// override it.
+	DisableFilters bool `env:"DISABLE_FILTERS" yaml:"disable_filters"`
+
+	// AllowedFilters restricts the partial clone filters clients can use.
+	// An empty list allows all filters.
+	AllowedFilters []string `env:"ALLOWED_FILTERS" envSeparator:"," yaml:"allowed_filters"`
+
+	// HideArchived leaves archived repositories out of repository listings.
+	HideArchived bool `env:"HIDE_ARCHIVED" yaml:"hide_archived"`
+
+	// Languages is whether the language breakdown of the default branch of
+	// repositories is computed and shown.
+	Languages bool `env:"LANGUAGES" yaml:"languages"`
+
+	// MaxConcurrentReads is the maximum number of fetches and clones running
+	// concurrently against a single repository. A value of 0 means no limit.
+	MaxConcurrentReads int `env:"MAX_CONCURRENT_READS" yaml:"max_concurrent_reads"`
+
+	// MaxConcurrentWrites is the maximum number of pushes running
+	// concurrently against a single repository. A value of 0 means no limit.
+	MaxConcurrentWrites int `env:"MAX_CONCURRENT_WRITES" yaml:"max_concurrent_writes"`
+
+	// MaxConcurrentOperations is the maximum number of git operations
+	// running concurrently against all repositories. A value of 0 means no
+	// limit.
+	MaxConcurrentOperations int `env:"MAX_CONCURRENT_OPERATIONS" yaml:"max_concurrent_operations"`
+
+	// QueueTimeout is the maximum number of seconds an operation over the
+	// limits waits for a slot before it's rejected. A value of 0 rejects
+	// operations over the limits right away.
+	QueueTimeout int `env:"QUEUE_TIMEOUT" yaml:"queue_timeout"`
 }
 
 // JobsConfig is the configuration for cron jobs.
 type JobsConfig struct {
 	MirrorPull string `env:"MIRROR_PULL" yaml:"mirror_pull"`
+
+	// MirrorTimeout is the maximum number of seconds a single mirror update
+	// can take. A value of 0 uses the default of 60 seconds.
+	MirrorTimeout int `env:"MIRROR_TIMEOUT" yaml:"mirror_timeout"`
+
+	// LFSVerify is the spec of the job verifying stored LFS objects. An empty
+	// spec disables the job.
+	LFSVerify string `env:"LFS_VERIFY" yaml:"lfs_verify"`
+
+	// RepoStats is the spec of the job refreshing the repository stats and
+	// languages. An empty spec disables the job, stats are still refreshed
+	// after pushes.
+	RepoStats string `env:"REPO_STATS" yaml:"repo_stats"`
+
+	// RepoGC is the spec of the job garbage collecting repositories. An empty
+	// spec disables the job.
+	RepoGC string `env:"REPO_GC" yaml:"repo_gc"`
+
+	// RepoFsck is the spec of the job checking the integrity of repositories
+	// with git fsck. An empty spec disables the job.
+	RepoFsck string `env:"REPO_FSCK" yaml:"repo_fsck"`
+
+	// RepoGCLooseObjects is the number of loose objects that gets a
+	// repository collected by the job. A value of 0 uses the default of 1000.
+	RepoGCLooseObjects int `env:"REPO_GC_LOOSE_OBJECTS" yaml:"repo_gc_loose_objects"`
+
+	// RepoGCInterval is the number of hours after which a repository with
+	// loose objects or several packs is collected by the job, whatever its
+	// number of loose objects. A value of 0 uses the default of 168 hours.
+	RepoGCInterval int `env:"REPO_GC_INTERVAL" yaml:"repo_gc_interval"`
+
+	// MaintenanceWindow is the daily time range, in the server's local time,
+	// during which the maintenance jobs run, as "HH:MM-HH:MM". A range can
+	// span midnight. Runs falling outside the window are deferred to its
+	// start. An empty window lets the jobs run on their schedule.
+	MaintenanceWindow string `env:"MAINTENANCE_WINDOW" yaml:"maintenance_window"`
+
+	// MaintenanceJobs are the names of the jobs restricted to the maintenance
+	// window, other jobs always run on their schedule.
+	MaintenanceJobs []string `env:"MAINTENANCE_JOBS" envSeparator:"," yaml:"maintenance_jobs"`
+}
+
+// Window returns the maintenance window, or nil if jobs aren't restricted to
+// one.
+func (c JobsConfig) Window() (*MaintenanceWindow, error) {
+	if c.MaintenanceWindow == "" {
+		return nil, nil
+	}
+
+	invalid := fmt.Errorf("invalid maintenance window %q, want HH:MM-HH:MM", c.MaintenanceWindow)
+	start, end, ok := strings.Cut(c.MaintenanceWindow, "-")
+	if !ok {
+		return nil, invalid
+	}
+
+	var w MaintenanceWindow
+	var err error
+	if w.Start, err = timeOfDay(start); err != nil {
+		return nil, invalid
+	}
+	if w.End, err = timeOfDay(end); err != nil {
+		return nil, invalid
+	}
+
+	if w.Start == w.End {
+		return nil, fmt.Errorf("invalid maintenance window %q, it's empty", c.MaintenanceWindow)
+	}
+
+	return &w, nil
+}
+
+// IsMaintenanceJob returns whether the job is restricted to the maintenance
+// window.
+func (c JobsConfig) IsMaintenanceJob(name string) bool {
+	for _, j := range c.MaintenanceJobs {
+		if strings.TrimSpace(j) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MaintenanceWindow is a daily time range. Start and End are the times of day
+// it begins and ends at, as durations since midnight. The window spans
+// midnight when End is before Start.
+type MaintenanceWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Contains returns whether t falls within the window.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	d := t.Sub(midnight(t))
+	if w.Start < w.End {
+		return d >= w.Start && d < w.End
+	}
+
+	return d >= w.Start || d < w.End
+}
+
+// Next returns the time the window next begins at after t.
+func (w MaintenanceWindow) Next(t time.Time) time.Time {
+	next := midnight(t).Add(w.Start)
+	if !next.After(t) {
+		next = midnight(t).AddDate(0, 0, 1).Add(w.Start)
+	}
+
+	return next
+}
+
+// timeOfDay parses a HH:MM time of day as a duration since midnight.
+func timeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// midnight returns the beginning of the day of t.
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// WebhookConfig is the configuration for webhook deliveries.
+type WebhookConfig struct {
+	// MaxAttempts is the number of times a delivery is attempted before it's
+	// marked as failed. A value of 0 uses the default of 5 attempts.
+	MaxAttempts int `env:"MAX_ATTEMPTS" yaml:"max_attempts"`
+
+	// BaseDelay is the number of seconds to wait before retrying a failed
+	// delivery. The delay doubles with each attempt. A value of 0 uses the
+	// default of 10 seconds.
+	BaseDelay int `env:"BASE_DELAY" yaml:"base_delay"`
+
+	// Workers is the number of deliveries sent concurrently. A value of 0
+	// uses the default of 4 workers.
+	Workers int `env:"WORKERS" yaml:"workers"`
+}
+
+// HooksConfig is the configuration for custom git hooks.
+type HooksConfig struct {
+	// Timeout is the maximum number of seconds a custom hook can run. A value
+	// of 0 uses the default of 60 seconds.
+	Timeout int `env:"TIMEOUT" yaml:"timeout"`
+
+	// Env is the names of environment variables passed to custom hooks, in
+	// addition to the defaults.
+	Env []string `env:"ENV" envSeparator:"," yaml:"env"`
+
+	// SecretKeyPath is the path to the key encrypting the environment
+	// variables of repository hooks and the credentials of mirrors. It's
+	// generated when missing.
+	SecretKeyPath string `env:"SECRET_KEY_PATH" yaml:"secret_key_path"`
+}
+
+// LDAPConfig is the configuration for authenticating users against an LDAP
+// directory. It's enabled when a URL is set.
+type LDAPConfig struct {
+	// URL is the ldap:// or ldaps:// URL of the directory server.
+	URL string `env:"URL" yaml:"url"`
+
+	// StartTLS upgrades ldap:// connections to TLS.
+	StartTLS bool `env:"START_TLS" yaml:"start_tls"`
+
+	// InsecureSkipVerify disables the verification of the server certificate.
+	InsecureSkipVerify bool `env:"INSECURE_SKIP_VERIFY" yaml:"insecure_skip_verify"`
+
+	// BindDN and BindPassword are the credentials of the service account
+	// searching for users. Searches are anonymous without them.
+	BindDN       string `env:"BIND_DN" yaml:"bind_dn"`
+	BindPassword string `env:"BIND_PASSWORD" yaml:"bind_password"`
+
+	// BaseDN is the base of user searches.
+	BaseDN string `env:"BASE_DN" yaml:"base_dn"`
+
+	// UserFilter is the filter finding a user, with %s replaced by the
+	// username.
+	UserFilter string `env:"USER_FILTER" yaml:"user_filter"`
+
+	// GroupAttribute is the user attribute listing the groups of the user.
+	GroupAttribute string `env:"GROUP_ATTRIBUTE" yaml:"group_attribute"`
+
+	// GroupAccess maps groups to the access level their members have on all
+	// repositories, as "access-level:group DN" entries.
+	GroupAccess []string `env:"GROUP_ACCESS" envSeparator:";" yaml:"group_access"`
+
+	// CreateUsers creates users on their first login.
+	CreateUsers bool `env:"CREATE_USERS" yaml:"create_users"`
+
+	// HTTP toggles directory passwords for git over HTTP.
+	HTTP bool `env:"HTTP" yaml:"http"`
+
+	// CacheTTL is the number of seconds successful logins are cached.
+	CacheTTL int `env:"CACHE_TTL" yaml:"cache_ttl"`
+}
+
+// GroupAccessLevels returns the access levels of the groups in GroupAccess,
+// keyed by the lowercase group DN.
+func (c LDAPConfig) GroupAccessLevels() (map[string]access.AccessLevel, error) {
+	levels := make(map[string]access.AccessLevel, len(c.GroupAccess))
+	for _, ga := range c.GroupAccess {
+		l, group, ok := strings.Cut(ga, ":")
+		level := access.ParseAccessLevel(strings.TrimSpace(l))
+		group = strings.ToLower(strings.TrimSpace(group))
+		if !ok || level < 0 || group == "" {
+			return nil, fmt.Errorf("invalid ldap group access %q, want access-level:group", ga)
+		}
+		levels[group] = level
+	}
+
+	return levels, nil
+}
+
+// UIConfig is the configuration of the TUI.
+type UIConfig struct {
+	// Theme is the color scheme of the TUI.
+	Theme ThemeConfig `envPrefix:"THEME_" yaml:"theme"`
+}
+
+// ThemeConfig is a color scheme. Colors are lipgloss color values, ANSI
+// color numbers from 0 to 255 or hex colors, and override the ones of the
+// built-in theme.
+type ThemeConfig struct {
+	// Name is the built-in theme, one of "dark", "light", and
+	// "high-contrast". It defaults to "dark".
+	Name string `env:"NAME" yaml:"name"`
+
+	Accent     string `env:"ACCENT" yaml:"accent"`
+	AccentText string `env:"ACCENT_TEXT" yaml:"accent_text"`
+	Secondary  string `env:"SECONDARY" yaml:"secondary"`
+	Highlight  string `env:"HIGHLIGHT" yaml:"highlight"`
+	Selection  string `env:"SELECTION" yaml:"selection"`
+	Background string `env:"BACKGROUND" yaml:"background"`
+	Text       string `env:"TEXT" yaml:"text"`
+	Muted      string `env:"MUTED" yaml:"muted"`
+	Subtle     string `env:"SUBTLE" yaml:"subtle"`
+	Added      string `env:"ADDED" yaml:"added"`
+	Deleted    string `env:"DELETED" yaml:"deleted"`
 }
 
 // Config is the configuration for Soft Serve.
+// AuditConfig is the configuration for the audit log of access-control
+// decisions.
+type AuditConfig struct {
+	// Sink is where the audit log is written.
+	// Valid values are "file" and "database", an empty value disables the
+	// audit log.
+	Sink string `env:"SINK" yaml:"sink"`
+
+	// Path is the file the audit log is written to.
+	// This is only used if Sink is "file".
+	Path string `env:"PATH" yaml:"path"`
+}
+
 type Config struct {
 	// Name is the name of the server.
 	Name string `env:"NAME" yaml:"name"`
@@ -165,9 +747,32 @@ type Config struct {
 	// LFS is the configuration for Git LFS.
 	LFS LFSConfig `envPrefix:"LFS_" yaml:"lfs"`
 
+	// Repo is the configuration for repositories.
+	Repo RepoConfig `envPrefix:"REPO_" yaml:"repo"`
+
 	// Jobs is the configuration for cron jobs
 	Jobs JobsConfig `envPrefix:"JOBS_" yaml:"jobs"`
 
+	// LDAP is the configuration for LDAP authentication.
+	LDAP LDAPConfig `envPrefix:"LDAP_" yaml:"ldap"`
+
+	// Webhook is the configuration for webhook deliveries.
+	Webhook WebhookConfig `envPrefix:"WEBHOOK_" yaml:"webhook"`
+
+	// Audit is the configuration for the audit log.
+	Audit AuditConfig `envPrefix:"AUDIT_" yaml:"audit"`
+
+	// Hooks is the configuration for custom git hooks.
+	Hooks HooksConfig `envPrefix:"HOOKS_" yaml:"hooks"`
+
+	// UI is the configuration of the TUI.
+	UI UIConfig `envPrefix:"UI_" yaml:"ui"`
+
+	// DrainTimeout is the maximum number of seconds the server waits for
+	// running git operations to finish when it shuts down, before closing
+	// the remaining connections.
+	DrainTimeout int `env:"DRAIN_TIMEOUT" yaml:"drain_timeout"`
+
 	// InitialAdminKeys is a list of public keys that will be added to the list of admins.
 	InitialAdminKeys []string `env:"INITIAL_ADMIN_KEYS" envSeparator:"\n" yaml:"initial_admin_keys"`
 
@@ -189,6 +794,7 @@ func (c *Config) Environ() []string {
 		fmt.Sprintf("SOFT_SERVE_CONFIG_LOCATION=%s", c.ConfigPath()),
 		fmt.Sprintf("SOFT_SERVE_DATA_PATH=%s", c.DataPath),
 		fmt.Sprintf("SOFT_SERVE_NAME=%s", c.Name),
+		fmt.Sprintf("SOFT_SERVE_DRAIN_TIMEOUT=%d", c.DrainTimeout),
 		fmt.Sprintf("SOFT_SERVE_INITIAL_ADMIN_KEYS=%s", strings.Join(c.InitialAdminKeys, "\n")),
 		fmt.Sprintf("SOFT_SERVE_SSH_ENABLED=%t", c.SSH.Enabled),
 		fmt.Sprintf("SOFT_SERVE_SSH_LISTEN_ADDR=%s", c.SSH.ListenAddr),
@@ -197,12 +803,32 @@ func (c *Config) Environ() []string {
 		fmt.Sprintf("SOFT_SERVE_SSH_CLIENT_KEY_PATH=%s", c.SSH.ClientKeyPath),
 		fmt.Sprintf("SOFT_SERVE_SSH_MAX_TIMEOUT=%d", c.SSH.MaxTimeout),
 		fmt.Sprintf("SOFT_SERVE_SSH_IDLE_TIMEOUT=%d", c.SSH.IdleTimeout),
+		fmt.Sprintf("SOFT_SERVE_SSH_TRUSTED_USER_CA_KEYS=%s", c.SSH.TrustedUserCAKeys),
+		fmt.Sprintf("SOFT_SERVE_SSH_REVOKED_KEYS=%s", c.SSH.RevokedKeys),
+		fmt.Sprintf("SOFT_SERVE_SSH_RATE_LIMIT_CONNECTION_RATE=%g", c.SSH.RateLimit.ConnectionRate),
+		fmt.Sprintf("SOFT_SERVE_SSH_RATE_LIMIT_CONNECTION_BURST=%d", c.SSH.RateLimit.ConnectionBurst),
+		fmt.Sprintf("SOFT_SERVE_SSH_RATE_LIMIT_AUTH_RATE=%g", c.SSH.RateLimit.AuthRate),
+		fmt.Sprintf("SOFT_SERVE_SSH_RATE_LIMIT_AUTH_BURST=%d", c.SSH.RateLimit.AuthBurst),
+		fmt.Sprintf("SOFT_SERVE_SSH_CIPHERS=%s", strings.Join(c.SSH.Ciphers, ",")),
+		fmt.Sprintf("SOFT_SERVE_SSH_KEY_EXCHANGES=%s", strings.Join(c.SSH.KeyExchanges, ",")),
+		fmt.Sprintf("SOFT_SERVE_SSH_MACS=%s", strings.Join(c.SSH.MACs, ",")),
+		fmt.Sprintf("SOFT_SERVE_SSH_KEYBOARD_INTERACTIVE_ENABLED=%t", c.SSH.KeyboardInteractive.Enabled),
+		fmt.Sprintf("SOFT_SERVE_SSH_KEYBOARD_INTERACTIVE_COMMAND=%s", c.SSH.KeyboardInteractive.Command),
+		fmt.Sprintf("SOFT_SERVE_SSH_KEYBOARD_INTERACTIVE_PROMPT=%s", c.SSH.KeyboardInteractive.Prompt),
+		fmt.Sprintf("SOFT_SERVE_SSH_KEYBOARD_INTERACTIVE_CREATE_USERS=%t", c.SSH.KeyboardInteractive.CreateUsers),
+		fmt.Sprintf("SOFT_SERVE_SSH_BANNER_TEXT=%s", c.SSH.Banner.Text),
+		fmt.Sprintf("SOFT_SERVE_SSH_BANNER_PATH=%s", c.SSH.Banner.Path),
+		fmt.Sprintf("SOFT_SERVE_SSH_MOTD_TEXT=%s", c.SSH.MOTD.Text),
+		fmt.Sprintf("SOFT_SERVE_SSH_MOTD_PATH=%s", c.SSH.MOTD.Path),
 		fmt.Sprintf("SOFT_SERVE_GIT_ENABLED=%t", c.Git.Enabled),
 		fmt.Sprintf("SOFT_SERVE_GIT_LISTEN_ADDR=%s", c.Git.ListenAddr),
 		fmt.Sprintf("SOFT_SERVE_GIT_PUBLIC_URL=%s", c.Git.PublicURL),
 		fmt.Sprintf("SOFT_SERVE_GIT_MAX_TIMEOUT=%d", c.Git.MaxTimeout),
 		fmt.Sprintf("SOFT_SERVE_GIT_IDLE_TIMEOUT=%d", c.Git.IdleTimeout),
 		fmt.Sprintf("SOFT_SERVE_GIT_MAX_CONNECTIONS=%d", c.Git.MaxConnections),
+		fmt.Sprintf("SOFT_SERVE_GIT_BINARY_PATH=%s", c.Git.BinaryPath),
+		fmt.Sprintf("SOFT_SERVE_GIT_RATE_LIMIT_CONNECTION_RATE=%g", c.Git.RateLimit.ConnectionRate),
+		fmt.Sprintf("SOFT_SERVE_GIT_RATE_LIMIT_CONNECTION_BURST=%d", c.Git.RateLimit.ConnectionBurst),
 		fmt.Sprintf("SOFT_SERVE_HTTP_ENABLED=%t", c.HTTP.Enabled),
 		fmt.Sprintf("SOFT_SERVE_HTTP_LISTEN_ADDR=%s", c.HTTP.ListenAddr),
 		fmt.Sprintf("SOFT_SERVE_HTTP_TLS_KEY_PATH=%s", c.HTTP.TLSKeyPath),
@@ -211,15 +837,90 @@ func (c *Config) Environ() []string {
 		fmt.Sprintf("SOFT_SERVE_HTTP_CORS_ALLOWED_HEADERS=%s", strings.Join(c.HTTP.CORS.AllowedHeaders, ",")),
 		fmt.Sprintf("SOFT_SERVE_HTTP_CORS_ALLOWED_ORIGINS=%s", strings.Join(c.HTTP.CORS.AllowedOrigins, ",")),
 		fmt.Sprintf("SOFT_SERVE_HTTP_CORS_ALLOWED_METHODS=%s", strings.Join(c.HTTP.CORS.AllowedMethods, ",")),
+		fmt.Sprintf("SOFT_SERVE_HTTP_ALLOW_DUMB_HTTP=%t", c.HTTP.AllowDumbHTTP),
+		fmt.Sprintf("SOFT_SERVE_HTTP_OIDC_ISSUER=%s", c.HTTP.OIDC.Issuer),
+		fmt.Sprintf("SOFT_SERVE_HTTP_OIDC_CLIENT_ID=%s", c.HTTP.OIDC.ClientID),
+		fmt.Sprintf("SOFT_SERVE_HTTP_OIDC_CLIENT_SECRET=%s", c.HTTP.OIDC.ClientSecret),
+		fmt.Sprintf("SOFT_SERVE_HTTP_OIDC_SCOPES=%s", strings.Join(c.HTTP.OIDC.Scopes, ",")),
+		fmt.Sprintf("SOFT_SERVE_HTTP_OIDC_USERNAME_CLAIM=%s", c.HTTP.OIDC.UsernameClaim),
+		fmt.Sprintf("SOFT_SERVE_HTTP_OIDC_CREATE_USERS=%t", c.HTTP.OIDC.CreateUsers),
+		fmt.Sprintf("SOFT_SERVE_HTTP_OIDC_SESSION_LIFETIME=%d", c.HTTP.OIDC.SessionLifetime),
+		fmt.Sprintf("SOFT_SERVE_HTTP_TRUSTED_PROXIES=%s", strings.Join(c.HTTP.TrustedProxies, ",")),
 		fmt.Sprintf("SOFT_SERVE_STATS_ENABLED=%t", c.Stats.Enabled),
 		fmt.Sprintf("SOFT_SERVE_STATS_LISTEN_ADDR=%s", c.Stats.ListenAddr),
 		fmt.Sprintf("SOFT_SERVE_LOG_FORMAT=%s", c.Log.Format),
 		fmt.Sprintf("SOFT_SERVE_LOG_TIME_FORMAT=%s", c.Log.TimeFormat),
+		fmt.Sprintf("SOFT_SERVE_LOG_LEVEL=%s", c.Log.Level),
+		fmt.Sprintf("SOFT_SERVE_LOG_LEVELS=%s", c.Log.levelsEnv()),
 		fmt.Sprintf("SOFT_SERVE_DB_DRIVER=%s", c.DB.Driver),
 		fmt.Sprintf("SOFT_SERVE_DB_DATA_SOURCE=%s", c.DB.DataSource),
+		fmt.Sprintf("SOFT_SERVE_DB_MAX_OPEN_CONNS=%d", c.DB.MaxOpenConns),
+		fmt.Sprintf("SOFT_SERVE_DB_MAX_IDLE_CONNS=%d", c.DB.MaxIdleConns),
+		fmt.Sprintf("SOFT_SERVE_DB_CONN_MAX_LIFETIME=%d", c.DB.ConnMaxLifetime),
 		fmt.Sprintf("SOFT_SERVE_LFS_ENABLED=%t", c.LFS.Enabled),
 		fmt.Sprintf("SOFT_SERVE_LFS_SSH_ENABLED=%t", c.LFS.SSHEnabled),
+		// S3 credentials are deliberately left out of the environment.
+		fmt.Sprintf("SOFT_SERVE_LFS_STORAGE=%s", c.LFS.Storage),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_ENDPOINT=%s", c.LFS.S3.Endpoint),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_BUCKET=%s", c.LFS.S3.Bucket),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_REGION=%s", c.LFS.S3.Region),
+		fmt.Sprintf("SOFT_SERVE_LFS_S3_USE_PATH_STYLE=%t", c.LFS.S3.UsePathStyle),
+		fmt.Sprintf("SOFT_SERVE_REPO_MAX_SIZE=%d", c.Repo.MaxSize),
+		fmt.Sprintf("SOFT_SERVE_REPO_MAX_USER_SIZE=%d", c.Repo.MaxUserSize),
+		fmt.Sprintf("SOFT_SERVE_REPO_MAX_PACK_BYTES=%d", c.Repo.MaxPackBytes),
+		fmt.Sprintf("SOFT_SERVE_REPO_DEFAULT_BRANCH=%s", c.Repo.DefaultBranch),
+		fmt.Sprintf("SOFT_SERVE_REPO_ALLOW_PUSH_CREATE=%t", c.Repo.AllowPushCreate),
+		fmt.Sprintf("SOFT_SERVE_REPO_DENY_NON_FAST_FORWARDS=%t", c.Repo.DenyNonFastForwards),
+		fmt.Sprintf("SOFT_SERVE_REPO_DISABLE_FILTERS=%t", c.Repo.DisableFilters),
+		fmt.Sprintf("SOFT_SERVE_REPO_ALLOWED_FILTERS=%s", strings.Join(c.Repo.AllowedFilters, ",")),
+		fmt.Sprintf("SOFT_SERVE_REPO_HIDE_ARCHIVED=%t", c.Repo.HideArchived),
+		fmt.Sprintf("SOFT_SERVE_REPO_LANGUAGES=%t", c.Repo.Languages),
+		fmt.Sprintf("SOFT_SERVE_REPO_MAX_CONCURRENT_READS=%d", c.Repo.MaxConcurrentReads),
+		fmt.Sprintf("SOFT_SERVE_REPO_MAX_CONCURRENT_WRITES=%d", c.Repo.MaxConcurrentWrites),
+		fmt.Sprintf("SOFT_SERVE_REPO_MAX_CONCURRENT_OPERATIONS=%d", c.Repo.MaxConcurrentOperations),
+		fmt.Sprintf("SOFT_SERVE_REPO_QUEUE_TIMEOUT=%d", c.Repo.QueueTimeout),
 		fmt.Sprintf("SOFT_SERVE_JOBS_MIRROR_PULL=%s", c.Jobs.MirrorPull),
+		fmt.Sprintf("SOFT_SERVE_JOBS_MIRROR_TIMEOUT=%d", c.Jobs.MirrorTimeout),
+		fmt.Sprintf("SOFT_SERVE_JOBS_LFS_VERIFY=%s", c.Jobs.LFSVerify),
+		fmt.Sprintf("SOFT_SERVE_JOBS_REPO_STATS=%s", c.Jobs.RepoStats),
+		fmt.Sprintf("SOFT_SERVE_JOBS_REPO_GC=%s", c.Jobs.RepoGC),
+		fmt.Sprintf("SOFT_SERVE_JOBS_REPO_FSCK=%s", c.Jobs.RepoFsck),
+		fmt.Sprintf("SOFT_SERVE_JOBS_REPO_GC_LOOSE_OBJECTS=%d", c.Jobs.RepoGCLooseObjects),
+		fmt.Sprintf("SOFT_SERVE_JOBS_REPO_GC_INTERVAL=%d", c.Jobs.RepoGCInterval),
+		fmt.Sprintf("SOFT_SERVE_JOBS_MAINTENANCE_WINDOW=%s", c.Jobs.MaintenanceWindow),
+		fmt.Sprintf("SOFT_SERVE_JOBS_MAINTENANCE_JOBS=%s", strings.Join(c.Jobs.MaintenanceJobs, ",")),
+		fmt.Sprintf("SOFT_SERVE_LDAP_URL=%s", c.LDAP.URL),
+		fmt.Sprintf("SOFT_SERVE_LDAP_START_TLS=%t", c.LDAP.StartTLS),
+		fmt.Sprintf("SOFT_SERVE_LDAP_INSECURE_SKIP_VERIFY=%t", c.LDAP.InsecureSkipVerify),
+		fmt.Sprintf("SOFT_SERVE_LDAP_BIND_DN=%s", c.LDAP.BindDN),
+		fmt.Sprintf("SOFT_SERVE_LDAP_BIND_PASSWORD=%s", c.LDAP.BindPassword),
+		fmt.Sprintf("SOFT_SERVE_LDAP_BASE_DN=%s", c.LDAP.BaseDN),
+		fmt.Sprintf("SOFT_SERVE_LDAP_USER_FILTER=%s", c.LDAP.UserFilter),
+		fmt.Sprintf("SOFT_SERVE_LDAP_GROUP_ATTRIBUTE=%s", c.LDAP.GroupAttribute),
+		fmt.Sprintf("SOFT_SERVE_LDAP_GROUP_ACCESS=%s", strings.Join(c.LDAP.GroupAccess, ";")),
+		fmt.Sprintf("SOFT_SERVE_LDAP_CREATE_USERS=%t", c.LDAP.CreateUsers),
+		fmt.Sprintf("SOFT_SERVE_LDAP_HTTP=%t", c.LDAP.HTTP),
+		fmt.Sprintf("SOFT_SERVE_LDAP_CACHE_TTL=%d", c.LDAP.CacheTTL),
+		fmt.Sprintf("SOFT_SERVE_WEBHOOK_MAX_ATTEMPTS=%d", c.Webhook.MaxAttempts),
+		fmt.Sprintf("SOFT_SERVE_WEBHOOK_BASE_DELAY=%d", c.Webhook.BaseDelay),
+		fmt.Sprintf("SOFT_SERVE_WEBHOOK_WORKERS=%d", c.Webhook.Workers),
+		fmt.Sprintf("SOFT_SERVE_AUDIT_SINK=%s", c.Audit.Sink),
+		fmt.Sprintf("SOFT_SERVE_AUDIT_PATH=%s", c.Audit.Path),
+		fmt.Sprintf("SOFT_SERVE_HOOKS_TIMEOUT=%d", c.Hooks.Timeout),
+		fmt.Sprintf("SOFT_SERVE_HOOKS_ENV=%s", strings.Join(c.Hooks.Env, ",")),
+		fmt.Sprintf("SOFT_SERVE_HOOKS_SECRET_KEY_PATH=%s", c.Hooks.SecretKeyPath),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_NAME=%s", c.UI.Theme.Name),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_ACCENT=%s", c.UI.Theme.Accent),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_ACCENT_TEXT=%s", c.UI.Theme.AccentText),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_SECONDARY=%s", c.UI.Theme.Secondary),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_HIGHLIGHT=%s", c.UI.Theme.Highlight),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_SELECTION=%s", c.UI.Theme.Selection),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_BACKGROUND=%s", c.UI.Theme.Background),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_TEXT=%s", c.UI.Theme.Text),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_MUTED=%s", c.UI.Theme.Muted),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_SUBTLE=%s", c.UI.Theme.Subtle),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_ADDED=%s", c.UI.Theme.Added),
+		fmt.Sprintf("SOFT_SERVE_UI_THEME_DELETED=%s", c.UI.Theme.Deleted),
 	}...)
 
 	return envs
@@ -347,8 +1048,9 @@ func (c *Config) Exist() bool {
 // Use Validate() to validate the config and ensure absolute paths.
 func DefaultConfig() *Config {
 	return &Config{
-		Name:     "Soft Serve",
-		DataPath: DefaultDataPath(),
+		Name:         "Soft Serve",
+		DataPath:     DefaultDataPath(),
+		DrainTimeout: 30,
 		SSH: SSHConfig{
 			Enabled:       true,
 			ListenAddr:    ":23231",
@@ -357,14 +1059,24 @@ func DefaultConfig() *Config {
 			ClientKeyPath: filepath.Join("ssh", "soft_serve_client_ed25519"),
 			MaxTimeout:    0,
 			IdleTimeout:   10 * 60, // 10 minutes
+			RateLimit: SSHRateLimitConfig{
+				ConnectionBurst: 10,
+				AuthBurst:       20,
+			},
+			KeyboardInteractive: SSHKeyboardInteractiveConfig{
+				Prompt: "Code: ",
+			},
 		},
 		Git: GitConfig{
-			Enabled:        true,
+			Enabled:        false,
 			ListenAddr:     ":9418",
 			PublicURL:      "git://localhost",
 			MaxTimeout:     0,
 			IdleTimeout:    3,
 			MaxConnections: 32,
+			RateLimit: GitRateLimitConfig{
+				ConnectionBurst: 10,
+			},
 		},
 		HTTP: HTTPConfig{
 			Enabled:    true,
@@ -375,6 +1087,12 @@ func DefaultConfig() *Config {
 				AllowedMethods: []string{"GET", "HEAD", "POST", "PUT", "OPTIONS"},
 				AllowedOrigins: []string{"http://localhost:23232"},
 			},
+			AllowDumbHTTP: false,
+			OIDC: OIDCConfig{
+				Scopes:          []string{"email", "profile"},
+				UsernameClaim:   "email",
+				SessionLifetime: 24 * 60 * 60,
+			},
 		},
 		Stats: StatsConfig{
 			Enabled:    true,
@@ -383,18 +1101,55 @@ func DefaultConfig() *Config {
 		Log: LogConfig{
 			Format:     "text",
 			TimeFormat: time.DateTime,
+			Level:      "info",
 		},
 		DB: DBConfig{
 			Driver: "sqlite",
 			DataSource: "soft-serve.db" +
 				"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * 60,
 		},
 		LFS: LFSConfig{
 			Enabled:    true,
 			SSHEnabled: false,
+			Storage:    "local",
+		},
+		Repo: RepoConfig{
+			AllowPushCreate: true,
+			QueueTimeout:    10,
 		},
 		Jobs: JobsConfig{
-			MirrorPull: "@every 10m",
+			MirrorPull:         "@every 10m",
+			MirrorTimeout:      60,
+			RepoStats:          "@every 1h",
+			RepoGC:             "@daily",
+			RepoGCLooseObjects: 1000,
+			RepoGCInterval:     168,
+			MaintenanceJobs:    []string{"mirror-pull", "lfs-verify", "repo-gc", "repo-fsck"},
+		},
+		LDAP: LDAPConfig{
+			UserFilter:     "(&(objectClass=person)(uid=%s))",
+			GroupAttribute: "memberOf",
+			CacheTTL:       60,
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts: 5,
+			BaseDelay:   10,
+			Workers:     4,
+		},
+		Audit: AuditConfig{
+			Path: filepath.Join("log", "audit.log"),
+		},
+		Hooks: HooksConfig{
+			Timeout:       60,
+			SecretKeyPath: filepath.Join("hooks", "secret.key"),
+		},
+		UI: UIConfig{
+			Theme: ThemeConfig{
+				Name: "dark",
+			},
 		},
 	}
 }
@@ -422,6 +1177,24 @@ func (c *Config) Validate() error {
 		c.SSH.ClientKeyPath = filepath.Join(c.DataPath, c.SSH.ClientKeyPath)
 	}
 
+	if c.SSH.TrustedUserCAKeys != "" && !filepath.IsAbs(c.SSH.TrustedUserCAKeys) {
+		c.SSH.TrustedUserCAKeys = filepath.Join(c.DataPath, c.SSH.TrustedUserCAKeys)
+	}
+
+	if c.SSH.RevokedKeys != "" && !filepath.IsAbs(c.SSH.RevokedKeys) {
+		c.SSH.RevokedKeys = filepath.Join(c.DataPath, c.SSH.RevokedKeys)
+	}
+
+	for _, m := range []*SSHMessageConfig{&c.SSH.Banner, &c.SSH.MOTD} {
+		if m.Path != "" && !filepath.IsAbs(m.Path) {
+			m.Path = filepath.Join(c.DataPath, m.Path)
+		}
+	}
+
+	if c.Hooks.SecretKeyPath != "" && !filepath.IsAbs(c.Hooks.SecretKeyPath) {
+		c.Hooks.SecretKeyPath = filepath.Join(c.DataPath, c.Hooks.SecretKeyPath)
+	}
+
 	if c.HTTP.TLSKeyPath != "" && !filepath.IsAbs(c.HTTP.TLSKeyPath) {
 		c.HTTP.TLSKeyPath = filepath.Join(c.DataPath, c.HTTP.TLSKeyPath)
 	}
@@ -434,6 +1207,122 @@ func (c *Config) Validate() error {
 		c.DB.DataSource = filepath.Join(c.DataPath, c.DB.DataSource)
 	}
 
+	switch c.DB.Driver {
+	case "", "sqlite", "postgres", "mysql":
+	default:
+		return fmt.Errorf("invalid database driver %q, valid drivers are sqlite, postgres, and mysql", c.DB.Driver)
+	}
+
+	if c.DB.MaxOpenConns < 0 || c.DB.MaxIdleConns < 0 || c.DB.ConnMaxLifetime < 0 {
+		return errors.New("database connection pool settings can't be negative")
+	}
+
+	if c.Audit.Path != "" && !filepath.IsAbs(c.Audit.Path) {
+		c.Audit.Path = filepath.Join(c.DataPath, c.Audit.Path)
+	}
+
+	switch c.Audit.Sink {
+	case "", "database":
+	case "file":
+		if c.Audit.Path == "" {
+			return errors.New("audit file sink requires a path")
+		}
+	default:
+		return fmt.Errorf("invalid audit sink %q", c.Audit.Sink)
+	}
+
+	switch c.LFS.Storage {
+	case "", "local":
+	case "s3":
+		if c.LFS.S3.Endpoint == "" || c.LFS.S3.Bucket == "" {
+			return errors.New("lfs s3 storage requires an endpoint and a bucket")
+		}
+	default:
+		return fmt.Errorf("invalid lfs storage %q", c.LFS.Storage)
+	}
+
+	if c.SSH.RateLimit.ConnectionRate < 0 || c.SSH.RateLimit.AuthRate < 0 {
+		return errors.New("ssh rate limits can't be negative")
+	}
+
+	if c.Git.RateLimit.ConnectionRate < 0 {
+		return errors.New("git rate limits can't be negative")
+	}
+
+	if err := validateSSHAlgorithms(c.SSH); err != nil {
+		return err
+	}
+
+	if err := validateSSHMessages(c.SSH); err != nil {
+		return err
+	}
+
+	if _, err := c.UI.Theme.Theme(); err != nil {
+		return fmt.Errorf("invalid ui theme: %w", err)
+	}
+
+	if c.Webhook.MaxAttempts < 0 || c.Webhook.BaseDelay < 0 || c.Webhook.Workers < 0 {
+		return errors.New("webhook settings can't be negative")
+	}
+
+	if c.Jobs.RepoGCLooseObjects < 0 || c.Jobs.RepoGCInterval < 0 {
+		return errors.New("repo gc settings can't be negative")
+	}
+
+	if err := c.Log.validate(); err != nil {
+		return err
+	}
+
+	if c.Hooks.Timeout < 0 {
+		return errors.New("hooks timeout can't be negative")
+	}
+
+	if c.DrainTimeout < 0 {
+		return errors.New("drain timeout can't be negative")
+	}
+
+	if _, err := c.Jobs.Window(); err != nil {
+		return err
+	}
+
+	if c.Repo.DefaultBranch != "" {
+		if err := utils.ValidateBranch(c.Repo.DefaultBranch); err != nil {
+			return fmt.Errorf("invalid repo default branch: %w", err)
+		}
+	}
+
+	if c.Repo.MaxUserSize < 0 {
+		return errors.New("repo max user size can't be negative")
+	}
+
+	if c.Repo.MaxConcurrentReads < 0 || c.Repo.MaxConcurrentWrites < 0 ||
+		c.Repo.MaxConcurrentOperations < 0 || c.Repo.QueueTimeout < 0 {
+		return errors.New("repo concurrency limits can't be negative")
+	}
+
+	if c.LDAP.URL != "" {
+		if !strings.Contains(c.LDAP.UserFilter, "%s") {
+			return errors.New("ldap user filter must contain %s")
+		}
+		if _, err := c.LDAP.GroupAccessLevels(); err != nil {
+			return err
+		}
+	}
+
+	c.HTTP.OIDC.Issuer = strings.TrimSuffix(c.HTTP.OIDC.Issuer, "/")
+	if c.HTTP.OIDC.Issuer != "" {
+		if c.HTTP.OIDC.ClientID == "" {
+			return errors.New("oidc login requires a client id")
+		}
+		if c.HTTP.OIDC.SessionLifetime <= 0 {
+			return errors.New("oidc session lifetime must be positive")
+		}
+	}
+
+	if _, err := c.HTTP.TrustedProxyRanges(); err != nil {
+		return err
+	}
+
 	// Validate keys
 	pks := make([]string, 0)
 	for _, key := range parseAuthKeys(c.InitialAdminKeys) {
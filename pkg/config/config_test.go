@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/matryer/is"
 )
@@ -124,3 +126,107 @@ func TestParseMultipleMethods(t *testing.T) {
 		"PUT",
 	})
 }
+
+func TestParseLogLevels(t *testing.T) {
+	is := is.New(t)
+	is.NoErr(os.Setenv("SOFT_SERVE_LOG_LEVELS", "git=debug,http=warn"))
+	t.Cleanup(func() {
+		is.NoErr(os.Unsetenv("SOFT_SERVE_LOG_LEVELS"))
+	})
+	cfg := DefaultConfig()
+	is.NoErr(cfg.ParseEnv())
+	is.Equal(cfg.Log.Levels, map[string]string{"git": "debug", "http": "warn"})
+	is.True(slices.Contains(cfg.Environ(), "SOFT_SERVE_LOG_LEVELS=git=debug,http=warn"))
+
+	cfg.Log.Levels["web"] = "debug"
+	is.True(cfg.Validate() != nil)
+	delete(cfg.Log.Levels, "web")
+	cfg.Log.Levels["git"] = "loud"
+	is.True(cfg.Validate() != nil)
+	cfg.Log.Levels["git"] = "debug"
+	cfg.Log.Level = "loud"
+	is.True(cfg.Validate() != nil)
+}
+
+func TestValidateDB(t *testing.T) {
+	cases := []struct {
+		name string
+		db   DBConfig
+		ok   bool
+	}{
+		{"sqlite", DBConfig{Driver: "sqlite"}, true},
+		{"postgres", DBConfig{Driver: "postgres", MaxOpenConns: 10, MaxIdleConns: 5, ConnMaxLifetime: 60}, true},
+		{"mysql", DBConfig{Driver: "mysql"}, true},
+		{"unknown driver", DBConfig{Driver: "oracle"}, false},
+		{"negative pool size", DBConfig{Driver: "postgres", MaxOpenConns: -1}, false},
+		{"negative lifetime", DBConfig{Driver: "postgres", ConnMaxLifetime: -1}, false},
+	}
+
+	for _, c := range cases {
+		cfg := &Config{DataPath: t.TempDir(), DB: c.db}
+		if err := cfg.Validate(); (err == nil) != c.ok {
+			t.Errorf("%s: Validate() = %v, want ok %v", c.name, err, c.ok)
+		}
+	}
+}
+
+func TestValidateRepoDefaultBranch(t *testing.T) {
+	cases := map[string]bool{
+		"":             true,
+		"main":         true,
+		"release/v1":   true,
+		"with space":   false,
+		"a..b":         false,
+		"refs/heads/.": false,
+	}
+
+	for branch, ok := range cases {
+		cfg := &Config{DataPath: t.TempDir(), DB: DBConfig{Driver: "sqlite"}, Repo: RepoConfig{DefaultBranch: branch}}
+		if err := cfg.Validate(); (err == nil) != ok {
+			t.Errorf("%q: Validate() = %v, want ok %v", branch, err, ok)
+		}
+	}
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+	at := func(h, m int) time.Time {
+		return time.Date(2024, 3, 10, h, m, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		window   string
+		t        time.Time
+		contains bool
+		next     time.Time
+	}{
+		{"01:00-05:00", at(3, 0), true, at(25, 0)},
+		{"01:00-05:00", at(0, 59), false, at(1, 0)},
+		{"01:00-05:00", at(5, 0), false, at(25, 0)},
+		{"22:00-06:00", at(23, 30), true, at(46, 0)},
+		{"22:00-06:00", at(2, 0), true, at(22, 0)},
+		{"22:00-06:00", at(12, 0), false, at(22, 0)},
+	}
+
+	for _, c := range cases {
+		w, err := JobsConfig{MaintenanceWindow: c.window}.Window()
+		if err != nil {
+			t.Fatalf("%s: %v", c.window, err)
+		}
+		if got := w.Contains(c.t); got != c.contains {
+			t.Errorf("%s: Contains(%s) = %v, want %v", c.window, c.t, got, c.contains)
+		}
+		if got := w.Next(c.t); !got.Equal(c.next) {
+			t.Errorf("%s: Next(%s) = %s, want %s", c.window, c.t, got, c.next)
+		}
+	}
+
+	for _, s := range []string{"01:00", "1am-5am", "25:00-05:00", "03:00-03:00"} {
+		if _, err := (JobsConfig{MaintenanceWindow: s}).Window(); err == nil {
+			t.Errorf("%s: Window() = nil error, want an error", s)
+		}
+	}
+
+	if w, err := (JobsConfig{}).Window(); w != nil || err != nil {
+		t.Errorf("Window() = %v, %v, want no window", w, err)
+	}
+}
@@ -11,6 +11,10 @@ var configFileTmpl = template.Must(template.New("config").Parse(`# Soft Serve Se
 # This is the name that will be displayed in the UI.
 name: "{{ .Name }}"
 
+# The number of seconds running git operations have to finish when the server
+# shuts down, before the remaining connections are closed.
+drain_timeout: {{ .DrainTimeout }}
+
 # Logging configuration.
 log:
   # Log format to use. Valid values are "json", "logfmt", and "text".
@@ -20,6 +24,12 @@ log:
   time_format: "{{ .Log.TimeFormat }}"
   # Path to the log file. Leave empty to write to stderr.
   #path: "{{ .Log.Path }}"
+  # Log level to use. Valid values are "debug", "info", "warn", "error", and
+  # "fatal".
+  level: "{{ .Log.Level }}"
+  # The log levels of subsystems, which log at the level above otherwise.
+  # Subsystems are "db", "git", "http", "lfs", "ssh", and "webhook".
+  levels: { {{- range $k, $v := .Log.Levels }} {{ $k }}: "{{ $v }}",{{ end }} }
 
 # The SSH server configuration.
 ssh:
@@ -44,13 +54,66 @@ ssh:
   # A value of 0 means no timeout.
   max_timeout: {{ .SSH.MaxTimeout }}
 
-  # The number of seconds a connection can be idle before it is closed.
+  # The number of seconds a session can be idle before it is closed. Interactive
+  # sessions are idle without input, git commands without data transfers.
   # A value of 0 means no timeout.
   idle_timeout: {{ .SSH.IdleTimeout }}
 
+  # The path to a file of CA public keys trusted to sign user certificates.
+  # The certificate principals are mapped to usernames.
+  trusted_user_ca_keys: "{{ .SSH.TrustedUserCAKeys }}"
+
+  # The path to an OpenSSH key revocation list, or a file of public keys, that
+  # are refused.
+  revoked_keys: "{{ .SSH.RevokedKeys }}"
+
+  # Per source IP rate limits. Connections over the limit are dropped before
+  # the key exchange. A rate of 0 disables the limit.
+  rate_limit:
+    # The number of new connections per second.
+    connection_rate: {{ .SSH.RateLimit.ConnectionRate }}
+    # The number of connections that can be opened at once.
+    connection_burst: {{ .SSH.RateLimit.ConnectionBurst }}
+    # The number of authentication attempts per second.
+    auth_rate: {{ .SSH.RateLimit.AuthRate }}
+    # The number of authentication attempts that can be made at once.
+    auth_burst: {{ .SSH.RateLimit.AuthBurst }}
+
+  # The allowed ciphers, key exchange algorithms, and MACs, in order of
+  # preference. Leave empty to use the defaults.
+  ciphers: [{{ range $i, $a := .SSH.Ciphers }}{{ if $i }}, {{ end }}"{{ $a }}"{{ end }}]
+  key_exchanges: [{{ range $i, $a := .SSH.KeyExchanges }}{{ if $i }}, {{ end }}"{{ $a }}"{{ end }}]
+  macs: [{{ range $i, $a := .SSH.MACs }}{{ if $i }}, {{ end }}"{{ $a }}"{{ end }}]
+
+  # Keyboard interactive authentication prompts clients without an accepted
+  # public key for a code, e.g. a one-time password.
+  keyboard_interactive:
+    # Enable keyboard interactive authentication.
+    enabled: {{ .SSH.KeyboardInteractive.Enabled }}
+    # The command that verifies codes. It's run with the SSH username as its
+    # argument and the code on stdin, and must exit with 0 if the code is
+    # valid. It may print the name of the user to log in as.
+    command: "{{ .SSH.KeyboardInteractive.Command }}"
+    # The prompt shown to clients.
+    prompt: "{{ .SSH.KeyboardInteractive.Prompt }}"
+    # Create users on their first login.
+    create_users: {{ .SSH.KeyboardInteractive.CreateUsers }}
+
+  # The banner sent to clients before they authenticate, e.g. a legal notice,
+  # and the message of the day shown on the TUI welcome screen. Both are either
+  # text or the path of a file, and are templates with the {{"{{"}} .ServerName {{"}}"}}
+  # and {{"{{"}} .Username {{"}}"}} variables.
+  banner:
+    text: {{ printf "%q" .SSH.Banner.Text }}
+    path: "{{ .SSH.Banner.Path }}"
+  motd:
+    text: {{ printf "%q" .SSH.MOTD.Text }}
+    path: "{{ .SSH.MOTD.Path }}"
+
 # The Git daemon configuration.
 git:
-  # Enable the Git daemon.
+  # Enable the Git daemon. It serves anonymous read-only clones of exported
+  # repositories over git://.
   enabled: {{ .Git.Enabled }}
 
   # The address on which the Git daemon will listen.
@@ -70,6 +133,18 @@ git:
   # The maximum number of concurrent connections.
   max_connections: {{ .Git.MaxConnections }}
 
+  # The path of the git executable used to serve git services.
+  # Leave empty to use "git" from PATH.
+  binary_path: "{{ .Git.BinaryPath }}"
+
+  # Per source IP rate limits. Connections over the limit are closed before
+  # the request is read. A rate of 0 disables the limit.
+  rate_limit:
+    # The number of new connections per second.
+    connection_rate: {{ .Git.RateLimit.ConnectionRate }}
+    # The number of connections that can be opened at once.
+    connection_burst: {{ .Git.RateLimit.ConnectionBurst }}
+
 # The HTTP server configuration.
 http:
   # Enable the HTTP server.
@@ -115,6 +190,35 @@ http:
        - "PUT"
        - "OPTIONS"
 
+  # Serve repositories over the dumb HTTP protocol.
+  # Only repositories readable by anonymous users are served.
+  allow_dumb_http: {{ .HTTP.AllowDumbHTTP }}
+
+  # OpenID Connect login. Users are redirected to the provider from
+  # /auth/oidc/login and get a session cookie on their way back.
+  oidc:
+    # The URL of the OpenID provider. Leave empty to disable logins.
+    issuer: "{{ .HTTP.OIDC.Issuer }}"
+    # The client registered with the provider. Its redirect URL must be
+    # <public_url>/auth/oidc/callback.
+    client_id: "{{ .HTTP.OIDC.ClientID }}"
+    client_secret: "{{ .HTTP.OIDC.ClientSecret }}"
+    # The scopes requested in addition to "openid".
+    scopes: [{{ range $i, $s := .HTTP.OIDC.Scopes }}{{ if $i }}, {{ end }}"{{ $s }}"{{ end }}]
+    # The ID token claim mapped to usernames, e.g. "email", "sub", or
+    # "preferred_username". With "email", users are matched on their verified
+    # email address, and new users are named after "preferred_username".
+    username_claim: "{{ .HTTP.OIDC.UsernameClaim }}"
+    # Create users on their first login.
+    create_users: {{ .HTTP.OIDC.CreateUsers }}
+    # The number of seconds a login session lasts.
+    session_lifetime: {{ .HTTP.OIDC.SessionLifetime }}
+
+  # The addresses or CIDR ranges of the reverse proxies in front of the HTTP
+  # server. The client address of their requests is read from the Forwarded
+  # or X-Forwarded-For header.
+  trusted_proxies: [{{ range $i, $p := .HTTP.TrustedProxies }}{{ if $i }}, {{ end }}"{{ $p }}"{{ end }}]
+
 # The stats server configuration.
 stats:
   # Enable the stats server.
@@ -126,12 +230,19 @@ stats:
 # The database configuration.
 db:
   # The database driver to use.
-  # Valid values are "sqlite" and "postgres".
+  # Valid values are "sqlite", "postgres", and "mysql".
   driver: "{{ .DB.Driver }}"
   # The database data source name.
   # This is driver specific and can be a file path or connection string.
   # Make sure foreign key support is enabled when using SQLite.
   data_source: "{{ .DB.DataSource }}"
+  # The maximum number of open connections. A value of 0 means no limit.
+  max_open_conns: {{ .DB.MaxOpenConns }}
+  # The maximum number of idle connections kept in the pool.
+  max_idle_conns: {{ .DB.MaxIdleConns }}
+  # The maximum number of seconds a connection is reused.
+  # A value of 0 means connections are reused forever.
+  conn_max_lifetime: {{ .DB.ConnMaxLifetime }}
 
 # Git LFS configuration.
 lfs:
@@ -139,10 +250,191 @@ lfs:
   enabled: {{ .LFS.Enabled }}
   # Enable Git SSH transfer.
   ssh_enabled: {{ .LFS.SSHEnabled }}
+  # Where LFS objects are stored. Valid values are "local" and "s3".
+  storage: "{{ .LFS.Storage }}"
+  # S3 storage configuration. This is only used if storage is "s3".
+  s3:
+    # The URL of the S3 compatible server, e.g. "https://s3.amazonaws.com".
+    endpoint: "{{ .LFS.S3.Endpoint }}"
+    # The bucket LFS objects are stored in.
+    bucket: "{{ .LFS.S3.Bucket }}"
+    # The region of the bucket.
+    region: "{{ .LFS.S3.Region }}"
+    # The credentials used to authenticate to the server.
+    access_key_id: "{{ .LFS.S3.AccessKeyID }}"
+    secret_access_key: "{{ .LFS.S3.SecretAccessKey }}"
+    # Use path style requests. Required by most S3 compatible servers such as
+    # MinIO.
+    use_path_style: {{ .LFS.S3.UsePathStyle }}
+
+# Repository configuration.
+repo:
+  # The maximum size in bytes a repository can grow to through pushes.
+  # A value of 0 means no limit.
+  max_size: {{ .Repo.MaxSize }}
+
+  # The default maximum total size in bytes of the repositories a user owns.
+  # Admins can override it per user. A value of 0 means no limit.
+  max_user_size: {{ .Repo.MaxUserSize }}
+
+  # The maximum number of bytes a client can send in a single push.
+  # A value of 0 means no limit.
+  max_pack_bytes: {{ .Repo.MaxPackBytes }}
+
+  # The branch HEAD of new repositories points to. An empty value uses the
+  # default of git. Existing repositories keep theirs.
+  default_branch: "{{ .Repo.DefaultBranch }}"
+
+  # Create repositories that don't exist when users push to them. Admins can
+  # override it per user. Users still need the permission to create
+  # repositories.
+  allow_push_create: {{ .Repo.AllowPushCreate }}
+
+  # Reject non-fast-forward (force) pushes.
+  deny_non_fast_forwards: {{ .Repo.DenyNonFastForwards }}
+
+  # Disable partial clones. Repositories can override it with the
+  # "repo settings filters" command.
+  disable_filters: {{ .Repo.DisableFilters }}
+
+  # The partial clone filters clients are allowed to use, e.g. "blob:none" or
+  # "tree:1". Leave empty to allow all filters.
+  allowed_filters: [{{ range $i, $f := .Repo.AllowedFilters }}{{ if $i }}, {{ end }}"{{ $f }}"{{ end }}]
+
+  # Leave archived repositories out of repository listings.
+  hide_archived: {{ .Repo.HideArchived }}
+
+  # Compute the language breakdown of the default branch of repositories, and
+  # show it on their page. Vendored and binary files aren't counted.
+  languages: {{ .Repo.Languages }}
+
+  # The maximum number of fetches and clones, and of pushes, running at the
+  # same time against a single repository. A value of 0 means no limit.
+  max_concurrent_reads: {{ .Repo.MaxConcurrentReads }}
+  max_concurrent_writes: {{ .Repo.MaxConcurrentWrites }}
+
+  # The maximum number of git operations running at the same time against all
+  # repositories. A value of 0 means no limit.
+  max_concurrent_operations: {{ .Repo.MaxConcurrentOperations }}
+
+  # The number of seconds an operation over the limits waits for a slot
+  # before it's rejected with a "server busy" error.
+  queue_timeout: {{ .Repo.QueueTimeout }}
 
 # Cron job configuration
 jobs:
   mirror_pull: "{{ .Jobs.MirrorPull }}"
+  # The maximum number of seconds a single mirror update can take.
+  mirror_timeout: {{ .Jobs.MirrorTimeout }}
+  # How often to verify that stored LFS objects match their OID, e.g.
+  # "@daily". Leave empty to disable.
+  lfs_verify: "{{ .Jobs.LFSVerify }}"
+  # How often to refresh the repository stats and languages, stats are also
+  # refreshed after pushes. Leave empty to disable.
+  repo_stats: "{{ .Jobs.RepoStats }}"
+  # How often to garbage collect repositories. Leave empty to disable.
+  repo_gc: "{{ .Jobs.RepoGC }}"
+  # How often to check the integrity of repositories with git fsck, e.g.
+  # "@weekly". Leave empty to disable.
+  repo_fsck: "{{ .Jobs.RepoFsck }}"
+  # The number of loose objects that gets a repository collected.
+  repo_gc_loose_objects: {{ .Jobs.RepoGCLooseObjects }}
+  # The number of hours after which repositories with loose objects or
+  # several packs are collected.
+  repo_gc_interval: {{ .Jobs.RepoGCInterval }}
+  # The daily time range, in the server's local time, during which the
+  # maintenance jobs run, e.g. "01:00-05:00". Runs falling outside of it are
+  # deferred to its start. Leave empty to run the jobs on their schedule.
+  maintenance_window: "{{ .Jobs.MaintenanceWindow }}"
+  # The jobs restricted to the maintenance window.
+  maintenance_jobs: [{{ range $i, $j := .Jobs.MaintenanceJobs }}{{ if $i }}, {{ end }}"{{ $j }}"{{ end }}]
+
+# LDAP authentication. Users log in with their directory password on the web
+# at /auth/login, and optionally over HTTP git. Leave the URL empty to disable.
+ldap:
+  # The ldap:// or ldaps:// URL of the directory server.
+  url: "{{ .LDAP.URL }}"
+  # Upgrade ldap:// connections to TLS.
+  start_tls: {{ .LDAP.StartTLS }}
+  # Skip the verification of the server certificate.
+  insecure_skip_verify: {{ .LDAP.InsecureSkipVerify }}
+  # The service account searching for users. Leave empty to search anonymously.
+  bind_dn: "{{ .LDAP.BindDN }}"
+  bind_password: "{{ .LDAP.BindPassword }}"
+  # The base of user searches.
+  base_dn: "{{ .LDAP.BaseDN }}"
+  # The filter finding a user, %s is replaced by the username.
+  user_filter: "{{ .LDAP.UserFilter }}"
+  # The user attribute listing the groups of the user.
+  group_attribute: "{{ .LDAP.GroupAttribute }}"
+  # The access level members of a group have on all repositories, as
+  # "access-level:group DN" entries.
+  group_access: [{{ range $i, $g := .LDAP.GroupAccess }}{{ if $i }}, {{ end }}"{{ $g }}"{{ end }}]
+  #  - "read-write:cn=developers,ou=groups,dc=example,dc=com"
+  # Create users on their first login.
+  create_users: {{ .LDAP.CreateUsers }}
+  # Accept directory passwords for git over HTTP.
+  http: {{ .LDAP.HTTP }}
+  # The number of seconds successful logins are cached.
+  cache_ttl: {{ .LDAP.CacheTTL }}
+
+# Webhook deliveries. Failed deliveries are retried with an exponential
+# backoff.
+webhook:
+  # The number of times a delivery is attempted before it's marked as failed.
+  max_attempts: {{ .Webhook.MaxAttempts }}
+  # The number of seconds to wait before the first retry, doubled with each
+  # attempt.
+  base_delay: {{ .Webhook.BaseDelay }}
+  # The number of deliveries sent concurrently.
+  workers: {{ .Webhook.Workers }}
+
+# The audit log of access-control decisions.
+audit:
+  # Where the audit log is written.
+  # Valid values are "file" and "database", leave empty to disable it.
+  sink: "{{ .Audit.Sink }}"
+  # The file the audit log is written to when the sink is "file".
+  path: "{{ .Audit.Path }}"
+
+# Custom hooks run from "hooks" in the data path, and from "custom_hooks" in
+# repositories.
+hooks:
+  # The number of seconds a custom hook can run.
+  timeout: {{ .Hooks.Timeout }}
+  # The environment variables passed to custom hooks, in addition to the
+  # defaults.
+  env: [{{ range $i, $e := .Hooks.Env }}{{ if $i }}, {{ end }}"{{ $e }}"{{ end }}]
+  # The key encrypting the environment variables of repository hooks and the
+  # credentials of mirrors.
+  secret_key_path: "{{ .Hooks.SecretKeyPath }}"
+
+# The TUI configuration.
+ui:
+  # The color scheme of the TUI. The built-in themes are "dark", "light", and
+  # "high-contrast". Colors are ANSI color numbers from 0 to 255 or hex colors
+  # like "#ff5f87", and override the ones of the built-in theme.
+  theme:
+    name: "{{ .UI.Theme.Name }}"
+    # The tabs, active borders, and server name, and the text on them.
+    accent: "{{ .UI.Theme.Accent }}"
+    accent_text: "{{ .UI.Theme.AccentText }}"
+    # Topics, commands, tags, and directories.
+    secondary: "{{ .UI.Theme.Secondary }}"
+    # Commit hashes and labels.
+    highlight: "{{ .UI.Theme.Highlight }}"
+    # The selected item.
+    selection: "{{ .UI.Theme.Selection }}"
+    # The background of the status bar and labels.
+    background: "{{ .UI.Theme.Background }}"
+    text: "{{ .UI.Theme.Text }}"
+    # Descriptions, dates, and help.
+    muted: "{{ .UI.Theme.Muted }}"
+    # Separators and line numbers.
+    subtle: "{{ .UI.Theme.Subtle }}"
+    # Diff stats.
+    added: "{{ .UI.Theme.Added }}"
+    deleted: "{{ .UI.Theme.Deleted }}"
 
 # Additional admin keys.
 #initial_admin_keys:
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Reload returns a copy of c with the settings of n that can change while the
+// server runs: the SSH and Git daemon rate limits, the SSH banner and message
+// of the day, the webhook retries, and the log levels of subsystems. It also
+// returns the keys of the other settings that differ between c and n, which
+// need a restart to take effect.
+func (c *Config) Reload(n *Config) (*Config, []string) {
+	cfg := *c
+	cfg.SSH.RateLimit = n.SSH.RateLimit
+	cfg.SSH.Banner = n.SSH.Banner
+	cfg.SSH.MOTD = n.SSH.MOTD
+	cfg.Git.RateLimit = n.Git.RateLimit
+	cfg.Webhook.MaxAttempts = n.Webhook.MaxAttempts
+	cfg.Webhook.BaseDelay = n.Webhook.BaseDelay
+	cfg.Log.Levels = n.Log.Levels
+
+	return &cfg, changedKeys(reflect.ValueOf(cfg), reflect.ValueOf(*n), "")
+}
+
+// changedKeys returns the yaml keys of the fields that differ between the
+// structs a and b.
+func changedKeys(a, b reflect.Value, prefix string) []string {
+	var keys []string
+	for i := range a.NumField() {
+		f := a.Type().Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fa, fb := a.Field(i), b.Field(i)
+		if f.Type.Kind() == reflect.Struct {
+			keys = append(keys, changedKeys(fa, fb, prefix+name+".")...)
+			continue
+		}
+		if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			keys = append(keys, prefix+name)
+		}
+	}
+
+	return keys
+}
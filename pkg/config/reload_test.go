@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestReload(t *testing.T) {
+	is := is.New(t)
+	old := DefaultConfig()
+	n := DefaultConfig()
+	n.SSH.RateLimit.ConnectionRate = 2
+	n.SSH.MOTD.Text = "Welcome!"
+	n.Git.RateLimit.ConnectionBurst = 5
+	n.Webhook.MaxAttempts = 10
+	n.Webhook.Workers = 8
+	n.Log.Levels = map[string]string{"git": "debug"}
+	n.SSH.ListenAddr = ":2222"
+	n.DB.DataSource = "other.db"
+
+	live, restart := old.Reload(n)
+	is.Equal(restart, []string{"ssh.listen_addr", "db.data_source", "webhook.workers"})
+
+	// Reloadable settings are applied.
+	is.Equal(live.SSH.RateLimit, n.SSH.RateLimit)
+	is.Equal(live.SSH.MOTD, n.SSH.MOTD)
+	is.Equal(live.Git.RateLimit, n.Git.RateLimit)
+	is.Equal(live.Webhook.MaxAttempts, 10)
+	is.Equal(live.Log.Levels, n.Log.Levels)
+
+	// The others are kept until a restart, and the old config is untouched.
+	is.Equal(live.SSH.ListenAddr, old.SSH.ListenAddr)
+	is.Equal(live.Webhook.Workers, old.Webhook.Workers)
+	is.Equal(old.SSH.MOTD.Text, "")
+
+	_, restart = live.Reload(live)
+	is.Equal(len(restart), 0)
+}
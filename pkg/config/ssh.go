@@ -1,9 +1,16 @@
 package config
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
 
 	"github.com/charmbracelet/keygen"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 var (
@@ -26,3 +33,74 @@ func KeyPair(cfg *Config) (*keygen.SSHKeyPair, error) {
 
 	return keygen.New(cfg.SSH.KeyPath, keygen.WithKeyType(keygen.Ed25519))
 }
+
+// validateSSHAlgorithms returns an error if the configured ciphers, key
+// exchanges, or MACs aren't supported.
+func validateSSHAlgorithms(cfg SSHConfig) error {
+	supported := gossh.SupportedAlgorithms()
+	for _, a := range []struct {
+		name       string
+		configured []string
+		supported  []string
+	}{
+		{"cipher", cfg.Ciphers, supported.Ciphers},
+		{"key exchange", cfg.KeyExchanges, supported.KeyExchanges},
+		{"mac", cfg.MACs, supported.MACs},
+	} {
+		for _, v := range a.configured {
+			if !slices.Contains(a.supported, v) {
+				return fmt.Errorf("unsupported ssh %s %q, supported values are: %s", a.name, v, strings.Join(a.supported, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Render returns the message executed as a template with data, it's empty
+// when the message isn't set.
+func (m SSHMessageConfig) Render(data any) (string, error) {
+	text := m.Text
+	if m.Path != "" {
+		b, err := os.ReadFile(m.Path)
+		if err != nil {
+			return "", err
+		}
+		text = string(b)
+	}
+	if text == "" {
+		return "", nil
+	}
+
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// validateSSHMessages returns an error if the banner or the message of the
+// day has both a text and a path, or isn't a valid template.
+func validateSSHMessages(cfg SSHConfig) error {
+	for _, m := range []struct {
+		name string
+		cfg  SSHMessageConfig
+	}{
+		{"banner", cfg.Banner},
+		{"motd", cfg.MOTD},
+	} {
+		if m.cfg.Text != "" && m.cfg.Path != "" {
+			return fmt.Errorf("ssh %s can't have both a text and a path", m.name)
+		}
+		if _, err := template.New(m.name).Parse(m.cfg.Text); err != nil {
+			return fmt.Errorf("invalid ssh %s: %w", m.name, err)
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,11 @@
 package config
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
 
 func TestBadSSHKeyPair(t *testing.T) {
 	for _, cfg := range []*Config{
@@ -24,3 +29,81 @@ func TestGoodSSHKeyPair(t *testing.T) {
 		t.Errorf("cfg.SSH.KeyPair() => _, %v, want nil error", err)
 	}
 }
+
+func TestValidateSSHAlgorithms(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     SSHConfig
+		wantErr bool
+	}{
+		{"defaults", SSHConfig{}, false},
+		{"supported", SSHConfig{
+			Ciphers:      []string{"aes256-gcm@openssh.com", "chacha20-poly1305@openssh.com"},
+			KeyExchanges: []string{"curve25519-sha256"},
+			MACs:         []string{"hmac-sha2-256-etm@openssh.com"},
+		}, false},
+		{"unknown cipher", SSHConfig{Ciphers: []string{"rot13"}}, true},
+		{"unknown key exchange", SSHConfig{KeyExchanges: []string{"diffie-hellman-group1-sha0"}}, true},
+		{"unknown mac", SSHConfig{MACs: []string{"hmac-md4"}}, true},
+	}
+
+	for _, c := range cases {
+		if err := validateSSHAlgorithms(c.cfg); (err != nil) != c.wantErr {
+			t.Errorf("%s: validateSSHAlgorithms() error = %v, want error %t", c.name, err, c.wantErr)
+		}
+	}
+}
+
+func TestParseSSHAlgorithmsEnv(t *testing.T) {
+	t.Setenv("SOFT_SERVE_SSH_CIPHERS", "aes256-gcm@openssh.com,aes128-ctr")
+	cfg := DefaultConfig()
+	if err := cfg.ParseEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(cfg.SSH.Ciphers, " "); got != "aes256-gcm@openssh.com aes128-ctr" {
+		t.Errorf("SSH.Ciphers = %q", got)
+	}
+
+	t.Setenv("SOFT_SERVE_SSH_MACS", "hmac-md4")
+	cfg = DefaultConfig()
+	err := cfg.ParseEnv()
+	if err == nil || !strings.Contains(err.Error(), "hmac-sha2-256") {
+		t.Errorf("Validate() error = %v, want the supported macs", err)
+	}
+}
+
+func TestSSHMessage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "motd")
+	if err := os.WriteFile(path, []byte("Hi {{ .Username }}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	data := struct{ ServerName, Username string }{"Soft Serve", "frankie"}
+
+	cases := []struct {
+		name string
+		cfg  SSHMessageConfig
+		want string
+	}{
+		{"empty", SSHMessageConfig{}, ""},
+		{"text", SSHMessageConfig{Text: "Welcome to {{ .ServerName }}"}, "Welcome to Soft Serve"},
+		{"path", SSHMessageConfig{Path: path}, "Hi frankie"},
+	}
+	for _, c := range cases {
+		got, err := c.cfg.Render(data)
+		if err != nil {
+			t.Fatalf("%s: Render() error = %v", c.name, err)
+		}
+		if got != c.want {
+			t.Errorf("%s: Render() = %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	for _, cfg := range []SSHConfig{
+		{Banner: SSHMessageConfig{Text: "hi", Path: path}},
+		{MOTD: SSHMessageConfig{Text: "{{ .Username"}},
+	} {
+		if err := validateSSHMessages(cfg); err == nil {
+			t.Errorf("validateSSHMessages(%+v) => nil, want non-nil error", cfg)
+		}
+	}
+}
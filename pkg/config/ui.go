@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/charmbracelet/soft-serve/pkg/ui/styles"
+)
+
+// Theme returns the built-in theme with the configured colors.
+func (c ThemeConfig) Theme() (styles.Theme, error) {
+	t, err := styles.ThemeByName(c.Name)
+	if err != nil {
+		return t, err
+	}
+
+	for _, o := range []struct {
+		name  string
+		value string
+		color *color.Color
+	}{
+		{"accent", c.Accent, &t.Accent},
+		{"accent_text", c.AccentText, &t.AccentText},
+		{"secondary", c.Secondary, &t.Secondary},
+		{"highlight", c.Highlight, &t.Highlight},
+		{"selection", c.Selection, &t.Selection},
+		{"background", c.Background, &t.Background},
+		{"text", c.Text, &t.Text},
+		{"muted", c.Muted, &t.Muted},
+		{"subtle", c.Subtle, &t.Subtle},
+		{"added", c.Added, &t.Added},
+		{"deleted", c.Deleted, &t.Deleted},
+	} {
+		if o.value == "" {
+			continue
+		}
+		v, err := styles.ParseColor(o.value)
+		if err != nil {
+			return t, fmt.Errorf("%s: %w", o.name, err)
+		}
+		*o.color = v
+	}
+
+	return t, nil
+}
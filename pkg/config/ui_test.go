@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/soft-serve/pkg/ui/styles"
+)
+
+func TestTheme(t *testing.T) {
+	theme, err := ThemeConfig{Name: "light", Accent: "#ff5f87", Text: "16"}.Theme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	light := styles.LightTheme()
+	if theme.Accent != lipgloss.Color("#ff5f87") || theme.Text != lipgloss.Color("16") {
+		t.Errorf("Theme() didn't override the colors: %+v", theme)
+	}
+	if theme.Selection != light.Selection {
+		t.Errorf("Theme().Selection = %v, want the light theme's %v", theme.Selection, light.Selection)
+	}
+
+	cases := []struct {
+		name  string
+		theme ThemeConfig
+		ok    bool
+	}{
+		{"default", ThemeConfig{}, true},
+		{"high contrast", ThemeConfig{Name: "high-contrast"}, true},
+		{"unknown theme", ThemeConfig{Name: "solarized"}, false},
+		{"named color", ThemeConfig{Accent: "pink"}, false},
+		{"out of range", ThemeConfig{Selection: "256"}, false},
+		{"bad hex", ThemeConfig{Background: "#12345"}, false},
+	}
+	for _, c := range cases {
+		cfg := &Config{DataPath: t.TempDir(), UI: UIConfig{Theme: c.theme}}
+		if err := cfg.Validate(); (err == nil) != c.ok {
+			t.Errorf("%s: Validate() = %v, want ok %v", c.name, err, c.ok)
+		}
+	}
+}
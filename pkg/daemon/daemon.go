@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -14,9 +15,12 @@ import (
 
 	"charm.land/log/v2"
 	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/audit"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/git"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
+	"github.com/charmbracelet/soft-serve/pkg/ratelimit"
 	"github.com/charmbracelet/soft-serve/pkg/utils"
 	"github.com/go-git/go-git/v5/plumbing/format/pktline"
 	"github.com/prometheus/client_golang/prometheus"
@@ -37,6 +41,13 @@ var (
 		Name:      "git_upload_archive_total",
 		Help:      "The total number of git-upload-archive requests",
 	}, []string{"repo"})
+
+	rateLimitCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "rate_limit_total",
+		Help:      "The total number of rate limited git daemon connections",
+	}, []string{"allowed"})
 )
 
 // ErrServerClosed indicates that the server has been closed.
@@ -56,6 +67,14 @@ type GitDaemon struct {
 	done      atomic.Bool // indicates if the server has been closed
 	listeners []net.Listener
 	liMu      sync.Mutex
+	limiter   atomic.Pointer[connLimiter]
+}
+
+// connLimiter is the limiter of new connections, it's replaced when the
+// configuration is reloaded.
+type connLimiter struct {
+	ratelimit.Limiter
+	cfg config.GitRateLimitConfig
 }
 
 // NewGitDaemon returns a new Git daemon.
@@ -69,11 +88,54 @@ func NewGitDaemon(ctx context.Context) (*GitDaemon, error) {
 		cfg:      cfg,
 		be:       backend.FromContext(ctx),
 		conns:    connections{m: make(map[net.Conn]struct{})},
-		logger:   log.FromContext(ctx).WithPrefix("gitdaemon"),
+		logger:   logr.Subsystem(ctx, "git", "gitdaemon"),
 	}
+	d.Reload(cfg)
 	return d, nil
 }
 
+// Reload applies the rate limit of cfg to new connections. The rate limit
+// buckets are kept if the limit didn't change.
+func (d *GitDaemon) Reload(cfg *config.Config) {
+	rl := cfg.Git.RateLimit
+	if old := d.limiter.Load(); old != nil && old.cfg == rl {
+		return
+	}
+
+	l := &connLimiter{cfg: rl}
+	if rl.ConnectionRate > 0 {
+		l.Limiter = ratelimit.NewTokenBucket(rl.ConnectionRate, rl.ConnectionBurst)
+	}
+	d.limiter.Store(l)
+}
+
+// SetRateLimiter replaces the limiter of new connections per source IP. A nil
+// limiter disables the limit.
+func (d *GitDaemon) SetRateLimiter(limiter ratelimit.Limiter) {
+	l := &connLimiter{Limiter: limiter}
+	if old := d.limiter.Load(); old != nil {
+		l.cfg = old.cfg
+	}
+	d.limiter.Store(l)
+}
+
+// allow reports whether a new connection from addr is within the rate limit.
+func (d *GitDaemon) allow(addr net.Addr) bool {
+	l := d.limiter.Load()
+	if l == nil || l.Limiter == nil || addr == nil {
+		return true
+	}
+
+	host := addr.String()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	allowed := l.Allow(host)
+	rateLimitCounter.WithLabelValues(strconv.FormatBool(allowed)).Inc()
+	return allowed
+}
+
 // ListenAndServe starts the Git TCP daemon.
 func (d *GitDaemon) ListenAndServe() error {
 	if d.done.Load() {
@@ -124,6 +186,13 @@ func (d *GitDaemon) Serve(listener net.Listener) error {
 			return err
 		}
 
+		// Close connections over the rate limit of their source IP.
+		if !d.allow(conn.RemoteAddr()) {
+			d.logger.Info("rate limited connection", "remote-addr", conn.RemoteAddr())
+			d.fatal(conn, git.ErrMaxConnections)
+			continue
+		}
+
 		// Close connection if there are too many open connections.
 		if d.conns.Size()+1 >= d.cfg.Git.MaxConnections {
 			d.logger.Debugf("git: max connections reached, closing %s", conn.RemoteAddr())
@@ -207,6 +276,10 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 			counter = uploadPackGitCounter
 		case git.UploadArchiveService:
 			counter = uploadArchiveGitCounter
+		case git.ReceivePackService:
+			d.logger.Info("refused push", "remote-addr", c.RemoteAddr())
+			d.fatal(c, git.ErrDaemonPush)
+			return
 		default:
 			d.fatal(c, git.ErrInvalidRequest)
 			return
@@ -251,8 +324,12 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 		}
 
 		name := utils.SanitizeRepo(string(opts[0]))
-		d.logger.Debugf("git: connect %s %s %s", c.RemoteAddr(), service, name)
-		defer d.logger.Debugf("git: disconnect %s %s %s", c.RemoteAddr(), service, name)
+		logger := d.logger.With("remote-addr", c.RemoteAddr(), "service", service, "repo", name)
+		logger.Info("connect")
+		start := time.Now()
+		defer func() {
+			logger.Info("disconnect", "duration", time.Since(start))
+		}()
 
 		// git bare repositories should end in ".git"
 		// https://git-scm.com/docs/gitrepository-layout
@@ -264,13 +341,35 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 			return
 		}
 
+		// Operations can't run while the repository is being renamed.
+		release, err := d.be.AcquireRepository(name)
+		if err != nil {
+			d.fatal(c, err)
+			return
+		}
+		defer release()
+
 		if _, err := d.be.Repository(ctx, repo); err != nil {
 			d.fatal(c, git.ErrInvalidRepo)
 			return
 		}
 
-		auth := be.AccessLevel(ctx, name, "")
-		if auth < access.ReadOnlyAccess {
+		// Only repositories explicitly exported are served. Private
+		// repositories are never exported, so they look like missing ones.
+		if exported, err := d.be.IsExported(ctx, name); err != nil || !exported {
+			logger.Info("refused repository that isn't exported", "err", err)
+			d.fatal(c, git.ErrInvalidRepo)
+			return
+		}
+
+		ctx = audit.WithSource(ctx, audit.Source{Transport: "git", RemoteAddr: c.RemoteAddr().String()})
+		if err := be.CheckRemoteAddr(ctx, c.RemoteAddr().String(), nil); err != nil {
+			logger.Info("refused by ip rules", "err", err)
+			d.fatal(c, git.ErrNotAuthed)
+			return
+		}
+		if _, ok := be.Authorize(ctx, name, nil, service.String(), access.ReadOnlyAccess); !ok {
+			logger.Info("refused anonymous access")
 			d.fatal(c, git.ErrNotAuthed)
 			return
 		}
@@ -298,11 +397,19 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 		envs = append(envs, d.cfg.Environ()...)
 
 		cmd := git.ServiceCommand{
-			Stdin:  c,
-			Stdout: c,
-			Stderr: c,
-			Env:    envs,
-			Dir:    filepath.Join(reposDir, repo),
+			Stdin:      c,
+			Stdout:     c,
+			Stderr:     c,
+			Env:        envs,
+			Dir:        filepath.Join(reposDir, repo),
+			OnComplete: git.ObserveService("git", service),
+			Pack:       be.ServicePackOptions(ctx, name, service),
+		}
+		be.TrackActivity(ctx, &cmd, name, nil, service.String())
+
+		if service == git.UploadPackService {
+			cmd.DisableFilter = be.FiltersDisabled(ctx, name)
+			cmd.AllowedFilters = d.cfg.Repo.AllowedFilters
 		}
 
 		if err := service.Handler(ctx, cmd); err != nil {
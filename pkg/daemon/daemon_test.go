@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -15,6 +16,8 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/migrate"
 	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/ratelimit"
 	"github.com/charmbracelet/soft-serve/pkg/store"
 	"github.com/charmbracelet/soft-serve/pkg/store/database"
 	"github.com/charmbracelet/soft-serve/pkg/test"
@@ -22,7 +25,10 @@ import (
 	_ "modernc.org/sqlite" // sqlite driver
 )
 
-var testDaemon *GitDaemon
+var (
+	testCtx    context.Context
+	testDaemon *GitDaemon
+)
 
 func TestMain(m *testing.M) {
 	tmp, err := os.MkdirTemp("", "soft-serve-test")
@@ -49,6 +55,7 @@ func TestMain(m *testing.M) {
 	if err := migrate.Migrate(ctx, dbx); err != nil {
 		log.Fatal(err)
 	}
+	ctx = db.WithContext(ctx, dbx)
 	datastore := database.New(ctx, dbx)
 	ctx = store.WithContext(ctx, datastore)
 	be := backend.New(ctx, cfg, dbx, datastore)
@@ -57,6 +64,7 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	testCtx = ctx
 	testDaemon = d
 	go d.ListenAndServe() //nolint:errcheck
 	code := m.Run()
@@ -107,6 +115,94 @@ func TestInvalidRepo(t *testing.T) {
 	}
 }
 
+func TestReceivePackRefused(t *testing.T) {
+	c, err := net.Dial("tcp", testDaemon.addr) //nolint:noctx
+	if err != nil {
+		t.Fatalf("failed to connect to daemon: %v", err)
+	}
+	if err := pktline.NewEncoder(c).EncodeString("git-receive-pack /test.git\x00host=localhost\x00"); err != nil {
+		t.Fatalf("expected nil, got error: %v", err)
+	}
+	_, err = readPktline(c)
+	if err == nil || err.Error() != git.ErrDaemonPush.Error() {
+		t.Errorf("expected %q error, got %v", git.ErrDaemonPush, err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	be := testDaemon.be
+	user, err := be.CreateUser(testCtx, "alice", proto.UserOptions{})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if _, err := be.CreateRepository(testCtx, "exported", user, proto.RepositoryOptions{}); err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	uploadPack := func() (string, error) {
+		c, err := net.Dial("tcp", testDaemon.addr) //nolint:noctx
+		if err != nil {
+			t.Fatalf("failed to connect to daemon: %v", err)
+		}
+		defer c.Close() //nolint:errcheck
+		if err := pktline.NewEncoder(c).EncodeString("git-upload-pack /exported.git\x00host=localhost\x00"); err != nil {
+			t.Fatalf("expected nil, got error: %v", err)
+		}
+		return readPktline(c)
+	}
+
+	if _, err := uploadPack(); err == nil || err.Error() != git.ErrInvalidRepo.Error() {
+		t.Errorf("expected %q error for a repository that isn't exported, got %v", git.ErrInvalidRepo, err)
+	}
+
+	if err := be.SetExported(testCtx, "exported", true); err != nil {
+		t.Fatalf("failed to export repository: %v", err)
+	}
+	if _, err := uploadPack(); err != nil {
+		t.Errorf("expected no error for an exported repository, got %v", err)
+	}
+
+	// Making the repository private unexports it.
+	if err := be.SetPrivate(proto.WithUserContext(testCtx, user), "exported", true); err != nil {
+		t.Fatalf("failed to make repository private: %v", err)
+	}
+	if exported, err := be.IsExported(testCtx, "exported"); err != nil || exported {
+		t.Errorf("expected private repository not to be exported, got %v, %v", exported, err)
+	}
+	if err := be.SetExported(testCtx, "exported", true); !errors.Is(err, proto.ErrPrivateExport) {
+		t.Errorf("expected %q error, got %v", proto.ErrPrivateExport, err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	d, err := NewGitDaemon(testCtx)
+	if err != nil {
+		t.Fatalf("failed to create daemon: %v", err)
+	}
+	d.SetRateLimiter(ratelimit.NewTokenBucket(0.001, 1))
+
+	l, err := net.Listen("tcp", "localhost:0") //nolint:noctx
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go d.Serve(l)   //nolint:errcheck
+	defer d.Close() //nolint:errcheck
+
+	// The first connection is allowed and times out waiting for the request,
+	// the second one is over the limit.
+	for i, want := range []error{git.ErrTimeout, git.ErrMaxConnections} {
+		c, err := net.Dial("tcp", l.Addr().String()) //nolint:noctx
+		if err != nil {
+			t.Fatalf("failed to connect to daemon: %v", err)
+		}
+		_, err = readPktline(c)
+		if err == nil || err.Error() != want.Error() {
+			t.Errorf("connection %d: expected %q error, got %v", i, want, err)
+		}
+		c.Close() //nolint:errcheck
+	}
+}
+
 func readPktline(c net.Conn) (string, error) {
 	pktout := pktline.NewScanner(c)
 	if !pktout.Scan() {
@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"charm.land/log/v2"
-	"github.com/charmbracelet/soft-serve/pkg/config"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"  // postgres driver
 	_ "modernc.org/sqlite" // sqlite driver
@@ -21,21 +23,47 @@ type DB struct {
 
 // Open opens a database connection.
 func Open(ctx context.Context, driverName string, dsn string) (*DB, error) {
+	if driverName == "mysql" {
+		var err error
+		dsn, err = mysqlDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := sqlx.ConnectContext(ctx, driverName, dsn)
 	if err != nil {
 		return nil, err
 	}
 
+	// Queries are traced when the db subsystem logs at the debug level.
 	d := &DB{
-		DB: db,
+		DB:     db,
+		logger: logr.Subsystem(ctx, "db", "db"),
+	}
+
+	return d, nil
+}
+
+// mysqlDSN sets the connection parameters the queries and migrations rely
+// on. Times are read as UTC, multiple statements are allowed for migrations,
+// and double quotes quote identifiers like they do in sqlite and postgres.
+func mysqlDSN(dsn string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
 	}
 
-	if config.IsVerbose() {
-		logger := log.FromContext(ctx).WithPrefix("db")
-		d.logger = logger
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+	cfg.MultiStatements = true
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
 	}
+	cfg.Params["time_zone"] = "'+00:00'"
+	cfg.Params["sql_mode"] = "CONCAT(@@sql_mode, ',ANSI_QUOTES')"
 
-	return d, nil
+	return cfg.FormatDSN(), nil
 }
 
 // Close implements db.DB.
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"strings"
+)
+
+// isMySQL returns whether the handler is connected to a MySQL database.
+func isMySQL(h Handler) bool {
+	return h.DriverName() == "mysql"
+}
+
+// InsertReturningID runs an insert query and returns the id of the inserted
+// row. The query must not end with a semicolon.
+//
+// MySQL doesn't support RETURNING, the last insert id is used instead.
+func InsertReturningID(ctx context.Context, h Handler, query string, args ...interface{}) (int64, error) {
+	if isMySQL(h) {
+		res, err := h.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	}
+
+	var id int64
+	if err := h.GetContext(ctx, &id, query+" RETURNING id", args...); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// OnConflictUpdate returns the clause of an insert query that updates the
+// given columns of the existing row when the insert conflicts on the keys.
+func OnConflictUpdate(h Handler, keys []string, columns ...string) string {
+	sets := make([]string, len(columns))
+	for i, c := range columns {
+		if isMySQL(h) {
+			sets[i] = c + " = VALUES(" + c + ")"
+		} else {
+			sets[i] = c + " = excluded." + c
+		}
+	}
+
+	if isMySQL(h) {
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	}
+	return "ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+// OnConflictDoNothing returns the clause of an insert query that keeps the
+// existing row when the insert conflicts on the keys.
+func OnConflictDoNothing(h Handler, keys ...string) string {
+	if isMySQL(h) {
+		return "ON DUPLICATE KEY UPDATE " + keys[0] + " = " + keys[0]
+	}
+	return "ON CONFLICT (" + strings.Join(keys, ", ") + ") DO NOTHING"
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestOnConflict(t *testing.T) {
+	cases := []struct {
+		driver    string
+		update    string
+		doNothing string
+	}{
+		{"sqlite", "ON CONFLICT (repo_id) DO UPDATE SET url = excluded.url, updated_at = excluded.updated_at", "ON CONFLICT (name) DO NOTHING"},
+		{"postgres", "ON CONFLICT (repo_id) DO UPDATE SET url = excluded.url, updated_at = excluded.updated_at", "ON CONFLICT (name) DO NOTHING"},
+		{"mysql", "ON DUPLICATE KEY UPDATE url = VALUES(url), updated_at = VALUES(updated_at)", "ON DUPLICATE KEY UPDATE name = name"},
+	}
+	for _, c := range cases {
+		t.Run(c.driver, func(t *testing.T) {
+			h := sqlx.NewDb(nil, c.driver)
+			if got := OnConflictUpdate(h, []string{"repo_id"}, "url", "updated_at"); got != c.update {
+				t.Errorf("OnConflictUpdate() = %q, want %q", got, c.update)
+			}
+			if got := OnConflictDoNothing(h, "name"); got != c.doNothing {
+				t.Errorf("OnConflictDoNothing() = %q, want %q", got, c.doNothing)
+			}
+		})
+	}
+}
+
+func TestMySQLDSN(t *testing.T) {
+	dsn, err := mysqlDSN("user:pass@tcp(localhost:3306)/soft_serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.ParseTime || !cfg.MultiStatements {
+		t.Errorf("mysqlDSN() = %q, want parseTime and multiStatements", dsn)
+	}
+	if cfg.Params["sql_mode"] == "" || cfg.Params["time_zone"] == "" {
+		t.Errorf("mysqlDSN() = %q, want sql_mode and time_zone", dsn)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/go-sql-driver/mysql"
 )
 
 func TestWrapErrorBadNoRows(t *testing.T) {
@@ -23,3 +25,11 @@ func TestWrapErrorGoodNoRows(t *testing.T) {
 		t.Errorf("WrapError(sql.ErrNoRows) => %v, want %v", err, ErrRecordNotFound)
 	}
 }
+
+func TestWrapErrorMySQL(t *testing.T) {
+	for _, n := range []uint16{1062, 1451, 1452} {
+		if err := WrapError(&mysql.MySQLError{Number: n}); err != ErrDuplicateKey {
+			t.Errorf("WrapError(mysql error %d) => %v, want %v", n, err, ErrDuplicateKey)
+		}
+	}
+}
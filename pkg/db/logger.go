@@ -10,7 +10,7 @@ import (
 )
 
 func trace(l *log.Logger, query string, args ...interface{}) {
-	if l != nil {
+	if l != nil && l.GetLevel() <= log.DebugLevel {
 		// Remove newlines and tabs
 		query = strings.ReplaceAll(query, "\t", "")
 		query = strings.TrimSpace(query)
@@ -28,6 +28,8 @@ var createTables = Migration{
 		// Alter old tables (if exist)
 		// This is to support prior versions of Soft Serve v0.6
 		switch tx.DriverName() {
+		case "mysql":
+			insert += "IGNORE "
 		case "sqlite3", "sqlite":
 			insert += "OR IGNORE "
 
@@ -155,7 +157,7 @@ var createTables = Migration{
 		}
 
 		// Insert default settings
-		insertSettings := insert + "INTO settings (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)"
+		insertSettings := insert + `INTO settings ("key", value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)`
 		insertSettings = tx.Rebind(insertSettings)
 		settings := []struct {
 			Key   string
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoMirrorsName    = "repo mirrors"
+	repoMirrorsVersion = 4
+)
+
+var repoMirrors = Migration{
+	Name:    repoMirrorsName,
+	Version: repoMirrorsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoMirrorsVersion, repoMirrorsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoMirrorsVersion, repoMirrorsName)
+	},
+}
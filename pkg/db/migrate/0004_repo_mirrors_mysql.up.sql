@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS repo_mirrors (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL UNIQUE,
+  remote_url TEXT NOT NULL,
+  sync_interval INT NOT NULL DEFAULT 0,
+  username TEXT,
+  password TEXT,
+  last_synced_at DATETIME,
+  last_error TEXT,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT repo_mirrors_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS repo_mirrors (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL UNIQUE,
+  remote_url TEXT NOT NULL,
+  sync_interval INTEGER NOT NULL DEFAULT 0,
+  username TEXT,
+  password TEXT,
+  last_synced_at TIMESTAMP,
+  last_error TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
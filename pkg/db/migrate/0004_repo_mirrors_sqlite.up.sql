@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS repo_mirrors (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  repo_id INTEGER NOT NULL UNIQUE,
+  remote_url TEXT NOT NULL,
+  sync_interval INTEGER NOT NULL DEFAULT 0,
+  username TEXT,
+  password TEXT,
+  last_synced_at DATETIME,
+  last_error TEXT,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
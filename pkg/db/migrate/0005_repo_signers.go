@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoSignersName    = "repo signers"
+	repoSignersVersion = 5
+)
+
+var repoSigners = Migration{
+	Name:    repoSignersName,
+	Version: repoSignersVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoSignersVersion, repoSignersName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoSignersVersion, repoSignersName)
+	},
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS team_repos;
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	branchProtectionsName    = "branch protections"
+	branchProtectionsVersion = 8
+)
+
+var branchProtections = Migration{
+	Name:    branchProtectionsName,
+	Version: branchProtectionsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, branchProtectionsVersion, branchProtectionsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, branchProtectionsVersion, branchProtectionsName)
+	},
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS branch_protections (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  pattern TEXT NOT NULL,
+  access_level INTEGER NOT NULL,
+  allow_force BOOLEAN NOT NULL DEFAULT false,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  UNIQUE (repo_id, pattern),
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
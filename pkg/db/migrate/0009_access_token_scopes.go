@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	accessTokenScopesName    = "access token scopes"
+	accessTokenScopesVersion = 9
+)
+
+var accessTokenScopes = Migration{
+	Name:    accessTokenScopesName,
+	Version: accessTokenScopesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, accessTokenScopesVersion, accessTokenScopesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, accessTokenScopesVersion, accessTokenScopesName)
+	},
+}
@@ -0,0 +1 @@
+ALTER TABLE access_tokens ADD COLUMN scope INTEGER NOT NULL DEFAULT 3;
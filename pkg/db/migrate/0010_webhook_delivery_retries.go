@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	webhookDeliveryRetriesName    = "webhook delivery retries"
+	webhookDeliveryRetriesVersion = 10
+)
+
+var webhookDeliveryRetries = Migration{
+	Name:    webhookDeliveryRetriesName,
+	Version: webhookDeliveryRetriesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, webhookDeliveryRetriesVersion, webhookDeliveryRetriesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, webhookDeliveryRetriesVersion, webhookDeliveryRetriesName)
+	},
+}
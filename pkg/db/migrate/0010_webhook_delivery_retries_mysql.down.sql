@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+
+ALTER TABLE webhook_deliveries DROP COLUMN next_attempt_at;
+ALTER TABLE webhook_deliveries DROP COLUMN attempts;
@@ -0,0 +1,16 @@
+ALTER TABLE webhook_deliveries ADD COLUMN attempts INT NOT NULL DEFAULT 1;
+ALTER TABLE webhook_deliveries ADD COLUMN next_attempt_at DATETIME;
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  delivery_id VARCHAR(36) NOT NULL,
+  attempt INT NOT NULL,
+  request_error TEXT,
+  response_status INT NOT NULL,
+  response_body MEDIUMTEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT webhook_delivery_attempts_delivery_id_fk
+  FOREIGN KEY(delivery_id) REFERENCES webhook_deliveries(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,16 @@
+ALTER TABLE webhook_deliveries ADD COLUMN attempts INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE webhook_deliveries ADD COLUMN next_attempt_at DATETIME;
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  delivery_id TEXT NOT NULL,
+  attempt INTEGER NOT NULL,
+  request_error TEXT,
+  response_status INTEGER NOT NULL,
+  response_body TEXT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT delivery_id_fk
+  FOREIGN KEY(delivery_id) REFERENCES webhook_deliveries(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
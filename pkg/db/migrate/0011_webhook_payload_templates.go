@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	webhookPayloadTemplatesName    = "webhook payload templates"
+	webhookPayloadTemplatesVersion = 11
+)
+
+var webhookPayloadTemplates = Migration{
+	Name:    webhookPayloadTemplatesName,
+	Version: webhookPayloadTemplatesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, webhookPayloadTemplatesVersion, webhookPayloadTemplatesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, webhookPayloadTemplatesVersion, webhookPayloadTemplatesName)
+	},
+}
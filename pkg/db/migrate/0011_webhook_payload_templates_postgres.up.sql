@@ -0,0 +1,2 @@
+ALTER TABLE webhooks ADD COLUMN payload_template TEXT NOT NULL DEFAULT '';
+ALTER TABLE webhooks ADD COLUMN payload_content_type TEXT NOT NULL DEFAULT '';
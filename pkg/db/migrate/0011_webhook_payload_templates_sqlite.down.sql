@@ -0,0 +1,2 @@
+ALTER TABLE webhooks DROP COLUMN payload_content_type;
+ALTER TABLE webhooks DROP COLUMN payload_template;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoTopicsName    = "repo topics"
+	repoTopicsVersion = 12
+)
+
+var repoTopics = Migration{
+	Name:    repoTopicsName,
+	Version: repoTopicsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoTopicsVersion, repoTopicsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoTopicsVersion, repoTopicsName)
+	},
+}
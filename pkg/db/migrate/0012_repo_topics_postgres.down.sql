@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS repo_topics;
+DROP TABLE IF EXISTS topics;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	mirrorSSHKeysName    = "mirror ssh keys"
+	mirrorSSHKeysVersion = 13
+)
+
+var mirrorSSHKeys = Migration{
+	Name:    mirrorSSHKeysName,
+	Version: mirrorSSHKeysVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, mirrorSSHKeysVersion, mirrorSSHKeysName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, mirrorSSHKeysVersion, mirrorSSHKeysName)
+	},
+}
@@ -0,0 +1 @@
+ALTER TABLE repo_mirrors ADD COLUMN ssh_key TEXT;
@@ -0,0 +1 @@
+ALTER TABLE repo_mirrors DROP COLUMN ssh_key;
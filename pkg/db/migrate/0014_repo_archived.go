@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoArchivedName    = "repo archived"
+	repoArchivedVersion = 14
+)
+
+var repoArchived = Migration{
+	Name:    repoArchivedName,
+	Version: repoArchivedVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoArchivedVersion, repoArchivedName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoArchivedVersion, repoArchivedName)
+	},
+}
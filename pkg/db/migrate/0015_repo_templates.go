@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoTemplatesName    = "repo templates"
+	repoTemplatesVersion = 15
+)
+
+var repoTemplates = Migration{
+	Name:    repoTemplatesName,
+	Version: repoTemplatesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoTemplatesVersion, repoTemplatesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoTemplatesVersion, repoTemplatesName)
+	},
+}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  username VARCHAR(255) NOT NULL,
+  repo VARCHAR(255) NOT NULL,
+  service TEXT NOT NULL,
+  transport TEXT NOT NULL,
+  access_level INT NOT NULL,
+  remote_addr TEXT NOT NULL,
+  allowed BOOLEAN NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX audit_log_username_idx (username),
+  INDEX audit_log_repo_idx (repo)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  username TEXT NOT NULL,
+  repo TEXT NOT NULL,
+  service TEXT NOT NULL,
+  transport TEXT NOT NULL,
+  access_level INTEGER NOT NULL,
+  remote_addr TEXT NOT NULL,
+  allowed BOOLEAN NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_username_idx ON audit_log (username);
+CREATE INDEX IF NOT EXISTS audit_log_repo_idx ON audit_log (repo);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	ipRulesName    = "ip rules"
+	ipRulesVersion = 17
+)
+
+var ipRules = Migration{
+	Name:    ipRulesName,
+	Version: ipRulesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, ipRulesVersion, ipRulesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, ipRulesVersion, ipRulesName)
+	},
+}
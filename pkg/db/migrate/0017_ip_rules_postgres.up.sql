@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS ip_rules (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER,
+  cidr TEXT NOT NULL,
+  allow BOOLEAN NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  CONSTRAINT user_id_fk
+  FOREIGN KEY(user_id) REFERENCES users(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ip_rules_user_id_idx ON ip_rules (user_id);
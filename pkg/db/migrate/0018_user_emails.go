@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	userEmailsName    = "user emails"
+	userEmailsVersion = 18
+)
+
+var userEmails = Migration{
+	Name:    userEmailsName,
+	Version: userEmailsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, userEmailsVersion, userEmailsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, userEmailsVersion, userEmailsName)
+	},
+}
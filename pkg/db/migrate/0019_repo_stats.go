@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoStatsName    = "repo stats"
+	repoStatsVersion = 19
+)
+
+var repoStats = Migration{
+	Name:    repoStatsName,
+	Version: repoStatsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoStatsVersion, repoStatsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoStatsVersion, repoStatsName)
+	},
+}
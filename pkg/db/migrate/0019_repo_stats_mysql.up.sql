@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS repo_stats (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL UNIQUE,
+  size BIGINT NOT NULL DEFAULT 0,
+  commits BIGINT NOT NULL DEFAULT 0,
+  default_branch VARCHAR(255) NOT NULL DEFAULT '',
+  last_pushed_at DATETIME,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT repo_stats_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS repo_stats (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL UNIQUE,
+  size BIGINT NOT NULL DEFAULT 0,
+  commits BIGINT NOT NULL DEFAULT 0,
+  default_branch TEXT NOT NULL DEFAULT '',
+  last_pushed_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
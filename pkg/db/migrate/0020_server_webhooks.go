@@ -0,0 +1,74 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	serverWebhooksName    = "server webhooks"
+	serverWebhooksVersion = 20
+)
+
+// serverWebhooks makes the repository of webhooks optional, server webhooks
+// don't have one.
+var serverWebhooks = Migration{
+	Name:    serverWebhooksName,
+	Version: serverWebhooksVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		switch tx.DriverName() {
+		case "sqlite3", "sqlite":
+			return setWebhooksRepoNull(ctx, tx, "repo_id INTEGER NOT NULL", "repo_id INTEGER")
+		}
+
+		return migrateUp(ctx, tx, serverWebhooksVersion, serverWebhooksName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		switch tx.DriverName() {
+		case "sqlite3", "sqlite":
+			if _, err := tx.ExecContext(ctx, "DELETE FROM webhooks WHERE repo_id IS NULL;"); err != nil {
+				return err
+			}
+			return setWebhooksRepoNull(ctx, tx, "repo_id INTEGER,", "repo_id INTEGER NOT NULL,")
+		}
+
+		return migrateDown(ctx, tx, serverWebhooksVersion, serverWebhooksName)
+	},
+}
+
+// setWebhooksRepoNull replaces the definition of the repo_id column of the
+// webhooks table. SQLite can't alter columns, but NOT NULL constraints can be
+// changed in the schema table without rebuilding the table, and its
+// dependents.
+// See https://www.sqlite.org/lang_altertable.html#otheralter
+func setWebhooksRepoNull(ctx context.Context, tx *db.Tx, from, to string) error {
+	var sql string
+	if err := tx.GetContext(ctx, &sql, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'webhooks';"); err != nil {
+		return err
+	}
+	if !strings.Contains(sql, from) {
+		return fmt.Errorf("unexpected webhooks table schema: %s", sql)
+	}
+
+	var version int
+	if err := tx.GetContext(ctx, &version, "PRAGMA schema_version;"); err != nil {
+		return err
+	}
+
+	for _, q := range []string{
+		"PRAGMA writable_schema = ON;",
+		fmt.Sprintf("UPDATE sqlite_master SET sql = '%s' WHERE type = 'table' AND name = 'webhooks';",
+			strings.ReplaceAll(strings.Replace(sql, from, to, 1), "'", "''")),
+		fmt.Sprintf("PRAGMA schema_version = %d;", version+1),
+		"PRAGMA writable_schema = OFF;",
+	} {
+		if _, err := tx.ExecContext(ctx, q); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,2 @@
+DELETE FROM webhooks WHERE repo_id IS NULL;
+ALTER TABLE webhooks MODIFY repo_id INT NOT NULL;
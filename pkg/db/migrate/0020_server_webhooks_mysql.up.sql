@@ -0,0 +1 @@
+ALTER TABLE webhooks MODIFY repo_id INT NULL;
@@ -0,0 +1,2 @@
+DELETE FROM webhooks WHERE repo_id IS NULL;
+ALTER TABLE webhooks ALTER COLUMN repo_id SET NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE webhooks ALTER COLUMN repo_id DROP NOT NULL;
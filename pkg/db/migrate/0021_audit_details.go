@@ -0,0 +1,25 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	auditDetailsName    = "audit details"
+	auditDetailsVersion = 21
+)
+
+// auditDetails adds the details of changes, such as the new owner of a
+// transferred repository, to the audit log.
+var auditDetails = Migration{
+	Name:    auditDetailsName,
+	Version: auditDetailsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, auditDetailsVersion, auditDetailsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, auditDetailsVersion, auditDetailsName)
+	},
+}
@@ -0,0 +1 @@
+ALTER TABLE audit_log ADD COLUMN details TEXT NOT NULL DEFAULT '';
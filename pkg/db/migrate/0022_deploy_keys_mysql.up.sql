@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS deploy_keys (
+  id INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  repo_id INT NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  public_key VARCHAR(3000) CHARACTER SET ascii COLLATE ascii_bin NOT NULL UNIQUE,
+  access_level INT NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (repo_id, title),
+  CONSTRAINT deploy_keys_repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
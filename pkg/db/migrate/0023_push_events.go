@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	pushEventsName    = "push events"
+	pushEventsVersion = 23
+)
+
+var pushEvents = Migration{
+	Name:    pushEventsName,
+	Version: pushEventsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, pushEventsVersion, pushEventsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, pushEventsVersion, pushEventsName)
+	},
+}
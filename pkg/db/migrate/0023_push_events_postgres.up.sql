@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS push_events (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  username TEXT NOT NULL,
+  refs TEXT NOT NULL,
+  commits INTEGER NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
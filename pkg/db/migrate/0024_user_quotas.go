@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	userQuotasName    = "user quotas"
+	userQuotasVersion = 24
+)
+
+var userQuotas = Migration{
+	Name:    userQuotasName,
+	Version: userQuotasVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, userQuotasVersion, userQuotasName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, userQuotasVersion, userQuotasName)
+	},
+}
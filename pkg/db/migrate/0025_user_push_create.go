@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	userPushCreateName    = "user push create"
+	userPushCreateVersion = 25
+)
+
+var userPushCreate = Migration{
+	Name:    userPushCreateName,
+	Version: userPushCreateVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, userPushCreateVersion, userPushCreateName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, userPushCreateVersion, userPushCreateName)
+	},
+}
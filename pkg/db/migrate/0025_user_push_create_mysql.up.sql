@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN allow_push_create BOOLEAN;
@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN allow_push_create;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoHookEnvName    = "repo hook env"
+	repoHookEnvVersion = 26
+)
+
+var repoHookEnv = Migration{
+	Name:    repoHookEnvName,
+	Version: repoHookEnvVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoHookEnvVersion, repoHookEnvName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoHookEnvVersion, repoHookEnvName)
+	},
+}
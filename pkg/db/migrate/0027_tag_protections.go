@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	tagProtectionsName    = "tag protections"
+	tagProtectionsVersion = 27
+)
+
+var tagProtections = Migration{
+	Name:    tagProtectionsName,
+	Version: tagProtectionsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, tagProtectionsVersion, tagProtectionsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, tagProtectionsVersion, tagProtectionsName)
+	},
+}
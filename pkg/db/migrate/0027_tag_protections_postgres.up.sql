@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS tag_protections (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  pattern TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  UNIQUE (repo_id, pattern),
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
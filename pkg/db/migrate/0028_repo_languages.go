@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoLanguagesName    = "repo languages"
+	repoLanguagesVersion = 28
+)
+
+var repoLanguages = Migration{
+	Name:    repoLanguagesName,
+	Version: repoLanguagesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoLanguagesVersion, repoLanguagesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoLanguagesVersion, repoLanguagesName)
+	},
+}
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoForksName    = "repo forks"
+	repoForksVersion = 29
+)
+
+var repoForks = Migration{
+	Name:    repoForksName,
+	Version: repoForksVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoForksVersion, repoForksName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoForksVersion, repoForksName)
+	},
+}
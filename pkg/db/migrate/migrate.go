@@ -130,9 +130,9 @@ func hasTable(tx *db.Tx, tableName string) bool {
 	case "sqlite3", "sqlite":
 		query = "SELECT name FROM sqlite_master WHERE type='table' AND name=?"
 	case "postgres":
-		fallthrough
-	case "mysql":
 		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public' AND table_name = ?"
+	case "mysql":
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
 	}
 
 	query = tx.Rebind(query)
@@ -18,6 +18,32 @@ var migrations = []Migration{
 	createTables,
 	webhooks,
 	migrateLfsObjects,
+	repoMirrors,
+	repoSigners,
+	repoSettings,
+	teams,
+	branchProtections,
+	accessTokenScopes,
+	webhookDeliveryRetries,
+	webhookPayloadTemplates,
+	repoTopics,
+	mirrorSSHKeys,
+	repoArchived,
+	repoTemplates,
+	auditLog,
+	ipRules,
+	userEmails,
+	repoStats,
+	serverWebhooks,
+	auditDetails,
+	deployKeys,
+	pushEvents,
+	userQuotas,
+	userPushCreate,
+	repoHookEnv,
+	tagProtections,
+	repoLanguages,
+	repoForks,
 }
 
 func execMigration(ctx context.Context, tx *db.Tx, version int, name string, down bool) error {
@@ -37,6 +63,11 @@ func execMigration(ctx context.Context, tx *db.Tx, version int, name string, dow
 		return err
 	}
 
+	// MySQL refuses empty queries.
+	if strings.TrimSpace(string(sqlstr)) == "" {
+		return nil
+	}
+
 	if _, err := tx.ExecContext(ctx, string(sqlstr)); err != nil {
 		return err
 	}
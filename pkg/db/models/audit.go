@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+)
+
+// AuditEntry is an access-control decision of the audit log.
+type AuditEntry struct {
+	ID          int64              `db:"id"`
+	Username    string             `db:"username"`
+	Repo        string             `db:"repo"`
+	Service     string             `db:"service"`
+	Transport   string             `db:"transport"`
+	AccessLevel access.AccessLevel `db:"access_level"`
+	RemoteAddr  string             `db:"remote_addr"`
+	Allowed     bool               `db:"allowed"`
+	Details     string             `db:"details"`
+	CreatedAt   time.Time          `db:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+)
+
+// BranchProtection is a rule restricting updates to the branches of a
+// repository matching a pattern.
+type BranchProtection struct {
+	ID          int64              `db:"id"`
+	RepoID      int64              `db:"repo_id"`
+	Pattern     string             `db:"pattern"`
+	AccessLevel access.AccessLevel `db:"access_level"`
+	AllowForce  bool               `db:"allow_force"`
+	CreatedAt   time.Time          `db:"created_at"`
+	UpdatedAt   time.Time          `db:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RepoHookEnv is an environment variable passed to the custom hooks of a
+// repository. The value is encrypted.
+type RepoHookEnv struct {
+	ID        int64     `db:"id"`
+	RepoID    int64     `db:"repo_id"`
+	Name      string    `db:"name"`
+	Value     string    `db:"value"`
+	Secret    bool      `db:"secret"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// IPRule allows or denies clients connecting from an address range. Rules
+// without a user apply to every connection.
+type IPRule struct {
+	ID        int64         `db:"id"`
+	UserID    sql.NullInt64 `db:"user_id"`
+	CIDR      string        `db:"cidr"`
+	Allow     bool          `db:"allow"`
+	CreatedAt time.Time     `db:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at"`
+}
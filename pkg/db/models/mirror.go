@@ -0,0 +1,25 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// MirrorConfig is the upstream configuration of a mirror repository.
+type MirrorConfig struct {
+	ID        int64  `db:"id"`
+	RepoID    int64  `db:"repo_id"`
+	RemoteURL string `db:"remote_url"`
+	// SyncInterval is the minimum number of seconds between syncs. Zero means
+	// the repository is synced every time the mirror job runs.
+	SyncInterval int64          `db:"sync_interval"`
+	Username     sql.NullString `db:"username"`
+	Password     sql.NullString `db:"password"`
+	// SSHKey is the private key used to authenticate to an SSH upstream
+	// instead of the server client key.
+	SSHKey       sql.NullString `db:"ssh_key"`
+	LastSyncedAt sql.NullTime   `db:"last_synced_at"`
+	LastError    sql.NullString `db:"last_error"`
+	CreatedAt    time.Time      `db:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at"`
+}
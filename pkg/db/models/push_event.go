@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PushEvent is a push to a repository.
+type PushEvent struct {
+	ID       int64  `db:"id"`
+	RepoID   int64  `db:"repo_id"`
+	Username string `db:"username"`
+	// Refs are the refs updated by the push, one "<old> <new> <ref>" line per
+	// ref, as git passes them to the receive hooks.
+	Refs      string    `db:"refs"`
+	Commits   int64     `db:"commits"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RepoLanguages is the language breakdown of the default branch of a
+// repository at a commit. It's refreshed by the repo-stats job when the
+// default branch moves, so it may lag behind the repository.
+type RepoLanguages struct {
+	ID       int64  `db:"id"`
+	RepoID   int64  `db:"repo_id"`
+	CommitID string `db:"commit_id"`
+	// Languages are the languages as JSON.
+	Languages string    `db:"languages"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RepoStats are the statistics of a repository. They're refreshed after
+// pushes and by the repo-stats job, so they may lag behind the repository.
+type RepoStats struct {
+	ID     int64 `db:"id"`
+	RepoID int64 `db:"repo_id"`
+	// Size is the size in bytes of the loose and packed objects.
+	Size          int64        `db:"size"`
+	Commits       int64        `db:"commits"`
+	DefaultBranch string       `db:"default_branch"`
+	LastPushedAt  sql.NullTime `db:"last_pushed_at"`
+	CreatedAt     time.Time    `db:"created_at"`
+	UpdatedAt     time.Time    `db:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RepoSigner is a key allowed to sign commits pushed to a repository.
+type RepoSigner struct {
+	ID        int64     `db:"id"`
+	RepoID    int64     `db:"repo_id"`
+	Principal string    `db:"principal"`
+	PublicKey string    `db:"public_key"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
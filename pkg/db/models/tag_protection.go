@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// TagProtection is a rule keeping the existing tags of a repository matching
+// a pattern from being moved or deleted.
+type TagProtection struct {
+	ID        int64     `db:"id"`
+	RepoID    int64     `db:"repo_id"`
+	Pattern   string    `db:"pattern"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
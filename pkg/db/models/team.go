@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+)
+
+// Team is a named group of users.
+type Team struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+	// AccessLevel is the access level granted to repositories by default.
+	AccessLevel access.AccessLevel `db:"access_level"`
+	CreatedAt   time.Time          `db:"created_at"`
+	UpdatedAt   time.Time          `db:"updated_at"`
+}
+
+// TeamMember is the membership of a user in a team.
+type TeamMember struct {
+	ID        int64     `db:"id"`
+	TeamID    int64     `db:"team_id"`
+	UserID    int64     `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// TeamRepo is the access of a team to a repository.
+type TeamRepo struct {
+	ID          int64              `db:"id"`
+	TeamID      int64              `db:"team_id"`
+	RepoID      int64              `db:"repo_id"`
+	AccessLevel access.AccessLevel `db:"access_level"`
+	CreatedAt   time.Time          `db:"created_at"`
+	UpdatedAt   time.Time          `db:"updated_at"`
+}
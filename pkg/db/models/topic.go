@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Topic is a topic repositories can be categorized with.
+type Topic struct {
+	ID        int64     `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// RepoTopic is a topic of a repository.
+type RepoTopic struct {
+	ID        int64     `db:"id"`
+	RepoID    int64     `db:"repo_id"`
+	TopicID   int64     `db:"topic_id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -7,10 +7,17 @@ import (
 
 // User represents a user.
 type User struct {
-	ID        int64          `db:"id"`
-	Username  string         `db:"username"`
-	Admin     bool           `db:"admin"`
-	Password  sql.NullString `db:"password"`
-	CreatedAt time.Time      `db:"created_at"`
-	UpdatedAt time.Time      `db:"updated_at"`
+	ID       int64          `db:"id"`
+	Username string         `db:"username"`
+	Admin    bool           `db:"admin"`
+	Password sql.NullString `db:"password"`
+	Email    sql.NullString `db:"email"`
+	// Quota is the maximum total size in bytes of the repositories the user
+	// owns, zero for no limit. The server default applies when it's null.
+	Quota sql.NullInt64 `db:"quota"`
+	// AllowPushCreate is whether pushes of the user can create repositories.
+	// The server default applies when it's null.
+	AllowPushCreate sql.NullBool `db:"allow_push_create"`
+	CreatedAt       time.Time    `db:"created_at"`
+	UpdatedAt       time.Time    `db:"updated_at"`
 }
@@ -7,16 +7,18 @@ import (
 	"github.com/google/uuid"
 )
 
-// Webhook is a repository webhook.
+// Webhook is a repository webhook. Server webhooks don't have a repository.
 type Webhook struct {
-	ID          int64     `db:"id"`
-	RepoID      int64     `db:"repo_id"`
-	URL         string    `db:"url"`
-	Secret      string    `db:"secret"`
-	ContentType int       `db:"content_type"`
-	Active      bool      `db:"active"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID                 int64         `db:"id"`
+	RepoID             sql.NullInt64 `db:"repo_id"`
+	URL                string        `db:"url"`
+	Secret             string        `db:"secret"`
+	ContentType        int           `db:"content_type"`
+	PayloadTemplate    string        `db:"payload_template"`
+	PayloadContentType string        `db:"payload_content_type"`
+	Active             bool          `db:"active"`
+	CreatedAt          time.Time     `db:"created_at"`
+	UpdatedAt          time.Time     `db:"updated_at"`
 }
 
 // WebhookEvent is a webhook event.
@@ -40,5 +42,18 @@ type WebhookDelivery struct {
 	ResponseStatus  int            `db:"response_status"`
 	ResponseHeaders string         `db:"response_headers"`
 	ResponseBody    string         `db:"response_body"`
+	Attempts        int            `db:"attempts"`
+	NextAttemptAt   sql.NullTime   `db:"next_attempt_at"`
 	CreatedAt       time.Time      `db:"created_at"`
 }
+
+// WebhookDeliveryAttempt is an attempt to send a webhook delivery.
+type WebhookDeliveryAttempt struct {
+	ID             int64          `db:"id"`
+	DeliveryID     uuid.UUID      `db:"delivery_id"`
+	Attempt        int            `db:"attempt"`
+	RequestError   sql.NullString `db:"request_error"`
+	ResponseStatus int            `db:"response_status"`
+	ResponseBody   string         `db:"response_body"`
+	CreatedAt      time.Time      `db:"created_at"`
+}
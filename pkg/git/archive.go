@@ -0,0 +1,23 @@
+package git
+
+import (
+	"context"
+	"strings"
+)
+
+// archiveService is the git archive command. Unlike the other services, it
+// isn't a protocol spoken with a client, it writes an archive of a tree-ish
+// to the command stdout.
+const archiveService Service = "git-archive"
+
+// Archive writes an archive of a tree-ish to the command stdout using git
+// archive. The format is one of the formats git archive knows, such as
+// "tar.gz" or "zip", and every path in the archive starts with prefix.
+func Archive(ctx context.Context, cmd ServiceCommand, format, prefix, treeish string) error {
+	if strings.HasPrefix(treeish, "-") {
+		return ErrInvalidRequest
+	}
+
+	cmd.Args = append(cmd.Args, "--format="+format, "--prefix="+prefix, treeish)
+	return gitServiceHandler(ctx, archiveService, cmd)
+}
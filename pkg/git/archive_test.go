@@ -0,0 +1,54 @@
+package git
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+func TestArchive(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second := testCommits(t, repo.Path)
+
+	var stdout bytes.Buffer
+	if err := Archive(context.TODO(), ServiceCommand{Stdout: &stdout, Dir: repo.Path}, "zip", "repo-main/", "main"); err != nil {
+		t.Fatalf("Archive() => %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(stdout.Bytes()), int64(stdout.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip archive: %v", err)
+	}
+	// git archive stores the archived commit in the zip comment.
+	if zr.Comment != second {
+		t.Errorf("got archive of %q, want %q", zr.Comment, second)
+	}
+}
+
+func TestArchiveInvalidTreeish(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCommits(t, repo.Path)
+
+	var stdout bytes.Buffer
+	err = Archive(context.TODO(), ServiceCommand{Stdout: &stdout, Dir: repo.Path}, "zip", "", "--output=/tmp/archive.zip")
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("Archive() => %v, want ErrInvalidRequest", err)
+	}
+
+	var serr *ServiceError
+	err = Archive(context.TODO(), ServiceCommand{Stdout: &stdout, Dir: repo.Path}, "zip", "", "nope")
+	if !errors.As(err, &serr) {
+		t.Errorf("Archive() => %v, want a service error", err)
+	}
+}
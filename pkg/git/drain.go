@@ -0,0 +1,78 @@
+package git
+
+import (
+	"context"
+	"sync"
+)
+
+// services keeps track of the git service processes running, so the server
+// can wait for them to exit before shutting down.
+var services serviceTracker
+
+// serviceTracker counts running services. Unlike a sync.WaitGroup, services
+// can start while others wait for the count to reach zero.
+type serviceTracker struct {
+	mu   sync.Mutex
+	n    int
+	idle chan struct{}
+}
+
+// add marks a service as running.
+func (t *serviceTracker) add() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.n++
+}
+
+// done marks a service as exited.
+func (t *serviceTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.n--
+	if t.n == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// running returns the number of running services.
+func (t *serviceTracker) running() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.n
+}
+
+// wait waits until no service is running, or until ctx is done.
+func (t *serviceTracker) wait(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		if t.n == 0 {
+			t.mu.Unlock()
+			return nil
+		}
+		if t.idle == nil {
+			t.idle = make(chan struct{})
+		}
+		idle := t.idle
+		t.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-idle:
+		}
+	}
+}
+
+// RunningServices returns the number of git service processes, such as
+// upload-pack and receive-pack, running over all transports.
+func RunningServices() int {
+	return services.running()
+}
+
+// WaitServices waits for the running git service processes to exit. It
+// returns the error of ctx if it's done first.
+func WaitServices(ctx context.Context) error {
+	return services.wait(ctx)
+}
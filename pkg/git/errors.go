@@ -1,6 +1,10 @@
 package git
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+)
 
 var (
 	// ErrNotAuthed represents unauthorized access.
@@ -20,4 +24,55 @@ var (
 
 	// ErrTimeout is returned when the maximum read timeout is exceeded.
 	ErrTimeout = errors.New("I/O timeout reached")
+
+	// ErrServiceTimeout is returned when a git service command exceeds its
+	// configured timeout.
+	ErrServiceTimeout = errors.New("git service timeout reached")
+
+	// ErrPackTooLarge is returned when a client sends more data than the
+	// configured maximum pack size.
+	ErrPackTooLarge = errors.New("pack exceeds maximum allowed size")
+
+	// ErrMirrorPush is returned when a client tries to push to a mirror
+	// repository.
+	ErrMirrorPush = errors.New("repository is a mirror and can't be pushed to")
+
+	// ErrArchivedPush is returned when a client tries to push to an archived
+	// repository.
+	ErrArchivedPush = errors.New("repository is archived")
+
+	// ErrPushCreateDisabled is returned when a client pushes to a repository
+	// that doesn't exist and pushes can't create it.
+	ErrPushCreateDisabled = errors.New("repository does not exist; create it first")
+
+	// ErrDaemonPush is returned when a client tries to push over the git
+	// protocol, which only serves clones and fetches.
+	ErrDaemonPush = errors.New("pushing over git:// isn't supported, use ssh or http")
 )
+
+// ServiceError is returned when a git service command exits with a non-zero
+// status.
+type ServiceError struct {
+	// Err is the underlying exit error.
+	Err *exec.ExitError
+	// Stderr is the tail of the command's standard error.
+	Stderr string
+}
+
+// Error implements error.
+func (e *ServiceError) Error() string {
+	if e.Stderr == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Err, e.Stderr)
+}
+
+// Unwrap returns the underlying exit error.
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of the command.
+func (e *ServiceError) ExitCode() int {
+	return e.Err.ExitCode()
+}
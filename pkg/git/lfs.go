@@ -2,24 +2,26 @@ package git
 
 import (
 	"context"
-	"crypto/rand"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path"
-	"path/filepath"
 	"strconv"
 	"time"
 
 	"charm.land/log/v2"
 	"github.com/charmbracelet/git-lfs-transfer/transfer"
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
 	"github.com/charmbracelet/soft-serve/pkg/lfs"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/storage"
 	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/webhook"
 )
 
 // lfsTransfer implements transfer.Backend.
@@ -50,7 +52,7 @@ func LFSTransfer(ctx context.Context, cmd ServiceCommand) error {
 		return errors.New("invalid operation")
 	}
 
-	logger := log.FromContext(ctx).WithPrefix("lfs-transfer")
+	logger := logr.Subsystem(ctx, "lfs", "lfs-transfer")
 	handler := transfer.NewPktline(cmd.Stdin, cmd.Stdout, &lfsLogger{logger})
 	repo := proto.RepositoryFromContext(ctx)
 	if repo == nil {
@@ -71,15 +73,20 @@ func LFSTransfer(ctx context.Context, cmd ServiceCommand) error {
 		return err
 	}
 
-	repoID := strconv.FormatInt(repo.ID(), 10)
 	cfg := config.FromContext(ctx)
+	strg, err := storage.NewLFSStorage(cfg, repo.ID())
+	if err != nil {
+		logger.Error("error creating lfs storage", "err", err)
+		return err
+	}
+
 	processor := transfer.NewProcessor(handler, &lfsTransfer{
 		ctx:     ctx,
 		cfg:     cfg,
 		dbx:     db.FromContext(ctx),
 		store:   store.FromContext(ctx),
 		logger:  logger,
-		storage: storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID)),
+		storage: strg,
 		repo:    repo,
 	}, &lfsLogger{logger})
 
@@ -111,19 +118,20 @@ func (t *lfsTransfer) Batch(_ string, pointers []transfer.BatchItem, _ transfer.
 
 // Download implements transfer.Backend.
 func (t *lfsTransfer) Download(oid string, _ transfer.Args) (io.ReadCloser, int64, error) {
-	cfg := config.FromContext(t.ctx)
-	repoID := strconv.FormatInt(t.repo.ID(), 10)
-	strg := storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
-	pointer := transfer.Pointer{Oid: oid}
-	obj, err := strg.Open(path.Join("objects", pointer.RelativePath()))
+	// Never serve corrupted objects, git-lfs doesn't always verify them.
+	obj, err := lfs.OpenObject(t.storage, lfs.Pointer{Oid: oid})
 	if err != nil {
+		if errors.Is(err, lfs.ErrOIDMismatch) || errors.Is(err, lfs.ErrInvalidOIDFormat) {
+			t.logger.Error("refusing to serve corrupted object", "oid", oid, "err", err)
+			return nil, 0, fmt.Errorf("%w: %w", transfer.ErrCorruptData, err)
+		}
 		return nil, 0, err
 	}
 	stat, err := obj.Stat()
 	if err != nil {
 		return nil, 0, err
 	}
-	return obj, stat.Size(), nil
+	return lfs.CountingReader(obj, "ssh", lfs.DirectionDownload), stat.Size(), nil
 }
 
 // Upload implements transfer.Backend.
@@ -132,33 +140,23 @@ func (t *lfsTransfer) Upload(oid string, size int64, r io.Reader, _ transfer.Arg
 		return fmt.Errorf("no reader: %w", transfer.ErrMissingData)
 	}
 
-	tempDir := "incomplete"
-	randBytes := make([]byte, 12)
-	if _, err := rand.Read(randBytes); err != nil {
-		return err
+	pointer := lfs.Pointer{
+		Oid:  oid,
+		Size: size,
 	}
 
-	tempName := fmt.Sprintf("%s%x", oid, randBytes)
-	tempName = path.Join(tempDir, tempName)
-
-	written, err := t.storage.Put(tempName, r)
+	// The object is only stored once its content matches its oid.
+	written, err := lfs.StoreObject(t.storage, pointer, r)
+	lfs.ObserveTransfer("ssh", lfs.DirectionUpload, written)
 	if err != nil {
-		t.logger.Errorf("error putting object: %v", err)
-		return err
-	}
-
-	obj, err := t.storage.Open(tempName)
-	if err != nil {
-		t.logger.Errorf("error opening object: %v", err)
+		t.logger.Errorf("error storing object: %v", err)
+		if errors.Is(err, lfs.ErrOIDMismatch) || errors.Is(err, lfs.ErrSizeMismatch) || errors.Is(err, lfs.ErrInvalidOIDFormat) {
+			return fmt.Errorf("%w: %w", transfer.ErrCorruptData, err)
+		}
 		return err
 	}
 
-	pointer := transfer.Pointer{
-		Oid: oid,
-	}
-	if size > 0 {
-		pointer.Size = size
-	} else {
+	if pointer.Size <= 0 {
 		pointer.Size = written
 	}
 
@@ -166,11 +164,14 @@ func (t *lfsTransfer) Upload(oid string, size int64, r io.Reader, _ transfer.Arg
 		return db.WrapError(err)
 	}
 
-	expectedPath := path.Join("objects", pointer.RelativePath())
-	if err := t.storage.Rename(obj.Name(), expectedPath); err != nil {
-		t.logger.Errorf("error renaming object: %v", err)
-		_ = t.store.DeleteLFSObjectByOid(t.ctx, t.dbx, t.repo.ID(), pointer.Oid)
-		return err
+	// The object is verified and recorded, it won't go away if the webhook
+	// fails.
+	wh, err := webhook.NewLFSEvent(t.ctx, proto.UserFromContext(t.ctx), t.repo, webhook.LFSEventActionUpload, pointer.Oid, pointer.Size)
+	if err == nil {
+		err = webhook.SendEvent(t.ctx, wh)
+	}
+	if err != nil {
+		t.logger.Error("error sending lfs upload webhook", "repo", t.repo.Name(), "oid", pointer.Oid, "err", err)
 	}
 
 	return nil
@@ -254,10 +255,13 @@ func (l *lfsLockBackend) FromID(id string) (transfer.Lock, error) {
 
 	if err := l.dbx.TransactionContext(l.ctx, func(tx *db.Tx) error {
 		var err error
-		lock.lock, err = l.store.GetLFSLockForUserByID(l.ctx, tx, l.repo.ID(), l.user.ID(), iid)
+		lock.lock, err = l.store.GetLFSLockByID(l.ctx, tx, iid)
 		if err != nil {
 			return db.WrapError(err)
 		}
+		if lock.lock.RepoID != l.repo.ID() {
+			return db.ErrRecordNotFound
+		}
 
 		lock.owner, err = l.store.GetUserByID(l.ctx, tx, lock.lock.UserID)
 		return db.WrapError(err)
@@ -280,7 +284,7 @@ func (l *lfsLockBackend) FromPath(path string) (transfer.Lock, error) {
 
 	if err := l.dbx.TransactionContext(l.ctx, func(tx *db.Tx) error {
 		var err error
-		lock.lock, err = l.store.GetLFSLockForUserPath(l.ctx, tx, l.repo.ID(), l.user.ID(), path)
+		lock.lock, err = l.store.GetLFSLockForPath(l.ctx, tx, l.repo.ID(), path)
 		if err != nil {
 			return db.WrapError(err)
 		}
@@ -364,14 +368,28 @@ func (l *lfsLockBackend) Unlock(lock transfer.Lock) error {
 	}
 
 	err = l.dbx.TransactionContext(l.ctx, func(tx *db.Tx) error {
-		return db.WrapError(
-			l.store.DeleteLFSLockForUserByID(l.ctx, tx, l.repo.ID(), l.user.ID(), id),
-		)
+		owned, err := l.store.GetLFSLockForUserByID(l.ctx, tx, l.repo.ID(), l.user.ID(), id)
+		if err == nil {
+			return db.WrapError(l.store.DeleteLFSLock(l.ctx, tx, l.repo.ID(), owned.ID))
+		} else if !errors.Is(db.WrapError(err), db.ErrRecordNotFound) {
+			return db.WrapError(err)
+		}
+
+		// Only users with write access can remove the locks of others, and
+		// only when they ask for it.
+		if l.args["force"] != "true" || access.FromContext(l.ctx) < access.ReadWriteAccess {
+			return os.ErrPermission
+		}
+
+		return db.WrapError(l.store.DeleteLFSLock(l.ctx, tx, l.repo.ID(), id))
 	})
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			return transfer.ErrNotFound
 		}
+		if errors.Is(err, os.ErrPermission) {
+			return err
+		}
 		l.logger.Error("error unlocking lock", "err", err)
 		return err
 	}
@@ -411,7 +429,7 @@ func (l *LFSLock) AsLockSpec(ownerID bool) ([]string, error) {
 
 	if ownerID {
 		who := "theirs"
-		if l.lock.UserID == l.owner.ID {
+		if l.lock.UserID == l.backend.user.ID() {
 			who = "ours"
 		}
 
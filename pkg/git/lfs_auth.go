@@ -7,10 +7,10 @@ import (
 	"fmt"
 	"time"
 
-	"charm.land/log/v2"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/jwk"
 	"github.com/charmbracelet/soft-serve/pkg/lfs"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -23,7 +23,7 @@ func LFSAuthenticate(ctx context.Context, cmd ServiceCommand) error {
 		return errors.New("missing args")
 	}
 
-	logger := log.FromContext(ctx).WithPrefix("ssh.lfs-authenticate")
+	logger := logr.Subsystem(ctx, "lfs", "ssh.lfs-authenticate")
 	operation := cmd.Args[1]
 	if operation != lfs.OperationDownload && operation != lfs.OperationUpload {
 		logger.Errorf("invalid operation: %s", operation)
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	operationCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "operations_total",
+		Help:      "The total number of git operations by transport, service, and result",
+	}, []string{"transport", "service", "result"})
+
+	operationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "operation_duration_seconds",
+		Help:      "The duration of git operations by transport and service",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+	}, []string{"transport", "service"})
+
+	operationBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "transferred_bytes_total",
+		Help:      "The total number of bytes transferred by git operations by transport, service, and direction",
+	}, []string{"transport", "service", "direction"})
+)
+
+// ObserveService returns a ServiceCommand.OnComplete callback that records
+// the outcome, duration, and transferred bytes of a git operation made over a
+// transport, like "ssh", "http", or "git".
+func ObserveService(transport string, svc Service) func(ServiceStats) {
+	return func(stats ServiceStats) {
+		name := svc.Name()
+		operationCounter.WithLabelValues(transport, name, ServiceResult(stats.Err)).Inc()
+		operationSeconds.WithLabelValues(transport, name).Observe(stats.Duration.Seconds())
+		operationBytes.WithLabelValues(transport, name, "in").Add(float64(stats.BytesIn))
+		operationBytes.WithLabelValues(transport, name, "out").Add(float64(stats.BytesOut))
+	}
+}
+
+// ServiceResult returns the result of a git operation, as in the result label
+// of its metrics: "success", "timeout", "canceled", "rejected", or "error".
+func ServiceResult(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, ErrServiceTimeout):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, ErrPackTooLarge), errors.Is(err, ErrQuotaExceeded):
+		return "rejected"
+	default:
+		return "error"
+	}
+}
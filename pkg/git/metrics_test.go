@@ -0,0 +1,51 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveService(t *testing.T) {
+	observe := ObserveService("ssh", ReceivePackService)
+	ops := operationCounter.WithLabelValues("ssh", "receive-pack", "success")
+	in := operationBytes.WithLabelValues("ssh", "receive-pack", "in")
+	out := operationBytes.WithLabelValues("ssh", "receive-pack", "out")
+	before := testutil.ToFloat64(ops)
+
+	observe(ServiceStats{BytesIn: 100, BytesOut: 20, Duration: time.Second})
+
+	if got := testutil.ToFloat64(ops) - before; got != 1 {
+		t.Errorf("operations = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(in); got != 100 {
+		t.Errorf("bytes in = %v, want 100", got)
+	}
+	if got := testutil.ToFloat64(out); got != 20 {
+		t.Errorf("bytes out = %v, want 20", got)
+	}
+}
+
+func TestServiceResult(t *testing.T) {
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{nil, "success"},
+		{ErrServiceTimeout, "timeout"},
+		{context.Canceled, "canceled"},
+		{ErrPackTooLarge, "rejected"},
+		{fmt.Errorf("repo: %w", ErrQuotaExceeded), "rejected"},
+		{errors.New("exit status 128"), "error"},
+	}
+
+	for _, c := range cases {
+		if got := ServiceResult(c.err); got != c.want {
+			t.Errorf("ServiceResult(%v) = %q, want %q", c.err, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package git
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Mirror updates a mirror repository from its upstream by running git remote
+// update. Only Dir, Env, Args, Stdout, Stderr, Timeout, CmdFunc, and
+// KillGracePeriod are used from cmd.
+func Mirror(ctx context.Context, scmd ServiceCommand) error {
+	return runUpstream(ctx, scmd, append([]string{"remote", "update", "--prune"}, scmd.Args...))
+}
+
+// Clone clones an upstream into a new bare repository at dst, or a mirror
+// repository when mirror is set. Progress is written to Stderr when it's set.
+// Only Env, Stdout, Stderr, Timeout, CmdFunc, and KillGracePeriod are used
+// from cmd.
+func Clone(ctx context.Context, remote, dst string, mirror bool, scmd ServiceCommand) error {
+	args := []string{"clone", "--bare"}
+	if mirror {
+		args = []string{"clone", "--mirror"}
+	}
+	if scmd.Stderr != nil {
+		args = append(args, "--progress")
+	} else {
+		args = append(args, "--quiet")
+	}
+
+	// Clone from the parent directory so that progress doesn't show the
+	// server path.
+	scmd.Dir = filepath.Dir(dst)
+	if err := os.MkdirAll(scmd.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	return runUpstream(ctx, scmd, append(args, "--", remote, filepath.Base(dst)))
+}
+
+// runUpstream runs a git command talking to an upstream repository.
+func runUpstream(ctx context.Context, scmd ServiceCommand, args []string) error {
+	// Our timeout is recorded as the cause of timeoutCtx so that a deadline
+	// of the parent context isn't reported as ErrServiceTimeout.
+	var timeoutCtx context.Context
+	if scmd.Timeout > 0 {
+		var cancel context.CancelFunc
+		timeoutCtx, cancel = context.WithTimeoutCause(ctx, scmd.Timeout, ErrServiceTimeout)
+		defer cancel()
+		ctx = timeoutCtx
+	}
+
+	cmd := exec.CommandContext(ctx, GitBinary(), args...)
+	cmd.Dir = scmd.Dir
+	cmd.Env = append(os.Environ(), scmd.Env...)
+	// Never wait on a credentials prompt.
+	cmd.Env = append(cmd.Env, "GIT_TERMINAL_PROMPT=0")
+	setGracefulCancel(cmd, scmd.KillGracePeriod)
+
+	stderrTail := &tailBuffer{max: stderrTailSize}
+	cmd.Stdout = scmd.Stdout
+	cmd.Stderr = stderrTail
+	if scmd.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(stderrTail, scmd.Stderr)
+	}
+
+	if scmd.CmdFunc != nil {
+		scmd.CmdFunc(cmd)
+	}
+
+	if err := cmd.Run(); err != nil {
+		if timeoutCtx != nil && errors.Is(context.Cause(timeoutCtx), ErrServiceTimeout) {
+			return ErrServiceTimeout
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return &ServiceError{Err: exitErr, Stderr: stderrTail.String()}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// MirrorCredentialsEnv returns the environment variables used to
+// authenticate to an HTTP upstream with username and password. Passing them
+// through the environment keeps credentials out of the repository config.
+// It returns nil when no credentials are given.
+func MirrorCredentialsEnv(username, password string) []string {
+	if username == "" && password == "" {
+		return nil
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + auth,
+	}
+}
@@ -0,0 +1,140 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+func TestMirror(t *testing.T) {
+	upstream, err := git.Init(filepath.Join(t.TempDir(), "upstream"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := testCommits(t, upstream.Path)
+
+	dir := filepath.Join(t.TempDir(), "mirror.git")
+	if out, err := exec.Command("git", "clone", "--mirror", upstream.Path, dir).CombinedOutput(); err != nil {
+		t.Fatalf("git clone: %v: %s", err, out)
+	}
+
+	// Rewind main and add a branch upstream.
+	for _, args := range [][]string{
+		{"update-ref", "refs/heads/main", first},
+		{"update-ref", "refs/heads/other", second},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = upstream.Path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	if err := Mirror(context.Background(), ServiceCommand{Dir: dir}); err != nil {
+		t.Fatalf("Mirror() => %v, want nil error", err)
+	}
+
+	for ref, want := range map[string]string{
+		"refs/heads/main":  first,
+		"refs/heads/other": second,
+	} {
+		cmd := exec.Command("git", "rev-parse", ref)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git rev-parse %s: %v", ref, err)
+		}
+		if got := strings.TrimSpace(string(out)); got != want {
+			t.Errorf("%s = %s, want %s", ref, got, want)
+		}
+	}
+}
+
+func TestMirrorError(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("git", "remote", "add", "origin", filepath.Join(t.TempDir(), "missing"))
+	cmd.Dir = repo.Path
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git remote add: %v: %s", err, out)
+	}
+
+	err = Mirror(context.Background(), ServiceCommand{Dir: repo.Path})
+	var serr *ServiceError
+	if !errors.As(err, &serr) {
+		t.Fatalf("Mirror() => %v, want *ServiceError", err)
+	}
+	if serr.Stderr == "" {
+		t.Error("expected stderr to be captured")
+	}
+}
+
+func TestClone(t *testing.T) {
+	upstream, err := git.Init(filepath.Join(t.TempDir(), "upstream"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, second := testCommits(t, upstream.Path)
+
+	// The parent directory of namespaced repositories may not exist yet.
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "org", "clone.git")
+	var progress bytes.Buffer
+	if err := Clone(context.Background(), upstream.Path, dst, true, ServiceCommand{Stderr: &progress}); err != nil {
+		t.Fatalf("Clone() => %v, want nil error", err)
+	}
+
+	cmd := exec.Command("git", "rev-parse", "refs/heads/main")
+	cmd.Dir = dst
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git rev-parse: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != second {
+		t.Errorf("refs/heads/main = %s, want %s", got, second)
+	}
+
+	if !strings.Contains(progress.String(), "clone.git") {
+		t.Errorf("expected progress, got %q", progress.String())
+	}
+	if strings.Contains(progress.String(), dir) {
+		t.Errorf("progress leaked the server path: %q", progress.String())
+	}
+}
+
+func TestCloneError(t *testing.T) {
+	err := Clone(context.Background(), filepath.Join(t.TempDir(), "missing"), filepath.Join(t.TempDir(), "clone.git"), false, ServiceCommand{})
+	var serr *ServiceError
+	if !errors.As(err, &serr) {
+		t.Fatalf("Clone() => %v, want *ServiceError", err)
+	}
+}
+
+func TestMirrorCredentialsEnv(t *testing.T) {
+	if env := MirrorCredentialsEnv("", ""); env != nil {
+		t.Errorf("MirrorCredentialsEnv() = %v, want nil", env)
+	}
+
+	env := MirrorCredentialsEnv("user", "token")
+	want := "GIT_CONFIG_VALUE_0=Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("user:token"))
+	if len(env) != 3 || env[2] != want {
+		t.Errorf("MirrorCredentialsEnv() = %v, want %q", env, want)
+	}
+	for _, e := range env {
+		if strings.Contains(e, "token") {
+			t.Errorf("credentials leaked in plain text: %q", e)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrQuotaExceeded is returned when a push would make a repository exceed its
+// quota.
+var ErrQuotaExceeded = errors.New("repository quota exceeded")
+
+// QuotaChecker checks whether a repository is allowed to receive more data.
+type QuotaChecker interface {
+	// Allowed returns an error if the repository can't accept incomingBytes
+	// of data.
+	Allowed(repo string, incomingBytes int64) error
+}
+
+// QuotaFunc returns a size in bytes for the given repository.
+type QuotaFunc func(repo string) (int64, error)
+
+// NewSizeQuota returns a QuotaChecker that rejects pushes that would grow a
+// repository past the size returned by limit. A limit less than or equal to
+// zero means no limit. Both limit and size are only looked up once per
+// repository, so a new checker should be created for each push.
+func NewSizeQuota(limit, size QuotaFunc) QuotaChecker {
+	return &sizeQuota{
+		limit: limit,
+		size:  size,
+		repos: map[string][2]int64{},
+	}
+}
+
+type sizeQuota struct {
+	limit QuotaFunc
+	size  QuotaFunc
+	mu    sync.Mutex
+	repos map[string][2]int64
+}
+
+// Allowed implements QuotaChecker.
+func (q *sizeQuota) Allowed(repo string, incomingBytes int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	v, ok := q.repos[repo]
+	if !ok {
+		limit, err := q.limit(repo)
+		if err != nil {
+			return err
+		}
+
+		var size int64
+		if limit > 0 {
+			size, err = q.size(repo)
+			if err != nil {
+				return err
+			}
+		}
+
+		v = [2]int64{limit, size}
+		q.repos[repo] = v
+	}
+
+	limit, size := v[0], v[1]
+	if limit > 0 && size+incomingBytes > limit {
+		return fmt.Errorf("%w: %s is limited to %d bytes", ErrQuotaExceeded, repo, limit)
+	}
+
+	return nil
+}
+
+// quotaReader consults a QuotaChecker as bytes are read from the underlying
+// reader.
+type quotaReader struct {
+	r     io.Reader
+	repo  string
+	quota QuotaChecker
+	n     int64
+}
+
+// Read implements io.Reader.
+func (q *quotaReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+	q.n += int64(n)
+	if qerr := q.quota.Allowed(q.repo, q.n); qerr != nil {
+		if !errors.Is(qerr, ErrQuotaExceeded) {
+			qerr = fmt.Errorf("%w: %w", ErrQuotaExceeded, qerr)
+		}
+		return 0, qerr
+	}
+	return n, err
+}
+
+// writeSidebandError writes msg to w as a pkt-line on the error sideband
+// channel so that git clients display it.
+func writeSidebandError(w io.Writer, msg string) error {
+	payload := "\x03" + msg + "\n"
+	_, err := fmt.Fprintf(w, "%04x%s", len(payload)+4, payload)
+	return err
+}
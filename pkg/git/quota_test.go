@@ -0,0 +1,85 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+func TestSizeQuota(t *testing.T) {
+	var lookups int
+	q := NewSizeQuota(
+		func(repo string) (int64, error) {
+			if repo == "unlimited" {
+				return 0, nil
+			}
+			return 100, nil
+		},
+		func(string) (int64, error) {
+			lookups++
+			return 60, nil
+		},
+	)
+
+	cases := []struct {
+		repo     string
+		incoming int64
+		err      error
+	}{
+		{repo: "repo", incoming: 10},
+		{repo: "repo", incoming: 40},
+		{repo: "repo", incoming: 41, err: ErrQuotaExceeded},
+		{repo: "unlimited", incoming: 1 << 40},
+	}
+	for _, c := range cases {
+		if err := q.Allowed(c.repo, c.incoming); !errors.Is(err, c.err) {
+			t.Errorf("Allowed(%q, %d) => %v, want %v", c.repo, c.incoming, err, c.err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("size looked up %d times, want 1", lookups)
+	}
+}
+
+type quotaFunc func(repo string, incomingBytes int64) error
+
+func (f quotaFunc) Allowed(repo string, incomingBytes int64) error {
+	return f(repo, incomingBytes)
+}
+
+func TestReceivePackQuota(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errFull := errors.New("disk is full")
+	var gotRepo string
+	var stdout bytes.Buffer
+	stdin := io.MultiReader(strings.NewReader("0fa0"), strings.NewReader(strings.Repeat("a", 4000)))
+	err = ReceivePack(context.TODO(), ServiceCommand{
+		Stdin:  stdin,
+		Stdout: &stdout,
+		Dir:    repo.Path,
+		Repo:   "repo",
+		Quota: quotaFunc(func(repo string, _ int64) error {
+			gotRepo = repo
+			return errFull
+		}),
+	})
+	if !errors.Is(err, ErrQuotaExceeded) || !errors.Is(err, errFull) {
+		t.Errorf("ReceivePack() => %v, want ErrQuotaExceeded", err)
+	}
+	if gotRepo != "repo" {
+		t.Errorf("quota checked for %q, want %q", gotRepo, "repo")
+	}
+	if !strings.Contains(stdout.String(), "\x03"+err.Error()+"\n") {
+		t.Errorf("expected quota error on sideband, got %q", stdout.String())
+	}
+}
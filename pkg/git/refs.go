@@ -0,0 +1,72 @@
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RefsState returns a fingerprint of the refs of the repository at dir. It
+// changes whenever a ref is created, updated, or deleted, whoever changes it,
+// so it can key caches of data derived from the refs, like the ref
+// advertisement. It reads the files of the refs instead of running git: HEAD,
+// the loose refs, and the size and modification time of packed-refs, which is
+// rewritten as a whole.
+func RefsState(dir string) (string, error) {
+	h := sha256.New()
+
+	head, err := os.ReadFile(filepath.Join(dir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "HEAD\x00%s\x00", head)
+
+	fi, err := os.Stat(filepath.Join(dir, "packed-refs"))
+	switch {
+	case err == nil:
+		fmt.Fprintf(h, "packed-refs\x00%d\x00%d\x00", fi.Size(), fi.ModTime().UnixNano())
+	case !errors.Is(err, fs.ErrNotExist):
+		return "", err
+	}
+
+	// WalkDir visits the refs in lexical order, the fingerprint doesn't
+	// depend on the order of the directory entries.
+	if err := filepath.WalkDir(filepath.Join(dir, "refs"), func(p string, d fs.DirEntry, err error) error {
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			// The ref was deleted while walking.
+			return nil
+		case err != nil:
+			return err
+		case d.IsDir() || strings.HasSuffix(p, ".lock"):
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint: errcheck
+
+		rel, _ := filepath.Rel(dir, p)
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		_, err = h.Write([]byte{0})
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+func TestRefsState(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := func() string {
+		t.Helper()
+		s, err := RefsState(repo.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo.Path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	empty := state()
+	first, second := testCommits(t, repo.Path)
+	pushed := state()
+	if pushed == empty {
+		t.Error("state didn't change when a branch was created")
+	}
+	if again := state(); again != pushed {
+		t.Errorf("state changed without ref changes: %s != %s", again, pushed)
+	}
+
+	run("update-ref", "refs/heads/main", first)
+	if state() == pushed {
+		t.Error("state didn't change when a branch was updated")
+	}
+	run("update-ref", "refs/heads/main", second)
+
+	run("tag", "v1", first)
+	tagged := state()
+	run("pack-refs", "--all")
+	packed := state()
+	if packed == tagged {
+		t.Error("state didn't change when refs were packed")
+	}
+
+	run("tag", "-d", "v1")
+	deleted := state()
+	if deleted == packed {
+		t.Error("state didn't change when a packed tag was deleted")
+	}
+
+	run("symbolic-ref", "HEAD", "refs/heads/other")
+	if state() == deleted {
+		t.Error("state didn't change when HEAD changed")
+	}
+}
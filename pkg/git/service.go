@@ -7,10 +7,15 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
-	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/git"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 )
 
 // Service is a Git daemon service.
@@ -41,6 +46,10 @@ func (s Service) Name() string {
 
 // Handler is the service handler.
 func (s Service) Handler(ctx context.Context, cmd ServiceCommand) error {
+	if h, ok := LookupServiceHandler(s); ok {
+		return h(ctx, cmd)
+	}
+
 	switch s {
 	case UploadPackService, UploadArchiveService, ReceivePackService:
 		return gitServiceHandler(ctx, s, cmd)
@@ -53,39 +62,162 @@ func (s Service) Handler(ctx context.Context, cmd ServiceCommand) error {
 	}
 }
 
+// DefaultKillGracePeriod is the default time given to git processes to exit
+// before they get killed.
+const DefaultKillGracePeriod = 5 * time.Second
+
 // ServiceHandler is a git service command handler.
 type ServiceHandler func(ctx context.Context, cmd ServiceCommand) error
 
+var (
+	gitBinaryMu sync.RWMutex
+	gitBinary   = "git"
+)
+
+// SetGitBinary sets the path of the git executable used to run git services.
+// An empty path resets it to "git" which is looked up in PATH.
+func SetGitBinary(path string) error {
+	if path == "" {
+		path = "git"
+	} else {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("git binary: %w", err)
+		}
+		if fi.IsDir() || fi.Mode().Perm()&0o111 == 0 {
+			return fmt.Errorf("git binary %q is not executable", path)
+		}
+	}
+
+	gitBinaryMu.Lock()
+	defer gitBinaryMu.Unlock()
+	gitBinary = path
+	return nil
+}
+
+// GitBinary returns the path of the git executable used to run git services.
+func GitBinary() string {
+	gitBinaryMu.RLock()
+	defer gitBinaryMu.RUnlock()
+	return gitBinary
+}
+
+var (
+	serviceHandlersMu sync.RWMutex
+	serviceHandlers   = map[Service]ServiceHandler{}
+)
+
+// RegisterServiceHandler registers a handler for the given service. Registered
+// handlers take precedence over the built-in ones. Registering a nil handler
+// removes any previously registered handler for the service.
+func RegisterServiceHandler(svc Service, h ServiceHandler) {
+	serviceHandlersMu.Lock()
+	defer serviceHandlersMu.Unlock()
+	if h == nil {
+		delete(serviceHandlers, svc)
+		return
+	}
+	serviceHandlers[svc] = h
+}
+
+// LookupServiceHandler returns the registered handler for the given service.
+func LookupServiceHandler(svc Service) (ServiceHandler, bool) {
+	serviceHandlersMu.RLock()
+	defer serviceHandlersMu.RUnlock()
+	h, ok := serviceHandlers[svc]
+	return h, ok
+}
+
 // gitServiceHandler is the default service handler using the git binary.
-func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) error {
-	cmd := exec.CommandContext(ctx, "git")
+func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) (rerr error) {
+	services.add()
+	defer services.done()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Keep the timeout context around to tell our own timeout apart from a
+	// deadline of the parent context, which the derived contexts share.
+	var timeoutCtx context.Context
+	if scmd.Timeout > 0 {
+		var cancel context.CancelFunc
+		timeoutCtx, cancel = context.WithTimeoutCause(ctx, scmd.Timeout, ErrServiceTimeout)
+		defer cancel()
+		ctx = timeoutCtx
+	}
+
+	var bytesIn, bytesOut atomic.Int64
+	var abortErr atomic.Value
+	if scmd.OnStart != nil {
+		scmd.OnStart()
+	}
+	if scmd.OnComplete != nil {
+		start := time.Now()
+		defer func() {
+			scmd.OnComplete(ServiceStats{
+				BytesIn:  bytesIn.Load(),
+				BytesOut: bytesOut.Load(),
+				Duration: time.Since(start),
+				Err:      rerr,
+			})
+		}()
+	}
+
+	if v := scmd.ProtocolVersion; v != nil && (*v < 0 || *v > 2) {
+		return fmt.Errorf("unsupported protocol version: %d", *v)
+	}
+
+	filters, err := filterArgs(scmd)
+	if err != nil {
+		return err
+	}
+
+	logger := logr.Subsystem(ctx, "git", "git").With("service", svc, "dir", scmd.Dir)
+
+	cmd := exec.CommandContext(ctx, GitBinary())
 	cmd.Dir = scmd.Dir
+	if scmd.ProtocolVersion != nil {
+		cmd.Args = append(cmd.Args, "-c", fmt.Sprintf("protocol.version=%d", *scmd.ProtocolVersion))
+	}
+	cmd.Args = append(cmd.Args, filters...)
 	cmd.Args = append(cmd.Args, []string{
-		// Enable partial clones
-		"-c", "uploadpack.allowFilter=true",
 		// Enable push options
 		"-c", "receive.advertisePushOptions=true",
 		// Disable LFS filters
 		"-c", "filter.lfs.required=", "-c", "filter.lfs.smudge=", "-c", "filter.lfs.clean=",
-		svc.Name(),
 	}...)
+	if scmd.DenyNonFastForward {
+		cmd.Args = append(cmd.Args, "-c", "receive.denyNonFastForwards=true")
+	}
+	if scmd.WriteBitmaps {
+		cmd.Args = append(cmd.Args, "-c", "pack.writeBitmaps=true")
+	}
+	cmd.Args = append(cmd.Args, scmd.Pack.ConfigArgs()...)
+	cmd.Args = append(cmd.Args, svc.Name())
 	if len(scmd.Args) > 0 {
 		cmd.Args = append(cmd.Args, scmd.Args...)
 	}
 
-	cmd.Args = append(cmd.Args, ".")
+	if svc != archiveService {
+		cmd.Args = append(cmd.Args, ".")
+	}
 
 	cmd.Env = os.Environ()
 	if len(scmd.Env) > 0 {
 		cmd.Env = append(cmd.Env, scmd.Env...)
 	}
+	if scmd.ProtocolVersion != nil {
+		// This overrides the protocol version requested by the client.
+		cmd.Env = append(cmd.Env, fmt.Sprintf("GIT_PROTOCOL=version=%d", *scmd.ProtocolVersion))
+	}
+
+	setGracefulCancel(cmd, scmd.KillGracePeriod)
 
 	if scmd.CmdFunc != nil {
 		scmd.CmdFunc(cmd)
 	}
 
 	var (
-		err    error
 		stdin  io.WriteCloser
 		stdout io.ReadCloser
 		stderr io.ReadCloser
@@ -105,11 +237,15 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 		}
 	}
 
+	// Keep the tail of stderr around to log it if git fails.
+	stderrTail := &tailBuffer{max: stderrTailSize}
 	if scmd.Stderr != nil {
 		stderr, err = cmd.StderrPipe()
 		if err != nil {
 			return err
 		}
+	} else if cmd.Stderr == nil {
+		cmd.Stderr = stderrTail
 	}
 
 	if err := cmd.Start(); err != nil {
@@ -119,14 +255,47 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 		return err
 	}
 
+	// Run this before consuming any output so that limits applied to the
+	// process take effect before it does the heavy work.
+	if scmd.PostStartFunc != nil {
+		scmd.PostStartFunc(cmd.Process.Pid)
+	}
+
+	// Close our ends of the pipes once the context is done so the copy
+	// goroutines below don't block on a killed process.
+	stop := context.AfterFunc(ctx, func() {
+		if stdin != nil {
+			stdin.Close() //nolint: errcheck
+		}
+		if stdout != nil {
+			stdout.Close() //nolint: errcheck
+		}
+		if stderr != nil {
+			stderr.Close() //nolint: errcheck
+		}
+	})
+	defer stop()
+
 	wg := &sync.WaitGroup{}
 
 	// stdin
 	if scmd.Stdin != nil {
+		in := scmd.Stdin
+		if scmd.MaxPackBytes > 0 {
+			in = &maxBytesReader{r: in, remaining: scmd.MaxPackBytes}
+		}
+		if scmd.Quota != nil {
+			in = &quotaReader{r: in, repo: scmd.Repo, quota: scmd.Quota}
+		}
 		go func() {
 			defer stdin.Close() //nolint: errcheck
-			if _, err := io.Copy(stdin, scmd.Stdin); err != nil {
-				log.Errorf("gitServiceHandler: failed to copy stdin: %v", err)
+			_, err := io.Copy(&countingWriter{stdin, &bytesIn}, in)
+			if errors.Is(err, ErrPackTooLarge) || errors.Is(err, ErrQuotaExceeded) {
+				// Kill the git process before it gets to unpack anything.
+				abortErr.Store(err)
+				cancel()
+			} else if err != nil && ctx.Err() == nil {
+				logger.Error("failed to copy stdin", "err", err)
 			}
 		}()
 	}
@@ -136,8 +305,8 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if _, err := io.Copy(scmd.Stdout, stdout); err != nil {
-				log.Errorf("gitServiceHandler: failed to copy stdout: %v", err)
+			if _, err := io.Copy(&countingWriter{scmd.Stdout, &bytesOut}, stdout); err != nil && ctx.Err() == nil {
+				logger.Error("failed to copy stdout", "err", err)
 			}
 		}()
 	}
@@ -147,8 +316,9 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if _, erro := io.Copy(scmd.Stderr, stderr); err != nil {
-				log.Errorf("gitServiceHandler: failed to copy stderr: %v", erro)
+			w := io.MultiWriter(stderrTail, &countingWriter{scmd.Stderr, &bytesOut})
+			if _, err := io.Copy(w, stderr); err != nil && ctx.Err() == nil {
+				logger.Error("failed to copy stderr", "err", err)
 			}
 		}()
 	}
@@ -159,12 +329,23 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 	wg.Wait()
 
 	err = cmd.Wait()
-	if err != nil && errors.Is(err, os.ErrNotExist) {
+	if aerr, ok := abortErr.Load().(error); ok {
+		if scmd.Stdout != nil {
+			if err := writeSidebandError(scmd.Stdout, aerr.Error()); err != nil {
+				logger.Error("failed to write abort error", "err", err)
+			}
+		}
+		return aerr
+	} else if err != nil && timeoutCtx != nil && errors.Is(context.Cause(timeoutCtx), ErrServiceTimeout) {
+		return ErrServiceTimeout
+	} else if err != nil && errors.Is(err, os.ErrNotExist) {
 		return ErrInvalidRepo
 	} else if err != nil {
 		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
-			return fmt.Errorf("%s: %s", exitErr, exitErr.Stderr)
+		if errors.As(err, &exitErr) {
+			serr := &ServiceError{Err: exitErr, Stderr: stderrTail.String()}
+			logger.Error("git command failed", "exit_code", serr.ExitCode(), "stderr", serr.Stderr)
+			return serr
 		}
 
 		return err
@@ -182,8 +363,212 @@ type ServiceCommand struct {
 	Env    []string
 	Args   []string
 
+	// Timeout is the maximum duration the git process is allowed to run.
+	// A zero value means no timeout.
+	Timeout time.Duration
+
+	// ProtocolVersion, if set, forces the git protocol version used by the
+	// service, regardless of what the client asks for. It can be 0, 1, or 2,
+	// and nil uses the version requested by the client. Protocol v2 only
+	// affects upload-pack and ls-refs, so set it to 0 or 1 to disable v2 for
+	// clients that don't handle it.
+	ProtocolVersion *int
+
+	// DisableFilter disables partial clones.
+	DisableFilter bool
+
+	// AllowedFilters, if not empty, restricts the partial clone filters
+	// clients can use. Entries are filter kinds such as "blob:none",
+	// "blob:limit", or "sparse:oid". A "tree:<depth>" entry allows tree
+	// filters up to the given depth.
+	AllowedFilters []string
+
+	// DenyNonFastForward rejects pushes that are not fast-forwards.
+	DenyNonFastForward bool
+
+	// WriteBitmaps writes reachability bitmaps when a push triggers an
+	// automatic repack of the repository.
+	WriteBitmaps bool
+
+	// Pack tunes the packs git writes, the ones upload-pack sends and the
+	// ones the automatic repack after a push writes.
+	Pack git.PackOptions
+
+	// MaxPackBytes is the maximum number of bytes the client is allowed to
+	// send to the git process. The git process is killed once the limit is
+	// exceeded. A zero value means no limit.
+	MaxPackBytes int64
+
+	// Quota, if set, is consulted as data arrives from the client. The git
+	// process is killed and the error is sent to the client on the sideband
+	// once the quota is exceeded.
+	Quota QuotaChecker
+
+	// Repo is the name of the repository, passed to Quota.
+	Repo string
+
+	// OnStart, if set, is called when the command starts. OnComplete is
+	// always called after it.
+	OnStart func()
+
+	// OnComplete, if set, is called once the command finishes, even if it
+	// fails, with the number of bytes transferred.
+	OnComplete func(stats ServiceStats)
+
 	// Modifier functions
 	CmdFunc func(*exec.Cmd)
+
+	// KillGracePeriod is how long to wait for the git process to exit after
+	// sending it SIGTERM once the context is done, before killing it. A zero
+	// value uses DefaultKillGracePeriod.
+	KillGracePeriod time.Duration
+
+	// PostStartFunc, if set, is called with the PID of the git process right
+	// after it starts. This can be used to apply resource limits that need
+	// the PID, like cgroups or process priority.
+	PostStartFunc func(pid int)
+}
+
+// filterKinds are the partial clone filter kinds known to git.
+var filterKinds = map[string]struct{}{
+	"blob:none":   {},
+	"blob:limit":  {},
+	"tree":        {},
+	"sparse:oid":  {},
+	"object:type": {},
+	"combine":     {},
+}
+
+// filterArgs returns the git config arguments that control partial clone
+// filters. Git rejects disallowed filters itself during negotiation.
+func filterArgs(scmd ServiceCommand) ([]string, error) {
+	if scmd.DisableFilter {
+		return []string{"-c", "uploadpack.allowFilter=false"}, nil
+	}
+
+	// Enable partial clones
+	args := []string{"-c", "uploadpack.allowFilter=true"}
+	if len(scmd.AllowedFilters) == 0 {
+		return args, nil
+	}
+
+	args = append(args, "-c", "uploadpackfilter.allow=false")
+	for _, f := range scmd.AllowedFilters {
+		kind, depth, hasDepth := strings.Cut(f, ":")
+		if kind == "tree" && hasDepth {
+			if _, err := strconv.ParseUint(depth, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid tree filter depth: %q", f)
+			}
+			args = append(args, "-c", "uploadpackfilter.tree.maxDepth="+depth)
+		} else {
+			kind = f
+		}
+		if _, ok := filterKinds[kind]; !ok {
+			return nil, fmt.Errorf("unsupported filter: %q", f)
+		}
+		args = append(args, "-c", fmt.Sprintf("uploadpackfilter.%s.allow=true", kind))
+	}
+
+	return args, nil
+}
+
+// ServiceStats holds transfer statistics of a git service command.
+type ServiceStats struct {
+	// BytesIn is the number of bytes read from the client and written to
+	// the git process.
+	BytesIn int64
+	// BytesOut is the number of bytes written by the git process to the
+	// client, including stderr.
+	BytesOut int64
+	// Duration is how long the command took to run.
+	Duration time.Duration
+	// Err is the error the command failed with, nil if it succeeded.
+	Err error
+}
+
+// maxBytesReader returns ErrPackTooLarge once more than remaining bytes have
+// been read from the underlying reader.
+type maxBytesReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+// Read implements io.Reader.
+func (l *maxBytesReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrPackTooLarge
+	}
+	// Read one byte past the limit to detect when it's exceeded.
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return 0, ErrPackTooLarge
+	}
+	return n, err
+}
+
+// setGracefulCancel gives git a chance to clean up, e.g. temporary pack
+// files, before killing it when the command's context is done.
+func setGracefulCancel(cmd *exec.Cmd, grace time.Duration) {
+	if grace <= 0 {
+		grace = DefaultKillGracePeriod
+	}
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = grace
+}
+
+// stderrTailSize is the number of bytes of stderr kept for logging.
+const stderrTailSize = 4096
+
+// tailBuffer is a writer that keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+// Write implements io.Writer.
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := len(p)
+	if n >= t.max {
+		t.buf = append(t.buf[:0], p[n-t.max:]...)
+		return n, nil
+	}
+	if over := len(t.buf) + n - t.max; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	t.buf = append(t.buf, p...)
+	return n, nil
+}
+
+// String returns the buffered bytes as a string.
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.TrimSpace(string(t.buf))
+}
+
+// countingWriter counts the number of bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+// Write implements io.Writer.
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n.Add(int64(n))
+	return n, err
 }
 
 // UploadPack runs the git upload-pack protocol against the provided repo.
@@ -0,0 +1,808 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+func TestServiceTimeout(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// upload-pack advertises refs then waits for the client to send its
+	// wants, which never happens.
+	pr, pw := io.Pipe()
+	t.Cleanup(func() { pw.Close() }) //nolint: errcheck
+
+	var stdout bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- UploadPack(context.TODO(), ServiceCommand{
+			Stdin:   pr,
+			Stdout:  &stdout,
+			Dir:     repo.Path,
+			Timeout: 100 * time.Millisecond,
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrServiceTimeout) {
+			t.Errorf("UploadPack() => %v, want ErrServiceTimeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("UploadPack() did not return after timeout")
+	}
+}
+
+func TestServiceParentDeadline(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, pw := io.Pipe()
+	t.Cleanup(func() { pw.Close() }) //nolint: errcheck
+
+	// The parent deadline expires long before the service timeout.
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- UploadPack(ctx, ServiceCommand{
+			Stdin:   pr,
+			Stdout:  io.Discard,
+			Dir:     repo.Path,
+			Timeout: time.Minute,
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || errors.Is(err, ErrServiceTimeout) {
+			t.Errorf("UploadPack() => %v, want a non-timeout error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("UploadPack() did not return after the parent deadline")
+	}
+}
+
+func TestServiceStats(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A flush packet tells upload-pack the client doesn't want anything.
+	stdin := strings.NewReader("0000")
+	var stdout bytes.Buffer
+	var stats ServiceStats
+	var called int
+	if err := UploadPack(context.TODO(), ServiceCommand{
+		Stdin:  stdin,
+		Stdout: &stdout,
+		Dir:    repo.Path,
+		OnComplete: func(s ServiceStats) {
+			called++
+			stats = s
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if called != 1 {
+		t.Fatalf("OnComplete called %d times, want 1", called)
+	}
+	if stats.BytesIn != 4 {
+		t.Errorf("BytesIn = %d, want 4", stats.BytesIn)
+	}
+	if stats.BytesOut != int64(stdout.Len()) {
+		t.Errorf("BytesOut = %d, want %d", stats.BytesOut, stdout.Len())
+	}
+	if stats.Duration <= 0 {
+		t.Errorf("Duration = %v, want > 0", stats.Duration)
+	}
+}
+
+func TestServiceStatsOnError(t *testing.T) {
+	var called bool
+	err := UploadPack(context.TODO(), ServiceCommand{
+		Stdout: io.Discard,
+		Dir:    t.TempDir(),
+		OnComplete: func(ServiceStats) {
+			called = true
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error for non repository directory")
+	}
+	if !called {
+		t.Error("OnComplete was not called on error")
+	}
+}
+
+func TestRegisterServiceHandler(t *testing.T) {
+	bundleService := Service("git-bundle-serve")
+	if err := bundleService.Handler(context.TODO(), ServiceCommand{}); err == nil {
+		t.Fatal("expected error for unsupported service")
+	}
+
+	var called bool
+	RegisterServiceHandler(bundleService, func(context.Context, ServiceCommand) error {
+		called = true
+		return nil
+	})
+	t.Cleanup(func() { RegisterServiceHandler(bundleService, nil) })
+
+	if _, ok := LookupServiceHandler(bundleService); !ok {
+		t.Fatal("expected registered handler")
+	}
+	if err := bundleService.Handler(context.TODO(), ServiceCommand{}); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("registered handler was not called")
+	}
+
+	RegisterServiceHandler(bundleService, nil)
+	if _, ok := LookupServiceHandler(bundleService); ok {
+		t.Error("expected handler to be removed")
+	}
+}
+
+func TestRegisterServiceHandlerOverride(t *testing.T) {
+	errFake := errors.New("fake upload-pack")
+	RegisterServiceHandler(UploadPackService, func(context.Context, ServiceCommand) error {
+		return errFake
+	})
+	t.Cleanup(func() { RegisterServiceHandler(UploadPackService, nil) })
+
+	if err := UploadPackService.Handler(context.TODO(), ServiceCommand{}); !errors.Is(err, errFake) {
+		t.Errorf("Handler() => %v, want %v", err, errFake)
+	}
+}
+
+func TestReceivePackMaxPackBytes(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A single pkt-line that is longer than the limit.
+	stdin := io.MultiReader(strings.NewReader("0fa0"), strings.NewReader(strings.Repeat("a", 4000)))
+	err = ReceivePack(context.TODO(), ServiceCommand{
+		Stdin:        stdin,
+		Stdout:       io.Discard,
+		Dir:          repo.Path,
+		MaxPackBytes: 100,
+	})
+	if !errors.Is(err, ErrPackTooLarge) {
+		t.Errorf("ReceivePack() => %v, want ErrPackTooLarge", err)
+	}
+}
+
+func TestMaxBytesReader(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		max  int64
+		err  error
+	}{
+		{name: "under limit", data: "hello", max: 10},
+		{name: "at limit", data: "hello", max: 5},
+		{name: "over limit", data: "hello world", max: 5, err: ErrPackTooLarge},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := &maxBytesReader{r: strings.NewReader(c.data), remaining: c.max}
+			_, err := io.ReadAll(r)
+			if !errors.Is(err, c.err) {
+				t.Errorf("ReadAll() => %v, want %v", err, c.err)
+			}
+		})
+	}
+}
+
+func TestSetGitBinary(t *testing.T) {
+	t.Cleanup(func() { SetGitBinary("") }) //nolint: errcheck
+
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "git")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\n"), 0o755); err != nil { //nolint: gosec
+		t.Fatal(err)
+	}
+	noexec := filepath.Join(dir, "noexec")
+	if err := os.WriteFile(noexec, []byte("#!/bin/sh\n"), 0o644); err != nil { //nolint: gosec
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "executable", path: exe, want: exe},
+		{name: "empty resets", path: "", want: "git"},
+		{name: "missing", path: filepath.Join(dir, "missing"), want: "git", wantErr: true},
+		{name: "not executable", path: noexec, want: "git", wantErr: true},
+		{name: "directory", path: dir, want: "git", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := SetGitBinary(c.path)
+			if (err != nil) != c.wantErr {
+				t.Errorf("SetGitBinary(%q) => %v, wantErr %t", c.path, err, c.wantErr)
+			}
+			if got := GitBinary(); got != c.want {
+				t.Errorf("GitBinary() => %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestServiceProtocolVersion(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version := func(v int) *int { return &v }
+	cases := []struct {
+		name    string
+		version *int
+		flag    string
+		env     string
+		wantErr bool
+	}{
+		{name: "client"},
+		{name: "0", version: version(0), flag: "protocol.version=0", env: "GIT_PROTOCOL=version=0"},
+		{name: "1", version: version(1), flag: "protocol.version=1", env: "GIT_PROTOCOL=version=1"},
+		{name: "2", version: version(2), flag: "protocol.version=2", env: "GIT_PROTOCOL=version=2"},
+		{name: "3", version: version(3), wantErr: true},
+		{name: "-1", version: version(-1), wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var args, env []string
+			err := UploadPack(context.TODO(), ServiceCommand{
+				Stdin:           strings.NewReader("0000"),
+				Stdout:          io.Discard,
+				Dir:             repo.Path,
+				ProtocolVersion: c.version,
+				CmdFunc: func(cmd *exec.Cmd) {
+					args = cmd.Args
+					env = cmd.Env
+				},
+			})
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("expected error for unsupported protocol version")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var flags []string
+			for i, arg := range args {
+				if arg == "-c" && i+1 < len(args) && strings.HasPrefix(args[i+1], "protocol.version=") {
+					flags = append(flags, args[i+1])
+				}
+			}
+			var protos []string
+			for _, e := range env {
+				if strings.HasPrefix(e, "GIT_PROTOCOL=") {
+					protos = append(protos, e)
+				}
+			}
+
+			if c.flag == "" {
+				if len(flags) > 0 {
+					t.Errorf("unexpected protocol flags: %v", flags)
+				}
+			} else if len(flags) != 1 || flags[0] != c.flag {
+				t.Errorf("protocol flags = %v, want [%s]", flags, c.flag)
+			}
+			if c.env == "" {
+				if len(protos) > 0 {
+					t.Errorf("unexpected protocol env: %v", protos)
+				}
+			} else if len(protos) == 0 || protos[len(protos)-1] != c.env {
+				t.Errorf("protocol env = %v, want %s last", protos, c.env)
+			}
+		})
+	}
+}
+
+func TestServiceForceProtocolV0(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCommits(t, repo.Path)
+
+	v0 := 0
+	for _, force := range []*int{nil, &v0} {
+		t.Run(fmt.Sprintf("forced=%t", force != nil), func(t *testing.T) {
+			var stdout bytes.Buffer
+			if err := UploadPack(context.TODO(), ServiceCommand{
+				Stdout: &stdout,
+				Dir:    repo.Path,
+				Args:   []string{"--advertise-refs"},
+				// The client asks for protocol v2.
+				Env:             []string{"GIT_PROTOCOL=version=2"},
+				ProtocolVersion: force,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			v2 := strings.Contains(stdout.String(), "version 2")
+			if v2 == (force != nil) {
+				t.Errorf("protocol v2 advertised = %t, want %t: %q", v2, force == nil, stdout.String())
+			}
+		})
+	}
+}
+
+// testCommits creates two commits on the main branch of the repository at
+// dir and returns their hashes.
+func testCommits(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	tree := run("mktree")
+	first := run("commit-tree", tree, "-m", "first")
+	second := run("commit-tree", tree, "-p", first, "-m", "second")
+	run("update-ref", "refs/heads/main", second)
+
+	return first, second
+}
+
+func TestReceivePackDenyNonFastForward(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := testCommits(t, repo.Path)
+
+	// Rewind main to its parent. The objects already exist, so send an
+	// empty pack.
+	pkt := fmt.Sprintf("%s %s refs/heads/main\x00report-status\n", second, first)
+	pack := []byte("PACK\x00\x00\x00\x02\x00\x00\x00\x00")
+	sum := sha1.Sum(pack)
+	req := fmt.Sprintf("%04x%s0000%s%s", len(pkt)+4, pkt, pack, sum[:])
+
+	cases := []struct {
+		deny bool
+		want string
+	}{
+		{deny: true, want: "ng refs/heads/main non-fast-forward"},
+		{deny: false, want: "ok refs/heads/main"},
+	}
+	for _, c := range cases {
+		t.Run(strconv.FormatBool(c.deny), func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if err := ReceivePack(context.TODO(), ServiceCommand{
+				Stdin:              strings.NewReader(req),
+				Stdout:             &stdout,
+				Stderr:             &stderr,
+				Dir:                repo.Path,
+				DenyNonFastForward: c.deny,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(stdout.String(), c.want) {
+				t.Errorf("expected %q in report, got %q", c.want, stdout.String())
+			}
+			if c.deny && !strings.Contains(stderr.String(), "denying non-fast-forward") {
+				t.Errorf("expected git error on stderr, got %q", stderr.String())
+			}
+		})
+	}
+}
+
+func TestFilterArgs(t *testing.T) {
+	cases := []struct {
+		name    string
+		scmd    ServiceCommand
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: []string{"-c", "uploadpack.allowFilter=true"},
+		},
+		{
+			name: "disabled",
+			scmd: ServiceCommand{DisableFilter: true, AllowedFilters: []string{"blob:none"}},
+			want: []string{"-c", "uploadpack.allowFilter=false"},
+		},
+		{
+			name: "allowlist",
+			scmd: ServiceCommand{AllowedFilters: []string{"blob:none", "tree:1"}},
+			want: []string{
+				"-c", "uploadpack.allowFilter=true",
+				"-c", "uploadpackfilter.allow=false",
+				"-c", "uploadpackfilter.blob:none.allow=true",
+				"-c", "uploadpackfilter.tree.maxDepth=1",
+				"-c", "uploadpackfilter.tree.allow=true",
+			},
+		},
+		{
+			name:    "unknown filter",
+			scmd:    ServiceCommand{AllowedFilters: []string{"blob:all"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid depth",
+			scmd:    ServiceCommand{AllowedFilters: []string{"tree:deep"}},
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := filterArgs(c.scmd)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("filterArgs() => %v, wantErr %t", err, c.wantErr)
+			}
+			if strings.Join(got, " ") != strings.Join(c.want, " ") {
+				t.Errorf("filterArgs() => %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestUploadPackDisableFilter(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCommits(t, repo.Path)
+
+	for _, disable := range []bool{false, true} {
+		t.Run(strconv.FormatBool(disable), func(t *testing.T) {
+			var stdout bytes.Buffer
+			if err := UploadPack(context.TODO(), ServiceCommand{
+				Stdout:        &stdout,
+				Dir:           repo.Path,
+				Args:          []string{"--advertise-refs"},
+				DisableFilter: disable,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if advertised := strings.Contains(stdout.String(), " filter"); advertised == disable {
+				t.Errorf("filter advertised = %t, want %t: %q", advertised, !disable, stdout.String())
+			}
+		})
+	}
+}
+
+func TestUploadPackAllowedFilters(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, head := testCommits(t, repo.Path)
+
+	pkt := func(s string) string {
+		return fmt.Sprintf("%04x%s", len(s)+4, s)
+	}
+
+	cases := []struct {
+		filter  string
+		wantErr string
+	}{
+		{filter: "blob:none"},
+		{filter: "tree:1"},
+		{filter: "tree:2", wantErr: "max depth 1"},
+		{filter: "blob:limit=1k", wantErr: "filter 'blob:limit' not supported"},
+	}
+	for _, c := range cases {
+		t.Run(c.filter, func(t *testing.T) {
+			// A protocol v0 fetch of head using the given filter.
+			stdin := pkt("want "+head+" filter\n") + pkt("filter "+c.filter+"\n") + "0000" + pkt("done\n")
+			err := UploadPack(context.TODO(), ServiceCommand{
+				Stdin:          strings.NewReader(stdin),
+				Stdout:         io.Discard,
+				Dir:            repo.Path,
+				AllowedFilters: []string{"blob:none", "tree:1"},
+			})
+			if c.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected filter %q to be allowed: %v", c.filter, err)
+				}
+				return
+			}
+
+			var serr *ServiceError
+			if !errors.As(err, &serr) {
+				t.Fatalf("expected filter %q to be rejected, got %v", c.filter, err)
+			}
+			if !strings.Contains(serr.Stderr, c.wantErr) {
+				t.Errorf("stderr = %q, want %q", serr.Stderr, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	cases := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{name: "under", writes: []string{"ab", "cd"}, want: "abcd"},
+		{name: "wrap", writes: []string{"abcd", "ef"}, want: "cdef"},
+		{name: "large write", writes: []string{"a", "bcdefgh"}, want: "efgh"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tb := &tailBuffer{max: 4}
+			for _, w := range c.writes {
+				if n, err := tb.Write([]byte(w)); err != nil || n != len(w) {
+					t.Fatalf("Write(%q) => %d, %v", w, n, err)
+				}
+			}
+			if got := tb.String(); got != c.want {
+				t.Errorf("String() => %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestServiceLogsStderr(t *testing.T) {
+	var logs bytes.Buffer
+	logger := log.New(&logs)
+	ctx := log.WithContext(context.TODO(), logger)
+	dir := t.TempDir()
+	if err := UploadPack(ctx, ServiceCommand{
+		Stdout: io.Discard,
+		Dir:    dir,
+	}); err == nil {
+		t.Fatal("expected error for non repository directory")
+	}
+
+	out := logs.String()
+	for _, want := range []string{"git command failed", "service=git-upload-pack", "exit_code=128", "not appear to be a git repository"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in logs, got %q", want, out)
+		}
+	}
+}
+
+func TestServicePostStartFunc(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCommits(t, repo.Path)
+
+	var stdout bytes.Buffer
+	var pids []int
+	var consumed int
+	if err := UploadPack(context.TODO(), ServiceCommand{
+		Stdout: &stdout,
+		Dir:    repo.Path,
+		Args:   []string{"--advertise-refs"},
+		PostStartFunc: func(pid int) {
+			pids = append(pids, pid)
+			consumed = stdout.Len()
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pids) != 1 || pids[0] <= 0 {
+		t.Fatalf("PostStartFunc called with %v, want a single pid", pids)
+	}
+	if consumed != 0 {
+		t.Errorf("%d bytes of output consumed before PostStartFunc", consumed)
+	}
+	if stdout.Len() == 0 {
+		t.Error("expected ref advertisement")
+	}
+}
+
+func TestServicePackOptions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on windows")
+	}
+	t.Cleanup(func() { SetGitBinary("") }) //nolint: errcheck
+
+	// A fake git records its arguments.
+	dir := t.TempDir()
+	out := filepath.Join(dir, "args")
+	exe := filepath.Join(dir, "git")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\" > "+out+"\n"), 0o755); err != nil { //nolint: gosec
+		t.Fatal(err)
+	}
+	if err := SetGitBinary(exe); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		pack git.PackOptions
+		want []string
+		not  []string
+	}{
+		{
+			pack: git.PackOptions{Window: 250, Depth: 100, Threads: 2},
+			want: []string{"-c\npack.window=250\n", "-c\npack.depth=100\n", "-c\npack.threads=2\n"},
+		},
+		{
+			pack: git.PackOptions{Depth: 100},
+			want: []string{"-c\npack.depth=100\n"},
+			not:  []string{"pack.window", "pack.threads"},
+		},
+		{
+			not: []string{"pack.window", "pack.depth", "pack.threads"},
+		},
+	}
+	for _, c := range cases {
+		for _, svc := range []Service{UploadPackService, ReceivePackService} {
+			if err := svc.Handler(context.TODO(), ServiceCommand{
+				Stdout: io.Discard,
+				Dir:    dir,
+				Pack:   c.pack,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args, _, _ := strings.Cut(string(b), svc.Name()+"\n")
+			for _, want := range c.want {
+				if !strings.Contains(args, want) {
+					t.Errorf("%s %+v: expected %q before the service in %q", svc, c.pack, want, b)
+				}
+			}
+			for _, not := range c.not {
+				if strings.Contains(args, not) {
+					t.Errorf("%s %+v: unexpected %q in %q", svc, c.pack, not, b)
+				}
+			}
+		}
+	}
+}
+
+func TestServiceKillGracePeriod(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported on windows")
+	}
+
+	cases := []struct {
+		name   string
+		script string
+		grace  time.Duration
+		min    time.Duration
+		max    time.Duration
+	}{
+		// The process exits on SIGTERM, before the grace period is over.
+		{name: "terminate", script: "trap 'exit 0' TERM; sleep 10 >/dev/null 2>&1 & wait $!", grace: 5 * time.Second, max: 3 * time.Second},
+		// The process ignores SIGTERM and gets killed after the grace period.
+		{name: "kill", script: "trap '' TERM; sleep 10 >/dev/null 2>&1 & wait $!", grace: 300 * time.Millisecond, min: 300 * time.Millisecond, max: 3 * time.Second},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			start := time.Now()
+			UploadPack(context.TODO(), ServiceCommand{ //nolint: errcheck
+				Dir:             t.TempDir(),
+				Timeout:         100 * time.Millisecond,
+				KillGracePeriod: c.grace,
+				CmdFunc: func(cmd *exec.Cmd) {
+					cmd.Path = "/bin/sh"
+					cmd.Args = []string{"sh", "-c", c.script}
+				},
+			})
+			elapsed := time.Since(start)
+			if elapsed < c.min || elapsed > c.max {
+				t.Errorf("command took %v, want between %v and %v", elapsed, c.min, c.max)
+			}
+		})
+	}
+}
+
+func TestServiceError(t *testing.T) {
+	err := UploadPack(context.TODO(), ServiceCommand{
+		Stdout: io.Discard,
+		Dir:    t.TempDir(),
+	})
+
+	var serr *ServiceError
+	if !errors.As(err, &serr) {
+		t.Fatalf("UploadPack() => %v, want ServiceError", err)
+	}
+	if code := serr.ExitCode(); code != 128 {
+		t.Errorf("ExitCode() => %d, want 128", code)
+	}
+	if !strings.Contains(serr.Stderr, "not appear to be a git repository") {
+		t.Errorf("unexpected stderr: %q", serr.Stderr)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Error("expected ServiceError to wrap the exit error")
+	}
+}
+
+func TestWaitServices(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// upload-pack waits for the client to send its wants until stdin is
+	// closed.
+	pr, pw := io.Pipe()
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- UploadPack(context.TODO(), ServiceCommand{
+			Stdin:         pr,
+			Stdout:        io.Discard,
+			Dir:           repo.Path,
+			PostStartFunc: func(int) { close(started) },
+		})
+	}()
+	<-started
+
+	if n := RunningServices(); n != 1 {
+		t.Errorf("RunningServices() => %d, want 1", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	if err := WaitServices(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitServices() with a running service => %v, want context.DeadlineExceeded", err)
+	}
+
+	pw.Close() //nolint: errcheck
+	<-done
+
+	ctx, cancel = context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	if err := WaitServices(ctx); err != nil {
+		t.Errorf("WaitServices() => %v, want nil", err)
+	}
+	if n := RunningServices(); n != 0 {
+		t.Errorf("RunningServices() => %d, want 0", n)
+	}
+}
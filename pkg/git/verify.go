@@ -0,0 +1,403 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+var (
+	// ErrUnsignedCommit is returned when a commit isn't signed by an allowed
+	// signer.
+	ErrUnsignedCommit = errors.New("commit is not signed by an allowed signer")
+
+	// ErrInvalidCommitMessage is returned when a commit subject doesn't match
+	// the required pattern.
+	ErrInvalidCommitMessage = errors.New("commit message doesn't match the required pattern")
+
+	// ErrPathLocked is returned when a push changes a file that is locked by
+	// another user.
+	ErrPathLocked = errors.New("path is locked by another user")
+
+	// ErrProtectedBranch is returned when a push updates a protected branch
+	// it isn't allowed to.
+	ErrProtectedBranch = errors.New("branch is protected")
+
+	// ErrProtectedTag is returned when a push moves or deletes a protected
+	// tag.
+	ErrProtectedTag = errors.New("tag is protected")
+
+	// ErrInvalidSignatureFormat is returned when parsing an unknown
+	// signature format.
+	ErrInvalidSignatureFormat = errors.New("invalid signature format, must be one of: gpg, ssh, any")
+
+	// ErrInvalidGPGKey is returned when an OpenPGP public key can't be read.
+	ErrInvalidGPGKey = errors.New("invalid OpenPGP public key")
+)
+
+// CommitSubject is a commit hash and its subject line.
+type CommitSubject struct {
+	Hash    string
+	Subject string
+}
+
+// NewCommits returns the commits reachable from rev that aren't reachable
+// from any ref in the repository at dir. In a pre-receive hook, these are the
+// commits a push introduces.
+func NewCommits(ctx context.Context, dir string, rev string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, GitBinary(), "rev-list", rev, "--not", "--all")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(out)), nil
+}
+
+// PushedCommits returns the number of commits a push of the ref updates refs
+// introduced to the repository at dir. It must be called after the refs are
+// updated, in a post-receive hook: these are the commits reachable from the
+// new values that aren't reachable from the old values, nor from the refs the
+// push didn't update.
+func PushedCommits(ctx context.Context, dir string, refs []hooks.HookArg) (int64, error) {
+	var revs, nots, excludes []string
+	for _, arg := range refs {
+		if !git.IsZeroHash(arg.NewSha) {
+			revs = append(revs, arg.NewSha)
+		}
+		if !git.IsZeroHash(arg.OldSha) {
+			nots = append(nots, arg.OldSha)
+		}
+		excludes = append(excludes, "--exclude="+arg.RefName)
+	}
+	if len(revs) == 0 {
+		return 0, nil
+	}
+
+	args := append([]string{"rev-list", "--count"}, revs...)
+	args = append(args, "--not")
+	args = append(args, nots...)
+	args = append(args, excludes...)
+	// Unlike --all, --glob doesn't include HEAD, which may point to one of the
+	// updated refs.
+	args = append(args, "--glob=refs/*")
+	cmd := exec.CommandContext(ctx, GitBinary(), args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+}
+
+// NewCommitSubjects is like NewCommits but also returns the subject line of
+// each commit.
+func NewCommitSubjects(ctx context.Context, dir string, rev string) ([]CommitSubject, error) {
+	cmd := exec.CommandContext(ctx, GitBinary(), "log", "--no-show-signature", "--format=%H %s", rev, "--not", "--all")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []CommitSubject
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		hash, subject, _ := strings.Cut(line, " ")
+		commits = append(commits, CommitSubject{Hash: hash, Subject: subject})
+	}
+
+	return commits, nil
+}
+
+// IsAncestor reports whether ancestor is reachable from commit, that is
+// whether moving a ref from ancestor to commit is a fast-forward.
+func IsAncestor(ctx context.Context, dir string, ancestor string, commit string) (bool, error) {
+	cmd := exec.CommandContext(ctx, GitBinary(), "merge-base", "--is-ancestor", ancestor, commit)
+	cmd.Dir = dir
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// ChangedPaths returns the paths changed by each of commits compared to their
+// first parent. Merge commits are skipped.
+func ChangedPaths(ctx context.Context, dir string, commits []string) ([]string, error) {
+	if len(commits) == 0 {
+		return nil, nil
+	}
+
+	cmd := exec.CommandContext(ctx, GitBinary(), "diff-tree", "--stdin", "-r", "--root", "--no-commit-id", "--name-only", "-z")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(strings.Join(commits, "\n") + "\n")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	seen := map[string]struct{}{}
+	for _, p := range strings.Split(string(out), "\x00") {
+		if _, ok := seen[p]; ok || p == "" {
+			continue
+		}
+		seen[p] = struct{}{}
+		paths = append(paths, p)
+	}
+
+	return paths, nil
+}
+
+// SignatureFormat is a format of commit signatures.
+type SignatureFormat string
+
+const (
+	// SignatureFormatAny accepts both SSH and OpenPGP signatures.
+	SignatureFormatAny SignatureFormat = "any"
+	// SignatureFormatSSH accepts SSH signatures only.
+	SignatureFormatSSH SignatureFormat = "ssh"
+	// SignatureFormatGPG accepts OpenPGP signatures only.
+	SignatureFormatGPG SignatureFormat = "gpg"
+)
+
+// ParseSignatureFormat parses a signature format, an empty string is
+// SignatureFormatAny.
+func ParseSignatureFormat(s string) (SignatureFormat, error) {
+	switch f := SignatureFormat(strings.ToLower(s)); f {
+	case "":
+		return SignatureFormatAny, nil
+	case SignatureFormatAny, SignatureFormatSSH, SignatureFormatGPG:
+		return f, nil
+	default:
+		return "", ErrInvalidSignatureFormat
+	}
+}
+
+// VerifyOptions are the keys and formats VerifyCommit accepts signatures
+// from.
+type VerifyOptions struct {
+	// AllowedSigners is the path of the SSH allowed signers file. SSH
+	// signatures are rejected when it's empty.
+	AllowedSigners string
+
+	// GPGHome is the GnuPG home directory holding the trusted OpenPGP keys,
+	// see ImportGPGKeys. OpenPGP signatures are rejected when it's empty.
+	GPGHome string
+
+	// Format is the signature format accepted, SignatureFormatAny when it's
+	// empty.
+	Format SignatureFormat
+}
+
+// VerifyCommit checks that commit is signed by one of the keys of opts in
+// one of the accepted formats. The returned error wraps ErrUnsignedCommit and
+// includes git's output when the signature is missing or untrusted.
+//
+// OpenPGP signatures are only checked against the keyring in opts.GPGHome,
+// never against the keyring of the server user.
+func VerifyCommit(ctx context.Context, dir string, opts VerifyOptions, commit string) error {
+	sigType, err := commitSignatureType(ctx, dir, commit)
+	if err != nil {
+		return err
+	}
+
+	var format SignatureFormat
+	switch sigType {
+	case "":
+		return fmt.Errorf("%w: %s: no signature found", ErrUnsignedCommit, commit)
+	case sshSignatureType:
+		format = SignatureFormatSSH
+	case pgpSignatureType:
+		format = SignatureFormatGPG
+	default:
+		return fmt.Errorf("%w: %s: only SSH and OpenPGP signatures are accepted, got %s", ErrUnsignedCommit, commit, sigType)
+	}
+	if opts.Format != "" && opts.Format != SignatureFormatAny && opts.Format != format {
+		return fmt.Errorf("%w: %s: only %s signatures are accepted, got %s", ErrUnsignedCommit, commit, opts.Format, format)
+	}
+
+	args := []string{"-c", "gpg.minTrustLevel=fully"}
+	env := os.Environ()
+	switch format {
+	case SignatureFormatSSH:
+		if opts.AllowedSigners == "" {
+			return fmt.Errorf("%w: %s: no SSH signers are allowed", ErrUnsignedCommit, commit)
+		}
+		args = append(args, "-c", "gpg.ssh.allowedSignersFile="+opts.AllowedSigners)
+	case SignatureFormatGPG:
+		if opts.GPGHome == "" {
+			return fmt.Errorf("%w: %s: no OpenPGP signers are allowed", ErrUnsignedCommit, commit)
+		}
+		env = append(env, "GNUPGHOME="+opts.GPGHome)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, GitBinary(), append(args, "verify-commit", commit)...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return err
+		}
+
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s: %s", ErrUnsignedCommit, commit, msg)
+		}
+		return fmt.Errorf("%w: %s", ErrUnsignedCommit, commit)
+	}
+
+	return nil
+}
+
+// gpgKeyArmor is the armor header of OpenPGP public keys.
+const gpgKeyArmor = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+
+// IsGPGKey reports whether key is an armored OpenPGP public key.
+func IsGPGKey(key string) bool {
+	return strings.HasPrefix(strings.TrimSpace(key), gpgKeyArmor)
+}
+
+// GPGKeyFingerprint returns the fingerprint of the primary key of an armored
+// OpenPGP public key. It fails if key isn't a valid public key.
+func GPGKeyFingerprint(ctx context.Context, key string) (string, error) {
+	if !IsGPGKey(key) {
+		return "", ErrInvalidGPGKey
+	}
+
+	home, err := os.MkdirTemp("", "soft-serve-gnupg-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(home) //nolint: errcheck
+
+	out, err := gpg(ctx, home, strings.NewReader(key), "--with-colons", "--import-options", "show-only", "--import")
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidGPGKey, err)
+	}
+
+	fprs := gpgFingerprints(out)
+	if len(fprs) != 1 {
+		return "", fmt.Errorf("%w: expected one key, got %d", ErrInvalidGPGKey, len(fprs))
+	}
+
+	return fprs[0], nil
+}
+
+// ImportGPGKeys writes armored OpenPGP public keys to the keyring of the
+// GnuPG home directory at home, trusting them to sign commits verified by
+// VerifyCommit. Importing keys with gpg would start a gpg-agent, the keys are
+// converted to a keyring file instead.
+func ImportGPGKeys(ctx context.Context, home string, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var keyring bytes.Buffer
+	for _, key := range keys {
+		out, err := gpg(ctx, home, strings.NewReader(key), "--dearmor")
+		if err != nil {
+			return err
+		}
+		keyring.WriteString(out)
+	}
+
+	if err := os.WriteFile(filepath.Join(home, "pubring.gpg"), keyring.Bytes(), 0o600); err != nil {
+		return err
+	}
+
+	// The keyring only holds the trusted keys, their signatures are trusted
+	// without signatures between the keys.
+	return os.WriteFile(filepath.Join(home, "gpg.conf"), []byte("trust-model always\n"), 0o600)
+}
+
+// gpg runs gpg with the GnuPG home directory at home.
+func gpg(ctx context.Context, home string, stdin io.Reader, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "gpg", append([]string{"--batch", "--no-tty", "--homedir", home}, args...)...)
+	cmd.Stdin = stdin
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("gpg: %s", msg)
+		}
+		return "", fmt.Errorf("gpg: %w", err)
+	}
+
+	return string(out), nil
+}
+
+// gpgFingerprints returns the fingerprints of the primary keys of gpg
+// --with-colons output.
+func gpgFingerprints(out string) []string {
+	var fprs []string
+	var primary bool
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, ":")
+		switch fields[0] {
+		case "pub":
+			primary = true
+		case "sub":
+			primary = false
+		case "fpr":
+			if primary && len(fields) > 9 {
+				fprs = append(fprs, fields[9])
+				primary = false
+			}
+		}
+	}
+
+	return fprs
+}
+
+// Armor types of commit signatures.
+const (
+	sshSignatureType = "SSH SIGNATURE"
+	pgpSignatureType = "PGP SIGNATURE"
+)
+
+// commitSignatureType returns the armor type of the signature of commit, such
+// as "SSH SIGNATURE" or "PGP SIGNATURE", or an empty string if commit isn't
+// signed.
+func commitSignatureType(ctx context.Context, dir string, commit string) (string, error) {
+	cmd := exec.CommandContext(ctx, GitBinary(), "cat-file", "commit", commit)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	// The signature is in a header and the headers end at the first empty
+	// line.
+	headers, _, _ := strings.Cut(string(out), "\n\n")
+	for _, line := range strings.Split(headers, "\n") {
+		for _, h := range []string{"gpgsig ", "gpgsig-sha256 "} {
+			if armor, ok := strings.CutPrefix(line, h); ok {
+				armor = strings.TrimPrefix(armor, "-----BEGIN ")
+				return strings.TrimSuffix(armor, "-----"), nil
+			}
+		}
+	}
+
+	return "", nil
+}
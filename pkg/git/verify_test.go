@@ -0,0 +1,362 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+func TestNewCommits(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := testCommits(t, repo.Path)
+
+	// Both commits are reachable from main already.
+	commits, err := NewCommits(context.Background(), repo.Path, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 0 {
+		t.Errorf("NewCommits() = %v, want none", commits)
+	}
+
+	cmd := exec.Command("git", "update-ref", "refs/heads/main", first)
+	cmd.Dir = repo.Path
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git update-ref: %v: %s", err, out)
+	}
+
+	commits, err = NewCommits(context.Background(), repo.Path, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 || commits[0] != second {
+		t.Errorf("NewCommits() = %v, want [%s]", commits, second)
+	}
+}
+
+func TestPushedCommits(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := testCommits(t, repo.Path)
+	for _, args := range [][]string{
+		{"update-ref", "refs/heads/feature", first},
+		{"symbolic-ref", "HEAD", "refs/heads/main"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo.Path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	zero := strings.Repeat("0", 40)
+	cases := []struct {
+		name string
+		refs []hooks.HookArg
+		want int64
+	}{
+		{"update", []hooks.HookArg{{OldSha: first, NewSha: second, RefName: "refs/heads/main"}}, 1},
+		{"create", []hooks.HookArg{{OldSha: zero, NewSha: second, RefName: "refs/heads/main"}}, 1},
+		{"create all", []hooks.HookArg{
+			{OldSha: zero, NewSha: second, RefName: "refs/heads/main"},
+			{OldSha: zero, NewSha: first, RefName: "refs/heads/feature"},
+		}, 2},
+		{"delete", []hooks.HookArg{{OldSha: first, NewSha: zero, RefName: "refs/heads/old"}}, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n, err := PushedCommits(context.Background(), repo.Path, c.refs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != c.want {
+				t.Errorf("PushedCommits() = %d, want %d", n, c.want)
+			}
+		})
+	}
+}
+
+func TestVerifyCommit(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	tmp := t.TempDir()
+	keygen := func(name string) string {
+		path := filepath.Join(tmp, name)
+		if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "", "-f", path).CombinedOutput(); err != nil {
+			t.Fatalf("ssh-keygen: %v: %s", err, out)
+		}
+		return path
+	}
+	signer, other := keygen("signer"), keygen("other")
+
+	repo, err := git.Init(filepath.Join(tmp, "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit := func(args ...string) string {
+		args = append([]string{
+			"-c", "gpg.format=ssh",
+			"commit-tree", "4b825dc642cb6eb9a060e54bf8d69288fbee4904", "-m", "test",
+		}, args...)
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo.Path
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git commit-tree: %v", err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	// Make sure the empty tree exists.
+	cmd := exec.Command("git", "mktree")
+	cmd.Dir = repo.Path
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := os.ReadFile(signer + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed := filepath.Join(tmp, "allowed_signers")
+	if err := os.WriteFile(allowed, []byte(`test@example.com namespaces="git" `+string(pub)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ssh := VerifyOptions{AllowedSigners: allowed}
+	cases := []struct {
+		name   string
+		commit string
+		opts   VerifyOptions
+		ok     bool
+	}{
+		{"unsigned", commit(), ssh, false},
+		{"untrusted", commit("-S" + other), ssh, false},
+		{"signed", commit("-S" + signer), ssh, true},
+		{"ssh only", commit("-S" + signer), VerifyOptions{AllowedSigners: allowed, Format: SignatureFormatSSH}, true},
+		{"gpg only", commit("-S" + signer), VerifyOptions{AllowedSigners: allowed, Format: SignatureFormatGPG}, false},
+	}
+
+	// An OpenPGP signature made with a key the server user trusts must not
+	// bypass the allowed signers.
+	if _, err := exec.LookPath("gpg"); err == nil {
+		gnupgHome, err := os.MkdirTemp("", "gnupg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			exec.Command("gpgconf", "--homedir", gnupgHome, "--kill", "gpg-agent").Run() //nolint: errcheck
+			os.RemoveAll(gnupgHome)                                                      //nolint: errcheck
+		})
+		t.Setenv("GNUPGHOME", gnupgHome)
+		if out, err := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", "test@example.com", "ed25519", "sign", "never").CombinedOutput(); err != nil {
+			t.Fatalf("gpg: %v: %s", err, out)
+		}
+		cmd := exec.Command("git", "-c", "gpg.format=openpgp",
+			"commit-tree", "4b825dc642cb6eb9a060e54bf8d69288fbee4904", "-m", "test", "-Stest@example.com")
+		cmd.Dir = repo.Path
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git commit-tree: %v", err)
+		}
+		gpgCommit := strings.TrimSpace(string(out))
+
+		// Git itself trusts the signature.
+		cmd = exec.Command("git", "verify-commit", gpgCommit)
+		cmd.Dir = repo.Path
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git verify-commit: %v: %s", err, out)
+		}
+
+		// Import the public key into a keyring of its own.
+		key, err := exec.Command("gpg", "--armor", "--export", "test@example.com").Output()
+		if err != nil {
+			t.Fatalf("gpg export: %v", err)
+		}
+		if _, err := GPGKeyFingerprint(context.Background(), string(key)); err != nil {
+			t.Fatalf("GPGKeyFingerprint() => %v", err)
+		}
+		trusted := t.TempDir()
+		if err := ImportGPGKeys(context.Background(), trusted, []string{string(key)}); err != nil {
+			t.Fatalf("ImportGPGKeys() => %v", err)
+		}
+		t.Cleanup(func() {
+			exec.Command("gpgconf", "--homedir", trusted, "--kill", "all").Run() //nolint: errcheck
+		})
+		empty := t.TempDir()
+		if err := ImportGPGKeys(context.Background(), empty, nil); err != nil {
+			t.Fatalf("ImportGPGKeys() => %v", err)
+		}
+
+		cases = append(cases, []struct {
+			name   string
+			commit string
+			opts   VerifyOptions
+			ok     bool
+		}{
+			{"gpg", gpgCommit, ssh, false},
+			{"gpg untrusted", gpgCommit, VerifyOptions{AllowedSigners: allowed, GPGHome: empty}, false},
+			{"gpg signed", gpgCommit, VerifyOptions{AllowedSigners: allowed, GPGHome: trusted}, true},
+			{"gpg ssh only", gpgCommit, VerifyOptions{AllowedSigners: allowed, GPGHome: trusted, Format: SignatureFormatSSH}, false},
+		}...)
+	}
+
+	for _, c := range cases {
+		err := VerifyCommit(context.Background(), repo.Path, c.opts, c.commit)
+		if c.ok && err != nil {
+			t.Errorf("%s: VerifyCommit() => %v, want nil error", c.name, err)
+		}
+		if !c.ok && !errors.Is(err, ErrUnsignedCommit) {
+			t.Errorf("%s: VerifyCommit() => %v, want ErrUnsignedCommit", c.name, err)
+		}
+	}
+}
+
+func TestParseSignatureFormat(t *testing.T) {
+	for s, want := range map[string]SignatureFormat{
+		"":    SignatureFormatAny,
+		"any": SignatureFormatAny,
+		"SSH": SignatureFormatSSH,
+		"gpg": SignatureFormatGPG,
+	} {
+		if f, err := ParseSignatureFormat(s); err != nil || f != want {
+			t.Errorf("ParseSignatureFormat(%q) => %q, %v, want %q", s, f, err, want)
+		}
+	}
+	if _, err := ParseSignatureFormat("x509"); !errors.Is(err, ErrInvalidSignatureFormat) {
+		t.Errorf("ParseSignatureFormat(x509) => %v, want ErrInvalidSignatureFormat", err)
+	}
+}
+
+func TestNewCommitSubjects(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := testCommits(t, repo.Path)
+	cmd := exec.Command("git", "update-ref", "-d", "refs/heads/main")
+	cmd.Dir = repo.Path
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git update-ref: %v: %s", err, out)
+	}
+
+	commits, err := NewCommitSubjects(context.Background(), repo.Path, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []CommitSubject{
+		{Hash: second, Subject: "second"},
+		{Hash: first, Subject: "first"},
+	}
+	if len(commits) != len(want) {
+		t.Fatalf("NewCommitSubjects() = %v, want %v", commits, want)
+	}
+	for i := range want {
+		if commits[i] != want[i] {
+			t.Errorf("NewCommitSubjects()[%d] = %v, want %v", i, commits[i], want[i])
+		}
+	}
+}
+
+func TestIsAncestor(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := testCommits(t, repo.Path)
+	for _, c := range []struct {
+		ancestor, commit string
+		want             bool
+	}{
+		{first, second, true},
+		{second, first, false},
+		{second, second, true},
+	} {
+		got, err := IsAncestor(context.Background(), repo.Path, c.ancestor, c.commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Errorf("IsAncestor(%s, %s) = %t, want %t", c.ancestor, c.commit, got, c.want)
+		}
+	}
+}
+
+func TestChangedPaths(t *testing.T) {
+	repo, err := git.Init(filepath.Join(t.TempDir(), "repo"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(stdin string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo.Path
+		cmd.Stdin = strings.NewReader(stdin)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	a := run("a", "hash-object", "-w", "--stdin")
+	b := run("b", "hash-object", "-w", "--stdin")
+	dir := run("100644 blob "+a+"\tfile.bin\n", "mktree")
+	first := run("", "commit-tree", run("100644 blob "+a+"\ta.txt\n040000 tree "+dir+"\tassets\n", "mktree"), "-m", "first")
+	second := run("", "commit-tree", run("100644 blob "+b+"\ta.txt\n040000 tree "+dir+"\tassets\n", "mktree"), "-p", first, "-m", "second")
+
+	cases := []struct {
+		name    string
+		commits []string
+		want    []string
+	}{
+		{name: "none"},
+		{name: "root", commits: []string{first}, want: []string{"a.txt", "assets/file.bin"}},
+		{name: "modified", commits: []string{second}, want: []string{"a.txt"}},
+		{name: "deduplicated", commits: []string{second, first}, want: []string{"a.txt", "assets/file.bin"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			paths, err := ChangedPaths(context.Background(), repo.Path, c.commits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(paths, ",") != strings.Join(c.want, ",") {
+				t.Errorf("ChangedPaths() = %v, want %v", paths, c.want)
+			}
+		})
+	}
+}
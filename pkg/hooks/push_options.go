@@ -0,0 +1,87 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PushOptionNotify is the push option that disables the webhooks of a push
+// when it's false, e.g. `git push -o notify=false`.
+const PushOptionNotify = "notify"
+
+// PushOptions are the options of a push, given with `git push -o`. Options
+// without a value, such as `-o ci.skip`, have an empty value.
+type PushOptions map[string]string
+
+// ParsePushOptions parses push options of the form key or key=value. When an
+// option is given more than once, the last one wins.
+func ParsePushOptions(opts []string) PushOptions {
+	po := make(PushOptions, len(opts))
+	for _, o := range opts {
+		k, v, _ := strings.Cut(o, "=")
+		if k == "" {
+			continue
+		}
+		po[k] = v
+	}
+	return po
+}
+
+// PushOptionsFromEnv returns the push options git passes to the pre-receive
+// and post-receive hooks in GIT_PUSH_OPTION_COUNT and GIT_PUSH_OPTION_<n>.
+func PushOptionsFromEnv() PushOptions {
+	n, err := strconv.Atoi(os.Getenv("GIT_PUSH_OPTION_COUNT"))
+	if err != nil || n <= 0 {
+		return PushOptions{}
+	}
+
+	opts := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		opts = append(opts, os.Getenv("GIT_PUSH_OPTION_"+strconv.Itoa(i)))
+	}
+
+	return ParsePushOptions(opts)
+}
+
+// Has returns whether the option key was given.
+func (po PushOptions) Has(key string) bool {
+	_, ok := po[key]
+	return ok
+}
+
+// Bool returns the boolean value of the option key, or def when it's missing
+// or not a boolean. An option without a value is true.
+func (po PushOptions) Bool(key string, def bool) bool {
+	v, ok := po[key]
+	if !ok {
+		return def
+	}
+	if v == "" {
+		return true
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+
+	return b
+}
+
+// pushOptionsKey is the context key of the push options.
+type pushOptionsKey struct{}
+
+// WithPushOptions returns a context with the push options of a push.
+func WithPushOptions(ctx context.Context, po PushOptions) context.Context {
+	return context.WithValue(ctx, pushOptionsKey{}, po)
+}
+
+// PushOptionsFromContext returns the push options of a push from the context.
+func PushOptionsFromContext(ctx context.Context) PushOptions {
+	if po, ok := ctx.Value(pushOptionsKey{}).(PushOptions); ok {
+		return po
+	}
+	return PushOptions{}
+}
@@ -0,0 +1,56 @@
+package hooks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParsePushOptions(t *testing.T) {
+	po := ParsePushOptions([]string{"ci.skip", "notify=false", "title=a=b", "=x", "notify=true"})
+	want := PushOptions{"ci.skip": "", "notify": "true", "title": "a=b"}
+	if !reflect.DeepEqual(po, want) {
+		t.Fatalf("ParsePushOptions() = %v, want %v", po, want)
+	}
+
+	if !po.Has("ci.skip") || po.Has("missing") {
+		t.Error("Has() returned the wrong result")
+	}
+	if !po.Bool("ci.skip", false) {
+		t.Error("an option without a value should be true")
+	}
+	if !po.Bool("notify", false) {
+		t.Error("notify=true should be true")
+	}
+	if !po.Bool("title", true) || po.Bool("title", false) {
+		t.Error("a non boolean value should return the default")
+	}
+}
+
+func TestPushOptionsFromEnv(t *testing.T) {
+	t.Setenv("GIT_PUSH_OPTION_COUNT", "2")
+	t.Setenv("GIT_PUSH_OPTION_0", "notify=false")
+	t.Setenv("GIT_PUSH_OPTION_1", "ci.skip")
+
+	po := PushOptionsFromEnv()
+	want := PushOptions{"notify": "false", "ci.skip": ""}
+	if !reflect.DeepEqual(po, want) {
+		t.Fatalf("PushOptionsFromEnv() = %v, want %v", po, want)
+	}
+
+	t.Setenv("GIT_PUSH_OPTION_COUNT", "")
+	if po := PushOptionsFromEnv(); len(po) != 0 {
+		t.Fatalf("PushOptionsFromEnv() = %v, want no options", po)
+	}
+}
+
+func TestPushOptionsContext(t *testing.T) {
+	if po := PushOptionsFromContext(context.TODO()); po == nil || len(po) != 0 {
+		t.Fatalf("PushOptionsFromContext() = %v, want no options", po)
+	}
+
+	ctx := WithPushOptions(context.TODO(), PushOptions{"notify": "false"})
+	if po := PushOptionsFromContext(ctx); po["notify"] != "false" {
+		t.Fatalf("PushOptionsFromContext() = %v", po)
+	}
+}
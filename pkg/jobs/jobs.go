@@ -3,6 +3,11 @@ package jobs
 import (
 	"context"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/config"
 )
 
 // Job is a job that can be registered with the scheduler.
@@ -11,7 +16,7 @@ type Job struct {
 	Runner Runner
 }
 
-// Runner is a job runner.
+// Runner is a job runner. Jobs with an empty spec are not scheduled.
 type Runner interface {
 	Spec(context.Context) string
 	Func(context.Context) func()
@@ -35,3 +40,40 @@ func List() map[string]*Job {
 	defer mtx.Unlock()
 	return jobs
 }
+
+// Windowed returns fn, the scheduled function of the job name, restricted to
+// the maintenance window when the job is one of the maintenance jobs. Runs
+// falling outside the window are deferred to its start, and runs falling
+// before a deferred run happens are dropped.
+func Windowed(ctx context.Context, name string, fn func()) func() {
+	cfg := config.FromContext(ctx)
+	w, err := cfg.Jobs.Window()
+	if err != nil || w == nil || !cfg.Jobs.IsMaintenanceJob(name) {
+		return fn
+	}
+
+	logger := log.FromContext(ctx).WithPrefix("jobs." + name)
+	var deferred atomic.Bool
+	return func() {
+		now := time.Now()
+		if w.Contains(now) {
+			fn()
+			return
+		}
+
+		if !deferred.CompareAndSwap(false, true) {
+			logger.Debug("job already deferred to the maintenance window")
+			return
+		}
+
+		next := w.Next(now)
+		logger.Info("deferring job to the maintenance window", "until", next.Format(time.DateTime))
+		time.AfterFunc(time.Until(next), func() {
+			deferred.Store(false)
+			if ctx.Err() != nil {
+				return
+			}
+			fn()
+		})
+	}
+}
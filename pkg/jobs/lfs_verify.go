@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+func init() {
+	Register("lfs-verify", lfsVerify{})
+}
+
+type lfsVerify struct{}
+
+// Spec derives the spec used for LFS verification and implements Runner. The
+// job is disabled unless it's configured.
+func (lfsVerify) Spec(ctx context.Context) string {
+	cfg := config.FromContext(ctx)
+	if !cfg.LFS.Enabled {
+		return ""
+	}
+	return cfg.Jobs.LFSVerify
+}
+
+// Func runs the LFS verification job and implements Runner. Objects that fail
+// verification are reported in the logs.
+func (lfsVerify) Func(ctx context.Context) func() {
+	logger := log.FromContext(ctx).WithPrefix("jobs.lfs-verify")
+	b := backend.FromContext(ctx)
+	return func() {
+		repos, err := b.Repositories(ctx)
+		if err != nil {
+			logger.Error("error getting repositories", "err", err)
+			return
+		}
+
+		var failed int
+		for _, repo := range repos {
+			objs, err := b.VerifyLFSObjects(ctx, repo, backend.LFSVerifyOptions{})
+			if err != nil {
+				logger.Error("error verifying lfs objects", "repo", repo.Name(), "err", err)
+				continue
+			}
+
+			failed += len(objs)
+			for _, obj := range objs {
+				logger.Warn("lfs object failed verification", "repo", repo.Name(), "oid", obj.Oid, "size", obj.Size, "err", obj.Err)
+			}
+		}
+
+		if failed > 0 {
+			logger.Warn("lfs verification found failed objects, run soft lfs verify --repair to delete them", "count", failed)
+		} else {
+			logger.Info("lfs verification finished without errors", "repos", len(repos))
+		}
+	}
+}
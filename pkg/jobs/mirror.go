@@ -2,16 +2,15 @@ package jobs
 
 import (
 	"context"
-	"fmt"
-	"path/filepath"
+	"errors"
 	"runtime"
-	"strings"
+	"time"
 
 	"charm.land/log/v2"
-	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/git"
 	"github.com/charmbracelet/soft-serve/pkg/lfs"
 	"github.com/charmbracelet/soft-serve/pkg/store"
 	"github.com/charmbracelet/soft-serve/pkg/sync"
@@ -23,6 +22,10 @@ func init() {
 
 type mirrorPull struct{}
 
+// defaultMirrorTimeout is how long a single mirror update may take when no
+// timeout is configured, so a stalled upstream can't hold a worker forever.
+const defaultMirrorTimeout = time.Minute
+
 // Spec derives the spec used for pull mirrors and implements Runner.
 func (m mirrorPull) Spec(ctx context.Context) string {
 	cfg := config.FromContext(ctx)
@@ -39,6 +42,10 @@ func (m mirrorPull) Func(ctx context.Context) func() {
 	b := backend.FromContext(ctx)
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
+	timeout := time.Duration(cfg.Jobs.MirrorTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultMirrorTimeout
+	}
 	return func() {
 		repos, err := b.Repositories(ctx)
 		if err != nil {
@@ -53,7 +60,8 @@ func (m mirrorPull) Func(ctx context.Context) func() {
 
 		logger.Debug("updating mirror repos")
 		for _, repo := range repos {
-			if repo.IsMirror() {
+			// Archived mirrors are frozen.
+			if repo.IsMirror() && !repo.IsArchived() {
 				r, err := repo.Open()
 				if err != nil {
 					logger.Error("error opening repository", "repo", repo.Name(), "err", err)
@@ -61,26 +69,41 @@ func (m mirrorPull) Func(ctx context.Context) func() {
 				}
 
 				name := repo.Name()
+				mc, err := b.MirrorConfig(ctx, repo)
+				if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+					logger.Error("error getting mirror config", "repo", name, "err", err)
+					continue
+				}
+
+				// Mirrors imported before mirror configs existed don't have
+				// one, keep syncing them every time.
+				hasConfig := err == nil
+				if hasConfig && !backend.MirrorDue(mc, time.Now()) {
+					continue
+				}
+
 				wq.Add(name, func() {
 					repo := repo
 
-					cmds := []string{
-						"fetch --prune",         // fetch prune before updating remote
-						"remote update --prune", // update remote and prune remote refs
+					env, cleanup, err := b.MirrorEnv(mc.Username.String, mc.Password.String, mc.SSHKey.String)
+					if err != nil {
+						logger.Error("error preparing mirror environment", "repo", name, "err", err)
+						return
+					}
+
+					err = git.Mirror(ctx, git.ServiceCommand{
+						Dir:     r.Path,
+						Env:     env,
+						Timeout: timeout,
+					})
+					cleanup()
+					if err != nil {
+						logger.Error("error running git remote update", "repo", name, "err", err)
 					}
 
-					for _, c := range cmds {
-						args := strings.Split(c, " ")
-						cmd := git.NewCommand(args...).WithContext(ctx)
-						cmd.AddEnvs(
-							fmt.Sprintf(`GIT_SSH_COMMAND=ssh -o UserKnownHostsFile="%s" -o StrictHostKeyChecking=no -i "%s"`,
-								filepath.Join(cfg.DataPath, "ssh", "known_hosts"),
-								cfg.SSH.ClientKeyPath,
-							),
-						)
-
-						if _, err := cmd.RunInDir(r.Path); err != nil {
-							logger.Error("error running git remote update", "repo", name, "err", err)
+					if hasConfig {
+						if err := b.SetMirrorSynced(ctx, repo, err); err != nil {
+							logger.Error("error recording mirror sync", "repo", name, "err", err)
 						}
 					}
 
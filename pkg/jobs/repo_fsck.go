@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+func init() {
+	Register("repo-fsck", repoFsck{})
+}
+
+type repoFsck struct{}
+
+// Spec derives the spec used to check the integrity of repositories and
+// implements Runner.
+func (repoFsck) Spec(ctx context.Context) string {
+	cfg := config.FromContext(ctx)
+	return cfg.Jobs.RepoFsck
+}
+
+// Func runs the repository integrity check job and implements Runner. Corrupt
+// repositories are reported in the logs and in the metrics.
+func (repoFsck) Func(ctx context.Context) func() {
+	logger := log.FromContext(ctx).WithPrefix("jobs.repo-fsck")
+	b := backend.FromContext(ctx)
+	return func() {
+		repos, err := b.Repositories(ctx)
+		if err != nil {
+			logger.Error("error getting repositories", "err", err)
+			return
+		}
+
+		var corrupt int
+		for _, repo := range repos {
+			res, err := b.FsckRepository(ctx, repo, nil)
+			if errors.Is(err, proto.ErrRepoBusy) {
+				logger.Info("skipping busy repository", "repo", repo.Name())
+				continue
+			} else if err != nil {
+				logger.Error("error checking repository", "repo", repo.Name(), "err", err)
+				continue
+			}
+
+			if res.Corrupt() {
+				corrupt++
+			}
+		}
+
+		if corrupt > 0 {
+			logger.Warn("integrity check found corrupt repositories, run repo fsck to see the problems", "count", corrupt)
+		} else {
+			logger.Info("integrity check finished without errors", "repos", len(repos))
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+func init() {
+	Register("repo-gc", repoGC{})
+}
+
+type repoGC struct{}
+
+// The thresholds used when none are configured.
+const (
+	defaultGCLooseObjects = 1000
+	defaultGCInterval     = 7 * 24 * time.Hour
+)
+
+// Spec derives the spec used to garbage collect repositories and implements
+// Runner.
+func (repoGC) Spec(ctx context.Context) string {
+	cfg := config.FromContext(ctx)
+	return cfg.Jobs.RepoGC
+}
+
+// Func runs the repository garbage collection job and implements Runner.
+// Repositories are collected when they have too many loose objects, or when
+// they weren't collected for a while. The commit-graph and bitmap of the
+// repositories that opted in are then brought up to date. Repositories with a
+// push running, or a fetch for the bitmaps, are skipped until the next run.
+func (repoGC) Func(ctx context.Context) func() {
+	cfg := config.FromContext(ctx)
+	logger := log.FromContext(ctx).WithPrefix("jobs.repo-gc")
+	b := backend.FromContext(ctx)
+	loose := int64(cfg.Jobs.RepoGCLooseObjects)
+	if loose <= 0 {
+		loose = defaultGCLooseObjects
+	}
+	interval := time.Duration(cfg.Jobs.RepoGCInterval) * time.Hour
+	if interval <= 0 {
+		interval = defaultGCInterval
+	}
+	return func() {
+		repos, err := b.Repositories(ctx)
+		if err != nil {
+			logger.Error("error getting repositories", "err", err)
+			return
+		}
+
+		for _, repo := range repos {
+			name := repo.Name()
+			if enabled, err := b.GCEnabled(ctx, name); err != nil {
+				logger.Error("error getting gc setting", "repo", name, "err", err)
+			} else if enabled {
+				gcRepo(ctx, logger, b, repo, loose, interval)
+			}
+
+			if enabled, err := b.BitmapsEnabled(ctx, name); err != nil {
+				logger.Error("error getting bitmaps setting", "repo", name, "err", err)
+			} else if enabled {
+				if err := b.WriteBitmaps(ctx, repo); errors.Is(err, proto.ErrRepoBusy) {
+					logger.Info("skipping busy repository", "repo", name)
+				} else if err != nil {
+					logger.Error("error writing repository bitmaps", "repo", name, "err", err)
+				}
+			}
+		}
+	}
+}
+
+// gcRepo garbage collects a repository when it has at least loose loose
+// objects, or when it wasn't collected for interval.
+func gcRepo(ctx context.Context, logger *log.Logger, b *backend.Backend, repo proto.Repository, loose int64, interval time.Duration) {
+	name := repo.Name()
+	r, err := repo.Open()
+	if err != nil {
+		logger.Error("error opening repository", "repo", name, "err", err)
+		return
+	}
+
+	c, err := r.CountObjects()
+	if err != nil {
+		logger.Error("error counting objects", "repo", name, "err", err)
+		return
+	}
+
+	last, err := b.LastGC(ctx, name)
+	if err != nil {
+		logger.Error("error getting last gc", "repo", name, "err", err)
+		return
+	}
+	if last.IsZero() {
+		last = repo.CreatedAt()
+	}
+
+	stale := time.Since(last) >= interval && (c.Loose > 0 || c.Packs > 1)
+	if c.Loose < loose && !stale {
+		return
+	}
+
+	if _, err := b.GCRepository(ctx, repo); errors.Is(err, proto.ErrRepoBusy) {
+		logger.Info("skipping busy repository", "repo", name)
+	} else if err != nil {
+		logger.Error("error garbage collecting repository", "repo", name, "err", err)
+	}
+}
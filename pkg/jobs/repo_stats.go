@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+func init() {
+	Register("repo-stats", repoStats{})
+}
+
+type repoStats struct{}
+
+// Spec derives the spec used to refresh repository stats and languages, and
+// implements Runner.
+func (repoStats) Spec(ctx context.Context) string {
+	cfg := config.FromContext(ctx)
+	return cfg.Jobs.RepoStats
+}
+
+// Func runs the repository stats job and implements Runner.
+func (repoStats) Func(ctx context.Context) func() {
+	logger := log.FromContext(ctx).WithPrefix("jobs.repo-stats")
+	b := backend.FromContext(ctx)
+	return func() {
+		repos, err := b.Repositories(ctx)
+		if err != nil {
+			logger.Error("error getting repositories", "err", err)
+			return
+		}
+
+		for _, repo := range repos {
+			if _, err := b.UpdateRepoStats(ctx, repo); err != nil {
+				logger.Error("error updating repository stats", "repo", repo.Name(), "err", err)
+			}
+			// The languages are only recomputed when the default branch moved.
+			if _, err := b.UpdateRepoLanguages(ctx, repo); err != nil {
+				logger.Error("error updating repository languages", "repo", repo.Name(), "err", err)
+			}
+		}
+
+		logger.Debug("repository stats updated", "repos", len(repos))
+	}
+}
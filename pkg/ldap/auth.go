@@ -0,0 +1,160 @@
+// Package ldap authenticates users against an LDAP directory.
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/auth"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// dialTimeout is the time connecting to the server and each operation can
+// take.
+const dialTimeout = 10 * time.Second
+
+// Authenticator is an auth.Authenticator verifying passwords against an LDAP
+// directory. It searches for the user with the service account, then binds
+// as the user with their password.
+type Authenticator struct {
+	cfg config.LDAPConfig
+}
+
+var _ auth.Authenticator = (*Authenticator)(nil)
+
+// NewAuthenticator returns a new Authenticator for the directory in cfg.
+func NewAuthenticator(cfg config.LDAPConfig) *Authenticator {
+	return &Authenticator{cfg: cfg}
+}
+
+// Authenticate implements auth.Authenticator.
+func (a *Authenticator) Authenticate(ctx context.Context, username, password string) (*auth.Identity, error) {
+	if username == "" || password == "" {
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: a.cfg.InsecureSkipVerify} //nolint: gosec
+	c, err := dial(ctx, a.cfg.URL, tlsConfig, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close() //nolint: errcheck
+
+	if a.cfg.StartTLS {
+		if err := c.StartTLS(clientTLSConfig(tlsConfig, hostname(a.cfg.URL))); err != nil {
+			return nil, fmt.Errorf("ldap: start tls: %w", err)
+		}
+	}
+
+	if a.cfg.BindDN != "" {
+		if err := c.Bind(a.cfg.BindDN, a.cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldap: service account bind: %w", err)
+		}
+	}
+
+	// Referrals aren't followed, users must be found on the configured
+	// server.
+	res, err := c.Search(goldap.NewSearchRequest(
+		a.cfg.BaseDN,
+		goldap.ScopeWholeSubtree,
+		goldap.NeverDerefAliases,
+		2,
+		int(dialTimeout.Seconds()),
+		false,
+		strings.ReplaceAll(a.cfg.UserFilter, "%s", goldap.EscapeFilter(username)),
+		[]string{a.cfg.GroupAttribute},
+		nil,
+	))
+	if err != nil && !goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("ldap: user search: %w", err)
+	}
+	if err != nil || len(res.Entries) != 1 {
+		// Unknown or ambiguous users.
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	entry := res.Entries[0]
+	if err := c.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, auth.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	return &auth.Identity{
+		Username: username,
+		Groups:   entry.GetAttributeValues(a.cfg.GroupAttribute),
+	}, nil
+}
+
+// dial connects to the server at rawURL, an ldap:// or ldaps:// URL. Each
+// operation must complete within timeout.
+func dial(ctx context.Context, rawURL string, tlsConfig *tls.Config, timeout time.Duration) (*goldap.Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	host := u.Host
+	secure := false
+	switch u.Scheme {
+	case "ldap":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "389")
+		}
+	case "ldaps":
+		secure = true
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "636")
+		}
+	default:
+		return nil, fmt.Errorf("ldap: unsupported url scheme %q", u.Scheme)
+	}
+
+	d := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if secure {
+		td := &tls.Dialer{NetDialer: d, Config: clientTLSConfig(tlsConfig, u.Hostname())}
+		conn, err = td.DialContext(ctx, "tcp", host)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", host)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := goldap.NewConn(conn, secure)
+	c.Start()
+	c.SetTimeout(timeout)
+	return c, nil
+}
+
+// hostname returns the host name of an LDAP URL.
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+func clientTLSConfig(cfg *tls.Config, host string) *tls.Config {
+	if cfg == nil {
+		cfg = &tls.Config{} //nolint: gosec
+	} else {
+		cfg = cfg.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+	return cfg
+}
@@ -0,0 +1,226 @@
+package ldap
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/auth"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+type fakeUser struct {
+	dn       string
+	password string
+	groups   []string
+}
+
+// fakeServer is a directory answering binds, and searches on the uid
+// equality items of their filters.
+type fakeServer struct {
+	net.Listener
+	users map[string][]fakeUser
+
+	// referral is sent along with the search results if set.
+	referral string
+
+	// malformed makes the server answer searches with garbage.
+	malformed bool
+}
+
+func newFakeServer(t *testing.T, users map[string][]fakeUser) *fakeServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() }) //nolint: errcheck
+
+	s := &fakeServer{Listener: ln, users: users}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) URL() string {
+	return "ldap://" + s.Addr().String()
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close() //nolint: errcheck
+	for {
+		msg, err := ber.ReadPacket(conn)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+
+		id, op := msg.Children[0], msg.Children[1]
+		reply := func(resp *ber.Packet) {
+			envelope := ber.NewSequence("LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id.Value, "Message ID"))
+			envelope.AppendChild(resp)
+			conn.Write(envelope.Bytes()) //nolint: errcheck
+		}
+		result := func(op ber.Tag, code uint16) *ber.Packet {
+			p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, op, nil, "Result")
+			p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+			p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+			p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+			return p
+		}
+
+		switch op.Tag {
+		case goldap.ApplicationBindRequest:
+			dn, password := op.Children[1].Data.String(), op.Children[2].Data.String()
+			code := uint16(goldap.LDAPResultInvalidCredentials)
+			if dn == "cn=service,dc=example,dc=com" && password == "service" {
+				code = goldap.LDAPResultSuccess
+			}
+			for _, users := range s.users {
+				for _, u := range users {
+					if u.dn == dn && u.password == password {
+						code = goldap.LDAPResultSuccess
+					}
+				}
+			}
+			reply(result(goldap.ApplicationBindResponse, code))
+		case goldap.ApplicationSearchRequest:
+			if s.malformed {
+				conn.Write([]byte{0x30, 0x84, 0xff, 0xff, 0xff, 0xff, 0x02}) //nolint: errcheck
+				return
+			}
+			if s.referral != "" {
+				ref := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultReference, nil, "Search Result Reference")
+				ref.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, s.referral, "URI"))
+				reply(ref)
+			}
+			for _, u := range s.users[filterValue(op.Children[6], "uid")] {
+				groups := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+				for _, g := range u.groups {
+					groups.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, g, "Value"))
+				}
+				attr := ber.NewSequence("Attribute")
+				attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "memberOf", "Type"))
+				attr.AppendChild(groups)
+				attrs := ber.NewSequence("Attributes")
+				attrs.AppendChild(attr)
+				entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+				entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, u.dn, "DN"))
+				entry.AppendChild(attrs)
+				reply(entry)
+			}
+			reply(result(goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess))
+		default:
+			return
+		}
+	}
+}
+
+// filterValue returns the value of the first equality item on attr in f.
+func filterValue(f *ber.Packet, attr string) string {
+	if f.ClassType == ber.ClassContext && f.Tag == goldap.FilterEqualityMatch && len(f.Children) == 2 && f.Children[0].Data.String() == attr {
+		return f.Children[1].Data.String()
+	}
+	for _, c := range f.Children {
+		if v := filterValue(c, attr); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := newFakeServer(t, map[string][]fakeUser{
+		"alice": {{dn: "uid=alice,ou=people,dc=example,dc=com", password: "hunter2", groups: []string{"cn=developers,dc=example,dc=com"}}},
+		"twin":  {{dn: "uid=twin,ou=a,dc=example,dc=com", password: "pw"}, {dn: "uid=twin,ou=b,dc=example,dc=com", password: "pw"}},
+	})
+	a := NewAuthenticator(config.LDAPConfig{
+		URL:            s.URL(),
+		BindDN:         "cn=service,dc=example,dc=com",
+		BindPassword:   "service",
+		BaseDN:         "dc=example,dc=com",
+		UserFilter:     "(&(objectClass=person)(uid=%s))",
+		GroupAttribute: "memberOf",
+	})
+
+	id, err := a.Authenticate(context.TODO(), "alice", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Username != "alice" || !slices.Equal(id.Groups, []string{"cn=developers,dc=example,dc=com"}) {
+		t.Errorf("identity = %+v", id)
+	}
+
+	cases := []struct {
+		name               string
+		username, password string
+	}{
+		{"wrong password", "alice", "wrong"},
+		{"empty password", "alice", ""},
+		{"unknown user", "bob", "hunter2"},
+		{"ambiguous user", "twin", "pw"},
+	}
+	for _, c := range cases {
+		if _, err := a.Authenticate(context.TODO(), c.username, c.password); !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Errorf("%s: err = %v, want %v", c.name, err, auth.ErrInvalidCredentials)
+		}
+	}
+}
+
+func TestAuthenticateServiceBind(t *testing.T) {
+	s := newFakeServer(t, nil)
+	a := NewAuthenticator(config.LDAPConfig{
+		URL:          s.URL(),
+		BindDN:       "cn=service,dc=example,dc=com",
+		BindPassword: "wrong",
+		UserFilter:   "(uid=%s)",
+	})
+
+	_, err := a.Authenticate(context.TODO(), "alice", "hunter2")
+	if err == nil || errors.Is(err, auth.ErrInvalidCredentials) || !goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+		t.Errorf("err = %v, want a service account bind error", err)
+	}
+}
+
+func TestAuthenticateReferral(t *testing.T) {
+	s := newFakeServer(t, map[string][]fakeUser{
+		"alice": {{dn: "uid=alice,ou=people,dc=example,dc=com", password: "hunter2"}},
+	})
+	s.referral = "ldap://other.example.com/dc=example,dc=com"
+	a := NewAuthenticator(config.LDAPConfig{
+		URL:        s.URL(),
+		UserFilter: "(uid=%s)",
+	})
+
+	if _, err := a.Authenticate(context.TODO(), "alice", "hunter2"); err != nil {
+		t.Errorf("err = %v, want the referral to be ignored", err)
+	}
+	if _, err := a.Authenticate(context.TODO(), "bob", "hunter2"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("err = %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+}
+
+func TestAuthenticateMalformedResponse(t *testing.T) {
+	s := newFakeServer(t, nil)
+	s.malformed = true
+	a := NewAuthenticator(config.LDAPConfig{
+		URL:        s.URL(),
+		UserFilter: "(uid=%s)",
+	})
+
+	_, err := a.Authenticate(context.TODO(), "alice", "hunter2")
+	if err == nil || errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("err = %v, want a search error", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 
 	"charm.land/log/v2"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 )
 
 // BasicTransferAdapter implements the "basic" adapter
@@ -52,7 +53,7 @@ func (a *BasicTransferAdapter) Upload(ctx context.Context, p Pointer, r io.Reade
 
 // Verify calls the verify handler on the LFS server
 func (a *BasicTransferAdapter) Verify(ctx context.Context, p Pointer, l *Link) error {
-	logger := log.FromContext(ctx).WithPrefix("lfs")
+	logger := logr.Subsystem(ctx, "lfs", "lfs")
 	b, err := json.Marshal(p)
 	if err != nil {
 		logger.Errorf("Error encoding json: %v", err)
@@ -69,7 +70,7 @@ func (a *BasicTransferAdapter) Verify(ctx context.Context, p Pointer, l *Link) e
 }
 
 func (a *BasicTransferAdapter) performRequest(ctx context.Context, method string, l *Link, body io.Reader, callback func(*http.Request)) (*http.Response, error) {
-	logger := log.FromContext(ctx).WithPrefix("lfs")
+	logger := logr.Subsystem(ctx, "lfs", "lfs")
 	logger.Debugf("Calling: %s %s", method, l.Href)
 
 	req, err := http.NewRequestWithContext(ctx, method, l.Href, body)
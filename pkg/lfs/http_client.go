@@ -8,7 +8,7 @@ import (
 	"fmt"
 	"net/http"
 
-	"charm.land/log/v2"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/ssrf"
 )
 
@@ -55,7 +55,7 @@ func (c *httpClient) transferNames() []string {
 
 // batch performs a batch request to the LFS server.
 func (c *httpClient) batch(ctx context.Context, operation string, objects []Pointer) (*BatchResponse, error) {
-	logger := log.FromContext(ctx).WithPrefix("lfs")
+	logger := logr.Subsystem(ctx, "lfs", "lfs")
 	url := fmt.Sprintf("%s/objects/batch", c.endpoint.String())
 
 	// TODO: support ref
@@ -109,7 +109,7 @@ func (c *httpClient) batch(ctx context.Context, operation string, objects []Poin
 }
 
 func (c *httpClient) performOperation(ctx context.Context, objects []Pointer, dc DownloadCallback, uc UploadCallback) error {
-	logger := log.FromContext(ctx).WithPrefix("lfs")
+	logger := logr.Subsystem(ctx, "lfs", "lfs")
 	if len(objects) == 0 {
 		return nil
 	}
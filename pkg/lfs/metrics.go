@@ -0,0 +1,47 @@
+package lfs
+
+import (
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var transferredBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "lfs",
+	Name:      "transferred_bytes_total",
+	Help:      "The total number of LFS object bytes transferred by transport and direction",
+}, []string{"transport", "direction"})
+
+// Transfer directions.
+const (
+	DirectionUpload   = "upload"
+	DirectionDownload = "download"
+)
+
+// ObserveTransfer records n bytes of LFS objects transferred over a
+// transport, like "ssh" or "http", in a direction.
+func ObserveTransfer(transport, direction string, n int64) {
+	if n > 0 {
+		transferredBytes.WithLabelValues(transport, direction).Add(float64(n))
+	}
+}
+
+// CountingReader returns a reader that records the bytes read from r as
+// transferred.
+func CountingReader(r io.ReadCloser, transport, direction string) io.ReadCloser {
+	return &countingReader{ReadCloser: r, transport: transport, direction: direction}
+}
+
+type countingReader struct {
+	io.ReadCloser
+	transport, direction string
+}
+
+// Read implements io.Reader.
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	ObserveTransfer(r.transport, r.direction, int64(n))
+	return n, err
+}
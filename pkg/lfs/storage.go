@@ -0,0 +1,115 @@
+package lfs
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/charmbracelet/soft-serve/pkg/storage"
+)
+
+var (
+	// ErrOIDMismatch occurs if the content of an object doesn't match its OID.
+	ErrOIDMismatch = errors.New("object content doesn't match its OID")
+
+	// ErrSizeMismatch occurs if the content of an object doesn't match its
+	// size.
+	ErrSizeMismatch = errors.New("object content doesn't match its size")
+)
+
+// StoreObject writes the content of the object p from r to strg.
+//
+// The content is first written to a temporary object under "incomplete" and
+// is only moved to its final location under "objects" once it's verified to
+// hash to p.Oid. If p.Size is greater than zero, the content size is verified
+// as well. It returns the number of bytes written.
+func StoreObject(strg storage.Storage, p Pointer, r io.Reader) (int64, error) {
+	if !p.IsValid() {
+		return 0, ErrInvalidOIDFormat
+	}
+
+	randBytes := make([]byte, 12)
+	if _, err := rand.Read(randBytes); err != nil {
+		return 0, err
+	}
+
+	tempName := path.Join("incomplete", fmt.Sprintf("%s%x", p.Oid, randBytes))
+	h := sha256.New()
+	written, err := strg.Put(tempName, io.TeeReader(r, h))
+	if err != nil {
+		_ = strg.Delete(tempName)
+		return written, err
+	}
+
+	if err := checkContent(p, hex.EncodeToString(h.Sum(nil)), written); err != nil {
+		_ = strg.Delete(tempName)
+		return written, err
+	}
+
+	if err := strg.Rename(tempName, path.Join("objects", p.RelativePath())); err != nil {
+		_ = strg.Delete(tempName)
+		return written, err
+	}
+
+	return written, nil
+}
+
+// OpenObject opens the stored object p from strg once its content is verified
+// to hash to p.Oid. If p.Size is greater than zero, the content size is
+// verified as well.
+//
+// The whole object is read before it's returned, so corrupted objects are
+// never served to clients.
+func OpenObject(strg storage.Storage, p Pointer) (storage.Object, error) {
+	if !p.IsValid() {
+		return nil, ErrInvalidOIDFormat
+	}
+
+	obj, err := strg.Open(path.Join("objects", p.RelativePath()))
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	read, err := io.Copy(h, obj)
+	if err == nil {
+		err = checkContent(p, hex.EncodeToString(h.Sum(nil)), read)
+	}
+	if err == nil {
+		_, err = obj.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		obj.Close() //nolint: errcheck
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// VerifyObject verifies that the stored content of the object p matches its
+// OID and size.
+func VerifyObject(strg storage.Storage, p Pointer) error {
+	obj, err := OpenObject(strg, p)
+	if err != nil {
+		return err
+	}
+
+	return obj.Close()
+}
+
+// checkContent checks the hash and size of the content of p.
+func checkContent(p Pointer, oid string, size int64) error {
+	if oid != p.Oid {
+		return fmt.Errorf("%w: got %s, expected %s", ErrOIDMismatch, oid, p.Oid)
+	}
+
+	if p.Size > 0 && size != p.Size {
+		return fmt.Errorf("%w: got %d bytes, expected %d", ErrSizeMismatch, size, p.Size)
+	}
+
+	return nil
+}
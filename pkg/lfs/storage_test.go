@@ -0,0 +1,137 @@
+package lfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/storage"
+)
+
+func TestStoreObject(t *testing.T) {
+	const content = "hello, world"
+	p, err := GeneratePointer(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		pointer Pointer
+		content string
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			pointer: p,
+			content: content,
+		},
+		{
+			name:    "oid mismatch",
+			pointer: p,
+			content: "hello, there",
+			wantErr: ErrOIDMismatch,
+		},
+		{
+			name:    "size mismatch",
+			pointer: Pointer{Oid: p.Oid, Size: p.Size + 1},
+			content: content,
+			wantErr: ErrSizeMismatch,
+		},
+		{
+			name:    "invalid oid",
+			pointer: Pointer{Oid: "../../etc/passwd"},
+			content: content,
+			wantErr: ErrInvalidOIDFormat,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			strg := storage.NewLocalStorage(t.TempDir())
+			_, err := StoreObject(strg, c.pointer, strings.NewReader(c.content))
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("StoreObject() error = %v, want %v", err, c.wantErr)
+			}
+
+			exists, err := strg.Exists(path.Join("objects", p.RelativePath()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exists != (c.wantErr == nil) {
+				t.Errorf("object exists = %t, want %t", exists, c.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestOpenObject(t *testing.T) {
+	const content = "hello, world"
+	p, err := GeneratePointer(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		pointer Pointer
+		stored  string
+		wantErr error
+	}{
+		{
+			name:    "valid",
+			pointer: p,
+			stored:  content,
+		},
+		{
+			name:    "truncated",
+			pointer: p,
+			stored:  content[:5],
+			wantErr: ErrOIDMismatch,
+		},
+		{
+			name:    "unknown size",
+			pointer: Pointer{Oid: p.Oid},
+			stored:  content,
+		},
+		{
+			name:    "size mismatch",
+			pointer: Pointer{Oid: p.Oid, Size: p.Size + 1},
+			stored:  content,
+			wantErr: ErrSizeMismatch,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			strg := storage.NewLocalStorage(t.TempDir())
+			if _, err := strg.Put(path.Join("objects", p.RelativePath()), strings.NewReader(c.stored)); err != nil {
+				t.Fatal(err)
+			}
+
+			obj, err := OpenObject(strg, c.pointer)
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("OpenObject() error = %v, want %v", err, c.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer obj.Close() //nolint: errcheck
+
+			got, err := io.ReadAll(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != content {
+				t.Errorf("OpenObject() content = %q, want %q", got, content)
+			}
+		})
+	}
+
+	if err := VerifyObject(storage.NewLocalStorage(t.TempDir()), p); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("VerifyObject() error = %v, want fs.ErrNotExist", err)
+	}
+}
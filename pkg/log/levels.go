@@ -0,0 +1,152 @@
+package log
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"weak"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+// levels are the log levels of the subsystems. They're global, like the
+// default logger, so that they can be changed while the server runs.
+var levels = &subsystemLevels{
+	def:     log.InfoLevel,
+	levels:  map[string]log.Level{},
+	loggers: map[string][]weak.Pointer[log.Logger]{},
+}
+
+type subsystemLevels struct {
+	mu     sync.Mutex
+	def    log.Level
+	levels map[string]log.Level
+	// loggers are the loggers returned by Subsystem, to change their level
+	// along with the one of their subsystem. They're weak so that the
+	// short-lived ones, such as the loggers of requests, can be collected.
+	loggers map[string][]weak.Pointer[log.Logger]
+	tracked int
+}
+
+// Subsystem returns the logger of ctx with prefix and the log level of the
+// subsystem name, see [config.LogSubsystems]. Changing the level of the
+// subsystem changes the one of the logger, but not of the loggers already
+// derived from it with With.
+func Subsystem(ctx context.Context, name string, prefix string) *log.Logger {
+	logger := log.FromContext(ctx).WithPrefix(prefix)
+
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	logger.SetLevel(levels.level(name))
+
+	// Forget about the collected loggers when their number doubled.
+	if len(levels.loggers[name]) >= 2*max(levels.tracked, 8) {
+		levels.compact()
+	}
+	levels.loggers[name] = append(levels.loggers[name], weak.Make(logger))
+
+	return logger
+}
+
+// SetLevels sets the default log level and the ones of subsystems, which are
+// applied to the loggers returned by Subsystem. The subsystems missing from
+// subsystems log at the default level.
+func SetLevels(def log.Level, subsystems map[string]log.Level) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	levels.def = def
+	levels.levels = make(map[string]log.Level, len(subsystems))
+	for name, l := range subsystems {
+		levels.levels[name] = l
+	}
+	levels.apply()
+}
+
+// SetLevel sets the log level of a subsystem. A nil level resets it to the
+// default level.
+func SetLevel(name string, l *log.Level) error {
+	if err := config.ValidateLogSubsystem(name); err != nil {
+		return err
+	}
+
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	if l == nil {
+		delete(levels.levels, name)
+	} else {
+		levels.levels[name] = *l
+	}
+	levels.apply()
+
+	return nil
+}
+
+// Levels returns the default log level and the levels of all the
+// subsystems, along with whether they're set or follow the default level.
+func Levels() (log.Level, map[string]log.Level, map[string]bool) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+	all := make(map[string]log.Level, len(config.LogSubsystems))
+	set := make(map[string]bool, len(config.LogSubsystems))
+	for _, name := range config.LogSubsystems {
+		all[name] = levels.level(name)
+		_, set[name] = levels.levels[name]
+	}
+
+	return levels.def, all, set
+}
+
+// ConfigLevels returns the default log level and the levels of subsystems of
+// a configuration. Invalid levels are ignored, the configuration is
+// validated when it's parsed.
+func ConfigLevels(cfg *config.Config) (log.Level, map[string]log.Level) {
+	def := log.InfoLevel
+	if l, err := log.ParseLevel(cfg.Log.Level); err == nil {
+		def = l
+	}
+	if config.IsDebug() {
+		def = log.DebugLevel
+	}
+
+	subsystems := make(map[string]log.Level, len(cfg.Log.Levels))
+	for name, level := range cfg.Log.Levels {
+		if l, err := log.ParseLevel(level); err == nil {
+			subsystems[name] = l
+		}
+	}
+
+	return def, subsystems
+}
+
+func (s *subsystemLevels) level(name string) log.Level {
+	if l, ok := s.levels[name]; ok {
+		return l
+	}
+
+	return s.def
+}
+
+// apply sets the levels of the live loggers.
+func (s *subsystemLevels) apply() {
+	s.compact()
+	for name, loggers := range s.loggers {
+		l := s.level(name)
+		for _, p := range loggers {
+			if logger := p.Value(); logger != nil {
+				logger.SetLevel(l)
+			}
+		}
+	}
+}
+
+// compact forgets about the loggers that were collected.
+func (s *subsystemLevels) compact() {
+	s.tracked = 0
+	for name, loggers := range s.loggers {
+		s.loggers[name] = slices.DeleteFunc(loggers, func(p weak.Pointer[log.Logger]) bool {
+			return p.Value() == nil
+		})
+		s.tracked = max(s.tracked, len(s.loggers[name]))
+	}
+}
@@ -19,14 +19,14 @@ func NewLogger(cfg *config.Config) (*log.Logger, *os.File, error) {
 		TimeFormat:      time.DateOnly,
 	})
 
-	switch {
-	case config.IsVerbose():
+	if config.IsVerbose() {
 		logger.SetReportCaller(true)
-		fallthrough
-	case config.IsDebug():
-		logger.SetLevel(log.DebugLevel)
 	}
 
+	def, subsystems := ConfigLevels(cfg)
+	logger.SetLevel(def)
+	SetLevels(def, subsystems)
+
 	logger.SetTimeFormat(cfg.Log.TimeFormat)
 
 	switch strings.ToLower(cfg.Log.Format) {
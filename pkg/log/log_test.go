@@ -1,9 +1,12 @@
 package log
 
 import (
+	"context"
+	"io"
 	"path/filepath"
 	"testing"
 
+	"charm.land/log/v2"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 )
 
@@ -41,3 +44,39 @@ func TestBadNewLogger(t *testing.T) {
 		}
 	}
 }
+
+func TestSubsystemLevels(t *testing.T) {
+	t.Cleanup(func() { SetLevels(log.InfoLevel, nil) })
+	ctx := log.WithContext(context.Background(), log.New(io.Discard))
+
+	SetLevels(log.WarnLevel, map[string]log.Level{"git": log.DebugLevel})
+	git := Subsystem(ctx, "git", "git")
+	http := Subsystem(ctx, "http", "http.auth")
+	if git.GetLevel() != log.DebugLevel || http.GetLevel() != log.WarnLevel {
+		t.Fatalf("levels = %s, %s, want debug, warn", git.GetLevel(), http.GetLevel())
+	}
+	if http.GetPrefix() != "http.auth" {
+		t.Errorf("prefix = %q, want http.auth", http.GetPrefix())
+	}
+
+	// Changing a level applies to the existing loggers.
+	debug := log.DebugLevel
+	if err := SetLevel("http", &debug); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLevel("git", nil); err != nil {
+		t.Fatal(err)
+	}
+	if git.GetLevel() != log.WarnLevel || http.GetLevel() != log.DebugLevel {
+		t.Errorf("levels = %s, %s, want warn, debug", git.GetLevel(), http.GetLevel())
+	}
+
+	if err := SetLevel("web", &debug); err == nil {
+		t.Error("setting the level of an unknown subsystem succeeded")
+	}
+
+	def, levels, set := Levels()
+	if def != log.WarnLevel || levels["http"] != log.DebugLevel || !set["http"] || set["git"] {
+		t.Errorf("Levels() = %s, %v, %v", def, levels, set)
+	}
+}
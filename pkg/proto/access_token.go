@@ -1,6 +1,10 @@
 package proto
 
-import "time"
+import (
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+)
 
 // AccessToken represents an access token.
 type AccessToken struct {
@@ -9,5 +13,6 @@ type AccessToken struct {
 	UserID    int64
 	TokenHash string
 	ExpiresAt time.Time
+	Scope     access.AccessLevel
 	CreatedAt time.Time
 }
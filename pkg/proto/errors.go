@@ -9,12 +9,32 @@ var (
 	ErrUnauthorized = errors.New("unauthorized")
 	// ErrInvalidRemote is returned when a repository import remote is invalid.
 	ErrInvalidRemote = errors.New("remote must be a network URL")
+	// ErrTokenRemote is returned when importing from a non-HTTP remote with
+	// a token.
+	ErrTokenRemote = errors.New("token can only be used with HTTP remotes")
+	// ErrSSHKeyRemote is returned when importing from a non-SSH remote with
+	// an SSH key.
+	ErrSSHKeyRemote = errors.New("ssh key can only be used with SSH remotes")
+	// ErrInvalidSSHKey is returned when an import SSH key can't be parsed.
+	ErrInvalidSSHKey = errors.New("invalid ssh private key")
 	// ErrFileNotFound is returned when the file is not found.
 	ErrFileNotFound = errors.New("file not found")
 	// ErrRepoNotFound is returned when a repository is not found.
 	ErrRepoNotFound = errors.New("repository not found")
 	// ErrRepoExist is returned when a repository already exists.
 	ErrRepoExist = errors.New("repository already exists")
+	// ErrNotTemplate is returned when a repository is created from a
+	// repository that isn't a template.
+	ErrNotTemplate = errors.New("repository is not a template")
+	// ErrRepoBusy is returned when a repository is being renamed, or when it
+	// can't be renamed because it's in use.
+	ErrRepoBusy = errors.New("repository is busy being renamed, try again later")
+	// ErrServerBusy is returned when a git operation is over the concurrency
+	// limits of the server.
+	ErrServerBusy = errors.New("server busy, try again later")
+	// ErrPrivateExport is returned when exporting a private repository to
+	// the Git daemon.
+	ErrPrivateExport = errors.New("private repositories can't be exported")
 	// ErrUserNotFound is returned when a user is not found.
 	ErrUserNotFound = errors.New("user not found")
 	// ErrTokenNotFound is returned when a token is not found.
@@ -25,4 +45,57 @@ var (
 	ErrCollaboratorNotFound = errors.New("collaborator not found")
 	// ErrCollaboratorExist is returned when a collaborator already exists.
 	ErrCollaboratorExist = errors.New("collaborator already exists")
+	// ErrTeamNotFound is returned when a team is not found.
+	ErrTeamNotFound = errors.New("team not found")
+	// ErrTeamExist is returned when a team already exists.
+	ErrTeamExist = errors.New("team already exists")
+	// ErrTeamMemberExist is returned when a user is already a member of a team.
+	ErrTeamMemberExist = errors.New("team member already exists")
+	// ErrTeamRepoExist is returned when a team already has access to a
+	// repository.
+	ErrTeamRepoExist = errors.New("team already has access to the repository")
+	// ErrBranchProtectionExist is returned when a branch protection rule
+	// already exists for a pattern.
+	ErrBranchProtectionExist = errors.New("branch protection rule already exists")
+	// ErrInvalidBranchPattern is returned when a branch protection pattern is
+	// invalid.
+	ErrInvalidBranchPattern = errors.New("invalid branch pattern")
+	// ErrTagProtectionExist is returned when a tag protection rule already
+	// exists.
+	ErrTagProtectionExist = errors.New("tag protection rule already exists")
+	// ErrInvalidTagPattern is returned when a tag protection pattern is
+	// invalid.
+	ErrInvalidTagPattern = errors.New("invalid tag pattern")
+	// ErrSignerExist is returned when a signer key already exists.
+	ErrSignerExist = errors.New("signer already exists")
+	// ErrInvalidPrincipal is returned when a signer principal is invalid.
+	ErrInvalidPrincipal = errors.New("principal must not be empty or contain spaces")
+	// ErrNoCommitMessagePattern is returned when enabling the commit message
+	// check of a repository without a pattern.
+	ErrNoCommitMessagePattern = errors.New("commit message pattern is not set")
+	// ErrTopicExist is returned when a repository already has a topic.
+	ErrTopicExist = errors.New("repository already has the topic")
+	// ErrIPRuleExist is returned when an address range already has a rule.
+	ErrIPRuleExist = errors.New("ip rule already exists")
+	// ErrIPRuleNotFound is returned when an address range has no rule.
+	ErrIPRuleNotFound = errors.New("ip rule not found")
+	// ErrInvalidCIDR is returned when an ip rule address range is invalid.
+	ErrInvalidCIDR = errors.New("invalid ip address or cidr range")
+	// ErrInvalidEmail is returned when an email address is invalid.
+	ErrInvalidEmail = errors.New("invalid email address")
+	// ErrDeployKeyExist is returned when a public key is already a deploy key,
+	// or a repository already has a deploy key with the same title.
+	ErrDeployKeyExist = errors.New("deploy key already exists")
+	// ErrDeployKeyNotFound is returned when a repository has no deploy key
+	// with a title.
+	ErrDeployKeyNotFound = errors.New("deploy key not found")
+	// ErrPublicKeyInUse is returned when adding a deploy key that's the
+	// public key of a user.
+	ErrPublicKeyInUse = errors.New("public key is already used by a user")
+	// ErrAddrDenied is returned when a client address is refused by the ip
+	// rules.
+	ErrAddrDenied = errors.New("address denied")
+	// ErrEmailNotUnique is returned when looking up the user of an email
+	// address several users have.
+	ErrEmailNotUnique = errors.New("email address belongs to several users")
 )
@@ -1,6 +1,7 @@
 package proto
 
 import (
+	"io"
 	"time"
 
 	"github.com/charmbracelet/soft-serve/git"
@@ -22,6 +23,10 @@ type Repository interface {
 	IsMirror() bool
 	// IsHidden returns whether the repository is hidden.
 	IsHidden() bool
+	// IsArchived returns whether the repository is archived.
+	IsArchived() bool
+	// IsTemplate returns whether the repository is a template.
+	IsTemplate() bool
 	// UserID returns the ID of the user who owns the repository.
 	// It returns 0 if the repository is not owned by a user.
 	UserID() int64
@@ -43,6 +48,26 @@ type RepositoryOptions struct {
 	Hidden      bool
 	LFS         bool
 	LFSEndpoint string
+	// MirrorInterval is the minimum time between syncs of a mirror.
+	MirrorInterval time.Duration
+	// Token authenticates an import from an HTTP remote.
+	Token string
+	// SSHKey is the private key that authenticates an import from an SSH
+	// remote, instead of the server client key.
+	SSHKey string
+	// Progress, if set, receives the progress of an import.
+	Progress io.Writer
+	// Template is the name of the template repository whose default branch
+	// tree becomes the initial commit of a new repository.
+	Template string
+	// Fork is the name of the repository a new repository is a fork of. The
+	// fork gets its branches and tags, and borrows its objects instead of
+	// copying them.
+	Fork string
+	// DefaultBranch is the branch HEAD of a new repository points to,
+	// instead of the configured one, or the one of its template. Forks get
+	// the one of their parent.
+	DefaultBranch string
 }
 
 // RepositoryDefaultBranch returns the default branch of a repository.
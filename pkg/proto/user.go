@@ -14,6 +14,8 @@ type User interface {
 	PublicKeys() []ssh.PublicKey
 	// Password returns the user's password hash.
 	Password() string
+	// Email returns the user's email address, it's empty if unset.
+	Email() string
 }
 
 // UserOptions are options for creating a user.
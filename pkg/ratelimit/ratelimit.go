@@ -0,0 +1,124 @@
+// Package ratelimit limits the rate of events per key, such as connections
+// per source IP.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter limits the rate of events per key.
+//
+// Implementations must be safe for concurrent use.
+type Limiter interface {
+	// Allow reports whether an event for key may happen now. Allowed events
+	// consume from the key's limit.
+	Allow(key string) bool
+}
+
+// Stats are the counters of a TokenBucket limiter.
+type Stats struct {
+	// Allowed is the number of allowed events.
+	Allowed uint64
+
+	// Denied is the number of denied events.
+	Denied uint64
+
+	// Keys is the number of keys currently tracked.
+	Keys int
+}
+
+// TokenBucket is an in-memory token bucket Limiter. Each key gets a bucket of
+// Burst tokens that refills at Rate tokens per second, and each event takes
+// one token.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	stats     Stats
+
+	// now is used to get the current time, tests can override it.
+	now func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+var _ Limiter = (*TokenBucket)(nil)
+
+// NewTokenBucket returns a new TokenBucket allowing rate events per second
+// per key, with bursts of up to burst events. A burst smaller than one is
+// treated as one.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow implements Limiter.
+func (t *TokenBucket) Allow(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+
+	b, ok := t.buckets[key]
+	if !ok {
+		b = &bucket{tokens: t.burst, last: now}
+		t.buckets[key] = b
+	}
+
+	b.tokens = min(t.burst, b.tokens+now.Sub(b.last).Seconds()*t.rate)
+	b.last = now
+	if b.tokens < 1 {
+		t.stats.Denied++
+		return false
+	}
+
+	b.tokens--
+	t.stats.Allowed++
+	return true
+}
+
+// Stats returns the current counters of the limiter.
+func (t *TokenBucket) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := t.stats
+	stats.Keys = len(t.buckets)
+	return stats
+}
+
+// sweep forgets the buckets that have refilled, they behave the same as new
+// ones. This keeps the memory used bounded by the number of recently active
+// keys.
+func (t *TokenBucket) sweep(now time.Time) {
+	refill := time.Minute
+	if t.rate > 0 {
+		refill = max(refill, time.Duration(t.burst/t.rate*float64(time.Second)))
+	}
+	if now.Sub(t.lastSweep) < refill {
+		return
+	}
+
+	t.lastSweep = now
+	for key, b := range t.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(t.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	tb := NewTokenBucket(1, 2)
+	tb.now = func() time.Time { return now }
+
+	allow := func(key string, want bool) {
+		t.Helper()
+		if got := tb.Allow(key); got != want {
+			t.Fatalf("Allow(%q) = %t, want %t", key, got, want)
+		}
+	}
+
+	// The burst is available right away.
+	allow("a", true)
+	allow("a", true)
+	allow("a", false)
+
+	// Keys are limited independently.
+	allow("b", true)
+
+	// Tokens refill over time.
+	now = now.Add(500 * time.Millisecond)
+	allow("a", false)
+	now = now.Add(500 * time.Millisecond)
+	allow("a", true)
+	allow("a", false)
+
+	// Refills are capped to the burst.
+	now = now.Add(time.Hour)
+	allow("c", true)
+	if got := tb.Stats(); got.Keys != 1 {
+		t.Errorf("Stats().Keys = %d, want 1 after refilled buckets are swept", got.Keys)
+	}
+	allow("a", true)
+	allow("a", true)
+	allow("a", false)
+
+	want := Stats{Allowed: 7, Denied: 4, Keys: 2}
+	if got := tb.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// AccessCommand returns a command for inspecting access to repositories.
+func AccessCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "access",
+		Short: "Inspect repository access",
+	}
+
+	cmd.AddCommand(
+		accessCheckCommand(),
+	)
+
+	return cmd
+}
+
+func accessCheckCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "check USERNAME REPOSITORY",
+		Short:             "Show the access level of a user to a repository and why",
+		Long:              "Show the access level a user has to a repository and the rules it comes from, such as ownership, collaborator and team grants, the anonymous access level, and admin rights.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfServerAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user, err := be.User(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			rn := utils.SanitizeRepo(args[1])
+			e := be.ExplainAccess(ctx, rn, user)
+			cmd.Printf("Access of %s to %s: %s\n", user.Username(), rn, e.Level)
+			cmd.Printf("Reasons:\n")
+			for _, r := range e.Reasons {
+				cmd.Printf("  - %s: %s\n", r.Reason, r.Level)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
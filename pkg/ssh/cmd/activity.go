@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func activityCommand() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:               "activity REPOSITORY",
+		Short:             "List the pushes to a repository",
+		Long:              "List the last pushes to a repository, newest first, with the user, the number of commits pushed and the refs updated.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			rr, err := be.Repository(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			events, err := be.PushEvents(ctx, rr, limit)
+			if err != nil {
+				return err
+			}
+
+			for _, e := range events {
+				username := e.Username
+				if username == "" {
+					username = "-"
+				}
+				cmd.Printf("%s %s %d commit(s)\n", e.Time.UTC().Format(time.RFC3339), username, e.Commits)
+				for _, ref := range e.Refs {
+					cmd.Printf("  %s %s -> %s\n", ref.RefName, ref.OldSha, ref.NewSha)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Number of pushes to list")
+
+	return cmd
+}
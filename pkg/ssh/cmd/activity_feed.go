@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// activityBuffer is the number of events buffered for a session following
+// the activity feed, events are dropped once it's full.
+const activityBuffer = 256
+
+// ActivityCommand returns a command that shows the git operations of the
+// server as they happen.
+func ActivityCommand() *cobra.Command {
+	var follow bool
+	var limit int
+	var repo string
+
+	cmd := &cobra.Command{
+		Use:               "activity",
+		Short:             "Show the git operations of the server",
+		Long:              "Show the last git operations of the server, oldest first, with the user, the repository, and the service of each operation starting, finishing, or being denied. With --follow, keep showing them as they happen, events are dropped if the session doesn't keep up.",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfServerAdmin,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo = utils.SanitizeRepo(repo)
+
+			history, sub := be.SubscribeActivity(activityBuffer)
+			defer sub.Close()
+
+			var events []backend.ActivityEvent
+			for _, e := range history {
+				if repo == "" || e.Repo == repo {
+					events = append(events, e)
+				}
+			}
+			if limit >= 0 && len(events) > limit {
+				events = events[len(events)-limit:]
+			}
+			for _, e := range events {
+				printActivityEvent(cmd, e)
+			}
+
+			if !follow {
+				return nil
+			}
+
+			var dropped int64
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case e, ok := <-sub.Events():
+					if !ok {
+						return nil
+					}
+					if n := sub.Dropped(); n > dropped {
+						cmd.PrintErrf("dropped %d event(s)\n", n-dropped)
+						dropped = n
+					}
+					if repo == "" || e.Repo == repo {
+						printActivityEvent(cmd, e)
+					}
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "keep showing the operations as they happen")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "number of past events to show")
+	cmd.Flags().StringVarP(&repo, "repo", "r", "", "only show the operations of a repository")
+
+	return cmd
+}
+
+// printActivityEvent prints an activity event on a line.
+func printActivityEvent(cmd *cobra.Command, e backend.ActivityEvent) {
+	username := e.Username
+	if username == "" {
+		username = "-"
+	}
+	addr := e.RemoteAddr
+	if addr == "" {
+		addr = "-"
+	}
+
+	cmd.Printf("%s %s %s %s %s %s %s", e.Time.Format(time.RFC3339), e.Kind, e.Transport, e.Service, e.Repo, username, addr)
+	if e.Kind == backend.ActivityFinish {
+		cmd.Printf(" %s %s", e.Result, e.Duration.Round(time.Millisecond))
+	}
+	cmd.Println()
+}
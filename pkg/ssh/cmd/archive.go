@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func archiveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "archive REPOSITORY",
+		Short:             "Archive a repository, making it read-only",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadableAndAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.SetArchived(ctx, args[0], true)
+		},
+	}
+
+	return cmd
+}
+
+func unarchiveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "unarchive REPOSITORY",
+		Short:             "Unarchive a repository",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadableAndAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.SetArchived(ctx, args[0], false)
+		},
+	}
+
+	return cmd
+}
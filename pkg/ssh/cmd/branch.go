@@ -7,6 +7,7 @@ import (
 	gitm "github.com/aymanbagabas/git-module"
 	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/webhook"
 	"github.com/spf13/cobra"
@@ -22,6 +23,7 @@ func branchCommand() *cobra.Command {
 		branchListCommand(),
 		branchDefaultCommand(),
 		branchDeleteCommand(),
+		branchProtectionCommand(),
 	)
 
 	return cmd
@@ -92,46 +94,7 @@ func branchDefaultCommand() *cobra.Command {
 					return err
 				}
 
-				rr, err := be.Repository(ctx, rn)
-				if err != nil {
-					return err
-				}
-
-				r, err := rr.Open()
-				if err != nil {
-					return err
-				}
-
-				branch := args[1]
-				branches, _ := r.Branches()
-				var exists bool
-				for _, b := range branches {
-					if branch == b {
-						exists = true
-						break
-					}
-				}
-
-				if !exists {
-					return git.ErrReferenceNotExist
-				}
-
-				if _, err := r.SymbolicRef(git.HEAD, gitm.RefsHeads+branch, gitm.SymbolicRefOptions{
-					CommandOptions: gitm.CommandOptions{
-						Context: ctx,
-					},
-				}); err != nil {
-					return err
-				}
-
-				// TODO: move this to backend?
-				user := proto.UserFromContext(ctx)
-				wh, err := webhook.NewRepositoryEvent(ctx, user, rr, webhook.RepositoryEventActionDefaultBranchChange)
-				if err != nil {
-					return err
-				}
-
-				return webhook.SendEvent(ctx, wh)
+				return be.SetDefaultBranch(ctx, rn, args[1])
 			}
 
 			return nil
@@ -190,6 +153,13 @@ func branchDeleteCommand() *cobra.Command {
 				return err
 			}
 
+			level := be.AccessLevelForUser(ctx, rn, proto.UserFromContext(ctx))
+			if err := be.CheckBranchProtections(ctx, rn, level, []hooks.HookArg{
+				{OldSha: branchCommit.ID.String(), NewSha: git.ZeroID, RefName: git.RefsHeads + branch},
+			}); err != nil {
+				return err
+			}
+
 			if err := r.DeleteBranch(branch, gitm.DeleteBranchOptions{Force: true}); err != nil {
 				return err
 			}
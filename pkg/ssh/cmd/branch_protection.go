@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func branchProtectionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "protection",
+		Aliases: []string{"protections", "protect"},
+		Short:   "Manage branch protection rules",
+		Long:    "Manage branch protection rules. Pushes to branches matching a rule require its access level, and can't delete or force push them unless the rule allows it.",
+	}
+
+	cmd.AddCommand(
+		branchProtectionAddCommand(),
+		branchProtectionRemoveCommand(),
+		branchProtectionListCommand(),
+	)
+
+	return cmd
+}
+
+func branchProtectionAddCommand() *cobra.Command {
+	var allowForce bool
+	cmd := &cobra.Command{
+		Use:               "add REPOSITORY PATTERN [LEVEL]",
+		Short:             "Protect the branches matching a pattern",
+		Long:              "Protect the branches matching a glob pattern, such as main or release/*. LEVEL is the access level required to push, one of: no-access, read-only, read-write, or admin-access. Defaults to admin-access.",
+		Args:              cobra.RangeArgs(2, 3),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			level := access.AdminAccess
+			if len(args) > 2 {
+				level = access.ParseAccessLevel(args[2])
+				if level < 0 {
+					return access.ErrInvalidAccessLevel
+				}
+			}
+
+			return be.AddBranchProtection(ctx, args[0], args[1], level, allowForce)
+		},
+	}
+
+	cmd.Flags().BoolVar(&allowForce, "allow-force", false, "allow force pushes and deletions")
+
+	return cmd
+}
+
+func branchProtectionRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY PATTERN",
+		Short:             "Remove a branch protection rule",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.RemoveBranchProtection(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func branchProtectionListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Short:             "List the branch protection rules of a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rules, err := be.BranchProtections(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, r := range rules {
+				force := "no-force"
+				if r.AllowForce {
+					force = "allow-force"
+				}
+				cmd.Printf("%s\t%s\t%s\n", r.Pattern, r.AccessLevel, force)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -195,3 +195,13 @@ func checkIfReadableAndCollab(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+func checkIfReadableAndAdmin(cmd *cobra.Command, args []string) error {
+	if err := checkIfReadable(cmd, args); err != nil {
+		return err
+	}
+	if err := checkIfAdmin(cmd, args); err != nil {
+		return err
+	}
+	return nil
+}
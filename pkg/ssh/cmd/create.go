@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
@@ -15,6 +16,8 @@ func createCommand() *cobra.Command {
 	var description string
 	var projectName string
 	var hidden bool
+	var template string
+	var defaultBranch string
 
 	cmd := &cobra.Command{
 		Use:               "create REPOSITORY",
@@ -27,11 +30,19 @@ func createCommand() *cobra.Command {
 			be := backend.FromContext(ctx)
 			user := proto.UserFromContext(ctx)
 			name := args[0]
+
+			// Don't hint that the template exists if the user can't read it.
+			if template != "" && be.AccessLevelForUser(ctx, template, user) < access.ReadOnlyAccess {
+				return proto.ErrRepoNotFound
+			}
+
 			r, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{
-				Private:     private,
-				Description: description,
-				ProjectName: projectName,
-				Hidden:      hidden,
+				Private:       private,
+				Description:   description,
+				ProjectName:   projectName,
+				Hidden:        hidden,
+				Template:      template,
+				DefaultBranch: defaultBranch,
 			})
 			if err != nil {
 				return err
@@ -49,6 +60,8 @@ func createCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&description, "description", "d", "", "set the repository description")
 	cmd.Flags().StringVarP(&projectName, "name", "n", "", "set the project name")
 	cmd.Flags().BoolVarP(&hidden, "hidden", "H", false, "hide the repository from the UI")
+	cmd.Flags().StringVarP(&template, "from-template", "t", "", "start from the default branch of a template repository")
+	cmd.Flags().StringVar(&defaultBranch, "default-branch", "", "set the default branch instead of the configured one")
 
 	return cmd
 }
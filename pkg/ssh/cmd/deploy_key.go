@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/spf13/cobra"
+)
+
+func deployKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deploy-key",
+		Aliases: []string{"deploy-keys"},
+		Short:   "Manage deploy keys",
+		Long:    "Manage the deploy keys of a repo. A deploy key is an SSH key that isn't a user's, it authenticates with access to its repo only, like a CI system cloning a single repo.",
+	}
+
+	cmd.AddCommand(
+		deployKeyAddCommand(),
+		deployKeyRemoveCommand(),
+		deployKeyListCommand(),
+	)
+
+	return cmd
+}
+
+func deployKeyAddCommand() *cobra.Command {
+	var level string
+	cmd := &cobra.Command{
+		Use:               "add REPOSITORY TITLE AUTHORIZED_KEY",
+		Short:             "Add a deploy key to a repo",
+		Long:              "Add a deploy key to a repo. The key has read-only access to the repo unless --access is read-write, and no access to other repos.",
+		Args:              cobra.MinimumNArgs(3),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			al := access.ParseAccessLevel(level)
+			if al < 0 {
+				return access.ErrInvalidAccessLevel
+			}
+
+			pk, _, err := sshutils.ParseAuthorizedKey(strings.Join(args[2:], " "))
+			if err != nil {
+				return err
+			}
+
+			return be.AddDeployKey(ctx, args[0], args[1], pk, al)
+		},
+	}
+
+	cmd.Flags().StringVarP(&level, "access", "a", access.ReadOnlyAccess.String(), "Access level of the key (read-only or read-write)")
+
+	return cmd
+}
+
+func deployKeyRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY TITLE",
+		Short:             "Remove a deploy key from a repo",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.RemoveDeployKey(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func deployKeyListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Short:             "List the deploy keys of a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			keys, err := be.DeployKeys(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, k := range keys {
+				cmd.Println(k.Title, k.AccessLevel, k.PublicKey)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
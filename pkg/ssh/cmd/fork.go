@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/spf13/cobra"
+)
+
+// forkCommand is the command for forking a repository.
+func forkCommand() *cobra.Command {
+	var private bool
+	var description string
+	var projectName string
+	var hidden bool
+
+	cmd := &cobra.Command{
+		Use:   "fork SOURCE REPOSITORY",
+		Short: "Fork a repository",
+		Long:  "Create a repository with the branches and tags of a source repository. The fork is independent, but shares the objects of the source instead of copying them. Forks of private repositories are private.",
+		Args:  cobra.ExactArgs(2),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkIfReadable(cmd, args[:1]); err != nil {
+				return err
+			}
+			return checkIfCollab(cmd, args[1:])
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			src, name := args[0], args[1]
+
+			parent, err := be.Repository(ctx, src)
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("description") {
+				description = parent.Description()
+			}
+			if !cmd.Flags().Changed("name") {
+				projectName = parent.ProjectName()
+			}
+
+			r, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{
+				Private:     private,
+				Description: description,
+				ProjectName: projectName,
+				Hidden:      hidden,
+				Fork:        parent.Name(),
+			})
+			if err != nil {
+				return err
+			}
+
+			cloneurl := fmt.Sprintf("%s/%s.git", cfg.SSH.PublicURL, r.Name())
+			cmd.PrintErrf("Forked repository %s to %s\n", parent.Name(), r.Name())
+			cmd.Println(cloneurl)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&private, "private", "p", false, "make the repository private")
+	cmd.Flags().StringVarP(&description, "description", "d", "", "set the repository description, the source's by default")
+	cmd.Flags().StringVarP(&projectName, "name", "n", "", "set the project name, the source's by default")
+	cmd.Flags().BoolVarP(&hidden, "hidden", "H", false, "hide the repository from the UI")
+
+	return cmd
+}
@@ -8,6 +8,7 @@ import (
 
 	"charm.land/log/v2"
 	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/audit"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/git"
@@ -176,7 +177,11 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 	pk := sshutils.PublicKeyFromContext(ctx)
 	ak := sshutils.MarshalAuthorizedKey(pk)
 	user := proto.UserFromContext(ctx)
-	accessLevel := be.AccessLevelForUser(ctx, name, user)
+	service := git.Service(cmd.Name())
+	if sess := sshutils.SessionFromContext(ctx); sess != nil {
+		ctx = audit.WithSource(ctx, audit.Source{Transport: "ssh", RemoteAddr: sess.RemoteAddr().String()})
+	}
+	accessLevel, _ := be.Authorize(ctx, name, user, service.String(), requiredAccess(service, args))
 	// git bare repositories should end in ".git"
 	// https://git-scm.com/docs/gitrepository-layout
 	repoDir := name + ".git"
@@ -185,6 +190,18 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Operations can't run while the repository is being renamed, and pushes
+	// keep it from being garbage collected.
+	acquire := be.AcquireRepository
+	if service == git.ReceivePackService {
+		acquire = be.AcquireRepositoryPush
+	}
+	release, err := acquire(name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// Set repo in context
 	repo, _ := be.Repository(ctx, name)
 	ctx = proto.WithRepositoryContext(ctx, repo)
@@ -194,6 +211,7 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 		"SOFT_SERVE_REPO_NAME=" + name,
 		"SOFT_SERVE_REPO_PATH=" + filepath.Join(reposDir, repoDir),
 		"SOFT_SERVE_PUBLIC_KEY=" + ak,
+		"SOFT_SERVE_ACCESS_LEVEL=" + accessLevel.String(),
 		"SOFT_SERVE_LOG_PATH=" + filepath.Join(cfg.DataPath, "log", "hooks.log"),
 	}
 
@@ -216,17 +234,19 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 	}
 
 	repoPath := filepath.Join(reposDir, repoDir)
-	service := git.Service(cmd.Name())
 	stdin := cmd.InOrStdin()
 	stdout := cmd.OutOrStdout()
 	stderr := cmd.ErrOrStderr()
 	scmd := git.ServiceCommand{
-		Stdin:  stdin,
-		Stdout: stdout,
-		Stderr: stderr,
-		Env:    envs,
-		Dir:    repoPath,
+		Stdin:      stdin,
+		Stdout:     stdout,
+		Stderr:     stderr,
+		Env:        envs,
+		Dir:        repoPath,
+		OnComplete: git.ObserveService("ssh", service),
+		Pack:       be.ServicePackOptions(ctx, name, service),
 	}
+	be.TrackActivity(ctx, &scmd, name, user, service.String())
 
 	switch service {
 	case git.ReceivePackService:
@@ -234,10 +254,21 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 		defer func() {
 			receivePackSeconds.WithLabelValues(name).Add(time.Since(start).Seconds())
 		}()
+		// Archived repositories are read-only, tell whoever can read them.
+		if repo != nil && repo.IsArchived() && accessLevel >= access.ReadOnlyAccess {
+			return git.ErrArchivedPush
+		}
 		if accessLevel < access.ReadWriteAccess {
 			return git.ErrNotAuthed
 		}
+		if repo != nil && repo.IsMirror() {
+			return git.ErrMirrorPush
+		}
 		if repo == nil {
+			if !be.AllowPushCreate(ctx, user) {
+				logger.Info("push rejected", "err", git.ErrPushCreateDisabled, "repo", name)
+				return git.ErrPushCreateDisabled
+			}
 			if _, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{Private: false}); err != nil {
 				log.Errorf("failed to create repo: %s", err)
 				return err
@@ -245,8 +276,16 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			createRepoCounter.WithLabelValues(name).Inc()
 		}
 
+		scmd.Repo = name
+		scmd.Quota = be.RepositoryQuota(ctx)
+		scmd.DenyNonFastForward = cfg.Repo.DenyNonFastForwards
+		scmd.MaxPackBytes = cfg.Repo.MaxPackBytes
+		if bitmaps, err := be.BitmapsEnabled(ctx, name); err != nil {
+			logger.Error("error getting bitmaps setting", "repo", name, "err", err)
+		} else {
+			scmd.WriteBitmaps = bitmaps
+		}
 		if err := service.Handler(ctx, scmd); err != nil {
-			logger.Error("failed to handle git service", "service", service, "err", err, "repo", name)
 			defer func() {
 				if repo == nil {
 					// If the repo was created, but the request failed, delete it.
@@ -254,7 +293,17 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 				}
 			}()
 
-			return git.ErrSystemMalfunction
+			if errors.Is(err, git.ErrQuotaExceeded) {
+				logger.Info("push rejected", "err", err, "repo", name)
+				return git.ErrQuotaExceeded
+			}
+			if errors.Is(err, git.ErrPackTooLarge) {
+				logger.Info("push rejected", "err", err, "repo", name)
+				return git.ErrPackTooLarge
+			}
+
+			logger.Error("failed to handle git service", "service", service, "err", err, "repo", name)
+			return serviceError(err)
 		}
 
 		if err := git.EnsureDefaultBranch(ctx, scmd.Dir); err != nil {
@@ -274,6 +323,9 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			return git.ErrInvalidRepo
 		}
 
+		scmd.DisableFilter = be.FiltersDisabled(ctx, name)
+		scmd.AllowedFilters = cfg.Repo.AllowedFilters
+
 		switch service {
 		case git.UploadArchiveService:
 			uploadArchiveCounter.WithLabelValues(name).Inc()
@@ -292,7 +344,7 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			return git.ErrInvalidRepo
 		} else if err != nil {
 			logger.Error("failed to handle git service", "service", service, "err", err, "repo", name)
-			return git.ErrSystemMalfunction
+			return serviceError(err)
 		}
 
 		return nil
@@ -333,6 +385,8 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			}()
 		}
 
+		// The lock backend needs the access level to allow force unlocks.
+		ctx = access.WithContext(ctx, accessLevel)
 		if err := service.Handler(ctx, scmd); err != nil {
 			logger.Error("failed to handle lfs service", "service", service, "err", err, "repo", name)
 			return git.ErrSystemMalfunction
@@ -343,3 +397,39 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 
 	return errors.New("unsupported git service")
 }
+
+// requiredAccess returns the access level a git service needs. Pushes and
+// LFS uploads need write access, everything else read access.
+func requiredAccess(service git.Service, args []string) access.AccessLevel {
+	switch service {
+	case git.ReceivePackService:
+		return access.ReadWriteAccess
+	case git.LFSTransferService, git.LFSAuthenticateService:
+		if len(args) > 1 && args[1] == lfs.OperationUpload {
+			return access.ReadWriteAccess
+		}
+	}
+
+	return access.ReadOnlyAccess
+}
+
+// gitServiceError hides the details of a failed git service from the client
+// while keeping the exit code of the git process.
+type gitServiceError struct {
+	*git.ServiceError
+}
+
+// Error implements error.
+func (gitServiceError) Error() string {
+	return git.ErrSystemMalfunction.Error()
+}
+
+// serviceError returns the error to report to the client for a failed git
+// service.
+func serviceError(err error) error {
+	var serr *git.ServiceError
+	if errors.As(err, &serr) {
+		return gitServiceError{serr}
+	}
+	return git.ErrSystemMalfunction
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func hookEnvCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hook-env",
+		Short: "Manage hook environment variables",
+		Long:  "Manage the environment variables passed to the custom hooks of a repo. Values are encrypted on the server. SOFT_SERVE_* and GIT_* variables are reserved.",
+	}
+
+	cmd.AddCommand(
+		hookEnvSetCommand(),
+		hookEnvUnsetCommand(),
+		hookEnvListCommand(),
+	)
+
+	return cmd
+}
+
+func hookEnvSetCommand() *cobra.Command {
+	var secret bool
+	cmd := &cobra.Command{
+		Use:               "set REPOSITORY NAME [VALUE]",
+		Short:             "Set a hook environment variable",
+		Long:              "Set a hook environment variable of a repo. The value is read from stdin when VALUE is missing, which keeps secrets out of the shell history. The values of secrets aren't listed.",
+		Args:              cobra.RangeArgs(2, 3),
+		PersistentPreRunE: checkIfReadableAndAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			var value string
+			if len(args) > 2 {
+				value = args[2]
+			} else {
+				b, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("error reading value: %w", err)
+				}
+				value = strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r")
+			}
+
+			return be.SetHookEnv(ctx, args[0], args[1], value, secret)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&secret, "secret", "s", false, "don't show the value when listing variables")
+
+	return cmd
+}
+
+func hookEnvUnsetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "unset REPOSITORY NAME",
+		Aliases:           []string{"remove", "rm"},
+		Short:             "Remove a hook environment variable",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadableAndAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.RemoveHookEnv(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func hookEnvListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Aliases:           []string{"ls"},
+		Short:             "List the hook environment variables of a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadableAndAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			vars, err := be.HookEnv(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, v := range vars {
+				if v.Secret {
+					cmd.Printf("%s=******** (secret)\n", v.Name)
+					continue
+				}
+				cmd.Printf("%s=%s\n", v.Name, v.Value)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
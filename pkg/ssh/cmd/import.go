@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"time"
 
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
@@ -18,10 +21,14 @@ func importCommand() *cobra.Command {
 	var hidden bool
 	var lfs bool
 	var lfsEndpoint string
+	var mirrorInterval time.Duration
+	var token string
+	var sshKey string
 
 	cmd := &cobra.Command{
 		Use:               "import REPOSITORY REMOTE",
 		Short:             "Import a new repository from remote",
+		Long:              "Import a new repository from remote. HTTP remotes can be authenticated with a token, and SSH remotes with a private key instead of the server key. Mirrors keep using them to sync.",
 		Args:              cobra.ExactArgs(2),
 		PersistentPreRunE: checkIfCollab,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -30,6 +37,11 @@ func importCommand() *cobra.Command {
 			user := proto.UserFromContext(ctx)
 			name := args[0]
 			remote := args[1]
+			sshKey, err := readSSHKey(cmd, sshKey)
+			if err != nil {
+				return err
+			}
+
 			if _, err := be.ImportRepository(ctx, name, user, remote, proto.RepositoryOptions{
 				Private:     private,
 				Description: description,
@@ -38,6 +50,11 @@ func importCommand() *cobra.Command {
 				Hidden:      hidden,
 				LFS:         lfs,
 				LFSEndpoint: lfsEndpoint,
+
+				MirrorInterval: mirrorInterval,
+				Token:          token,
+				SSHKey:         sshKey,
+				Progress:       cmd.ErrOrStderr(),
 			}); err != nil {
 				if errors.Is(err, task.ErrAlreadyStarted) {
 					return errors.New("import already in progress")
@@ -53,10 +70,28 @@ func importCommand() *cobra.Command {
 	cmd.Flags().BoolVarP(&lfs, "lfs", "", false, "pull Git LFS objects")
 	cmd.Flags().StringVarP(&lfsEndpoint, "lfs-endpoint", "", "", "set the Git LFS endpoint")
 	cmd.Flags().BoolVarP(&mirror, "mirror", "m", false, "mirror the repository")
+	cmd.Flags().DurationVarP(&mirrorInterval, "mirror-interval", "", 0, "minimum time between mirror syncs")
 	cmd.Flags().BoolVarP(&private, "private", "p", false, "make the repository private")
 	cmd.Flags().StringVarP(&description, "description", "d", "", "set the repository description")
 	cmd.Flags().StringVarP(&projectName, "name", "n", "", "set the project name")
 	cmd.Flags().BoolVarP(&hidden, "hidden", "H", false, "hide the repository from the UI")
+	cmd.Flags().StringVarP(&token, "token", "t", "", "token to authenticate to an HTTP remote")
+	cmd.Flags().StringVarP(&sshKey, "ssh-key", "", "", "private key to authenticate to an SSH remote, use - to read it from stdin")
 
 	return cmd
 }
+
+// readSSHKey returns an SSH key flag value, reading it from the command input
+// when it's "-".
+func readSSHKey(cmd *cobra.Command, key string) (string, error) {
+	if key != "-" {
+		return key, nil
+	}
+
+	b, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("error reading ssh key: %w", err)
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+// IPCommand returns a command that manages the ip rules.
+func IPCommand() *cobra.Command {
+	var username string
+	cmd := &cobra.Command{
+		Use:               "ip",
+		Short:             "Manage ip allow and deny rules",
+		Long:              "Manage the ip addresses and CIDR ranges clients can connect from. A client is refused when its address matches a deny rule, or when there are allow rules and it matches none of them. Rules apply to every connection, or to the connections of a user with --user.",
+		PersistentPreRunE: checkIfServerAdmin,
+	}
+
+	cmd.PersistentFlags().StringVarP(&username, "user", "u", "", "manage the rules of a user")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "allow CIDR",
+			Short: "Allow clients connecting from an address range",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ctx := cmd.Context()
+				be := backend.FromContext(ctx)
+
+				return be.AddIPRule(ctx, username, args[0], true)
+			},
+		},
+		&cobra.Command{
+			Use:   "deny CIDR",
+			Short: "Deny clients connecting from an address range",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ctx := cmd.Context()
+				be := backend.FromContext(ctx)
+
+				return be.AddIPRule(ctx, username, args[0], false)
+			},
+		},
+		&cobra.Command{
+			Use:     "remove CIDR",
+			Aliases: []string{"rm"},
+			Short:   "Remove the rule of an address range",
+			Args:    cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ctx := cmd.Context()
+				be := backend.FromContext(ctx)
+
+				return be.RemoveIPRule(ctx, username, args[0])
+			},
+		},
+		&cobra.Command{
+			Use:     "list",
+			Aliases: []string{"ls"},
+			Short:   "List the ip rules",
+			Args:    cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				ctx := cmd.Context()
+				be := backend.FromContext(ctx)
+				rules, err := be.IPRules(ctx, username)
+				if err != nil {
+					return err
+				}
+
+				for _, r := range rules {
+					action := "deny"
+					if r.Allow {
+						action = "allow"
+					}
+					cmd.Printf("%s\t%s\n", action, r.CIDR)
+				}
+
+				return nil
+			},
+		},
+	)
+
+	return cmd
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/sshutils"
 	"github.com/spf13/cobra"
 )
@@ -10,6 +12,8 @@ import (
 // listCommand returns a command that list file or directory at path.
 func listCommand() *cobra.Command {
 	var all bool
+	var topic string
+	var template bool
 
 	listCmd := &cobra.Command{
 		Use:     "list",
@@ -18,15 +22,25 @@ func listCommand() *cobra.Command {
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
 			be := backend.FromContext(ctx)
 			pk := sshutils.PublicKeyFromContext(ctx)
-			repos, err := be.Repositories(ctx)
+			var repos []proto.Repository
+			var err error
+			if topic != "" {
+				repos, err = be.RepositoriesByTopic(ctx, topic)
+			} else {
+				repos, err = be.Repositories(ctx)
+			}
 			if err != nil {
 				return err
 			}
 			for _, r := range repos {
+				if template && !r.IsTemplate() {
+					continue
+				}
 				if be.AccessLevelByPublicKey(ctx, r.Name(), pk) >= access.ReadOnlyAccess {
-					if !r.IsHidden() || all {
+					if all || !r.IsHidden() && !(r.IsArchived() && cfg.Repo.HideArchived) {
 						cmd.Println(r.Name())
 					}
 				}
@@ -35,7 +49,9 @@ func listCommand() *cobra.Command {
 		},
 	}
 
-	listCmd.Flags().BoolVarP(&all, "all", "a", false, "List all repositories")
+	listCmd.Flags().BoolVarP(&all, "all", "a", false, "List all repositories, including hidden and archived ones")
+	listCmd.Flags().StringVarP(&topic, "topic", "t", "", "List repositories with a topic")
+	listCmd.Flags().BoolVarP(&template, "template", "T", false, "List template repositories")
 
 	return listCmd
 }
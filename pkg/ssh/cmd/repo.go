@@ -3,9 +3,12 @@ package cmd
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 )
 
@@ -18,90 +21,173 @@ func RepoCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(
+		repoAccessCommand(),
+		activityCommand(),
+		archiveCommand(),
 		blobCommand(),
 		branchCommand(),
 		collabCommand(),
 		commitCommand(),
 		createCommand(),
 		deleteCommand(),
+		deployKeyCommand(),
 		descriptionCommand(),
+		forkCommand(),
+		fsckCommand(),
+		gcCommand(),
 		hiddenCommand(),
+		hookEnvCommand(),
 		importCommand(),
 		listCommand(),
 		mirrorCommand(),
 		privateCommand(),
 		projectName(),
 		renameCommand(),
+		repoSettingsCommand(),
+		signerCommand(),
 		tagCommand(),
+		repoTeamCommand(),
+		templateCommand(),
+		topicCommand(),
+		transferCommand(),
 		treeCommand(),
+		unarchiveCommand(),
 		webhookCommand(),
 	)
 
-	cmd.AddCommand(
-		&cobra.Command{
-			Use:               "info REPOSITORY",
-			Short:             "Get information about a repository",
-			Args:              cobra.ExactArgs(1),
-			PersistentPreRunE: checkIfReadable,
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := cmd.Context()
-				be := backend.FromContext(ctx)
-				rn := args[0]
-				rr, err := be.Repository(ctx, rn)
-				if err != nil {
-					return err
-				}
+	var stats bool
+	infoCmd := &cobra.Command{
+		Use:               "info REPOSITORY",
+		Short:             "Get information about a repository",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := args[0]
+			rr, err := be.Repository(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			r, err := rr.Open()
+			if err != nil {
+				return err
+			}
+
+			head, err := r.HEAD()
+			if err != nil {
+				return err
+			}
 
-				r, err := rr.Open()
+			var owner proto.User
+			if rr.UserID() > 0 {
+				owner, err = be.UserByID(ctx, rr.UserID())
 				if err != nil {
 					return err
 				}
+			}
 
-				head, err := r.HEAD()
+			branches, _ := r.Branches()
+			tags, _ := r.Tags()
+			topics, err := be.RepoTopics(ctx, rn)
+			if err != nil {
+				return err
+			}
+			patterns, err := be.SparsePatterns(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			// project name and description are optional, handle trailing
+			// whitespace to avoid breaking tests.
+			cmd.Println(strings.TrimSpace(fmt.Sprint("Project Name: ", rr.ProjectName())))
+			cmd.Println("Repository:", rr.Name())
+			cmd.Println(strings.TrimSpace(fmt.Sprint("Description: ", rr.Description())))
+			cmd.Println("Private:", rr.IsPrivate())
+			cmd.Println("Hidden:", rr.IsHidden())
+			cmd.Println("Mirror:", rr.IsMirror())
+			if rr.IsArchived() {
+				cmd.Println("Archived:", rr.IsArchived())
+			}
+			if rr.IsTemplate() {
+				cmd.Println("Template:", rr.IsTemplate())
+			}
+			parent, err := be.RepositoryParent(ctx, rr)
+			if err != nil {
+				return err
+			}
+			// Don't hint that the parent exists if the user can't read it.
+			if parent != nil && be.AccessLevelForUser(ctx, parent.Name(), proto.UserFromContext(ctx)) >= access.ReadOnlyAccess {
+				cmd.Println("Fork Of:", parent.Name())
+			}
+			if rr.IsMirror() {
+				if m, err := be.MirrorConfig(ctx, rr); err == nil {
+					cmd.Println("Upstream:", m.RemoteURL)
+					if m.LastSyncedAt.Valid {
+						cmd.Println("Last Synced:", m.LastSyncedAt.Time.Format(time.RFC3339))
+					}
+					if m.LastError.Valid {
+						cmd.Println("Last Sync Error:", m.LastError.String)
+					}
+				}
+			}
+			if owner != nil {
+				cmd.Println(strings.TrimSpace(fmt.Sprint("Owner: ", owner.Username())))
+			}
+			cmd.Println("Default Branch:", head.Name().Short())
+			if len(topics) > 0 {
+				cmd.Println("Topics:", strings.Join(topics, ", "))
+			}
+			if len(patterns) > 0 {
+				cmd.Println("Sparse Patterns:")
+				for _, p := range patterns {
+					cmd.Println("  -", p)
+				}
+			}
+			if len(branches) > 0 {
+				cmd.Println("Branches:")
+				for _, b := range branches {
+					cmd.Println("  -", b)
+				}
+			}
+			if len(tags) > 0 {
+				cmd.Println("Tags:")
+				for _, t := range tags {
+					cmd.Println("  -", t)
+				}
+			}
+			if stats {
+				st, err := be.RepoStats(ctx, rr)
 				if err != nil {
 					return err
 				}
 
-				var owner proto.User
-				if rr.UserID() > 0 {
-					owner, err = be.UserByID(ctx, rr.UserID())
-					if err != nil {
-						return err
-					}
+				cmd.Println("Size:", humanize.Bytes(uint64(st.Size))) //nolint:gosec
+				cmd.Println("Commits:", st.Commits)
+				if st.LastPushedAt.Valid {
+					cmd.Println("Last Pushed:", st.LastPushedAt.Time.UTC().Format(time.RFC3339))
 				}
+				cmd.Println("Stats Updated:", st.UpdatedAt.UTC().Format(time.RFC3339))
 
-				branches, _ := r.Branches()
-				tags, _ := r.Tags()
-
-				// project name and description are optional, handle trailing
-				// whitespace to avoid breaking tests.
-				cmd.Println(strings.TrimSpace(fmt.Sprint("Project Name: ", rr.ProjectName())))
-				cmd.Println("Repository:", rr.Name())
-				cmd.Println(strings.TrimSpace(fmt.Sprint("Description: ", rr.Description())))
-				cmd.Println("Private:", rr.IsPrivate())
-				cmd.Println("Hidden:", rr.IsHidden())
-				cmd.Println("Mirror:", rr.IsMirror())
-				if owner != nil {
-					cmd.Println(strings.TrimSpace(fmt.Sprint("Owner: ", owner.Username())))
-				}
-				cmd.Println("Default Branch:", head.Name().Short())
-				if len(branches) > 0 {
-					cmd.Println("Branches:")
-					for _, b := range branches {
-						cmd.Println("  -", b)
-					}
+				langs, err := be.RepoLanguages(ctx, rr)
+				if err != nil {
+					return err
 				}
-				if len(tags) > 0 {
-					cmd.Println("Tags:")
-					for _, t := range tags {
-						cmd.Println("  -", t)
+				if len(langs) > 0 {
+					cmd.Println("Languages:")
+					for _, l := range langs {
+						cmd.Printf("  - %s %.1f%%\n", l.Name, l.Percent)
 					}
 				}
+			}
 
-				return nil
-			},
+			return nil
 		},
-	)
+	}
+
+	infoCmd.Flags().BoolVarP(&stats, "stats", "s", false, "show the repository size, commit count, last push time, and languages")
+	cmd.AddCommand(infoCmd)
 
 	return cmd
 }
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func repoAccessCommand() *cobra.Command {
+	var anon string
+	cmd := &cobra.Command{
+		Use:               "access REPOSITORY",
+		Short:             "Set or get the anonymous access level of a repository",
+		Long:              "Set or get the access level anonymous users get to a repository. Set it with --anon to inherit, none, or read. The level of the repository takes precedence over the server anon-access setting, which applies with inherit. Private repositories stay hidden from anonymous users.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			if !cmd.Flags().Changed("anon") {
+				level, ok, err := be.RepoAnonAccess(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				if ok {
+					cmd.Printf("anon: %s\n", level)
+				} else {
+					cmd.Printf("anon: %s (inherited)\n", level)
+				}
+				return nil
+			}
+
+			var level access.AccessLevel
+			switch anon {
+			case "inherit":
+				level = -1
+			case "none", access.NoAccess.String():
+				level = access.NoAccess
+			case "read", access.ReadOnlyAccess.String():
+				level = access.ReadOnlyAccess
+			default:
+				return fmt.Errorf("invalid anonymous access %q, choose one of: inherit, none, read", anon)
+			}
+
+			if err := checkIfCollab(cmd, args); err != nil {
+				return err
+			}
+
+			return be.SetRepoAnonAccess(ctx, rn, level)
+		},
+	}
+
+	cmd.Flags().StringVar(&anon, "anon", "", "anonymous access level: inherit, none, or read")
+
+	return cmd
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/spf13/cobra"
+)
+
+func fsckCommand() *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:               "fsck [REPOSITORY]",
+		Short:             "Check the integrity of a repository",
+		Long:              "Run git fsck --full on a repository, or on all of them with --all, and report its dangling and corrupt objects. The command fails if any repository is corrupt.",
+		Args:              cobra.MaximumNArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if all == (len(args) == 1) {
+				return errors.New("specify a repository or --all")
+			}
+
+			var repos []proto.Repository
+			if all {
+				var err error
+				repos, err = be.Repositories(ctx)
+				if err != nil {
+					return err
+				}
+			} else {
+				rr, err := be.Repository(ctx, strings.TrimSuffix(args[0], ".git"))
+				if err != nil {
+					return err
+				}
+				repos = append(repos, rr)
+			}
+
+			var corrupt, failed []string
+			for _, rr := range repos {
+				res, err := be.FsckRepository(ctx, rr, cmd.ErrOrStderr())
+				if err != nil {
+					if !all {
+						return err
+					}
+					cmd.PrintErrf("%s: %s\n", rr.Name(), err)
+					failed = append(failed, rr.Name())
+					continue
+				}
+
+				status := "ok"
+				if res.Corrupt() {
+					status = "corrupt"
+					corrupt = append(corrupt, rr.Name())
+				}
+				cmd.Printf("%s: %s, %d problem(s), %d dangling object(s)\n", rr.Name(), status, len(res.Problems), len(res.Dangling))
+				for _, p := range res.Problems {
+					cmd.Printf("  %s\n", p)
+				}
+				for _, d := range res.Dangling {
+					cmd.Printf("  dangling %s\n", d)
+				}
+			}
+
+			switch {
+			case len(corrupt) > 0:
+				return fmt.Errorf("corrupt repositories: %s", strings.Join(corrupt, ", "))
+			case len(failed) > 0:
+				return fmt.Errorf("failed to check repositories: %s", strings.Join(failed, ", "))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "check all repositories")
+
+	return cmd
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func gcCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "gc REPOSITORY",
+		Short:             "Garbage collect a repository",
+		Long:              "Run git gc on a repository to pack its loose objects and remove unreachable ones. Repositories with a push running can't be collected.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+			rr, err := be.Repository(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			res, err := be.GCRepository(ctx, rr)
+			if err != nil {
+				return err
+			}
+
+			reclaimed := max(res.Reclaimed, 0)
+			cmd.Printf("Reclaimed %s in %s\n", humanize.Bytes(uint64(reclaimed)), res.Duration.Round(time.Millisecond)) //nolint:gosec
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func gcSettingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "gc REPOSITORY [true|false]",
+		Short:             "Enable or disable scheduled garbage collection",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			switch len(args) {
+			case 1:
+				enabled, err := be.GCEnabled(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(enabled)
+			case 2:
+				enabled, err := strconv.ParseBool(args[1])
+				if err != nil {
+					return err
+				}
+				if err := checkIfAdmin(cmd, args); err != nil {
+					return err
+				}
+				if err := be.SetGCEnabled(ctx, rn, enabled); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func bitmapsSettingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "bitmaps REPOSITORY [true|false]",
+		Short:             "Enable or disable commit-graph and bitmap maintenance",
+		Long:              "Enable or disable maintaining the commit-graph and the reachability bitmap of the repository with the garbage collection job, which speeds up clones and fetches of large repositories.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			switch len(args) {
+			case 1:
+				enabled, err := be.BitmapsEnabled(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(enabled)
+			case 2:
+				enabled, err := strconv.ParseBool(args[1])
+				if err != nil {
+					return err
+				}
+				if err := checkIfAdmin(cmd, args); err != nil {
+					return err
+				}
+				if err := be.SetBitmapsEnabled(ctx, rn, enabled); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+func repoSettingsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "settings",
+		Short: "Manage repository settings",
+	}
+
+	cmd.AddCommand(
+		bitmapsSettingCommand(),
+		commitMessagePatternCommand(),
+		commitMessageCheckCommand(),
+		exportSettingCommand(),
+		filtersSettingCommand(),
+		gcSettingCommand(),
+		packSettingCommand(),
+		refCacheSettingCommand(),
+		signingFormatCommand(),
+		sparsePatternsCommand(),
+	)
+
+	return cmd
+}
+
+func commitMessagePatternCommand() *cobra.Command {
+	var unset bool
+
+	cmd := &cobra.Command{
+		Use:               "commit-message-pattern REPOSITORY [PATTERN]",
+		Short:             "Set or get the pattern commit subjects must match",
+		Long:              "Set or get the regular expression the subject of every pushed commit must match when the commit message check is enabled.",
+		Args:              cobra.MinimumNArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			switch {
+			case unset || len(args) > 1:
+				if err := checkIfAdmin(cmd, args); err != nil {
+					return err
+				}
+
+				var pattern string
+				if !unset {
+					pattern = strings.Join(args[1:], " ")
+				}
+
+				return be.SetCommitMessagePattern(ctx, rn, pattern)
+			default:
+				pattern, err := be.CommitMessagePattern(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(pattern)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&unset, "unset", "", false, "remove the pattern and disable the check")
+
+	return cmd
+}
+
+func commitMessageCheckCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "commit-message-check REPOSITORY [true|false]",
+		Short:             "Enable or disable checking pushed commit subjects",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			switch len(args) {
+			case 1:
+				enabled, err := be.CommitMessageCheck(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(enabled)
+			case 2:
+				enabled, err := strconv.ParseBool(args[1])
+				if err != nil {
+					return err
+				}
+				if err := checkIfAdmin(cmd, args); err != nil {
+					return err
+				}
+				if err := be.SetCommitMessageCheck(ctx, rn, enabled); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func signingFormatCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "signing-format REPOSITORY [gpg|ssh|any]",
+		Short:             "Set or get the signature format of pushed commits",
+		Long:              "Set or get the signature format commits pushed to a repository with signers must be signed in: gpg, ssh, or any, the default.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			switch len(args) {
+			case 1:
+				format, err := be.SigningFormat(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(format)
+			case 2:
+				format, err := git.ParseSignatureFormat(args[1])
+				if err != nil {
+					return err
+				}
+				if err := checkIfAdmin(cmd, args); err != nil {
+					return err
+				}
+				if err := be.SetSigningFormat(ctx, rn, format); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func exportSettingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "export REPOSITORY [true|false]",
+		Short:             "Enable or disable serving the repository over git://",
+		Long:              "Enable or disable anonymous read-only access to the repository over the git daemon. Private repositories can't be exported.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			switch len(args) {
+			case 1:
+				exported, err := be.IsExported(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(exported)
+			case 2:
+				export, err := strconv.ParseBool(args[1])
+				if err != nil {
+					return err
+				}
+				if err := checkIfAdmin(cmd, args); err != nil {
+					return err
+				}
+				if err := be.SetExported(ctx, rn, export); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func filtersSettingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "filters REPOSITORY [true|false|inherit]",
+		Short:             "Enable or disable partial clones",
+		Long:              "Enable or disable partial clone filters, like blob:none, when fetching the repository. The setting of the repository takes precedence over the server repo.disable_filters setting, which applies with inherit.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			if len(args) == 1 {
+				enabled, ok, err := be.RepoFilters(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				if ok {
+					cmd.Println(enabled)
+				} else {
+					cmd.Println(enabled, "(inherited)")
+				}
+				return nil
+			}
+
+			if args[1] == "inherit" {
+				if err := checkIfAdmin(cmd, args); err != nil {
+					return err
+				}
+				return be.ResetRepoFilters(ctx, rn)
+			}
+
+			enabled, err := strconv.ParseBool(args[1])
+			if err != nil {
+				return err
+			}
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			return be.SetRepoFilters(ctx, rn, enabled)
+		},
+	}
+
+	return cmd
+}
+
+func sparsePatternsCommand() *cobra.Command {
+	var unset bool
+
+	cmd := &cobra.Command{
+		Use:               "sparse-patterns REPOSITORY [DIRECTORY...]",
+		Short:             "Set or get the recommended sparse-checkout directories",
+		Long:              "Set or get the directories recommended to check out with git sparse-checkout in cone mode. They're shown in the repository info and on its web page, with the commands to clone only them.",
+		Args:              cobra.MinimumNArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			if !unset && len(args) == 1 {
+				patterns, err := be.SparsePatterns(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				for _, p := range patterns {
+					cmd.Println(p)
+				}
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			var patterns []string
+			if !unset {
+				patterns = args[1:]
+			}
+
+			return be.SetSparsePatterns(ctx, rn, patterns)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&unset, "unset", "", false, "remove the recommended directories")
+
+	return cmd
+}
+
+func refCacheSettingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "ref-cache REPOSITORY [true|false]",
+		Short:             "Enable or disable caching the ref advertisement",
+		Long:              "Enable or disable caching the ref advertisement of the repository served to HTTP clones and fetches, which spares running git when the refs haven't changed.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			switch len(args) {
+			case 1:
+				enabled, err := be.RefCacheEnabled(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(enabled)
+			case 2:
+				enabled, err := strconv.ParseBool(args[1])
+				if err != nil {
+					return err
+				}
+				if err := checkIfAdmin(cmd, args); err != nil {
+					return err
+				}
+				if err := be.SetRefCacheEnabled(ctx, rn, enabled); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func packSettingCommand() *cobra.Command {
+	var window, depth, threads int
+	var upload bool
+
+	cmd := &cobra.Command{
+		Use:               "pack REPOSITORY",
+		Short:             "Set or get the pack options of the repository",
+		Long:              "Set or get the pack.window, pack.depth, and pack.threads git uses to compress the repository when it's garbage collected or repacked after a push, 0 uses the default of git. With --upload, they apply to the packs sent to clones and fetches too.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			opts, err := be.PackOptions(ctx, rn)
+			if err != nil {
+				return err
+			}
+
+			flags := cmd.Flags()
+			if !flags.Changed("window") && !flags.Changed("depth") && !flags.Changed("threads") && !flags.Changed("upload") {
+				enabled, err := be.PackUploadEnabled(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				for _, opt := range []struct {
+					name string
+					val  int
+				}{
+					{"window", opts.Window},
+					{"depth", opts.Depth},
+					{"threads", opts.Threads},
+				} {
+					if opt.val > 0 {
+						cmd.Printf("%s: %d\n", opt.name, opt.val)
+					} else {
+						cmd.Printf("%s: default\n", opt.name)
+					}
+				}
+				cmd.Printf("upload: %t\n", enabled)
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			if flags.Changed("window") {
+				opts.Window = window
+			}
+			if flags.Changed("depth") {
+				opts.Depth = depth
+			}
+			if flags.Changed("threads") {
+				opts.Threads = threads
+			}
+			if err := be.SetPackOptions(ctx, rn, opts); err != nil {
+				return err
+			}
+
+			if flags.Changed("upload") {
+				return be.SetPackUploadEnabled(ctx, rn, upload)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&window, "window", 0, "set the number of objects compared when looking for deltas")
+	cmd.Flags().IntVar(&depth, "depth", 0, "set the maximum length of delta chains")
+	cmd.Flags().IntVar(&threads, "threads", 0, "set the number of threads looking for deltas")
+	cmd.Flags().BoolVar(&upload, "upload", false, "apply the options to clones and fetches too")
+
+	return cmd
+}
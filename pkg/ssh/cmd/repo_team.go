@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"sort"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func repoTeamCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "team",
+		Aliases: []string{"teams"},
+		Short:   "Manage team access",
+		Long:    "Manage the teams with access to a repo. Members of a team have the highest of their collaborator and team access levels.",
+	}
+
+	cmd.AddCommand(
+		repoTeamAddCommand(),
+		repoTeamRemoveCommand(),
+		repoTeamListCommand(),
+	)
+
+	return cmd
+}
+
+func repoTeamAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "add REPOSITORY TEAM [LEVEL]",
+		Short:             "Grant a team access to a repo",
+		Long:              "Grant a team access to a repo. LEVEL can be one of: no-access, read-only, read-write, or admin-access. Defaults to the access level of the team.",
+		Args:              cobra.RangeArgs(2, 3),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			level := access.AccessLevel(-1)
+			if len(args) > 2 {
+				level = access.ParseAccessLevel(args[2])
+				if level < 0 {
+					return access.ErrInvalidAccessLevel
+				}
+			}
+
+			return be.AddTeamRepository(ctx, args[0], args[1], level)
+		},
+	}
+
+	return cmd
+}
+
+func repoTeamRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY TEAM",
+		Short:             "Revoke the access of a team to a repo",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.RemoveTeamRepository(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func repoTeamListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Short:             "List the teams with access to a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadableAndCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			teams, err := be.RepositoryTeams(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			names := make([]string, 0, len(teams))
+			for name := range teams {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				cmd.Printf("%s\t%s\n", name, teams[name])
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -3,9 +3,13 @@ package cmd
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
+	"charm.land/log/v2"
 	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/spf13/cobra"
 )
 
@@ -69,5 +73,56 @@ func SettingsCommand() *cobra.Command {
 		},
 	)
 
+	cmd.AddCommand(logLevelCommand())
+
+	return cmd
+}
+
+func logLevelCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "log-level [SUBSYSTEM [LEVEL|default]]",
+		Short:             "Set or get the log levels of subsystems",
+		Long:              fmt.Sprintf("Set or get the log levels of subsystems until the configuration is reloaded. SUBSYSTEM is one of: %s. LEVEL is one of: debug, info, warn, error, or fatal, and default resets the subsystem to the default level.", strings.Join(config.LogSubsystems, ", ")),
+		Args:              cobra.RangeArgs(0, 2),
+		ValidArgs:         config.LogSubsystems,
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			def, levels, set := logr.Levels()
+			switch len(args) {
+			case 0:
+				cmd.Printf("default\t%s\n", def)
+				for _, name := range config.LogSubsystems {
+					if set[name] {
+						cmd.Printf("%s\t%s\n", name, levels[name])
+					} else {
+						cmd.Printf("%s\t%s (default)\n", name, levels[name])
+					}
+				}
+			case 1:
+				if err := config.ValidateLogSubsystem(args[0]); err != nil {
+					return err
+				}
+				if set[args[0]] {
+					cmd.Println(levels[args[0]])
+				} else {
+					cmd.Printf("%s (default)\n", levels[args[0]])
+				}
+			case 2:
+				if args[1] == "default" {
+					return logr.SetLevel(args[0], nil)
+				}
+
+				l, err := log.ParseLevel(args[1])
+				if err != nil {
+					return err
+				}
+
+				return logr.SetLevel(args[0], &l)
+			}
+
+			return nil
+		},
+	}
+
 	return cmd
 }
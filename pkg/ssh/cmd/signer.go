@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/spf13/cobra"
+)
+
+func signerCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "signer",
+		Aliases: []string{"signers"},
+		Short:   "Manage commit signers",
+		Long:    "Manage the SSH and OpenPGP keys allowed to sign commits pushed to a repo. Once a repo has signers, pushes containing commits that aren't signed by one of them are rejected.",
+	}
+
+	cmd.AddCommand(
+		signerAddCommand(),
+		signerRemoveCommand(),
+		signerListCommand(),
+	)
+
+	return cmd
+}
+
+func signerAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "add REPOSITORY PRINCIPAL AUTHORIZED_KEY",
+		Short:             "Allow a key to sign commits pushed to a repo",
+		Long:              "Allow a key to sign commits pushed to a repo. PRINCIPAL is usually the committer email. The key is an SSH public key, or an armored OpenPGP public key read from stdin when AUTHORIZED_KEY is -.",
+		Args:              cobra.MinimumNArgs(3),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+			principal := args[1]
+			key := strings.Join(args[2:], " ")
+			if key == "-" {
+				b, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("error reading key: %w", err)
+				}
+				key = string(b)
+			}
+			if git.IsGPGKey(key) {
+				return be.AddGPGSigner(ctx, repo, principal, key)
+			}
+
+			pk, _, err := sshutils.ParseAuthorizedKey(key)
+			if err != nil {
+				return err
+			}
+
+			return be.AddSigner(ctx, repo, principal, pk)
+		},
+	}
+
+	return cmd
+}
+
+func signerRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY PRINCIPAL",
+		Short:             "Remove the keys of a principal from the signers of a repo",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.RemoveSigner(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func signerListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Short:             "List the signers of a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadableAndCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			signers, err := be.Signers(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, s := range signers {
+				if git.IsGPGKey(s.PublicKey) {
+					fpr, err := git.GPGKeyFingerprint(ctx, s.PublicKey)
+					if err != nil {
+						return err
+					}
+					cmd.Println(s.Principal, "gpg", fpr)
+					continue
+				}
+				cmd.Println(s.Principal, s.PublicKey)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -6,6 +6,7 @@ import (
 	"charm.land/log/v2"
 	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/webhook"
 	"github.com/spf13/cobra"
@@ -20,6 +21,7 @@ func tagCommand() *cobra.Command {
 	cmd.AddCommand(
 		tagListCommand(),
 		tagDeleteCommand(),
+		tagProtectionCommand(),
 	)
 
 	return cmd
@@ -101,6 +103,12 @@ func tagDeleteCommand() *cobra.Command {
 				return err
 			}
 
+			if err := be.CheckTagProtections(ctx, rn, []hooks.HookArg{
+				{OldSha: tagCommit.ID.String(), NewSha: git.ZeroID, RefName: git.RefsTags + tag},
+			}); err != nil {
+				return err
+			}
+
 			if err := r.DeleteTag(tag); err != nil {
 				log.Errorf("failed to delete tag: %s", err)
 				return err
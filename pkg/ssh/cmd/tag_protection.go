@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func tagProtectionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "protection",
+		Aliases: []string{"protections", "protect"},
+		Short:   "Manage tag protection rules",
+		Long:    "Manage tag protection rules. Tags matching a rule can be created, but once they exist nobody can move or delete them, admins included.",
+	}
+
+	cmd.AddCommand(
+		tagProtectionAddCommand(),
+		tagProtectionRemoveCommand(),
+		tagProtectionListCommand(),
+	)
+
+	return cmd
+}
+
+func tagProtectionAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "add REPOSITORY PATTERN",
+		Short:             "Protect the tags matching a pattern",
+		Long:              "Protect the tags matching a glob pattern, such as v*.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.AddTagProtection(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func tagProtectionRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY PATTERN",
+		Short:             "Remove a tag protection rule",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.RemoveTagProtection(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func tagProtectionListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Short:             "List the tag protection rules of a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rules, err := be.TagProtections(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, r := range rules {
+				cmd.Println(r.Pattern)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+// TeamCommand returns a command for managing teams.
+func TeamCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "team",
+		Aliases: []string{"teams"},
+		Short:   "Manage teams",
+	}
+
+	cmd.AddCommand(
+		teamCreateCommand(),
+		teamDeleteCommand(),
+		teamListCommand(),
+		teamMemberCommand(),
+	)
+
+	return cmd
+}
+
+// checkIfServerAdmin checks that the user is a server admin. Team names
+// aren't repositories, so they aren't passed on to checkIfAdmin.
+func checkIfServerAdmin(cmd *cobra.Command, _ []string) error {
+	return checkIfAdmin(cmd, nil)
+}
+
+func teamCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "create TEAM [LEVEL]",
+		Short:             "Create a team",
+		Long:              "Create a team. LEVEL is the access level granted to repos by default, one of: no-access, read-only, read-write, or admin-access. Defaults to read-only.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfServerAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			level := access.ReadOnlyAccess
+			if len(args) > 1 {
+				level = access.ParseAccessLevel(args[1])
+				if level < 0 {
+					return access.ErrInvalidAccessLevel
+				}
+			}
+
+			return be.CreateTeam(ctx, args[0], level)
+		},
+	}
+
+	return cmd
+}
+
+func teamDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete TEAM",
+		Short:             "Delete a team",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfServerAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.DeleteTeam(ctx, args[0])
+		},
+	}
+
+	return cmd
+}
+
+func teamListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list",
+		Aliases:           []string{"ls"},
+		Short:             "List teams and their default access levels",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfServerAdmin,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			teams, err := be.Teams(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, t := range teams {
+				cmd.Printf("%s\t%s\n", t.Name, t.AccessLevel)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func teamMemberCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "member",
+		Aliases: []string{"members"},
+		Short:   "Manage team members",
+	}
+
+	addCmd := &cobra.Command{
+		Use:               "add TEAM USERNAME",
+		Short:             "Add a user to a team",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfServerAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.AddTeamMember(ctx, args[0], args[1])
+		},
+	}
+
+	removeCmd := &cobra.Command{
+		Use:               "remove TEAM USERNAME",
+		Short:             "Remove a user from a team",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfServerAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.RemoveTeamMember(ctx, args[0], args[1])
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:               "list TEAM",
+		Short:             "List the members of a team",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfServerAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			members, err := be.TeamMembers(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, m := range members {
+				cmd.Println(m)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.AddCommand(
+		addCmd,
+		removeCmd,
+		listCmd,
+	)
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func templateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "template REPOSITORY [true|false]",
+		Short:             "Set or get whether a repository is a template",
+		Long:              "Set or get whether a repository is a template. New repositories can be created from templates with the create --from-template flag.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := args[0]
+
+			switch len(args) {
+			case 1:
+				isTemplate, err := be.IsTemplate(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(isTemplate)
+			case 2:
+				isTemplate, err := strconv.ParseBool(args[1])
+				if err != nil {
+					return err
+				}
+				if err := checkIfCollab(cmd, args); err != nil {
+					return err
+				}
+				if err := be.SetTemplate(ctx, rn, isTemplate); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
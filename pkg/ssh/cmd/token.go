@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"charm.land/lipgloss/v2/table"
 	"github.com/caarlos0/duration"
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/dustin/go-humanize"
@@ -22,9 +24,11 @@ func TokenCommand() *cobra.Command {
 	}
 
 	var createExpiresIn string
+	var createScope string
 	createCmd := &cobra.Command{
 		Use:   "create NAME",
 		Short: "Create a new access token",
+		Long:  "Create a new access token. The scope limits the access the token grants to repositories over HTTP: read (read-only), write (read-write), or admin, the default.",
 		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
@@ -36,6 +40,11 @@ func TokenCommand() *cobra.Command {
 				return proto.ErrUserNotFound
 			}
 
+			scope, err := parseTokenScope(createScope)
+			if err != nil {
+				return err
+			}
+
 			var expiresAt time.Time
 			var expiresIn time.Duration
 			if createExpiresIn != "" {
@@ -48,7 +57,7 @@ func TokenCommand() *cobra.Command {
 				expiresAt = time.Now().Add(d)
 			}
 
-			token, err := be.CreateAccessToken(ctx, user, name, expiresAt)
+			token, err := be.CreateAccessToken(ctx, user, name, expiresAt, scope)
 			if err != nil {
 				return err
 			}
@@ -65,7 +74,10 @@ func TokenCommand() *cobra.Command {
 		},
 	}
 
+	createCmd.Flags().StringVar(&createExpiresIn, "expires", "", "Token expiration time (e.g. 1y, 3mo, 2w, 5d4h, 1h30m)")
 	createCmd.Flags().StringVar(&createExpiresIn, "expires-in", "", "Token expiration time (e.g. 1y, 3mo, 2w, 5d4h, 1h30m)")
+	createCmd.Flags().MarkHidden("expires-in") //nolint: errcheck
+	createCmd.Flags().StringVar(&createScope, "scope", "admin", "Token scope (read, write, or admin)")
 
 	listCmd := &cobra.Command{
 		Use:     "list",
@@ -92,7 +104,7 @@ func TokenCommand() *cobra.Command {
 			}
 
 			now := time.Now()
-			table := table.New().Headers("ID", "Name", "Created At", "Expires In")
+			table := table.New().Headers("ID", "Name", "Scope", "Created At", "Expires In")
 			for _, token := range tokens {
 				expiresAt := "-"
 				if !token.ExpiresAt.IsZero() {
@@ -105,6 +117,7 @@ func TokenCommand() *cobra.Command {
 
 				table = table.Row(strconv.FormatInt(token.ID, 10),
 					token.Name,
+					tokenScopeName(token.Scope),
 					humanize.Time(token.CreatedAt),
 					expiresAt,
 				)
@@ -150,3 +163,32 @@ func TokenCommand() *cobra.Command {
 
 	return cmd
 }
+
+// parseTokenScope parses the scope of an access token. Access level names
+// are accepted too.
+func parseTokenScope(s string) (access.AccessLevel, error) {
+	switch s {
+	case "read", "read-only":
+		return access.ReadOnlyAccess, nil
+	case "write", "read-write":
+		return access.ReadWriteAccess, nil
+	case "admin", "admin-access":
+		return access.AdminAccess, nil
+	default:
+		return -1, fmt.Errorf("invalid token scope %q, must be one of: read, write, admin", s)
+	}
+}
+
+// tokenScopeName returns the name of an access token scope.
+func tokenScopeName(scope access.AccessLevel) string {
+	switch scope {
+	case access.ReadOnlyAccess:
+		return "read"
+	case access.ReadWriteAccess:
+		return "write"
+	case access.AdminAccess:
+		return "admin"
+	default:
+		return scope.String()
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func topicCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "topic",
+		Aliases: []string{"topics"},
+		Short:   "Manage repository topics",
+		Long:    "Manage the topics a repo is categorized with. Topics are lowercase and can only contain letters, numbers, and hyphens.",
+	}
+
+	cmd.AddCommand(
+		topicAddCommand(),
+		topicRemoveCommand(),
+		topicListCommand(),
+	)
+
+	return cmd
+}
+
+func topicAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "add REPOSITORY TOPIC...",
+		Short:             "Add topics to a repo",
+		Args:              cobra.MinimumNArgs(2),
+		PersistentPreRunE: checkIfReadableAndCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			for _, topic := range args[1:] {
+				if err := be.AddRepoTopic(ctx, args[0], topic); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func topicRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY TOPIC...",
+		Aliases:           []string{"rm"},
+		Short:             "Remove topics from a repo",
+		Args:              cobra.MinimumNArgs(2),
+		PersistentPreRunE: checkIfReadableAndCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			for _, topic := range args[1:] {
+				if err := be.RemoveRepoTopic(ctx, args[0], topic); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func topicListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Aliases:           []string{"ls"},
+		Short:             "List the topics of a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			topics, err := be.RepoTopics(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, t := range topics {
+				cmd.Println(t)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
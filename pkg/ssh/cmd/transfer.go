@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/audit"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/spf13/cobra"
+)
+
+func transferCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "transfer REPOSITORY USERNAME",
+		Short:             "Transfer the ownership of a repository to another user",
+		Long:              "Transfer the ownership of a repository to another user. Only server admins and the current owner can transfer a repository. The collaborator grant of the new owner is removed since owners have admin access.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadableAndOwner,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if sess := sshutils.SessionFromContext(ctx); sess != nil {
+				ctx = audit.WithSource(ctx, audit.Source{Transport: "ssh", RemoteAddr: sess.RemoteAddr().String()})
+			}
+
+			return be.TransferRepository(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+// checkIfReadableAndOwner checks that the user is a server admin or the owner
+// of the repository, admin access through collaborator and team grants isn't
+// enough.
+func checkIfReadableAndOwner(cmd *cobra.Command, args []string) error {
+	if err := checkIfReadable(cmd, args); err != nil {
+		return err
+	}
+	if err := checkIfServerAdmin(cmd, args); err == nil {
+		return nil
+	}
+
+	ctx := cmd.Context()
+	be := backend.FromContext(ctx)
+	user := proto.UserFromContext(ctx)
+	if user == nil {
+		return proto.ErrUnauthorized
+	}
+
+	repo, err := be.Repository(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if repo.UserID() != user.ID() {
+		return proto.ErrUnauthorized
+	}
+
+	return nil
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh"
 )
@@ -161,6 +164,9 @@ func UserCommand() *cobra.Command {
 
 			cmd.Printf("Username: %s\n", user.Username())
 			cmd.Printf("Admin: %t\n", isAdmin)
+			if email := user.Email(); email != "" {
+				cmd.Printf("Email: %s\n", email)
+			}
 			cmd.Printf("Public keys:\n")
 			for _, pk := range user.PublicKeys() {
 				cmd.Printf("  %s\n", sshutils.MarshalAuthorizedKey(pk))
@@ -170,6 +176,116 @@ func UserCommand() *cobra.Command {
 		},
 	}
 
+	userSetEmailCommand := &cobra.Command{
+		Use:               "set-email USERNAME [EMAIL]",
+		Short:             "Set or unset a user's email address",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			var email string
+			if len(args) > 1 {
+				email = args[1]
+			}
+
+			return be.SetEmail(ctx, args[0], email)
+		},
+	}
+
+	userQuotaCommand := &cobra.Command{
+		Use:   "quota USERNAME [SIZE|default]",
+		Short: "Show or set a user's storage quota",
+		Long: `Show or set the maximum total size of the repositories a user owns.
+A size of 0 means no limit, and default resets it to the server default.`,
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			username := args[0]
+
+			if len(args) > 1 {
+				if args[1] == "default" {
+					return be.ResetUserQuota(ctx, username)
+				}
+
+				size, err := humanize.ParseBytes(args[1])
+				if err != nil {
+					return fmt.Errorf("invalid size: %s", args[1])
+				}
+
+				return be.SetUserQuota(ctx, username, int64(size)) //nolint:gosec
+			}
+
+			user, err := be.User(ctx, username)
+			if err != nil {
+				return err
+			}
+
+			quota, override, err := be.UserQuota(ctx, username)
+			if err != nil {
+				return err
+			}
+
+			used, err := be.UserStorage(ctx, user)
+			if err != nil {
+				return err
+			}
+
+			limit := "unlimited"
+			if quota > 0 {
+				limit = humanize.Bytes(uint64(quota)) //nolint:gosec
+			}
+			if !override {
+				limit += " (default)"
+			}
+
+			cmd.Printf("Quota: %s\n", limit)
+			cmd.Printf("Used: %s\n", humanize.Bytes(uint64(used))) //nolint:gosec
+			return nil
+		},
+	}
+
+	userAllowPushCreateCommand := &cobra.Command{
+		Use:               "allow-push-create USERNAME [true|false|default]",
+		Short:             "Set or get whether a user's pushes can create repositories",
+		Long:              "Set or get whether pushing to a repository that doesn't exist creates it for a user. default resets it to the server default.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			username := args[0]
+
+			if len(args) > 1 {
+				if args[1] == "default" {
+					return be.ResetUserAllowPushCreate(ctx, username)
+				}
+
+				allow, err := strconv.ParseBool(args[1])
+				if err != nil {
+					return fmt.Errorf("invalid value: %s, want true, false, or default", args[1])
+				}
+
+				return be.SetUserAllowPushCreate(ctx, username, allow)
+			}
+
+			allow, override, err := be.UserAllowPushCreate(ctx, username)
+			if err != nil {
+				return err
+			}
+
+			if override {
+				cmd.Println(allow)
+			} else {
+				cmd.Printf("%t (default)\n", allow)
+			}
+
+			return nil
+		},
+	}
+
 	userSetUsernameCommand := &cobra.Command{
 		Use:               "set-username USERNAME NEW_USERNAME",
 		Short:             "Change a user's username",
@@ -188,11 +304,14 @@ func UserCommand() *cobra.Command {
 	cmd.AddCommand(
 		userCreateCommand,
 		userAddPubkeyCommand,
+		userAllowPushCreateCommand,
 		userInfoCommand,
 		userListCommand,
 		userDeleteCommand,
+		userQuotaCommand,
 		userRemovePubkeyCommand,
 		userSetAdminCommand,
+		userSetEmailCommand,
 		userSetUsernameCommand,
 	)
 
@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
 	"charm.land/lipgloss/v2/table"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/utils"
 	"github.com/charmbracelet/soft-serve/pkg/webhook"
 	"github.com/dustin/go-humanize"
@@ -15,43 +17,135 @@ import (
 )
 
 func webhookCommand() *cobra.Command {
+	return newWebhookCommand(webhookScope{})
+}
+
+// WebhookCommand returns the command managing the server webhooks.
+func WebhookCommand() *cobra.Command {
+	cmd := newWebhookCommand(webhookScope{server: true})
+	cmd.Long = "Manage server webhooks, they receive the repository events of every repository."
+	return cmd
+}
+
+func newWebhookCommand(s webhookScope) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "webhook",
 		Aliases: []string{"webhooks"},
-		Short:   "Manage repository webhooks",
+		Short:   s.short("Manage %s webhooks"),
 	}
 
 	cmd.AddCommand(
-		webhookListCommand(),
-		webhookCreateCommand(),
-		webhookDeleteCommand(),
-		webhookUpdateCommand(),
-		webhookDeliveriesCommand(),
+		webhookListCommand(s),
+		webhookCreateCommand(s),
+		webhookDeleteCommand(s),
+		webhookUpdateCommand(s),
+		webhookDeliveriesCommand(s),
 	)
 
 	return cmd
 }
 
-var webhookEvents []string
+// webhookScope is what the webhook commands manage, the webhooks of a
+// repository, or the server webhooks. The commands of repository webhooks
+// take the repository as their first argument.
+type webhookScope struct {
+	server bool
+}
+
+// use returns the usage of a command with args.
+func (s webhookScope) use(name string, args ...string) string {
+	if !s.server {
+		args = append([]string{"REPOSITORY"}, args...)
+	}
+
+	return strings.Join(append([]string{name}, args...), " ")
+}
 
-func init() {
+// short formats the short description of a command with the kind of
+// webhooks.
+func (s webhookScope) short(format string) string {
+	if s.server {
+		return fmt.Sprintf(format, "server")
+	}
+
+	return fmt.Sprintf(format, "repository")
+}
+
+// args returns the positional arguments of a command with n arguments.
+func (s webhookScope) args(n int) cobra.PositionalArgs {
+	if s.server {
+		return cobra.ExactArgs(n)
+	}
+
+	return cobra.ExactArgs(n + 1)
+}
+
+// check returns the access check of the commands.
+func (s webhookScope) check() func(*cobra.Command, []string) error {
+	if s.server {
+		return checkIfServerAdmin
+	}
+
+	return checkIfAdmin
+}
+
+// repo returns the repository of the webhooks, nil for the server webhooks,
+// and the remaining arguments.
+func (s webhookScope) repo(cmd *cobra.Command, args []string) (proto.Repository, []string, error) {
+	if s.server {
+		return nil, args, nil
+	}
+
+	ctx := cmd.Context()
+	be := backend.FromContext(ctx)
+	repo, err := be.Repository(ctx, args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return repo, args[1:], nil
+}
+
+// events returns the names of the events the webhooks can subscribe to.
+func (s webhookScope) events() []string {
 	events := webhook.Events()
-	webhookEvents = make([]string, len(events))
+	if s.server {
+		events = webhook.ServerEvents()
+	}
+
+	names := make([]string, len(events))
 	for i, e := range events {
-		webhookEvents[i] = e.String()
+		names[i] = e.String()
 	}
+
+	return names
 }
 
-func webhookListCommand() *cobra.Command {
+// parseEvents parses the names of webhook events.
+func (s webhookScope) parseEvents(names []string) ([]webhook.Event, error) {
+	var evs []webhook.Event
+	for _, e := range names {
+		ev, err := webhook.ParseEvent(e)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q, valid events are: %s", err, e, strings.Join(s.events(), ", "))
+		}
+
+		evs = append(evs, ev)
+	}
+
+	return evs, nil
+}
+
+func webhookListCommand(s webhookScope) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:               "list REPOSITORY",
-		Short:             "List repository webhooks",
-		Args:              cobra.ExactArgs(1),
-		PersistentPreRunE: checkIfAdmin,
+		Use:               s.use("list"),
+		Short:             s.short("List %s webhooks"),
+		Args:              s.args(0),
+		PersistentPreRunE: s.check(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			repo, err := be.Repository(ctx, args[0])
+			repo, _, err := s.repo(cmd, args)
 			if err != nil {
 				return err
 			}
@@ -85,32 +179,29 @@ func webhookListCommand() *cobra.Command {
 	return cmd
 }
 
-func webhookCreateCommand() *cobra.Command {
+func webhookCreateCommand(s webhookScope) *cobra.Command {
 	var events []string
 	var secret string
 	var active bool
 	var contentType string
+	var tmpl string
+	var tmplContentType string
 	cmd := &cobra.Command{
-		Use:               "create REPOSITORY URL",
-		Short:             "Create a repository webhook",
-		Args:              cobra.ExactArgs(2),
-		PersistentPreRunE: checkIfAdmin,
+		Use:               s.use("create", "URL"),
+		Short:             s.short("Create a %s webhook"),
+		Args:              s.args(1),
+		PersistentPreRunE: s.check(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			repo, err := be.Repository(ctx, args[0])
+			repo, args, err := s.repo(cmd, args)
 			if err != nil {
 				return err
 			}
 
-			var evs []webhook.Event
-			for _, e := range events {
-				ev, err := webhook.ParseEvent(e)
-				if err != nil {
-					return fmt.Errorf("invalid event: %w", err)
-				}
-
-				evs = append(evs, ev)
+			evs, err := s.parseEvents(events)
+			if err != nil {
+				return err
 			}
 
 			var ct webhook.ContentType
@@ -123,34 +214,56 @@ func webhookCreateCommand() *cobra.Command {
 				return webhook.ErrInvalidContentType
 			}
 
-			url := utils.Sanitize(args[1])
-			return be.CreateWebhook(ctx, repo, strings.TrimSpace(url), ct, secret, evs, active)
+			tmpl, err := readPayloadTemplate(cmd, tmpl)
+			if err != nil {
+				return err
+			}
+
+			url := utils.Sanitize(args[0])
+			return be.CreateWebhook(ctx, repo, strings.TrimSpace(url), ct, tmpl, strings.TrimSpace(tmplContentType), secret, evs, active)
 		},
 	}
 
-	cmd.Flags().StringSliceVarP(&events, "events", "e", nil, fmt.Sprintf("events to trigger the webhook, available events are (%s)", strings.Join(webhookEvents, ", ")))
+	cmd.Flags().StringSliceVarP(&events, "events", "e", nil, fmt.Sprintf("events to trigger the webhook, available events are (%s)", strings.Join(s.events(), ", ")))
 	cmd.Flags().StringVarP(&secret, "secret", "s", "", "secret to sign the webhook payload")
 	cmd.Flags().BoolVarP(&active, "active", "a", true, "whether the webhook is active")
 	cmd.Flags().StringVarP(&contentType, "content-type", "c", "json", "content type of the webhook payload, can be either `json` or `form`")
+	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Go template rendering the webhook payload, use - to read it from stdin")
+	cmd.Flags().StringVar(&tmplContentType, "template-content-type", webhook.DefaultTemplateContentType, "content type of the payload rendered by the template")
 
 	return cmd
 }
 
-func webhookDeleteCommand() *cobra.Command {
+// readPayloadTemplate returns a payload template flag value, reading it from
+// the command input when it's "-".
+func readPayloadTemplate(cmd *cobra.Command, tmpl string) (string, error) {
+	if tmpl != "-" {
+		return tmpl, nil
+	}
+
+	b, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("error reading template: %w", err)
+	}
+
+	return string(b), nil
+}
+
+func webhookDeleteCommand(s webhookScope) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:               "delete REPOSITORY WEBHOOK_ID",
-		Short:             "Delete a repository webhook",
-		Args:              cobra.ExactArgs(2),
-		PersistentPreRunE: checkIfAdmin,
+		Use:               s.use("delete", "WEBHOOK_ID"),
+		Short:             s.short("Delete a %s webhook"),
+		Args:              s.args(1),
+		PersistentPreRunE: s.check(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			repo, err := be.Repository(ctx, args[0])
+			repo, args, err := s.repo(cmd, args)
 			if err != nil {
 				return err
 			}
 
-			id, err := strconv.ParseInt(args[1], 10, 64)
+			id, err := strconv.ParseInt(args[0], 10, 64)
 			if err != nil {
 				return fmt.Errorf("invalid webhook ID: %w", err)
 			}
@@ -162,26 +275,28 @@ func webhookDeleteCommand() *cobra.Command {
 	return cmd
 }
 
-func webhookUpdateCommand() *cobra.Command {
+func webhookUpdateCommand(s webhookScope) *cobra.Command {
 	var events []string
 	var secret string
 	var active string
 	var contentType string
+	var tmpl string
+	var tmplContentType string
 	var url string
 	cmd := &cobra.Command{
-		Use:               "update REPOSITORY WEBHOOK_ID",
-		Short:             "Update a repository webhook",
-		Args:              cobra.ExactArgs(2),
-		PersistentPreRunE: checkIfAdmin,
+		Use:               s.use("update", "WEBHOOK_ID"),
+		Short:             s.short("Update a %s webhook"),
+		Args:              s.args(1),
+		PersistentPreRunE: s.check(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			repo, err := be.Repository(ctx, args[0])
+			repo, args, err := s.repo(cmd, args)
 			if err != nil {
 				return err
 			}
 
-			id, err := strconv.ParseInt(args[1], 10, 64)
+			id, err := strconv.ParseInt(args[0], 10, 64)
 			if err != nil {
 				return fmt.Errorf("invalid webhook ID: %w", err)
 			}
@@ -225,35 +340,43 @@ func webhookUpdateCommand() *cobra.Command {
 				newContentType = ct
 			}
 
+			newTmpl := wh.PayloadTemplate
+			if cmd.Flags().Changed("template") {
+				newTmpl, err = readPayloadTemplate(cmd, tmpl)
+				if err != nil {
+					return err
+				}
+			}
+
+			newTmplContentType := wh.PayloadContentType
+			if tmplContentType != "" {
+				newTmplContentType = strings.TrimSpace(tmplContentType)
+			}
+
 			newEvents := wh.Events
 			if len(events) > 0 {
-				var evs []webhook.Event
-				for _, e := range events {
-					ev, err := webhook.ParseEvent(e)
-					if err != nil {
-						return fmt.Errorf("invalid event: %w", err)
-					}
-
-					evs = append(evs, ev)
+				newEvents, err = s.parseEvents(events)
+				if err != nil {
+					return err
 				}
-
-				newEvents = evs
 			}
 
-			return be.UpdateWebhook(ctx, repo, id, newURL, newContentType, newSecret, newEvents, newActive)
+			return be.UpdateWebhook(ctx, repo, id, newURL, newContentType, newTmpl, newTmplContentType, newSecret, newEvents, newActive)
 		},
 	}
 
-	cmd.Flags().StringSliceVarP(&events, "events", "e", nil, fmt.Sprintf("events to trigger the webhook, available events are (%s)", strings.Join(webhookEvents, ", ")))
+	cmd.Flags().StringSliceVarP(&events, "events", "e", nil, fmt.Sprintf("events to trigger the webhook, available events are (%s)", strings.Join(s.events(), ", ")))
 	cmd.Flags().StringVarP(&secret, "secret", "s", "", "secret to sign the webhook payload")
 	cmd.Flags().StringVarP(&active, "active", "a", "", "whether the webhook is active")
 	cmd.Flags().StringVarP(&contentType, "content-type", "c", "", "content type of the webhook payload, can be either `json` or `form`")
+	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Go template rendering the webhook payload, use - to read it from stdin and an empty value to remove it")
+	cmd.Flags().StringVar(&tmplContentType, "template-content-type", "", "content type of the payload rendered by the template")
 	cmd.Flags().StringVarP(&url, "url", "u", "", "webhook URL")
 
 	return cmd
 }
 
-func webhookDeliveriesCommand() *cobra.Command {
+func webhookDeliveriesCommand(s webhookScope) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "deliveries",
 		Short:   "Manage webhook deliveries",
@@ -261,43 +384,57 @@ func webhookDeliveriesCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(
-		webhookDeliveriesListCommand(),
-		webhookDeliveriesRedeliverCommand(),
-		webhookDeliveriesGetCommand(),
+		webhookDeliveriesListCommand(s),
+		webhookDeliveriesRedeliverCommand(s),
+		webhookDeliveriesGetCommand(s),
 	)
 
 	return cmd
 }
 
-func webhookDeliveriesListCommand() *cobra.Command {
+func webhookDeliveriesListCommand(s webhookScope) *cobra.Command {
+	var failed bool
 	cmd := &cobra.Command{
-		Use:               "list REPOSITORY WEBHOOK_ID",
+		Use:               s.use("list", "WEBHOOK_ID"),
 		Short:             "List webhook deliveries",
-		Args:              cobra.ExactArgs(2),
-		PersistentPreRunE: checkIfAdmin,
+		Args:              s.args(1),
+		PersistentPreRunE: s.check(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			id, err := strconv.ParseInt(args[1], 10, 64)
+			repo, args, err := s.repo(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
 			if err != nil {
 				return fmt.Errorf("invalid webhook ID: %w", err)
 			}
 
-			dels, err := be.ListWebhookDeliveries(ctx, id)
+			dels, err := be.ListWebhookDeliveries(ctx, repo, id)
 			if err != nil {
 				return err
 			}
 
-			table := table.New().Headers("Status", "ID", "Event", "Created At")
+			table := table.New().Headers("Status", "ID", "Event", "Attempts", "Created At")
 			for _, d := range dels {
-				status := "❌"
-				if d.ResponseStatus >= 200 && d.ResponseStatus < 300 {
-					status = "✅"
+				if failed && !d.Failed() {
+					continue
+				}
+
+				status := "✅"
+				switch {
+				case d.Pending():
+					status = "⏳"
+				case d.Failed():
+					status = "❌"
 				}
 				table = table.Row(
 					status,
 					d.ID.String(),
 					d.Event.String(),
+					strconv.Itoa(d.Attempts),
 					humanize.Time(d.CreatedAt),
 				)
 			}
@@ -306,29 +443,32 @@ func webhookDeliveriesListCommand() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&failed, "failed", false, "only list deliveries that ran out of attempts")
+
 	return cmd
 }
 
-func webhookDeliveriesRedeliverCommand() *cobra.Command {
+func webhookDeliveriesRedeliverCommand(s webhookScope) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:               "redeliver REPOSITORY WEBHOOK_ID DELIVERY_ID",
+		Use:               s.use("redeliver", "WEBHOOK_ID", "DELIVERY_ID"),
 		Short:             "Redeliver a webhook delivery",
-		Args:              cobra.ExactArgs(3),
-		PersistentPreRunE: checkIfAdmin,
+		Long:              "Queue a webhook delivery to be sent again, with a fresh set of attempts.",
+		Args:              s.args(2),
+		PersistentPreRunE: s.check(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			repo, err := be.Repository(ctx, args[0])
+			repo, args, err := s.repo(cmd, args)
 			if err != nil {
 				return err
 			}
 
-			id, err := strconv.ParseInt(args[1], 10, 64)
+			id, err := strconv.ParseInt(args[0], 10, 64)
 			if err != nil {
 				return fmt.Errorf("invalid webhook ID: %w", err)
 			}
 
-			delID, err := uuid.Parse(args[2])
+			delID, err := uuid.Parse(args[1])
 			if err != nil {
 				return fmt.Errorf("invalid delivery ID: %w", err)
 			}
@@ -340,26 +480,31 @@ func webhookDeliveriesRedeliverCommand() *cobra.Command {
 	return cmd
 }
 
-func webhookDeliveriesGetCommand() *cobra.Command {
+func webhookDeliveriesGetCommand(s webhookScope) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:               "get REPOSITORY WEBHOOK_ID DELIVERY_ID",
+		Use:               s.use("get", "WEBHOOK_ID", "DELIVERY_ID"),
 		Short:             "Get a webhook delivery",
-		Args:              cobra.ExactArgs(3),
-		PersistentPreRunE: checkIfAdmin,
+		Args:              s.args(2),
+		PersistentPreRunE: s.check(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
-			id, err := strconv.ParseInt(args[1], 10, 64)
+			repo, args, err := s.repo(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
 			if err != nil {
 				return fmt.Errorf("invalid webhook ID: %w", err)
 			}
 
-			delID, err := uuid.Parse(args[2])
+			delID, err := uuid.Parse(args[1])
 			if err != nil {
 				return fmt.Errorf("invalid delivery ID: %w", err)
 			}
 
-			del, err := be.WebhookDelivery(ctx, id, delID)
+			del, err := be.WebhookDelivery(ctx, repo, id, delID)
 			if err != nil {
 				return err
 			}
@@ -395,6 +540,15 @@ func webhookDeliveriesGetCommand() *cobra.Command {
 				fmt.Fprintf(out, "  %s\n", b) //nolint:errcheck
 			}
 
+			fmt.Fprintf(out, "Attempts: %d\n", del.Attempts) //nolint:errcheck
+			if del.Pending() {
+				fmt.Fprintf(out, "Next Attempt: %s\n", humanize.Time(del.NextAttemptAt.Time)) //nolint:errcheck
+			}
+			fmt.Fprintf(out, "History:\n") //nolint:errcheck
+			for _, a := range del.History {
+				fmt.Fprintf(out, "  %d: %d %s %s\n", a.Attempt, a.ResponseStatus, humanize.Time(a.CreatedAt), a.RequestError.String) //nolint:errcheck
+			}
+
 			return nil
 		},
 	}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/spf13/cobra"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// WhoamiCommand returns a command that shows the identity the server
+// authenticated the connection as.
+func WhoamiCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "whoami [REPOSITORY]",
+		Short: "Show who the server sees you as",
+		Long:  "Show the user the connection is authenticated as, whether it's an admin, and the fingerprint of the key used. With a repository, also show the access level the server grants you to it.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			pk := sshutils.PublicKeyFromContext(ctx)
+
+			username := "anonymous"
+			admin := pk != nil && IsPublicKeyAdmin(cfg, pk)
+			if user != nil {
+				username = user.Username()
+				admin = admin || user.IsAdmin()
+			}
+
+			fingerprint := "none"
+			if pk != nil {
+				fingerprint = gossh.FingerprintSHA256(pk)
+			}
+
+			cmd.Printf("Username: %s\n", username)
+			cmd.Printf("Admin: %t\n", admin)
+			cmd.Printf("Key fingerprint: %s\n", fingerprint)
+			if len(args) > 0 {
+				rn := utils.SanitizeRepo(args[0])
+				cmd.Printf("Access to %s: %s\n", rn, be.AccessLevelForUser(ctx, rn, user))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,109 @@
+package ssh
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"charm.land/wish/v2"
+	"github.com/charmbracelet/ssh"
+)
+
+// IdleTimeoutMiddleware closes sessions after timeout without activity, with
+// a message telling the user why. Input from the client is activity. For
+// sessions without a PTY, such as git commands, output is activity too so
+// transfers in progress are never idle. A timeout of 0 disables it.
+func IdleTimeoutMiddleware(timeout time.Duration) wish.Middleware {
+	return func(sh ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			if timeout <= 0 {
+				sh(s)
+				return
+			}
+
+			_, _, isPty := s.Pty()
+			is := &idleSession{Session: s, pty: isPty}
+			is.touch()
+
+			done := make(chan struct{})
+			defer close(done)
+			go is.watch(timeout, done)
+
+			sh(is)
+		}
+	}
+}
+
+// idleSession is a session that records its last activity.
+type idleSession struct {
+	ssh.Session
+	pty  bool
+	last atomic.Int64
+}
+
+func (s *idleSession) touch() {
+	s.last.Store(time.Now().UnixNano())
+}
+
+// watch closes the session once it's been idle for timeout, until done is
+// closed.
+func (s *idleSession) watch(timeout time.Duration, done <-chan struct{}) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+
+		idle := time.Since(time.Unix(0, s.last.Load()))
+		if idle < timeout {
+			timer.Reset(timeout - idle)
+			continue
+		}
+
+		fmt.Fprintf(s.Session.Stderr(), "\r\nClosing the session after %s of inactivity.\r\n", timeout) //nolint: errcheck
+		s.Session.Close()                                                                               //nolint: errcheck
+		return
+	}
+}
+
+// Read implements io.Reader.
+func (s *idleSession) Read(p []byte) (int, error) {
+	n, err := s.Session.Read(p)
+	if n > 0 {
+		s.touch()
+	}
+	return n, err
+}
+
+// Write implements io.Writer.
+func (s *idleSession) Write(p []byte) (int, error) {
+	if !s.pty {
+		s.touch()
+	}
+	return s.Session.Write(p)
+}
+
+// Stderr implements ssh.Session.
+func (s *idleSession) Stderr() io.ReadWriter {
+	if s.pty {
+		return s.Session.Stderr()
+	}
+	return &idleWriter{ReadWriter: s.Session.Stderr(), s: s}
+}
+
+// idleWriter records writes as activity of its session.
+type idleWriter struct {
+	io.ReadWriter
+	s *idleSession
+}
+
+// Write implements io.Writer.
+func (w *idleWriter) Write(p []byte) (int, error) {
+	w.s.touch()
+	return w.ReadWriter.Write(p)
+}
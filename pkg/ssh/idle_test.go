@@ -0,0 +1,51 @@
+package ssh
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"charm.land/wish/v2/testsession"
+	"github.com/charmbracelet/ssh"
+)
+
+func TestIdleTimeoutMiddleware(t *testing.T) {
+	timeout := 200 * time.Millisecond
+	handler := func(s ssh.Session) {
+		switch s.RawCommand() {
+		case "idle":
+			<-s.Context().Done()
+		case "busy":
+			// Output keeps sessions without a PTY active.
+			for i := 0; i < 6; i++ {
+				time.Sleep(timeout / 2)
+				s.Write([]byte(".")) //nolint: errcheck
+			}
+		}
+	}
+
+	run := func(cmd string) (string, string, time.Duration) {
+		sess := testsession.New(t, &ssh.Server{
+			Handler: IdleTimeoutMiddleware(timeout)(handler),
+		}, nil)
+		var stdout, stderr bytes.Buffer
+		sess.Stdout, sess.Stderr = &stdout, &stderr
+		start := time.Now()
+		sess.Run(cmd) //nolint: errcheck
+		return stdout.String(), stderr.String(), time.Since(start)
+	}
+
+	_, stderr, elapsed := run("idle")
+	if !strings.Contains(stderr, "Closing the session after 200ms of inactivity.") {
+		t.Errorf("idle session stderr = %q, want an idle message", stderr)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("idle session took %s to close", elapsed)
+	}
+
+	stdout, stderr, _ := run("busy")
+	if stdout != "......" || stderr != "" {
+		t.Errorf("busy session = %q, %q, want all output and no idle message", stdout, stderr)
+	}
+}
@@ -0,0 +1,135 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// ErrInvalidCode is returned when a keyboard interactive code is invalid.
+var ErrInvalidCode = errors.New("invalid code")
+
+// errNoCode is returned when the client didn't enter a code.
+var errNoCode = errors.New("no code")
+
+// interactiveUserExtension is the permissions extension holding the name of
+// the user authenticated with keyboard interactive auth.
+const interactiveUserExtension = "interactive-user"
+
+// InteractiveAuthenticator verifies the codes clients enter during keyboard
+// interactive authentication, such as one-time passwords.
+type InteractiveAuthenticator interface {
+	// Authenticate verifies code for the SSH user and returns the name of the
+	// user to log in as. It returns ErrInvalidCode if the code is invalid.
+	Authenticate(ctx context.Context, user, code string) (string, error)
+}
+
+// CommandAuthenticator is an InteractiveAuthenticator running a command with
+// the SSH user as its argument and the code on stdin. The code is valid if the
+// command succeeds, and the first line it prints, if any, is the name of the
+// user to log in as.
+type CommandAuthenticator struct {
+	// Command is the path of the command.
+	Command string
+
+	// Timeout is the time the command can take, defaults to ten seconds.
+	Timeout time.Duration
+}
+
+var _ InteractiveAuthenticator = (*CommandAuthenticator)(nil)
+
+// Authenticate implements InteractiveAuthenticator.
+func (a *CommandAuthenticator) Authenticate(ctx context.Context, user, code string) (string, error) {
+	timeout := a.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, a.Command, user) //nolint: gosec
+	cmd.Stdin = strings.NewReader(code + "\n")
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && ctx.Err() == nil {
+			return "", ErrInvalidCode
+		}
+		return "", fmt.Errorf("keyboard interactive command: %w", err)
+	}
+
+	scanner := bufio.NewScanner(&stdout)
+	if scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			return name, nil
+		}
+	}
+
+	return user, nil
+}
+
+// SetInteractiveAuthenticator replaces the authenticator used for keyboard
+// interactive authentication when it's enabled.
+func (s *SSHServer) SetInteractiveAuthenticator(a InteractiveAuthenticator) {
+	s.interactiveAuth = a
+}
+
+// interactiveLogin prompts the client for a code and returns the user it
+// belongs to, creating it if allowed. It returns errNoCode if the client
+// didn't enter one.
+func (s *SSHServer) interactiveLogin(ctx ssh.Context, challenge gossh.KeyboardInteractiveChallenge) (proto.User, error) {
+	kic := s.config().SSH.KeyboardInteractive
+	answers, err := challenge("", "", []string{kic.Prompt}, []bool{false})
+	if err != nil {
+		return nil, err
+	}
+	if len(answers) != 1 || strings.TrimSpace(answers[0]) == "" {
+		return nil, errNoCode
+	}
+
+	username, err := s.interactiveAuth.Authenticate(ctx, ctx.User(), strings.TrimSpace(answers[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.be.User(ctx, username)
+	if errors.Is(err, proto.ErrUserNotFound) && kic.CreateUsers {
+		user, err = s.be.CreateUser(ctx, username, proto.UserOptions{})
+		if err == nil {
+			s.logger.Info("created user on keyboard interactive login", "username", user.Username())
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// interactiveUser returns the user authenticated with keyboard interactive
+// auth for the session, if any.
+func interactiveUser(ctx context.Context, be *backend.Backend, perms *gossh.Permissions) proto.User {
+	if perms == nil {
+		return nil
+	}
+
+	name := perms.Extensions[interactiveUserExtension]
+	if name == "" {
+		return nil
+	}
+
+	user, _ := be.User(ctx, name)
+	return user
+}
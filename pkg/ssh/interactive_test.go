@@ -0,0 +1,166 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"charm.land/log/v2"
+	"charm.land/wish/v2/testsession"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/migrate"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/store/database"
+	gossh "golang.org/x/crypto/ssh"
+	_ "modernc.org/sqlite" // sqlite driver
+)
+
+// codeAuthenticator accepts a fixed code for each user.
+type codeAuthenticator map[string]string
+
+func (a codeAuthenticator) Authenticate(_ context.Context, user, code string) (string, error) {
+	if c, ok := a[user]; ok && c == code {
+		return user, nil
+	}
+	return "", ErrInvalidCode
+}
+
+func newInteractiveServer(t *testing.T, createUsers bool) (*SSHServer, *backend.Backend) {
+	t.Helper()
+	t.Setenv("SOFT_SERVE_DATA_PATH", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.SSH.KeyboardInteractive.Enabled = true
+	cfg.SSH.KeyboardInteractive.CreateUsers = createUsers
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := config.WithContext(context.TODO(), cfg)
+	ctx = log.WithContext(ctx, log.New(os.Stderr))
+	dbx, err := db.Open(ctx, cfg.DB.Driver, cfg.DB.DataSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbx.Close() }) //nolint: errcheck
+	if err := migrate.Migrate(ctx, dbx); err != nil {
+		t.Fatal(err)
+	}
+	dbstore := database.New(ctx, dbx)
+	ctx = db.WithContext(ctx, dbx)
+	ctx = store.WithContext(ctx, dbstore)
+	be := backend.New(ctx, cfg, dbx, dbstore)
+	ctx = backend.WithContext(ctx, be)
+
+	s, err := NewSSHServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetInteractiveAuthenticator(codeAuthenticator{"alice": "123456", "bob": "654321"})
+	return s, be
+}
+
+func interactiveSession(t *testing.T, s *SSHServer, user, code string) (*gossh.Session, error) {
+	t.Helper()
+	return testsession.NewClientSession(t, testsession.Listen(t, s.srv), &gossh.ClientConfig{
+		User: user,
+		Auth: []gossh.AuthMethod{
+			gossh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = code
+				}
+				return answers, nil
+			}),
+		},
+	})
+}
+
+func TestKeyboardInteractiveAuth(t *testing.T) {
+	s, be := newInteractiveServer(t, false)
+	if _, err := be.CreateUser(context.TODO(), "alice", proto.UserOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	sess, err := interactiveSession(t, s, "alice", "123456")
+	if err != nil {
+		t.Fatalf("valid code: %v", err)
+	}
+	out, err := sess.Output("info")
+	if err != nil || !strings.Contains(string(out), "Username: alice") {
+		t.Errorf("info = %q, %v, want alice", out, err)
+	}
+
+	if _, err := interactiveSession(t, s, "alice", "000000"); err == nil {
+		t.Error("invalid code was accepted")
+	}
+
+	// bob has a valid code but no user.
+	if _, err := interactiveSession(t, s, "bob", "654321"); err == nil {
+		t.Error("unknown user was accepted")
+	}
+
+	// Without a code, keyless access applies.
+	sess, err = interactiveSession(t, s, "alice", "")
+	if err != nil {
+		t.Fatalf("no code: %v", err)
+	}
+	if out, err := sess.Output("info"); err == nil {
+		t.Errorf("info without a code = %q, want an error", out)
+	}
+}
+
+func TestKeyboardInteractiveAuthCreateUsers(t *testing.T) {
+	s, be := newInteractiveServer(t, true)
+
+	if _, err := interactiveSession(t, s, "bob", "654321"); err != nil {
+		t.Fatalf("valid code: %v", err)
+	}
+	if _, err := be.User(context.TODO(), "bob"); err != nil {
+		t.Errorf("user wasn't created: %v", err)
+	}
+}
+
+func TestCommandAuthenticator(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	script := filepath.Join(t.TempDir(), "verify")
+	if err := os.WriteFile(script, []byte(`#!/bin/sh
+read -r code
+[ "$code" = "123456" ] || exit 1
+[ "$1" = "git" ] && echo alice
+exit 0
+`), 0o755); err != nil { //nolint: gosec
+		t.Fatal(err)
+	}
+
+	a := &CommandAuthenticator{Command: script}
+	cases := []struct {
+		user, code string
+		want       string
+		wantErr    error
+	}{
+		{"bob", "123456", "bob", nil},
+		{"git", "123456", "alice", nil},
+		{"bob", "000000", "", ErrInvalidCode},
+	}
+	for _, c := range cases {
+		got, err := a.Authenticate(context.TODO(), c.user, c.code)
+		if got != c.want || !errors.Is(err, c.wantErr) {
+			t.Errorf("Authenticate(%q, %q) = %q, %v, want %q, %v", c.user, c.code, got, err, c.want, c.wantErr)
+		}
+	}
+
+	a.Command = filepath.Join(t.TempDir(), "missing")
+	if _, err := a.Authenticate(context.TODO(), "bob", "123456"); err == nil || errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Authenticate() with a missing command = %v, want an execution error", err)
+	}
+}
@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/ssh/cmd"
 	"github.com/charmbracelet/soft-serve/pkg/sshutils"
@@ -55,15 +57,21 @@ func AuthenticationMiddleware(sh ssh.Handler) ssh.Handler {
 			return
 		}
 
+		// Users authenticated with keyboard interactive auth don't need
+		// keyless access.
+		var user proto.User
+		if pk == nil {
+			user = interactiveUser(ctx, be, perms)
+		}
+
 		ac := be.AllowKeyless(ctx)
 		publicKeyCounter.WithLabelValues(strconv.FormatBool(ac || pk != nil)).Inc()
-		if !ac && pk == nil {
+		if !ac && pk == nil && user == nil {
 			wish.Fatalln(s, ErrPermissionDenied)
 			return
 		}
 
 		// Set the auth'd user, or anon, in the context
-		var user proto.User
 		if pk != nil {
 			user, _ = be.UserByPublicKey(ctx, pk)
 		}
@@ -75,11 +83,17 @@ func AuthenticationMiddleware(sh ssh.Handler) ssh.Handler {
 
 // ContextMiddleware adds the config, backend, and logger to the session context.
 func ContextMiddleware(cfg *config.Config, dbx *db.DB, datastore store.Store, be *backend.Backend, logger *log.Logger) func(ssh.Handler) ssh.Handler {
+	return contextMiddleware(func() *config.Config { return cfg }, dbx, datastore, be, logger)
+}
+
+// contextMiddleware is ContextMiddleware with the configuration returned by
+// cfg when sessions start, which changes when it's reloaded.
+func contextMiddleware(cfg func() *config.Config, dbx *db.DB, datastore store.Store, be *backend.Backend, logger *log.Logger) func(ssh.Handler) ssh.Handler {
 	return func(sh ssh.Handler) ssh.Handler {
 		return func(s ssh.Session) {
 			ctx := s.Context()
 			ctx.SetValue(sshutils.ContextKeySession, s)
-			ctx.SetValue(config.ContextKey, cfg)
+			ctx.SetValue(config.ContextKey, cfg())
 			ctx.SetValue(db.ContextKey, dbx)
 			ctx.SetValue(store.ContextKey, datastore)
 			ctx.SetValue(backend.ContextKey, be)
@@ -123,14 +137,20 @@ func CommandMiddleware(sh ssh.Handler) ssh.Handler {
 			cmd.GitUploadPackCommand(),
 			cmd.GitUploadArchiveCommand(),
 			cmd.GitReceivePackCommand(),
+			cmd.AccessCommand(),
 			cmd.RepoCommand(),
 			cmd.SettingsCommand(),
+			cmd.WebhookCommand(),
 			cmd.UserCommand(),
+			cmd.IPCommand(),
+			cmd.ActivityCommand(),
+			cmd.TeamCommand(),
 			cmd.InfoCommand(),
 			cmd.PubkeyCommand(),
 			cmd.SetUsernameCommand(),
 			cmd.JWTCommand(),
 			cmd.TokenCommand(),
+			cmd.WhoamiCommand(),
 		)
 
 		if cfg.LFS.Enabled {
@@ -156,7 +176,13 @@ func CommandMiddleware(sh ssh.Handler) ssh.Handler {
 		rootCmd.SetContext(ctx)
 
 		if err := rootCmd.ExecuteContext(ctx); err != nil {
-			s.Exit(1) //nolint: errcheck
+			code := 1
+			// Propagate the exit code of failed git services.
+			var ec interface{ ExitCode() int }
+			if errors.As(err, &ec) && ec.ExitCode() > 0 {
+				code = ec.ExitCode()
+			}
+			s.Exit(code) //nolint: errcheck
 			return
 		}
 	}
@@ -166,7 +192,7 @@ func CommandMiddleware(sh ssh.Handler) ssh.Handler {
 func LoggingMiddleware(sh ssh.Handler) ssh.Handler {
 	return func(s ssh.Session) {
 		ctx := s.Context()
-		logger := log.FromContext(ctx).WithPrefix("ssh")
+		logger := logr.Subsystem(ctx, "ssh", "ssh")
 		ct := time.Now()
 		hpk := sshutils.MarshalAuthorizedKey(s.PublicKey())
 		ptyReq, _, isPty := s.Pty()
@@ -1,9 +1,11 @@
 package ssh
 
 import (
+	"strings"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"charm.land/log/v2"
 	"charm.land/wish/v2"
 	bm "charm.land/wish/v2/bubbletea"
 	"github.com/charmbracelet/soft-serve/pkg/access"
@@ -11,6 +13,7 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
+	"github.com/charmbracelet/soft-serve/pkg/ui/styles"
 	"github.com/charmbracelet/ssh"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -62,7 +65,11 @@ func SessionHandler(s ssh.Session) *tea.Program {
 
 	c := common.NewCommon(ctx, pty.Window.Width, pty.Window.Height)
 	c.SetValue(common.ConfigKey, cfg)
+	if theme, err := cfg.UI.Theme.Theme(); err == nil {
+		c.Styles = styles.NewStyles(theme)
+	}
 	m := NewUI(c, initialRepo)
+	m.motd = motd(ctx, cfg)
 	p := tea.NewProgram(m, opts...)
 
 	tuiSessionCounter.WithLabelValues(initialRepo, pty.Term).Inc()
@@ -75,3 +82,18 @@ func SessionHandler(s ssh.Session) *tea.Program {
 
 	return p
 }
+
+// motd returns the message of the day of the session user.
+func motd(ctx ssh.Context, cfg *config.Config) string {
+	data := messageData{ServerName: cfg.Name}
+	if user := proto.UserFromContext(ctx); user != nil {
+		data.Username = user.Username()
+	}
+	motd, err := cfg.SSH.MOTD.Render(data)
+	if err != nil {
+		log.FromContext(ctx).Error("failed to render motd", "err", err)
+		return ""
+	}
+
+	return strings.TrimSpace(motd)
+}
@@ -2,10 +2,14 @@ package ssh
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"charm.land/log/v2"
@@ -13,9 +17,14 @@ import (
 	bm "charm.land/wish/v2/bubbletea"
 	rm "charm.land/wish/v2/recover"
 	"github.com/charmbracelet/keygen"
+	"github.com/charmbracelet/soft-serve/pkg/audit"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/ratelimit"
 	"github.com/charmbracelet/soft-serve/pkg/store"
 	"github.com/charmbracelet/ssh"
 	"github.com/prometheus/client_golang/prometheus"
@@ -37,28 +46,64 @@ var (
 		Name:      "keyboard_interactive_auth_total",
 		Help:      "The total number of keyboard interactive auth requests",
 	}, []string{"allowed"})
+
+	rateLimitCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "ssh",
+		Name:      "rate_limit_total",
+		Help:      "The total number of rate limited connections and auth attempts",
+	}, []string{"kind", "allowed"})
+
+	activeConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "soft_serve",
+		Subsystem: "ssh",
+		Name:      "active_connections",
+		Help:      "The number of open SSH connections",
+	})
 )
 
+// messageData is the data the banner and the message of the day are
+// executed with.
+type messageData struct {
+	ServerName string
+	Username   string
+}
+
+// idleTimeoutGrace is added to the connection idle timeout so idle sessions
+// are closed politely first.
+const idleTimeoutGrace = 30 * time.Second
+
 // SSHServer is a SSH server that implements the git protocol.
 type SSHServer struct { //nolint: revive
 	srv    *ssh.Server
-	cfg    *config.Config
 	be     *backend.Backend
 	ctx    context.Context
 	logger *log.Logger
+
+	// live holds the configuration and the settings that change when it's
+	// reloaded, they're replaced at once.
+	live atomic.Pointer[liveSettings]
+
+	interactiveAuth InteractiveAuthenticator
+}
+
+// liveSettings are the settings of a running SSH server.
+type liveSettings struct {
+	cfg         *config.Config
+	connLimiter ratelimit.Limiter
+	authLimiter ratelimit.Limiter
 }
 
 // NewSSHServer returns a new SSHServer.
 func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 	cfg := config.FromContext(ctx)
-	logger := log.FromContext(ctx).WithPrefix("ssh")
+	logger := logr.Subsystem(ctx, "ssh", "ssh")
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 	be := backend.FromContext(ctx)
 
 	var err error
 	s := &SSHServer{
-		cfg:    cfg,
 		ctx:    ctx,
 		be:     be,
 		logger: logger,
@@ -73,6 +118,8 @@ func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 			CommandMiddleware,
 			// Logging middleware.
 			LoggingMiddleware,
+			// Idle timeout middleware.
+			IdleTimeoutMiddleware(time.Duration(cfg.SSH.IdleTimeout)*time.Second),
 			// Authentication middleware.
 			// gossh.PublicKeyHandler doesn't guarantee that the public key
 			// is in fact the one used for authentication, so we need to
@@ -80,10 +127,20 @@ func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 			AuthenticationMiddleware,
 			// Context middleware.
 			// This must come first to set up the context.
-			ContextMiddleware(cfg, dbx, datastore, be, logger),
+			contextMiddleware(s.config, dbx, datastore, be, logger),
 		),
 	}
 
+	s.Reload(cfg)
+
+	kic := cfg.SSH.KeyboardInteractive
+	if kic.Command != "" {
+		s.interactiveAuth = &CommandAuthenticator{Command: kic.Command}
+	}
+	if kic.Enabled && s.interactiveAuth == nil {
+		logger.Warn("keyboard interactive auth is enabled without a command")
+	}
+
 	opts := []ssh.Option{
 		ssh.PublicKeyAuth(s.PublicKeyHandler),
 		ssh.KeyboardInteractiveAuth(s.KeyboardInteractiveHandler),
@@ -100,22 +157,33 @@ func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 		return nil, err
 	}
 
-	if config.IsDebug() {
-		s.srv.ServerConfigCallback = func(_ ssh.Context) *gossh.ServerConfig {
-			return &gossh.ServerConfig{
-				AuthLogCallback: func(conn gossh.ConnMetadata, method string, err error) {
-					logger.Debug("authentication", "user", conn.User(), "method", method, "err", err)
-				},
+	s.srv.ServerConfigCallback = func(_ ssh.Context) *gossh.ServerConfig {
+		sc := &gossh.ServerConfig{
+			Config: gossh.Config{
+				Ciphers:      cfg.SSH.Ciphers,
+				KeyExchanges: cfg.SSH.KeyExchanges,
+				MACs:         cfg.SSH.MACs,
+			},
+		}
+		if config.IsDebug() {
+			sc.AuthLogCallback = func(conn gossh.ConnMetadata, method string, err error) {
+				logger.Debug("authentication", "user", conn.User(), "method", method, "err", err)
 			}
 		}
+		return sc
 	}
 
+	s.srv.ConnCallback = s.ConnCallback
+	s.srv.BannerHandler = s.BannerHandler
+
 	if cfg.SSH.MaxTimeout > 0 {
 		s.srv.MaxTimeout = time.Duration(cfg.SSH.MaxTimeout) * time.Second
 	}
 
 	if cfg.SSH.IdleTimeout > 0 {
-		s.srv.IdleTimeout = time.Duration(cfg.SSH.IdleTimeout) * time.Second
+		// Sessions are closed with a message by IdleTimeoutMiddleware, this
+		// only closes connections that never start one.
+		s.srv.IdleTimeout = time.Duration(cfg.SSH.IdleTimeout)*time.Second + idleTimeoutGrace
 	}
 
 	// Create client ssh key
@@ -139,14 +207,140 @@ func (s *SSHServer) Serve(l net.Listener) error {
 	return s.srv.Serve(l)
 }
 
+// Reload applies the settings of cfg that can change while the server runs,
+// see [config.Config.Reload], to new connections and sessions. The rate limit
+// buckets are kept if the limits didn't change.
+func (s *SSHServer) Reload(cfg *config.Config) {
+	l := &liveSettings{cfg: cfg}
+	if old := s.live.Load(); old != nil && old.cfg.SSH.RateLimit == cfg.SSH.RateLimit {
+		l.connLimiter, l.authLimiter = old.connLimiter, old.authLimiter
+	} else {
+		rl := cfg.SSH.RateLimit
+		if rl.ConnectionRate > 0 {
+			l.connLimiter = ratelimit.NewTokenBucket(rl.ConnectionRate, rl.ConnectionBurst)
+		}
+		if rl.AuthRate > 0 {
+			l.authLimiter = ratelimit.NewTokenBucket(rl.AuthRate, rl.AuthBurst)
+		}
+	}
+
+	s.live.Store(l)
+}
+
+// settings returns the current settings of the server.
+func (s *SSHServer) settings() *liveSettings {
+	if l := s.live.Load(); l != nil {
+		return l
+	}
+	return &liveSettings{}
+}
+
+// config returns the current configuration of the server.
+func (s *SSHServer) config() *config.Config {
+	return s.settings().cfg
+}
+
+// SetRateLimiters replaces the limiters of new connections and authentication
+// attempts per source IP. A nil limiter disables the corresponding limit.
+func (s *SSHServer) SetRateLimiters(conn, auth ratelimit.Limiter) {
+	l := *s.settings()
+	l.connLimiter, l.authLimiter = conn, auth
+	s.live.Store(&l)
+}
+
+// ConnCallback drops new connections over the rate limit of their source IP
+// before the key exchange.
+func (s *SSHServer) ConnCallback(_ ssh.Context, conn net.Conn) net.Conn {
+	if !s.allow(s.settings().connLimiter, "connection", conn.RemoteAddr()) {
+		s.logger.Debug("rate limited connection", "remote-addr", conn.RemoteAddr())
+		return nil
+	}
+
+	activeConnections.Inc()
+	return &countedConn{Conn: conn}
+}
+
+// countedConn is a connection counted in the active connections until it's
+// closed.
+type countedConn struct {
+	net.Conn
+	once sync.Once
+}
+
+// Close implements net.Conn.
+func (c *countedConn) Close() error {
+	c.once.Do(activeConnections.Dec)
+	return c.Conn.Close()
+}
+
+// allow reports whether an event of kind from addr is within the limits of
+// limiter.
+func (s *SSHServer) allow(limiter ratelimit.Limiter, kind string, addr net.Addr) bool {
+	if limiter == nil || addr == nil {
+		return true
+	}
+
+	host := addr.String()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	allowed := limiter.Allow(host)
+	rateLimitCounter.WithLabelValues(kind, strconv.FormatBool(allowed)).Inc()
+	return allowed
+}
+
+// allowAddr returns whether the ip rules allow the client to authenticate as
+// user, or anonymously when user is nil.
+func (s *SSHServer) allowAddr(ctx ssh.Context, user proto.User) bool {
+	var addr string
+	if ctx.RemoteAddr() != nil {
+		addr = ctx.RemoteAddr().String()
+	}
+
+	actx := audit.WithSource(ctx, audit.Source{Transport: "ssh", RemoteAddr: addr})
+	if err := s.be.CheckRemoteAddr(actx, addr, user); err != nil {
+		s.logger.Info("refused by ip rules", "user", ctx.User(), "remote-addr", addr, "err", err)
+		return false
+	}
+
+	return true
+}
+
 // Close closes the SSH server.
 func (s *SSHServer) Close() error {
 	return s.srv.Close()
 }
 
-// Shutdown gracefully shuts down the SSH server.
+// sessionCloseGrace is how long connections have to close on their own once
+// the git operations are done during a shutdown, so clients get the exit
+// status of their commands.
+const sessionCloseGrace = time.Second
+
+// Shutdown gracefully shuts down the SSH server. It stops accepting
+// connections and waits for the running git operations to finish, then
+// closes the remaining connections, like idle TUI sessions.
 func (s *SSHServer) Shutdown(ctx context.Context) error {
-	return s.srv.Shutdown(ctx)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.srv.Shutdown(ctx)
+	}()
+
+	if err := git.WaitServices(ctx); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(sessionCloseGrace):
+	}
+
+	if err := s.srv.Close(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return err
+	}
+
+	return <-errc
 }
 
 func initializePermissions(ctx ssh.Context) {
@@ -165,6 +359,16 @@ func (s *SSHServer) PublicKeyHandler(ctx ssh.Context, pk ssh.PublicKey) (allowed
 		return false
 	}
 
+	if !s.allow(s.settings().authLimiter, "auth", ctx.RemoteAddr()) {
+		s.logger.Debug("rate limited public key auth", "remote-addr", ctx.RemoteAddr())
+		return false
+	}
+
+	user, _ := s.be.UserByPublicKey(ctx, pk)
+	if !s.allowAddr(ctx, user) {
+		return false
+	}
+
 	allowed = true
 
 	// XXX: store the first "approved" public-key fingerprint in the
@@ -180,15 +384,42 @@ func (s *SSHServer) PublicKeyHandler(ctx ssh.Context, pk ssh.PublicKey) (allowed
 }
 
 // KeyboardInteractiveHandler handles keyboard interactive authentication.
-// This is used after all public key authentication has failed.
-func (s *SSHServer) KeyboardInteractiveHandler(ctx ssh.Context, _ gossh.KeyboardInteractiveChallenge) bool {
-	ac := s.be.AllowKeyless(ctx)
-	keyboardInteractiveCounter.WithLabelValues(strconv.FormatBool(ac)).Inc()
+// This is used after all public key authentication has failed. When enabled,
+// clients are prompted for a code verified by the InteractiveAuthenticator,
+// and fall back to keyless access if they don't enter one.
+func (s *SSHServer) KeyboardInteractiveHandler(ctx ssh.Context, challenge gossh.KeyboardInteractiveChallenge) bool {
+	if !s.allow(s.settings().authLimiter, "auth", ctx.RemoteAddr()) {
+		s.logger.Debug("rate limited keyboard interactive auth", "remote-addr", ctx.RemoteAddr())
+		return false
+	}
 
-	// If we're allowing keyless access, reset the public key fingerprint
 	initializePermissions(ctx)
 	perms := ctx.Permissions()
 
+	if s.config().SSH.KeyboardInteractive.Enabled && s.interactiveAuth != nil {
+		user, err := s.interactiveLogin(ctx, challenge)
+		if !errors.Is(err, errNoCode) {
+			keyboardInteractiveCounter.WithLabelValues(strconv.FormatBool(err == nil)).Inc()
+			if err != nil {
+				s.logger.Info("keyboard interactive auth failed", "user", ctx.User(), "remote-addr", ctx.RemoteAddr(), "err", err)
+				return false
+			}
+			if !s.allowAddr(ctx, user) {
+				return false
+			}
+
+			perms.Extensions["pubkey-fp"] = ""
+			perms.Extensions[interactiveUserExtension] = user.Username()
+			ctx.SetValue(ssh.ContextKeyPermissions, perms)
+			return true
+		}
+	}
+
+	ac := s.be.AllowKeyless(ctx) && s.allowAddr(ctx, nil)
+	keyboardInteractiveCounter.WithLabelValues(strconv.FormatBool(ac)).Inc()
+
+	// If we're allowing keyless access, reset the public key fingerprint
+
 	if ac {
 		// XXX: reset the public-key fingerprint. This is used to validate the
 		// public key being used to authenticate.
@@ -197,3 +428,22 @@ func (s *SSHServer) KeyboardInteractiveHandler(ctx ssh.Context, _ gossh.Keyboard
 	}
 	return ac
 }
+
+// BannerHandler returns the banner sent to clients before they authenticate.
+// The username is the one the client asked to log in as.
+func (s *SSHServer) BannerHandler(ctx ssh.Context) string {
+	cfg := s.config()
+	banner, err := cfg.SSH.Banner.Render(messageData{
+		ServerName: cfg.Name,
+		Username:   ctx.User(),
+	})
+	if err != nil {
+		s.logger.Error("failed to render ssh banner", "err", err)
+		return ""
+	}
+	if banner != "" && !strings.HasSuffix(banner, "\n") {
+		banner += "\n"
+	}
+
+	return banner
+}
@@ -0,0 +1,137 @@
+package ssh
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"charm.land/log/v2"
+	"charm.land/wish/v2/testsession"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/ratelimit"
+	"github.com/charmbracelet/ssh"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestConnCallbackRateLimit(t *testing.T) {
+	s := &SSHServer{logger: log.New(io.Discard)}
+	s.SetRateLimiters(ratelimit.NewTokenBucket(0.001, 2), nil)
+
+	conn := func(ip string, port int) net.Conn {
+		return &addrConn{remote: &net.TCPAddr{IP: net.ParseIP(ip), Port: port}}
+	}
+
+	// The limit applies to the source IP regardless of the port.
+	for i, want := range []bool{true, true, false} {
+		if got := s.ConnCallback(nil, conn("192.0.2.1", 1000+i)) != nil; got != want {
+			t.Errorf("connection %d allowed = %t, want %t", i, got, want)
+		}
+	}
+
+	if s.ConnCallback(nil, conn("192.0.2.2", 1000)) == nil {
+		t.Error("connection from another IP was dropped")
+	}
+
+	s.SetRateLimiters(nil, nil)
+	if s.ConnCallback(nil, conn("192.0.2.1", 1000)) == nil {
+		t.Error("connection was dropped without a limiter")
+	}
+}
+
+type addrConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c *addrConn) RemoteAddr() net.Addr { return c.remote }
+
+func TestConnCallbackActiveConnections(t *testing.T) {
+	s := &SSHServer{logger: log.New(io.Discard)}
+	before := testutil.ToFloat64(activeConnections)
+
+	c1, c2 := net.Pipe()
+	defer c2.Close() //nolint: errcheck
+	conn := s.ConnCallback(nil, c1)
+	if got := testutil.ToFloat64(activeConnections) - before; got != 1 {
+		t.Fatalf("active connections = %v, want 1", got)
+	}
+
+	conn.Close() //nolint: errcheck
+	conn.Close() //nolint: errcheck
+	if got := testutil.ToFloat64(activeConnections) - before; got != 0 {
+		t.Errorf("active connections after close = %v, want 0", got)
+	}
+}
+
+func TestBannerHandler(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Name = "Soft Serve"
+	cfg.SSH.Banner.Text = "Authorized use only on {{ .ServerName }}, {{ .Username }}."
+	s := &SSHServer{logger: log.New(io.Discard)}
+	s.Reload(cfg)
+
+	// The banner is sent before the client fails to authenticate.
+	addr := testsession.Listen(t, &ssh.Server{
+		BannerHandler: s.BannerHandler,
+		PasswordHandler: func(ssh.Context, string) bool {
+			return false
+		},
+	})
+	bannerOf := func(user string) string {
+		var banner string
+		_, err := testsession.NewClientSession(t, addr, &gossh.ClientConfig{
+			User: user,
+			Auth: []gossh.AuthMethod{gossh.Password("nope")},
+			BannerCallback: func(message string) error {
+				banner = message
+				return nil
+			},
+		})
+		if err == nil {
+			t.Fatal("authentication succeeded")
+		}
+		return banner
+	}
+
+	if got, want := bannerOf("frankie"), "Authorized use only on Soft Serve, frankie.\n"; got != want {
+		t.Errorf("banner = %q, want %q", got, want)
+	}
+
+	// New connections get the banner of a reloaded configuration.
+	reloaded := *cfg
+	reloaded.SSH.Banner.Text = "Be nice, {{ .Username }}."
+	s.Reload(&reloaded)
+	if got, want := bannerOf("frankie"), "Be nice, frankie.\n"; got != want {
+		t.Errorf("reloaded banner = %q, want %q", got, want)
+	}
+}
+
+func TestReloadRateLimits(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SSH.RateLimit.ConnectionRate = 0.001
+	cfg.SSH.RateLimit.ConnectionBurst = 1
+	s := &SSHServer{logger: log.New(io.Discard)}
+	s.Reload(cfg)
+
+	conn := &addrConn{remote: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1000}}
+	if s.ConnCallback(nil, conn) == nil {
+		t.Fatal("first connection was dropped")
+	}
+
+	// Reloading the same limits keeps the buckets.
+	same := *cfg
+	same.SSH.Banner.Text = "hello"
+	s.Reload(&same)
+	if s.ConnCallback(nil, conn) != nil {
+		t.Error("connection over the limit was allowed after a reload")
+	}
+
+	// Disabling the limit applies right away.
+	off := *cfg
+	off.SSH.RateLimit.ConnectionRate = 0
+	s.Reload(&off)
+	if s.ConnCallback(nil, conn) == nil {
+		t.Error("connection was dropped after the limit was disabled")
+	}
+}
@@ -36,6 +36,7 @@ const (
 type UI struct {
 	serverName  string
 	initialRepo string
+	motd        string
 	common      common.Common
 	pages       []common.Component
 	activePage  page
@@ -70,6 +71,9 @@ func (ui *UI) getMargins() (wm, hm int) {
 	case selectionPage:
 		hm += ui.common.Styles.ServerName.GetHeight() +
 			ui.common.Styles.ServerName.GetVerticalFrameSize()
+		if ui.motd != "" {
+			hm += lipgloss.Height(ui.motdView())
+		}
 	case repoPage:
 	}
 	wm += style.GetHorizontalFrameSize()
@@ -155,12 +159,18 @@ func (ui *UI) Init() tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
-// IsFiltering returns true if the selection page is filtering.
+// IsFiltering returns true if the selection page or the ref picker of the
+// repo page is filtering.
 func (ui *UI) IsFiltering() bool {
-	if ui.activePage == selectionPage {
+	switch ui.activePage {
+	case selectionPage:
 		if s, ok := ui.pages[selectionPage].(*selection.Selection); ok && s.FilterState() == list.Filtering {
 			return true
 		}
+	case repoPage:
+		if r, ok := ui.pages[repoPage].(*repo.Repo); ok && r.IsFiltering() {
+			return true
+		}
 	}
 	return false
 }
@@ -283,6 +293,9 @@ func (ui *UI) View() tea.View {
 		view = "Unknown state :/ this is a bug!"
 	}
 	if ui.activePage == selectionPage {
+		if ui.motd != "" {
+			view = lipgloss.JoinVertical(lipgloss.Left, ui.motdView(), view)
+		}
 		view = lipgloss.JoinVertical(lipgloss.Left, ui.header.View(), view)
 	}
 	if ui.showFooter {
@@ -294,6 +307,13 @@ func (ui *UI) View() tea.View {
 	return v
 }
 
+// motdView returns the message of the day shown under the server name.
+func (ui *UI) motdView() string {
+	style := ui.common.Styles.MOTD
+	width := ui.common.Width - ui.common.Styles.App.GetHorizontalFrameSize()
+	return style.Width(max(width-style.GetHorizontalMargins(), 0)).Render(ui.motd)
+}
+
 func (ui *UI) openRepo(rn string) (proto.Repository, error) {
 	cfg := ui.common.Config()
 	if cfg == nil {
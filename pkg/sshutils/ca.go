@@ -0,0 +1,163 @@
+package sshutils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// ErrNoPrincipals is returned when a certificate has no principals.
+var ErrNoPrincipals = errors.New("certificate has no principals")
+
+// CertChecker verifies SSH user certificates against a file of trusted CA
+// keys and an optional key revocation list. The files are read again when
+// they're modified.
+type CertChecker struct {
+	caPath  string
+	krlPath string
+
+	mu     sync.Mutex
+	cas    []gossh.PublicKey
+	caMod  time.Time
+	krl    *KRL
+	krlMod time.Time
+}
+
+// NewCertChecker returns a new CertChecker trusting the CA keys in caPath,
+// in the authorized keys format, and honoring the key revocation list at
+// krlPath. Either path can be empty.
+func NewCertChecker(caPath, krlPath string) *CertChecker {
+	return &CertChecker{
+		caPath:  caPath,
+		krlPath: krlPath,
+	}
+}
+
+// Principals returns the principals of cert once it's verified to be a valid
+// user certificate signed by a trusted CA that isn't revoked.
+func (c *CertChecker) Principals(cert *gossh.Certificate) ([]string, error) {
+	if cert.CertType != gossh.UserCert {
+		return nil, errors.New("not a user certificate")
+	}
+
+	// Certificates without principals are valid for any user in OpenSSH,
+	// refuse them since we couldn't tell who they belong to.
+	if len(cert.ValidPrincipals) == 0 {
+		return nil, ErrNoPrincipals
+	}
+
+	cas, krl, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	trusted := false
+	for _, ca := range cas {
+		if KeysEqual(ca, cert.SignatureKey) {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return nil, errors.New("certificate signed by an untrusted authority")
+	}
+
+	// CheckCert verifies the validity period, signature, critical options,
+	// and revocation.
+	checker := gossh.CertChecker{
+		IsRevoked: func(cert *gossh.Certificate) bool {
+			return krl.IsRevoked(cert)
+		},
+	}
+	if err := checker.CheckCert(cert.ValidPrincipals[0], cert); err != nil {
+		return nil, err
+	}
+
+	return cert.ValidPrincipals, nil
+}
+
+// IsRevoked reports whether key is in the key revocation list.
+func (c *CertChecker) IsRevoked(key gossh.PublicKey) (bool, error) {
+	_, krl, err := c.load()
+	if err != nil {
+		return false, err
+	}
+
+	return krl.IsRevoked(key), nil
+}
+
+// load returns the trusted CA keys and the key revocation list, reading
+// their files again if they changed.
+func (c *CertChecker) load() ([]gossh.PublicKey, *KRL, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.caPath != "" {
+		if err := c.loadCAs(); err != nil {
+			return nil, nil, fmt.Errorf("trusted user ca keys: %w", err)
+		}
+	}
+
+	if c.krlPath != "" {
+		if err := c.loadKRL(); err != nil {
+			return nil, nil, fmt.Errorf("revoked keys: %w", err)
+		}
+	}
+
+	return c.cas, c.krl, nil
+}
+
+func (c *CertChecker) loadCAs() error {
+	info, err := os.Stat(c.caPath)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(c.caMod) {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.caPath)
+	if err != nil {
+		return err
+	}
+
+	var cas []gossh.PublicKey
+	for len(data) > 0 {
+		pk, _, _, rest, err := gossh.ParseAuthorizedKey(data)
+		if err != nil {
+			break
+		}
+		cas = append(cas, pk)
+		data = rest
+	}
+
+	c.cas, c.caMod = cas, info.ModTime()
+	return nil
+}
+
+func (c *CertChecker) loadKRL() error {
+	info, err := os.Stat(c.krlPath)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(c.krlMod) {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.krlPath)
+	if err != nil {
+		return err
+	}
+
+	krl, err := ParseKRL(data)
+	if err != nil {
+		return err
+	}
+
+	c.krl, c.krlMod = krl, info.ModTime()
+	return nil
+}
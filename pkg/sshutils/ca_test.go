@@ -0,0 +1,197 @@
+package sshutils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func newCert(t *testing.T, ca ssh.Signer, key ssh.PublicKey, modify func(*ssh.Certificate)) *ssh.Certificate {
+	t.Helper()
+	cert := &ssh.Certificate{
+		Key:             key,
+		Serial:          1,
+		CertType:        ssh.UserCert,
+		KeyId:           "test",
+		ValidPrincipals: []string{"alice"},
+		ValidAfter:      uint64(time.Now().Add(-time.Hour).Unix()),
+		ValidBefore:     uint64(time.Now().Add(time.Hour).Unix()),
+	}
+	if modify != nil {
+		modify(cert)
+	}
+	if err := cert.SignCert(rand.Reader, ca); err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func writeFile(t *testing.T, name string, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// generateKRL generates a key revocation list with ssh-keygen.
+func generateKRL(t *testing.T, ca ssh.PublicKey, spec string) []byte {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not found")
+	}
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pub")
+	specPath := filepath.Join(dir, "spec")
+	krlPath := filepath.Join(dir, "krl")
+	if err := os.WriteFile(caPath, ssh.MarshalAuthorizedKey(ca), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(specPath, []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("ssh-keygen", "-k", "-f", krlPath, "-s", caPath, specPath).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v: %s", err, out)
+	}
+
+	data, err := os.ReadFile(krlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseKRL(t *testing.T) {
+	ca, otherCA := newSigner(t), newSigner(t)
+	user, revokedKey, hashedKey := newSigner(t), newSigner(t), newSigner(t)
+
+	spec := strings.Join([]string{
+		"serial: 5",
+		"serial: 10-20",
+		"serial: 100",
+		"serial: 102",
+		"id: stolen",
+		"key: " + MarshalAuthorizedKey(revokedKey.PublicKey()),
+		"hash: " + ssh.FingerprintSHA256(hashedKey.PublicKey()),
+	}, "\n") + "\n"
+	krl, err := ParseKRL(generateKRL(t, ca.PublicKey(), spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		key  ssh.PublicKey
+		want bool
+	}{
+		{"valid key", user.PublicKey(), false},
+		{"explicit key", revokedKey.PublicKey(), true},
+		{"key hash", hashedKey.PublicKey(), true},
+		{"valid cert", newCert(t, ca, user.PublicKey(), nil), false},
+		{"serial", newCert(t, ca, user.PublicKey(), func(c *ssh.Certificate) { c.Serial = 5 }), true},
+		{"serial range", newCert(t, ca, user.PublicKey(), func(c *ssh.Certificate) { c.Serial = 15 }), true},
+		{"serial after range", newCert(t, ca, user.PublicKey(), func(c *ssh.Certificate) { c.Serial = 21 }), false},
+		{"serial bitmap", newCert(t, ca, user.PublicKey(), func(c *ssh.Certificate) { c.Serial = 102 }), true},
+		{"serial not in bitmap", newCert(t, ca, user.PublicKey(), func(c *ssh.Certificate) { c.Serial = 101 }), false},
+		{"key id", newCert(t, ca, user.PublicKey(), func(c *ssh.Certificate) { c.KeyId = "stolen" }), true},
+		{"other ca", newCert(t, otherCA, user.PublicKey(), func(c *ssh.Certificate) { c.Serial = 5 }), false},
+		{"revoked certified key", newCert(t, ca, revokedKey.PublicKey(), nil), true},
+	}
+
+	for _, c := range cases {
+		if got := krl.IsRevoked(c.key); got != c.want {
+			t.Errorf("%s: IsRevoked() = %t, want %t", c.name, got, c.want)
+		}
+	}
+}
+
+func TestParseKRLAuthorizedKeys(t *testing.T) {
+	revoked, other := newSigner(t), newSigner(t)
+	krl, err := ParseKRL(ssh.MarshalAuthorizedKey(revoked.PublicKey()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !krl.IsRevoked(revoked.PublicKey()) {
+		t.Error("revoked key isn't revoked")
+	}
+	if krl.IsRevoked(other.PublicKey()) {
+		t.Error("other key is revoked")
+	}
+
+	if _, err := ParseKRL([]byte("SSHKRL\n\x00\x00")); !errors.Is(err, ErrInvalidKRL) {
+		t.Errorf("ParseKRL() error = %v, want ErrInvalidKRL", err)
+	}
+}
+
+func TestCertCheckerPrincipals(t *testing.T) {
+	ca, otherCA := newSigner(t), newSigner(t)
+	user := newSigner(t)
+	caPath := writeFile(t, "ca.pub", string(ssh.MarshalAuthorizedKey(ca.PublicKey())))
+	krlPath := writeFile(t, "revoked", "")
+	checker := NewCertChecker(caPath, krlPath)
+
+	cases := []struct {
+		name    string
+		cert    *ssh.Certificate
+		wantErr bool
+	}{
+		{"valid", newCert(t, ca, user.PublicKey(), nil), false},
+		{"untrusted ca", newCert(t, otherCA, user.PublicKey(), nil), true},
+		{"expired", newCert(t, ca, user.PublicKey(), func(c *ssh.Certificate) {
+			c.ValidBefore = uint64(time.Now().Add(-time.Minute).Unix())
+		}), true},
+		{"host cert", newCert(t, ca, user.PublicKey(), func(c *ssh.Certificate) { c.CertType = ssh.HostCert }), true},
+		{"no principals", newCert(t, ca, user.PublicKey(), func(c *ssh.Certificate) { c.ValidPrincipals = nil }), true},
+		{"critical option", newCert(t, ca, user.PublicKey(), func(c *ssh.Certificate) {
+			c.CriticalOptions = map[string]string{"force-command": "true"}
+		}), true},
+	}
+
+	for _, c := range cases {
+		principals, err := checker.Principals(c.cert)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: Principals() error = %v, want error %t", c.name, err, c.wantErr)
+		}
+		if err == nil && strings.Join(principals, ",") != "alice" {
+			t.Errorf("%s: Principals() = %v, want [alice]", c.name, principals)
+		}
+	}
+
+	// Revoking the key takes effect without restarting.
+	valid := newCert(t, ca, user.PublicKey(), nil)
+	if err := os.WriteFile(krlPath, ssh.MarshalAuthorizedKey(user.PublicKey()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(krlPath, time.Now(), time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := checker.Principals(valid); err == nil {
+		t.Error("Principals() of a revoked certificate didn't fail")
+	}
+	if revoked, err := checker.IsRevoked(user.PublicKey()); err != nil || !revoked {
+		t.Errorf("IsRevoked() = %t, %v, want true, nil", revoked, err)
+	}
+}
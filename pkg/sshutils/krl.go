@@ -0,0 +1,276 @@
+package sshutils
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint: gosec
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// krlMagic is the magic number of OpenSSH key revocation lists.
+const krlMagic = "SSHKRL\n\x00"
+
+// KRL section types as defined in OpenSSH PROTOCOL.krl.
+const (
+	krlSectionCertificates      = 1
+	krlSectionExplicitKey       = 2
+	krlSectionFingerprintSHA1   = 3
+	krlSectionSignature         = 4
+	krlSectionFingerprintSHA256 = 5
+
+	krlSectionCertSerialList   = 0x20
+	krlSectionCertSerialRange  = 0x21
+	krlSectionCertSerialBitmap = 0x22
+	krlSectionCertKeyID        = 0x23
+)
+
+// ErrInvalidKRL is returned when a key revocation list can't be parsed.
+var ErrInvalidKRL = errors.New("invalid key revocation list")
+
+// KRL is a list of revoked SSH keys and certificates. It can be parsed from
+// an OpenSSH binary key revocation list, as generated by ssh-keygen -k, or
+// from a list of public keys in the authorized keys format.
+type KRL struct {
+	keys   map[string]struct{}
+	sha1   map[string]struct{}
+	sha256 map[string]struct{}
+	certs  []krlCerts
+}
+
+// krlCerts are the certificates revoked for a CA.
+type krlCerts struct {
+	// ca is the marshaled CA key, empty for any CA.
+	ca      string
+	serials []krlSerialRange
+	keyIDs  map[string]struct{}
+}
+
+type krlSerialRange struct {
+	min, max uint64
+}
+
+// ParseKRL parses a key revocation list.
+func ParseKRL(data []byte) (*KRL, error) {
+	k := &KRL{
+		keys:   map[string]struct{}{},
+		sha1:   map[string]struct{}{},
+		sha256: map[string]struct{}{},
+	}
+
+	if !bytes.HasPrefix(data, []byte(krlMagic)) {
+		for len(bytes.TrimSpace(data)) > 0 {
+			pk, _, _, rest, err := gossh.ParseAuthorizedKey(data)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidKRL, err)
+			}
+			k.keys[string(pk.Marshal())] = struct{}{}
+			data = rest
+		}
+		return k, nil
+	}
+
+	r := &krlReader{data: data[len(krlMagic):]}
+	if version := r.uint32(); version != 1 {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidKRL, version)
+	}
+
+	// KRL version, generated date, flags, reserved, and comment.
+	r.uint64()
+	r.uint64()
+	r.uint64()
+	r.string()
+	r.string()
+
+	for r.err == nil && len(r.data) > 0 {
+		typ := r.byte()
+		section := &krlReader{data: r.string()}
+		if r.err != nil {
+			break
+		}
+
+		switch typ {
+		case krlSectionCertificates:
+			k.certs = append(k.certs, parseKRLCerts(section))
+		case krlSectionExplicitKey:
+			for section.err == nil && len(section.data) > 0 {
+				k.keys[string(section.string())] = struct{}{}
+			}
+		case krlSectionFingerprintSHA1:
+			for section.err == nil && len(section.data) > 0 {
+				k.sha1[string(section.string())] = struct{}{}
+			}
+		case krlSectionFingerprintSHA256:
+			for section.err == nil && len(section.data) > 0 {
+				k.sha256[string(section.string())] = struct{}{}
+			}
+		case krlSectionSignature:
+			// Signatures are optional and only verified by ssh-keygen -Q.
+			continue
+		default:
+			return nil, fmt.Errorf("%w: unsupported section type %d", ErrInvalidKRL, typ)
+		}
+
+		if section.err != nil {
+			return nil, section.err
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return k, nil
+}
+
+func parseKRLCerts(r *krlReader) krlCerts {
+	c := krlCerts{keyIDs: map[string]struct{}{}}
+	if ca := r.string(); len(ca) > 0 {
+		c.ca = string(ca)
+	}
+	r.string() // reserved
+
+	for r.err == nil && len(r.data) > 0 {
+		typ := r.byte()
+		section := &krlReader{data: r.string()}
+		if r.err != nil {
+			break
+		}
+
+		switch typ {
+		case krlSectionCertSerialList:
+			for section.err == nil && len(section.data) > 0 {
+				s := section.uint64()
+				c.serials = append(c.serials, krlSerialRange{s, s})
+			}
+		case krlSectionCertSerialRange:
+			c.serials = append(c.serials, krlSerialRange{section.uint64(), section.uint64()})
+		case krlSectionCertSerialBitmap:
+			offset := section.uint64()
+			bitmap := new(big.Int).SetBytes(section.string())
+			for i := range bitmap.BitLen() {
+				if bitmap.Bit(i) == 1 {
+					s := offset + uint64(i) //nolint: gosec
+					c.serials = append(c.serials, krlSerialRange{s, s})
+				}
+			}
+		case krlSectionCertKeyID:
+			for section.err == nil && len(section.data) > 0 {
+				c.keyIDs[string(section.string())] = struct{}{}
+			}
+		default:
+			r.err = fmt.Errorf("%w: unsupported certificate section type %d", ErrInvalidKRL, typ)
+		}
+
+		if section.err != nil {
+			r.err = section.err
+		}
+	}
+
+	return c
+}
+
+// IsRevoked reports whether key is revoked. Certificates are revoked if they
+// match a revoked serial or key ID of their CA, or if either the certified
+// key or the CA key is revoked.
+func (k *KRL) IsRevoked(key gossh.PublicKey) bool {
+	if k == nil || key == nil {
+		return false
+	}
+
+	cert, ok := key.(*gossh.Certificate)
+	if !ok {
+		return k.isKeyRevoked(key)
+	}
+
+	if k.isKeyRevoked(cert.Key) || k.isKeyRevoked(cert.SignatureKey) {
+		return true
+	}
+
+	ca := string(cert.SignatureKey.Marshal())
+	for _, c := range k.certs {
+		if c.ca != "" && c.ca != ca {
+			continue
+		}
+		if _, ok := c.keyIDs[cert.KeyId]; ok {
+			return true
+		}
+		for _, s := range c.serials {
+			if cert.Serial >= s.min && cert.Serial <= s.max {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (k *KRL) isKeyRevoked(key gossh.PublicKey) bool {
+	blob := key.Marshal()
+	if _, ok := k.keys[string(blob)]; ok {
+		return true
+	}
+
+	sum1 := sha1.Sum(blob) //nolint: gosec
+	if _, ok := k.sha1[string(sum1[:])]; ok {
+		return true
+	}
+
+	sum256 := sha256.Sum256(blob)
+	_, ok := k.sha256[string(sum256[:])]
+	return ok
+}
+
+// krlReader reads SSH wire format values. The first error is kept, and
+// further reads return zero values.
+type krlReader struct {
+	data []byte
+	err  error
+}
+
+func (r *krlReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidKRL)
+		return nil
+	}
+
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *krlReader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *krlReader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *krlReader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *krlReader) string() []byte {
+	n := r.uint32()
+	if r.err != nil {
+		return nil
+	}
+	return r.next(int(n))
+}
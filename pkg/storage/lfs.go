@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+// NewLFSStorage returns the storage holding the LFS objects of the repository
+// repoID, as configured in cfg.
+func NewLFSStorage(cfg *config.Config, repoID int64) (Storage, error) {
+	id := strconv.FormatInt(repoID, 10)
+	switch cfg.LFS.Storage {
+	case "", "local":
+		return NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", id)), nil
+	case "s3":
+		return NewS3Storage(S3Options{
+			Endpoint:        cfg.LFS.S3.Endpoint,
+			Bucket:          cfg.LFS.S3.Bucket,
+			Region:          cfg.LFS.S3.Region,
+			AccessKeyID:     cfg.LFS.S3.AccessKeyID,
+			SecretAccessKey: cfg.LFS.S3.SecretAccessKey,
+			UsePathStyle:    cfg.LFS.S3.UsePathStyle,
+			// Use the same layout as local storage.
+			Prefix: path.Join("lfs", id),
+		})
+	default:
+		return nil, fmt.Errorf("unknown lfs storage %q", cfg.LFS.Storage)
+	}
+}
@@ -0,0 +1,544 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Options are the options used to create an S3Storage.
+type S3Options struct {
+	// Endpoint is the URL of the S3 compatible server, e.g.
+	// "https://s3.amazonaws.com" or "http://localhost:9000".
+	Endpoint string
+
+	// Bucket is the name of the bucket.
+	Bucket string
+
+	// Region is the region of the bucket. Defaults to "us-east-1".
+	Region string
+
+	// AccessKeyID is the access key ID used to sign requests.
+	AccessKeyID string
+
+	// SecretAccessKey is the secret access key used to sign requests.
+	SecretAccessKey string
+
+	// UsePathStyle makes requests to "endpoint/bucket/key" instead of
+	// "bucket.endpoint/key". Most S3 compatible servers such as MinIO require
+	// path style requests.
+	UsePathStyle bool
+
+	// Prefix is prepended to all object names.
+	Prefix string
+
+	// Client is the HTTP client used to make requests. Defaults to
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// S3Storage is a storage implementation that stores objects in an S3
+// compatible object storage.
+type S3Storage struct {
+	opts     S3Options
+	endpoint *url.URL
+	now      func() time.Time
+}
+
+var _ Storage = (*S3Storage)(nil)
+
+// NewS3Storage creates a new S3Storage.
+func NewS3Storage(opts S3Options) (*S3Storage, error) {
+	if opts.Bucket == "" {
+		return nil, errors.New("s3: missing bucket")
+	}
+	if opts.Endpoint == "" {
+		return nil, errors.New("s3: missing endpoint")
+	}
+
+	u, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("s3: invalid endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("s3: invalid endpoint scheme %q", u.Scheme)
+	}
+
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	opts.Prefix = strings.Trim(opts.Prefix, "/")
+
+	return &S3Storage{
+		opts:     opts,
+		endpoint: u,
+		now:      time.Now,
+	}, nil
+}
+
+// Delete implements Storage.
+func (s *S3Storage) Delete(name string) error {
+	res, err := s.do(context.Background(), http.MethodDelete, name, nil, nil, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() //nolint: errcheck
+
+	// S3 doesn't report missing objects on delete, so neither do we.
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
+		return s.responseError("delete", name, res)
+	}
+
+	return nil
+}
+
+// Open implements Storage.
+func (s *S3Storage) Open(name string) (Object, error) {
+	info, err := s.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Object{s: s, name: name, info: info}, nil
+}
+
+// Stat implements Storage.
+func (s *S3Storage) Stat(name string) (fs.FileInfo, error) {
+	info, err := s.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Put implements Storage.
+//
+// S3 needs to know the length of an object before it's uploaded, so the
+// content of r is buffered in a temporary file first.
+func (s *S3Storage) Put(name string, r io.Reader) (int64, error) {
+	f, err := os.CreateTemp("", "soft-serve-s3-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name()) //nolint: errcheck
+	defer f.Close()           //nolint: errcheck
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), r)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	// The temporary file is closed by the deferred call above.
+	var body io.ReadCloser = http.NoBody
+	if n > 0 {
+		body = io.NopCloser(f)
+	}
+	res, err := s.do(context.Background(), http.MethodPut, name, body, func(req *http.Request) {
+		req.ContentLength = n
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}, hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close() //nolint: errcheck
+
+	if res.StatusCode != http.StatusOK {
+		return 0, s.responseError("put", name, res)
+	}
+
+	return n, nil
+}
+
+// Exists implements Storage.
+func (s *S3Storage) Exists(name string) (bool, error) {
+	_, err := s.stat("stat", name)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Rename implements Storage.
+//
+// S3 has no rename operation, the object is copied to its new name and the
+// old object is deleted.
+func (s *S3Storage) Rename(oldName, newName string) error {
+	source := "/" + s.opts.Bucket + "/" + escapePath(s.key(oldName))
+	res, err := s.do(context.Background(), http.MethodPut, newName, nil, func(req *http.Request) {
+		req.Header.Set("x-amz-copy-source", source)
+	}, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() //nolint: errcheck
+
+	if res.StatusCode != http.StatusOK {
+		return s.responseError("rename", oldName, res)
+	}
+
+	// A copy can fail after S3 has responded with 200 OK, in which case the
+	// error is reported in the response body.
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if err := decodeS3Error(body); err != nil {
+		return &fs.PathError{Op: "rename", Path: oldName, Err: err}
+	}
+
+	return s.Delete(oldName)
+}
+
+func (s *S3Storage) stat(op, name string) (*s3FileInfo, error) {
+	res, err := s.do(context.Background(), http.MethodHead, name, nil, nil, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close() //nolint: errcheck
+
+	if res.StatusCode != http.StatusOK {
+		return nil, s.responseError(op, name, res)
+	}
+
+	info := &s3FileInfo{name: path.Base(name), size: res.ContentLength}
+	if lm := res.Header.Get("Last-Modified"); lm != "" {
+		info.modTime, _ = http.ParseTime(lm)
+	}
+
+	return info, nil
+}
+
+// key returns the object key of name.
+func (s *S3Storage) key(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if s.opts.Prefix == "" {
+		return name
+	}
+	return s.opts.Prefix + "/" + name
+}
+
+// objectURL returns the URL of the object name.
+func (s *S3Storage) objectURL(name string) *url.URL {
+	u := *s.endpoint
+	key := s.key(name)
+	basePath := strings.TrimSuffix(u.Path, "/")
+	if s.opts.UsePathStyle {
+		u.Path = basePath + "/" + s.opts.Bucket + "/" + key
+	} else {
+		u.Host = s.opts.Bucket + "." + u.Host
+		u.Path = basePath + "/" + key
+	}
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
+
+// do makes a signed request for the object name. payloadHash is the
+// hex-encoded SHA-256 hash of body.
+func (s *S3Storage) do(ctx context.Context, method, name string, body io.ReadCloser, fn func(*http.Request), payloadHash string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(name).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if fn != nil {
+		fn(req)
+	}
+
+	s.sign(req, payloadHash, s.now())
+
+	return s.opts.Client.Do(req)
+}
+
+// responseError returns an error describing the failed response res.
+func (s *S3Storage) responseError(op, name string, res *http.Response) error {
+	if res.StatusCode == http.StatusNotFound {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+	err := decodeS3Error(body)
+	if err == nil {
+		err = fmt.Errorf("s3: unexpected status %s", res.Status)
+	}
+
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// S3Error is an error returned by an S3 compatible server.
+type S3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// Error implements error.
+func (e *S3Error) Error() string {
+	return fmt.Sprintf("s3: %s: %s", e.Code, e.Message)
+}
+
+// decodeS3Error returns the error described in body, if any.
+func decodeS3Error(body []byte) error {
+	var e struct {
+		XMLName xml.Name
+		S3Error
+	}
+	if err := xml.Unmarshal(body, &e); err != nil || e.XMLName.Local != "Error" {
+		return nil
+	}
+	return &e.S3Error
+}
+
+const (
+	// emptyPayloadHash is the SHA-256 hash of an empty payload.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	amzDateFormat = "20060102T150405Z"
+)
+
+// sign signs req using AWS Signature Version 4.
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *S3Storage) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if s.opts.AccessKeyID == "" {
+		// Anonymous request.
+		return
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if k == "content-type" || k == "range" || strings.HasPrefix(k, "x-amz-") {
+			headers[k] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.opts.Region + "/s3/aws4_request"
+	crh := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crh[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretAccessKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data)) //nolint: errcheck
+	return h.Sum(nil)
+}
+
+// canonicalQuery returns the SigV4 canonical form of a query string.
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vals := append([]string(nil), q[k]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// escapePath URI-encodes each segment of p.
+func escapePath(p string) string {
+	segs := strings.Split(p, "/")
+	for i, seg := range segs {
+		segs[i] = uriEncode(seg)
+	}
+	return strings.Join(segs, "/")
+}
+
+// uriEncode encodes s the way SigV4 expects; everything but the unreserved
+// characters of RFC 3986 is percent-encoded.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Object is an Object stored in S3. Content is fetched lazily with ranged
+// GET requests starting at the current offset.
+type s3Object struct {
+	s      *S3Storage
+	name   string
+	info   *s3FileInfo
+	offset int64
+	body   io.ReadCloser
+	closed bool
+}
+
+var _ Object = (*s3Object)(nil)
+
+// Name implements Object.
+func (o *s3Object) Name() string {
+	return o.name
+}
+
+// Stat implements fs.File.
+func (o *s3Object) Stat() (fs.FileInfo, error) {
+	return o.info, nil
+}
+
+// Read implements io.Reader.
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.closed {
+		return 0, fs.ErrClosed
+	}
+	if o.offset >= o.info.size {
+		return 0, io.EOF
+	}
+
+	if o.body == nil {
+		res, err := o.s.do(context.Background(), http.MethodGet, o.name, nil, func(req *http.Request) {
+			req.Header.Set("Range", "bytes="+strconv.FormatInt(o.offset, 10)+"-")
+		}, emptyPayloadHash)
+		if err != nil {
+			return 0, err
+		}
+		if res.StatusCode != http.StatusPartialContent && res.StatusCode != http.StatusOK {
+			defer res.Body.Close() //nolint: errcheck
+			return 0, o.s.responseError("read", o.name, res)
+		}
+		if res.StatusCode == http.StatusOK && o.offset > 0 {
+			// The server ignored the range, skip to the offset.
+			if _, err := io.CopyN(io.Discard, res.Body, o.offset); err != nil {
+				res.Body.Close() //nolint: errcheck
+				return 0, err
+			}
+		}
+		o.body = res.Body
+	}
+
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	if errors.Is(err, io.EOF) && o.offset < o.info.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker.
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	if o.closed {
+		return 0, fs.ErrClosed
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.info.size
+	default:
+		return 0, errors.New("s3: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("s3: negative position")
+	}
+
+	if offset != o.offset && o.body != nil {
+		o.body.Close() //nolint: errcheck
+		o.body = nil
+	}
+	o.offset = offset
+
+	return offset, nil
+}
+
+// Close implements io.Closer.
+func (o *s3Object) Close() error {
+	if o.closed {
+		return fs.ErrClosed
+	}
+	o.closed = true
+	if o.body != nil {
+		return o.body.Close()
+	}
+	return nil
+}
+
+// s3FileInfo implements fs.FileInfo for S3 objects.
+type s3FileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+var _ fs.FileInfo = (*s3FileInfo)(nil)
+
+func (i *s3FileInfo) Name() string       { return i.name }
+func (i *s3FileInfo) Size() int64        { return i.size }
+func (i *s3FileInfo) Mode() fs.FileMode  { return 0o444 }
+func (i *s3FileInfo) ModTime() time.Time { return i.modTime }
+func (i *s3FileInfo) IsDir() bool        { return false }
+func (i *s3FileInfo) Sys() any           { return nil }
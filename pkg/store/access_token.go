@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
 )
@@ -13,7 +14,7 @@ type AccessTokenStore interface {
 	GetAccessToken(ctx context.Context, h db.Handler, id int64) (models.AccessToken, error)
 	GetAccessTokenByToken(ctx context.Context, h db.Handler, token string) (models.AccessToken, error)
 	GetAccessTokensByUserID(ctx context.Context, h db.Handler, userID int64) ([]models.AccessToken, error)
-	CreateAccessToken(ctx context.Context, h db.Handler, name string, userID int64, token string, expiresAt time.Time) (models.AccessToken, error)
+	CreateAccessToken(ctx context.Context, h db.Handler, name string, userID int64, token string, expiresAt time.Time, scope access.AccessLevel) (models.AccessToken, error)
 	DeleteAccessToken(ctx context.Context, h db.Handler, id int64) error
 	DeleteAccessTokenForUser(ctx context.Context, h db.Handler, userID int64, id int64) error
 }
@@ -0,0 +1,14 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// AuditStore is an interface for managing the audit log.
+type AuditStore interface {
+	CreateAuditEntry(ctx context.Context, h db.Handler, entry models.AuditEntry) error
+	GetAuditEntries(ctx context.Context, h db.Handler, username string, repo string, limit int) ([]models.AuditEntry, error)
+}
@@ -0,0 +1,17 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// BranchProtectionStore is an interface for managing the branch protection
+// rules of repositories.
+type BranchProtectionStore interface {
+	AddBranchProtectionByRepo(ctx context.Context, h db.Handler, repo string, pattern string, level access.AccessLevel, allowForce bool) error
+	RemoveBranchProtectionByRepo(ctx context.Context, h db.Handler, repo string, pattern string) error
+	ListBranchProtectionsByRepo(ctx context.Context, h db.Handler, repo string) ([]models.BranchProtection, error)
+}
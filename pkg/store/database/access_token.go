@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
 	"github.com/charmbracelet/soft-serve/pkg/store"
@@ -14,21 +15,21 @@ type accessTokenStore struct{}
 var _ store.AccessTokenStore = (*accessTokenStore)(nil)
 
 // CreateAccessToken implements store.AccessTokenStore.
-func (s *accessTokenStore) CreateAccessToken(ctx context.Context, h db.Handler, name string, userID int64, token string, expiresAt time.Time) (models.AccessToken, error) {
-	queryWithoutExpires := `INSERT INTO access_tokens (name, user_id, token, created_at, updated_at)
-	VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id`
-	queryWithExpires := `INSERT INTO access_tokens (name, user_id, token, expires_at, created_at, updated_at)
-	VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id`
+func (s *accessTokenStore) CreateAccessToken(ctx context.Context, h db.Handler, name string, userID int64, token string, expiresAt time.Time, scope access.AccessLevel) (models.AccessToken, error) {
+	queryWithoutExpires := `INSERT INTO access_tokens (name, user_id, token, scope, created_at, updated_at)
+	VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`
+	queryWithExpires := `INSERT INTO access_tokens (name, user_id, token, scope, expires_at, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`
 
 	query := queryWithoutExpires
-	values := []interface{}{name, userID, token}
+	values := []interface{}{name, userID, token, scope}
 	if !expiresAt.IsZero() {
 		query = queryWithExpires
 		values = append(values, expiresAt.UTC())
 	}
 
-	var id int64
-	if err := h.GetContext(ctx, &id, h.Rebind(query), values...); err != nil {
+	id, err := db.InsertReturningID(ctx, h, h.Rebind(query), values...)
+	if err != nil {
 		return models.AccessToken{}, err
 	}
 
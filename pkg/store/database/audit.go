@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type auditStore struct{}
+
+var _ store.AuditStore = (*auditStore)(nil)
+
+// CreateAuditEntry implements store.AuditStore.
+func (*auditStore) CreateAuditEntry(ctx context.Context, h db.Handler, entry models.AuditEntry) error {
+	query := h.Rebind(`INSERT INTO audit_log (username, repo, service, transport, access_level, remote_addr, allowed, details, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`)
+	_, err := h.ExecContext(ctx, query, entry.Username, entry.Repo, entry.Service, entry.Transport,
+		entry.AccessLevel, entry.RemoteAddr, entry.Allowed, entry.Details, entry.CreatedAt.UTC())
+	return db.WrapError(err)
+}
+
+// GetAuditEntries implements store.AuditStore. Entries are returned newest
+// first, an empty username or repo matches all entries.
+func (*auditStore) GetAuditEntries(ctx context.Context, h db.Handler, username string, repo string, limit int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	query := h.Rebind(`SELECT * FROM audit_log
+			WHERE (? = '' OR username = ?) AND (? = '' OR repo = ?)
+			ORDER BY id DESC
+			LIMIT ?;`)
+	err := h.SelectContext(ctx, &entries, query, username, username, repo, repo, limit)
+	return entries, db.WrapError(err)
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type branchProtectionStore struct{}
+
+var _ store.BranchProtectionStore = (*branchProtectionStore)(nil)
+
+// AddBranchProtectionByRepo implements store.BranchProtectionStore.
+func (*branchProtectionStore) AddBranchProtectionByRepo(ctx context.Context, tx db.Handler, repo string, pattern string, level access.AccessLevel, allowForce bool) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`INSERT INTO branch_protections (repo_id, pattern, access_level, allow_force, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?,
+				?,
+				?,
+				CURRENT_TIMESTAMP
+			);`)
+	_, err := tx.ExecContext(ctx, query, repo, pattern, level, allowForce)
+	return err
+}
+
+// RemoveBranchProtectionByRepo implements store.BranchProtectionStore.
+func (*branchProtectionStore) RemoveBranchProtectionByRepo(ctx context.Context, tx db.Handler, repo string, pattern string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		DELETE FROM branch_protections
+		WHERE
+			pattern = ? AND
+			repo_id = (SELECT id FROM repos WHERE name = ?);
+	`)
+	_, err := tx.ExecContext(ctx, query, pattern, repo)
+	return err
+}
+
+// ListBranchProtectionsByRepo implements store.BranchProtectionStore.
+func (*branchProtectionStore) ListBranchProtectionsByRepo(ctx context.Context, tx db.Handler, repo string) ([]models.BranchProtection, error) {
+	var m []models.BranchProtection
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			branch_protections.*
+		FROM
+			branch_protections
+		INNER JOIN repos ON repos.id = branch_protections.repo_id
+		WHERE
+			repos.name = ?
+		ORDER BY
+			branch_protections.pattern;
+	`)
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
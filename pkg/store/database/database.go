@@ -22,6 +22,20 @@ type datastore struct {
 	*lfsStore
 	*accessTokenStore
 	*webhookStore
+	*mirrorStore
+	*signerStore
+	*teamStore
+	*branchProtectionStore
+	*topicStore
+	*auditStore
+	*ipRuleStore
+	*repoStatsStore
+	*deployKeyStore
+	*pushEventStore
+	*hookEnvStore
+	*tagProtectionStore
+	*repoLanguagesStore
+	*repoForkStore
 }
 
 // New returns a new store.Store database.
@@ -35,12 +49,26 @@ func New(ctx context.Context, db *db.DB) store.Store {
 		db:     db,
 		logger: logger,
 
-		settingsStore:    &settingsStore{},
-		repoStore:        &repoStore{},
-		userStore:        &userStore{},
-		collabStore:      &collabStore{},
-		lfsStore:         &lfsStore{},
-		accessTokenStore: &accessTokenStore{},
+		settingsStore:         &settingsStore{},
+		repoStore:             &repoStore{},
+		userStore:             &userStore{},
+		collabStore:           &collabStore{},
+		lfsStore:              &lfsStore{},
+		accessTokenStore:      &accessTokenStore{},
+		mirrorStore:           &mirrorStore{},
+		signerStore:           &signerStore{},
+		teamStore:             &teamStore{},
+		branchProtectionStore: &branchProtectionStore{},
+		topicStore:            &topicStore{},
+		auditStore:            &auditStore{},
+		ipRuleStore:           &ipRuleStore{},
+		repoStatsStore:        &repoStatsStore{},
+		deployKeyStore:        &deployKeyStore{},
+		pushEventStore:        &pushEventStore{},
+		hookEnvStore:          &hookEnvStore{},
+		tagProtectionStore:    &tagProtectionStore{},
+		repoLanguagesStore:    &repoLanguagesStore{},
+		repoForkStore:         &repoForkStore{},
 	}
 
 	return s
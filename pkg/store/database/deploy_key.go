@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type deployKeyStore struct{}
+
+var _ store.DeployKeyStore = (*deployKeyStore)(nil)
+
+// AddDeployKeyByRepo implements store.DeployKeyStore.
+func (*deployKeyStore) AddDeployKeyByRepo(ctx context.Context, tx db.Handler, repo string, title string, publicKey string, level access.AccessLevel) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`INSERT INTO deploy_keys (repo_id, title, public_key, access_level, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?,
+				?,
+				?,
+				CURRENT_TIMESTAMP
+			);`)
+	_, err := tx.ExecContext(ctx, query, repo, title, publicKey, level)
+	return err
+}
+
+// RemoveDeployKeyByRepo implements store.DeployKeyStore.
+func (*deployKeyStore) RemoveDeployKeyByRepo(ctx context.Context, tx db.Handler, repo string, title string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		DELETE FROM deploy_keys
+		WHERE
+			title = ? AND
+			repo_id = (SELECT id FROM repos WHERE name = ?);
+	`)
+	_, err := tx.ExecContext(ctx, query, title, repo)
+	return err
+}
+
+// ListDeployKeysByRepo implements store.DeployKeyStore.
+func (*deployKeyStore) ListDeployKeysByRepo(ctx context.Context, tx db.Handler, repo string) ([]models.DeployKey, error) {
+	var m []models.DeployKey
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			deploy_keys.*
+		FROM
+			deploy_keys
+		INNER JOIN repos ON repos.id = deploy_keys.repo_id
+		WHERE
+			repos.name = ?
+		ORDER BY
+			deploy_keys.title;
+	`)
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
+
+// FindDeployKeyByPublicKey implements store.DeployKeyStore.
+func (*deployKeyStore) FindDeployKeyByPublicKey(ctx context.Context, tx db.Handler, publicKey string) (models.DeployKey, error) {
+	var m models.DeployKey
+	query := tx.Rebind(`SELECT * FROM deploy_keys WHERE public_key = ?;`)
+	err := tx.GetContext(ctx, &m, query, publicKey)
+	return m, err
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type hookEnvStore struct{}
+
+var _ store.HookEnvStore = (*hookEnvStore)(nil)
+
+// SetHookEnvByRepo implements store.HookEnvStore.
+func (*hookEnvStore) SetHookEnvByRepo(ctx context.Context, h db.Handler, repo string, name string, value string, secret bool) error {
+	repo = utils.SanitizeRepo(repo)
+	query := h.Rebind(`INSERT INTO repo_hook_env (repo_id, name, value, secret, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?,
+				?,
+				?,
+				CURRENT_TIMESTAMP
+			) ` +
+		db.OnConflictUpdate(h, []string{"repo_id", "name"}, "value", "secret", "updated_at"))
+	_, err := h.ExecContext(ctx, query, repo, name, value, secret)
+	return db.WrapError(err)
+}
+
+// RemoveHookEnvByRepo implements store.HookEnvStore.
+func (*hookEnvStore) RemoveHookEnvByRepo(ctx context.Context, h db.Handler, repo string, name string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := h.Rebind(`
+		DELETE FROM repo_hook_env
+		WHERE
+			name = ? AND
+			repo_id = (SELECT id FROM repos WHERE name = ?);
+	`)
+	_, err := h.ExecContext(ctx, query, name, repo)
+	return db.WrapError(err)
+}
+
+// ListHookEnvByRepo implements store.HookEnvStore.
+func (*hookEnvStore) ListHookEnvByRepo(ctx context.Context, h db.Handler, repo string) ([]models.RepoHookEnv, error) {
+	var m []models.RepoHookEnv
+	repo = utils.SanitizeRepo(repo)
+	query := h.Rebind(`
+		SELECT
+			repo_hook_env.*
+		FROM
+			repo_hook_env
+		INNER JOIN repos ON repos.id = repo_hook_env.repo_id
+		WHERE
+			repos.name = ?
+		ORDER BY
+			repo_hook_env.name;
+	`)
+	err := h.SelectContext(ctx, &m, query, repo)
+	return m, db.WrapError(err)
+}
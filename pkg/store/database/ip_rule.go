@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type ipRuleStore struct{}
+
+var _ store.IPRuleStore = (*ipRuleStore)(nil)
+
+// CreateIPRule implements store.IPRuleStore.
+func (*ipRuleStore) CreateIPRule(ctx context.Context, tx db.Handler, userID int64, cidr string, allow bool) error {
+	query := tx.Rebind(`INSERT INTO ip_rules (user_id, cidr, allow, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP);`)
+	_, err := tx.ExecContext(ctx, query, ipRuleUserID(userID), cidr, allow)
+	return err
+}
+
+// DeleteIPRule implements store.IPRuleStore.
+func (*ipRuleStore) DeleteIPRule(ctx context.Context, tx db.Handler, userID int64, cidr string) error {
+	if userID == 0 {
+		query := tx.Rebind(`DELETE FROM ip_rules WHERE user_id IS NULL AND cidr = ?;`)
+		_, err := tx.ExecContext(ctx, query, cidr)
+		return err
+	}
+
+	query := tx.Rebind(`DELETE FROM ip_rules WHERE user_id = ? AND cidr = ?;`)
+	_, err := tx.ExecContext(ctx, query, userID, cidr)
+	return err
+}
+
+// GetIPRules implements store.IPRuleStore.
+func (*ipRuleStore) GetIPRules(ctx context.Context, tx db.Handler, userID int64) ([]models.IPRule, error) {
+	var m []models.IPRule
+	if userID == 0 {
+		query := tx.Rebind(`SELECT * FROM ip_rules WHERE user_id IS NULL ORDER BY id;`)
+		err := tx.SelectContext(ctx, &m, query)
+		return m, err
+	}
+
+	query := tx.Rebind(`SELECT * FROM ip_rules WHERE user_id = ? ORDER BY id;`)
+	err := tx.SelectContext(ctx, &m, query, userID)
+	return m, err
+}
+
+// ipRuleUserID returns the user_id column of a rule, NULL for server-wide
+// rules.
+func ipRuleUserID(userID int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: userID, Valid: userID != 0}
+}
@@ -185,6 +185,20 @@ func (*lfsStore) GetLFSObjects(ctx context.Context, tx db.Handler, repoID int64)
 	return objs, db.WrapError(err)
 }
 
+// GetLFSObjectsAfterID implements store.LFSStore.
+func (*lfsStore) GetLFSObjectsAfterID(ctx context.Context, tx db.Handler, repoID int64, afterID int64, limit int) ([]models.LFSObject, error) {
+	var objs []models.LFSObject
+	query := tx.Rebind(`
+		SELECT *
+		FROM lfs_objects
+		WHERE repo_id = ? AND id > ?
+		ORDER BY id ASC
+		LIMIT ?;
+	`)
+	err := tx.SelectContext(ctx, &objs, query, repoID, afterID, limit)
+	return objs, db.WrapError(err)
+}
+
 // GetLFSObjectsByName implements store.LFSStore.
 func (*lfsStore) GetLFSObjectsByName(ctx context.Context, tx db.Handler, name string) ([]models.LFSObject, error) {
 	var objs []models.LFSObject
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type mirrorStore struct{}
+
+var _ store.MirrorStore = (*mirrorStore)(nil)
+
+// GetMirrorConfigByRepoID implements store.MirrorStore.
+func (*mirrorStore) GetMirrorConfigByRepoID(ctx context.Context, h db.Handler, repoID int64) (models.MirrorConfig, error) {
+	var m models.MirrorConfig
+	query := h.Rebind("SELECT * FROM repo_mirrors WHERE repo_id = ?;")
+	err := h.GetContext(ctx, &m, query, repoID)
+	return m, db.WrapError(err)
+}
+
+// SetMirrorConfigByRepoID implements store.MirrorStore.
+func (*mirrorStore) SetMirrorConfigByRepoID(ctx context.Context, h db.Handler, repoID int64, remoteURL string, syncInterval int64, username string, password string, sshKey string) error {
+	query := h.Rebind(`INSERT INTO repo_mirrors (repo_id, remote_url, sync_interval, username, password, ssh_key, updated_at)
+			VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), CURRENT_TIMESTAMP) ` +
+		db.OnConflictUpdate(h, []string{"repo_id"}, "remote_url", "sync_interval", "username", "password", "ssh_key", "updated_at"))
+	_, err := h.ExecContext(ctx, query, repoID, remoteURL, syncInterval, username, password, sshKey)
+	return db.WrapError(err)
+}
+
+// SetMirrorSyncedByRepoID implements store.MirrorStore.
+func (*mirrorStore) SetMirrorSyncedByRepoID(ctx context.Context, h db.Handler, repoID int64, syncErr error) error {
+	var lastErr *string
+	if syncErr != nil {
+		s := syncErr.Error()
+		lastErr = &s
+	}
+	query := h.Rebind(`UPDATE repo_mirrors SET last_synced_at = CURRENT_TIMESTAMP, last_error = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE repo_id = ?;`)
+	_, err := h.ExecContext(ctx, query, lastErr, repoID)
+	return db.WrapError(err)
+}
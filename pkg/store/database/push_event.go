@@ -0,0 +1,33 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type pushEventStore struct{}
+
+var _ store.PushEventStore = (*pushEventStore)(nil)
+
+// CreatePushEvent implements store.PushEventStore.
+func (*pushEventStore) CreatePushEvent(ctx context.Context, h db.Handler, repoID int64, username string, refs string, commits int64) error {
+	query := h.Rebind(`INSERT INTO push_events (repo_id, username, refs, commits)
+			VALUES (?, ?, ?, ?);`)
+	_, err := h.ExecContext(ctx, query, repoID, username, refs, commits)
+	return db.WrapError(err)
+}
+
+// GetPushEventsByRepoID implements store.PushEventStore. Events are returned
+// newest first.
+func (*pushEventStore) GetPushEventsByRepoID(ctx context.Context, h db.Handler, repoID int64, limit int) ([]models.PushEvent, error) {
+	var events []models.PushEvent
+	query := h.Rebind(`SELECT * FROM push_events
+			WHERE repo_id = ?
+			ORDER BY id DESC
+			LIMIT ?;`)
+	err := h.SelectContext(ctx, &events, query, repoID, limit)
+	return events, db.WrapError(err)
+}
@@ -83,6 +83,15 @@ func (*repoStore) GetRepoIsHiddenByName(ctx context.Context, tx db.Handler, name
 	return isHidden, db.WrapError(err)
 }
 
+// GetRepoIsArchivedByName implements store.RepositoryStore.
+func (*repoStore) GetRepoIsArchivedByName(ctx context.Context, tx db.Handler, name string) (bool, error) {
+	var isArchived bool
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("SELECT archived FROM repos WHERE name = ?;")
+	err := tx.GetContext(ctx, &isArchived, query, name)
+	return isArchived, db.WrapError(err)
+}
+
 // GetRepoIsMirrorByName implements store.RepositoryStore.
 func (*repoStore) GetRepoIsMirrorByName(ctx context.Context, tx db.Handler, name string) (bool, error) {
 	var isMirror bool
@@ -101,6 +110,15 @@ func (*repoStore) GetRepoIsPrivateByName(ctx context.Context, tx db.Handler, nam
 	return isPrivate, db.WrapError(err)
 }
 
+// GetRepoIsTemplateByName implements store.RepositoryStore.
+func (*repoStore) GetRepoIsTemplateByName(ctx context.Context, tx db.Handler, name string) (bool, error) {
+	var isTemplate bool
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("SELECT template FROM repos WHERE name = ?;")
+	err := tx.GetContext(ctx, &isTemplate, query, name)
+	return isTemplate, db.WrapError(err)
+}
+
 // GetRepoProjectNameByName implements store.RepositoryStore.
 func (*repoStore) GetRepoProjectNameByName(ctx context.Context, tx db.Handler, name string) (string, error) {
 	var pname string
@@ -118,6 +136,14 @@ func (*repoStore) SetRepoDescriptionByName(ctx context.Context, tx db.Handler, n
 	return db.WrapError(err)
 }
 
+// SetRepoIsArchivedByName implements store.RepositoryStore.
+func (*repoStore) SetRepoIsArchivedByName(ctx context.Context, tx db.Handler, name string, isArchived bool) error {
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("UPDATE repos SET archived = ? WHERE name = ?;")
+	_, err := tx.ExecContext(ctx, query, isArchived, name)
+	return db.WrapError(err)
+}
+
 // SetRepoIsHiddenByName implements store.RepositoryStore.
 func (*repoStore) SetRepoIsHiddenByName(ctx context.Context, tx db.Handler, name string, isHidden bool) error {
 	name = utils.SanitizeRepo(name)
@@ -134,6 +160,14 @@ func (*repoStore) SetRepoIsPrivateByName(ctx context.Context, tx db.Handler, nam
 	return db.WrapError(err)
 }
 
+// SetRepoIsTemplateByName implements store.RepositoryStore.
+func (*repoStore) SetRepoIsTemplateByName(ctx context.Context, tx db.Handler, name string, isTemplate bool) error {
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("UPDATE repos SET template = ? WHERE name = ?;")
+	_, err := tx.ExecContext(ctx, query, isTemplate, name)
+	return db.WrapError(err)
+}
+
 // SetRepoNameByName implements store.RepositoryStore.
 func (*repoStore) SetRepoNameByName(ctx context.Context, tx db.Handler, name string, newName string) error {
 	name = utils.SanitizeRepo(name)
@@ -143,6 +177,14 @@ func (*repoStore) SetRepoNameByName(ctx context.Context, tx db.Handler, name str
 	return db.WrapError(err)
 }
 
+// SetRepoUserIDByName implements store.RepositoryStore.
+func (*repoStore) SetRepoUserIDByName(ctx context.Context, tx db.Handler, name string, userID int64) error {
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind("UPDATE repos SET user_id = ? WHERE name = ?;")
+	_, err := tx.ExecContext(ctx, query, userID, name)
+	return db.WrapError(err)
+}
+
 // SetRepoProjectNameByName implements store.RepositoryStore.
 func (*repoStore) SetRepoProjectNameByName(ctx context.Context, tx db.Handler, name string, projectName string) error {
 	name = utils.SanitizeRepo(name)
@@ -150,3 +192,33 @@ func (*repoStore) SetRepoProjectNameByName(ctx context.Context, tx db.Handler, n
 	_, err := tx.ExecContext(ctx, query, projectName, name)
 	return db.WrapError(err)
 }
+
+// GetRepoSettingByName implements store.RepositoryStore.
+func (*repoStore) GetRepoSettingByName(ctx context.Context, tx db.Handler, name string, key string) (string, error) {
+	var value string
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind(`SELECT repo_settings.value FROM repo_settings
+			INNER JOIN repos ON repos.id = repo_settings.repo_id
+			WHERE repos.name = ? AND repo_settings."key" = ?;`)
+	err := tx.GetContext(ctx, &value, query, name, key)
+	return value, db.WrapError(err)
+}
+
+// SetRepoSettingByName implements store.RepositoryStore.
+func (*repoStore) SetRepoSettingByName(ctx context.Context, tx db.Handler, name string, key string, value string) error {
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind(`INSERT INTO repo_settings (repo_id, "key", value, updated_at)
+			VALUES ((SELECT id FROM repos WHERE name = ?), ?, ?, CURRENT_TIMESTAMP) ` +
+		db.OnConflictUpdate(tx, []string{"repo_id", `"key"`}, "value", "updated_at"))
+	_, err := tx.ExecContext(ctx, query, name, key, value)
+	return db.WrapError(err)
+}
+
+// DeleteRepoSettingByName implements store.RepositoryStore.
+func (*repoStore) DeleteRepoSettingByName(ctx context.Context, tx db.Handler, name string, key string) error {
+	name = utils.SanitizeRepo(name)
+	query := tx.Rebind(`DELETE FROM repo_settings
+			WHERE "key" = ? AND repo_id = (SELECT id FROM repos WHERE name = ?);`)
+	_, err := tx.ExecContext(ctx, query, key, name)
+	return db.WrapError(err)
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type repoForkStore struct{}
+
+var _ store.RepoForkStore = (*repoForkStore)(nil)
+
+// CreateRepoFork implements store.RepoForkStore.
+func (*repoForkStore) CreateRepoFork(ctx context.Context, h db.Handler, repoID int64, parentID int64) error {
+	query := h.Rebind("INSERT INTO repo_forks (repo_id, parent_id) VALUES (?, ?);")
+	_, err := h.ExecContext(ctx, query, repoID, parentID)
+	return db.WrapError(err)
+}
+
+// GetRepoForkParent implements store.RepoForkStore.
+func (*repoForkStore) GetRepoForkParent(ctx context.Context, h db.Handler, repoID int64) (models.Repo, error) {
+	var m models.Repo
+	query := h.Rebind(`SELECT repos.* FROM repos
+			INNER JOIN repo_forks ON repo_forks.parent_id = repos.id
+			WHERE repo_forks.repo_id = ?;`)
+	err := h.GetContext(ctx, &m, query, repoID)
+	return m, db.WrapError(err)
+}
+
+// GetRepoForks implements store.RepoForkStore.
+func (*repoForkStore) GetRepoForks(ctx context.Context, h db.Handler, parentID int64) ([]models.Repo, error) {
+	var repos []models.Repo
+	query := h.Rebind(`SELECT repos.* FROM repos
+			INNER JOIN repo_forks ON repo_forks.repo_id = repos.id
+			WHERE repo_forks.parent_id = ?
+			ORDER BY repos.name;`)
+	err := h.SelectContext(ctx, &repos, query, parentID)
+	return repos, db.WrapError(err)
+}
+
+// DeleteRepoFork implements store.RepoForkStore.
+func (*repoForkStore) DeleteRepoFork(ctx context.Context, h db.Handler, repoID int64) error {
+	query := h.Rebind("DELETE FROM repo_forks WHERE repo_id = ?;")
+	_, err := h.ExecContext(ctx, query, repoID)
+	return db.WrapError(err)
+}
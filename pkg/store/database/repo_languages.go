@@ -0,0 +1,30 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type repoLanguagesStore struct{}
+
+var _ store.RepoLanguagesStore = (*repoLanguagesStore)(nil)
+
+// GetRepoLanguagesByRepoID implements store.RepoLanguagesStore.
+func (*repoLanguagesStore) GetRepoLanguagesByRepoID(ctx context.Context, h db.Handler, repoID int64) (models.RepoLanguages, error) {
+	var m models.RepoLanguages
+	query := h.Rebind("SELECT * FROM repo_languages WHERE repo_id = ?;")
+	err := h.GetContext(ctx, &m, query, repoID)
+	return m, db.WrapError(err)
+}
+
+// SetRepoLanguagesByRepoID implements store.RepoLanguagesStore.
+func (*repoLanguagesStore) SetRepoLanguagesByRepoID(ctx context.Context, h db.Handler, repoID int64, commitID string, languages string) error {
+	query := h.Rebind(`INSERT INTO repo_languages (repo_id, commit_id, languages, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP) ` +
+		db.OnConflictUpdate(h, []string{"repo_id"}, "commit_id", "languages", "updated_at"))
+	_, err := h.ExecContext(ctx, query, repoID, commitID, languages)
+	return db.WrapError(err)
+}
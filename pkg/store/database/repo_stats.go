@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type repoStatsStore struct{}
+
+var _ store.RepoStatsStore = (*repoStatsStore)(nil)
+
+// GetRepoStatsByRepoID implements store.RepoStatsStore.
+func (*repoStatsStore) GetRepoStatsByRepoID(ctx context.Context, h db.Handler, repoID int64) (models.RepoStats, error) {
+	var m models.RepoStats
+	query := h.Rebind("SELECT * FROM repo_stats WHERE repo_id = ?;")
+	err := h.GetContext(ctx, &m, query, repoID)
+	return m, db.WrapError(err)
+}
+
+// SetRepoStatsByRepoID implements store.RepoStatsStore.
+func (*repoStatsStore) SetRepoStatsByRepoID(ctx context.Context, h db.Handler, repoID int64, size int64, commits int64, defaultBranch string) error {
+	query := h.Rebind(`INSERT INTO repo_stats (repo_id, size, commits, default_branch, updated_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP) ` +
+		db.OnConflictUpdate(h, []string{"repo_id"}, "size", "commits", "default_branch", "updated_at"))
+	_, err := h.ExecContext(ctx, query, repoID, size, commits, defaultBranch)
+	return db.WrapError(err)
+}
+
+// SetRepoPushedByRepoID implements store.RepoStatsStore.
+func (*repoStatsStore) SetRepoPushedByRepoID(ctx context.Context, h db.Handler, repoID int64) error {
+	query := h.Rebind(`INSERT INTO repo_stats (repo_id, last_pushed_at, updated_at)
+			VALUES (?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) ` +
+		db.OnConflictUpdate(h, []string{"repo_id"}, "last_pushed_at", "updated_at"))
+	_, err := h.ExecContext(ctx, query, repoID)
+	return db.WrapError(err)
+}
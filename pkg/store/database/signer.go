@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type signerStore struct{}
+
+var _ store.SignerStore = (*signerStore)(nil)
+
+// AddSignerByRepo implements store.SignerStore.
+func (*signerStore) AddSignerByRepo(ctx context.Context, tx db.Handler, repo string, principal string, publicKey string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`INSERT INTO repo_signers (repo_id, principal, public_key, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?,
+				?,
+				CURRENT_TIMESTAMP
+			);`)
+	_, err := tx.ExecContext(ctx, query, repo, principal, publicKey)
+	return err
+}
+
+// RemoveSignerByRepo implements store.SignerStore.
+func (*signerStore) RemoveSignerByRepo(ctx context.Context, tx db.Handler, repo string, principal string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		DELETE FROM repo_signers
+		WHERE
+			principal = ? AND
+			repo_id = (SELECT id FROM repos WHERE name = ?);
+	`)
+	_, err := tx.ExecContext(ctx, query, principal, repo)
+	return err
+}
+
+// ListSignersByRepo implements store.SignerStore.
+func (*signerStore) ListSignersByRepo(ctx context.Context, tx db.Handler, repo string) ([]models.RepoSigner, error) {
+	var m []models.RepoSigner
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			repo_signers.*
+		FROM
+			repo_signers
+		INNER JOIN repos ON repos.id = repo_signers.repo_id
+		WHERE
+			repos.name = ?
+		ORDER BY
+			repo_signers.principal;
+	`)
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type tagProtectionStore struct{}
+
+var _ store.TagProtectionStore = (*tagProtectionStore)(nil)
+
+// AddTagProtectionByRepo implements store.TagProtectionStore.
+func (*tagProtectionStore) AddTagProtectionByRepo(ctx context.Context, tx db.Handler, repo string, pattern string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`INSERT INTO tag_protections (repo_id, pattern, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?,
+				CURRENT_TIMESTAMP
+			);`)
+	_, err := tx.ExecContext(ctx, query, repo, pattern)
+	return err
+}
+
+// RemoveTagProtectionByRepo implements store.TagProtectionStore.
+func (*tagProtectionStore) RemoveTagProtectionByRepo(ctx context.Context, tx db.Handler, repo string, pattern string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		DELETE FROM tag_protections
+		WHERE
+			pattern = ? AND
+			repo_id = (SELECT id FROM repos WHERE name = ?);
+	`)
+	_, err := tx.ExecContext(ctx, query, pattern, repo)
+	return err
+}
+
+// ListTagProtectionsByRepo implements store.TagProtectionStore.
+func (*tagProtectionStore) ListTagProtectionsByRepo(ctx context.Context, tx db.Handler, repo string) ([]models.TagProtection, error) {
+	var m []models.TagProtection
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			tag_protections.*
+		FROM
+			tag_protections
+		INNER JOIN repos ON repos.id = tag_protections.repo_id
+		WHERE
+			repos.name = ?
+		ORDER BY
+			tag_protections.pattern;
+	`)
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
@@ -0,0 +1,190 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type teamStore struct{}
+
+var _ store.TeamStore = (*teamStore)(nil)
+
+// CreateTeam implements store.TeamStore.
+func (*teamStore) CreateTeam(ctx context.Context, tx db.Handler, name string, level access.AccessLevel) error {
+	name = strings.ToLower(name)
+	if err := utils.ValidateTeamName(name); err != nil {
+		return err
+	}
+
+	query := tx.Rebind(`INSERT INTO teams (name, access_level, updated_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP);`)
+	_, err := tx.ExecContext(ctx, query, name, level)
+	return err
+}
+
+// DeleteTeamByName implements store.TeamStore.
+func (*teamStore) DeleteTeamByName(ctx context.Context, tx db.Handler, name string) error {
+	name = strings.ToLower(name)
+	query := tx.Rebind(`DELETE FROM teams WHERE name = ?;`)
+	_, err := tx.ExecContext(ctx, query, name)
+	return err
+}
+
+// GetTeamByName implements store.TeamStore.
+func (*teamStore) GetTeamByName(ctx context.Context, tx db.Handler, name string) (models.Team, error) {
+	var m models.Team
+	name = strings.ToLower(name)
+	query := tx.Rebind(`SELECT * FROM teams WHERE name = ?;`)
+	err := tx.GetContext(ctx, &m, query, name)
+	return m, err
+}
+
+// ListTeams implements store.TeamStore.
+func (*teamStore) ListTeams(ctx context.Context, tx db.Handler) ([]models.Team, error) {
+	var m []models.Team
+	query := tx.Rebind(`SELECT * FROM teams ORDER BY name;`)
+	err := tx.SelectContext(ctx, &m, query)
+	return m, err
+}
+
+// AddTeamMemberByUsername implements store.TeamStore.
+func (*teamStore) AddTeamMemberByUsername(ctx context.Context, tx db.Handler, team string, username string) error {
+	team = strings.ToLower(team)
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	query := tx.Rebind(`INSERT INTO team_members (team_id, user_id, updated_at)
+			VALUES (
+				(
+					SELECT id FROM teams WHERE name = ?
+				),
+				(
+					SELECT id FROM users WHERE username = ?
+				),
+				CURRENT_TIMESTAMP
+			);`)
+	_, err := tx.ExecContext(ctx, query, team, username)
+	return err
+}
+
+// RemoveTeamMemberByUsername implements store.TeamStore.
+func (*teamStore) RemoveTeamMemberByUsername(ctx context.Context, tx db.Handler, team string, username string) error {
+	team = strings.ToLower(team)
+	username = strings.ToLower(username)
+	query := tx.Rebind(`
+		DELETE FROM
+			team_members
+		WHERE
+			team_id = (
+				SELECT id FROM teams WHERE name = ?
+			) AND user_id = (
+				SELECT id FROM users WHERE username = ?
+			)
+	`)
+	_, err := tx.ExecContext(ctx, query, team, username)
+	return err
+}
+
+// ListTeamMembersAsUsers implements store.TeamStore.
+func (*teamStore) ListTeamMembersAsUsers(ctx context.Context, tx db.Handler, team string) ([]models.User, error) {
+	var m []models.User
+	team = strings.ToLower(team)
+	query := tx.Rebind(`
+		SELECT
+			users.*
+		FROM
+			users
+		INNER JOIN team_members ON team_members.user_id = users.id
+		INNER JOIN teams ON teams.id = team_members.team_id
+		WHERE
+			teams.name = ?
+		ORDER BY
+			users.username
+	`)
+	err := tx.SelectContext(ctx, &m, query, team)
+	return m, err
+}
+
+// AddTeamRepo implements store.TeamStore.
+func (*teamStore) AddTeamRepo(ctx context.Context, tx db.Handler, team string, repo string, level access.AccessLevel) error {
+	team = strings.ToLower(team)
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`INSERT INTO team_repos (team_id, repo_id, access_level, updated_at)
+			VALUES (
+				(
+					SELECT id FROM teams WHERE name = ?
+				),
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?,
+				CURRENT_TIMESTAMP
+			);`)
+	_, err := tx.ExecContext(ctx, query, team, repo, level)
+	return err
+}
+
+// RemoveTeamRepo implements store.TeamStore.
+func (*teamStore) RemoveTeamRepo(ctx context.Context, tx db.Handler, team string, repo string) error {
+	team = strings.ToLower(team)
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		DELETE FROM
+			team_repos
+		WHERE
+			team_id = (
+				SELECT id FROM teams WHERE name = ?
+			) AND repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			)
+	`)
+	_, err := tx.ExecContext(ctx, query, team, repo)
+	return err
+}
+
+// ListTeamReposByRepo implements store.TeamStore.
+func (*teamStore) ListTeamReposByRepo(ctx context.Context, tx db.Handler, repo string) ([]models.TeamRepo, error) {
+	var m []models.TeamRepo
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			team_repos.*
+		FROM
+			team_repos
+		INNER JOIN repos ON repos.id = team_repos.repo_id
+		WHERE
+			repos.name = ?
+	`)
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
+
+// GetTeamAccessByUsernameAndRepo implements store.TeamStore. It returns the
+// highest access level the teams of the user have on the repository, or -1 if
+// none of them has access.
+func (*teamStore) GetTeamAccessByUsernameAndRepo(ctx context.Context, tx db.Handler, username string, repo string) (access.AccessLevel, error) {
+	var level access.AccessLevel
+	username = strings.ToLower(username)
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			COALESCE(MAX(team_repos.access_level), -1)
+		FROM
+			team_repos
+		INNER JOIN team_members ON team_members.team_id = team_repos.team_id
+		INNER JOIN users ON users.id = team_members.user_id
+		INNER JOIN repos ON repos.id = team_repos.repo_id
+		WHERE
+			users.username = ? AND repos.name = ?
+	`)
+	err := tx.GetContext(ctx, &level, query, username, repo)
+	return level, err
+}
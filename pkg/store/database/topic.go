@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type topicStore struct{}
+
+var _ store.TopicStore = (*topicStore)(nil)
+
+// AddTopicByRepo implements store.TopicStore.
+func (*topicStore) AddTopicByRepo(ctx context.Context, tx db.Handler, repo string, topic string) error {
+	repo = utils.SanitizeRepo(repo)
+	topic = strings.ToLower(topic)
+	query := tx.Rebind(`INSERT INTO topics (name, updated_at)
+			VALUES (?, CURRENT_TIMESTAMP) ` +
+		db.OnConflictDoNothing(tx, "name"))
+	if _, err := tx.ExecContext(ctx, query, topic); err != nil {
+		return err
+	}
+
+	query = tx.Rebind(`INSERT INTO repo_topics (repo_id, topic_id, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				(
+					SELECT id FROM topics WHERE name = ?
+				),
+				CURRENT_TIMESTAMP
+			);`)
+	_, err := tx.ExecContext(ctx, query, repo, topic)
+	return err
+}
+
+// RemoveTopicByRepo implements store.TopicStore. Topics no repository uses
+// anymore are deleted.
+func (*topicStore) RemoveTopicByRepo(ctx context.Context, tx db.Handler, repo string, topic string) error {
+	repo = utils.SanitizeRepo(repo)
+	topic = strings.ToLower(topic)
+	query := tx.Rebind(`
+		DELETE FROM
+			repo_topics
+		WHERE
+			repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			) AND topic_id = (
+				SELECT id FROM topics WHERE name = ?
+			);
+	`)
+	if _, err := tx.ExecContext(ctx, query, repo, topic); err != nil {
+		return err
+	}
+
+	query = tx.Rebind(`
+		DELETE FROM
+			topics
+		WHERE
+			NOT EXISTS (
+				SELECT 1 FROM repo_topics WHERE repo_topics.topic_id = topics.id
+			);
+	`)
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+// ListTopicsByRepo implements store.TopicStore.
+func (*topicStore) ListTopicsByRepo(ctx context.Context, tx db.Handler, repo string) ([]models.Topic, error) {
+	var m []models.Topic
+	repo = utils.SanitizeRepo(repo)
+	query := tx.Rebind(`
+		SELECT
+			topics.*
+		FROM
+			topics
+		INNER JOIN repo_topics ON repo_topics.topic_id = topics.id
+		INNER JOIN repos ON repos.id = repo_topics.repo_id
+		WHERE
+			repos.name = ?
+		ORDER BY
+			topics.name;
+	`)
+	err := tx.SelectContext(ctx, &m, query, repo)
+	return m, err
+}
+
+// ListReposByTopic implements store.TopicStore.
+func (*topicStore) ListReposByTopic(ctx context.Context, tx db.Handler, topic string) ([]models.Repo, error) {
+	var m []models.Repo
+	topic = strings.ToLower(topic)
+	query := tx.Rebind(`
+		SELECT
+			repos.*
+		FROM
+			repos
+		INNER JOIN repo_topics ON repo_topics.repo_id = repos.id
+		INNER JOIN topics ON topics.id = repo_topics.topic_id
+		WHERE
+			topics.name = ?
+		ORDER BY
+			repos.name;
+	`)
+	err := tx.SelectContext(ctx, &m, query, topic)
+	return m, err
+}
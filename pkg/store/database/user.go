@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"strings"
 
 	"github.com/charmbracelet/soft-serve/pkg/db"
@@ -44,10 +45,10 @@ func (*userStore) CreateUser(ctx context.Context, tx db.Handler, username string
 	}
 
 	query := tx.Rebind(`INSERT INTO users (username, admin, updated_at)
-			VALUES (?, ?, CURRENT_TIMESTAMP) RETURNING id;`)
+			VALUES (?, ?, CURRENT_TIMESTAMP)`)
 
-	var userID int64
-	if err := tx.GetContext(ctx, &userID, query, username, isAdmin); err != nil {
+	userID, err := db.InsertReturningID(ctx, tx, query, username, isAdmin)
+	if err != nil {
 		return err
 	}
 
@@ -119,6 +120,15 @@ func (*userStore) FindUserByAccessToken(ctx context.Context, tx db.Handler, toke
 	return m, err
 }
 
+// FindUsersByEmail implements store.UserStore. Emails are compared
+// case-insensitively.
+func (*userStore) FindUsersByEmail(ctx context.Context, tx db.Handler, email string) ([]models.User, error) {
+	var ms []models.User
+	query := tx.Rebind(`SELECT * FROM users WHERE LOWER(email) = LOWER(?);`)
+	err := tx.SelectContext(ctx, &ms, query, email)
+	return ms, err
+}
+
 // GetAllUsers implements store.UserStore.
 func (*userStore) GetAllUsers(ctx context.Context, tx db.Handler) ([]models.User, error) {
 	var ms []models.User
@@ -240,3 +250,41 @@ func (*userStore) SetUserPasswordByUsername(ctx context.Context, tx db.Handler,
 	_, err := tx.ExecContext(ctx, query, password, username)
 	return err
 }
+
+// SetUserEmailByUsername implements store.UserStore. An empty email unsets
+// it.
+func (*userStore) SetUserEmailByUsername(ctx context.Context, tx db.Handler, username string, email string) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	query := tx.Rebind(`UPDATE users SET email = ? WHERE username = ?;`)
+	_, err := tx.ExecContext(ctx, query, sql.NullString{String: email, Valid: email != ""}, username)
+	return err
+}
+
+// SetUserQuotaByUsername implements store.UserStore. A null quota unsets it.
+func (*userStore) SetUserQuotaByUsername(ctx context.Context, tx db.Handler, username string, quota sql.NullInt64) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	query := tx.Rebind(`UPDATE users SET quota = ? WHERE username = ?;`)
+	_, err := tx.ExecContext(ctx, query, quota, username)
+	return err
+}
+
+// SetUserAllowPushCreateByUsername implements store.UserStore. A null value
+// unsets it.
+func (*userStore) SetUserAllowPushCreateByUsername(ctx context.Context, tx db.Handler, username string, allow sql.NullBool) error {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return err
+	}
+
+	query := tx.Rebind(`UPDATE users SET allow_push_create = ? WHERE username = ?;`)
+	_, err := tx.ExecContext(ctx, query, allow, username)
+	return err
+}
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
@@ -14,12 +16,17 @@ type webhookStore struct{}
 
 var _ store.WebhookStore = (*webhookStore)(nil)
 
+// webhookRepoID returns the repo_id column value of the webhooks of a
+// repository, NULL for the server webhooks.
+func webhookRepoID(repoID int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: repoID, Valid: repoID != 0}
+}
+
 // CreateWebhook implements store.WebhookStore.
-func (*webhookStore) CreateWebhook(ctx context.Context, h db.Handler, repoID int64, url string, secret string, contentType int, active bool) (int64, error) {
-	var id int64
-	query := h.Rebind(`INSERT INTO webhooks (repo_id, url, secret, content_type, active, updated_at)
-			VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP) RETURNING id;`)
-	err := h.GetContext(ctx, &id, query, repoID, url, secret, contentType, active)
+func (*webhookStore) CreateWebhook(ctx context.Context, h db.Handler, repoID int64, url string, secret string, contentType int, payloadTemplate string, payloadContentType string, active bool) (int64, error) {
+	query := h.Rebind(`INSERT INTO webhooks (repo_id, url, secret, content_type, payload_template, payload_content_type, active, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)`)
+	id, err := db.InsertReturningID(ctx, h, query, webhookRepoID(repoID), url, secret, contentType, payloadTemplate, payloadContentType, active)
 	if err != nil {
 		return 0, err
 	}
@@ -28,14 +35,22 @@ func (*webhookStore) CreateWebhook(ctx context.Context, h db.Handler, repoID int
 }
 
 // CreateWebhookDelivery implements store.WebhookStore.
-func (*webhookStore) CreateWebhookDelivery(ctx context.Context, h db.Handler, id uuid.UUID, webhookID int64, event int, url string, method string, requestError error, requestHeaders string, requestBody string, responseStatus int, responseHeaders string, responseBody string) error {
-	query := h.Rebind(`INSERT INTO webhook_deliveries (id, webhook_id, event, request_url, request_method, request_error, request_headers, request_body, response_status, response_headers, response_body)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`)
+func (*webhookStore) CreateWebhookDelivery(ctx context.Context, h db.Handler, id uuid.UUID, webhookID int64, event int, url string, method string, requestBody string, nextAttemptAt time.Time) error {
+	query := h.Rebind(`INSERT INTO webhook_deliveries (id, webhook_id, event, request_url, request_method, request_headers, request_body, response_status, response_headers, response_body, attempts, next_attempt_at)
+			VALUES (?, ?, ?, ?, ?, '', ?, 0, '', '', 0, ?);`)
+	_, err := h.ExecContext(ctx, query, id, webhookID, event, url, method, requestBody, nextAttemptAt.UTC())
+	return err
+}
+
+// CreateWebhookDeliveryAttempt implements store.WebhookStore.
+func (*webhookStore) CreateWebhookDeliveryAttempt(ctx context.Context, h db.Handler, deliveryID uuid.UUID, attempt int, requestError error, responseStatus int, responseBody string) error {
+	query := h.Rebind(`INSERT INTO webhook_delivery_attempts (delivery_id, attempt, request_error, response_status, response_body)
+			VALUES (?, ?, ?, ?, ?);`)
 	var reqErr string
 	if requestError != nil {
 		reqErr = requestError.Error()
 	}
-	_, err := h.ExecContext(ctx, query, id, webhookID, event, url, method, reqErr, requestHeaders, requestBody, responseStatus, responseHeaders, responseBody)
+	_, err := h.ExecContext(ctx, query, deliveryID, attempt, reqErr, responseStatus, responseBody)
 	return err
 }
 
@@ -61,7 +76,7 @@ func (*webhookStore) DeleteWebhookByID(ctx context.Context, h db.Handler, id int
 
 // DeleteWebhookForRepoByID implements store.WebhookStore.
 func (*webhookStore) DeleteWebhookForRepoByID(ctx context.Context, h db.Handler, repoID int64, id int64) error {
-	query := h.Rebind(`DELETE FROM webhooks WHERE repo_id = ? AND id = ?;`)
+	query := h.Rebind(`DELETE FROM webhooks WHERE COALESCE(repo_id, 0) = ? AND id = ?;`)
 	_, err := h.ExecContext(ctx, query, repoID, id)
 	return err
 }
@@ -75,6 +90,10 @@ func (*webhookStore) DeleteWebhookDeliveryByID(ctx context.Context, h db.Handler
 
 // DeleteWebhookEventsByWebhookID implements store.WebhookStore.
 func (*webhookStore) DeleteWebhookEventsByID(ctx context.Context, h db.Handler, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
 	query, args, err := sqlx.In(`DELETE FROM webhook_events WHERE id IN (?);`, ids)
 	if err != nil {
 		return err
@@ -87,7 +106,7 @@ func (*webhookStore) DeleteWebhookEventsByID(ctx context.Context, h db.Handler,
 
 // GetWebhookByID implements store.WebhookStore.
 func (*webhookStore) GetWebhookByID(ctx context.Context, h db.Handler, repoID int64, id int64) (models.Webhook, error) {
-	query := h.Rebind(`SELECT * FROM webhooks WHERE repo_id = ? AND id = ?;`)
+	query := h.Rebind(`SELECT * FROM webhooks WHERE COALESCE(repo_id, 0) = ? AND id = ?;`)
 	var wh models.Webhook
 	err := h.GetContext(ctx, &wh, query, repoID, id)
 	return wh, err
@@ -101,6 +120,14 @@ func (*webhookStore) GetWebhookDeliveriesByWebhookID(ctx context.Context, h db.H
 	return whds, err
 }
 
+// GetWebhookDeliveryAttemptsByDeliveryID implements store.WebhookStore.
+func (*webhookStore) GetWebhookDeliveryAttemptsByDeliveryID(ctx context.Context, h db.Handler, deliveryID uuid.UUID) ([]models.WebhookDeliveryAttempt, error) {
+	query := h.Rebind(`SELECT * FROM webhook_delivery_attempts WHERE delivery_id = ? ORDER BY id;`)
+	var attempts []models.WebhookDeliveryAttempt
+	err := h.SelectContext(ctx, &attempts, query, deliveryID)
+	return attempts, err
+}
+
 // GetWebhookDeliveryByID implements store.WebhookStore.
 func (*webhookStore) GetWebhookDeliveryByID(ctx context.Context, h db.Handler, webhookID int64, id uuid.UUID) (models.WebhookDelivery, error) {
 	query := h.Rebind(`SELECT * FROM webhook_deliveries WHERE webhook_id = ? AND id = ?;`)
@@ -109,6 +136,24 @@ func (*webhookStore) GetWebhookDeliveryByID(ctx context.Context, h db.Handler, w
 	return whd, err
 }
 
+// GetWebhookForDelivery implements store.WebhookStore.
+func (*webhookStore) GetWebhookForDelivery(ctx context.Context, h db.Handler, deliveryID uuid.UUID) (models.Webhook, error) {
+	query := h.Rebind(`SELECT webhooks.* FROM webhooks
+			INNER JOIN webhook_deliveries ON webhook_deliveries.webhook_id = webhooks.id
+			WHERE webhook_deliveries.id = ?;`)
+	var wh models.Webhook
+	err := h.GetContext(ctx, &wh, query, deliveryID)
+	return wh, err
+}
+
+// GetPendingWebhookDeliveries implements store.WebhookStore.
+func (*webhookStore) GetPendingWebhookDeliveries(ctx context.Context, h db.Handler) ([]models.WebhookDelivery, error) {
+	query := h.Rebind(`SELECT * FROM webhook_deliveries WHERE next_attempt_at IS NOT NULL ORDER BY next_attempt_at;`)
+	var whds []models.WebhookDelivery
+	err := h.SelectContext(ctx, &whds, query)
+	return whds, err
+}
+
 // GetWebhookEventByID implements store.WebhookStore.
 func (*webhookStore) GetWebhookEventByID(ctx context.Context, h db.Handler, id int64) (models.WebhookEvent, error) {
 	query := h.Rebind(`SELECT * FROM webhook_events WHERE id = ?;`)
@@ -127,7 +172,7 @@ func (*webhookStore) GetWebhookEventsByWebhookID(ctx context.Context, h db.Handl
 
 // GetWebhooksByRepoID implements store.WebhookStore.
 func (*webhookStore) GetWebhooksByRepoID(ctx context.Context, h db.Handler, repoID int64) ([]models.Webhook, error) {
-	query := h.Rebind(`SELECT * FROM webhooks WHERE repo_id = ?;`)
+	query := h.Rebind(`SELECT * FROM webhooks WHERE COALESCE(repo_id, 0) = ?;`)
 	var whs []models.Webhook
 	err := h.SelectContext(ctx, &whs, query, repoID)
 	return whs, err
@@ -135,10 +180,10 @@ func (*webhookStore) GetWebhooksByRepoID(ctx context.Context, h db.Handler, repo
 
 // GetWebhooksByRepoIDWhereEvent implements store.WebhookStore.
 func (*webhookStore) GetWebhooksByRepoIDWhereEvent(ctx context.Context, h db.Handler, repoID int64, events []int) ([]models.Webhook, error) {
-	query, args, err := sqlx.In(`SELECT webhooks.*
+	query, args, err := sqlx.In(`SELECT DISTINCT webhooks.*
 			FROM webhooks
 			INNER JOIN webhook_events ON webhooks.id = webhook_events.webhook_id
-			WHERE webhooks.repo_id = ? AND webhook_events.event IN (?);`, repoID, events)
+			WHERE COALESCE(webhooks.repo_id, 0) = ? AND webhooks.active = ? AND webhook_events.event IN (?);`, repoID, true, events)
 	if err != nil {
 		return nil, err
 	}
@@ -151,15 +196,34 @@ func (*webhookStore) GetWebhooksByRepoIDWhereEvent(ctx context.Context, h db.Han
 
 // ListWebhookDeliveriesByWebhookID implements store.WebhookStore.
 func (*webhookStore) ListWebhookDeliveriesByWebhookID(ctx context.Context, h db.Handler, webhookID int64) ([]models.WebhookDelivery, error) {
-	query := h.Rebind(`SELECT id, response_status, event FROM webhook_deliveries WHERE webhook_id = ?;`)
+	query := h.Rebind(`SELECT id, response_status, event, attempts, next_attempt_at, created_at FROM webhook_deliveries WHERE webhook_id = ? ORDER BY created_at;`)
 	var whds []models.WebhookDelivery
 	err := h.SelectContext(ctx, &whds, query, webhookID)
 	return whds, err
 }
 
+// RequeueWebhookDeliveryByID implements store.WebhookStore.
+func (*webhookStore) RequeueWebhookDeliveryByID(ctx context.Context, h db.Handler, webhookID int64, id uuid.UUID, nextAttemptAt time.Time) error {
+	query := h.Rebind(`UPDATE webhook_deliveries SET attempts = 0, next_attempt_at = ? WHERE webhook_id = ? AND id = ?;`)
+	_, err := h.ExecContext(ctx, query, nextAttemptAt.UTC(), webhookID, id)
+	return err
+}
+
 // UpdateWebhookByID implements store.WebhookStore.
-func (*webhookStore) UpdateWebhookByID(ctx context.Context, h db.Handler, repoID int64, id int64, url string, secret string, contentType int, active bool) error {
-	query := h.Rebind(`UPDATE webhooks SET url = ?, secret = ?, content_type = ?, active = ?, updated_at = CURRENT_TIMESTAMP WHERE repo_id = ? AND id = ?;`)
-	_, err := h.ExecContext(ctx, query, url, secret, contentType, active, repoID, id)
+func (*webhookStore) UpdateWebhookByID(ctx context.Context, h db.Handler, repoID int64, id int64, url string, secret string, contentType int, payloadTemplate string, payloadContentType string, active bool) error {
+	query := h.Rebind(`UPDATE webhooks SET url = ?, secret = ?, content_type = ?, payload_template = ?, payload_content_type = ?, active = ?, updated_at = CURRENT_TIMESTAMP WHERE COALESCE(repo_id, 0) = ? AND id = ?;`)
+	_, err := h.ExecContext(ctx, query, url, secret, contentType, payloadTemplate, payloadContentType, active, repoID, id)
+	return err
+}
+
+// UpdateWebhookDeliveryByID implements store.WebhookStore.
+func (*webhookStore) UpdateWebhookDeliveryByID(ctx context.Context, h db.Handler, id uuid.UUID, attempts int, requestError error, requestHeaders string, responseStatus int, responseHeaders string, responseBody string, nextAttemptAt time.Time) error {
+	query := h.Rebind(`UPDATE webhook_deliveries SET attempts = ?, request_error = ?, request_headers = ?, response_status = ?, response_headers = ?, response_body = ?, next_attempt_at = ? WHERE id = ?;`)
+	var reqErr sql.NullString
+	if requestError != nil {
+		reqErr = sql.NullString{String: requestError.Error(), Valid: true}
+	}
+	next := sql.NullTime{Time: nextAttemptAt.UTC(), Valid: !nextAttemptAt.IsZero()}
+	_, err := h.ExecContext(ctx, query, attempts, reqErr, requestHeaders, responseStatus, responseHeaders, responseBody, next, id)
 	return err
 }
@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// DeployKeyStore is an interface for managing the keys granting access to a
+// single repository.
+type DeployKeyStore interface {
+	AddDeployKeyByRepo(ctx context.Context, h db.Handler, repo string, title string, publicKey string, level access.AccessLevel) error
+	RemoveDeployKeyByRepo(ctx context.Context, h db.Handler, repo string, title string) error
+	ListDeployKeysByRepo(ctx context.Context, h db.Handler, repo string) ([]models.DeployKey, error)
+	FindDeployKeyByPublicKey(ctx context.Context, h db.Handler, publicKey string) (models.DeployKey, error)
+}
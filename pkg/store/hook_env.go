@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// HookEnvStore is an interface for managing the environment variables passed
+// to the custom hooks of a repository.
+type HookEnvStore interface {
+	// SetHookEnvByRepo creates or updates an environment variable of a
+	// repository.
+	SetHookEnvByRepo(ctx context.Context, h db.Handler, repo string, name string, value string, secret bool) error
+	// RemoveHookEnvByRepo removes an environment variable of a repository.
+	RemoveHookEnvByRepo(ctx context.Context, h db.Handler, repo string, name string) error
+	// ListHookEnvByRepo returns the environment variables of a repository.
+	ListHookEnvByRepo(ctx context.Context, h db.Handler, repo string) ([]models.RepoHookEnv, error)
+}
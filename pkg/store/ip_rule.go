@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// IPRuleStore is an interface for managing the ip rules of the server and
+// of users. A user ID of 0 selects the server-wide rules.
+type IPRuleStore interface {
+	CreateIPRule(ctx context.Context, h db.Handler, userID int64, cidr string, allow bool) error
+	DeleteIPRule(ctx context.Context, h db.Handler, userID int64, cidr string) error
+	GetIPRules(ctx context.Context, h db.Handler, userID int64) ([]models.IPRule, error)
+}
@@ -12,6 +12,7 @@ type LFSStore interface {
 	CreateLFSObject(ctx context.Context, h db.Handler, repoID int64, oid string, size int64) error
 	GetLFSObjectByOid(ctx context.Context, h db.Handler, repoID int64, oid string) (models.LFSObject, error)
 	GetLFSObjects(ctx context.Context, h db.Handler, repoID int64) ([]models.LFSObject, error)
+	GetLFSObjectsAfterID(ctx context.Context, h db.Handler, repoID int64, afterID int64, limit int) ([]models.LFSObject, error)
 	GetLFSObjectsByName(ctx context.Context, h db.Handler, name string) ([]models.LFSObject, error)
 	DeleteLFSObjectByOid(ctx context.Context, h db.Handler, repoID int64, oid string) error
 
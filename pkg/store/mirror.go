@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// MirrorStore is an interface for managing mirror repositories.
+type MirrorStore interface {
+	// GetMirrorConfigByRepoID returns the mirror configuration of a repository.
+	GetMirrorConfigByRepoID(ctx context.Context, h db.Handler, repoID int64) (models.MirrorConfig, error)
+	// SetMirrorConfigByRepoID creates or updates the mirror configuration of a repository.
+	SetMirrorConfigByRepoID(ctx context.Context, h db.Handler, repoID int64, remoteURL string, syncInterval int64, username string, password string, sshKey string) error
+	// SetMirrorSyncedByRepoID records the result of a mirror sync.
+	SetMirrorSyncedByRepoID(ctx context.Context, h db.Handler, repoID int64, syncErr error) error
+}
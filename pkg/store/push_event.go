@@ -0,0 +1,14 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// PushEventStore is an interface for managing the pushes to repositories.
+type PushEventStore interface {
+	CreatePushEvent(ctx context.Context, h db.Handler, repoID int64, username string, refs string, commits int64) error
+	GetPushEventsByRepoID(ctx context.Context, h db.Handler, repoID int64, limit int) ([]models.PushEvent, error)
+}
@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// RepoForkStore is an interface for managing the forks of repositories.
+type RepoForkStore interface {
+	// CreateRepoFork records that a repository is a fork of another.
+	CreateRepoFork(ctx context.Context, h db.Handler, repoID int64, parentID int64) error
+	// GetRepoForkParent returns the repository a repository is a fork of.
+	GetRepoForkParent(ctx context.Context, h db.Handler, repoID int64) (models.Repo, error)
+	// GetRepoForks returns the forks of a repository.
+	GetRepoForks(ctx context.Context, h db.Handler, parentID int64) ([]models.Repo, error)
+	// DeleteRepoFork records that a repository is no longer a fork.
+	DeleteRepoFork(ctx context.Context, h db.Handler, repoID int64) error
+}
@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// RepoLanguagesStore is an interface for managing the language breakdown of
+// repositories.
+type RepoLanguagesStore interface {
+	// GetRepoLanguagesByRepoID returns the language breakdown of a repository.
+	GetRepoLanguagesByRepoID(ctx context.Context, h db.Handler, repoID int64) (models.RepoLanguages, error)
+	// SetRepoLanguagesByRepoID creates or updates the language breakdown of a
+	// repository at a commit.
+	SetRepoLanguagesByRepoID(ctx context.Context, h db.Handler, repoID int64, commitID string, languages string) error
+}
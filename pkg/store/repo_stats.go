@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// RepoStatsStore is an interface for managing repository statistics.
+type RepoStatsStore interface {
+	// GetRepoStatsByRepoID returns the statistics of a repository.
+	GetRepoStatsByRepoID(ctx context.Context, h db.Handler, repoID int64) (models.RepoStats, error)
+	// SetRepoStatsByRepoID creates or updates the statistics of a repository.
+	SetRepoStatsByRepoID(ctx context.Context, h db.Handler, repoID int64, size int64, commits int64, defaultBranch string) error
+	// SetRepoPushedByRepoID records a push to a repository.
+	SetRepoPushedByRepoID(ctx context.Context, h db.Handler, repoID int64) error
+}
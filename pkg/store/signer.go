@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// SignerStore is an interface for managing the keys allowed to sign commits
+// pushed to a repository.
+type SignerStore interface {
+	AddSignerByRepo(ctx context.Context, h db.Handler, repo string, principal string, publicKey string) error
+	RemoveSignerByRepo(ctx context.Context, h db.Handler, repo string, principal string) error
+	ListSignersByRepo(ctx context.Context, h db.Handler, repo string) ([]models.RepoSigner, error)
+}
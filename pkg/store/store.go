@@ -9,4 +9,18 @@ type Store interface {
 	LFSStore
 	AccessTokenStore
 	WebhookStore
+	MirrorStore
+	SignerStore
+	TeamStore
+	BranchProtectionStore
+	TopicStore
+	AuditStore
+	IPRuleStore
+	RepoStatsStore
+	DeployKeyStore
+	PushEventStore
+	HookEnvStore
+	TagProtectionStore
+	RepoLanguagesStore
+	RepoForkStore
 }
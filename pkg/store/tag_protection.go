@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// TagProtectionStore is an interface for managing the tag protection rules of
+// repositories.
+type TagProtectionStore interface {
+	AddTagProtectionByRepo(ctx context.Context, h db.Handler, repo string, pattern string) error
+	RemoveTagProtectionByRepo(ctx context.Context, h db.Handler, repo string, pattern string) error
+	ListTagProtectionsByRepo(ctx context.Context, h db.Handler, repo string) ([]models.TagProtection, error)
+}
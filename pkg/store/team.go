@@ -0,0 +1,27 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// TeamStore is an interface for managing teams, their members, and their
+// access to repositories.
+type TeamStore interface {
+	CreateTeam(ctx context.Context, h db.Handler, name string, level access.AccessLevel) error
+	DeleteTeamByName(ctx context.Context, h db.Handler, name string) error
+	GetTeamByName(ctx context.Context, h db.Handler, name string) (models.Team, error)
+	ListTeams(ctx context.Context, h db.Handler) ([]models.Team, error)
+
+	AddTeamMemberByUsername(ctx context.Context, h db.Handler, team string, username string) error
+	RemoveTeamMemberByUsername(ctx context.Context, h db.Handler, team string, username string) error
+	ListTeamMembersAsUsers(ctx context.Context, h db.Handler, team string) ([]models.User, error)
+
+	AddTeamRepo(ctx context.Context, h db.Handler, team string, repo string, level access.AccessLevel) error
+	RemoveTeamRepo(ctx context.Context, h db.Handler, team string, repo string) error
+	ListTeamReposByRepo(ctx context.Context, h db.Handler, repo string) ([]models.TeamRepo, error)
+	GetTeamAccessByUsernameAndRepo(ctx context.Context, h db.Handler, username string, repo string) (access.AccessLevel, error)
+}
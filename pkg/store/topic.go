@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// TopicStore is an interface for managing the topics of repositories.
+type TopicStore interface {
+	AddTopicByRepo(ctx context.Context, h db.Handler, repo string, topic string) error
+	RemoveTopicByRepo(ctx context.Context, h db.Handler, repo string, topic string) error
+	ListTopicsByRepo(ctx context.Context, h db.Handler, repo string) ([]models.Topic, error)
+	ListReposByTopic(ctx context.Context, h db.Handler, topic string) ([]models.Repo, error)
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
@@ -14,6 +15,7 @@ type UserStore interface {
 	FindUserByUsername(ctx context.Context, h db.Handler, username string) (models.User, error)
 	FindUserByPublicKey(ctx context.Context, h db.Handler, pk ssh.PublicKey) (models.User, error)
 	FindUserByAccessToken(ctx context.Context, h db.Handler, token string) (models.User, error)
+	FindUsersByEmail(ctx context.Context, h db.Handler, email string) ([]models.User, error)
 	GetAllUsers(ctx context.Context, h db.Handler) ([]models.User, error)
 	CreateUser(ctx context.Context, h db.Handler, username string, isAdmin bool, pks []ssh.PublicKey) error
 	DeleteUserByUsername(ctx context.Context, h db.Handler, username string) error
@@ -25,4 +27,7 @@ type UserStore interface {
 	ListPublicKeysByUsername(ctx context.Context, h db.Handler, username string) ([]ssh.PublicKey, error)
 	SetUserPassword(ctx context.Context, h db.Handler, userID int64, password string) error
 	SetUserPasswordByUsername(ctx context.Context, h db.Handler, username string, password string) error
+	SetUserEmailByUsername(ctx context.Context, h db.Handler, username string, email string) error
+	SetUserQuotaByUsername(ctx context.Context, h db.Handler, username string, quota sql.NullInt64) error
+	SetUserAllowPushCreateByUsername(ctx context.Context, h db.Handler, username string, allow sql.NullBool) error
 }
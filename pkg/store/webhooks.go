@@ -2,24 +2,28 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
 	"github.com/google/uuid"
 )
 
-// WebhookStore is an interface for managing webhooks.
+// WebhookStore is an interface for managing webhooks. The server webhooks are
+// the ones of repoID 0.
 type WebhookStore interface {
+	// GetWebhookForDelivery returns the webhook of a webhook delivery.
+	GetWebhookForDelivery(ctx context.Context, h db.Handler, deliveryID uuid.UUID) (models.Webhook, error)
 	// GetWebhookByID returns a webhook by its ID.
 	GetWebhookByID(ctx context.Context, h db.Handler, repoID int64, id int64) (models.Webhook, error)
 	// GetWebhooksByRepoID returns all webhooks for a repository.
 	GetWebhooksByRepoID(ctx context.Context, h db.Handler, repoID int64) ([]models.Webhook, error)
-	// GetWebhooksByRepoIDWhereEvent returns all webhooks for a repository where event is in the events.
+	// GetWebhooksByRepoIDWhereEvent returns all active webhooks for a repository where event is in the events.
 	GetWebhooksByRepoIDWhereEvent(ctx context.Context, h db.Handler, repoID int64, events []int) ([]models.Webhook, error)
 	// CreateWebhook creates a webhook.
-	CreateWebhook(ctx context.Context, h db.Handler, repoID int64, url string, secret string, contentType int, active bool) (int64, error)
+	CreateWebhook(ctx context.Context, h db.Handler, repoID int64, url string, secret string, contentType int, payloadTemplate string, payloadContentType string, active bool) (int64, error)
 	// UpdateWebhookByID updates a webhook by its ID.
-	UpdateWebhookByID(ctx context.Context, h db.Handler, repoID int64, id int64, url string, secret string, contentType int, active bool) error
+	UpdateWebhookByID(ctx context.Context, h db.Handler, repoID int64, id int64, url string, secret string, contentType int, payloadTemplate string, payloadContentType string, active bool) error
 	// DeleteWebhookByID deletes a webhook by its ID.
 	DeleteWebhookByID(ctx context.Context, h db.Handler, id int64) error
 	// DeleteWebhookForRepoByID deletes a webhook for a repository by its ID.
@@ -41,8 +45,24 @@ type WebhookStore interface {
 	// ListWebhookDeliveriesByWebhookID returns all webhook deliveries for a webhook.
 	// This only returns the delivery ID, response status, and event.
 	ListWebhookDeliveriesByWebhookID(ctx context.Context, h db.Handler, webhookID int64) ([]models.WebhookDelivery, error)
-	// CreateWebhookDelivery creates a webhook delivery.
-	CreateWebhookDelivery(ctx context.Context, h db.Handler, id uuid.UUID, webhookID int64, event int, url string, method string, requestError error, requestHeaders string, requestBody string, responseStatus int, responseHeaders string, responseBody string) error
+	// GetPendingWebhookDeliveries returns all webhook deliveries waiting for an
+	// attempt, soonest first.
+	GetPendingWebhookDeliveries(ctx context.Context, h db.Handler) ([]models.WebhookDelivery, error)
+	// CreateWebhookDelivery creates a webhook delivery to be attempted at
+	// nextAttemptAt.
+	CreateWebhookDelivery(ctx context.Context, h db.Handler, id uuid.UUID, webhookID int64, event int, url string, method string, requestBody string, nextAttemptAt time.Time) error
+	// UpdateWebhookDeliveryByID records the last attempt of a webhook delivery.
+	// A zero nextAttemptAt stops further attempts.
+	UpdateWebhookDeliveryByID(ctx context.Context, h db.Handler, id uuid.UUID, attempts int, requestError error, requestHeaders string, responseStatus int, responseHeaders string, responseBody string, nextAttemptAt time.Time) error
+	// RequeueWebhookDeliveryByID resets the attempts of a webhook delivery and
+	// schedules it for nextAttemptAt.
+	RequeueWebhookDeliveryByID(ctx context.Context, h db.Handler, webhookID int64, id uuid.UUID, nextAttemptAt time.Time) error
 	// DeleteWebhookDeliveryByID deletes a webhook delivery by its ID.
 	DeleteWebhookDeliveryByID(ctx context.Context, h db.Handler, webhookID int64, id uuid.UUID) error
+
+	// GetWebhookDeliveryAttemptsByDeliveryID returns all the attempts of a
+	// webhook delivery, oldest first.
+	GetWebhookDeliveryAttemptsByDeliveryID(ctx context.Context, h db.Handler, deliveryID uuid.UUID) ([]models.WebhookDeliveryAttempt, error)
+	// CreateWebhookDeliveryAttempt records an attempt of a webhook delivery.
+	CreateWebhookDeliveryAttempt(ctx context.Context, h db.Handler, deliveryID uuid.UUID, attempt int, requestError error, responseStatus int, responseBody string) error
 }
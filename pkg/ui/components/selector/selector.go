@@ -267,6 +267,10 @@ func (s *Selector) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 	case list.FilterMatchesMsg:
 		cmds = append(cmds, s.activeFilterCmd)
 	}
+	var selected string
+	if item := s.SelectedItem(); item != nil {
+		selected = item.ID()
+	}
 	m, cmd := s.Model.Update(msg)
 	s.mtx.Lock()
 	s.Model = &m
@@ -276,6 +280,10 @@ func (s *Selector) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 	}
 	// Track filter state and update active item when filter state changes.
 	filterState := s.FilterState()
+	_, filtered := msg.(list.FilterMatchesMsg)
+	if filtered || s.filterState != filterState {
+		s.selectID(selected)
+	}
 	if s.filterState != filterState {
 		cmds = append(cmds, s.activeFilterCmd)
 	}
@@ -311,10 +319,21 @@ func (s *Selector) activeFilterCmd() tea.Msg {
 	if len(items) == 0 {
 		return nil
 	}
-	item := items[0]
-	i, ok := item.(IdentifiableItem)
-	if !ok {
+	item := s.SelectedItem()
+	if item == nil {
 		return nil
 	}
-	return ActiveMsg{i}
+	return ActiveMsg{item}
+}
+
+// selectID keeps the item with the given ID selected if it's still visible,
+// such as when the filter changes. Otherwise, the first item is selected.
+func (s *Selector) selectID(id string) {
+	for i, item := range s.VisibleItems() {
+		if item, ok := item.(IdentifiableItem); ok && item.ID() == id {
+			s.Select(i)
+			return
+		}
+	}
+	s.Select(0)
 }
@@ -0,0 +1,293 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/ui/common"
+	"github.com/charmbracelet/soft-serve/pkg/ui/components/viewport"
+)
+
+var (
+	nextFile = key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next file"),
+	)
+	prevFile = key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous file"),
+	)
+	toggleFile = key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "collapse/expand file"),
+	)
+	toggleFiles = key.NewBinding(
+		key.WithKeys("Z"),
+		key.WithHelp("Z", "collapse/expand all"),
+	)
+)
+
+// diffBatchFiles is the number of files rendered at once. Files of large
+// diffs are rendered as the diff is scrolled through.
+const diffBatchFiles = 20
+
+// DiffView is a model that displays a diff file by file. Files can be
+// collapsed to their header.
+type DiffView struct {
+	common    common.Common
+	vp        *viewport.Viewport
+	header    string
+	diff      *git.Diff
+	collapsed []bool
+	// cur is the current file, -1 before the first file.
+	cur int
+	// patches are the rendered patches of the loaded files, offsets the
+	// lines the files start at.
+	patches []string
+	offsets []int
+}
+
+// NewDiffView creates a new DiffView model.
+func NewDiffView(common common.Common) *DiffView {
+	return &DiffView{
+		common: common,
+		vp:     viewport.New(common),
+		cur:    -1,
+	}
+}
+
+// SetSize implements common.Component.
+func (d *DiffView) SetSize(width, height int) {
+	d.common.SetSize(width, height)
+	d.vp.SetSize(width, height)
+	for i := range d.patches {
+		d.patches[i] = d.renderPatch(i)
+	}
+	d.render()
+}
+
+// SetDiff sets the diff and the header shown above it.
+func (d *DiffView) SetDiff(header string, diff *git.Diff) {
+	d.header = header
+	d.diff = diff
+	d.collapsed = make([]bool, len(diff.Files))
+	d.cur = -1
+	d.patches = d.patches[:0]
+	d.loadMore()
+	d.render()
+	d.vp.GotoTop()
+}
+
+// SetHeader sets the header shown above the diff.
+func (d *DiffView) SetHeader(header string) {
+	d.header = header
+	d.render()
+}
+
+// ShortHelp implements help.KeyMap.
+func (d *DiffView) ShortHelp() []key.Binding {
+	return []key.Binding{
+		nextFile,
+		prevFile,
+		toggleFile,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (d *DiffView) FullHelp() [][]key.Binding {
+	k := d.vp.KeyMap
+	return [][]key.Binding{
+		{
+			k.PageDown,
+			k.PageUp,
+			k.HalfPageDown,
+			k.HalfPageUp,
+		},
+		{
+			k.Down,
+			k.Up,
+			d.common.KeyMap.GotoTop,
+			d.common.KeyMap.GotoBottom,
+		},
+		{
+			nextFile,
+			prevFile,
+			toggleFile,
+			toggleFiles,
+		},
+	}
+}
+
+// Init implements tea.Model.
+func (d *DiffView) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (d *DiffView) Update(msg tea.Msg) (common.Model, tea.Cmd) {
+	if d.diff == nil {
+		return d, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, nextFile):
+			d.gotoFile(d.cur + 1)
+			return d, nil
+		case key.Matches(msg, prevFile):
+			if d.cur > 0 {
+				d.gotoFile(d.cur - 1)
+			} else {
+				d.cur = -1
+				d.render()
+				d.vp.GotoTop()
+			}
+			return d, nil
+		case key.Matches(msg, toggleFile):
+			if d.cur >= 0 {
+				d.collapsed[d.cur] = !d.collapsed[d.cur]
+				d.gotoFile(d.cur)
+			}
+			return d, nil
+		case key.Matches(msg, toggleFiles):
+			collapse := false
+			for _, c := range d.collapsed {
+				if !c {
+					collapse = true
+					break
+				}
+			}
+			for i := range d.collapsed {
+				d.collapsed[i] = collapse
+			}
+			d.gotoFile(max(d.cur, 0))
+			return d, nil
+		}
+	}
+
+	y := d.vp.YOffset()
+	vp, cmd := d.vp.Update(msg)
+	d.vp = vp.(*viewport.Viewport)
+	if d.vp.YOffset() != y {
+		// Scrolling makes the file at the top the current one.
+		d.cur = d.fileAt(d.vp.YOffset())
+		if d.vp.AtBottom() && len(d.patches) < len(d.diff.Files) {
+			d.loadMore()
+		}
+		d.render()
+	}
+	return d, cmd
+}
+
+// View implements tea.Model.
+func (d *DiffView) View() string {
+	return d.vp.View()
+}
+
+// ScrollPercent returns the scroll percentage of the diff.
+func (d *DiffView) ScrollPercent() float64 {
+	return d.vp.ScrollPercent()
+}
+
+// loadMore renders the next batch of files.
+func (d *DiffView) loadMore() {
+	n := min(len(d.patches)+diffBatchFiles, len(d.diff.Files))
+	for i := len(d.patches); i < n; i++ {
+		d.patches = append(d.patches, d.renderPatch(i))
+	}
+}
+
+// render sets the viewport content to the header and the rendered files,
+// keeping the scroll position.
+func (d *DiffView) render() {
+	if d.diff == nil {
+		return
+	}
+
+	var s strings.Builder
+	s.WriteString(d.header)
+	d.offsets = d.offsets[:0]
+	line := lipgloss.Height(d.header)
+	for i, p := range d.patches {
+		f := d.renderFileHeader(i)
+		if !d.collapsed[i] {
+			f += p
+		}
+		s.WriteString("\n")
+		d.offsets = append(d.offsets, line)
+		s.WriteString(f)
+		line += lipgloss.Height(f)
+	}
+	if rest := len(d.diff.Files) - len(d.patches); rest > 0 {
+		s.WriteString("\n")
+		s.WriteString(d.common.Styles.NoContent.Render(fmt.Sprintf("… %d more files", rest)))
+	}
+
+	y := d.vp.YOffset()
+	d.vp.SetContent(s.String())
+	d.vp.SetYOffset(y)
+}
+
+// renderFileHeader renders the header of a file, pointing at the current
+// file.
+func (d *DiffView) renderFileHeader(i int) string {
+	f := d.diff.Files[i]
+	styles := d.common.Styles.Log
+
+	cursor := " "
+	if i == d.cur {
+		cursor = ">"
+	}
+	icon := "▾"
+	if d.collapsed[i] {
+		icon = "▸"
+	}
+	name := f.Name
+	if f.IsRenamed() {
+		name = f.OldName() + " → " + f.Name
+	}
+	return fmt.Sprintf("%s %s %s %s %s",
+		cursor,
+		icon,
+		styles.DiffFile.Render(name),
+		styles.CommitStatsAdd.Render(fmt.Sprintf("+%d", f.NumAdditions())),
+		styles.CommitStatsDel.Render(fmt.Sprintf("-%d", f.NumDeletions())),
+	)
+}
+
+// renderPatch renders the patch of a file.
+func (d *DiffView) renderPatch(i int) string {
+	return renderPatch(d.diff.Files[i].Patch(), d.common.Width)
+}
+
+// fileAt returns the index of the file at a line, or -1 before the first
+// file.
+func (d *DiffView) fileAt(line int) int {
+	cur := -1
+	for i, o := range d.offsets {
+		if o > line {
+			break
+		}
+		cur = i
+	}
+	return cur
+}
+
+// gotoFile makes a file the current one and scrolls to its header,
+// rendering more files if needed.
+func (d *DiffView) gotoFile(i int) {
+	if i < 0 || i >= len(d.diff.Files) {
+		return
+	}
+	for i >= len(d.patches) {
+		d.loadMore()
+	}
+	d.cur = i
+	d.render()
+	d.vp.SetYOffset(d.offsets[i])
+}
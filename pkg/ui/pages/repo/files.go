@@ -3,18 +3,19 @@ package repo
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"strings"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
-	gitm "github.com/aymanbagabas/git-module"
 	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/code"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/selector"
+	"github.com/dustin/go-humanize"
 )
 
 type filesView int
@@ -44,11 +45,30 @@ var (
 		key.WithKeys("p"),
 		key.WithHelp("p", "toggle preview"),
 	)
+	expandDir = key.NewBinding(
+		key.WithKeys("l", "right"),
+		key.WithHelp("→/l", "expand"),
+	)
+	collapseDir = key.NewBinding(
+		key.WithKeys("h", "left"),
+		key.WithHelp("←/h", "collapse"),
+	)
+	parentDir = key.NewBinding(
+		key.WithKeys("backspace"),
+		key.WithHelp("backspace", "go up"),
+	)
 )
 
 // FileItemsMsg is a message that contains a list of files.
 type FileItemsMsg []selector.IdentifiableItem
 
+// FileTreeMsg is a message that contains the entries of an expanded
+// directory.
+type FileTreeMsg struct {
+	parent string
+	items  []selector.IdentifiableItem
+}
+
 // FileContentMsg is a message that contains the content of a file.
 type FileContentMsg struct {
 	content string
@@ -56,7 +76,7 @@ type FileContentMsg struct {
 }
 
 // FileBlameMsg is a message that contains the blame of a file.
-type FileBlameMsg *gitm.Blame
+type FileBlameMsg []git.BlameLine
 
 // Files is the model for the files view.
 type Files struct {
@@ -67,6 +87,8 @@ type Files struct {
 	repo           proto.Repository
 	code           *code.Code
 	path           string
+	parents        []string
+	expanded       map[string]bool
 	currentItem    *FileItem
 	currentContent FileContentMsg
 	currentBlame   FileBlameMsg
@@ -131,8 +153,10 @@ func (f *Files) ShortHelp() []key.Binding {
 	switch f.activeView {
 	case filesViewFiles:
 		return []key.Binding{
-			f.common.KeyMap.SelectItem,
-			f.common.KeyMap.BackItem,
+			f.openKey(),
+			expandDir,
+			collapseDir,
+			parentDir,
 			k.CursorUp,
 			k.CursorDown,
 		}
@@ -158,8 +182,10 @@ func (f *Files) FullHelp() [][]key.Binding {
 		k := f.selector.KeyMap
 		b = append(b, [][]key.Binding{
 			{
-				f.common.KeyMap.SelectItem,
-				f.common.KeyMap.BackItem,
+				f.openKey(),
+				expandDir,
+				collapseDir,
+				parentDir,
 			},
 			{
 				k.CursorUp,
@@ -207,9 +233,17 @@ func (f *Files) FullHelp() [][]key.Binding {
 	return append(b, actionKeys)
 }
 
+func (f *Files) openKey() key.Binding {
+	k := f.common.KeyMap.Select
+	k.SetHelp("enter", "open")
+	return k
+}
+
 // Init implements tea.Model.
 func (f *Files) Init() tea.Cmd {
 	f.path = ""
+	f.parents = nil
+	f.expanded = nil
 	f.currentItem = nil
 	f.activeView = filesViewLoading
 	f.lastSelected = make([]int, 0)
@@ -238,6 +272,8 @@ func (f *Files) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 			f.selector.Select(f.cursor)
 			f.cursor = -1
 		}
+	case FileTreeMsg:
+		cmds = append(cmds, f.expand(msg))
 	case FileContentMsg:
 		f.activeView = filesViewContent
 		f.currentContent = msg
@@ -253,7 +289,8 @@ func (f *Files) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 		switch sel := msg.IdentifiableItem.(type) {
 		case FileItem:
 			f.currentItem = &sel
-			f.path = filepath.Join(f.path, sel.entry.Name())
+			f.parents = append(f.parents, f.path)
+			f.path = sel.ID()
 			if sel.entry.IsTree() {
 				cmds = append(cmds, f.selectTreeCmd)
 			} else {
@@ -268,11 +305,35 @@ func (f *Files) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 	case tea.KeyPressMsg:
 		switch f.activeView {
 		case filesViewFiles:
+			item, _ := f.selector.SelectedItem().(FileItem)
 			switch {
-			case key.Matches(msg, f.common.KeyMap.SelectItem):
-				cmds = append(cmds, f.selector.SelectItemCmd)
-			case key.Matches(msg, f.common.KeyMap.BackItem):
+			case key.Matches(msg, parentDir):
 				cmds = append(cmds, f.deselectItemCmd())
+			case key.Matches(msg, expandDir):
+				switch {
+				case item.entry == nil:
+				case !item.entry.IsTree():
+					cmds = append(cmds, f.selector.SelectItemCmd)
+				case !item.expanded:
+					cmds = append(cmds, f.expandCmd(item))
+				default:
+					// Move to the first entry of the directory, if any.
+					if i := f.selector.Index() + 1; i < len(f.selector.Items()) {
+						if next, ok := f.selector.Items()[i].(FileItem); ok && next.depth > item.depth {
+							f.selector.Select(i)
+						}
+					}
+				}
+			case key.Matches(msg, collapseDir):
+				switch {
+				case item.entry == nil:
+				case item.expanded:
+					cmds = append(cmds, f.collapse(item))
+				case item.depth > 0:
+					f.selectParent(item)
+				default:
+					cmds = append(cmds, f.deselectItemCmd())
+				}
 			}
 		case filesViewContent:
 			switch {
@@ -319,6 +380,8 @@ func (f *Files) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 	case EmptyRepoMsg:
 		f.ref = nil
 		f.path = ""
+		f.parents = nil
+		f.expanded = nil
 		f.currentItem = nil
 		f.activeView = filesViewFiles
 		f.lastSelected = make([]int, 0)
@@ -391,8 +454,6 @@ func (f *Files) StatusBarInfo() string {
 }
 
 func (f *Files) updateFilesCmd() tea.Msg {
-	files := make([]selector.IdentifiableItem, 0)
-	dirs := make([]selector.IdentifiableItem, 0)
 	if f.ref == nil {
 		return nil
 	}
@@ -400,25 +461,142 @@ func (f *Files) updateFilesCmd() tea.Msg {
 	if err != nil {
 		return common.ErrorCmd(err)
 	}
-	path := f.path
-	ref := f.ref
-	t, err := r.TreePath(ref, path)
+	items, err := readTree(r, f.ref, f.path, 0, f.expanded)
 	if err != nil {
 		return common.ErrorCmd(err)
 	}
+	return FileItemsMsg(items)
+}
+
+// readTree returns the entries of the directory at path, directories first.
+// The entries of expanded subdirectories are read too and listed right below
+// them, the others are only read once they're expanded.
+func readTree(r *git.Repository, ref *git.Reference, path string, depth int, expanded map[string]bool) ([]selector.IdentifiableItem, error) {
+	t, err := r.TreePath(ref, path)
+	if err != nil {
+		return nil, err
+	}
 	ents, err := t.Entries()
 	if err != nil {
-		return common.ErrorCmd(err)
+		return nil, err
 	}
 	ents.Sort()
+	files := make([]selector.IdentifiableItem, 0)
+	dirs := make([]selector.IdentifiableItem, 0)
 	for _, e := range ents {
-		if e.IsTree() {
-			dirs = append(dirs, FileItem{entry: e})
-		} else {
-			files = append(files, FileItem{entry: e})
+		item := FileItem{entry: e, depth: depth}
+		if !e.IsTree() {
+			files = append(files, item)
+			continue
+		}
+		if !expanded[item.ID()] {
+			dirs = append(dirs, item)
+			continue
+		}
+		children, err := readTree(r, ref, item.ID(), depth+1, expanded)
+		if err != nil {
+			return nil, err
+		}
+		item.expanded = true
+		dirs = append(dirs, item)
+		dirs = append(dirs, children...)
+	}
+	return append(dirs, files...), nil
+}
+
+func (f *Files) expandCmd(item FileItem) tea.Cmd {
+	repo, ref, expanded := f.repo, f.ref, f.expanded
+	return func() tea.Msg {
+		r, err := repo.Open()
+		if err != nil {
+			return common.ErrorMsg(err)
+		}
+		items, err := readTree(r, ref, item.ID(), item.depth+1, expanded)
+		if err != nil {
+			return common.ErrorMsg(err)
+		}
+		return FileTreeMsg{parent: item.ID(), items: items}
+	}
+}
+
+// expand lists the entries of an expanded directory below it.
+func (f *Files) expand(msg FileTreeMsg) tea.Cmd {
+	items := f.selector.Items()
+	for i, it := range items {
+		item, ok := it.(FileItem)
+		if !ok || item.ID() != msg.parent {
+			continue
+		}
+		if item.expanded {
+			return nil
+		}
+		item.expanded = true
+		// The map is replaced rather than changed in place since commands
+		// read it concurrently.
+		expanded := maps.Clone(f.expanded)
+		if expanded == nil {
+			expanded = make(map[string]bool)
+		}
+		expanded[item.ID()] = true
+		f.expanded = expanded
+		newItems := make([]selector.IdentifiableItem, 0, len(items)+len(msg.items))
+		for _, it := range items[:i] {
+			newItems = append(newItems, it.(selector.IdentifiableItem))
+		}
+		newItems = append(newItems, item)
+		newItems = append(newItems, msg.items...)
+		for _, it := range items[i+1:] {
+			newItems = append(newItems, it.(selector.IdentifiableItem))
+		}
+		return f.setTreeItems(newItems, i)
+	}
+	return nil
+}
+
+// collapse removes the entries of an expanded directory from the list.
+func (f *Files) collapse(item FileItem) tea.Cmd {
+	expanded := make(map[string]bool, len(f.expanded))
+	prefix := item.ID() + "/"
+	for p := range f.expanded {
+		if p != item.ID() && !strings.HasPrefix(p, prefix) {
+			expanded[p] = true
+		}
+	}
+	f.expanded = expanded
+	items := f.selector.Items()
+	newItems := make([]selector.IdentifiableItem, 0, len(items))
+	index := f.selector.Index()
+	for i := 0; i < len(items); i++ {
+		it := items[i].(FileItem)
+		if it.ID() == item.ID() {
+			index = len(newItems)
+			it.expanded = false
+			newItems = append(newItems, it)
+			for i+1 < len(items) && items[i+1].(FileItem).depth > it.depth {
+				i++
+			}
+			continue
+		}
+		newItems = append(newItems, it)
+	}
+	return f.setTreeItems(newItems, index)
+}
+
+// selectParent moves the cursor to the directory the item is listed in.
+func (f *Files) selectParent(item FileItem) {
+	items := f.selector.Items()
+	for i := f.selector.Index() - 1; i >= 0; i-- {
+		if it, ok := items[i].(FileItem); ok && it.depth < item.depth {
+			f.selector.Select(i)
+			return
 		}
 	}
-	return FileItemsMsg(append(dirs, files...))
+}
+
+func (f *Files) setTreeItems(items []selector.IdentifiableItem, index int) tea.Cmd {
+	cmd := f.selector.SetItems(items)
+	f.selector.Select(index)
+	return cmd
 }
 
 func (f *Files) selectTreeCmd() tea.Msg {
@@ -458,19 +636,19 @@ func (f *Files) selectFileCmd() tea.Msg {
 		if !bin {
 			bin, err = fi.IsBinary()
 			if err != nil {
-				f.path = filepath.Dir(f.path)
+				f.path = f.popParent()
 				return common.ErrorMsg(err)
 			}
 		}
 
 		if bin {
-			f.path = filepath.Dir(f.path)
+			f.path = f.popParent()
 			return common.ErrorMsg(errBinaryFile)
 		}
 
 		c, err := fi.Bytes()
 		if err != nil {
-			f.path = filepath.Dir(f.path)
+			f.path = f.popParent()
 			return common.ErrorMsg(err)
 		}
 
@@ -482,12 +660,8 @@ func (f *Files) selectFileCmd() tea.Msg {
 }
 
 func (f *Files) fetchBlame() tea.Msg {
-	r, err := f.repo.Open()
-	if err != nil {
-		return common.ErrorMsg(err)
-	}
-
-	b, err := r.BlameFile(f.ref.ID, f.currentItem.entry.File().Path())
+	be := f.common.Backend()
+	b, err := be.Blame(f.common.Context(), f.repo, f.ref.ID, f.currentItem.entry.File().Path())
 	if err != nil {
 		return common.ErrorMsg(err)
 	}
@@ -495,39 +669,45 @@ func (f *Files) fetchBlame() tea.Msg {
 	return FileBlameMsg(b)
 }
 
-func renderBlame(c common.Common, f *FileItem, b *gitm.Blame) string {
+func renderBlame(c common.Common, f *FileItem, b []git.BlameLine) string {
 	if f == nil || f.entry.IsTree() || b == nil {
 		return ""
 	}
 
-	lines := make([]string, 0)
-	i := 1
-	var prev string
-	for {
-		commit := b.Line(i)
-		if commit == nil {
-			break
-		}
-		who := fmt.Sprintf("%s <%s>", commit.Author.Name, commit.Author.Email)
-		line := fmt.Sprintf("%s %s %s",
-			c.Styles.Tree.Blame.Hash.Render(commit.ID.String()[:7]),
-			c.Styles.Tree.Blame.Message.Render(commit.Summary()),
-			c.Styles.Tree.Blame.Who.Render(who),
-		)
-		if line != prev {
-			lines = append(lines, line)
-		} else {
+	lines := make([]string, 0, len(b))
+	var prev *git.BlameCommit
+	for _, l := range b {
+		// Only annotate the first line of consecutive lines of a commit.
+		if l.Commit == prev {
 			lines = append(lines, "")
+			continue
 		}
-		prev = line
-		i++
+		prev = l.Commit
+
+		who := fmt.Sprintf("%s <%s> %s", l.Commit.Author, l.Commit.AuthorEmail, humanize.Time(l.Commit.AuthorTime))
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			c.Styles.Tree.Blame.Hash.Render(l.Commit.ID[:7]),
+			c.Styles.Tree.Blame.Message.Render(l.Commit.Summary),
+			c.Styles.Tree.Blame.Who.Render(who),
+		))
 	}
 
 	return strings.Join(lines, "\n")
 }
 
+// popParent returns the directory the current file or directory was opened
+// from.
+func (f *Files) popParent() string {
+	if len(f.parents) == 0 {
+		return filepath.Dir(f.path)
+	}
+	p := f.parents[len(f.parents)-1]
+	f.parents = f.parents[:len(f.parents)-1]
+	return p
+}
+
 func (f *Files) deselectItemCmd() tea.Cmd {
-	f.path = filepath.Dir(f.path)
+	f.path = f.popParent()
 	index := 0
 	if len(f.lastSelected) > 0 {
 		index = f.lastSelected[len(f.lastSelected)-1]
@@ -18,11 +18,15 @@ import (
 // FileItem is a list item for a file.
 type FileItem struct {
 	entry *git.TreeEntry
+	// depth is how deep the item is nested below the current directory.
+	depth int
+	// expanded is whether the entries of the directory are listed below it.
+	expanded bool
 }
 
-// ID returns the ID of the file item.
+// ID returns the ID of the file item, its path in the repository.
 func (i FileItem) ID() string {
-	return i.entry.Name()
+	return i.entry.File().Path()
 }
 
 // Title returns the title of the file item.
@@ -104,7 +108,12 @@ func (d FileItemDelegate) Render(w io.Writer, m list.Model, index int, listItem
 	size := humanize.Bytes(uint64(i.entry.Size())) //nolint:gosec
 	size = strings.ReplaceAll(size, " ", "")
 	sizeLen := lipgloss.Width(size)
+	marker := "  "
 	if i.entry.IsTree() {
+		marker = "▸ "
+		if i.expanded {
+			marker = "▾ "
+		}
 		size = strings.Repeat(" ", sizeLen)
 		if index == m.Index() {
 			name = s.Active.FileDir.Render(name)
@@ -112,6 +121,7 @@ func (d FileItemDelegate) Render(w io.Writer, m list.Model, index int, listItem
 			name = s.Normal.FileDir.Render(name)
 		}
 	}
+	name = strings.Repeat("  ", i.depth) + marker + name
 	var nameStyle, sizeStyle, modeStyle lipgloss.Style
 	mode := i.Mode()
 	if index == m.Index() {
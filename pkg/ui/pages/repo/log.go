@@ -15,13 +15,21 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/footer"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/selector"
-	"github.com/charmbracelet/soft-serve/pkg/ui/components/viewport"
 	"github.com/charmbracelet/soft-serve/pkg/ui/styles"
 	"github.com/muesli/reflow/wrap"
 )
 
 var waitBeforeLoading = time.Millisecond * 100
 
+// logCachedPages is the number of pages of commits kept in memory, the ones
+// farthest from the current page are evicted first.
+const logCachedPages = 5
+
+var markBase = key.NewBinding(
+	key.WithKeys("m"),
+	key.WithHelp("m", "mark diff base"),
+)
+
 type logView int
 
 const (
@@ -33,8 +41,20 @@ const (
 // LogCountMsg is a message that contains the number of commits in a repo.
 type LogCountMsg int64
 
-// LogItemsMsg is a message that contains a slice of LogItem.
-type LogItemsMsg []selector.IdentifiableItem
+// LogItemsMsg is a message that contains a page of LogItem.
+type LogItemsMsg struct {
+	ref     *git.Reference
+	page    int
+	perPage int
+	logPage
+}
+
+// logPage is a page of commits.
+type logPage struct {
+	items []selector.IdentifiableItem
+	// more is true when there are commits after the page.
+	more bool
+}
 
 // LogCommitMsg is a message that contains a git commit.
 type LogCommitMsg *git.Commit
@@ -44,26 +64,40 @@ type LogDiffMsg *git.Diff
 
 // Log is a model that displays a list of commits and their diffs.
 type Log struct {
-	common         common.Common
-	selector       *selector.Selector
-	vp             *viewport.Viewport
-	activeView     logView
-	repo           proto.Repository
-	ref            *git.Reference
-	count          int64
-	nextPage       int
+	common     common.Common
+	selector   *selector.Selector
+	dv         *DiffView
+	activeView logView
+	repo       proto.Repository
+	ref        *git.Reference
+	count      int64
+	// page is the page of commits shown, or being loaded, and index the item
+	// to select once it's loaded, -1 being the last one.
+	page    int
+	index   int
+	perPage int
+	// endPending is whether to jump to the last commit once commits are
+	// counted.
+	endPending bool
+	// pages are the loaded pages of commits, and loading the ones being
+	// loaded.
+	pages          map[int]logPage
+	loading        map[int]bool
 	activeCommit   *git.Commit
 	selectedCommit *git.Commit
-	currentDiff    *git.Diff
-	loadingTime    time.Time
-	spinner        spinner.Model
+	// baseCommit is the commit selected commits are compared to instead of
+	// their parent.
+	baseCommit  *git.Commit
+	currentDiff *git.Diff
+	loadingTime time.Time
+	spinner     spinner.Model
 }
 
 // NewLog creates a new Log model.
 func NewLog(common common.Common) *Log {
 	l := &Log{
 		common:     common,
-		vp:         viewport.New(common),
+		dv:         NewDiffView(common),
 		activeView: logViewCommits,
 	}
 	selector := selector.New(common, []selector.IdentifiableItem{}, LogItemDelegate{&common})
@@ -80,6 +114,7 @@ func NewLog(common common.Common) *Log {
 	s := spinner.New(spinner.WithSpinner(spinner.Dot),
 		spinner.WithStyle(common.Styles.Spinner))
 	l.spinner = s
+	l.resetPages()
 	return l
 }
 
@@ -102,7 +137,7 @@ func (l *Log) TabName() string {
 func (l *Log) SetSize(width, height int) {
 	l.common.SetSize(width, height)
 	l.selector.SetSize(width, height)
-	l.vp.SetSize(width, height)
+	l.dv.SetSize(width, height)
 }
 
 // ShortHelp implements help.KeyMap.
@@ -115,17 +150,16 @@ func (l *Log) ShortHelp() []key.Binding {
 			l.common.KeyMap.UpDown,
 			l.common.KeyMap.SelectItem,
 			copyKey,
+			markBase,
 		}
 	case logViewDiff:
 		copyKey := l.common.KeyMap.Copy
 		copyKey.SetHelp("c", "copy diff")
-		return []key.Binding{
+		return append([]key.Binding{
 			l.common.KeyMap.UpDown,
 			l.common.KeyMap.BackItem,
 			copyKey,
-			l.common.KeyMap.GotoTop,
-			l.common.KeyMap.GotoBottom,
-		}
+		}, l.dv.ShortHelp()...)
 	default:
 		return []key.Binding{}
 	}
@@ -146,12 +180,13 @@ func (l *Log) FullHelp() [][]key.Binding {
 		b = append(b, [][]key.Binding{
 			{
 				copyKey,
+				markBase,
 				k.CursorUp,
 				k.CursorDown,
 			},
 			{
-				k.NextPage,
-				k.PrevPage,
+				l.common.KeyMap.NextPage,
+				l.common.KeyMap.PrevPage,
 				k.GoToStart,
 				k.GoToEnd,
 			},
@@ -159,25 +194,11 @@ func (l *Log) FullHelp() [][]key.Binding {
 	case logViewDiff:
 		copyKey := l.common.KeyMap.Copy
 		copyKey.SetHelp("c", "copy diff")
-		k := l.vp.KeyMap
 		b = append(b, []key.Binding{
 			l.common.KeyMap.BackItem,
 			copyKey,
 		})
-		b = append(b, [][]key.Binding{
-			{
-				k.PageDown,
-				k.PageUp,
-				k.HalfPageDown,
-				k.HalfPageUp,
-			},
-			{
-				k.Down,
-				k.Up,
-				l.common.KeyMap.GotoTop,
-				l.common.KeyMap.GotoBottom,
-			},
-		}...)
+		b = append(b, l.dv.FullHelp()...)
 	}
 	return b
 }
@@ -191,17 +212,154 @@ func (l *Log) startLoading() tea.Cmd {
 // Init implements tea.Model.
 func (l *Log) Init() tea.Cmd {
 	l.activeView = logViewCommits
-	l.nextPage = 0
 	l.count = 0
+	l.endPending = false
 	l.activeCommit = nil
 	l.selectedCommit = nil
+	l.baseCommit = nil
+	l.resetPages()
+	if l.ref == nil {
+		return nil
+	}
+	// Commits are counted in the background, only the status bar needs
+	// the count.
 	return tea.Batch(
 		l.countCommitsCmd,
-		// start loading on init
-		l.startLoading(),
+		l.showPage(0, 0),
 	)
 }
 
+// resetPages drops the loaded pages.
+func (l *Log) resetPages() {
+	l.page = 0
+	l.index = 0
+	l.perPage = max(l.selector.PerPage(), 1)
+	l.pages = make(map[int]logPage)
+	l.loading = make(map[int]bool)
+}
+
+// showPage shows a page of commits, selecting the item at index, and loads it
+// first if it's not loaded.
+func (l *Log) showPage(page, index int) tea.Cmd {
+	l.page = page
+	l.index = index
+	if _, ok := l.pages[page]; !ok {
+		return tea.Batch(l.loadPage(page), l.startLoading())
+	}
+	return l.setPage()
+}
+
+// setPage sets the items of the current page and prefetches the next one.
+func (l *Log) setPage() tea.Cmd {
+	p := l.pages[l.page]
+	cmd := l.selector.SetItems(p.items)
+	index := l.index
+	if index < 0 || index >= len(p.items) {
+		index = len(p.items) - 1
+	}
+	l.selector.Select(max(index, 0))
+	if i := l.selector.SelectedItem(); i != nil {
+		l.activeCommit = i.(LogItem).Commit
+	}
+	if l.activeView == logViewLoading {
+		l.activeView = logViewCommits
+	}
+	l.evictPages()
+	if p.more {
+		return tea.Batch(cmd, l.loadPage(l.page+1))
+	}
+	return cmd
+}
+
+// evictPages drops the pages farthest from the current one to keep at most
+// logCachedPages pages.
+func (l *Log) evictPages() {
+	for len(l.pages) > logCachedPages {
+		far := l.page
+		for p := range l.pages {
+			if abs(p-l.page) > abs(far-l.page) {
+				far = p
+			}
+		}
+		delete(l.pages, far)
+	}
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// pageKey returns the page and the item to show for a key or mouse wheel
+// event that goes past the first or last commit of the current page.
+func (l *Log) pageKey(msg tea.Msg) (page, index int, ok bool) {
+	km := l.selector.KeyMap
+	p, loaded := l.pages[l.page]
+	if !loaded {
+		return 0, 0, false
+	}
+	next := p.more
+	prev := l.page > 0
+	first := l.selector.Index() == 0
+	last := l.selector.Index() >= len(p.items)-1
+	var up, down bool
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		// The selector disables its page keys since it only has one page.
+		case key.Matches(msg, l.common.KeyMap.NextPage):
+			return l.page + 1, 0, next
+		case key.Matches(msg, l.common.KeyMap.PrevPage):
+			return l.page - 1, 0, prev
+		case key.Matches(msg, km.GoToStart):
+			return 0, 0, l.page != 0
+		case key.Matches(msg, km.GoToEnd):
+			// The last page is only known once commits are counted, the
+			// jump waits for the count until then.
+			if l.count == 0 {
+				l.endPending = true
+				return 0, 0, false
+			}
+			return l.lastPage(), -1, l.page != l.lastPage()
+		}
+		up = key.Matches(msg, km.CursorUp)
+		down = key.Matches(msg, km.CursorDown)
+	case tea.MouseClickMsg:
+		up = msg.Button == tea.MouseWheelUp
+		down = msg.Button == tea.MouseWheelDown
+	}
+	switch {
+	case down && last:
+		return l.page + 1, 0, next
+	case up && first:
+		return l.page - 1, -1, prev
+	}
+	return 0, 0, false
+}
+
+// lastPage returns the last page of commits, commits must be counted.
+func (l *Log) lastPage() int {
+	return int((l.count - 1) / int64(l.perPage))
+}
+
+// resized reloads the commits when the number of commits per page changes.
+func (l *Log) resized() tea.Cmd {
+	if l.repo == nil || l.ref == nil || max(l.selector.PerPage(), 1) == l.perPage {
+		return nil
+	}
+	first := l.page * l.perPage
+	l.resetPages()
+	page := first / l.perPage
+	if l.activeView == logViewDiff {
+		l.page = page
+		return l.loadPage(page)
+	}
+	return l.showPage(page, 0)
+}
+
 // Update implements tea.Model.
 func (l *Log) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 	cmds := make([]tea.Cmd, 0)
@@ -214,50 +372,51 @@ func (l *Log) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 		cmds = append(cmds, l.Init())
 	case LogCountMsg:
 		l.count = int64(msg)
-		l.selector.SetTotalPages(int(msg))
-		l.selector.SetItems(make([]selector.IdentifiableItem, l.count))
-		cmds = append(cmds, l.updateCommitsCmd)
+		if l.endPending && l.count > 0 && l.activeView != logViewDiff {
+			cmds = append(cmds, l.showPage(l.lastPage(), -1))
+		}
+		l.endPending = false
 	case LogItemsMsg:
-		// stop loading after receiving items
-		l.activeView = logViewCommits
-		cmds = append(cmds, l.selector.SetItems(msg))
-		l.selector.SetPage(l.nextPage)
-		l.SetSize(l.common.Width, l.common.Height)
-		i := l.selector.SelectedItem()
-		if i != nil {
-			l.activeCommit = i.(LogItem).Commit
+		// Drop pages of another ref or page size.
+		if msg.ref != l.ref || msg.perPage != l.perPage {
+			break
+		}
+		delete(l.loading, msg.page)
+		l.pages[msg.page] = msg.logPage
+		if msg.page == l.page && l.activeView != logViewDiff {
+			cmds = append(cmds, l.setPage())
+		} else {
+			l.evictPages()
 		}
 	case tea.KeyPressMsg, tea.MouseClickMsg:
 		switch l.activeView {
 		case logViewCommits:
+			// Moving the cursor cancels a jump waiting for the count.
+			l.endPending = false
 			switch kmsg := msg.(type) {
 			case tea.KeyPressMsg:
 				switch {
 				case key.Matches(kmsg, l.common.KeyMap.SelectItem):
 					cmds = append(cmds, l.selector.SelectItemCmd)
+				case key.Matches(kmsg, markBase):
+					l.toggleBase()
 				}
 			}
-			// XXX: This is a hack for loading commits on demand based on
-			// list.Pagination.
-			curPage := l.selector.Page()
-			s, cmd := l.selector.Update(msg)
-			m := s.(*selector.Selector)
-			l.selector = m
-			if m.Page() != curPage {
-				l.nextPage = m.Page()
-				l.selector.SetPage(curPage)
-				cmds = append(cmds,
-					l.updateCommitsCmd,
-					l.startLoading(),
-				)
+			// Pages are loaded as the cursor moves past the first or last
+			// commit of the current page.
+			if page, index, ok := l.pageKey(msg); ok {
+				cmds = append(cmds, l.showPage(page, index))
+				break
 			}
+			s, cmd := l.selector.Update(msg)
+			l.selector = s.(*selector.Selector)
 			cmds = append(cmds, cmd)
 		case logViewDiff:
 			switch kmsg := msg.(type) {
 			case tea.KeyPressMsg:
 				switch {
 				case key.Matches(kmsg, l.common.KeyMap.BackItem):
-					l.goBack()
+					cmds = append(cmds, l.goBack())
 				case key.Matches(kmsg, l.common.KeyMap.Copy):
 					if l.currentDiff != nil {
 						cmds = append(cmds, copyCmd(l.currentDiff.Patch(), "Commit diff copied to clipboard"))
@@ -266,7 +425,7 @@ func (l *Log) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 			}
 		}
 	case GoBackMsg:
-		l.goBack()
+		cmds = append(cmds, l.goBack())
 	case selector.ActiveMsg:
 		switch sel := msg.IdentifiableItem.(type) {
 		case LogItem:
@@ -285,47 +444,27 @@ func (l *Log) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 		cmds = append(cmds, l.loadDiffCmd)
 	case LogDiffMsg:
 		l.currentDiff = msg
-		l.vp.SetContent(
-			lipgloss.JoinVertical(lipgloss.Left,
-				l.renderCommit(l.selectedCommit),
-				renderSummary(msg, l.common.Styles, l.common.Width),
-				renderDiff(msg, l.common.Width),
-			),
-		)
-		l.vp.GotoTop()
+		l.dv.SetDiff(l.renderDiffHeader(), msg)
 		l.activeView = logViewDiff
 	case footer.ToggleFooterMsg:
-		cmds = append(cmds, l.updateCommitsCmd)
+		cmds = append(cmds, l.resized())
 	case tea.WindowSizeMsg:
 		l.SetSize(msg.Width, msg.Height)
 		if l.selectedCommit != nil && l.currentDiff != nil {
-			l.vp.SetContent(
-				lipgloss.JoinVertical(lipgloss.Left,
-					l.renderCommit(l.selectedCommit),
-					renderSummary(l.currentDiff, l.common.Styles, l.common.Width),
-					renderDiff(l.currentDiff, l.common.Width),
-				),
-			)
-		}
-		if l.repo != nil && l.ref != nil {
-			cmds = append(cmds,
-				l.updateCommitsCmd,
-				// start loading on resize since the number of commits per page
-				// might change and we'd need to load more commits.
-				l.startLoading(),
-			)
+			l.dv.SetHeader(l.renderDiffHeader())
 		}
+		// The number of commits per page might change.
+		cmds = append(cmds, l.resized())
 	case EmptyRepoMsg:
 		l.ref = nil
 		l.activeView = logViewCommits
-		l.nextPage = 0
+		l.resetPages()
 		l.count = 0
 		l.activeCommit = nil
 		l.selectedCommit = nil
+		l.baseCommit = nil
 		l.selector.Select(0)
-		cmds = append(cmds,
-			l.setItems([]selector.IdentifiableItem{}),
-		)
+		cmds = append(cmds, l.selector.SetItems([]selector.IdentifiableItem{}))
 	case spinner.TickMsg:
 		if l.activeView == logViewLoading && l.spinner.ID() == msg.ID {
 			s, cmd := l.spinner.Update(msg)
@@ -337,8 +476,8 @@ func (l *Log) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 	}
 	switch l.activeView {
 	case logViewDiff:
-		vp, cmd := l.vp.Update(msg)
-		l.vp = vp.(*viewport.Viewport)
+		dv, cmd := l.dv.Update(msg)
+		l.dv = dv.(*DiffView)
 		if cmd != nil {
 			cmds = append(cmds, cmd)
 		}
@@ -364,7 +503,7 @@ func (l *Log) View() string {
 	case logViewCommits:
 		return l.selector.View()
 	case logViewDiff:
-		return l.vp.View()
+		return l.dv.View()
 	default:
 		return ""
 	}
@@ -392,33 +531,42 @@ func (l *Log) StatusBarValue() string {
 	if who != "" {
 		value += " by " + who
 	}
+	if b := l.baseCommit; b != nil && l.activeView == logViewCommits {
+		value = fmt.Sprintf("base %s • %s", b.ID.String()[:7], value)
+	}
 	return value
 }
 
 // StatusBarInfo returns the status bar info.
 func (l *Log) StatusBarInfo() string {
 	switch l.activeView {
-	case logViewLoading:
-		if l.count == 0 {
+	case logViewLoading, logViewCommits:
+		if l.ref == nil {
 			return ""
 		}
-		fallthrough
-	case logViewCommits:
-		// We're using l.nextPage instead of l.selector.Paginator.Page because
-		// of the paginator hack above.
-		return fmt.Sprintf("p. %d/%d", l.nextPage+1, l.selector.TotalPages())
+		if l.count == 0 {
+			return fmt.Sprintf("p. %d", l.page+1)
+		}
+		pages := (l.count + int64(l.perPage) - 1) / int64(l.perPage)
+		return fmt.Sprintf("p. %d/%d", l.page+1, pages)
 	case logViewDiff:
-		return fmt.Sprintf("☰ %.f%%", l.vp.ScrollPercent()*100)
+		return fmt.Sprintf("☰ %.f%%", l.dv.ScrollPercent()*100)
 	default:
 		return ""
 	}
 }
 
-func (l *Log) goBack() {
-	if l.activeView == logViewDiff {
-		l.activeView = logViewCommits
-		l.selectedCommit = nil
+func (l *Log) goBack() tea.Cmd {
+	if l.activeView != logViewDiff {
+		return nil
+	}
+	l.activeView = logViewCommits
+	l.selectedCommit = nil
+	// The page might have been reloaded while showing the diff.
+	if l.ref == nil {
+		return nil
 	}
+	return l.showPage(l.page, l.selector.Index())
 }
 
 func (l *Log) countCommitsCmd() tea.Msg {
@@ -437,39 +585,35 @@ func (l *Log) countCommitsCmd() tea.Msg {
 	return LogCountMsg(count)
 }
 
-func (l *Log) updateCommitsCmd() tea.Msg {
-	if l.ref == nil {
+// loadPage returns a command loading a page of commits, unless it's loaded
+// or being loaded.
+func (l *Log) loadPage(page int) tea.Cmd {
+	if _, ok := l.pages[page]; ok || l.loading[page] || l.ref == nil {
 		return nil
 	}
-	r, err := l.repo.Open()
-	if err != nil {
-		return common.ErrorMsg(err)
-	}
-
-	count := l.count
-	if count == 0 {
-		return LogItemsMsg([]selector.IdentifiableItem{})
-	}
-
-	page := l.nextPage
-	limit := l.selector.PerPage()
-	skip := page * limit
-	ref := l.ref
-	items := make([]selector.IdentifiableItem, count)
-	// CommitsByPage pages start at 1
-	cc, err := r.CommitsByPage(ref, page+1, limit)
-	if err != nil {
-		l.common.Logger.Debugf("ui: error loading commits: %v", err)
-		return common.ErrorMsg(err)
-	}
-	for i, c := range cc {
-		idx := i + skip
-		if int64(idx) >= count {
-			break
+	l.loading[page] = true
+	repo, ref, perPage := l.repo, l.ref, l.perPage
+	return func() tea.Msg {
+		r, err := repo.Open()
+		if err != nil {
+			return common.ErrorMsg(err)
+		}
+		// Load one more commit to know if there's a next page without
+		// counting commits.
+		cc, err := r.CommitsAfter(ref, page*perPage, perPage+1)
+		if err != nil {
+			l.common.Logger.Debugf("ui: error loading commits: %v", err)
+			return common.ErrorMsg(err)
+		}
+		msg := LogItemsMsg{ref: ref, page: page, perPage: perPage}
+		msg.more = len(cc) > perPage
+		cc = cc[:min(len(cc), perPage)]
+		msg.items = make([]selector.IdentifiableItem, len(cc))
+		for i, c := range cc {
+			msg.items[i] = LogItem{Commit: c}
 		}
-		items[idx] = LogItem{Commit: c}
+		return msg
 	}
-	return LogItemsMsg(items)
 }
 
 func (l *Log) selectCommitCmd(commit *git.Commit) tea.Cmd {
@@ -487,7 +631,12 @@ func (l *Log) loadDiffCmd() tea.Msg {
 		l.common.Logger.Debugf("ui: error loading diff repository: %v", err)
 		return common.ErrorMsg(err)
 	}
-	diff, err := r.Diff(l.selectedCommit)
+	var diff *git.Diff
+	if b := l.baseCommit; b != nil && b.ID.String() != l.selectedCommit.ID.String() {
+		diff, err = r.DiffRange(b.ID.String(), l.selectedCommit.ID.String())
+	} else {
+		diff, err = r.Diff(l.selectedCommit)
+	}
 	if err != nil {
 		l.common.Logger.Debugf("ui: error loading diff: %v", err)
 		return common.ErrorMsg(err)
@@ -495,6 +644,34 @@ func (l *Log) loadDiffCmd() tea.Msg {
 	return LogDiffMsg(diff)
 }
 
+// toggleBase marks the active commit as the base of the diffs of selected
+// commits, or unmarks it if it's already the base.
+func (l *Log) toggleBase() {
+	c := l.activeCommit
+	if c == nil || (l.baseCommit != nil && l.baseCommit.ID.String() == c.ID.String()) {
+		l.baseCommit = nil
+		return
+	}
+	l.baseCommit = c
+}
+
+// renderDiffHeader renders the selected commit and the diff summary, along
+// with the compared range when there's a base commit.
+func (l *Log) renderDiffHeader() string {
+	header := l.renderCommit(l.selectedCommit)
+	if b := l.baseCommit; b != nil && b.ID.String() != l.selectedCommit.ID.String() {
+		header = lipgloss.JoinVertical(lipgloss.Left,
+			l.common.Styles.Log.CommitHash.Render(fmt.Sprintf("diff %s..%s",
+				b.ID.String()[:7], l.selectedCommit.ID.String()[:7])),
+			header,
+		)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		renderSummary(l.currentDiff, l.common.Styles, l.common.Width),
+	)
+}
+
 func (l *Log) renderCommit(c *git.Commit) string {
 	s := strings.Builder{}
 	// FIXME: lipgloss prints empty lines when CRLF is used
@@ -524,10 +701,14 @@ func renderSummary(diff *git.Diff, styles *styles.Styles, width int) string {
 }
 
 func renderDiff(diff *git.Diff, width int) string {
+	return renderPatch(diff.Patch(), width)
+}
+
+func renderPatch(patch string, width int) string {
 	var s strings.Builder
 	var pr strings.Builder
 	diffChroma := &gansi.CodeBlockElement{
-		Code:     diff.Patch(),
+		Code:     patch,
 		Language: "diff",
 	}
 	err := diffChroma.Render(&pr, common.StyleRenderer())
@@ -538,9 +719,3 @@ func renderDiff(diff *git.Diff, width int) string {
 	}
 	return wrap.String(s.String(), width)
 }
-
-func (l *Log) setItems(items []selector.IdentifiableItem) tea.Cmd {
-	return func() tea.Msg {
-		return LogItemsMsg(items)
-	}
-}
@@ -0,0 +1,231 @@
+package repo
+
+import (
+	"fmt"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/list"
+	"charm.land/bubbles/v2/spinner"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/ui/common"
+	"github.com/charmbracelet/soft-serve/pkg/ui/components/selector"
+)
+
+var switchRef = key.NewBinding(
+	key.WithKeys("r"),
+	key.WithHelp("r", "switch ref"),
+)
+
+// RefPickerItemsMsg is a message that contains the branches and tags of the
+// ref picker.
+type RefPickerItemsMsg []selector.IdentifiableItem
+
+// RefPicker is a searchable list of the branches and tags of a repository to
+// switch the ref the files, log, and readme are shown for.
+type RefPicker struct {
+	common   common.Common
+	selector *selector.Selector
+	spinner  spinner.Model
+	repo     proto.Repository
+	ref      *git.Reference
+	open     bool
+	loading  bool
+}
+
+// NewRefPicker creates a new RefPicker.
+func NewRefPicker(common common.Common) *RefPicker {
+	p := &RefPicker{
+		common: common,
+	}
+	s := selector.New(common, []selector.IdentifiableItem{}, RefItemDelegate{&common})
+	s.SetShowHelp(false)
+	s.SetShowPagination(false)
+	s.SetShowStatusBar(false)
+	s.SetShowTitle(false)
+	s.DisableQuitKeybindings()
+	s.KeyMap.NextPage = common.KeyMap.NextPage
+	s.KeyMap.PrevPage = common.KeyMap.PrevPage
+	p.selector = s
+	p.spinner = spinner.New(spinner.WithSpinner(spinner.Dot),
+		spinner.WithStyle(common.Styles.Spinner))
+	return p
+}
+
+// IsOpen returns whether the picker is shown.
+func (p *RefPicker) IsOpen() bool {
+	return p.open
+}
+
+// IsFiltering returns whether the ref list is being filtered.
+func (p *RefPicker) IsFiltering() bool {
+	return p.open && p.selector.FilterState() == list.Filtering
+}
+
+// Open shows the picker and loads the refs of the repository. Input is
+// ignored until they're loaded, so filters aren't typed in an empty list.
+func (p *RefPicker) Open() tea.Cmd {
+	p.open = true
+	p.loading = true
+	p.selector.ResetFilter()
+	return tea.Batch(p.spinner.Tick, p.updateItemsCmd)
+}
+
+// Close hides the picker.
+func (p *RefPicker) Close() {
+	p.open = false
+}
+
+// SetSize implements common.Component.
+func (p *RefPicker) SetSize(width, height int) {
+	p.common.SetSize(width, height)
+	p.selector.SetSize(width, height)
+}
+
+// ShortHelp implements help.KeyMap.
+func (p *RefPicker) ShortHelp() []key.Binding {
+	k := p.selector.KeyMap
+	if p.IsFiltering() {
+		return []key.Binding{
+			k.CancelWhileFiltering,
+			k.AcceptWhileFiltering,
+		}
+	}
+	back := p.common.KeyMap.Back
+	back.SetHelp("esc", "close")
+	return []key.Binding{
+		p.common.KeyMap.Select,
+		back,
+		k.Filter,
+		k.CursorUp,
+		k.CursorDown,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (p *RefPicker) FullHelp() [][]key.Binding {
+	k := p.selector.KeyMap
+	if p.IsFiltering() {
+		return [][]key.Binding{{
+			k.CancelWhileFiltering,
+			k.AcceptWhileFiltering,
+		}}
+	}
+	back := p.common.KeyMap.Back
+	back.SetHelp("esc", "close")
+	return [][]key.Binding{
+		{
+			p.common.KeyMap.Select,
+			back,
+			k.Filter,
+		},
+		{
+			k.CursorUp,
+			k.CursorDown,
+			k.NextPage,
+			k.PrevPage,
+		},
+		{
+			k.GoToStart,
+			k.GoToEnd,
+		},
+	}
+}
+
+// Update implements tea.Model.
+func (p *RefPicker) Update(msg tea.Msg) (*RefPicker, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+	switch msg := msg.(type) {
+	case RepoMsg:
+		p.repo = msg
+		p.open = false
+	case RefMsg:
+		p.ref = msg
+	case RefPickerItemsMsg:
+		p.loading = false
+		cmds = append(cmds, p.selector.SetItems(msg))
+		p.selector.Select(0)
+		if p.ref != nil {
+			for i, item := range msg {
+				if item.ID() == p.ref.Name().String() {
+					p.selector.Select(i)
+					break
+				}
+			}
+		}
+	case selector.SelectMsg:
+		if item, ok := msg.IdentifiableItem.(RefItem); ok {
+			p.open = false
+			cmds = append(cmds, switchRefCmd(item.Reference))
+		}
+		return p, tea.Batch(cmds...)
+	case spinner.TickMsg:
+		if p.loading && p.spinner.ID() == msg.ID {
+			s, cmd := p.spinner.Update(msg)
+			p.spinner = s
+			return p, cmd
+		}
+		return p, nil
+	case tea.KeyPressMsg:
+		if !p.IsFiltering() && key.Matches(msg, p.common.KeyMap.Back) {
+			p.open = false
+			return p, nil
+		}
+		if p.loading {
+			return p, nil
+		}
+	case common.ErrorMsg:
+		// The refs failed to load.
+		if p.loading {
+			p.open, p.loading = false, false
+		}
+		return p, nil
+	}
+	filtering := p.IsFiltering()
+	m, cmd := p.selector.Update(msg)
+	p.selector = m.(*selector.Selector)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	// Switch to the highlighted ref right away when accepting a filter.
+	if msg, ok := msg.(tea.KeyPressMsg); ok && filtering &&
+		key.Matches(msg, p.common.KeyMap.Select) &&
+		p.selector.FilterState() == list.FilterApplied {
+		cmds = append(cmds, p.selector.SelectItemCmd)
+	}
+	return p, tea.Batch(cmds...)
+}
+
+// View implements tea.Model.
+func (p *RefPicker) View() string {
+	if p.loading {
+		return renderLoading(p.common, p.spinner)
+	}
+	return p.selector.View()
+}
+
+// StatusBarValue returns the status bar value.
+func (p *RefPicker) StatusBarValue() string {
+	return "switch ref"
+}
+
+// StatusBarInfo returns the status bar info.
+func (p *RefPicker) StatusBarInfo() string {
+	if p.loading {
+		return ""
+	}
+	return fmt.Sprintf("# %d/%d", p.selector.Index()+1, len(p.selector.VisibleItems()))
+}
+
+func (p *RefPicker) updateItemsCmd() tea.Msg {
+	branches, err := refItems(p.repo, git.RefsHeads)
+	if err != nil {
+		return common.ErrorMsg(err)
+	}
+	tags, err := refItems(p.repo, git.RefsTags)
+	if err != nil {
+		return common.ErrorMsg(err)
+	}
+	return RefPickerItemsMsg(append(branches, tags...))
+}
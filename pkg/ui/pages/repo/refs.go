@@ -209,30 +209,40 @@ func (r *Refs) StatusBarInfo() string {
 }
 
 func (r *Refs) updateItemsCmd() tea.Msg {
-	its := make(RefItems, 0)
-	rr, err := r.repo.Open()
+	items, err := refItems(r.repo, r.refPrefix)
 	if err != nil {
+		r.common.Logger.Debugf("ui: error getting references: %v", err)
 		return common.ErrorMsg(err)
 	}
+	return RefItemsMsg{
+		items:  items,
+		prefix: r.refPrefix,
+	}
+}
+
+// refItems returns the references of the repository starting with prefix,
+// the most recently updated first.
+func refItems(repo proto.Repository, prefix string) ([]selector.IdentifiableItem, error) {
+	its := make(RefItems, 0)
+	rr, err := repo.Open()
+	if err != nil {
+		return nil, err
+	}
 	refs, err := rr.References()
 	if err != nil {
-		r.common.Logger.Debugf("ui: error getting references: %v", err)
-		return common.ErrorMsg(err)
+		return nil, err
+	}
+	var head string
+	if ref, err := rr.HEAD(); err == nil {
+		head = ref.Name().String()
 	}
 	for _, ref := range refs {
-		if strings.HasPrefix(ref.Name().String(), r.refPrefix) {
+		if strings.HasPrefix(ref.Name().String(), prefix) {
 			refItem := RefItem{
 				Reference: ref,
+				isDefault: ref.Name().String() == head,
 			}
-
-			if ref.IsTag() {
-				refItem.Tag, _ = rr.Tag(ref.Name().Short())
-				if refItem.Tag != nil {
-					refItem.Commit, _ = refItem.Tag.Commit()
-				}
-			} else {
-				refItem.Commit, _ = rr.CatFileCommit(ref.ID)
-			}
+			refItem.Tag, refItem.Commit, _ = rr.RefCommit(ref)
 			its = append(its, refItem)
 		}
 	}
@@ -241,10 +251,7 @@ func (r *Refs) updateItemsCmd() tea.Msg {
 	for i, it := range its {
 		items[i] = it
 	}
-	return RefItemsMsg{
-		items:  items,
-		prefix: r.refPrefix,
-	}
+	return items, nil
 }
 
 func (r *Refs) setItems(items []selector.IdentifiableItem) tea.Cmd {
@@ -21,6 +21,8 @@ type RefItem struct {
 	*git.Reference
 	*git.Tag
 	*git.Commit
+	// isDefault is whether the reference is the default branch.
+	isDefault bool
 }
 
 // ID implements selector.IdentifiableItem.
@@ -128,6 +130,10 @@ func (d RefItemDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 	}
 
 	ref := i.Short()
+	var label string
+	if i.isDefault {
+		label = " " + s.ItemDefault.Render("default")
+	}
 
 	var desc string
 	if isTag {
@@ -153,6 +159,7 @@ func (d RefItemDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 					horizontalFrameSize -
 					lipgloss.Width(selector) -
 					lipgloss.Width(ref) -
+					lipgloss.Width(label) -
 					lipgloss.Width(desc) -
 					lipgloss.Width(sha) -
 					3 // 3 is for the paddings and truncation symbol
@@ -167,6 +174,7 @@ func (d RefItemDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 			horizontalFrameSize -
 			lipgloss.Width(selector) -
 			lipgloss.Width(ref) -
+			lipgloss.Width(label) -
 			lipgloss.Width(desc) -
 			lipgloss.Width(sha) -
 			2 // 2 is for the padding and truncation symbol
@@ -182,6 +190,7 @@ func (d RefItemDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 		horizontalFrameSize -
 		lipgloss.Width(selector) -
 		lipgloss.Width(ref) -
+		lipgloss.Width(label) -
 		lipgloss.Width(desc) -
 		lipgloss.Width(sha) -
 		1 // 1 is for the left padding
@@ -196,7 +205,7 @@ func (d RefItemDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 			i.ID(),
 			st.Base.Render(
 				lipgloss.JoinHorizontal(lipgloss.Top,
-					truncate.String(selector+ref+desc+hash,
+					truncate.String(selector+ref+label+desc+hash,
 						uint(m.Width()-horizontalFrameSize)), //nolint:gosec
 				),
 			),
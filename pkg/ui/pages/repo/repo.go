@@ -6,16 +6,19 @@ import (
 
 	"charm.land/bubbles/v2/help"
 	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/list"
 	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/footer"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/selector"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/statusbar"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/tabs"
+	"github.com/dustin/go-humanize"
 )
 
 type state int
@@ -50,10 +53,14 @@ type SwitchTabMsg common.TabComponent
 type Repo struct {
 	common       common.Common
 	selectedRepo proto.Repository
+	topics       []string
+	stats        *models.RepoStats
+	languages    []git.Language
 	activeTab    int
 	tabs         *tabs.Tabs
 	statusbar    *statusbar.Model
 	panes        []common.TabComponent
+	refPicker    *RefPicker
 	ref          *git.Reference
 	state        state
 	spinner      spinner.Model
@@ -77,6 +84,7 @@ func New(c common.Common, comps ...common.TabComponent) *Repo {
 		tabs:       tb,
 		statusbar:  sb,
 		panes:      comps,
+		refPicker:  NewRefPicker(c),
 		state:      loadingState,
 		spinner:    s,
 		panesReady: make([]bool, len(comps)),
@@ -102,13 +110,22 @@ func (r *Repo) SetSize(width, height int) {
 	for _, p := range r.panes {
 		p.SetSize(width, height-hm)
 	}
+	r.refPicker.SetSize(width, height-hm)
 }
 
 // Path returns the current component path.
 func (r *Repo) Path() string {
+	if r.refPicker.IsOpen() {
+		return "refs"
+	}
 	return r.panes[r.activeTab].Path()
 }
 
+// IsFiltering returns true if the ref picker is filtering.
+func (r *Repo) IsFiltering() bool {
+	return r.refPicker.IsFiltering()
+}
+
 func (r *Repo) commonHelp() []key.Binding {
 	b := make([]key.Binding, 0)
 	back := r.common.KeyMap.Back
@@ -117,11 +134,17 @@ func (r *Repo) commonHelp() []key.Binding {
 	tab.SetHelp("tab", "switch tab")
 	b = append(b, back)
 	b = append(b, tab)
+	if r.ref != nil {
+		b = append(b, switchRef)
+	}
 	return b
 }
 
 // ShortHelp implements help.KeyMap.
 func (r *Repo) ShortHelp() []key.Binding {
+	if r.refPicker.IsOpen() {
+		return r.refPicker.ShortHelp()
+	}
 	b := r.commonHelp()
 	b = append(b, r.panes[r.activeTab].(help.KeyMap).ShortHelp()...)
 	return b
@@ -129,6 +152,9 @@ func (r *Repo) ShortHelp() []key.Binding {
 
 // FullHelp implements help.KeyMap.
 func (r *Repo) FullHelp() [][]key.Binding {
+	if r.refPicker.IsOpen() {
+		return r.refPicker.FullHelp()
+	}
 	b := make([][]key.Binding, 0)
 	b = append(b, r.commonHelp())
 	b = append(b, r.panes[r.activeTab].(help.KeyMap).FullHelp()...)
@@ -149,10 +175,45 @@ func (r *Repo) Init() tea.Cmd {
 // Update implements tea.Model.
 func (r *Repo) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 	cmds := make([]tea.Cmd, 0)
+	if r.refPicker.IsOpen() {
+		switch msg.(type) {
+		case tea.KeyPressMsg, tea.MouseClickMsg, tea.MouseWheelMsg,
+			selector.SelectMsg, selector.ActiveMsg, list.FilterMatchesMsg:
+			// The ref picker takes the input while it's open.
+			p, cmd := r.refPicker.Update(msg)
+			r.refPicker = p
+			r.setStatusBarInfo()
+			return r, cmd
+		}
+	}
+	switch msg := msg.(type) {
+	case RepoMsg, RefMsg, RefPickerItemsMsg, spinner.TickMsg, common.ErrorMsg:
+		p, cmd := r.refPicker.Update(msg)
+		r.refPicker = p
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
 	switch msg := msg.(type) {
 	case RepoMsg:
 		// Set the state to loading when we get a new repository.
 		r.selectedRepo = msg
+		topics, err := r.common.Backend().RepoTopics(r.common.Context(), msg.Name())
+		if err != nil {
+			r.common.Logger.Debugf("ui: failed to get topics of %s: %v", msg.Name(), err)
+		}
+		r.topics = topics
+		r.stats = nil
+		if stats, err := r.common.Backend().RepoStats(r.common.Context(), msg); err == nil {
+			r.stats = &stats
+		} else {
+			r.common.Logger.Debugf("ui: failed to get stats of %s: %v", msg.Name(), err)
+		}
+		languages, err := r.common.Backend().RepoLanguages(r.common.Context(), msg)
+		if err != nil {
+			r.common.Logger.Debugf("ui: failed to get languages of %s: %v", msg.Name(), err)
+		}
+		r.languages = languages
 		cmds = append(cmds,
 			r.Init(),
 			// This will set the selected repo in each pane's model.
@@ -204,6 +265,8 @@ func (r *Repo) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 			switch {
 			case key.Matches(msg, r.common.KeyMap.Back):
 				cmds = append(cmds, goBackCmd)
+			case key.Matches(msg, switchRef) && r.state == readyState && r.ref != nil:
+				cmds = append(cmds, r.refPicker.Open())
 			}
 		}
 	case CopyMsg:
@@ -214,7 +277,7 @@ func (r *Repo) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 		r.statusbar.SetStatus("", msg.Message, "", "")
 	case ReadmeMsg:
 		cmds = append(cmds, r.updateTabComponent(&Readme{}, msg))
-	case FileItemsMsg, FileContentMsg:
+	case FileItemsMsg, FileTreeMsg, FileContentMsg:
 		cmds = append(cmds, r.updateTabComponent(&Files{}, msg))
 	case LogItemsMsg, LogDiffMsg, LogCountMsg:
 		cmds = append(cmds, r.updateTabComponent(&Log{}, msg))
@@ -272,9 +335,9 @@ func (r *Repo) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 	// Must come after we've updated the active tab
 	switch msg.(type) {
 	case RepoMsg, RefMsg, tabs.ActiveTabMsg, tea.KeyPressMsg,
-		tea.MouseClickMsg, tea.MouseWheelMsg, FileItemsMsg, FileContentMsg,
+		tea.MouseClickMsg, tea.MouseWheelMsg, FileItemsMsg, FileTreeMsg, FileContentMsg,
 		FileBlameMsg, selector.ActiveMsg, LogItemsMsg, GoBackMsg, LogDiffMsg,
-		EmptyRepoMsg, StashListMsg, StashPatchMsg:
+		EmptyRepoMsg, StashListMsg, StashPatchMsg, RefPickerItemsMsg:
 		r.setStatusBarInfo()
 	}
 
@@ -304,6 +367,9 @@ func (r *Repo) View() string {
 		main = fmt.Sprintf("%s loading…", r.spinner.View())
 	case readyState:
 		main = r.panes[r.activeTab].View()
+		if r.refPicker.IsOpen() {
+			main = r.refPicker.View()
+		}
 		statusbar = r.statusbar.View()
 	}
 	main = r.common.Zone.Mark(
@@ -329,12 +395,25 @@ func (r *Repo) headerView() string {
 		header = r.selectedRepo.Name()
 	}
 	header = r.common.Styles.Repo.HeaderName.Render(header)
+	if r.selectedRepo.IsArchived() {
+		header += " " + r.common.Styles.Repo.HeaderArchived.Render("Archived")
+	}
 	desc := strings.TrimSpace(r.selectedRepo.Description())
 	if desc != "" {
-		header = lipgloss.JoinVertical(lipgloss.Left,
-			header,
-			r.common.Styles.Repo.HeaderDesc.Render(desc),
-		)
+		desc = r.common.Styles.Repo.HeaderDesc.Render(desc)
+	}
+	if len(r.topics) > 0 {
+		topics := make([]string, len(r.topics))
+		for i, t := range r.topics {
+			topics[i] = "#" + t
+		}
+		if desc != "" {
+			desc += " "
+		}
+		desc += r.common.Styles.Repo.HeaderTopics.Render(strings.Join(topics, " "))
+	}
+	if desc != "" {
+		header = lipgloss.JoinVertical(lipgloss.Left, header, desc)
 	}
 	urlStyle := r.common.Styles.URLStyle.
 		Width(r.common.Width - lipgloss.Width(header) - 1).
@@ -349,6 +428,20 @@ func (r *Repo) headerView() string {
 		urlStyle.Render(url),
 	)
 
+	if r.stats != nil {
+		stats := []string{humanize.Bytes(uint64(r.stats.Size))} //nolint:gosec
+		stats = append(stats, fmt.Sprintf("%d commits", r.stats.Commits))
+		if r.stats.LastPushedAt.Valid {
+			stats = append(stats, "pushed "+humanize.Time(r.stats.LastPushedAt.Time))
+		}
+		// Only the main languages fit in the header.
+		for _, l := range r.languages[:min(len(r.languages), 3)] {
+			stats = append(stats, fmt.Sprintf("%s %.1f%%", l.Name, l.Percent))
+		}
+		info := common.TruncateString(strings.Join(stats, " · "), r.common.Width-lipgloss.Width(header)-1)
+		url = lipgloss.JoinVertical(lipgloss.Right, url, urlStyle.Render(r.common.Styles.Repo.HeaderDesc.Render(info)))
+	}
+
 	header = lipgloss.JoinHorizontal(lipgloss.Top, header, url)
 
 	style := r.common.Styles.Repo.Header.Width(r.common.Width)
@@ -366,6 +459,10 @@ func (r *Repo) setStatusBarInfo() {
 	key := r.selectedRepo.Name()
 	value := active.StatusBarValue()
 	info := active.StatusBarInfo()
+	if r.refPicker.IsOpen() {
+		value = r.refPicker.StatusBarValue()
+		info = r.refPicker.StatusBarInfo()
+	}
 	extra := "*"
 	if r.ref != nil {
 		extra += " " + r.ref.Name().Short()
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/list"
@@ -48,12 +49,13 @@ func (it Items) Swap(i int, j int) {
 // Item represents a single item in the selector.
 type Item struct {
 	repo       proto.Repository
+	topics     []string
 	lastUpdate *time.Time
 	cmd        string
 }
 
 // New creates a new Item.
-func NewItem(c common.Common, repo proto.Repository) (Item, error) {
+func NewItem(c common.Common, repo proto.Repository, topics []string) (Item, error) {
 	var lastUpdate *time.Time
 	lu := repo.UpdatedAt()
 	if !lu.IsZero() {
@@ -65,6 +67,7 @@ func NewItem(c common.Common, repo proto.Repository) (Item, error) {
 	}
 	return Item{
 		repo:       repo,
+		topics:     topics,
 		lastUpdate: lastUpdate,
 		cmd:        cmd,
 	}, nil
@@ -88,8 +91,20 @@ func (i Item) Title() string {
 // Description returns the item description. Implements list.DefaultItem.
 func (i Item) Description() string { return strings.TrimSpace(i.repo.Description()) }
 
-// FilterValue implements list.Item.
-func (i Item) FilterValue() string { return i.Title() }
+// Topics returns the item topics, each prefixed with a hash and separated by
+// spaces.
+func (i Item) Topics() string {
+	topics := make([]string, len(i.topics))
+	for j, t := range i.topics {
+		topics[j] = "#" + t
+	}
+
+	return strings.Join(topics, " ")
+}
+
+// FilterValue implements list.Item. Items are filtered by title,
+// description, and topics, separated by spaces.
+func (i Item) FilterValue() string { return i.Title() + " " + i.Description() + " " + i.Topics() }
 
 // Command returns the item Command view.
 func (i Item) Command() string {
@@ -170,6 +185,12 @@ func (d *ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 	if i.repo.IsPrivate() {
 		title += " 🔒"
 	}
+	if i.repo.IsArchived() {
+		title += " (archived)"
+	}
+	if i.repo.IsTemplate() {
+		title += " (template)"
+	}
 	if isSelected {
 		title += " "
 	}
@@ -190,15 +211,52 @@ func (d *ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 		matchedRunes = m.MatchesForItem(index)
 	}
 
+	// Matches past the title and the separator are in the description, and
+	// past the description and the separator in the topics.
+	var titleRunes, descRunes, topicRunes []int
+	titleLen := utf8.RuneCountInString(i.Title())
+	descLen := utf8.RuneCountInString(i.Description())
+	for _, r := range matchedRunes {
+		switch {
+		case r < titleLen:
+			titleRunes = append(titleRunes, r)
+		case r > titleLen && r <= titleLen+descLen:
+			descRunes = append(descRunes, r-titleLen-1)
+		case r > titleLen+descLen+1:
+			topicRunes = append(topicRunes, r-titleLen-descLen-2)
+		}
+	}
+
 	if isFiltered {
 		unmatched := styles.Title.Inline(true)
 		matched := unmatched.Underline(true)
-		title = lipgloss.StyleRunes(title, matchedRunes, matched, unmatched)
+		title = lipgloss.StyleRunes(title, titleRunes, matched, unmatched)
 	}
 	title = styles.Title.Render(title)
 	desc := i.Description()
 	desc = common.TruncateString(desc, m.Width()-styles.Base.GetHorizontalFrameSize())
+	if isFiltered {
+		unmatched := styles.Desc.Inline(true)
+		matched := unmatched.Underline(true)
+		desc = lipgloss.StyleRunes(desc, descRunes, matched, unmatched)
+	}
 	desc = styles.Desc.Render(desc)
+	if topics := i.Topics(); topics != "" {
+		// Topics follow the description when there's room left.
+		if w := m.Width() - styles.Base.GetHorizontalFrameSize() - lipgloss.Width(desc); w > 1 {
+			if desc != "" {
+				desc += " "
+				w--
+			}
+			topics = common.TruncateString(topics, w)
+			if isFiltered {
+				unmatched := styles.Topics.Inline(true)
+				matched := unmatched.Underline(true)
+				topics = lipgloss.StyleRunes(topics, topicRunes, matched, unmatched)
+			}
+			desc += styles.Topics.Render(topics)
+		}
+	}
 
 	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Bottom, title, updated))
 	s.WriteRune('\n')
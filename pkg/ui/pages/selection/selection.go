@@ -213,9 +213,16 @@ func (s *Selection) Init() tea.Cmd {
 		if r.IsHidden() {
 			continue
 		}
+		if r.IsArchived() && cfg.Repo.HideArchived {
+			continue
+		}
 		al := be.AccessLevelByPublicKey(ctx, r.Name(), pk)
 		if al >= access.ReadOnlyAccess {
-			item, err := NewItem(s.common, r)
+			topics, err := be.RepoTopics(ctx, r.Name())
+			if err != nil {
+				s.common.Logger.Debugf("ui: failed to get topics of %s: %v", r.Name(), err)
+			}
+			item, err := NewItem(s.common, r, topics)
 			if err != nil {
 				s.common.Logger.Debugf("ui: failed to create item for %s: %v", r.Name(), err)
 				continue
@@ -274,11 +281,19 @@ func (s *Selection) Update(msg tea.Msg) (common.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 		}
 	case selectorPane:
+		filtering := s.IsFiltering()
 		m, cmd := s.selector.Update(msg)
 		s.selector = m.(*selector.Selector)
 		if cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+		// Open the highlighted repository right away when accepting a
+		// filter.
+		if msg, ok := msg.(tea.KeyPressMsg); ok && filtering &&
+			key.Matches(msg, s.common.KeyMap.Select) &&
+			s.FilterState() == list.FilterApplied {
+			cmds = append(cmds, s.selector.SelectItemCmd)
+		}
 	}
 	return s, tea.Batch(cmds...)
 }
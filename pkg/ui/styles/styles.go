@@ -16,6 +16,7 @@ type Styles struct {
 
 	App                  lipgloss.Style
 	ServerName           lipgloss.Style
+	MOTD                 lipgloss.Style
 	TopLevelNormalTab    lipgloss.Style
 	TopLevelActiveTab    lipgloss.Style
 	TopLevelActiveTabDot lipgloss.Style
@@ -30,6 +31,7 @@ type Styles struct {
 			Desc    lipgloss.Style
 			Command lipgloss.Style
 			Updated lipgloss.Style
+			Topics  lipgloss.Style
 		}
 		Active struct {
 			Base    lipgloss.Style
@@ -37,17 +39,20 @@ type Styles struct {
 			Desc    lipgloss.Style
 			Command lipgloss.Style
 			Updated lipgloss.Style
+			Topics  lipgloss.Style
 		}
 	}
 
 	Repo struct {
-		Base       lipgloss.Style
-		Title      lipgloss.Style
-		Command    lipgloss.Style
-		Body       lipgloss.Style
-		Header     lipgloss.Style
-		HeaderName lipgloss.Style
-		HeaderDesc lipgloss.Style
+		Base           lipgloss.Style
+		Title          lipgloss.Style
+		Command        lipgloss.Style
+		Body           lipgloss.Style
+		Header         lipgloss.Style
+		HeaderName     lipgloss.Style
+		HeaderDesc     lipgloss.Style
+		HeaderTopics   lipgloss.Style
+		HeaderArchived lipgloss.Style
 	}
 
 	Footer      lipgloss.Style
@@ -86,6 +91,7 @@ type Styles struct {
 		CommitBody     lipgloss.Style
 		CommitStatsAdd lipgloss.Style
 		CommitStatsDel lipgloss.Style
+		DiffFile       lipgloss.Style
 		Paginator      lipgloss.Style
 	}
 
@@ -105,6 +111,7 @@ type Styles struct {
 			ItemHash lipgloss.Style
 		}
 		ItemSelector lipgloss.Style
+		ItemDefault  lipgloss.Style
 		Paginator    lipgloss.Style
 		Selector     lipgloss.Style
 	}
@@ -168,15 +175,15 @@ type Styles struct {
 
 // DefaultStyles returns default styles for the UI.
 func DefaultStyles() *Styles {
-	highlightColor := lipgloss.Color("210")
-	highlightColorDim := lipgloss.Color("174")
-	selectorColor := lipgloss.Color("167")
-	hashColor := lipgloss.Color("185")
+	return NewStyles(DarkTheme())
+}
 
+// NewStyles returns the styles of the UI colored with the theme.
+func NewStyles(t Theme) *Styles {
 	s := new(Styles)
 
-	s.ActiveBorderColor = lipgloss.Color("62")
-	s.InactiveBorderColor = lipgloss.Color("241")
+	s.ActiveBorderColor = t.Accent
+	s.InactiveBorderColor = t.Muted
 
 	s.App = lipgloss.NewStyle().
 		Margin(1, 2)
@@ -186,50 +193,64 @@ func DefaultStyles() *Styles {
 		MarginLeft(1).
 		MarginBottom(1).
 		Padding(0, 1).
-		Background(lipgloss.Color("57")).
-		Foreground(lipgloss.Color("229")).
+		Background(t.Accent).
+		Foreground(t.AccentText).
 		Bold(true)
 
+	s.MOTD = lipgloss.NewStyle().
+		MarginLeft(1).
+		MarginBottom(1).
+		Padding(0, 1).
+		Foreground(t.Text)
+
 	s.TopLevelNormalTab = lipgloss.NewStyle().
 		MarginRight(2)
 
 	s.TopLevelActiveTab = s.TopLevelNormalTab.
-		Foreground(lipgloss.Color("36"))
+		Foreground(t.Accent)
 
 	s.TopLevelActiveTabDot = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("36"))
+		Foreground(t.Accent)
 
 	s.RepoSelector.Normal.Base = lipgloss.NewStyle().
 		PaddingLeft(1).
 		Border(lipgloss.Border{Left: " "}, false, false, false, true).
 		Height(3)
 
-	s.RepoSelector.Normal.Title = lipgloss.NewStyle().Bold(true)
+	s.RepoSelector.Normal.Title = lipgloss.NewStyle().
+		Foreground(t.Text).
+		Bold(true)
 
 	s.RepoSelector.Normal.Desc = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("243"))
+		Foreground(t.Muted)
 
 	s.RepoSelector.Normal.Command = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("132"))
+		Foreground(t.Secondary)
 
 	s.RepoSelector.Normal.Updated = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("243"))
+		Foreground(t.Muted)
+
+	s.RepoSelector.Normal.Topics = lipgloss.NewStyle().
+		Foreground(t.Secondary)
 
 	s.RepoSelector.Active.Base = s.RepoSelector.Normal.Base.
 		BorderStyle(lipgloss.Border{Left: "┃"}).
-		BorderForeground(lipgloss.Color("176"))
+		BorderForeground(t.Selection)
 
 	s.RepoSelector.Active.Title = s.RepoSelector.Normal.Title.
-		Foreground(lipgloss.Color("212"))
+		Foreground(t.Selection)
 
 	s.RepoSelector.Active.Desc = s.RepoSelector.Normal.Desc.
-		Foreground(lipgloss.Color("246"))
+		Foreground(t.Muted)
 
 	s.RepoSelector.Active.Updated = s.RepoSelector.Normal.Updated.
-		Foreground(lipgloss.Color("212"))
+		Foreground(t.Selection)
 
 	s.RepoSelector.Active.Command = s.RepoSelector.Normal.Command.
-		Foreground(lipgloss.Color("204"))
+		Foreground(t.Selection)
+
+	s.RepoSelector.Active.Topics = s.RepoSelector.Normal.Topics.
+		Foreground(t.Secondary)
 
 	s.MenuItem = lipgloss.NewStyle().
 		PaddingLeft(1).
@@ -239,7 +260,7 @@ func DefaultStyles() *Styles {
 		Height(3)
 
 	s.MenuLastUpdate = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241")).
+		Foreground(t.Muted).
 		Align(lipgloss.Right)
 
 	s.Repo.Base = lipgloss.NewStyle()
@@ -248,7 +269,7 @@ func DefaultStyles() *Styles {
 		Padding(0, 2)
 
 	s.Repo.Command = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("168"))
+		Foreground(t.Secondary)
 
 	s.Repo.Body = lipgloss.NewStyle().
 		Margin(1, 0)
@@ -256,14 +277,22 @@ func DefaultStyles() *Styles {
 	s.Repo.Header = lipgloss.NewStyle().
 		MaxHeight(2).
 		Border(lipgloss.NormalBorder(), false, false, true, false).
-		BorderForeground(lipgloss.Color("236"))
+		BorderForeground(t.Subtle)
 
 	s.Repo.HeaderName = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("212")).
+		Foreground(t.Selection).
 		Bold(true)
 
 	s.Repo.HeaderDesc = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("243"))
+		Foreground(t.Muted)
+
+	s.Repo.HeaderTopics = lipgloss.NewStyle().
+		Foreground(t.Secondary)
+
+	s.Repo.HeaderArchived = lipgloss.NewStyle().
+		Foreground(t.AccentText).
+		Background(t.Highlight).
+		Padding(0, 1)
 
 	s.Footer = lipgloss.NewStyle().
 		MarginTop(1).
@@ -271,35 +300,35 @@ func DefaultStyles() *Styles {
 		Height(1)
 
 	s.Branch = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("203")).
-		Background(lipgloss.Color("236")).
+		Foreground(t.Selection).
+		Background(t.Background).
 		Padding(0, 1)
 
 	s.HelpKey = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("241"))
+		Foreground(t.Muted)
 
 	s.HelpValue = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("239"))
+		Foreground(t.Muted)
 
 	s.HelpDivider = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("237")).
+		Foreground(t.Subtle).
 		SetString(" • ")
 
 	s.URLStyle = lipgloss.NewStyle().
 		MarginLeft(1).
-		Foreground(lipgloss.Color("168"))
+		Foreground(t.Secondary)
 
 	s.Error = lipgloss.NewStyle().
 		MarginTop(2)
 
 	s.ErrorTitle = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("230")).
-		Background(lipgloss.Color("204")).
+		Foreground(t.AccentText).
+		Background(t.Deleted).
 		Bold(true).
 		Padding(0, 1)
 
 	s.ErrorBody = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252")).
+		Foreground(t.Text).
 		MarginLeft(2)
 
 	s.LogItem.Normal.Base = lipgloss.NewStyle().
@@ -312,42 +341,43 @@ func DefaultStyles() *Styles {
 		Border(lipgloss.Border{
 			Left: "┃",
 		}, false, false, false, true).
-		BorderForeground(selectorColor)
+		BorderForeground(t.Selection)
 
 	s.LogItem.Active.Hash = s.LogItem.Normal.Hash.
-		Foreground(hashColor)
+		Foreground(t.Highlight)
 
 	s.LogItem.Active.Hash = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(highlightColor)
+		Foreground(t.Selection)
 
 	s.LogItem.Normal.Title = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("105"))
+		Foreground(t.Secondary)
 
 	s.LogItem.Active.Title = lipgloss.NewStyle().
-		Foreground(highlightColor).
+		Foreground(t.Selection).
 		Bold(true)
 
 	s.LogItem.Normal.Desc = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("246"))
+		Foreground(t.Muted)
 
 	s.LogItem.Active.Desc = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("95"))
+		Foreground(t.Selection).
+		Faint(true)
 
 	s.LogItem.Active.Keyword = s.LogItem.Active.Desc.
-		Foreground(highlightColorDim)
+		Foreground(t.Selection)
 
 	s.LogItem.Normal.Hash = lipgloss.NewStyle().
-		Foreground(hashColor)
+		Foreground(t.Highlight)
 
 	s.LogItem.Active.Hash = lipgloss.NewStyle().
-		Foreground(highlightColor)
+		Foreground(t.Selection)
 
 	s.Log.Commit = lipgloss.NewStyle().
 		Margin(0, 2)
 
 	s.Log.CommitHash = lipgloss.NewStyle().
-		Foreground(hashColor).
+		Foreground(t.Highlight).
 		Bold(true)
 
 	s.Log.CommitBody = lipgloss.NewStyle().
@@ -355,54 +385,63 @@ func DefaultStyles() *Styles {
 		MarginLeft(2)
 
 	s.Log.CommitStatsAdd = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("42")).
+		Foreground(t.Added).
 		Bold(true)
 
 	s.Log.CommitStatsDel = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("203")).
+		Foreground(t.Deleted).
+		Bold(true)
+
+	s.Log.DiffFile = lipgloss.NewStyle().
 		Bold(true)
 
 	s.Log.Paginator = lipgloss.NewStyle().
 		Margin(0).
 		Align(lipgloss.Center)
 
-	s.Ref.Normal.Item = lipgloss.NewStyle()
+	s.Ref.Normal.Item = lipgloss.NewStyle().
+		Foreground(t.Text)
 
 	s.Ref.ItemSelector = lipgloss.NewStyle().
-		Foreground(selectorColor).
+		Foreground(t.Selection).
 		SetString("> ")
 
 	s.Ref.Active.Item = lipgloss.NewStyle().
-		Foreground(highlightColorDim)
+		Foreground(t.Selection)
+
+	s.Ref.ItemDefault = lipgloss.NewStyle().
+		Foreground(t.AccentText).
+		Background(t.Accent).
+		Padding(0, 1)
 
 	s.Ref.Normal.Base = lipgloss.NewStyle()
 
 	s.Ref.Active.Base = lipgloss.NewStyle()
 
 	s.Ref.Normal.ItemTag = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("39"))
+		Foreground(t.Secondary)
 
 	s.Ref.Active.ItemTag = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(highlightColor)
+		Foreground(t.Selection)
 
 	s.Ref.Active.Item = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(highlightColor)
+		Foreground(t.Selection)
 
 	s.Ref.Normal.ItemDesc = lipgloss.NewStyle().
 		Faint(true)
 
 	s.Ref.Active.ItemDesc = lipgloss.NewStyle().
-		Foreground(highlightColor).
+		Foreground(t.Selection).
 		Faint(true)
 
 	s.Ref.Normal.ItemHash = lipgloss.NewStyle().
-		Foreground(hashColor).
+		Foreground(t.Highlight).
 		Bold(true)
 
 	s.Ref.Active.ItemHash = lipgloss.NewStyle().
-		Foreground(highlightColor).
+		Foreground(t.Selection).
 		Bold(true)
 
 	s.Ref.Paginator = s.Log.Paginator
@@ -411,40 +450,43 @@ func DefaultStyles() *Styles {
 
 	s.Tree.Selector = s.Tree.Normal.FileName.
 		Width(1).
-		Foreground(selectorColor)
+		Foreground(t.Selection)
 
 	s.Tree.Normal.FileName = lipgloss.NewStyle().
-		MarginLeft(1)
+		MarginLeft(1).
+		Foreground(t.Text)
 
 	s.Tree.Active.FileName = s.Tree.Normal.FileName.
 		Bold(true).
-		Foreground(highlightColor)
+		Foreground(t.Selection)
 
 	s.Tree.Normal.FileDir = lipgloss.NewStyle().
-		Foreground(lipgloss.Color("39"))
+		Foreground(t.Secondary)
 
 	s.Tree.Active.FileDir = lipgloss.NewStyle().
-		Foreground(highlightColor)
+		Foreground(t.Selection)
 
 	s.Tree.Normal.FileMode = s.Tree.Active.FileName.
 		Width(10).
-		Foreground(lipgloss.Color("243"))
+		Foreground(t.Muted)
 
 	s.Tree.Active.FileMode = s.Tree.Normal.FileMode.
-		Foreground(highlightColorDim)
+		Foreground(t.Selection).
+		Faint(true)
 
 	s.Tree.Normal.FileSize = s.Tree.Normal.FileName.
-		Foreground(lipgloss.Color("243"))
+		Foreground(t.Muted)
 
 	s.Tree.Active.FileSize = s.Tree.Normal.FileName.
-		Foreground(highlightColorDim)
+		Foreground(t.Selection).
+		Faint(true)
 
 	s.Tree.FileContent = lipgloss.NewStyle()
 
 	s.Tree.Paginator = s.Log.Paginator
 
 	s.Tree.Blame.Hash = lipgloss.NewStyle().
-		Foreground(hashColor).
+		Foreground(t.Highlight).
 		Bold(true)
 
 	s.Tree.Blame.Message = lipgloss.NewStyle()
@@ -455,14 +497,14 @@ func DefaultStyles() *Styles {
 	s.Spinner = lipgloss.NewStyle().
 		MarginTop(1).
 		MarginLeft(2).
-		Foreground(lipgloss.Color("205"))
+		Foreground(t.Accent)
 
 	s.SpinnerContainer = lipgloss.NewStyle()
 
 	s.NoContent = lipgloss.NewStyle().
 		MarginTop(1).
 		MarginLeft(2).
-		Foreground(lipgloss.Color("242"))
+		Foreground(t.Muted)
 
 	s.StatusBar = lipgloss.NewStyle().
 		Height(1)
@@ -470,28 +512,28 @@ func DefaultStyles() *Styles {
 	s.StatusBarKey = lipgloss.NewStyle().
 		Bold(true).
 		Padding(0, 1).
-		Background(lipgloss.Color("206")).
-		Foreground(lipgloss.Color("228"))
+		Background(t.Accent).
+		Foreground(t.AccentText)
 
 	s.StatusBarValue = lipgloss.NewStyle().
 		Padding(0, 1).
-		Background(lipgloss.Color("235")).
-		Foreground(lipgloss.Color("243"))
+		Background(t.Background).
+		Foreground(t.Muted)
 
 	s.StatusBarInfo = lipgloss.NewStyle().
 		Padding(0, 1).
-		Background(lipgloss.Color("212")).
-		Foreground(lipgloss.Color("230"))
+		Background(t.Selection).
+		Foreground(t.AccentText)
 
 	s.StatusBarBranch = lipgloss.NewStyle().
 		Padding(0, 1).
-		Background(lipgloss.Color("62")).
-		Foreground(lipgloss.Color("230"))
+		Background(t.Accent).
+		Foreground(t.AccentText)
 
 	s.StatusBarHelp = lipgloss.NewStyle().
 		Padding(0, 1).
-		Background(lipgloss.Color("237")).
-		Foreground(lipgloss.Color("243"))
+		Background(t.Background).
+		Foreground(t.Muted)
 
 	s.Tabs = lipgloss.NewStyle().
 		Height(1)
@@ -500,28 +542,28 @@ func DefaultStyles() *Styles {
 
 	s.TabActive = lipgloss.NewStyle().
 		Underline(true).
-		Foreground(lipgloss.Color("36"))
+		Foreground(t.Accent)
 
 	s.TabSeparator = lipgloss.NewStyle().
 		SetString("│").
 		Padding(0, 1).
-		Foreground(lipgloss.Color("238"))
+		Foreground(t.Subtle)
 
-	s.Code.LineDigit = lipgloss.NewStyle().Foreground(lipgloss.Color("239"))
+	s.Code.LineDigit = lipgloss.NewStyle().Foreground(t.Muted)
 
-	s.Code.LineBar = lipgloss.NewStyle().Foreground(lipgloss.Color("236"))
+	s.Code.LineBar = lipgloss.NewStyle().Foreground(t.Subtle)
 
 	s.Stash.Normal.Message = lipgloss.NewStyle().MarginLeft(1)
 
-	s.Stash.Active.Message = s.Stash.Normal.Message.Foreground(selectorColor)
+	s.Stash.Active.Message = s.Stash.Normal.Message.Foreground(t.Selection)
 
 	s.Stash.Title = lipgloss.NewStyle().
-		Foreground(hashColor).
+		Foreground(t.Highlight).
 		Bold(true)
 
 	s.Stash.Selector = lipgloss.NewStyle().
 		Width(1).
-		Foreground(selectorColor)
+		Foreground(t.Selection)
 
 	return s
 }
@@ -0,0 +1,120 @@
+package styles
+
+import (
+	"fmt"
+	"image/color"
+	"regexp"
+	"strconv"
+
+	"charm.land/lipgloss/v2"
+)
+
+// Theme is the color scheme of the UI.
+type Theme struct {
+	// Accent colors the tabs, the active borders, and the server name.
+	Accent color.Color
+	// AccentText is the color of text on an accent background.
+	AccentText color.Color
+	// Secondary colors topics, commands, tags, and directories.
+	Secondary color.Color
+	// Highlight colors commit hashes and labels.
+	Highlight color.Color
+	// Selection colors the selected item.
+	Selection color.Color
+	// Background is the background of the status bar and labels.
+	Background color.Color
+	// Text is the color of text.
+	Text color.Color
+	// Muted colors descriptions, dates, and help.
+	Muted color.Color
+	// Subtle colors separators and line numbers.
+	Subtle color.Color
+	// Added and Deleted color diff stats.
+	Added   color.Color
+	Deleted color.Color
+}
+
+// Themes returns the names of the built-in themes.
+func Themes() []string {
+	return []string{"dark", "light", "high-contrast"}
+}
+
+// ThemeByName returns the built-in theme with the given name.
+func ThemeByName(name string) (Theme, error) {
+	switch name {
+	case "", "dark":
+		return DarkTheme(), nil
+	case "light":
+		return LightTheme(), nil
+	case "high-contrast":
+		return HighContrastTheme(), nil
+	default:
+		return Theme{}, fmt.Errorf("unknown theme %q, built-in themes are: %v", name, Themes())
+	}
+}
+
+// DarkTheme returns the default theme, made for dark terminals.
+func DarkTheme() Theme {
+	return Theme{
+		Accent:     lipgloss.Color("62"),
+		AccentText: lipgloss.Color("230"),
+		Secondary:  lipgloss.Color("105"),
+		Highlight:  lipgloss.Color("185"),
+		Selection:  lipgloss.Color("212"),
+		Background: lipgloss.Color("235"),
+		Text:       lipgloss.Color("252"),
+		Muted:      lipgloss.Color("243"),
+		Subtle:     lipgloss.Color("237"),
+		Added:      lipgloss.Color("42"),
+		Deleted:    lipgloss.Color("203"),
+	}
+}
+
+// LightTheme returns a theme made for light terminals.
+func LightTheme() Theme {
+	return Theme{
+		Accent:     lipgloss.Color("25"),
+		AccentText: lipgloss.Color("255"),
+		Secondary:  lipgloss.Color("61"),
+		Highlight:  lipgloss.Color("130"),
+		Selection:  lipgloss.Color("161"),
+		Background: lipgloss.Color("254"),
+		Text:       lipgloss.Color("235"),
+		Muted:      lipgloss.Color("241"),
+		Subtle:     lipgloss.Color("250"),
+		Added:      lipgloss.Color("28"),
+		Deleted:    lipgloss.Color("160"),
+	}
+}
+
+// HighContrastTheme returns a theme using the bright ANSI colors only.
+func HighContrastTheme() Theme {
+	return Theme{
+		Accent:     lipgloss.Color("12"),
+		AccentText: lipgloss.Color("15"),
+		Secondary:  lipgloss.Color("14"),
+		Highlight:  lipgloss.Color("13"),
+		Selection:  lipgloss.Color("11"),
+		Background: lipgloss.Color("0"),
+		Text:       lipgloss.Color("15"),
+		Muted:      lipgloss.Color("7"),
+		Subtle:     lipgloss.Color("8"),
+		Added:      lipgloss.Color("10"),
+		Deleted:    lipgloss.Color("9"),
+	}
+}
+
+var hexColorRe = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ParseColor parses a lipgloss color value, either an ANSI color number from
+// 0 to 255 or a hex color like "#ff5f87".
+func ParseColor(s string) (color.Color, error) {
+	if hexColorRe.MatchString(s) {
+		return lipgloss.Color(s), nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(s), nil
+	}
+
+	return nil, fmt.Errorf("invalid color %q, colors are ANSI numbers from 0 to 255 or hex values", s)
+}
@@ -47,6 +47,53 @@ func ValidateUsername(username string) error {
 	return nil
 }
 
+// ValidateTeamName returns an error if the given team name is invalid. Team
+// names follow the same rules as usernames.
+func ValidateTeamName(name string) error {
+	if name == "" {
+		return fmt.Errorf("team name cannot be empty")
+	}
+
+	if !unicode.IsLetter(rune(name[0])) {
+		return fmt.Errorf("team name must start with a letter")
+	}
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' {
+			return fmt.Errorf("team name can only contain letters, numbers, and hyphens")
+		}
+	}
+
+	return nil
+}
+
+// MaxTopicLength is the maximum length of a repository topic.
+const MaxTopicLength = 50
+
+// ValidateTopic returns an error if the given repository topic is invalid.
+// Topics are lowercase and can only contain letters, numbers, and hyphens.
+func ValidateTopic(topic string) error {
+	if topic == "" {
+		return fmt.Errorf("topic cannot be empty")
+	}
+
+	if len(topic) > MaxTopicLength {
+		return fmt.Errorf("topic cannot be longer than %d characters", MaxTopicLength)
+	}
+
+	if topic[0] == '-' {
+		return fmt.Errorf("topic must start with a letter or a number")
+	}
+
+	for _, r := range topic {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return fmt.Errorf("topic can only contain lowercase letters, numbers, and hyphens")
+		}
+	}
+
+	return nil
+}
+
 // ValidateRepo returns an error if the given repository name is invalid.
 func ValidateRepo(repo string) error {
 	if repo == "" {
@@ -61,3 +108,35 @@ func ValidateRepo(repo string) error {
 
 	return nil
 }
+
+// ValidateBranch returns an error if the given branch name isn't a valid git
+// branch name, following the rules of git check-ref-format --branch.
+func ValidateBranch(branch string) error {
+	if branch == "" {
+		return fmt.Errorf("branch cannot be empty")
+	}
+
+	if branch == "HEAD" || branch == "@" || branch[0] == '-' {
+		return fmt.Errorf("branch cannot be %q", branch)
+	}
+
+	if strings.HasPrefix(branch, "/") || strings.HasSuffix(branch, "/") ||
+		strings.HasSuffix(branch, ".") || strings.Contains(branch, "..") ||
+		strings.Contains(branch, "@{") {
+		return fmt.Errorf("branch cannot start or end with a slash, end with a dot, or contain \"..\" or \"@{\"")
+	}
+
+	for _, c := range strings.Split(branch, "/") {
+		if c == "" || strings.HasPrefix(c, ".") || strings.HasSuffix(c, ".lock") {
+			return fmt.Errorf("branch path components cannot be empty, start with a dot, or end with \".lock\"")
+		}
+	}
+
+	for _, r := range branch {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return fmt.Errorf("branch cannot contain control characters, spaces, or any of ~^:?*[\\")
+		}
+	}
+
+	return nil
+}
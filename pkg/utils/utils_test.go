@@ -54,3 +54,87 @@ func TestSanitizeRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTopic(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		for _, topic := range []string{
+			"go",
+			"infra",
+			"with-dash",
+			"3d",
+		} {
+			t.Run(topic, func(t *testing.T) {
+				if err := ValidateTopic(topic); err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			})
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, topic := range []string{
+			"",
+			"Upper",
+			"-dash",
+			"with space",
+			"with_underline",
+			"with.dot",
+			"toolongtoolongtoolongtoolongtoolongtoolongtoolongto",
+		} {
+			t.Run(topic, func(t *testing.T) {
+				if err := ValidateTopic(topic); err == nil {
+					t.Error("expected an error, got nil")
+				}
+			})
+		}
+	})
+}
+
+func TestValidateBranch(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		for _, branch := range []string{
+			"main",
+			"trunk",
+			"release/v1.0",
+			"feature-x_y",
+			"a@b",
+		} {
+			t.Run(branch, func(t *testing.T) {
+				if err := ValidateBranch(branch); err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			})
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, branch := range []string{
+			"",
+			"HEAD",
+			"@",
+			"-main",
+			"/main",
+			"main/",
+			"main.",
+			"a..b",
+			"a@{b",
+			"a//b",
+			".hidden",
+			"a/.hidden",
+			"main.lock",
+			"with space",
+			"a~b",
+			"a^b",
+			"a:b",
+			"a?b",
+			"a*b",
+			"a[b",
+			"a\\b",
+			"a\tb",
+		} {
+			t.Run(branch, func(t *testing.T) {
+				if err := ValidateBranch(branch); err == nil {
+					t.Error("expected an error, got nil")
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,272 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/log/v2"
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/gorilla/mux"
+)
+
+// The page sizes of the API.
+const (
+	defaultPerPage = 30
+	maxPerPage     = 100
+)
+
+// apiCommit is the last commit of a reference.
+type apiCommit struct {
+	SHA     string    `json:"sha"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// apiBranch is a branch of a repository.
+type apiBranch struct {
+	Name    string     `json:"name"`
+	SHA     string     `json:"sha"`
+	Default bool       `json:"default"`
+	Commit  *apiCommit `json:"commit"`
+}
+
+// apiTag is a tag of a repository. The SHA of annotated tags is the one of
+// the tag object.
+type apiTag struct {
+	Name      string     `json:"name"`
+	SHA       string     `json:"sha"`
+	Annotated bool       `json:"annotated"`
+	Message   string     `json:"message,omitempty"`
+	Commit    *apiCommit `json:"commit"`
+}
+
+// APIController registers the JSON API routes. It must come before the git
+// routes, which match any path.
+func APIController(_ context.Context, r *mux.Router) {
+	api := r.PathPrefix("/api/v1").Subrouter()
+	// File paths could match any other route, the contents route comes first.
+	api.Handle("/repos/{repo:.+?}/contents{path:(?:/.*)?}", withAPIParams(withAccess(http.HandlerFunc(getContents)))).Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+?}/compare/{basehead:.+}", withAPIParams(withAccess(http.HandlerFunc(getCompare)))).Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+}/branches", withAPIParams(withAccess(http.HandlerFunc(getBranches)))).Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+}/tags", withAPIParams(withAccess(http.HandlerFunc(getTags)))).Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+}/languages", withAPIParams(withAccess(http.HandlerFunc(getLanguages)))).Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+}/avatar", withAPIParams(withAccess(http.HandlerFunc(getAvatar)))).Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+}/avatar", withAPIParams(withAccess(http.HandlerFunc(putAPIAvatar)))).Methods(http.MethodPut)
+	api.Handle("/repos/{repo:.+}/avatar", withAPIParams(withAccess(http.HandlerFunc(deleteAPIAvatar)))).Methods(http.MethodDelete)
+	// The repository routes match any path below /repos and must come last.
+	api.HandleFunc("/repos", getAPIRepos).Methods(http.MethodGet)
+	api.HandleFunc("/repos", createAPIRepo).Methods(http.MethodPost)
+	api.Handle("/repos/{repo:.+}", withAPIParams(withAccess(http.HandlerFunc(getAPIRepo)))).Methods(http.MethodGet)
+	api.Handle("/repos/{repo:.+}", withAPIParams(withAccess(http.HandlerFunc(updateAPIRepo)))).Methods(http.MethodPatch)
+	api.Handle("/repos/{repo:.+}", withAPIParams(withAccess(http.HandlerFunc(deleteAPIRepo)))).Methods(http.MethodDelete)
+}
+
+// withAPIParams sets the request vars withAccess needs for API routes.
+func withAPIParams(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := config.FromContext(r.Context())
+		vars := mux.Vars(r)
+		repo := utils.SanitizeRepo(vars["repo"])
+		vars["repo"] = repo
+		vars["dir"] = filepath.Join(cfg.DataPath, "repos", repo+".git")
+		next.ServeHTTP(w, mux.SetURLVars(r, vars))
+	})
+}
+
+// getBranches writes the branches of a repository as JSON, sorted by name.
+func getBranches(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+
+	gr, refs, ok := pageRefs(w, r, repo, gitb.RefsHeads)
+	if !ok {
+		return
+	}
+
+	var head string
+	if ref, err := gr.HEAD(); err == nil {
+		head = ref.Name().String()
+	}
+
+	branches := make([]apiBranch, 0, len(refs))
+	for _, ref := range refs {
+		_, c, err := gr.RefCommit(ref)
+		if err != nil {
+			logger.Debug("failed to get branch commit", "repo", repo.Name(), "branch", ref.Name(), "err", err)
+		}
+		branches = append(branches, apiBranch{
+			Name:    ref.Name().Short(),
+			SHA:     ref.ID,
+			Default: ref.Name().String() == head,
+			Commit:  newAPICommit(c),
+		})
+	}
+
+	renderAPIJSON(w, logger, branches)
+}
+
+// getTags writes the tags of a repository as JSON, sorted by name.
+func getTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+
+	gr, refs, ok := pageRefs(w, r, repo, gitb.RefsTags)
+	if !ok {
+		return
+	}
+
+	tags := make([]apiTag, 0, len(refs))
+	for _, ref := range refs {
+		t, c, err := gr.RefCommit(ref)
+		if err != nil {
+			logger.Debug("failed to get tag commit", "repo", repo.Name(), "tag", ref.Name(), "err", err)
+		}
+		tag := apiTag{
+			Name:   ref.Name().Short(),
+			SHA:    ref.ID,
+			Commit: newAPICommit(c),
+		}
+		if t != nil && t.Type() == gitb.ObjectTag {
+			tag.Annotated = true
+			tag.Message = strings.TrimSpace(t.Message())
+		}
+		tags = append(tags, tag)
+	}
+
+	renderAPIJSON(w, logger, tags)
+}
+
+// getLanguages writes the language breakdown of the default branch of a
+// repository as JSON, sorted by size. It's empty when the breakdown is
+// disabled.
+func getLanguages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+
+	langs, err := be.RepoLanguages(ctx, repo)
+	if err != nil {
+		logger.Error("failed to get languages", "repo", repo.Name(), "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	if langs == nil {
+		langs = []gitb.Language{}
+	}
+
+	renderAPIJSON(w, logger, langs)
+}
+
+// pageRefs returns the references of a repository starting with prefix on
+// the page of the request, and sets the pagination headers. The page and
+// per_page query parameters select the page. It renders an error and returns
+// false when the request can't be served.
+func pageRefs(w http.ResponseWriter, r *http.Request, repo proto.Repository, prefix string) (*gitb.Repository, []*gitb.Reference, bool) {
+	logger := log.FromContext(r.Context())
+	page, perPage, err := parsePage(r.URL.Query())
+	if err != nil {
+		renderBadRequest(w, r)
+		return nil, nil, false
+	}
+
+	gr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repo.Name(), "err", err)
+		renderInternalServerError(w, r)
+		return nil, nil, false
+	}
+
+	var refs []*gitb.Reference
+	all, err := gr.References()
+	if err != nil {
+		// Empty repository
+		all = nil
+	}
+	for _, ref := range all {
+		if strings.HasPrefix(ref.Name().String(), prefix) {
+			refs = append(refs, ref)
+		}
+	}
+
+	start, end := pageBounds(w, r, len(refs), page, perPage)
+	return gr, refs[start:end], true
+}
+
+// pageBounds returns the bounds of page in a list of n items, and sets the
+// X-Total-Count header and the Link header of the next page, if any.
+func pageBounds(w http.ResponseWriter, r *http.Request, n int, page int, perPage int) (start int, end int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(n))
+	start = (page - 1) * perPage
+	if start >= n {
+		return n, n
+	}
+	end = start + perPage
+	if end < n {
+		q := r.URL.Query()
+		q.Set("page", strconv.Itoa(page+1))
+		q.Set("per_page", strconv.Itoa(perPage))
+		next := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.String()))
+	} else {
+		end = n
+	}
+
+	return start, end
+}
+
+// parsePage returns the page and page size of query, 1 and defaultPerPage by
+// default. Page sizes are capped at maxPerPage.
+func parsePage(query url.Values) (page int, perPage int, err error) {
+	page, perPage = 1, defaultPerPage
+	if v := query.Get("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return 0, 0, fmt.Errorf("invalid page %q", v)
+		}
+	}
+	if v := query.Get("per_page"); v != "" {
+		if perPage, err = strconv.Atoi(v); err != nil || perPage < 1 {
+			return 0, 0, fmt.Errorf("invalid page size %q", v)
+		}
+	}
+
+	return page, min(perPage, maxPerPage), nil
+}
+
+// newAPICommit returns the API commit of c, nil if c is.
+func newAPICommit(c *gitb.Commit) *apiCommit {
+	if c == nil {
+		return nil
+	}
+
+	return &apiCommit{
+		SHA:     c.ID.String(),
+		Author:  c.Author.Name,
+		Email:   c.Author.Email,
+		Date:    c.Author.When.UTC(),
+		Subject: c.Summary(),
+	}
+}
+
+// renderAPIJSON writes v as JSON.
+func renderAPIJSON(w http.ResponseWriter, logger *log.Logger, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("error encoding json", "err", err)
+	}
+}
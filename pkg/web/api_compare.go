@@ -0,0 +1,139 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/gorilla/mux"
+)
+
+// apiCompareMaxCommits is the largest number of commits a comparison lists.
+const apiCompareMaxCommits = 250
+
+// apiComparison compares the head reference of a repository to its base
+// reference.
+type apiComparison struct {
+	// Status is identical, ahead, behind, or diverged.
+	Status    string     `json:"status"`
+	AheadBy   int64      `json:"ahead_by"`
+	BehindBy  int64      `json:"behind_by"`
+	Base      *apiCommit `json:"base_commit"`
+	Head      *apiCommit `json:"head_commit"`
+	MergeBase *apiCommit `json:"merge_base_commit"`
+	// Commits are the commits of head that aren't in base, newest first.
+	Commits []*apiCommit    `json:"commits"`
+	Files   []apiFileChange `json:"files"`
+}
+
+// apiFileChange is a file changed by the commits of a comparison.
+type apiFileChange struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename,omitempty"`
+	Status           string `json:"status"`
+	Additions        int64  `json:"additions"`
+	Deletions        int64  `json:"deletions"`
+	Binary           bool   `json:"binary"`
+}
+
+// getCompare writes the comparison of two references as JSON. The files are
+// the ones changed between the merge base and head, or between base and head
+// when they have no common ancestor.
+func getCompare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+
+	baseRef, headRef, ok := strings.Cut(mux.Vars(r)["basehead"], "...")
+	if !ok || baseRef == "" || headRef == "" {
+		renderAPIError(w, logger, http.StatusBadRequest, "invalid comparison, want BASE...HEAD")
+		return
+	}
+
+	gr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repo.Name(), "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	base, _, err := lookupTree(gr, baseRef)
+	if err != nil {
+		renderAPIError(w, logger, http.StatusNotFound, "base reference not found")
+		return
+	}
+	head, _, err := lookupTree(gr, headRef)
+	if err != nil {
+		renderAPIError(w, logger, http.StatusNotFound, "head reference not found")
+		return
+	}
+
+	baseID, headID := base.ID.String(), head.ID.String()
+	c, err := gr.Compare(baseID, headID)
+	if err != nil {
+		logger.Error("failed to compare references", "repo", repo.Name(), "base", baseID, "head", headID, "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	cmp := apiComparison{
+		AheadBy:  c.Ahead,
+		BehindBy: c.Behind,
+		Base:     newAPICommit(base),
+		Head:     newAPICommit(head),
+		Commits:  []*apiCommit{},
+		Files:    []apiFileChange{},
+	}
+	switch {
+	case c.Ahead > 0 && c.Behind > 0:
+		cmp.Status = "diverged"
+	case c.Ahead > 0:
+		cmp.Status = "ahead"
+	case c.Behind > 0:
+		cmp.Status = "behind"
+	default:
+		cmp.Status = "identical"
+	}
+
+	from := baseID
+	if c.MergeBase != "" {
+		from = c.MergeBase
+		if mb, err := gr.CatFileCommit(c.MergeBase); err != nil {
+			logger.Debug("failed to get merge base commit", "repo", repo.Name(), "commit", c.MergeBase, "err", err)
+		} else {
+			cmp.MergeBase = newAPICommit(mb)
+		}
+	}
+
+	if c.Ahead > 0 {
+		commits, err := gr.CommitsBetween(baseID, headID, apiCompareMaxCommits)
+		if err != nil {
+			logger.Error("failed to list commits", "repo", repo.Name(), "base", baseID, "head", headID, "err", err)
+			renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+		for _, c := range commits {
+			cmp.Commits = append(cmp.Commits, newAPICommit(c))
+		}
+	}
+
+	files, err := gr.ChangedFiles(from, headID)
+	if err != nil {
+		logger.Error("failed to diff references", "repo", repo.Name(), "from", from, "head", headID, "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	for _, f := range files {
+		cmp.Files = append(cmp.Files, apiFileChange{
+			Filename:         f.Path,
+			PreviousFilename: f.OldPath,
+			Status:           f.Status,
+			Additions:        f.Additions,
+			Deletions:        f.Deletions,
+			Binary:           f.Binary,
+		})
+	}
+
+	renderAPIJSON(w, logger, cmp)
+}
@@ -0,0 +1,169 @@
+package web
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"charm.land/log/v2"
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/gorilla/mux"
+)
+
+// apiContentsMaxSize is the size of the largest file whose content is
+// returned by the contents API. Larger files only have a download URL.
+const apiContentsMaxSize = 1 << 20 // 1 MiB
+
+// apiContent is a file, directory, symbolic link, or submodule of a
+// repository.
+type apiContent struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+	SHA  string `json:"sha"`
+	Size int64  `json:"size"`
+	// Encoding is base64 when the content is included, none when the file is
+	// too large and must be downloaded instead.
+	Encoding    string  `json:"encoding,omitempty"`
+	Content     *string `json:"content,omitempty"`
+	DownloadURL string  `json:"download_url,omitempty"`
+}
+
+// getContents writes a file of a repository at a reference as JSON, or the
+// entries of a directory. The ref query parameter selects the reference, the
+// repository HEAD by default.
+func getContents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	fp := strings.Trim(mux.Vars(r)["path"], "/")
+
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	gr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repo.Name(), "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	commit, tree, err := lookupTree(gr, ref)
+	if err != nil {
+		renderAPIError(w, logger, http.StatusNotFound, "reference not found")
+		return
+	}
+
+	// The download URLs point to the commit so they keep working when the
+	// reference moves.
+	rawURL := func(p string) string {
+		return fmt.Sprintf("%s/%s/raw/%s/%s", cfg.HTTP.PublicURL, repo.Name(), commit.ID, (&url.URL{Path: p}).EscapedPath())
+	}
+
+	if fp != "" {
+		te, err := tree.TreeEntry(fp)
+		if err != nil {
+			renderAPIError(w, logger, http.StatusNotFound, "path not found")
+			return
+		}
+
+		if !te.IsTree() {
+			c := newAPIContent(te, fp, rawURL)
+			if c.Type == "file" {
+				if !readAPIContent(w, r, &c, te) {
+					return
+				}
+			}
+
+			renderAPIJSON(w, logger, c)
+			return
+		}
+
+		tree, err = tree.SubTree(fp)
+		if err != nil {
+			logger.Error("failed to get tree", "repo", repo.Name(), "path", fp, "err", err)
+			renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+	}
+
+	entries, err := tree.Entries()
+	if err != nil {
+		logger.Error("failed to list tree", "repo", repo.Name(), "path", fp, "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	entries.Sort()
+	items := make([]apiContent, 0, len(entries))
+	for _, te := range entries {
+		p := te.Name()
+		if fp != "" {
+			p = fp + "/" + p
+		}
+		items = append(items, newAPIContent(te, p, rawURL))
+	}
+
+	renderAPIJSON(w, logger, items)
+}
+
+// newAPIContent returns the API content of the tree entry at path p, without
+// the content of files.
+func newAPIContent(te *gitb.TreeEntry, p string, rawURL func(string) string) apiContent {
+	c := apiContent{
+		Type: "file",
+		Name: te.Name(),
+		Path: p,
+		SHA:  te.ID().String(),
+	}
+
+	switch {
+	case te.IsTree():
+		c.Type = "dir"
+	case te.IsCommit():
+		c.Type = "submodule"
+	case te.IsSymlink():
+		c.Type = "symlink"
+		c.Size = te.Size()
+	default:
+		c.Size = te.Size()
+		c.DownloadURL = rawURL(p)
+	}
+
+	return c
+}
+
+// readAPIContent sets the base64 encoded content of the file te to c, unless
+// it's larger than apiContentsMaxSize. The file is streamed from git like raw
+// files are. It renders an error and returns false if the file can't be read.
+func readAPIContent(w http.ResponseWriter, r *http.Request, c *apiContent, te *gitb.TreeEntry) bool {
+	logger := log.FromContext(r.Context())
+	if c.Size > apiContentsMaxSize {
+		c.Encoding = "none"
+		return true
+	}
+
+	var buf, stderr bytes.Buffer
+	enc := base64.NewEncoder(base64.StdEncoding, &buf)
+	err := te.File().Pipeline(enc, &stderr)
+	err = errors.Join(err, enc.Close())
+	if err != nil {
+		logger.Error("failed to read file", "path", c.Path, "err", err, "stderr", stderr.String())
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return false
+	}
+
+	content := buf.String()
+	c.Encoding = "base64"
+	c.Content = &content
+	return true
+}
@@ -0,0 +1,365 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"charm.land/log/v2"
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// apiRequestMaxSize is the size of the largest API request body.
+const apiRequestMaxSize = 1 << 20 // 1 MiB
+
+// apiRepo is a repository.
+type apiRepo struct {
+	Name          string    `json:"name"`
+	ProjectName   string    `json:"project_name"`
+	Description   string    `json:"description"`
+	Private       bool      `json:"private"`
+	Hidden        bool      `json:"hidden"`
+	Archived      bool      `json:"archived"`
+	Mirror        bool      `json:"mirror"`
+	DefaultBranch string    `json:"default_branch"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// apiCreateRepo is the body of a repository creation request.
+type apiCreateRepo struct {
+	Name          string `json:"name"`
+	ProjectName   string `json:"project_name"`
+	Description   string `json:"description"`
+	Private       bool   `json:"private"`
+	Hidden        bool   `json:"hidden"`
+	Template      string `json:"template"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// apiUpdateRepo is the body of a repository update request. Only the fields
+// that are set are updated.
+type apiUpdateRepo struct {
+	Description   *string `json:"description"`
+	Private       *bool   `json:"private"`
+	DefaultBranch *string `json:"default_branch"`
+}
+
+// apiError is the body of an API error response.
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// getAPIRepos writes the repositories the user can read as JSON, sorted by
+// name. Hidden repositories aren't listed.
+func getAPIRepos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+
+	user, ok := authenticateAPI(w, r)
+	if !ok {
+		return
+	}
+
+	if user == nil && !be.AllowKeyless(ctx) {
+		askCredentials(w, r)
+		renderAPIError(w, logger, http.StatusUnauthorized, "credentials needed")
+		return
+	}
+
+	page, perPage, err := parsePage(r.URL.Query())
+	if err != nil {
+		renderAPIError(w, logger, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	repos, err := be.Repositories(ctx)
+	if err != nil {
+		logger.Error("failed to list repositories", "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	readable := make([]proto.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if repo.IsHidden() || be.AccessLevelForUser(ctx, repo.Name(), user) < access.ReadOnlyAccess {
+			continue
+		}
+		readable = append(readable, repo)
+	}
+
+	start, end := pageBounds(w, r, len(readable), page, perPage)
+	items := make([]*apiRepo, 0, end-start)
+	for _, repo := range readable[start:end] {
+		items = append(items, newAPIRepo(repo))
+	}
+
+	renderAPIJSON(w, logger, items)
+}
+
+// createAPIRepo creates a repository like the repo create command. The user
+// needs read-write access to the new repository, and read access to its
+// template, if any.
+func createAPIRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+
+	user, ok := authenticateAPI(w, r)
+	if !ok {
+		return
+	}
+
+	if user == nil {
+		askCredentials(w, r)
+		renderAPIError(w, logger, http.StatusUnauthorized, "credentials needed")
+		return
+	}
+
+	var req apiCreateRepo
+	if !decodeAPIRequest(w, r, &req) {
+		return
+	}
+
+	name := utils.SanitizeRepo(req.Name)
+	if err := utils.ValidateRepo(name); err != nil {
+		renderAPIError(w, logger, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.DefaultBranch != "" {
+		if err := utils.ValidateBranch(req.DefaultBranch); err != nil {
+			renderAPIError(w, logger, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if be.AccessLevelForUser(ctx, name, user) < access.ReadWriteAccess {
+		renderAPIError(w, logger, http.StatusForbidden, "write access required")
+		return
+	}
+
+	// Don't hint that the template exists if the user can't read it.
+	if req.Template != "" && be.AccessLevelForUser(ctx, req.Template, user) < access.ReadOnlyAccess {
+		renderAPIError(w, logger, http.StatusNotFound, "template "+proto.ErrRepoNotFound.Error())
+		return
+	}
+
+	ctx = proto.WithUserContext(ctx, user)
+	repo, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{
+		Private:       req.Private,
+		Description:   req.Description,
+		ProjectName:   req.ProjectName,
+		Hidden:        req.Hidden,
+		Template:      req.Template,
+		DefaultBranch: req.DefaultBranch,
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, proto.ErrRepoExist):
+		renderAPIError(w, logger, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, proto.ErrRepoNotFound):
+		renderAPIError(w, logger, http.StatusNotFound, "template "+err.Error())
+		return
+	case errors.Is(err, proto.ErrNotTemplate):
+		renderAPIError(w, logger, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, proto.ErrRepoBusy):
+		w.Header().Set("Retry-After", "5")
+		renderAPIError(w, logger, http.StatusServiceUnavailable, err.Error())
+		return
+	default:
+		logger.Error("failed to create repository", "repo", name, "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	logger.Info("created repository", "repo", repo.Name(), "username", user.Username())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/repos/"+repo.Name())
+	w.WriteHeader(http.StatusCreated)
+	renderAPIJSON(w, logger, newAPIRepo(repo))
+}
+
+// getAPIRepo writes a repository as JSON.
+func getAPIRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	renderAPIJSON(w, log.FromContext(ctx), newAPIRepo(proto.RepositoryFromContext(ctx)))
+}
+
+// updateAPIRepo updates the description, visibility, and default branch of a
+// repository. The user needs read-write access to the repository.
+func updateAPIRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+
+	if access.FromContext(ctx) < access.ReadWriteAccess {
+		renderAPIError(w, logger, http.StatusForbidden, "write access required")
+		return
+	}
+
+	var req apiUpdateRepo
+	if !decodeAPIRequest(w, r, &req) {
+		return
+	}
+
+	if req.Description == nil && req.Private == nil && req.DefaultBranch == nil {
+		renderAPIError(w, logger, http.StatusBadRequest, "nothing to update")
+		return
+	}
+
+	name := repo.Name()
+	if req.DefaultBranch != nil {
+		if err := be.SetDefaultBranch(ctx, name, *req.DefaultBranch); errors.Is(err, gitb.ErrReferenceNotExist) {
+			renderAPIError(w, logger, http.StatusBadRequest, "branch doesn't exist")
+			return
+		} else if err != nil {
+			logger.Error("failed to set default branch", "repo", name, "err", err)
+			renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+	}
+
+	if req.Description != nil {
+		if err := be.SetDescription(ctx, name, *req.Description); err != nil {
+			logger.Error("failed to set description", "repo", name, "err", err)
+			renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+	}
+
+	if req.Private != nil {
+		if err := be.SetPrivate(ctx, name, *req.Private); err != nil {
+			logger.Error("failed to set private", "repo", name, "err", err)
+			renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+	}
+
+	repo, err := be.Repository(ctx, name)
+	if err != nil {
+		logger.Error("failed to get repository", "repo", name, "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	renderAPIJSON(w, logger, newAPIRepo(repo))
+}
+
+// deleteAPIRepo deletes a repository. The user needs read-write access to the
+// repository, and must either confirm the deletion with the confirm query
+// parameter or have admin access.
+func deleteAPIRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	level := access.FromContext(ctx)
+
+	if level < access.ReadWriteAccess {
+		renderAPIError(w, logger, http.StatusForbidden, "write access required")
+		return
+	}
+
+	if r.URL.Query().Get("confirm") != "true" && level < access.AdminAccess {
+		renderAPIError(w, logger, http.StatusBadRequest, "confirm the deletion with confirm=true")
+		return
+	}
+
+	if err := be.DeleteRepository(ctx, repo.Name()); errors.Is(err, proto.ErrRepoNotFound) {
+		renderAPIError(w, logger, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		logger.Error("failed to delete repository", "repo", repo.Name(), "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	if user := proto.UserFromContext(ctx); user != nil {
+		logger.Info("deleted repository", "repo", repo.Name(), "username", user.Username())
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authenticateAPI authenticates the user of an API request that isn't about
+// an existing repository. It renders an error and returns false when the
+// credentials are refused, and returns a nil user for anonymous requests.
+func authenticateAPI(w http.ResponseWriter, r *http.Request) (proto.User, bool) {
+	logger := log.FromContext(r.Context())
+	user, err := authenticate(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrInvalidPassword):
+			renderAPIError(w, logger, http.StatusForbidden, "bad credentials")
+			return nil, false
+		case errors.Is(err, proto.ErrAddrDenied):
+			renderAPIError(w, logger, http.StatusForbidden, err.Error())
+			return nil, false
+		case errors.Is(err, proto.ErrUserNotFound):
+		case errors.Is(err, proto.ErrTokenExpired):
+			askCredentials(w, r)
+			renderAPIError(w, logger, http.StatusUnauthorized, "access token expired, create a new one with the token create command")
+			return nil, false
+		default:
+			logger.Error("failed to authenticate", "err", err)
+		}
+	}
+
+	return user, true
+}
+
+// decodeAPIRequest decodes the JSON body of r into v. It renders a bad
+// request error and returns false if the body isn't valid.
+func decodeAPIRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiRequestMaxSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		renderAPIError(w, log.FromContext(r.Context()), http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+
+	return true
+}
+
+// newAPIRepo returns the API repository of repo.
+func newAPIRepo(repo proto.Repository) *apiRepo {
+	ar := &apiRepo{
+		Name:        repo.Name(),
+		ProjectName: repo.ProjectName(),
+		Description: strings.TrimSpace(repo.Description()),
+		Private:     repo.IsPrivate(),
+		Hidden:      repo.IsHidden(),
+		Archived:    repo.IsArchived(),
+		Mirror:      repo.IsMirror(),
+		CreatedAt:   repo.CreatedAt().UTC(),
+		UpdatedAt:   repo.UpdatedAt().UTC(),
+	}
+
+	// Empty repositories don't have a default branch yet.
+	if gr, err := repo.Open(); err == nil {
+		if head, err := gr.HEAD(); err == nil {
+			ar.DefaultBranch = head.Name().Short()
+		}
+	}
+
+	return ar
+}
+
+// renderAPIError writes an API error with the status code.
+func renderAPIError(w http.ResponseWriter, logger *log.Logger, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(apiError{Message: msg}); err != nil {
+		logger.Error("error encoding json", "err", err)
+	}
+}
@@ -0,0 +1,38 @@
+package web
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParsePage(t *testing.T) {
+	cases := []struct {
+		query   string
+		page    int
+		perPage int
+		wantErr bool
+	}{
+		{"", 1, defaultPerPage, false},
+		{"page=3&per_page=10", 3, 10, false},
+		{"per_page=1000", 1, maxPerPage, false},
+		{"page=0", 0, 0, true},
+		{"page=x", 0, 0, true},
+		{"per_page=-1", 0, 0, true},
+	}
+
+	for _, c := range cases {
+		q, err := url.ParseQuery(c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		page, perPage, err := parsePage(q)
+		if (err != nil) != c.wantErr {
+			t.Errorf("parsePage(%q) error = %v, want error %v", c.query, err, c.wantErr)
+			continue
+		}
+		if page != c.page || perPage != c.perPage {
+			t.Errorf("parsePage(%q) = %d, %d, want %d, %d", c.query, page, perPage, c.page, c.perPage)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package web
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var archiveCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "archive_total",
+	Help:      "The total number of archive downloads",
+}, []string{"repo", "format"})
+
+// archiveContentTypes are the content types of the archive formats.
+var archiveContentTypes = map[string]string{
+	"tar.gz": "application/gzip",
+	"zip":    "application/zip",
+}
+
+// getArchive streams an archive of a repository reference.
+func getArchive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	dir, repoName := mux.Vars(r)["dir"], mux.Vars(r)["repo"]
+	ref, format := mux.Vars(r)["ref"], mux.Vars(r)["format"]
+
+	// Don't let the reference pass as an option to git.
+	if strings.HasPrefix(ref, "-") {
+		renderNotFound(w, r)
+		return
+	}
+
+	gr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repoName, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	// Resolve the reference first, git archive fails once the response has
+	// started.
+	commit, err := gr.CommitByRevision(ref)
+	if err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	name := path.Base(repoName) + "-" + strings.ReplaceAll(ref, "/", "-")
+	w.Header().Set("Content-Type", archiveContentTypes[format])
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": fmt.Sprintf("%s.%s", name, format),
+	}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	archiveCounter.WithLabelValues(repoName, format).Inc()
+
+	// The archive is streamed to the client as git writes it.
+	if err := git.Archive(ctx, git.ServiceCommand{
+		Stdout: w,
+		Dir:    dir,
+	}, format, name+"/", commit.ID.String()); err != nil {
+		logger.Error("failed to write archive", "repo", repoName, "ref", ref, "format", format, "err", err)
+	}
+}
@@ -8,18 +8,52 @@ import (
 	"strings"
 
 	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/audit"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// authenticate authenticates the user from the request.
+// authenticate authenticates the user from the request. It returns
+// [proto.ErrAddrDenied] when the ip rules refuse the client.
 func authenticate(r *http.Request) (proto.User, error) {
-	// Prefer the Authorization header
+	user, err := authenticateUser(r)
+	if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if err := checkRemoteAddr(r, user); err != nil {
+		return nil, err
+	}
+
+	return user, err
+}
+
+// checkRemoteAddr returns [proto.ErrAddrDenied] if the ip rules refuse the
+// client of the request to authenticate as user, or anonymously when user is
+// nil.
+func checkRemoteAddr(r *http.Request, user proto.User) error {
+	ctx := audit.WithSource(r.Context(), audit.Source{Transport: "http", RemoteAddr: r.RemoteAddr})
+	return backend.FromContext(ctx).CheckRemoteAddr(ctx, r.RemoteAddr, user)
+}
+
+// authenticateUser authenticates the user from the session cookie or the
+// Authorization header of the request.
+func authenticateUser(r *http.Request) (proto.User, error) {
+	// Use the login session cookie without an Authorization header
+	if r.Header.Get("Authorization") == "" {
+		if user, err := parseSession(r); err == nil {
+			return user, nil
+		} else if !errors.Is(err, http.ErrNoCookie) {
+			logr.Subsystem(r.Context(), "http", "http.auth").Debug("invalid session", "err", err)
+		}
+	}
+
 	user, err := parseAuthHdr(r)
 	if err != nil || user == nil {
-		if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrInvalidPassword) {
+		if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrInvalidPassword) || errors.Is(err, proto.ErrTokenExpired) {
 			return nil, err
 		}
 		return nil, proto.ErrUserNotFound
@@ -45,6 +79,16 @@ func parseUsernamePassword(ctx context.Context, username, password string) (prot
 		user, err = be.UserByAccessToken(ctx, password)
 		if err == nil {
 			return user, nil
+		} else if errors.Is(err, proto.ErrTokenExpired) {
+			return nil, err
+		}
+
+		// Try to authenticate using the directory password
+		if cfg := config.FromContext(ctx); cfg.LDAP.HTTP && be.HasAuthenticator() {
+			user, err = be.AuthenticatePassword(ctx, username, password)
+			if err == nil {
+				return user, nil
+			}
 		}
 
 		logger.Error("invalid password or token", "username", username, "err", err)
@@ -55,6 +99,8 @@ func parseUsernamePassword(ctx context.Context, username, password string) (prot
 		user, err := be.UserByAccessToken(ctx, username)
 		if err == nil {
 			return user, nil
+		} else if errors.Is(err, proto.ErrTokenExpired) {
+			return nil, err
 		}
 
 		logger.Error("failed to get user", "err", err)
@@ -75,7 +121,7 @@ func parseAuthHdr(r *http.Request) (proto.User, error) {
 	}
 
 	ctx := r.Context()
-	logger := log.FromContext(ctx).WithPrefix("http.auth")
+	logger := logr.Subsystem(ctx, "http", "http.auth")
 	be := backend.FromContext(ctx)
 
 	logger.Debug("authorization auth header", "header", header)
@@ -135,7 +181,7 @@ var ErrInvalidToken = errors.New("invalid token")
 
 func parseJWT(ctx context.Context, bearer string) (*jwt.RegisteredClaims, error) {
 	cfg := config.FromContext(ctx)
-	logger := log.FromContext(ctx).WithPrefix("http.auth")
+	logger := logr.Subsystem(ctx, "http", "http.auth")
 	kp, err := config.KeyPair(cfg)
 	if err != nil {
 		return nil, err
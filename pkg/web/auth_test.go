@@ -0,0 +1,30 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+func TestAuthenticateAddrDenied(t *testing.T) {
+	_, ctx := newTestServer(t, func(*config.Config) {})
+	be := backend.FromContext(ctx)
+	if err := be.AddIPRule(ctx, "", "203.0.113.0/24", false); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.5:1234"
+	if _, err := authenticate(req); !errors.Is(err, proto.ErrAddrDenied) {
+		t.Errorf("authenticate() = %v, want %v", err, proto.ErrAddrDenied)
+	}
+
+	req.RemoteAddr = "198.51.100.1:1234"
+	if _, err := authenticate(req); !errors.Is(err, proto.ErrUserNotFound) {
+		t.Errorf("authenticate() = %v, want %v", err, proto.ErrUserNotFound)
+	}
+}
@@ -0,0 +1,113 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// getAvatar writes the PNG avatar of a repository, or its identicon when it
+// hasn't any. Browsers revalidate it since it can be replaced at any time.
+func getAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+
+	b, _, err := be.Avatar(ctx, repo.Name())
+	if err != nil {
+		logger.Error("failed to get avatar", "repo", repo.Name(), "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	sum := sha256.Sum256(b)
+	etag := strconv.Quote(hex.EncodeToString(sum[:16]))
+	cacheControl := "no-cache"
+	if repo.IsPrivate() {
+		cacheControl = "private, " + cacheControl
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err := w.Write(b); err != nil {
+		logger.Error("failed to write avatar", "repo", repo.Name(), "err", err)
+	}
+}
+
+// putAPIAvatar sets the avatar of a repository from the PNG, JPEG, or GIF
+// image in the request body. The user needs admin access to the repository.
+func putAPIAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+
+	if access.FromContext(ctx) < access.AdminAccess {
+		renderAPIError(w, logger, http.StatusForbidden, "admin access required")
+		return
+	}
+
+	// Let the backend tell large bodies apart from invalid images.
+	body := http.MaxBytesReader(w, r.Body, backend.AvatarMaxSize+1)
+	err := be.SetAvatar(ctx, repo.Name(), body)
+	var maxErr *http.MaxBytesError
+	switch {
+	case err == nil:
+	case errors.Is(err, backend.ErrAvatarTooLarge), errors.As(err, &maxErr):
+		renderAPIError(w, logger, http.StatusRequestEntityTooLarge, backend.ErrAvatarTooLarge.Error())
+		return
+	case errors.Is(err, backend.ErrInvalidAvatar):
+		renderAPIError(w, logger, http.StatusUnsupportedMediaType, err.Error())
+		return
+	default:
+		logger.Error("failed to set avatar", "repo", repo.Name(), "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	if user := proto.UserFromContext(ctx); user != nil {
+		logger.Info("set avatar", "repo", repo.Name(), "username", user.Username())
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteAPIAvatar removes the avatar of a repository. The user needs admin
+// access to the repository.
+func deleteAPIAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+
+	if access.FromContext(ctx) < access.AdminAccess {
+		renderAPIError(w, logger, http.StatusForbidden, "admin access required")
+		return
+	}
+
+	if err := be.RemoveAvatar(ctx, repo.Name()); errors.Is(err, backend.ErrAvatarNotFound) {
+		renderAPIError(w, logger, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		logger.Error("failed to remove avatar", "repo", repo.Name(), "err", err)
+		renderAPIError(w, logger, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"charm.land/log/v2"
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/gorilla/mux"
+)
+
+var blameTpl = template.Must(template.New("blame").Parse(`<table class="blame">
+{{ range . }}<tr{{ if .First }} class="first"{{ end }}>
+<td>{{ if .First }}<a href="{{ .CommitURL }}" title="{{ .Commit.Summary }}">{{ .Short }}</a> {{ .Commit.Author }} {{ .Date }}{{ end }}</td>
+<td><a id="L{{ .Number }}" href="#L{{ .Number }}">{{ .Number }}</a></td>
+<td>{{ .Content }}</td>
+</tr>
+{{ end }}</table>
+`))
+
+// blameRow is a line of a blame page.
+type blameRow struct {
+	gitb.BlameLine
+	// First is true for the first of consecutive lines of a commit.
+	First     bool
+	Short     string
+	Date      string
+	CommitURL string
+}
+
+// getBlame renders the blame of a file of a repository at a reference. The
+// route path is the reference followed by the file path.
+func getBlame(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	repoName := mux.Vars(r)["repo"]
+
+	gr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repoName, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	ref, fp, commit, _, err := lookupFile(gr, mux.Vars(r)["path"])
+	if err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	page := newBlobPage(cfg, repo, repoName, ref, fp)
+	lines, err := be.Blame(ctx, repo, commit.ID.String(), fp)
+	if errors.Is(err, gitb.ErrBinaryFile) {
+		page.Notice = "Cannot blame binary files."
+	} else if err != nil {
+		logger.Error("failed to blame file", "repo", repoName, "commit", commit.ID, "path", fp, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	rows := make([]blameRow, 0, len(lines))
+	var prev *gitb.BlameCommit
+	for _, l := range lines {
+		rows = append(rows, blameRow{
+			BlameLine: l,
+			First:     l.Commit != prev,
+			Short:     l.Commit.ID[:7],
+			Date:      l.Commit.AuthorTime.Format("2006-01-02"),
+			CommitURL: fmt.Sprintf("%s/commit/%s.patch", page.RepoURL, l.Commit.ID),
+		})
+		prev = l.Commit
+	}
+
+	var body bytes.Buffer
+	if len(rows) > 0 {
+		if err := blameTpl.Execute(&body, rows); err != nil {
+			logger.Error("failed to render blame", "repo", repoName, "path", fp, "err", err)
+			renderInternalServerError(w, r)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := renderBlobPage(w, page, body.Bytes()); err != nil {
+		logger.Error("failed to render blame page", "repo", repoName, "path", fp, "err", err)
+	}
+}
@@ -0,0 +1,246 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+
+	"charm.land/log/v2"
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/gorilla/mux"
+)
+
+// blobHighlightMaxSize is the size of the largest file that gets
+// highlighted. Larger files are shown as plain text.
+const blobHighlightMaxSize = 1 << 20 // 1 MiB
+
+var blobPageTpl = template.Must(template.New("blob").Parse(`{{ define "header" }}<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{ .Path }} - {{ .Name }}</title>
+    <style>
+        body { margin: 2em; font-family: sans-serif; line-height: 1.5; }
+        pre { overflow: auto; padding: 1em; background: #f6f8fa; }
+        :target { background: #fff8c5; }
+        .blame { border-collapse: collapse; font-family: monospace; }
+        .blame td { padding: 0 .5em; vertical-align: top; white-space: pre; }
+        .blame tr.first td { border-top: 1px solid #d0d7de; }
+    </style>
+</head>
+<body>
+<h1><a href="{{ .RepoURL }}">{{ .Name }}</a> / {{ .Path }}</h1>
+<p>{{ .Ref }} &middot; <a href="{{ .BlobURL }}">file</a> &middot; <a href="{{ .RawURL }}">raw</a> &middot; <a href="{{ .BlameURL }}">blame</a></p>
+{{ with .Notice }}<p><em>{{ . }}</em></p>
+{{ end }}{{ end }}{{ define "footer" }}</body>
+</html>
+{{ end }}`))
+
+// blobPage is the data of a blob page.
+type blobPage struct {
+	Name     string
+	Path     string
+	Ref      string
+	RepoURL  string
+	BlobURL  string
+	RawURL   string
+	BlameURL string
+	Notice   string
+}
+
+// newBlobPage returns the data of a page about a file of a repository at a
+// reference.
+func newBlobPage(cfg *config.Config, repo proto.Repository, repoName, ref, fp string) blobPage {
+	name := repo.ProjectName()
+	if name == "" {
+		name = repo.Name()
+	}
+
+	repoURL := fmt.Sprintf("%s/%s", cfg.HTTP.PublicURL, repoName)
+	return blobPage{
+		Name:     name,
+		Path:     fp,
+		Ref:      ref,
+		RepoURL:  repoURL,
+		BlobURL:  fmt.Sprintf("%s/blob/%s/%s", repoURL, ref, fp),
+		RawURL:   fmt.Sprintf("%s/raw/%s/%s", repoURL, ref, fp),
+		BlameURL: fmt.Sprintf("%s/blame/%s/%s", repoURL, ref, fp),
+	}
+}
+
+// getBlob renders a file of a repository at a reference with syntax
+// highlighting. The route path is the reference followed by the file path.
+func getBlob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	repoName := mux.Vars(r)["repo"]
+
+	gr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repoName, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	ref, fp, commit, te, err := lookupFile(gr, mux.Vars(r)["path"])
+	if err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	page := newBlobPage(cfg, repo, repoName, ref, fp)
+
+	if te.Size() > blobHighlightMaxSize {
+		// Highlighting large files takes too long, stream them instead.
+		page.Notice = "This file is too large to highlight."
+		bw := &blobTextWriter{w: w, page: page}
+		var stderr bytes.Buffer
+		if err := te.File().Pipeline(bw, &stderr); err != nil {
+			logger.Error("failed to read file", "repo", repoName, "commit", commit.ID, "path", fp, "err", err, "stderr", stderr.String())
+			if !bw.wroteHeader {
+				renderInternalServerError(w, r)
+				return
+			}
+		}
+
+		if err := bw.close(); err != nil {
+			logger.Error("failed to render blob page", "repo", repoName, "path", fp, "err", err)
+		}
+		return
+	}
+
+	content, err := te.Contents()
+	if err != nil {
+		logger.Error("failed to read file", "repo", repoName, "commit", commit.ID, "path", fp, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	var body bytes.Buffer
+	if isBin, _ := gitb.IsBinary(bytes.NewReader(content)); isBin {
+		page.Notice = "Binary file not shown."
+	} else if err := highlight(&body, te.Name(), content); err != nil {
+		logger.Error("failed to highlight file", "repo", repoName, "path", fp, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := renderBlobPage(w, page, body.Bytes()); err != nil {
+		logger.Error("failed to render blob page", "repo", repoName, "path", fp, "err", err)
+	}
+}
+
+// renderBlobPage writes a blob page around an already rendered body.
+func renderBlobPage(w io.Writer, page blobPage, body []byte) error {
+	if err := blobPageTpl.ExecuteTemplate(w, "header", page); err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	return blobPageTpl.ExecuteTemplate(w, "footer", page)
+}
+
+// highlight writes a file as syntax highlighted HTML with linkable line
+// numbers. The lexer is picked from the file name, then from the content.
+func highlight(w io.Writer, name string, content []byte) error {
+	lexer := lexers.Match(name)
+	if lexer == nil {
+		lexer = lexers.Analyse(string(content))
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, string(content))
+	if err != nil {
+		return err
+	}
+
+	f := html.New(
+		html.WithLineNumbers(true),
+		html.LineNumbersInTable(true),
+		html.WithLinkableLineNumbers(true, "L"),
+	)
+	return f.Format(w, styles.Get("github"), it)
+}
+
+// blobTextWriter writes a blob page with a file as escaped plain text. It
+// holds back the first bytes of the file to leave binary files out.
+type blobTextWriter struct {
+	w           http.ResponseWriter
+	page        blobPage
+	head        []byte
+	binary      bool
+	wroteHeader bool
+}
+
+// Write implements io.Writer.
+func (bw *blobTextWriter) Write(p []byte) (int, error) {
+	if bw.wroteHeader {
+		if !bw.binary {
+			template.HTMLEscape(bw.w, p)
+		}
+		return len(p), nil
+	}
+
+	n := min(len(p), rawSniffLen-len(bw.head))
+	bw.head = append(bw.head, p[:n]...)
+	if len(bw.head) < rawSniffLen {
+		return len(p), nil
+	}
+
+	if err := bw.writeHeader(); err != nil {
+		return 0, err
+	}
+
+	m, err := bw.Write(p[n:])
+	return n + m, err
+}
+
+// writeHeader writes the beginning of the page and the bytes held back.
+func (bw *blobTextWriter) writeHeader() error {
+	bw.wroteHeader = true
+	bw.binary, _ = gitb.IsBinary(bytes.NewReader(bw.head))
+	if bw.binary {
+		bw.page.Notice = "Binary file not shown."
+	}
+
+	bw.w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	bw.w.WriteHeader(http.StatusOK)
+	if err := blobPageTpl.ExecuteTemplate(bw.w, "header", bw.page); err != nil {
+		return err
+	}
+
+	if !bw.binary {
+		io.WriteString(bw.w, "<pre>") //nolint:errcheck
+		template.HTMLEscape(bw.w, bw.head)
+	}
+	return nil
+}
+
+// close writes the end of the page.
+func (bw *blobTextWriter) close() error {
+	if !bw.wroteHeader {
+		if err := bw.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	if !bw.binary {
+		io.WriteString(bw.w, "</pre>\n") //nolint:errcheck
+	}
+	return blobPageTpl.ExecuteTemplate(bw.w, "footer", bw.page)
+}
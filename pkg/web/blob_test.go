@@ -0,0 +1,66 @@
+package web
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	var buf bytes.Buffer
+	src := "package main\n\nfunc main() {\n\tprintln(\"<hi>\")\n}\n"
+	if err := highlight(&buf, "main.go", []byte(src)); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{`id="L4"`, `href="#L4"`, "&lt;hi&gt;"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("highlight() missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<hi>") {
+		t.Errorf("highlight() didn't escape the content:\n%s", out)
+	}
+}
+
+func TestBlobTextWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+		notWant string
+	}{
+		{"short text", []byte("<b>hi</b>"), "<pre>&lt;b&gt;hi&lt;/b&gt;</pre>", "Binary file"},
+		{"long text", bytes.Repeat([]byte("a<"), rawSniffLen), strings.Repeat("a&lt;", rawSniffLen) + "</pre>", "Binary file"},
+		{"binary", append([]byte("\x7fELF\x00"), bytes.Repeat([]byte("a"), rawSniffLen)...), "Binary file not shown.", "<pre>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			bw := &blobTextWriter{w: rec, page: blobPage{Name: "repo", Path: "file"}}
+			// Write in small chunks like a pipe would.
+			for c := range bytes.SplitSeq(tt.content, nil) {
+				if _, err := bw.Write(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := bw.close(); err != nil {
+				t.Fatal(err)
+			}
+
+			out := rec.Body.String()
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("page missing %q", tt.want)
+			}
+			if strings.Contains(out, tt.notWant) {
+				t.Errorf("page has %q", tt.notWant)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package web
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"charm.land/log/v2"
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/gorilla/mux"
+)
+
+// feedCommits is the number of commits in a commits feed.
+const feedCommits = 20
+
+// atomFeed is an Atom feed.
+// https://www.rfc-editor.org/rfc/rfc4287
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Author  atomAuthor `xml:"author"`
+	Link    atomLink   `xml:"link"`
+	Content string     `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+}
+
+// getCommitsFeed renders the latest commits of a branch as an Atom feed. It
+// uses the default branch unless the route has a branch.
+func getCommitsFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	repoName := mux.Vars(r)["repo"]
+	branch := mux.Vars(r)["branch"]
+
+	gr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repoName, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	baseURL := fmt.Sprintf("%s/%s.git", cfg.HTTP.PublicURL, repoName)
+	feed := atomFeed{
+		ID:    baseURL + "/commits.atom",
+		Title: repoName + " commits",
+	}
+
+	var ref *gitb.Reference
+	if branch == "" {
+		// Empty repositories have no HEAD yet, their feed is empty.
+		ref, _ = gr.HEAD()
+	} else {
+		feed.ID = fmt.Sprintf("%s/commits/%s.atom", baseURL, branch)
+		feed.Title = fmt.Sprintf("%s commits on %s", repoName, branch)
+		ref, err = branchRef(gr, branch)
+		if err != nil {
+			renderNotFound(w, r)
+			return
+		}
+	}
+
+	var commits gitb.Commits
+	if ref != nil {
+		// CommitsByPage pages start at 1
+		commits, err = gr.CommitsByPage(ref, 1, feedCommits)
+		if err != nil {
+			logger.Error("failed to get commits", "repo", repoName, "ref", ref.Name(), "err", err)
+			renderInternalServerError(w, r)
+			return
+		}
+	}
+
+	feed.Link = []atomLink{{Href: feed.ID, Rel: "self"}}
+	feed.Updated = repo.UpdatedAt().UTC().Format(time.RFC3339)
+	for i, c := range commits {
+		when := c.Committer.When.UTC().Format(time.RFC3339)
+		if i == 0 {
+			feed.Updated = when
+		}
+
+		link := fmt.Sprintf("%s/commit/%s.patch", baseURL, c.ID)
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      link,
+			Title:   c.Summary(),
+			Updated: when,
+			Author: atomAuthor{
+				Name:  c.Author.Name,
+				Email: c.Author.Email,
+			},
+			Link:    atomLink{Href: link, Type: "text/plain"},
+			Content: c.Message,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header)) //nolint: errcheck
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		logger.Error("failed to render commits feed", "repo", repoName, "err", err)
+	}
+}
+
+// getCommitPatch renders the patch of a commit, commits feed entries link to
+// it.
+func getCommitPatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	repoName := mux.Vars(r)["repo"]
+
+	gr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repoName, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	commit, err := gr.CatFileCommit(mux.Vars(r)["sha"])
+	if err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	patch, err := gr.Patch(commit)
+	if err != nil {
+		logger.Error("failed to get patch", "repo", repoName, "commit", commit.ID, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(patch)) //nolint: errcheck
+}
+
+// branchRef returns the reference of a branch.
+func branchRef(r *gitb.Repository, branch string) (*gitb.Reference, error) {
+	refs, err := r.References()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range refs {
+		if ref.IsBranch() && ref.Name().Short() == branch {
+			return ref, nil
+		}
+	}
+
+	return nil, gitb.ErrReferenceNotExist
+}
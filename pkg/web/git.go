@@ -10,13 +10,13 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
 	"charm.land/log/v2"
 	gitb "github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/audit"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/git"
@@ -33,6 +33,10 @@ type GitRoute struct {
 	method  []string
 	handler http.HandlerFunc
 	path    string
+
+	// lazyRepo matches the shortest repository name instead of the longest,
+	// for routes ending with paths that can contain anything.
+	lazyRepo bool
 }
 
 var _ http.Handler = GitRoute{}
@@ -107,16 +111,41 @@ func withParams(next http.Handler) http.Handler {
 func GitController(_ context.Context, r *mux.Router) {
 	basePrefix := "/{repo:.*}"
 	for _, route := range gitRoutes {
+		prefix := basePrefix
+		if route.lazyRepo {
+			prefix = "/{repo:.*?}"
+		}
+
 		// NOTE: withParam must always be the outermost wrapper, otherwise the
 		// request vars will not be set.
-		r.Handle(basePrefix+route.path, withParams(withAccess(route)))
+		r.Handle(prefix+route.path, withParams(withAccess(route)))
 	}
 
-	// Handle go-get
-	r.Handle(basePrefix, withParams(withAccess(http.HandlerFunc(GoGetHandler)))).Methods(http.MethodGet)
+	// Handle the repository page and go-get
+	r.Handle(basePrefix, withParams(withAccess(http.HandlerFunc(getRepo)))).Methods(http.MethodGet)
 }
 
 var gitRoutes = []GitRoute{
+	// Raw, highlighted, and blamed files
+	// These come first since file paths could match any other route.
+	{
+		method:   []string{http.MethodGet},
+		handler:  getRawFile,
+		path:     "/raw/{path:.+}",
+		lazyRepo: true,
+	},
+	{
+		method:   []string{http.MethodGet},
+		handler:  getBlob,
+		path:     "/blob/{path:.+}",
+		lazyRepo: true,
+	},
+	{
+		method:   []string{http.MethodGet},
+		handler:  getBlame,
+		path:     "/blame/{path:.+}",
+		lazyRepo: true,
+	},
 	// Git services
 	// These routes don't handle authentication/authorization.
 	// This is handled through wrapping the handlers for each route.
@@ -131,31 +160,72 @@ var gitRoutes = []GitRoute{
 		handler: getInfoRefs,
 		path:    "/info/refs",
 	},
+	// Dumb HTTP
 	{
 		method:  []string{http.MethodGet},
-		handler: getTextFile,
+		handler: withDumbHTTP(getTextFile),
 		path:    "/{_:(?:HEAD|objects/info/alternates|objects/info/http-alternates|objects/info/[^/]*)$}",
 	},
 	{
 		method:  []string{http.MethodGet},
-		handler: getInfoPacks,
+		handler: withDumbHTTP(getInfoPacks),
 		path:    "/objects/info/packs",
 	},
 	{
 		method:  []string{http.MethodGet},
-		handler: getLooseObject,
+		handler: withDumbHTTP(getLooseObject),
 		path:    "/objects/{_:[0-9a-f]{2}/[0-9a-f]{38}$}",
 	},
 	{
 		method:  []string{http.MethodGet},
-		handler: getPackFile,
+		handler: withDumbHTTP(getPackFile),
 		path:    "/objects/pack/{_:pack-[0-9a-f]{40}\\.pack$}",
 	},
 	{
 		method:  []string{http.MethodGet},
-		handler: getIdxFile,
+		handler: withDumbHTTP(getIdxFile),
 		path:    "/objects/pack/{_:pack-[0-9a-f]{40}\\.idx$}",
 	},
+	// Description, where git keeps it for dumb HTTP
+	{
+		method:  []string{http.MethodGet},
+		handler: getDescription,
+		path:    "/description",
+	},
+	// Avatar, the identicon of the repository unless one is set
+	{
+		method:  []string{http.MethodGet},
+		handler: getAvatar,
+		path:    "/avatar.png",
+	},
+	// Repository stats
+	{
+		method:  []string{http.MethodGet},
+		handler: getStats,
+		path:    "/stats.json",
+	},
+	// Commits feeds
+	{
+		method:  []string{http.MethodGet},
+		handler: getCommitsFeed,
+		path:    "/commits.atom",
+	},
+	{
+		method:  []string{http.MethodGet},
+		handler: getCommitsFeed,
+		path:    "/commits/{branch:.+}.atom",
+	},
+	{
+		method:  []string{http.MethodGet},
+		handler: getCommitPatch,
+		path:    "/commit/{sha:[0-9a-f]{40,64}}.patch",
+	},
+	// Archives
+	{
+		method:  []string{http.MethodGet},
+		handler: getArchive,
+		path:    "/archive/{ref:.+}.{format:(?:tar\\.gz|zip)}",
+	},
 	// Git LFS
 	{
 		method:  []string{http.MethodPost},
@@ -208,6 +278,20 @@ func withAccess(next http.Handler) http.HandlerFunc {
 		// We're not checking for errors here because we want to allow
 		// repo creation on the fly.
 		repoName := mux.Vars(r)["repo"]
+
+		// Requests can't be served while the repository is being renamed,
+		// and pushes keep it from being garbage collected.
+		acquire := be.AcquireRepository
+		if git.Service(mux.Vars(r)["service"]) == git.ReceivePackService {
+			acquire = be.AcquireRepositoryPush
+		}
+		release, err := acquire(repoName)
+		if err != nil {
+			renderRepoBusy(w, r, err)
+			return
+		}
+		defer release()
+
 		repo, _ := be.Repository(ctx, repoName)
 		ctx = proto.WithRepositoryContext(ctx, repo)
 		r = r.WithContext(ctx)
@@ -217,6 +301,15 @@ func withAccess(next http.Handler) http.HandlerFunc {
 			switch {
 			case errors.Is(err, ErrInvalidToken):
 			case errors.Is(err, proto.ErrUserNotFound):
+			case errors.Is(err, proto.ErrAddrDenied):
+				renderForbidden(w, r)
+				return
+			case errors.Is(err, proto.ErrTokenExpired):
+				// Expired tokens are refused even on public repos so the
+				// client knows to create a new one.
+				askCredentials(w, r)
+				renderTokenExpired(w, r)
+				return
 			default:
 				logger.Error("failed to authenticate", "err", err)
 			}
@@ -242,26 +335,47 @@ func withAccess(next http.Handler) http.HandlerFunc {
 			service = getServiceType(r)
 		}
 
-		accessLevel := be.AccessLevelForUser(ctx, repoName, user)
+		file := mux.Vars(r)["file"]
+
+		ctx = audit.WithSource(ctx, audit.Source{Transport: "http", RemoteAddr: r.RemoteAddr})
+		accessLevel, _ := be.Authorize(ctx, repoName, user, auditService(service, file), requiredAccess(service, file, r.Method))
 		ctx = access.WithContext(ctx, accessLevel)
 		r = r.WithContext(ctx)
 
-		file := mux.Vars(r)["file"]
-
 		// We only allow these services to proceed any other services should return 403
 		// - git-upload-pack
 		// - git-receive-pack
 		// - git-lfs
 		switch {
 		case service == git.ReceivePackService:
+			// Archived repositories are read-only, tell whoever can read them.
+			if repo != nil && repo.IsArchived() && accessLevel >= access.ReadOnlyAccess {
+				logger.Info("push rejected", "err", git.ErrArchivedPush, "repo", repoName)
+				renderArchived(w, r)
+				return
+			}
+
 			if accessLevel < access.ReadWriteAccess {
 				askCredentials(w, r)
 				renderUnauthorized(w, r)
 				return
 			}
 
+			// Mirrors are only updated from their upstream.
+			if repo != nil && repo.IsMirror() {
+				logger.Info("push rejected", "err", git.ErrMirrorPush, "repo", repoName)
+				renderForbidden(w, r)
+				return
+			}
+
 			// Create the repo if it doesn't exist.
 			if repo == nil {
+				if !be.AllowPushCreate(ctx, user) {
+					logger.Info("push rejected", "err", git.ErrPushCreateDisabled, "repo", repoName)
+					renderPushCreateDisabled(w, r)
+					return
+				}
+
 				repo, err = be.CreateRepository(ctx, repoName, user, proto.RepositoryOptions{})
 				if err != nil {
 					logger.Error("failed to create repository", "repo", repoName, "err", err)
@@ -366,6 +480,41 @@ func withAccess(next http.Handler) http.HandlerFunc {
 	}
 }
 
+// requiredAccess returns the access level a git HTTP request needs. It
+// matches the checks of the git middleware.
+func requiredAccess(service git.Service, file string, method string) access.AccessLevel {
+	switch {
+	case service == git.ReceivePackService:
+		return access.ReadWriteAccess
+	case strings.HasPrefix(file, "info/lfs/locks"):
+		if strings.HasSuffix(file, "lfs/locks") || strings.HasSuffix(file, "lfs/locks/verify") ||
+			(strings.HasSuffix(file, "/unlock") && method == http.MethodPost) {
+			return access.ReadWriteAccess
+		}
+	case strings.HasPrefix(file, "info/lfs/objects/basic"):
+		if method == http.MethodPut {
+			return access.ReadWriteAccess
+		}
+	}
+
+	return access.ReadOnlyAccess
+}
+
+// auditService returns the name of the service of a git HTTP request in the
+// audit log.
+func auditService(service git.Service, file string) string {
+	switch {
+	case service != "":
+		return service.String()
+	case strings.HasPrefix(file, "info/lfs"):
+		return "git-lfs"
+	case file == "":
+		return "web"
+	default:
+		return "git-http"
+	}
+}
+
 //nolint:revive
 func serviceRpc(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -392,8 +541,10 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 
 	var stdout bytes.Buffer
 	cmd := git.ServiceCommand{
-		Stdout: &stdout,
-		Dir:    dir,
+		Stdout:     &stdout,
+		Dir:        dir,
+		OnComplete: git.ObserveService("http", service),
+		Pack:       backend.FromContext(ctx).ServicePackOptions(ctx, repoName, service),
 	}
 
 	switch service {
@@ -401,11 +552,17 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 		cmd.Args = append(cmd.Args, "--stateless-rpc")
 	}
 
+	if service == git.UploadPackService {
+		cmd.DisableFilter = backend.FromContext(ctx).FiltersDisabled(ctx, repoName)
+		cmd.AllowedFilters = cfg.Repo.AllowedFilters
+	}
+
 	user := proto.UserFromContext(ctx)
 	cmd.Env = cfg.Environ()
 	cmd.Env = append(cmd.Env, []string{
 		"SOFT_SERVE_REPO_NAME=" + repoName,
 		"SOFT_SERVE_REPO_PATH=" + dir,
+		"SOFT_SERVE_ACCESS_LEVEL=" + access.FromContext(ctx).String(),
 		"SOFT_SERVE_LOG_PATH=" + filepath.Join(cfg.DataPath, "log", "hooks.log"),
 	}...)
 	if user != nil {
@@ -418,6 +575,7 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 			fmt.Sprintf("GIT_PROTOCOL=%s", version),
 		}...)
 	}
+	backend.FromContext(ctx).TrackActivity(ctx, &cmd, repoName, user, service.String())
 
 	var (
 		err    error
@@ -440,11 +598,57 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 	cmd.Stdin = reader
 	cmd.Stdout = &flushResponseWriter{w}
 
-	if err := service.Handler(ctx, cmd); err != nil {
+	// Protocol v2 clients list refs with an ls-refs request after the
+	// advertisement, it's cached like the advertisement of older protocols.
+	var (
+		lsRefs     bytes.Buffer
+		state, key string
+	)
+	if service == git.UploadPackService && protocolVersion(version) >= 2 {
+		if s, cached := refCacheState(ctx, repoName, dir); cached {
+			req, err := io.ReadAll(io.LimitReader(reader, refCacheMaxRequest+1))
+			if err != nil {
+				logger.Errorf("failed to read request: %v", err)
+				return
+			}
+			cmd.Stdin = io.MultiReader(bytes.NewReader(req), reader)
+			if len(req) <= refCacheMaxRequest && isLsRefs(req) {
+				state, key = s, refCacheKey(repoName, string(req), version, cmd)
+				if refs, ok := cachedRefs(repoName, key, state); ok {
+					w.Write(refs) //nolint: errcheck
+					return
+				}
+				cmd.Stdout = &lsRefs
+			}
+		}
+	}
+
+	if service == git.ReceivePackService {
+		be := backend.FromContext(ctx)
+		cmd.Repo = repoName
+		cmd.Quota = be.RepositoryQuota(ctx)
+		cmd.DenyNonFastForward = cfg.Repo.DenyNonFastForwards
+		cmd.MaxPackBytes = cfg.Repo.MaxPackBytes
+		if bitmaps, err := be.BitmapsEnabled(ctx, repoName); err != nil {
+			logger.Errorf("failed to get bitmaps setting: %v", err)
+		} else {
+			cmd.WriteBitmaps = bitmaps
+		}
+	}
+
+	if err := service.Handler(ctx, cmd); errors.Is(err, git.ErrQuotaExceeded) || errors.Is(err, git.ErrPackTooLarge) {
+		logger.Info("push rejected", "err", err, "repo", repoName)
+		return
+	} else if err != nil {
 		logger.Errorf("failed to handle service: %v", err)
 		return
 	}
 
+	if key != "" {
+		cacheRefs(key, state, lsRefs.Bytes())
+		w.Write(lsRefs.Bytes()) //nolint: errcheck
+	}
+
 	if service == git.ReceivePackService {
 		if err := git.EnsureDefaultBranch(ctx, cmd.Dir); err != nil {
 			logger.Errorf("failed to ensure default branch: %s", err)
@@ -503,11 +707,17 @@ func getInfoRefs(w http.ResponseWriter, r *http.Request) {
 			Args:   []string{"--stateless-rpc", "--advertise-refs"},
 		}
 
+		if service == git.UploadPackService {
+			cmd.DisableFilter = backend.FromContext(ctx).FiltersDisabled(ctx, repoName)
+			cmd.AllowedFilters = cfg.Repo.AllowedFilters
+		}
+
 		user := proto.UserFromContext(ctx)
 		cmd.Env = cfg.Environ()
 		cmd.Env = append(cmd.Env, []string{
 			"SOFT_SERVE_REPO_NAME=" + repoName,
 			"SOFT_SERVE_REPO_PATH=" + dir,
+			"SOFT_SERVE_ACCESS_LEVEL=" + access.FromContext(ctx).String(),
 			"SOFT_SERVE_LOG_PATH=" + filepath.Join(cfg.DataPath, "log", "hooks.log"),
 		}...)
 		if user != nil {
@@ -519,18 +729,32 @@ func getInfoRefs(w http.ResponseWriter, r *http.Request) {
 			cmd.Env = append(cmd.Env, fmt.Sprintf("GIT_PROTOCOL=%s", protocol))
 		}
 
-		var version int
-		for _, p := range strings.Split(protocol, ":") {
-			if strings.HasPrefix(p, "version=") {
-				if v, _ := strconv.Atoi(p[8:]); v > version {
-					version = v
-				}
-			}
+		version := protocolVersion(protocol)
+
+		// Fetches that are already up to date only need the advertisement,
+		// repositories can opt in to serve it without running git while
+		// their refs don't change.
+		var (
+			state, key    string
+			cached, hit   bool
+			advertisement []byte
+		)
+		if service == git.UploadPackService {
+			state, cached = refCacheState(ctx, repoName, dir)
+			key = refCacheKey(repoName, "info/refs", protocol, cmd)
 		}
-
-		if err := service.Handler(ctx, cmd); err != nil {
-			renderNotFound(w, r)
-			return
+		if cached {
+			advertisement, hit = cachedRefs(repoName, key, state)
+		}
+		if !hit {
+			if err := service.Handler(ctx, cmd); err != nil {
+				renderNotFound(w, r)
+				return
+			}
+			advertisement = refs.Bytes()
+			if cached {
+				cacheRefs(key, state, advertisement)
+			}
 		}
 
 		hdrNocache(w)
@@ -539,9 +763,14 @@ func getInfoRefs(w http.ResponseWriter, r *http.Request) {
 		if version < 2 {
 			git.WritePktline(w, "# service="+service.String()) //nolint: errcheck
 		}
-		w.Write(refs.Bytes()) //nolint: errcheck
+		w.Write(advertisement) //nolint: errcheck
 	} else {
 		// Dumb HTTP
+		if !allowDumbHTTP(r) {
+			renderNotFound(w, r)
+			return
+		}
+
 		updateServerInfo(ctx, dir) //nolint: errcheck
 		hdrNocache(w)
 		sendFile("text/plain; charset=utf-8", w, r)
@@ -573,6 +802,32 @@ func getTextFile(w http.ResponseWriter, r *http.Request) {
 	sendFile("text/plain", w, r)
 }
 
+// allowDumbHTTP returns whether the repository of the request can be served
+// over the dumb HTTP protocol. Dumb HTTP bypasses the smart protocol
+// negotiation, so only repositories readable by anonymous users are served.
+func allowDumbHTTP(r *http.Request) bool {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	if !cfg.HTTP.AllowDumbHTTP {
+		return false
+	}
+
+	be := backend.FromContext(ctx)
+	return be.AccessLevelForUser(ctx, mux.Vars(r)["repo"], nil) >= access.ReadOnlyAccess
+}
+
+// withDumbHTTP only calls next if dumb HTTP is allowed for the repository.
+func withDumbHTTP(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !allowDumbHTTP(r) {
+			renderNotFound(w, r)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
 func sendFile(contentType string, w http.ResponseWriter, r *http.Request) {
 	dir, file := mux.Vars(r)["dir"], mux.Vars(r)["file"]
 	reqFile := filepath.Join(dir, file)
@@ -629,6 +884,54 @@ func renderUnauthorized(w http.ResponseWriter, r *http.Request) {
 	renderStatus(http.StatusUnauthorized)(w, r)
 }
 
+// renderTokenExpired renders an unauthorized response with a hint to create
+// a new access token.
+func renderTokenExpired(w http.ResponseWriter, r *http.Request) {
+	const hint = "access token expired, create a new one with the token create command"
+	if strings.HasPrefix(mux.Vars(r)["file"], "info/lfs") {
+		renderJSON(w, http.StatusUnauthorized, lfs.ErrorResponse{
+			Message: hint,
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusUnauthorized)
+	io.WriteString(w, fmt.Sprintf("%d %s: %s", http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized), hint)) //nolint: errcheck
+}
+
+// renderArchived renders a forbidden response for a push to an archived
+// repository.
+func renderArchived(w http.ResponseWriter, _ *http.Request) {
+	// Git shows plain text error messages to the user.
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+	io.WriteString(w, fmt.Sprintf("%d %s: %s", http.StatusForbidden, http.StatusText(http.StatusForbidden), git.ErrArchivedPush)) //nolint: errcheck
+}
+
+// renderPushCreateDisabled renders a not found response for a push to a
+// repository that doesn't exist and can't be created by pushes.
+func renderPushCreateDisabled(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	io.WriteString(w, fmt.Sprintf("%d %s: %s", http.StatusNotFound, http.StatusText(http.StatusNotFound), git.ErrPushCreateDisabled)) //nolint: errcheck
+}
+
+// renderRepoBusy renders a service unavailable response for a repository
+// that's being renamed, or for an operation over the concurrency limits.
+func renderRepoBusy(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("Retry-After", "5")
+	if strings.HasPrefix(mux.Vars(r)["file"], "info/lfs") {
+		renderJSON(w, http.StatusServiceUnavailable, lfs.ErrorResponse{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	io.WriteString(w, fmt.Sprintf("%d %s: %s", http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable), err)) //nolint: errcheck
+}
+
 func renderForbidden(w http.ResponseWriter, r *http.Request) {
 	renderStatus(http.StatusForbidden)(w, r)
 }
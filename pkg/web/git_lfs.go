@@ -9,7 +9,6 @@ import (
 	"net/http"
 	"net/url"
 	"path"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -20,9 +19,11 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
 	"github.com/charmbracelet/soft-serve/pkg/lfs"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/storage"
 	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/webhook"
 	"github.com/gorilla/mux"
 )
 
@@ -32,7 +33,7 @@ import (
 // POST: /<repo>.git/info/lfs/objects/batch
 func serviceLfsBatch(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	logger := log.FromContext(ctx).WithPrefix("http.lfs")
+	logger := logr.Subsystem(ctx, "lfs", "http.lfs")
 
 	if !isLfs(r) {
 		logger.Errorf("invalid content type: %s", r.Header.Get("Content-Type"))
@@ -88,9 +89,14 @@ func serviceLfsBatch(w http.ResponseWriter, r *http.Request) {
 	cfg := config.FromContext(ctx)
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
-	// TODO: support S3 storage
-	repoID := strconv.FormatInt(repo.ID(), 10)
-	strg := storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
+	strg, err := storage.NewLFSStorage(cfg, repo.ID())
+	if err != nil {
+		logger.Error("error creating lfs storage", "repo", name, "err", err)
+		renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
 
 	baseHref := fmt.Sprintf("%s/%s/info/lfs/objects/basic", cfg.HTTP.PublicURL, name+".git")
 
@@ -255,11 +261,17 @@ func serviceLfsBasicDownload(w http.ResponseWriter, r *http.Request) {
 	oid := mux.Vars(r)["oid"]
 	repo := proto.RepositoryFromContext(ctx)
 	cfg := config.FromContext(ctx)
-	logger := log.FromContext(ctx).WithPrefix("http.lfs-basic")
+	logger := logr.Subsystem(ctx, "lfs", "http.lfs-basic")
 	datastore := store.FromContext(ctx)
 	dbx := db.FromContext(ctx)
-	repoID := strconv.FormatInt(repo.ID(), 10)
-	strg := storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
+	strg, err := storage.NewLFSStorage(cfg, repo.ID())
+	if err != nil {
+		logger.Error("error creating lfs storage", "repo", repo.Name(), "err", err)
+		renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
 
 	obj, err := datastore.GetLFSObjectByOid(ctx, dbx, repo.ID(), oid)
 	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
@@ -270,9 +282,15 @@ func serviceLfsBasicDownload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pointer := lfs.Pointer{Oid: oid}
-	f, err := strg.Open(path.Join("objects", pointer.RelativePath()))
-	if err != nil {
+	// Never serve corrupted objects, git-lfs doesn't always verify them.
+	f, err := lfs.OpenObject(strg, lfs.Pointer{Oid: oid, Size: obj.Size})
+	if errors.Is(err, lfs.ErrOIDMismatch) || errors.Is(err, lfs.ErrSizeMismatch) {
+		logger.Error("refusing to serve corrupted object", "oid", oid, "repo", repo.Name(), "err", err)
+		renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
+			Message: "object is corrupted",
+		})
+		return
+	} else if err != nil {
 		logger.Error("error opening object", "oid", oid, "err", err)
 		renderJSON(w, http.StatusNotFound, lfs.ErrorResponse{
 			Message: "object not found",
@@ -283,7 +301,9 @@ func serviceLfsBasicDownload(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/octet-stream")
 	w.Header().Set("Content-Length", strconv.FormatInt(obj.Size, 10))
 	defer f.Close() //nolint: errcheck
-	if _, err := io.Copy(w, f); err != nil {
+	n, err := io.Copy(w, f)
+	lfs.ObserveTransfer("http", lfs.DirectionDownload, n)
+	if err != nil {
 		logger.Error("error copying object to response", "oid", oid, "err", err)
 		renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
 			Message: "internal server error",
@@ -307,10 +327,7 @@ func serviceLfsBasicUpload(w http.ResponseWriter, r *http.Request) {
 	be := backend.FromContext(ctx)
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
-	logger := log.FromContext(ctx).WithPrefix("http.lfs-basic")
-	repo := proto.RepositoryFromContext(ctx)
-	repoID := strconv.FormatInt(repo.ID(), 10)
-	strg := storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
+	logger := logr.Subsystem(ctx, "lfs", "http.lfs-basic")
 	name := mux.Vars(r)["repo"]
 
 	defer r.Body.Close() //nolint: errcheck
@@ -337,20 +354,37 @@ func serviceLfsBasicUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pointer := lfs.Pointer{Oid: oid}
-	if _, err := strg.Put(path.Join("objects", pointer.RelativePath()), r.Body); err != nil {
-		logger.Error("error writing object", "oid", oid, "err", err)
+	size, err := strconv.ParseInt(r.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		logger.Error("error parsing content length", "err", err)
+		renderJSON(w, http.StatusBadRequest, lfs.ErrorResponse{
+			Message: "invalid content length",
+		})
+		return
+	}
+
+	strg, err := storage.NewLFSStorage(cfg, repo.ID())
+	if err != nil {
+		logger.Error("error creating lfs storage", "repo", name, "err", err)
 		renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
 			Message: "internal server error",
 		})
 		return
 	}
 
-	size, err := strconv.ParseInt(r.Header.Get("Content-Length"), 10, 64)
+	pointer := lfs.Pointer{Oid: oid, Size: size}
+	written, err := lfs.StoreObject(strg, pointer, r.Body)
+	lfs.ObserveTransfer("http", lfs.DirectionUpload, written)
 	if err != nil {
-		logger.Error("error parsing content length", "err", err)
-		renderJSON(w, http.StatusBadRequest, lfs.ErrorResponse{
-			Message: "invalid content length",
+		logger.Error("error writing object", "oid", oid, "err", err)
+		if errors.Is(err, lfs.ErrOIDMismatch) || errors.Is(err, lfs.ErrSizeMismatch) || errors.Is(err, lfs.ErrInvalidOIDFormat) {
+			renderJSON(w, http.StatusUnprocessableEntity, lfs.ErrorResponse{
+				Message: "object content doesn't match its oid",
+			})
+			return
+		}
+		renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
+			Message: "internal server error",
 		})
 		return
 	}
@@ -363,6 +397,16 @@ func serviceLfsBasicUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The object is verified and recorded, it won't go away if the webhook
+	// fails.
+	wh, err := webhook.NewLFSEvent(ctx, proto.UserFromContext(ctx), repo, webhook.LFSEventActionUpload, oid, size)
+	if err == nil {
+		err = webhook.SendEvent(ctx, wh)
+	}
+	if err != nil {
+		logger.Error("error sending lfs upload webhook", "repo", name, "oid", oid, "err", err)
+	}
+
 	renderStatus(http.StatusOK)(w, nil)
 }
 
@@ -375,7 +419,7 @@ func serviceLfsBasicVerify(w http.ResponseWriter, r *http.Request) {
 
 	var pointer lfs.Pointer
 	ctx := r.Context()
-	logger := log.FromContext(ctx).WithPrefix("http.lfs-basic")
+	logger := logr.Subsystem(ctx, "lfs", "http.lfs-basic")
 	repo := proto.RepositoryFromContext(ctx)
 	if repo == nil {
 		logger.Error("error getting repository from context")
@@ -397,8 +441,15 @@ func serviceLfsBasicVerify(w http.ResponseWriter, r *http.Request) {
 	cfg := config.FromContext(ctx)
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
-	repoID := strconv.FormatInt(repo.ID(), 10)
-	strg := storage.NewLocalStorage(filepath.Join(cfg.DataPath, "lfs", repoID))
+	strg, err := storage.NewLFSStorage(cfg, repo.ID())
+	if err != nil {
+		logger.Error("error creating lfs storage", "repo", repo.Name(), "err", err)
+		renderJSON(w, http.StatusInternalServerError, lfs.ErrorResponse{
+			Message: "internal server error",
+		})
+		return
+	}
+
 	if stat, err := strg.Stat(path.Join("objects", pointer.RelativePath())); err == nil {
 		// Verify object is in the database.
 		obj, err := datastore.GetLFSObjectByOid(ctx, dbx, repo.ID(), pointer.Oid)
@@ -462,7 +513,7 @@ func serviceLfsLocksCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	logger := log.FromContext(ctx).WithPrefix("http.lfs-locks")
+	logger := logr.Subsystem(ctx, "lfs", "http.lfs-locks")
 
 	var req lfs.LockCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -595,7 +646,7 @@ func serviceLfsLocksGet(w http.ResponseWriter, r *http.Request) {
 		cursor = 1
 	}
 
-	logger := log.FromContext(ctx).WithPrefix("http.lfs-locks")
+	logger := logr.Subsystem(ctx, "lfs", "http.lfs-locks")
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 	repo := proto.RepositoryFromContext(ctx)
@@ -609,6 +660,9 @@ func serviceLfsLocksGet(w http.ResponseWriter, r *http.Request) {
 
 	if id > 0 {
 		lock, err := datastore.GetLFSLockByID(ctx, dbx, id)
+		if err == nil && lock.RepoID != repo.ID() {
+			err = db.ErrRecordNotFound
+		}
 		if err != nil {
 			if errors.Is(err, db.ErrRecordNotFound) {
 				renderJSON(w, http.StatusNotFound, lfs.ErrorResponse{
@@ -738,7 +792,7 @@ func serviceLfsLocksVerify(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	logger := log.FromContext(ctx).WithPrefix("http.lfs-locks")
+	logger := logr.Subsystem(ctx, "lfs", "http.lfs-locks")
 	repo := proto.RepositoryFromContext(ctx)
 	if repo == nil {
 		logger.Error("error getting repository from context")
@@ -835,7 +889,7 @@ func serviceLfsLocksDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	logger := log.FromContext(ctx).WithPrefix("http.lfs-locks")
+	logger := logr.Subsystem(ctx, "lfs", "http.lfs-locks")
 	lockIDStr := mux.Vars(r)["lock_id"]
 	if lockIDStr == "" {
 		logger.Error("error getting lock id")
@@ -876,6 +930,9 @@ func serviceLfsLocksDelete(w http.ResponseWriter, r *http.Request) {
 
 	// The lock being deleted
 	lock, err := datastore.GetLFSLockByID(ctx, dbx, lockID)
+	if err == nil && lock.RepoID != repo.ID() {
+		err = db.ErrRecordNotFound
+	}
 	if err != nil {
 		logger.Error("error getting lock", "err", err)
 		renderJSON(w, http.StatusNotFound, lfs.ErrorResponse{
@@ -912,12 +969,12 @@ func serviceLfsLocksDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Force delete another user's lock (requires admin privileges)
+	// Force delete another user's lock (requires write access)
 	if req.Force {
-		if !user.IsAdmin() {
-			logger.Error("non-admin user attempted force delete", "user", user.Username())
+		if access.FromContext(ctx) < access.ReadWriteAccess {
+			logger.Error("user without write access attempted force delete", "user", user.Username())
 			renderJSON(w, http.StatusForbidden, lfs.ErrorResponse{
-				Message: "admin access required for force delete",
+				Message: "write access required for force delete",
 			})
 			return
 		}
@@ -930,7 +987,7 @@ func serviceLfsLocksDelete(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		renderJSON(w, http.StatusOK, l)
+		renderJSON(w, http.StatusOK, lfs.LockResponse{Lock: l})
 		return
 	}
 
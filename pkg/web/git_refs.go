@@ -0,0 +1,118 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// refCacheSize is the number of ref advertisements kept in memory.
+const refCacheSize = 256
+
+// refCacheMaxRequest is the size of the largest ls-refs request whose
+// response is cached.
+const refCacheMaxRequest = 64 << 10
+
+// refCache holds the ref advertisements of the repositories that opted in
+// the cache, keyed by repository and request, along with the state of the
+// refs they were generated from.
+var refCache, _ = lru.New[string, cachedRefAdvertisement](refCacheSize)
+
+//nolint:revive
+var gitHttpRefCacheCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "git_ref_cache_total",
+	Help:      "The total number of ref advertisements looked up in the ref cache by result",
+}, []string{"repo", "result"})
+
+type cachedRefAdvertisement struct {
+	state string
+	refs  []byte
+}
+
+// refCacheState returns the state of the refs of a repository, which keys
+// its cached ref advertisements, and whether the repository opted in the
+// cache. Errors are logged and the advertisement isn't cached.
+func refCacheState(ctx context.Context, repo string, dir string) (string, bool) {
+	logger := log.FromContext(ctx)
+	enabled, err := backend.FromContext(ctx).RefCacheEnabled(ctx, repo)
+	if err != nil {
+		logger.Error("error reading repository ref cache setting", "repo", repo, "err", err)
+		return "", false
+	}
+	if !enabled {
+		return "", false
+	}
+
+	state, err := git.RefsState(dir)
+	if err != nil {
+		logger.Error("error reading repository refs state", "repo", repo, "err", err)
+		return "", false
+	}
+
+	return state, true
+}
+
+// refCacheKey returns the cache key of a ref advertisement. It depends on the
+// request, the protocol the client asked for, and the filter capabilities.
+func refCacheKey(repo string, request string, protocol string, cmd git.ServiceCommand) string {
+	return strings.Join([]string{
+		repo,
+		protocol,
+		strconv.FormatBool(cmd.DisableFilter),
+		strings.Join(cmd.AllowedFilters, ","),
+		request,
+	}, "\x00")
+}
+
+// cachedRefs returns the cached ref advertisement of key, if it was generated
+// from the current state of the refs.
+func cachedRefs(repo string, key string, state string) ([]byte, bool) {
+	if c, ok := refCache.Get(key); ok && c.state == state {
+		gitHttpRefCacheCounter.WithLabelValues(repo, "hit").Inc()
+		return c.refs, true
+	}
+
+	gitHttpRefCacheCounter.WithLabelValues(repo, "miss").Inc()
+	return nil, false
+}
+
+// cacheRefs caches the ref advertisement of key generated from the refs
+// state. The state is read before running git, so refs changing in between
+// only make the next lookup miss.
+func cacheRefs(key string, state string, refs []byte) {
+	refCache.Add(key, cachedRefAdvertisement{state: state, refs: bytes.Clone(refs)})
+}
+
+// isLsRefs returns whether a protocol v2 request is an ls-refs command.
+func isLsRefs(req []byte) bool {
+	if len(req) < 4 {
+		return false
+	}
+
+	return bytes.HasPrefix(req[4:], []byte("command=ls-refs"))
+}
+
+// protocolVersion returns the highest protocol version of a Git-Protocol
+// header, 0 if there is none.
+func protocolVersion(protocol string) int {
+	var version int
+	for _, p := range strings.Split(protocol, ":") {
+		if strings.HasPrefix(p, "version=") {
+			if v, _ := strconv.Atoi(p[8:]); v > version {
+				version = v
+			}
+		}
+	}
+
+	return version
+}
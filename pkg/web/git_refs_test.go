@@ -0,0 +1,60 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/git"
+)
+
+func TestProtocolVersion(t *testing.T) {
+	tests := map[string]int{
+		"":                    0,
+		"version=1":           1,
+		"version=2":           2,
+		"foo=bar:version=2":   2,
+		"version=2:version=1": 2,
+		"version=x":           0,
+	}
+
+	for protocol, want := range tests {
+		if got := protocolVersion(protocol); got != want {
+			t.Errorf("protocolVersion(%q) = %d, want %d", protocol, got, want)
+		}
+	}
+}
+
+func TestIsLsRefs(t *testing.T) {
+	tests := map[string]bool{
+		"0014command=ls-refs\n0001000csymrefs\n0000": true,
+		"0011command=fetch\n0001000ddone\n0000":      false,
+		"0000":                                       false,
+		"00":                                         false,
+	}
+
+	for req, want := range tests {
+		if got := isLsRefs([]byte(req)); got != want {
+			t.Errorf("isLsRefs(%q) = %t, want %t", req, got, want)
+		}
+	}
+}
+
+func TestCachedRefs(t *testing.T) {
+	key := refCacheKey("repo1", "info/refs", "version=2", git.ServiceCommand{})
+	if _, ok := cachedRefs("repo1", key, "state1"); ok {
+		t.Fatal("cachedRefs() hit an empty cache")
+	}
+
+	cacheRefs(key, "state1", []byte("refs"))
+	if refs, ok := cachedRefs("repo1", key, "state1"); !ok || string(refs) != "refs" {
+		t.Errorf("cachedRefs() = %q, %t, want %q, true", refs, ok, "refs")
+	}
+
+	// The refs changed, or the filter capabilities did.
+	if _, ok := cachedRefs("repo1", key, "state2"); ok {
+		t.Error("cachedRefs() hit a stale advertisement")
+	}
+	other := refCacheKey("repo1", "info/refs", "version=2", git.ServiceCommand{DisableFilter: true})
+	if _, ok := cachedRefs("repo1", other, "state1"); ok {
+		t.Error("cachedRefs() hit the advertisement of other capabilities")
+	}
+}
@@ -2,33 +2,111 @@ package web
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"os"
+	"path/filepath"
+	"time"
 
 	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/gorilla/mux"
 )
 
+// readinessTimeout bounds the database ping of a readiness check.
+const readinessTimeout = 2 * time.Second
+
+// healthStatus is the result of a health check.
+type healthStatus struct {
+	Status string                  `json:"status"`
+	Checks map[string]healthStatus `json:"checks,omitempty"`
+}
+
+var (
+	healthOK    = healthStatus{Status: "ok"}
+	healthError = healthStatus{Status: "error"}
+)
+
 // HealthController registers the health check routes for the web server.
+// They're served without authentication.
 func HealthController(_ context.Context, r *mux.Router) {
+	r.HandleFunc("/healthz", getLiveness)
 	r.HandleFunc("/livez", getLiveness)
 	r.HandleFunc("/readyz", getReadiness)
 }
 
+// getLiveness reports whether the server is up.
 func getLiveness(w http.ResponseWriter, _ *http.Request) {
-	renderStatus(http.StatusOK)(w, nil)
+	renderHealth(w, healthOK)
 }
 
+// getReadiness reports whether the server can serve requests, that is, the
+// database is reachable and the repository storage is writable.
 func getReadiness(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := log.FromContext(ctx)
-	db := db.FromContext(ctx)
+	cfg := config.FromContext(ctx)
+	dbx := db.FromContext(ctx)
+
+	res := healthStatus{Status: "ok", Checks: map[string]healthStatus{
+		"database": healthOK,
+		"storage":  healthOK,
+	}}
 
-	if err := db.PingContext(ctx); err != nil {
+	pctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	if err := dbx.PingContext(pctx); err != nil {
 		logger.Error("error getting db readiness", "err", err)
-		renderStatus(http.StatusServiceUnavailable)(w, nil)
-		return
+		res.Status = "error"
+		res.Checks["database"] = healthError
 	}
 
-	renderStatus(http.StatusOK)(w, nil)
+	if err := checkStorage(filepath.Join(cfg.DataPath, "repos")); err != nil {
+		logger.Error("error getting storage readiness", "err", err)
+		res.Status = "error"
+		res.Checks["storage"] = healthError
+	}
+
+	renderHealth(w, res)
+}
+
+// checkStorage checks the repository directory exists and files can be
+// written in it.
+func checkStorage(dir string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return errors.New("not a directory: " + dir)
+	}
+
+	f, err := os.CreateTemp(dir, ".readyz-")
+	if err != nil {
+		return err
+	}
+	f.Close() //nolint: errcheck
+	return os.Remove(f.Name())
+}
+
+// renderHealth writes the result of a health check, with a 503 status if it
+// failed.
+func renderHealth(w http.ResponseWriter, res healthStatus) {
+	code := http.StatusOK
+	if res.Status != "ok" {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		log.Error("error encoding json", "err", err)
+	}
 }
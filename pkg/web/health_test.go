@@ -0,0 +1,63 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+func getHealth(t *testing.T, url string) (int, healthStatus) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close() //nolint: errcheck
+
+	var res healthStatus
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, res
+}
+
+func TestHealth(t *testing.T) {
+	srv, ctx := newTestServer(t, func(*config.Config) {})
+	cfg := config.FromContext(ctx)
+
+	for _, p := range []string{"/healthz", "/livez", "/readyz"} {
+		if code, res := getHealth(t, srv.URL+p); code != http.StatusOK || res.Status != "ok" {
+			t.Errorf("%s: got %d %+v, want 200 ok", p, code, res)
+		}
+	}
+
+	// Repository storage that can't be written to isn't ready.
+	reposDir := filepath.Join(cfg.DataPath, "repos")
+	if err := os.RemoveAll(reposDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(reposDir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	code, res := getHealth(t, srv.URL+"/readyz")
+	if code != http.StatusServiceUnavailable || res.Checks["storage"].Status != "error" || res.Checks["database"].Status != "ok" {
+		t.Errorf("unwritable storage: got %d %+v", code, res)
+	}
+
+	// Neither is a server without its database.
+	db.FromContext(ctx).Close() //nolint: errcheck
+	code, res = getHealth(t, srv.URL+"/readyz")
+	if code != http.StatusServiceUnavailable || res.Checks["database"].Status != "error" {
+		t.Errorf("closed database: got %d %+v", code, res)
+	}
+
+	// The server is still alive.
+	if code, _ := getHealth(t, srv.URL+"/healthz"); code != http.StatusOK {
+		t.Errorf("/healthz: got %d, want 200", code)
+	}
+}
@@ -5,12 +5,23 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"charm.land/log/v2"
 	"github.com/dustin/go-humanize"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+var requestSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "soft_serve",
+	Subsystem: "http",
+	Name:      "request_duration_seconds",
+	Help:      "The duration of HTTP requests by method and status code",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"method", "code"})
+
 // logWriter is a wrapper around http.ResponseWriter that allows us to capture
 // the HTTP status code and bytes written to the response.
 type logWriter struct {
@@ -78,6 +89,7 @@ func NewLoggingMiddleware(next http.Handler, logger *log.Logger) http.Handler {
 			"addr", r.RemoteAddr)
 		next.ServeHTTP(writer, r)
 		elapsed := time.Since(start)
+		requestSeconds.WithLabelValues(r.Method, strconv.Itoa(writer.code)).Observe(elapsed.Seconds())
 		logger.Debug("response",
 			"status", fmt.Sprintf("%d %s", writer.code, http.StatusText(writer.code)),
 			"bytes", humanize.Bytes(uint64(writer.bytes)), //nolint:gosec
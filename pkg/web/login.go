@@ -0,0 +1,212 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/auth"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/jwk"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+// sessionCookie is the name of the login session cookie.
+const sessionCookie = "soft_serve_session"
+
+// passwordSessionLifetime is the lifetime of sessions started with a
+// directory password.
+const passwordSessionLifetime = 24 * time.Hour
+
+// sessionClaims are the claims of a login session.
+type sessionClaims struct {
+	jwt.RegisteredClaims
+
+	// Access is the access level the directory groups of the user had at
+	// login.
+	Access access.AccessLevel `json:"access,omitempty"`
+}
+
+// LoginController registers the login routes of the configured OpenID
+// provider and directory.
+func LoginController(ctx context.Context, r *mux.Router) {
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+
+	oidc := cfg.HTTP.OIDC.Issuer != ""
+	if oidc {
+		p := newOIDCProvider(cfg.HTTP.OIDC.Issuer, http.DefaultClient)
+		r.HandleFunc("/auth/oidc/login", p.login).Methods(http.MethodGet)
+		r.HandleFunc("/auth/oidc/callback", p.callback).Methods(http.MethodGet)
+	}
+
+	directory := be.HasAuthenticator()
+	if directory {
+		r.HandleFunc("/auth/login", passwordLogin).Methods(http.MethodPost)
+	}
+
+	if oidc || directory {
+		r.HandleFunc("/auth/logout", logout).Methods(http.MethodGet, http.MethodPost)
+	}
+}
+
+// passwordLogin starts a session for a user with a directory password posted
+// as a form.
+func passwordLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	logger := logr.Subsystem(ctx, "http", "http.login")
+
+	username, password := r.PostFormValue("username"), r.PostFormValue("password")
+	user, err := be.AuthenticatePassword(ctx, username, password)
+	if err != nil {
+		if !errors.Is(err, auth.ErrInvalidCredentials) {
+			logger.Error("failed to authenticate directory user", "username", username, "err", err)
+		}
+		renderUnauthorized(w, r)
+		return
+	}
+
+	if err := checkRemoteAddr(r, user); err != nil {
+		renderForbidden(w, r)
+		return
+	}
+
+	if err := startSession(w, cfg, user, passwordSessionLifetime); err != nil {
+		logger.Error("failed to sign session", "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	http.Redirect(w, r, returnTo(r.PostFormValue("return_to")), http.StatusFound)
+}
+
+// logout ends the login session.
+func logout(w http.ResponseWriter, r *http.Request) {
+	setCookie(w, config.FromContext(r.Context()), sessionCookie, "", -1)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// returnTo returns the local path to redirect to after a login.
+func returnTo(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+		return "/"
+	}
+	return path
+}
+
+// startSession sets the login session cookie of user.
+func startSession(w http.ResponseWriter, cfg *config.Config, user proto.User, lifetime time.Duration) error {
+	now := time.Now()
+	session, err := signClaims(cfg, sessionClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   fmt.Sprintf("%s#%d", user.Username(), user.ID()),
+			Issuer:    sessionIssuer(cfg),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(lifetime)),
+		},
+		Access: backend.DirectoryAccess(user),
+	})
+	if err != nil {
+		return err
+	}
+
+	setCookie(w, cfg, sessionCookie, session, lifetime)
+	return nil
+}
+
+// parseSession returns the user of the login session cookie.
+func parseSession(r *http.Request) (proto.User, error) {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+
+	var claims sessionClaims
+	if err := parseClaims(cfg, c.Value, sessionIssuer(cfg), &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	username, _, _ := strings.Cut(claims.Subject, "#")
+	user, err := be.User(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions of deleted users don't carry over to new users of the same name.
+	if claims.Subject != fmt.Sprintf("%s#%d", user.Username(), user.ID()) {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Access > access.NoAccess {
+		user = backend.WithDirectoryAccess(user, claims.Access)
+	}
+
+	return user, nil
+}
+
+// The issuer of session tokens differs from the issuer of access tokens, so
+// they can't be used in place of each other.
+func sessionIssuer(cfg *config.Config) string {
+	return cfg.HTTP.PublicURL + "/auth/session"
+}
+
+// signClaims signs claims with the server key.
+func signClaims(cfg *config.Config, claims jwt.Claims) (string, error) {
+	kp, err := jwk.NewPair(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwk.SigningMethod, claims)
+	token.Header["kid"] = kp.JWK().KeyID
+	return token.SignedString(kp.PrivateKey())
+}
+
+// parseClaims verifies a token signed with signClaims and its issuer.
+func parseClaims(cfg *config.Config, raw, issuer string, claims jwt.Claims) error {
+	kp, err := config.KeyPair(cfg)
+	if err != nil {
+		return err
+	}
+
+	_, err = jwt.ParseWithClaims(raw, claims, func(*jwt.Token) (interface{}, error) {
+		return kp.CryptoPublicKey(), nil
+	},
+		jwt.WithValidMethods([]string{jwk.SigningMethod.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	return err
+}
+
+// setCookie sets an HTTP only cookie, secure when the public URL is HTTPS. A
+// negative maxAge deletes it.
+func setCookie(w http.ResponseWriter, cfg *config.Config, name, value string, maxAge time.Duration) {
+	c := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(cfg.HTTP.PublicURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(maxAge.Seconds()),
+	}
+	if maxAge < 0 {
+		c.MaxAge = -1
+	}
+	http.SetCookie(w, c)
+}
@@ -0,0 +1,103 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/auth"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+// fakeDirectory is an authenticator of users in the developers group.
+type fakeDirectory map[string]string
+
+func (d fakeDirectory) Authenticate(_ context.Context, username, password string) (*auth.Identity, error) {
+	if p, ok := d[username]; !ok || p != password {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return &auth.Identity{Username: username, Groups: []string{"CN=Developers,DC=example,DC=com"}}, nil
+}
+
+func newDirectoryServer(t *testing.T) (string, context.Context) {
+	t.Helper()
+	srv, ctx := newTestServer(t, func(cfg *config.Config) {
+		cfg.LDAP.URL = "ldap://ldap.example.com"
+		cfg.LDAP.BaseDN = "dc=example,dc=com"
+		cfg.LDAP.GroupAccess = []string{"read-write:cn=developers,dc=example,dc=com"}
+		cfg.LDAP.CreateUsers = true
+		cfg.LDAP.HTTP = true
+	})
+	backend.FromContext(ctx).SetAuthenticator(fakeDirectory{"alice": "hunter2"})
+	return srv.URL, ctx
+}
+
+func TestPasswordLogin(t *testing.T) {
+	srvURL, ctx := newDirectoryServer(t)
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := client.PostForm(srvURL+"/auth/login", url.Values{"username": {"Alice"}, "password": {"wrong"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() //nolint: errcheck
+	if resp.StatusCode != http.StatusUnauthorized || len(resp.Cookies()) != 0 {
+		t.Fatalf("login with a wrong password = %s, %v", resp.Status, resp.Cookies())
+	}
+
+	resp, err = client.PostForm(srvURL+"/auth/login", url.Values{"username": {"Alice"}, "password": {"hunter2"}, "return_to": {"//evil.example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() //nolint: errcheck
+	if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "/" {
+		t.Fatalf("login = %s to %q", resp.Status, resp.Header.Get("Location"))
+	}
+
+	var session *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == sessionCookie {
+			session = c
+		}
+	}
+	if session == nil {
+		t.Fatal("no session cookie")
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	req.AddCookie(session)
+	user, err := authenticate(req)
+	if err != nil || user.Username() != "alice" {
+		t.Fatalf("authenticate() = %v, %v, want alice", user, err)
+	}
+	if level := backend.DirectoryAccess(user); level != access.ReadWriteAccess {
+		t.Errorf("session access = %s, want %s", level, access.ReadWriteAccess)
+	}
+}
+
+func TestDirectoryPasswordHTTP(t *testing.T) {
+	_, ctx := newDirectoryServer(t)
+	be := backend.FromContext(ctx)
+
+	user, err := parseUsernamePassword(ctx, "alice", "hunter2")
+	if err != nil || user.Username() != "alice" {
+		t.Fatalf("parseUsernamePassword() = %v, %v, want alice", user, err)
+	}
+	if level := be.AccessLevelForUser(ctx, "repo", user); level != access.ReadWriteAccess {
+		t.Errorf("access = %s, want %s", level, access.ReadWriteAccess)
+	}
+
+	if _, err := parseUsernamePassword(ctx, "alice", "wrong"); err != ErrInvalidPassword {
+		t.Errorf("parseUsernamePassword() with a wrong password = %v, want %v", err, ErrInvalidPassword)
+	}
+
+	config.FromContext(ctx).LDAP.HTTP = false
+	if _, err := parseUsernamePassword(ctx, "alice", "hunter2"); err != ErrInvalidPassword {
+		t.Errorf("parseUsernamePassword() without ldap http = %v, want %v", err, ErrInvalidPassword)
+	}
+}
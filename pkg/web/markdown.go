@@ -0,0 +1,89 @@
+package web
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// markdownPolicy sanitizes rendered markdown. Raw HTML in markdown is already
+// dropped by the renderer, the policy makes sure nothing else gets through.
+var markdownPolicy = bluemonday.UGCPolicy()
+
+// renderMarkdown renders markdown to sanitized HTML. Relative links and
+// images are rewritten to rawBase, resolved from dir, the directory of the
+// markdown file in the repository.
+func renderMarkdown(src []byte, rawBase string, dir string) (template.HTML, error) {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+			parser.WithASTTransformers(util.Prioritized(linkRewriter{base: rawBase, dir: dir}, 100)),
+		),
+	)
+
+	var buf bytes.Buffer
+	if err := md.Convert(src, &buf); err != nil {
+		return "", err
+	}
+
+	return template.HTML(markdownPolicy.SanitizeBytes(buf.Bytes())), nil //nolint:gosec
+}
+
+// linkRewriter rewrites relative link and image destinations to a base URL.
+type linkRewriter struct {
+	base string
+	dir  string
+}
+
+// Transform implements parser.ASTTransformer.
+func (l linkRewriter) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) { //nolint:errcheck
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := n.(type) {
+		case *ast.Link:
+			n.Destination = l.rewrite(n.Destination)
+		case *ast.Image:
+			n.Destination = l.rewrite(n.Destination)
+		}
+
+		return ast.WalkContinue, nil
+	})
+}
+
+// rewrite returns the destination of a relative link under the base URL.
+// Absolute URLs and anchors are left alone, paths starting with a slash
+// start at the repository root.
+func (l linkRewriter) rewrite(dest []byte) []byte {
+	d := string(dest)
+	if d == "" || strings.HasPrefix(d, "#") {
+		return dest
+	}
+
+	u, err := url.Parse(d)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return dest
+	}
+
+	p := u.Path
+	if !strings.HasPrefix(p, "/") {
+		p = path.Join(l.dir, p)
+	}
+
+	// Cleaning a rooted path drops ".." elements escaping the repository.
+	u.Path = path.Clean("/" + p)
+	return []byte(l.base + u.String())
+}
@@ -0,0 +1,79 @@
+package web
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	const base = "https://example.com/repo/raw/main"
+	tests := []struct {
+		name   string
+		src    string
+		dir    string
+		want   []string
+		absent []string
+	}{
+		{
+			name: "relative image",
+			src:  "![logo](img/logo.png)",
+			dir:  "docs",
+			want: []string{`src="https://example.com/repo/raw/main/docs/img/logo.png"`},
+		},
+		{
+			name: "root link",
+			src:  "[license](/LICENSE \"License\")",
+			dir:  "docs",
+			want: []string{`href="https://example.com/repo/raw/main/LICENSE"`},
+		},
+		{
+			name: "escaping link",
+			src:  "[up](../../../etc/passwd)",
+			dir:  ".",
+			want: []string{`href="https://example.com/repo/raw/main/etc/passwd"`},
+		},
+		{
+			name: "absolute and anchor links",
+			src:  "[a](https://charm.sh) [b](#usage) [c](mailto:hi@charm.sh)",
+			dir:  ".",
+			want: []string{`href="https://charm.sh"`, `href="#usage"`, `href="mailto:hi@charm.sh"`},
+		},
+		{
+			name:   "raw html",
+			src:    "<script>alert(1)</script>\n\n<img src=x onerror=alert(1)>",
+			dir:    ".",
+			absent: []string{"<script", "onerror"},
+		},
+		{
+			name:   "javascript link",
+			src:    "[x](javascript:alert(1))",
+			dir:    ".",
+			absent: []string{"javascript:"},
+		},
+		{
+			name: "gfm",
+			src:  "| a |\n|---|\n| b |\n\n~~gone~~",
+			dir:  ".",
+			want: []string{"<table>", "<del>gone</del>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := renderMarkdown([]byte(tt.src), base, tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(string(html), w) {
+					t.Errorf("renderMarkdown() = %q, want %q in it", html, w)
+				}
+			}
+			for _, a := range tt.absent {
+				if strings.Contains(string(html), a) {
+					t.Errorf("renderMarkdown() = %q, want no %q in it", html, a)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,426 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// oidcStateCookie is the name of the cookie holding the state of a login
+	// in progress.
+	oidcStateCookie = "soft_serve_oidc"
+
+	// oidcLoginTimeout is the time a login can take at the provider.
+	oidcLoginTimeout = 10 * time.Minute
+
+	// oidcKeysRefreshInterval is the minimum time between fetches of the
+	// provider keys.
+	oidcKeysRefreshInterval = time.Minute
+)
+
+// oidcSigningMethods are the ID token signing algorithms accepted.
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// oidcProvider is an OpenID provider. Its metadata is discovered on the first
+// login and its keys are fetched again when a token uses an unknown key.
+type oidcProvider struct {
+	issuer string
+	client *http.Client
+
+	mu          sync.Mutex
+	meta        *oidcMetadata
+	keys        jose.JSONWebKeySet
+	keysFetched time.Time
+}
+
+// oidcMetadata is the provider metadata used for logins.
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcState is the state of a login in progress, kept in a signed cookie.
+type oidcState struct {
+	jwt.RegisteredClaims
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	ReturnTo string `json:"return_to"`
+}
+
+func newOIDCProvider(issuer string, client *http.Client) *oidcProvider {
+	return &oidcProvider{issuer: issuer, client: client}
+}
+
+// login redirects to the provider.
+func (p *oidcProvider) login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	logger := logr.Subsystem(ctx, "http", "http.oidc")
+
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		logger.Error("failed to discover oidc provider", "err", err)
+		renderStatus(http.StatusBadGateway)(w, r)
+		return
+	}
+
+	now := time.Now()
+	st := oidcState{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    oidcStateIssuer(cfg),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(oidcLoginTimeout)),
+		},
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: randomString(),
+		ReturnTo: returnTo(r.URL.Query().Get("return_to")),
+	}
+	signed, err := signClaims(cfg, st)
+	if err != nil {
+		logger.Error("failed to sign oidc state", "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+	setCookie(w, cfg, oidcStateCookie, signed, oidcLoginTimeout)
+
+	challenge := sha256.Sum256([]byte(st.Verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {cfg.HTTP.OIDC.ClientID},
+		"redirect_uri":          {oidcRedirectURL(cfg)},
+		"scope":                 {strings.Join(append([]string{"openid"}, cfg.HTTP.OIDC.Scopes...), " ")},
+		"state":                 {st.State},
+		"nonce":                 {st.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	http.Redirect(w, r, meta.AuthorizationEndpoint+sep+q.Encode(), http.StatusFound)
+}
+
+// callback completes a login, and starts a session for the user mapped to the
+// ID token claims.
+func (p *oidcProvider) callback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	logger := logr.Subsystem(ctx, "http", "http.oidc")
+
+	c, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		renderBadRequest(w, r)
+		return
+	}
+	setCookie(w, cfg, oidcStateCookie, "", -1)
+
+	var st oidcState
+	if err := parseClaims(cfg, c.Value, oidcStateIssuer(cfg), &st); err != nil || st.State != r.URL.Query().Get("state") {
+		logger.Error("invalid oidc state", "err", err)
+		renderBadRequest(w, r)
+		return
+	}
+
+	if e := r.URL.Query().Get("error"); e != "" {
+		logger.Info("oidc login failed", "error", e, "description", r.URL.Query().Get("error_description"))
+		renderUnauthorized(w, r)
+		return
+	}
+
+	rawIDToken, err := p.exchange(ctx, cfg, r.URL.Query().Get("code"), st.Verifier)
+	if err != nil {
+		logger.Error("failed to exchange oidc code", "err", err)
+		renderUnauthorized(w, r)
+		return
+	}
+
+	claims, err := p.verify(ctx, rawIDToken, cfg.HTTP.OIDC.ClientID, st.Nonce)
+	if err != nil {
+		logger.Error("invalid oidc id token", "err", err)
+		renderUnauthorized(w, r)
+		return
+	}
+
+	user, err := oidcUser(ctx, be, cfg.HTTP.OIDC, claims)
+	if err != nil {
+		logger.Info("oidc login refused", "sub", claims["sub"], "err", err)
+		renderForbidden(w, r)
+		return
+	}
+
+	if err := checkRemoteAddr(r, user); err != nil {
+		renderForbidden(w, r)
+		return
+	}
+
+	lifetime := time.Duration(cfg.HTTP.OIDC.SessionLifetime) * time.Second
+	if err := startSession(w, cfg, user, lifetime); err != nil {
+		logger.Error("failed to sign session", "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	http.Redirect(w, r, st.ReturnTo, http.StatusFound)
+}
+
+// metadata returns the provider metadata.
+func (p *oidcProvider) metadata(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	var meta oidcMetadata
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(meta.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("provider issuer %q doesn't match %q", meta.Issuer, p.issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("incomplete provider metadata")
+	}
+
+	p.meta = &meta
+	return p.meta, nil
+}
+
+// key returns the provider key with the given ID, fetching the provider keys
+// if it's unknown.
+func (p *oidcProvider) key(ctx context.Context, kid string) (interface{}, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	find := func() interface{} {
+		if kid == "" && len(p.keys.Keys) == 1 {
+			return p.keys.Keys[0].Key
+		}
+		for _, k := range p.keys.Key(kid) {
+			if k.Use == "" || k.Use == "sig" {
+				return k.Key
+			}
+		}
+		return nil
+	}
+
+	if k := find(); k != nil {
+		return k, nil
+	}
+
+	if time.Since(p.keysFetched) < oidcKeysRefreshInterval {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := p.getJSON(ctx, meta.JWKSURI, &keys); err != nil {
+		return nil, err
+	}
+	p.keys, p.keysFetched = keys, time.Now()
+
+	if k := find(); k != nil {
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// exchange exchanges an authorization code for an ID token.
+func (p *oidcProvider) exchange(ctx context.Context, cfg *config.Config, code, verifier string) (string, error) {
+	if code == "" {
+		return "", errors.New("missing code")
+	}
+
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {oidcRedirectURL(cfg)},
+		"code_verifier": {verifier},
+		"client_id":     {cfg.HTTP.OIDC.ClientID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if cfg.HTTP.OIDC.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(cfg.HTTP.OIDC.ClientID), url.QueryEscape(cfg.HTTP.OIDC.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() //nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("token endpoint: %s: %s", resp.Status, body)
+	}
+
+	var tok struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", err
+	}
+	if tok.IDToken == "" {
+		return "", errors.New("token response without an id token")
+	}
+
+	return tok.IDToken, nil
+}
+
+// verify verifies an ID token and returns its claims.
+func (p *oidcProvider) verify(ctx context.Context, raw, clientID, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	); err != nil {
+		return nil, err
+	}
+
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, errors.New("invalid nonce")
+	}
+
+	return claims, nil
+}
+
+func (p *oidcProvider) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", u, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// oidcUser returns the user mapped to the ID token claims. With the email
+// claim, it's the user with the whole email address, which the provider must
+// have verified. Other claims are usernames as is. Unknown users are created
+// when enabled, those of email addresses are named after the
+// preferred_username claim and can't take over an existing user.
+func oidcUser(ctx context.Context, be *backend.Backend, cfg config.OIDCConfig, claims jwt.MapClaims) (proto.User, error) {
+	logger := logr.Subsystem(ctx, "http", "http.oidc")
+	if cfg.UsernameClaim == "email" {
+		email, _ := claims["email"].(string)
+		if email == "" {
+			return nil, errors.New("id token has no email address")
+		}
+		if verified, _ := claims["email_verified"].(bool); !verified {
+			return nil, errors.New("email address isn't verified")
+		}
+
+		user, err := be.UserByEmail(ctx, email)
+		if !errors.Is(err, proto.ErrUserNotFound) || !cfg.CreateUsers {
+			return user, err
+		}
+
+		username, _ := claims["preferred_username"].(string)
+		username = strings.ToLower(username)
+		if err := utils.ValidateUsername(username); err != nil {
+			return nil, fmt.Errorf("claim %q: %w", "preferred_username", err)
+		}
+
+		// Creating fails if the username is taken.
+		user, err = be.CreateUser(ctx, username, proto.UserOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if err := be.SetEmail(ctx, username, email); err != nil {
+			return nil, err
+		}
+		logger.Info("created user on oidc login", "username", username, "email", email)
+
+		return be.User(ctx, username)
+	}
+
+	v, _ := claims[cfg.UsernameClaim].(string)
+	username := strings.ToLower(v)
+	if err := utils.ValidateUsername(username); err != nil {
+		return nil, fmt.Errorf("claim %q: %w", cfg.UsernameClaim, err)
+	}
+
+	user, err := be.User(ctx, username)
+	if errors.Is(err, proto.ErrUserNotFound) && cfg.CreateUsers {
+		user, err = be.CreateUser(ctx, username, proto.UserOptions{})
+		if err == nil {
+			logger.Info("created user on oidc login", "username", username)
+		}
+	}
+
+	return user, err
+}
+
+func oidcRedirectURL(cfg *config.Config) string {
+	return cfg.HTTP.PublicURL + "/auth/oidc/callback"
+}
+
+// The issuer of state tokens differs from the issuers of access and session
+// tokens, so they can't be used in place of each other.
+func oidcStateIssuer(cfg *config.Config) string {
+	return cfg.HTTP.PublicURL + "/auth/oidc"
+}
+
+func randomString() string {
+	buf := make([]byte, 32)
+	rand.Read(buf) //nolint: errcheck
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,275 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/keygen"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/migrate"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/store/database"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang-jwt/jwt/v5"
+	_ "modernc.org/sqlite" // sqlite driver
+)
+
+// fakeProvider is an OpenID provider issuing ID tokens with the given claims.
+type fakeProvider struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+
+	codes map[string]url.Values
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &fakeProvider{key: key, codes: map[string]url.Values{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{ //nolint: errcheck
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{ //nolint: errcheck
+			{Key: &key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		code := randomString()
+		p.codes[code] = q
+		http.Redirect(w, r, q.Get("redirect_uri")+"?"+url.Values{"code": {code}, "state": {q.Get("state")}}.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		q, ok := p.codes[r.FormValue("code")]
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		id, secret, _ := r.BasicAuth()
+		if !ok || q.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(sum[:]) || id != "soft-serve" || secret != "s3cret" {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   p.URL,
+			"aud":   q.Get("client_id"),
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": q.Get("nonce"),
+		}
+		for k, v := range p.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Error(err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed}) //nolint: errcheck
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+func newOIDCServer(t *testing.T, issuer string, createUsers bool) (*httptest.Server, context.Context) {
+	t.Helper()
+	return newTestServer(t, func(cfg *config.Config) {
+		cfg.HTTP.OIDC.Issuer = issuer
+		cfg.HTTP.OIDC.ClientID = "soft-serve"
+		cfg.HTTP.OIDC.ClientSecret = "s3cret"
+		cfg.HTTP.OIDC.CreateUsers = createUsers
+	})
+}
+
+// newTestServer returns a server with the configuration changed by setup.
+func newTestServer(t *testing.T, setup func(*config.Config)) (*httptest.Server, context.Context) {
+	t.Helper()
+	var h http.Handler
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("SOFT_SERVE_DATA_PATH", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.HTTP.PublicURL = srv.URL
+	setup(cfg)
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keygen.New(cfg.SSH.KeyPath, keygen.WithKeyType(keygen.Ed25519), keygen.WithWrite()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := config.WithContext(context.TODO(), cfg)
+	ctx = log.WithContext(ctx, log.New(io.Discard))
+	dbx, err := db.Open(ctx, cfg.DB.Driver, cfg.DB.DataSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbx.Close() }) //nolint: errcheck
+	if err := migrate.Migrate(ctx, dbx); err != nil {
+		t.Fatal(err)
+	}
+	dbstore := database.New(ctx, dbx)
+	ctx = db.WithContext(ctx, dbx)
+	ctx = store.WithContext(ctx, dbstore)
+	ctx = backend.WithContext(ctx, backend.New(ctx, cfg, dbx, dbstore))
+
+	h = NewRouter(ctx)
+	return srv, ctx
+}
+
+// oidcLogin logs in and returns the final response and the session cookie.
+func oidcLogin(t *testing.T, srv *httptest.Server) (*http.Response, *http.Cookie) {
+	t.Helper()
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	resp, err := client.Get(srv.URL + "/auth/oidc/login?return_to=/livez")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() //nolint: errcheck
+
+	u, _ := url.Parse(srv.URL)
+	for _, c := range jar.Cookies(u) {
+		if c.Name == sessionCookie {
+			return resp, c
+		}
+	}
+	return resp, nil
+}
+
+func TestOIDCLogin(t *testing.T) {
+	p := newFakeProvider(t)
+	p.claims = jwt.MapClaims{"sub": "1234", "email": "Jane.Doe@example.com", "email_verified": true, "preferred_username": "Jane-Doe"}
+	srv, ctx := newOIDCServer(t, p.URL, true)
+
+	resp, session := oidcLogin(t, srv)
+	if resp.StatusCode != http.StatusOK || resp.Request.URL.Path != "/livez" {
+		t.Fatalf("login ended at %s with %s", resp.Request.URL, resp.Status)
+	}
+	if session == nil {
+		t.Fatal("no session cookie")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	req.AddCookie(session)
+	user, err := authenticate(req)
+	if err != nil || user.Username() != "jane-doe" || user.Email() != "Jane.Doe@example.com" {
+		t.Errorf("authenticate() = %v, %v, want jane-doe with the email address", user, err)
+	}
+
+	// The next login matches the email address.
+	p.claims = jwt.MapClaims{"sub": "1234", "email": "jane.doe@example.com", "email_verified": true, "preferred_username": "other"}
+	if resp, session := oidcLogin(t, srv); resp.StatusCode != http.StatusOK || session == nil {
+		t.Fatalf("second login = %s", resp.Status)
+	}
+	if _, err := backend.FromContext(ctx).User(ctx, "other"); err == nil {
+		t.Error("a second user was created for the same email address")
+	}
+
+	// Sessions can't be used as access tokens.
+	req = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+session.Value)
+	if _, err := authenticate(req); err == nil {
+		t.Error("session cookie was accepted as a bearer token")
+	}
+}
+
+func TestOIDCLoginRefused(t *testing.T) {
+	p := newFakeProvider(t)
+	srv, ctx := newOIDCServer(t, p.URL, false)
+	be := backend.FromContext(ctx)
+	if _, err := be.CreateUser(ctx, "alice", proto.UserOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := be.SetEmail(ctx, "alice", "alice@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		claims jwt.MapClaims
+		status int
+	}{
+		{"existing user", jwt.MapClaims{"email": "alice@example.com", "email_verified": true}, http.StatusOK},
+		{"unknown user", jwt.MapClaims{"email": "bob@example.com", "email_verified": true}, http.StatusForbidden},
+		{"unverified email", jwt.MapClaims{"email": "alice@example.com", "email_verified": false}, http.StatusForbidden},
+		{"no email_verified claim", jwt.MapClaims{"email": "alice@example.com"}, http.StatusForbidden},
+		{"other domain", jwt.MapClaims{"email": "alice@other.org", "email_verified": true}, http.StatusForbidden},
+		{"local part of an admin", jwt.MapClaims{"email": "admin@evil", "email_verified": true}, http.StatusForbidden},
+		{"wrong audience", jwt.MapClaims{"email": "alice@example.com", "email_verified": true, "aud": "other"}, http.StatusUnauthorized},
+		{"expired", jwt.MapClaims{"email": "alice@example.com", "email_verified": true, "exp": time.Now().Add(-time.Minute).Unix()}, http.StatusUnauthorized},
+		{"wrong nonce", jwt.MapClaims{"email": "alice@example.com", "email_verified": true, "nonce": "replayed"}, http.StatusUnauthorized},
+	}
+
+	for _, c := range cases {
+		p.claims = c.claims
+		resp, session := oidcLogin(t, srv)
+		if resp.StatusCode != c.status || (session != nil) != (c.status == http.StatusOK) {
+			t.Errorf("%s: login = %s, session %t, want %d", c.name, resp.Status, session != nil, c.status)
+		}
+	}
+}
+
+func TestOIDCLoginNoTakeover(t *testing.T) {
+	p := newFakeProvider(t)
+	srv, ctx := newOIDCServer(t, p.URL, true)
+	be := backend.FromContext(ctx)
+	if _, err := be.CreateUser(ctx, "alice", proto.UserOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Created users are named after preferred_username, which can't name an
+	// existing user.
+	for _, claims := range []jwt.MapClaims{
+		{"email": "admin@evil", "email_verified": true},
+		{"email": "admin@evil", "email_verified": true, "preferred_username": "admin"},
+		{"email": "alice@other.org", "email_verified": true, "preferred_username": "alice"},
+	} {
+		p.claims = claims
+		resp, session := oidcLogin(t, srv)
+		if resp.StatusCode != http.StatusForbidden || session != nil {
+			t.Errorf("%v: login = %s, session %t, want refused", claims, resp.Status, session != nil)
+		}
+	}
+
+	for _, username := range []string{"admin", "alice"} {
+		u, err := be.User(ctx, username)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Email() != "" {
+			t.Errorf("%s got the email address %q", username, u.Email())
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// NewProxyHeadersHandler returns a middleware that sets the remote address of
+// requests from trusted proxies to the client address of their Forwarded or
+// X-Forwarded-For header. The headers of other clients are ignored so they
+// can't spoof their address.
+func NewProxyHeadersHandler(proxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(proxies) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if addr := forwardedAddr(r, proxies); addr != r.RemoteAddr {
+				r2 := new(http.Request)
+				*r2 = *r
+				r2.RemoteAddr = addr
+				r = r2
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedAddr returns the client address of a request. Requests from
+// trusted proxies are attributed to the last address of their forwarding
+// chain that isn't a trusted proxy. The Forwarded header takes precedence
+// over X-Forwarded-For.
+func forwardedAddr(r *http.Request, proxies []netip.Prefix) string {
+	trusted := func(ip netip.Addr) bool {
+		for _, p := range proxies {
+			if p.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ip, ok := parseHop(r.RemoteAddr)
+	if !ok || !trusted(ip) {
+		return r.RemoteAddr
+	}
+
+	hops := forwardedFor(r.Header.Values("Forwarded"))
+	if len(hops) == 0 {
+		hops = splitHeader(r.Header.Values("X-Forwarded-For"))
+	}
+
+	addr := r.RemoteAddr
+	for i := len(hops) - 1; i >= 0; i-- {
+		// Stop at obfuscated or unknown hops, the client isn't known past
+		// them.
+		ip, ok := parseHop(hops[i])
+		if !ok {
+			break
+		}
+
+		addr = ip.String()
+		if !trusted(ip) {
+			break
+		}
+	}
+
+	return addr
+}
+
+// forwardedFor returns the "for" parameters of Forwarded headers, see RFC
+// 7239.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, elem := range splitHeader(values) {
+		for _, pair := range strings.Split(elem, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(k, "for") {
+				hops = append(hops, strings.Trim(v, `"`))
+			}
+		}
+	}
+	return hops
+}
+
+// splitHeader returns the comma separated elements of header values.
+func splitHeader(values []string) []string {
+	var elems []string
+	for _, v := range values {
+		for _, e := range strings.Split(v, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				elems = append(elems, e)
+			}
+		}
+	}
+	return elems
+}
+
+// parseHop parses the IP address of a hop, with or without a port. IPv6
+// addresses may be in brackets.
+func parseHop(hop string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		hop = host
+	}
+
+	ip, err := netip.ParseAddr(strings.Trim(hop, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return ip.WithZone("").Unmap(), true
+}
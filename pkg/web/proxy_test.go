@@ -0,0 +1,73 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestForwardedAddr(t *testing.T) {
+	proxies := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.1/32"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+
+	cases := []struct {
+		name    string
+		remote  string
+		headers map[string][]string
+		want    string
+	}{
+		{"untrusted client", "203.0.113.5:1234", map[string][]string{"X-Forwarded-For": {"198.51.100.1"}}, "203.0.113.5:1234"},
+		{"untrusted forwarded", "203.0.113.5:1234", map[string][]string{"Forwarded": {"for=198.51.100.1"}}, "203.0.113.5:1234"},
+		{"no headers", "10.0.0.1:1234", nil, "10.0.0.1:1234"},
+		{"x-forwarded-for", "10.0.0.1:1234", map[string][]string{"X-Forwarded-For": {"198.51.100.1"}}, "198.51.100.1"},
+		{"spoofed hops", "10.0.0.1:1234", map[string][]string{"X-Forwarded-For": {"1.1.1.1, 198.51.100.1, 192.168.1.1"}}, "198.51.100.1"},
+		{"multiple headers", "10.0.0.1:1234", map[string][]string{"X-Forwarded-For": {"1.1.1.1", "198.51.100.1"}}, "198.51.100.1"},
+		{"all trusted", "10.0.0.1:1234", map[string][]string{"X-Forwarded-For": {"192.168.1.2, 192.168.1.1"}}, "192.168.1.2"},
+		{"forwarded", "10.0.0.1:1234", map[string][]string{"Forwarded": {`for=192.0.2.60;proto=http;by=203.0.113.43`}}, "192.0.2.60"},
+		{"forwarded ipv6", "[2001:db8::2]:443", map[string][]string{"Forwarded": {`for="[2001:db8:cafe::17]:4711"`}}, "2001:db8:cafe::17"},
+		{"forwarded chain", "10.0.0.1:1234", map[string][]string{"Forwarded": {`For="192.0.2.43:47011", for=192.168.1.1`}}, "192.0.2.43"},
+		{"forwarded precedence", "10.0.0.1:1234", map[string][]string{
+			"Forwarded":       {"for=192.0.2.60"},
+			"X-Forwarded-For": {"198.51.100.1"},
+		}, "192.0.2.60"},
+		{"unknown hop", "10.0.0.1:1234", map[string][]string{"Forwarded": {"for=198.51.100.1, for=unknown, for=192.168.1.1"}}, "192.168.1.1"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = c.remote
+			for k, vs := range c.headers {
+				for _, v := range vs {
+					req.Header.Add(k, v)
+				}
+			}
+			if got := forwardedAddr(req, proxies); got != c.want {
+				t.Errorf("forwardedAddr() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestProxyHeadersHandler(t *testing.T) {
+	var got string
+	h := NewProxyHeadersHandler([]netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")})(
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			got = r.RemoteAddr
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if got != "198.51.100.1" {
+		t.Errorf("RemoteAddr = %q, want %q", got, "198.51.100.1")
+	}
+	if req.RemoteAddr != "10.0.0.1:1234" {
+		t.Errorf("original request was modified: %q", req.RemoteAddr)
+	}
+}
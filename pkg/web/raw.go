@@ -0,0 +1,239 @@
+package web
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"charm.land/log/v2"
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/gorilla/mux"
+)
+
+// rawSniffLen is the number of bytes read to detect the content type of a
+// raw file, same as git uses to detect binary files.
+const rawSniffLen = 8000
+
+// rawContentTypes are the content types raw files are served as. Files are
+// served from the same origin as the login session, so types that can run
+// scripts, such as HTML and SVG, are served as plain text instead.
+var rawContentTypes = map[string]struct{}{
+	"image/avif":      {},
+	"image/bmp":       {},
+	"image/gif":       {},
+	"image/jpeg":      {},
+	"image/png":       {},
+	"image/webp":      {},
+	"image/x-icon":    {},
+	"audio/mpeg":      {},
+	"audio/ogg":       {},
+	"audio/wave":      {},
+	"video/mp4":       {},
+	"video/webm":      {},
+	"application/pdf": {},
+}
+
+// rawContentType returns the content type of a raw file from its name and
+// its first bytes.
+func rawContentType(name string, head []byte) string {
+	ct := mime.TypeByExtension(path.Ext(name))
+	if ct == "" {
+		ct = http.DetectContentType(head)
+	}
+
+	if mt, _, err := mime.ParseMediaType(ct); err == nil {
+		if _, ok := rawContentTypes[mt]; ok {
+			return mt
+		}
+	}
+
+	if isBin, _ := gitb.IsBinary(bytes.NewReader(head)); isBin {
+		return "application/octet-stream"
+	}
+
+	return "text/plain; charset=utf-8"
+}
+
+// getRawFile streams a file of a repository at a reference. The route path
+// is the reference followed by the file path.
+func getRawFile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	repoName := mux.Vars(r)["repo"]
+
+	gr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", repoName, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	ref, fp, commit, te, err := lookupFile(gr, mux.Vars(r)["path"])
+	if err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	// The file can only change with the commit.
+	etag := strconv.Quote(commit.ID.String())
+	cacheControl := "no-cache"
+	if ref == commit.ID.String() {
+		// Commits never change, references do.
+		cacheControl = "max-age=31536000, immutable"
+	}
+	if repo.IsPrivate() {
+		cacheControl = "private, " + cacheControl
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(te.Size(), 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+
+	rw := &rawWriter{w: w, name: te.Name()}
+	var stderr bytes.Buffer
+	if err := te.File().Pipeline(rw, &stderr); err != nil {
+		logger.Error("failed to read file", "repo", repoName, "commit", commit.ID, "path", fp, "err", err, "stderr", stderr.String())
+		if !rw.wroteHeader {
+			w.Header().Del("Content-Length")
+			renderInternalServerError(w, r)
+		}
+		return
+	}
+
+	if err := rw.flush(); err != nil {
+		logger.Error("failed to write file", "repo", repoName, "path", fp, "err", err)
+	}
+}
+
+// lookupFile returns the file at a route path made of a reference and a file
+// path, along with the reference, the file path, and the commit.
+func lookupFile(r *gitb.Repository, p string) (string, string, *gitb.Commit, *gitb.TreeEntry, error) {
+	ref, fp, err := splitRefPath(r, p)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+
+	commit, tree, err := lookupTree(r, ref)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+
+	te, err := tree.TreeEntry(fp)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+	if te.Type() != "blob" {
+		return "", "", nil, nil, gitb.ErrFileNotFound
+	}
+
+	return ref, fp, commit, te, nil
+}
+
+// lookupTree returns the commit of a reference and its tree.
+func lookupTree(r *gitb.Repository, ref string) (*gitb.Commit, *gitb.Tree, error) {
+	// Don't let the reference pass as an option to git.
+	if strings.HasPrefix(ref, "-") {
+		return nil, nil, gitb.ErrFileNotFound
+	}
+
+	commit, err := r.CommitByRevision(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tree, err := r.LsTree(commit.ID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return commit, tree, nil
+}
+
+// splitRefPath splits a reference and a file path joined by a slash.
+// Branches and tags can contain slashes themselves, so the longest one the
+// path starts with wins. Otherwise, the reference is the first path element,
+// such as a commit hash.
+func splitRefPath(r *gitb.Repository, p string) (string, string, error) {
+	refs, err := r.References()
+	if err != nil {
+		return "", "", err
+	}
+
+	var ref string
+	for _, rf := range refs {
+		name := rf.Name().Short()
+		if (rf.IsBranch() || rf.IsTag()) && len(name) > len(ref) && strings.HasPrefix(p, name+"/") {
+			ref = name
+		}
+	}
+
+	if ref == "" {
+		var ok bool
+		ref, _, ok = strings.Cut(p, "/")
+		// Don't let the reference pass as an option to git.
+		if !ok || strings.HasPrefix(ref, "-") {
+			return "", "", gitb.ErrFileNotFound
+		}
+	}
+
+	fp := strings.TrimPrefix(p, ref+"/")
+	if fp == "" {
+		return "", "", gitb.ErrFileNotFound
+	}
+
+	return ref, fp, nil
+}
+
+// rawWriter holds back the first bytes of a raw file to detect its content
+// type before writing the response header.
+type rawWriter struct {
+	w           http.ResponseWriter
+	name        string
+	head        []byte
+	wroteHeader bool
+}
+
+// Write implements io.Writer.
+func (rw *rawWriter) Write(p []byte) (int, error) {
+	if rw.wroteHeader {
+		return rw.w.Write(p)
+	}
+
+	n := min(len(p), rawSniffLen-len(rw.head))
+	rw.head = append(rw.head, p[:n]...)
+	if len(rw.head) < rawSniffLen {
+		return len(p), nil
+	}
+
+	if err := rw.flush(); err != nil {
+		return 0, err
+	}
+
+	m, err := rw.w.Write(p[n:])
+	return n + m, err
+}
+
+// flush writes the response header and the bytes held back.
+func (rw *rawWriter) flush() error {
+	if rw.wroteHeader {
+		return nil
+	}
+
+	rw.wroteHeader = true
+	rw.w.Header().Set("Content-Type", rawContentType(rw.name, rw.head))
+	rw.w.WriteHeader(http.StatusOK)
+	_, err := rw.w.Write(rw.head)
+	return err
+}
@@ -0,0 +1,29 @@
+package web
+
+import "testing"
+
+func TestRawContentType(t *testing.T) {
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+	tests := []struct {
+		name string
+		file string
+		head []byte
+		want string
+	}{
+		{"text", "README.md", []byte("# Hello"), "text/plain; charset=utf-8"},
+		{"image", "logo.png", png, "image/png"},
+		{"sniffed image", "logo", png, "image/png"},
+		{"html", "index.html", []byte("<html><script>alert(1)</script>"), "text/plain; charset=utf-8"},
+		{"sniffed html", "index", []byte("<html><script>alert(1)</script>"), "text/plain; charset=utf-8"},
+		{"svg", "logo.svg", []byte("<svg><script>alert(1)</script></svg>"), "text/plain; charset=utf-8"},
+		{"binary", "app.bin", []byte("\x7fELF\x00\x00"), "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rawContentType(tt.file, tt.head); got != tt.want {
+				t.Errorf("rawContentType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"charm.land/log/v2"
+	gitb "github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/gorilla/mux"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+var repoPageTpl = template.Must(template.New("repo").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{ .Name }}</title>
+    <link rel="alternate" type="application/atom+xml" title="{{ .Name }} commits" href="{{ .BaseURL }}.git/commits.atom">
+    <style>
+        body { max-width: 60em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.5; }
+        pre { overflow: auto; padding: 1em; background: #f6f8fa; }
+        img { max-width: 100%; }
+        table { border-collapse: collapse; }
+        td, th { border: 1px solid #d0d7de; padding: .3em .7em; }
+        .badge { padding: .1em .5em; border: 1px solid #9a6700; border-radius: 1em; color: #9a6700; font-size: .5em; vertical-align: middle; }
+        .languages { display: flex; height: .5em; border-radius: .25em; overflow: hidden; margin-bottom: .3em; }
+        .languages span:nth-child(6n+1) { background: #0969da; }
+        .languages span:nth-child(6n+2) { background: #1a7f37; }
+        .languages span:nth-child(6n+3) { background: #9a6700; }
+        .languages span:nth-child(6n+4) { background: #cf222e; }
+        .languages span:nth-child(6n+5) { background: #8250df; }
+        .languages span:nth-child(6n+6) { background: #57606a; }
+        .avatar { width: 1.5em; height: 1.5em; border-radius: .2em; vertical-align: middle; }
+    </style>
+</head>
+<body>
+<h1><img class="avatar" src="{{ .BaseURL }}/avatar.png" alt=""> {{ .Name }}{{ if .Archived }} <span class="badge">Archived</span>{{ end }}</h1>
+{{ if .Archived }}<p><em>This repository is archived. It's read-only.</em></p>{{ end }}
+{{ with .Description }}<p>{{ . }}</p>{{ end }}
+{{ with .Topics }}<p>{{ range . }}<a href="{{ $.IndexURL }}/?topic={{ . }}">#{{ . }}</a> {{ end }}</p>{{ end }}
+{{ with .Languages }}<div class="languages">{{ range . }}<span style="flex: {{ .Size }}" title="{{ .Name }}"></span>{{ end }}</div>
+<p>{{ range $i, $l := . }}{{ if $i }} &middot; {{ end }}{{ $l.Name }} {{ $l.Percent }}%{{ end }}</p>{{ end }}
+<pre>git clone {{ .BaseURL }}.git
+git clone {{ .SSHURL }}</pre>
+{{ with .SparsePatterns }}<p>Most users only need part of this repository, clone it with:</p>
+<pre>git clone --filter=blob:none --sparse {{ $.SSHURL }}
+git -C {{ $.Dir }} sparse-checkout set{{ range . }} {{ . }}{{ end }}</pre>{{ end }}
+{{ if .Readme }}<article>{{ .Readme }}</article>{{ else }}<p>No readme found.</p>{{ end }}
+</body>
+</html>
+`))
+
+// readmeCacheSize is the number of rendered readmes kept in memory.
+const readmeCacheSize = 256
+
+// readmeCache holds rendered readmes keyed by repository and commit, so
+// they're only rendered once per commit.
+var readmeCache, _ = lru.New[string, template.HTML](readmeCacheSize)
+
+// getRepo renders the repository page, or handles go get requests.
+func getRepo(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("go-get") == "1" {
+		GoGetHandler(w, r)
+		return
+	}
+
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+	repoName := mux.Vars(r)["repo"]
+
+	name := repo.ProjectName()
+	if name == "" {
+		name = repo.Name()
+	}
+
+	readme, err := renderReadme(cfg, repo)
+	if err != nil {
+		logger.Error("failed to render readme", "repo", repoName, "err", err)
+	}
+
+	topics, err := be.RepoTopics(ctx, repoName)
+	if err != nil {
+		logger.Error("failed to get topics", "repo", repoName, "err", err)
+	}
+
+	langs, err := be.RepoLanguages(ctx, repo)
+	if err != nil {
+		logger.Error("failed to get languages", "repo", repoName, "err", err)
+	}
+
+	// Sparse checkouts are only recommended when partial clones are allowed,
+	// otherwise the whole repository is downloaded anyway.
+	var patterns []string
+	if !be.FiltersDisabled(ctx, repoName) {
+		patterns, err = be.SparsePatterns(ctx, repoName)
+		if err != nil {
+			logger.Error("failed to get sparse patterns", "repo", repoName, "err", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := repoPageTpl.Execute(w, struct {
+		Name           string
+		Description    string
+		Archived       bool
+		Topics         []string
+		Languages      []gitb.Language
+		IndexURL       string
+		BaseURL        string
+		SSHURL         string
+		Dir            string
+		SparsePatterns []string
+		Readme         template.HTML
+	}{
+		Name:           name,
+		Description:    repo.Description(),
+		Archived:       repo.IsArchived(),
+		Topics:         topics,
+		Languages:      langs,
+		IndexURL:       cfg.HTTP.PublicURL,
+		BaseURL:        fmt.Sprintf("%s/%s", cfg.HTTP.PublicURL, repoName),
+		SSHURL:         fmt.Sprintf("%s/%s.git", cfg.SSH.PublicURL, repoName),
+		Dir:            path.Base(repoName),
+		SparsePatterns: patterns,
+		Readme:         readme,
+	}); err != nil {
+		logger.Error("failed to render repo page", "repo", repoName, "err", err)
+	}
+}
+
+// getDescription writes the description of a repository as plain text.
+// Imports use it to carry descriptions over from other servers.
+func getDescription(w http.ResponseWriter, r *http.Request) {
+	repo := proto.RepositoryFromContext(r.Context())
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, repo.Description()) //nolint: errcheck
+}
+
+// repoStats are the statistics of a repository served as JSON.
+type repoStats struct {
+	Size          int64      `json:"size"`
+	Commits       int64      `json:"commits"`
+	DefaultBranch string     `json:"default_branch"`
+	LastPushedAt  *time.Time `json:"last_pushed_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// getStats writes the statistics of a repository as JSON. The size is in
+// bytes, and the stats may lag behind the repository.
+func getStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	repo := proto.RepositoryFromContext(ctx)
+
+	st, err := be.RepoStats(ctx, repo)
+	if err != nil {
+		logger.Error("failed to get repository stats", "repo", repo.Name(), "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	res := repoStats{
+		Size:          st.Size,
+		Commits:       st.Commits,
+		DefaultBranch: st.DefaultBranch,
+		UpdatedAt:     st.UpdatedAt.UTC(),
+	}
+	if st.LastPushedAt.Valid {
+		t := st.LastPushedAt.Time.UTC()
+		res.LastPushedAt = &t
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		logger.Error("error encoding json", "err", err)
+	}
+}
+
+// renderReadme renders the readme at the root of the default branch of a
+// repository. Markdown readmes are rendered to HTML, others are shown as
+// preformatted text. Empty repositories and repositories without a readme
+// have none.
+func renderReadme(cfg *config.Config, repo proto.Repository) (template.HTML, error) {
+	gr, err := repo.Open()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := gr.HEAD()
+	if err != nil {
+		// Empty repository
+		return "", nil
+	}
+
+	key := repo.Name() + "@" + head.ID
+	if html, ok := readmeCache.Get(key); ok {
+		return html, nil
+	}
+
+	content, fp, err := backend.Readme(repo, head)
+	if err != nil || fp == "" {
+		// No readme
+		return "", nil //nolint:nilerr
+	}
+
+	var html template.HTML
+	switch strings.ToLower(path.Ext(fp)) {
+	case ".md", ".markdown", ".mkd", ".mdown":
+		rawBase := fmt.Sprintf("%s/%s/raw/%s", cfg.HTTP.PublicURL, repo.Name(), head.Name().Short())
+		html, err = renderMarkdown([]byte(content), rawBase, path.Dir(fp))
+		if err != nil {
+			return "", err
+		}
+	default:
+		html = template.HTML("<pre>" + template.HTMLEscapeString(content) + "</pre>") //nolint:gosec
+	}
+
+	readmeCache.Add(key, html)
+	return html, nil
+}
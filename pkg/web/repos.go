@@ -0,0 +1,141 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/gorilla/mux"
+)
+
+var reposPageTpl = template.Must(template.New("repos").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>Repositories</title>
+    <style>
+        body { max-width: 60em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.5; }
+        ul { list-style: none; padding: 0; }
+        li { margin-bottom: .5em; }
+        .avatar { width: 1.2em; height: 1.2em; border-radius: .2em; vertical-align: middle; }
+        .badge { padding: .1em .5em; border: 1px solid #9a6700; border-radius: 1em; color: #9a6700; font-size: .8em; }
+    </style>
+</head>
+<body>
+<h1>Repositories{{ with .Topic }} with #{{ . }}{{ end }}</h1>
+{{ with .Repos }}<ul>
+{{ range . }}<li><img class="avatar" src="{{ $.BaseURL }}/{{ .Name }}/avatar.png" alt=""> <a href="{{ $.BaseURL }}/{{ .Name }}">{{ .Title }}</a>{{ if .Archived }} <span class="badge">Archived</span>{{ end }}{{ with .Description }} &middot; {{ . }}{{ end }}
+{{ range .Topics }}<a href="{{ $.BaseURL }}/?topic={{ . }}">#{{ . }}</a> {{ end }}</li>
+{{ end }}</ul>
+{{ else }}<p>No repositories found.</p>
+{{ end }}</body>
+</html>
+`))
+
+// reposItem is a repository of the repository index.
+type reposItem struct {
+	Name        string
+	Title       string
+	Description string
+	Archived    bool
+	Topics      []string
+}
+
+// RepoIndexController registers the repository index route. It must come
+// before the git routes, which match any path.
+func RepoIndexController(_ context.Context, r *mux.Router) {
+	r.HandleFunc("/", getRepos).Methods(http.MethodGet)
+}
+
+// getRepos renders the index of the repositories the user can read. The
+// topic query parameter only lists the repositories with that topic.
+func getRepos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+
+	user, err := authenticate(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrInvalidPassword), errors.Is(err, proto.ErrAddrDenied):
+			renderForbidden(w, r)
+			return
+		case errors.Is(err, proto.ErrUserNotFound):
+		case errors.Is(err, proto.ErrTokenExpired):
+			askCredentials(w, r)
+			renderTokenExpired(w, r)
+			return
+		default:
+			logger.Error("failed to authenticate", "err", err)
+		}
+	}
+
+	if user == nil && !be.AllowKeyless(ctx) {
+		askCredentials(w, r)
+		renderUnauthorized(w, r)
+		return
+	}
+
+	topic := strings.ToLower(r.URL.Query().Get("topic"))
+	var repos []proto.Repository
+	if topic != "" {
+		repos, err = be.RepositoriesByTopic(ctx, topic)
+	} else {
+		repos, err = be.Repositories(ctx)
+	}
+	if err != nil {
+		logger.Error("failed to list repositories", "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	items := make([]reposItem, 0, len(repos))
+	for _, repo := range repos {
+		if repo.IsHidden() || be.AccessLevelForUser(ctx, repo.Name(), user) < access.ReadOnlyAccess {
+			continue
+		}
+		if repo.IsArchived() && cfg.Repo.HideArchived {
+			continue
+		}
+
+		topics, err := be.RepoTopics(ctx, repo.Name())
+		if err != nil {
+			logger.Error("failed to get topics", "repo", repo.Name(), "err", err)
+		}
+
+		title := repo.ProjectName()
+		if title == "" {
+			title = repo.Name()
+		}
+
+		items = append(items, reposItem{
+			Name:        repo.Name(),
+			Title:       title,
+			Description: strings.TrimSpace(repo.Description()),
+			Archived:    repo.IsArchived(),
+			Topics:      topics,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := reposPageTpl.Execute(w, struct {
+		Topic   string
+		BaseURL string
+		Repos   []reposItem
+	}{
+		Topic:   topic,
+		BaseURL: cfg.HTTP.PublicURL,
+		Repos:   items,
+	}); err != nil {
+		logger.Error("failed to render repositories page", "err", err)
+	}
+}
@@ -4,20 +4,31 @@ import (
 	"context"
 	"net/http"
 
-	"charm.land/log/v2"
 	"github.com/charmbracelet/soft-serve/pkg/config"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 )
 
 // NewRouter returns a new HTTP router.
 func NewRouter(ctx context.Context) http.Handler {
-	logger := log.FromContext(ctx).WithPrefix("http")
+	logger := logr.Subsystem(ctx, "http", "http")
 	router := mux.NewRouter()
 
 	// Health routes
 	HealthController(ctx, router)
 
+	// Login routes
+	// These must come before the git routes, which match any path.
+	LoginController(ctx, router)
+
+	// Repository index
+	RepoIndexController(ctx, router)
+
+	// API routes
+	// These must come before the git routes, which match any path.
+	APIController(ctx, router)
+
 	// Git routes
 	GitController(ctx, router)
 
@@ -37,5 +48,14 @@ func NewRouter(ctx context.Context) http.Handler {
 		handlers.AllowedMethods(cfg.HTTP.CORS.AllowedMethods),
 	)(h)
 
+	// Proxy headers handler
+	// Resolves the client address of requests from trusted proxies before
+	// any other middleware sees them.
+	proxies, err := cfg.HTTP.TrustedProxyRanges()
+	if err != nil {
+		logger.Error("invalid trusted proxies", "err", err)
+	}
+	h = NewProxyHeadersHandler(proxies)(h)
+
 	return h
 }
@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var deliveryCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "webhook",
+	Name:      "delivery_attempts_total",
+	Help:      "The total number of webhook delivery attempts by event and outcome",
+}, []string{"event", "result"})
+
+const (
+	defaultMaxAttempts = 5
+	defaultBaseDelay   = 10 * time.Second
+	defaultWorkers     = 4
+
+	// maxDelay caps the delay between two attempts.
+	maxDelay = time.Hour
+
+	// pollInterval is how often the database is checked for deliveries.
+	// Deliveries are queued by other processes, such as git hooks, so the
+	// database is the queue.
+	pollInterval = time.Second
+)
+
+// Dispatcher sends queued webhook deliveries from a bounded pool of workers.
+// Failed deliveries are retried with an exponential backoff until they
+// succeed or run out of attempts.
+type Dispatcher struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	logger  *log.Logger
+	workers int
+	queue   chan models.WebhookDelivery
+	wg      sync.WaitGroup
+
+	mu       sync.Mutex
+	inflight map[uuid.UUID]struct{}
+
+	// maxAttempts and baseDelay are guarded by mu, they change when the
+	// configuration is reloaded.
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+// NewDispatcher returns a new webhook dispatcher.
+// It expects a context with *db.DB, store.Store, and *config.Config attached.
+func NewDispatcher(ctx context.Context) *Dispatcher {
+	cfg := config.FromContext(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	d := &Dispatcher{
+		ctx:         ctx,
+		cancel:      cancel,
+		logger:      logr.Subsystem(ctx, "webhook", "webhook"),
+		maxAttempts: defaultMaxAttempts,
+		baseDelay:   defaultBaseDelay,
+		workers:     defaultWorkers,
+		inflight:    map[uuid.UUID]struct{}{},
+	}
+
+	if cfg != nil {
+		d.Reload(cfg)
+		if cfg.Webhook.Workers > 0 {
+			d.workers = cfg.Webhook.Workers
+		}
+	}
+
+	// The queue is unbuffered so deliveries are only taken by idle workers.
+	d.queue = make(chan models.WebhookDelivery)
+
+	return d
+}
+
+// Reload applies the retry settings of cfg to the next delivery attempts.
+// The number of workers only changes on restart.
+func (d *Dispatcher) Reload(cfg *config.Config) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.maxAttempts = defaultMaxAttempts
+	if cfg.Webhook.MaxAttempts > 0 {
+		d.maxAttempts = cfg.Webhook.MaxAttempts
+	}
+	d.baseDelay = defaultBaseDelay
+	if cfg.Webhook.BaseDelay > 0 {
+		d.baseDelay = time.Duration(cfg.Webhook.BaseDelay) * time.Second
+	}
+}
+
+// Start starts the workers and the polling of queued deliveries.
+func (d *Dispatcher) Start() {
+	for range d.workers {
+		d.wg.Add(1)
+		go d.work()
+	}
+
+	d.wg.Add(1)
+	go d.poll()
+}
+
+// Stop stops the dispatcher and waits for deliveries in progress.
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// poll queues due deliveries until the dispatcher stops.
+func (d *Dispatcher) poll() {
+	defer d.wg.Done()
+	defer close(d.queue)
+
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
+
+	for {
+		d.dispatch()
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// dispatch hands due deliveries to the workers, as long as any is idle.
+func (d *Dispatcher) dispatch() {
+	dbx := db.FromContext(d.ctx)
+	datastore := store.FromContext(d.ctx)
+	deliveries, err := datastore.GetPendingWebhookDeliveries(d.ctx, dbx)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			d.logger.Error("error getting pending webhook deliveries", "err", db.WrapError(err))
+		}
+		return
+	}
+
+	now := time.Now()
+	for _, del := range deliveries {
+		if del.NextAttemptAt.Time.After(now) {
+			// Deliveries are sorted by their next attempt.
+			return
+		}
+
+		d.mu.Lock()
+		_, busy := d.inflight[del.ID]
+		d.inflight[del.ID] = struct{}{}
+		d.mu.Unlock()
+		if busy {
+			continue
+		}
+
+		select {
+		case d.queue <- del:
+		default:
+			// All the workers are busy, the rest waits for the next poll.
+			d.mu.Lock()
+			delete(d.inflight, del.ID)
+			d.mu.Unlock()
+			return
+		}
+	}
+}
+
+// work sends deliveries from the queue.
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for del := range d.queue {
+		if err := d.attempt(del); err != nil {
+			d.logger.Error("error recording webhook delivery", "delivery", del.ID, "err", err)
+		}
+
+		d.mu.Lock()
+		delete(d.inflight, del.ID)
+		d.mu.Unlock()
+	}
+}
+
+// attempt sends a delivery once, records the outcome, and schedules the next
+// attempt if it failed.
+func (d *Dispatcher) attempt(del models.WebhookDelivery) error {
+	// Deliveries in progress aren't cut short when the dispatcher stops.
+	ctx := context.WithoutCancel(d.ctx)
+	dbx := db.FromContext(ctx)
+	datastore := store.FromContext(ctx)
+	w, err := datastore.GetWebhookForDelivery(ctx, dbx, del.ID)
+	if err != nil {
+		// The webhook and its deliveries were deleted.
+		return db.WrapError(err)
+	}
+
+	a := deliver(ctx, w, del)
+	n := del.Attempts + 1
+
+	d.mu.Lock()
+	maxAttempts, baseDelay := d.maxAttempts, d.baseDelay
+	d.mu.Unlock()
+
+	var next time.Time
+	if !a.ok() && n < maxAttempts {
+		next = time.Now().Add(backoff(baseDelay, n))
+	}
+
+	result := "success"
+	switch {
+	case !a.ok() && !next.IsZero():
+		result = "retry"
+	case !a.ok():
+		result = "failure"
+	}
+	deliveryCounter.WithLabelValues(Event(del.Event).String(), result).Inc()
+
+	if a.ok() {
+		d.logger.Debug("webhook delivered", "delivery", del.ID, "attempt", n)
+	} else {
+		d.logger.Info("webhook delivery failed", "delivery", del.ID, "attempt", n, "status", a.responseStatus, "err", a.err, "retry", !next.IsZero())
+	}
+
+	return db.WrapError(dbx.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := datastore.UpdateWebhookDeliveryByID(ctx, tx, del.ID, n, a.err, a.requestHeaders, a.responseStatus, a.responseHeaders, a.responseBody, next); err != nil {
+			return err
+		}
+
+		return datastore.CreateWebhookDeliveryAttempt(ctx, tx, del.ID, n, a.err, a.responseStatus, a.responseBody)
+	}))
+}
+
+// backoff returns the delay before the next attempt after n failed attempts.
+// The delay doubles with each attempt, half of it is random so retries of
+// deliveries that failed together are spread out.
+func backoff(base time.Duration, n int) time.Duration {
+	delay := base
+	for i := 1; i < n && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+
+	half := delay / 2
+	return half + rand.N(half+1) //nolint:gosec
+}
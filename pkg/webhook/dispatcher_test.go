@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/migrate"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/store/database"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		min  time.Duration
+		max  time.Duration
+	}{
+		{"first", 1, 5 * time.Second, 10 * time.Second},
+		{"third", 3, 20 * time.Second, 40 * time.Second},
+		{"capped", 20, maxDelay / 2, maxDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				got := backoff(10*time.Second, tt.n)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("backoff() = %v, want between %v and %v", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcherReload(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Webhook.MaxAttempts = 3
+	cfg.Webhook.Workers = 2
+	d := NewDispatcher(config.WithContext(context.TODO(), cfg))
+	if d.maxAttempts != 3 || d.workers != 2 {
+		t.Fatalf("maxAttempts, workers = %d, %d, want 3, 2", d.maxAttempts, d.workers)
+	}
+
+	// Unset settings go back to the defaults, workers don't change.
+	reloaded := *cfg
+	reloaded.Webhook.MaxAttempts = 0
+	reloaded.Webhook.BaseDelay = 1
+	reloaded.Webhook.Workers = 8
+	d.Reload(&reloaded)
+	if d.maxAttempts != defaultMaxAttempts || d.baseDelay != time.Second || d.workers != 2 {
+		t.Errorf("maxAttempts, baseDelay, workers = %d, %v, %d, want %d, %v, 2", d.maxAttempts, d.baseDelay, d.workers, defaultMaxAttempts, time.Second)
+	}
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	var calls atomic.Int32
+	var signature atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature.Store(r.Header.Get(SignatureHeader))
+		if calls.Add(1) == 1 {
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, datastore, dbx, id := newTestWebhook(t, srv, "secret")
+
+	d := NewDispatcher(ctx)
+	d.baseDelay = 10 * time.Millisecond
+	d.Start()
+	t.Cleanup(d.Stop)
+
+	var dels []models.WebhookDelivery
+	var err error
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		dels, err = datastore.GetWebhookDeliveriesByWebhookID(ctx, dbx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dels) == 1 && !dels[0].NextAttemptAt.Valid {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery wasn't sent in time: %+v", dels)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	del := dels[0]
+	if del.Attempts != 2 || del.ResponseStatus != http.StatusNoContent {
+		t.Errorf("got %d attempts with status %d, want 2 attempts with status %d", del.Attempts, del.ResponseStatus, http.StatusNoContent)
+	}
+	if want := Signature("secret", []byte(del.RequestBody)); signature.Load() != want {
+		t.Errorf("got signature %v, want %v", signature.Load(), want)
+	}
+
+	history, err := datastore.GetWebhookDeliveryAttemptsByDeliveryID(ctx, dbx, del.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].ResponseStatus != http.StatusServiceUnavailable || history[0].ResponseBody != "try again later\n" {
+		t.Errorf("unexpected history %+v", history)
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, datastore, dbx, id := newTestWebhook(t, srv, "")
+
+	d := NewDispatcher(ctx)
+	d.baseDelay = 10 * time.Millisecond
+	d.maxAttempts = 3
+	d.Start()
+	t.Cleanup(d.Stop)
+
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		dels, err := datastore.GetWebhookDeliveriesByWebhookID(ctx, dbx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dels) == 1 && !dels[0].NextAttemptAt.Valid {
+			if dels[0].Attempts != 3 {
+				t.Errorf("got %d attempts, want 3", dels[0].Attempts)
+			}
+			if !(Delivery{WebhookDelivery: dels[0]}).Failed() {
+				t.Errorf("delivery isn't failed: %+v", dels[0])
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery wasn't given up in time: %+v", dels)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// newTestWebhook creates a webhook sending to srv and queues a push delivery.
+func newTestWebhook(t *testing.T, srv *httptest.Server, secret string) (context.Context, store.Store, *db.DB, int64) {
+	t.Helper()
+
+	// The receiver listens on localhost, which the SSRF protection denies.
+	client := secureHTTPClient
+	secureHTTPClient = srv.Client()
+	t.Cleanup(func() { secureHTTPClient = client })
+
+	ctx, datastore, dbx, repoID := newTestRepo(t)
+	id, err := datastore.CreateWebhook(ctx, dbx, repoID, srv.URL, secret, int(ContentTypeJSON), "", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := models.Webhook{ID: id, URL: srv.URL, Secret: secret, ContentType: int(ContentTypeJSON)}
+	if err := SendWebhook(ctx, w, EventPush, map[string]string{"event": "push"}); err != nil {
+		t.Fatal(err)
+	}
+
+	return ctx, datastore, dbx, id
+}
+
+// newTestRepo opens a new database with a repository, and returns its ID.
+func newTestRepo(t *testing.T) (context.Context, store.Store, *db.DB, int64) {
+	t.Helper()
+	t.Setenv("SOFT_SERVE_DATA_PATH", t.TempDir())
+	cfg := config.DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := config.WithContext(context.TODO(), cfg)
+	ctx = log.WithContext(ctx, log.New(io.Discard))
+	dbx, err := db.Open(ctx, cfg.DB.Driver, cfg.DB.DataSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbx.Close() }) //nolint: errcheck
+	if err := migrate.Migrate(ctx, dbx); err != nil {
+		t.Fatal(err)
+	}
+
+	datastore := database.New(ctx, dbx)
+	ctx = db.WithContext(ctx, dbx)
+	ctx = store.WithContext(ctx, datastore)
+
+	admin, err := datastore.FindUserByUsername(ctx, dbx, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := datastore.CreateRepo(ctx, dbx, "repo1", admin.ID, "", "", false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := datastore.GetRepoByName(ctx, dbx, "repo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ctx, datastore, dbx, repo.ID
+}
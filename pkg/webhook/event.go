@@ -3,6 +3,9 @@ package webhook
 import (
 	"encoding"
 	"errors"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
 )
 
 // Event is a webhook event.
@@ -26,6 +29,45 @@ const (
 
 	// EventRepositoryVisibilityChange is a repository visibility change event.
 	EventRepositoryVisibilityChange Event = 6
+
+	// EventBranchCreate is a branch create event, a narrower
+	// EventBranchTagCreate.
+	EventBranchCreate Event = 7
+
+	// EventBranchDelete is a branch delete event, a narrower
+	// EventBranchTagDelete.
+	EventBranchDelete Event = 8
+
+	// EventTagCreate is a tag create event, a narrower EventBranchTagCreate.
+	EventTagCreate Event = 9
+
+	// EventTagDelete is a tag delete event, a narrower EventBranchTagDelete.
+	EventTagDelete Event = 10
+
+	// EventRepositoryRename is a repository rename event, a narrower
+	// EventRepository.
+	EventRepositoryRename Event = 11
+
+	// EventRepositoryDelete is a repository delete event, a narrower
+	// EventRepository.
+	EventRepositoryDelete Event = 12
+
+	// EventRepositoryCreate is a repository create event, a narrower
+	// EventRepository.
+	EventRepositoryCreate Event = 13
+
+	// EventRepositoryOwnershipChange is a repository ownership change event,
+	// a narrower EventRepository.
+	EventRepositoryOwnershipChange Event = 14
+
+	// EventLFS is an LFS object upload or delete event.
+	EventLFS Event = 15
+
+	// EventLFSUpload is an LFS object upload event, a narrower EventLFS.
+	EventLFSUpload Event = 16
+
+	// EventLFSDelete is an LFS object delete event, a narrower EventLFS.
+	EventLFSDelete Event = 17
 )
 
 // Events return all events.
@@ -37,6 +79,33 @@ func Events() []Event {
 		EventPush,
 		EventRepository,
 		EventRepositoryVisibilityChange,
+		EventBranchCreate,
+		EventBranchDelete,
+		EventTagCreate,
+		EventTagDelete,
+		EventRepositoryRename,
+		EventRepositoryDelete,
+		EventRepositoryCreate,
+		EventRepositoryOwnershipChange,
+		EventLFS,
+		EventLFSUpload,
+		EventLFSDelete,
+	}
+}
+
+// ServerEvents returns the events server webhooks can subscribe to, the
+// lifecycle events of the repositories and their LFS object events.
+func ServerEvents() []Event {
+	return []Event{
+		EventRepository,
+		EventRepositoryVisibilityChange,
+		EventRepositoryCreate,
+		EventRepositoryRename,
+		EventRepositoryDelete,
+		EventRepositoryOwnershipChange,
+		EventLFS,
+		EventLFSUpload,
+		EventLFSDelete,
 	}
 }
 
@@ -47,6 +116,17 @@ var eventStrings = map[Event]string{
 	EventPush:                       "push",
 	EventRepository:                 "repository",
 	EventRepositoryVisibilityChange: "repository_visibility_change",
+	EventBranchCreate:               "branch_create",
+	EventBranchDelete:               "branch_delete",
+	EventTagCreate:                  "tag_create",
+	EventTagDelete:                  "tag_delete",
+	EventRepositoryRename:           "repository_rename",
+	EventRepositoryDelete:           "repository_delete",
+	EventRepositoryCreate:           "repository_create",
+	EventRepositoryOwnershipChange:  "repository_ownership_change",
+	EventLFS:                        "lfs",
+	EventLFSUpload:                  "lfs_upload",
+	EventLFSDelete:                  "lfs_delete",
 }
 
 // String returns the string representation of the event.
@@ -61,11 +141,26 @@ var stringEvent = map[string]Event{
 	"push":                         EventPush,
 	"repository":                   EventRepository,
 	"repository_visibility_change": EventRepositoryVisibilityChange,
+	"branch_create":                EventBranchCreate,
+	"branch_delete":                EventBranchDelete,
+	"tag_create":                   EventTagCreate,
+	"tag_delete":                   EventTagDelete,
+	"repository_rename":            EventRepositoryRename,
+	"repository_delete":            EventRepositoryDelete,
+	"repository_create":            EventRepositoryCreate,
+	"repository_ownership_change":  EventRepositoryOwnershipChange,
+	"lfs":                          EventLFS,
+	"lfs_upload":                   EventLFSUpload,
+	"lfs_delete":                   EventLFSDelete,
 }
 
 // ErrInvalidEvent is returned when the event is invalid.
 var ErrInvalidEvent = errors.New("invalid event")
 
+// ErrInvalidServerEvent is returned when a server webhook subscribes to an
+// event that isn't a repository or LFS event.
+var ErrInvalidServerEvent = errors.New("server webhooks only support repository and LFS events, invalid event")
+
 // ParseEvent parses an event string and returns the event.
 func ParseEvent(s string) (Event, error) {
 	e, ok := stringEvent[s]
@@ -76,6 +171,45 @@ func ParseEvent(s string) (Event, error) {
 	return e, nil
 }
 
+// narrowEvent returns the narrower event a payload is an instance of, e.g.
+// EventTagCreate for an EventBranchTagCreate creating a tag.
+func narrowEvent(payload EventPayload) (Event, bool) {
+	switch p := payload.(type) {
+	case BranchTagEvent:
+		tag := strings.HasPrefix(p.Ref, git.RefsTags)
+		switch {
+		case p.Created && tag:
+			return EventTagCreate, true
+		case p.Created:
+			return EventBranchCreate, true
+		case p.Deleted && tag:
+			return EventTagDelete, true
+		case p.Deleted:
+			return EventBranchDelete, true
+		}
+	case RepositoryEvent:
+		switch p.Action {
+		case RepositoryEventActionCreate:
+			return EventRepositoryCreate, true
+		case RepositoryEventActionRename:
+			return EventRepositoryRename, true
+		case RepositoryEventActionDelete:
+			return EventRepositoryDelete, true
+		case RepositoryEventActionOwnershipChange:
+			return EventRepositoryOwnershipChange, true
+		}
+	case LFSEvent:
+		switch p.Action {
+		case LFSEventActionUpload:
+			return EventLFSUpload, true
+		case LFSEventActionDelete:
+			return EventLFSDelete, true
+		}
+	}
+
+	return -1, false
+}
+
 var (
 	_ encoding.TextMarshaler   = Event(0)
 	_ encoding.TextUnmarshaler = (*Event)(nil)
@@ -0,0 +1,160 @@
+package webhook
+
+import "testing"
+
+func TestNarrowEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload EventPayload
+		want    Event
+		ok      bool
+	}{
+		{"branch create", BranchTagEvent{Ref: "refs/heads/main", Created: true}, EventBranchCreate, true},
+		{"branch delete", BranchTagEvent{Ref: "refs/heads/main", Deleted: true}, EventBranchDelete, true},
+		{"tag create", BranchTagEvent{Ref: "refs/tags/v1.0.0", Created: true}, EventTagCreate, true},
+		{"tag delete", BranchTagEvent{Ref: "refs/tags/v1.0.0", Deleted: true}, EventTagDelete, true},
+		{"repository create", RepositoryEvent{Action: RepositoryEventActionCreate}, EventRepositoryCreate, true},
+		{"repository rename", RepositoryEvent{Action: RepositoryEventActionRename}, EventRepositoryRename, true},
+		{"repository delete", RepositoryEvent{Action: RepositoryEventActionDelete}, EventRepositoryDelete, true},
+		{"repository visibility", RepositoryEvent{Action: RepositoryEventActionVisibilityChange}, -1, false},
+		{"lfs upload", LFSEvent{Action: LFSEventActionUpload}, EventLFSUpload, true},
+		{"lfs delete", LFSEvent{Action: LFSEventActionDelete}, EventLFSDelete, true},
+		{"push", PushEvent{}, -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := narrowEvent(tt.payload)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("narrowEvent() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSendEventFilters(t *testing.T) {
+	ctx, datastore, dbx, repoID := newTestRepo(t)
+	webhooks := map[string][]Event{
+		"https://example.com/tags":     {EventTagCreate},
+		"https://example.com/branches": {EventBranchTagCreate},
+		"https://example.com/inactive": {EventTagCreate},
+		"https://example.com/push":     {EventPush},
+	}
+	ids := map[string]int64{}
+	for url, events := range webhooks {
+		id, err := datastore.CreateWebhook(ctx, dbx, repoID, url, "", int(ContentTypeJSON), "", "", url != "https://example.com/inactive")
+		if err != nil {
+			t.Fatal(err)
+		}
+		evs := make([]int, len(events))
+		for i, e := range events {
+			evs[i] = int(e)
+		}
+		if err := datastore.CreateWebhookEvents(ctx, dbx, id, evs); err != nil {
+			t.Fatal(err)
+		}
+		ids[url] = id
+	}
+
+	// A tag is created, then a branch.
+	tag := BranchTagEvent{Ref: "refs/tags/v1.0.0", Created: true, Common: Common{EventType: EventBranchTagCreate, Repository: Repository{ID: repoID}}}
+	branch := BranchTagEvent{Ref: "refs/heads/main", Created: true, Common: Common{EventType: EventBranchTagCreate, Repository: Repository{ID: repoID}}}
+	for _, p := range []EventPayload{tag, branch} {
+		if err := SendEvent(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]int{
+		"https://example.com/tags":     1,
+		"https://example.com/branches": 2,
+		"https://example.com/inactive": 0,
+		"https://example.com/push":     0,
+	}
+	for url, n := range want {
+		dels, err := datastore.GetWebhookDeliveriesByWebhookID(ctx, dbx, ids[url])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dels) != n {
+			t.Errorf("%s got %d deliveries, want %d", url, len(dels), n)
+		}
+	}
+}
+
+func TestSendEventServerWebhooks(t *testing.T) {
+	ctx, datastore, dbx, repoID := newTestRepo(t)
+	webhooks := map[string][]Event{
+		"https://example.com/create": {EventRepositoryCreate},
+		"https://example.com/all":    {EventRepository},
+		"https://example.com/lfs":    {EventLFSUpload},
+	}
+	ids := map[string]int64{}
+	for url, events := range webhooks {
+		id, err := datastore.CreateWebhook(ctx, dbx, 0, url, "", int(ContentTypeJSON), "", "", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evs := make([]int, len(events))
+		for i, e := range events {
+			evs[i] = int(e)
+		}
+		if err := datastore.CreateWebhookEvents(ctx, dbx, id, evs); err != nil {
+			t.Fatal(err)
+		}
+		ids[url] = id
+	}
+
+	// A repository is created, pushed to, gets an LFS object uploaded and
+	// deleted, and is deleted. Server webhooks only receive the repository and
+	// LFS events, and outlive the repository.
+	common := Common{EventType: EventRepository, Repository: Repository{ID: repoID}}
+	create := RepositoryEvent{Action: RepositoryEventActionCreate, Common: common}
+	if err := SendEvent(ctx, create); err != nil {
+		t.Fatal(err)
+	}
+	if err := SendEvent(ctx, PushEvent{Common: Common{EventType: EventPush, Repository: Repository{ID: repoID}}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []LFSEventAction{LFSEventActionUpload, LFSEventActionDelete} {
+		lfs := LFSEvent{Action: action, Common: Common{EventType: EventLFS, Repository: Repository{ID: repoID}}}
+		if err := SendEvent(ctx, lfs); err != nil {
+			t.Fatal(err)
+		}
+	}
+	del := RepositoryEvent{Action: RepositoryEventActionDelete, Common: common}
+	if err := SendEventNow(ctx, del); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{
+		"https://example.com/create": 1,
+		"https://example.com/all":    2,
+		"https://example.com/lfs":    1,
+	}
+	for url, n := range want {
+		dels, err := datastore.GetWebhookDeliveriesByWebhookID(ctx, dbx, ids[url])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dels) != n {
+			t.Errorf("%s got %d deliveries, want %d", url, len(dels), n)
+		}
+	}
+
+	// Server webhooks aren't the webhooks of a repository.
+	whs, err := datastore.GetWebhooksByRepoID(ctx, dbx, repoID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(whs) != 0 {
+		t.Errorf("got %d repository webhooks, want 0", len(whs))
+	}
+	whs, err = datastore.GetWebhooksByRepoID(ctx, dbx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(whs) != 3 || whs[0].RepoID.Valid {
+		t.Errorf("got %d server webhooks, want 3 without a repository", len(whs))
+	}
+}
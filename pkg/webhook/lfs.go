@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+// LFSEvent is an LFS object event.
+type LFSEvent struct {
+	Common
+
+	// Action is the LFS event action.
+	Action LFSEventAction `json:"action" url:"action"`
+	// Object is the LFS object.
+	Object LFSObject `json:"object" url:"object"`
+}
+
+// LFSObject represents an LFS object in an event.
+type LFSObject struct {
+	// OID is the SHA-256 of the object.
+	OID string `json:"oid" url:"oid"`
+	// Size is the size of the object in bytes.
+	Size int64 `json:"size" url:"size"`
+}
+
+// LFSEventAction is an LFS event action.
+type LFSEventAction string
+
+const (
+	// LFSEventActionUpload is an LFS object uploaded event.
+	LFSEventActionUpload LFSEventAction = "upload"
+	// LFSEventActionDelete is an LFS object deleted event.
+	LFSEventActionDelete LFSEventAction = "delete"
+)
+
+// NewLFSEvent returns an LFS object event. The sender is the user who
+// uploaded the object, it's empty for anonymous users and the server, which
+// deletes unreferenced objects.
+func NewLFSEvent(ctx context.Context, user proto.User, repo proto.Repository, action LFSEventAction, oid string, size int64) (LFSEvent, error) {
+	payload := LFSEvent{
+		Action: action,
+		Object: LFSObject{
+			OID:  oid,
+			Size: size,
+		},
+		Common: Common{
+			EventType: EventLFS,
+			Repository: Repository{
+				ID:          repo.ID(),
+				Name:        repo.Name(),
+				Description: repo.Description(),
+				ProjectName: repo.ProjectName(),
+				Private:     repo.IsPrivate(),
+				CreatedAt:   repo.CreatedAt(),
+				UpdatedAt:   repo.UpdatedAt(),
+			},
+		},
+	}
+
+	if user != nil {
+		payload.Sender = User{
+			ID:       user.ID(),
+			Username: user.Username(),
+		}
+	}
+
+	cfg := config.FromContext(ctx)
+	payload.Repository.HTTPURL = repoURL(cfg.HTTP.PublicURL, repo.Name())
+	payload.Repository.SSHURL = repoURL(cfg.SSH.PublicURL, repo.Name())
+	payload.Repository.GitURL = repoURL(cfg.Git.PublicURL, repo.Name())
+
+	// Find repo owner, repositories created anonymously don't have one.
+	if repo.UserID() != 0 {
+		dbx := db.FromContext(ctx)
+		datastore := store.FromContext(ctx)
+		owner, err := datastore.GetUserByID(ctx, dbx, repo.UserID())
+		if err != nil {
+			return LFSEvent{}, db.WrapError(err)
+		}
+
+		payload.Repository.Owner.ID = owner.ID
+		payload.Repository.Owner.Username = owner.Username
+	}
+
+	payload.Repository.DefaultBranch, _ = getDefaultBranch(repo)
+
+	return payload, nil
+}
@@ -24,6 +24,9 @@ type PushEvent struct {
 	After string `json:"after" url:"after"`
 	// Commits is the list of commits.
 	Commits []Commit `json:"commits" url:"commits"`
+	// PushOptions are the options of the push, given with `git push -o`.
+	// They aren't included in form encoded payloads.
+	PushOptions map[string]string `json:"push_options,omitempty" url:"-"`
 }
 
 // NewPushEvent sends a push event.
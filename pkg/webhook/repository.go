@@ -15,12 +15,18 @@ type RepositoryEvent struct {
 
 	// Action is the repository event action.
 	Action RepositoryEventAction `json:"action" url:"action"`
+	// OldName is the name of the repository before a rename.
+	OldName string `json:"old_name,omitempty" url:"old_name,omitempty"`
+	// OldOwner is the owner of the repository before an ownership change.
+	OldOwner *User `json:"old_owner,omitempty" url:"old_owner,omitempty"`
 }
 
 // RepositoryEventAction is a repository event action.
 type RepositoryEventAction string
 
 const (
+	// RepositoryEventActionCreate is a repository created event.
+	RepositoryEventActionCreate RepositoryEventAction = "create"
 	// RepositoryEventActionDelete is a repository deleted event.
 	RepositoryEventActionDelete RepositoryEventAction = "delete"
 	// RepositoryEventActionRename is a repository renamed event.
@@ -29,9 +35,12 @@ const (
 	RepositoryEventActionVisibilityChange RepositoryEventAction = "visibility_change"
 	// RepositoryEventActionDefaultBranchChange is a repository default branch changed event.
 	RepositoryEventActionDefaultBranchChange RepositoryEventAction = "default_branch_change"
+	// RepositoryEventActionOwnershipChange is a repository ownership changed event.
+	RepositoryEventActionOwnershipChange RepositoryEventAction = "ownership_change"
 )
 
-// NewRepositoryEvent sends a repository event.
+// NewRepositoryEvent returns a repository event. The sender is the user who
+// performed the action, it's empty for anonymous users and the server.
 func NewRepositoryEvent(ctx context.Context, user proto.User, repo proto.Repository, action RepositoryEventAction) (RepositoryEvent, error) {
 	var event Event
 	switch action {
@@ -54,28 +63,34 @@ func NewRepositoryEvent(ctx context.Context, user proto.User, repo proto.Reposit
 				CreatedAt:   repo.CreatedAt(),
 				UpdatedAt:   repo.UpdatedAt(),
 			},
-			Sender: User{
-				ID:       user.ID(),
-				Username: user.Username(),
-			},
 		},
 	}
 
+	if user != nil {
+		payload.Sender = User{
+			ID:       user.ID(),
+			Username: user.Username(),
+		}
+	}
+
 	cfg := config.FromContext(ctx)
 	payload.Repository.HTTPURL = repoURL(cfg.HTTP.PublicURL, repo.Name())
 	payload.Repository.SSHURL = repoURL(cfg.SSH.PublicURL, repo.Name())
 	payload.Repository.GitURL = repoURL(cfg.Git.PublicURL, repo.Name())
 
-	// Find repo owner.
-	dbx := db.FromContext(ctx)
-	datastore := store.FromContext(ctx)
-	owner, err := datastore.GetUserByID(ctx, dbx, repo.UserID())
-	if err != nil {
-		return RepositoryEvent{}, db.WrapError(err)
+	// Find repo owner, repositories created anonymously don't have one.
+	if repo.UserID() != 0 {
+		dbx := db.FromContext(ctx)
+		datastore := store.FromContext(ctx)
+		owner, err := datastore.GetUserByID(ctx, dbx, repo.UserID())
+		if err != nil {
+			return RepositoryEvent{}, db.WrapError(err)
+		}
+
+		payload.Repository.Owner.ID = owner.ID
+		payload.Repository.Owner.Username = owner.Username
 	}
 
-	payload.Repository.Owner.ID = owner.ID
-	payload.Repository.Owner.Username = owner.Username
 	payload.Repository.DefaultBranch, _ = getDefaultBranch(repo)
 
 	return payload, nil
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// DefaultTemplateContentType is the content type of templated payloads when
+// the webhook doesn't set one.
+const DefaultTemplateContentType = "application/json"
+
+// templateFuncs are the functions available to payload templates.
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON, to embed strings and lists in JSON
+	// payloads safely.
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ParseTemplate parses a payload template. Templates are Go text/template
+// executed with the event payload, e.g. {{ .Repository.Name }}, {{ .Ref }},
+// {{ .Sender.Username }}, and {{ range .Commits }}.
+func ParseTemplate(text string) (*template.Template, error) {
+	t, err := template.New("payload").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload template: %w", err)
+	}
+
+	return t, nil
+}
+
+// renderTemplate renders the payload template of a webhook.
+func renderTemplate(w models.Webhook, payload interface{}) (string, error) {
+	t, err := ParseTemplate(w.PayloadTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, payload); err != nil {
+		return "", fmt.Errorf("error rendering payload template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// payloadContentType returns the Content-Type header of the payloads of a
+// webhook.
+func payloadContentType(w models.Webhook) string {
+	if w.PayloadTemplate == "" {
+		return ContentType(w.ContentType).String() //nolint:gosec
+	}
+	if w.PayloadContentType == "" {
+		return DefaultTemplateContentType
+	}
+
+	return w.PayloadContentType
+}
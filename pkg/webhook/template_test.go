@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	payload := PushEvent{
+		Common: Common{
+			EventType:  EventPush,
+			Repository: Repository{Name: "repo1"},
+			Sender:     User{Username: "admin"},
+		},
+		Ref:     "refs/heads/main",
+		Commits: []Commit{{ID: "abc", Message: "say \"hi\"\n"}, {ID: "def"}},
+	}
+
+	tests := []struct {
+		name    string
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "fields",
+			tmpl: `{"repo":"{{ .Repository.Name }}","ref":"{{ .Ref }}","pusher":"{{ .Sender.Username }}","event":"{{ .EventType }}"}`,
+			want: `{"repo":"repo1","ref":"refs/heads/main","pusher":"admin","event":"push"}`,
+		},
+		{
+			name: "commits",
+			tmpl: `{{ range .Commits }}{{ .ID }} {{ json .Message }};{{ end }}`,
+			want: `abc "say \"hi\"\n";def "";`,
+		},
+		{
+			name:    "unknown field",
+			tmpl:    `{{ .Pusher }}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTemplate(models.Webhook{PayloadTemplate: tt.tmpl}, payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("renderTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		w    models.Webhook
+		want string
+	}{
+		{"form", models.Webhook{ContentType: int(ContentTypeForm)}, "application/x-www-form-urlencoded"},
+		{"template", models.Webhook{ContentType: int(ContentTypeForm), PayloadTemplate: "{{ .Ref }}"}, DefaultTemplateContentType},
+		{"template content type", models.Webhook{PayloadTemplate: "{{ .Ref }}", PayloadContentType: "text/plain"}, "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := payloadContentType(tt.w); got != tt.want {
+				t.Errorf("payloadContentType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendWebhookTemplateError(t *testing.T) {
+	ctx, datastore, dbx, repoID := newTestRepo(t)
+	id, err := datastore.CreateWebhook(ctx, dbx, repoID, "https://example.com", "", int(ContentTypeJSON), "{{ .Pusher }}", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := datastore.GetWebhookByID(ctx, dbx, repoID, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SendWebhook(ctx, w, EventPush, PushEvent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dels, err := datastore.GetWebhookDeliveriesByWebhookID(ctx, dbx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dels) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(dels))
+	}
+
+	del := Delivery{WebhookDelivery: dels[0]}
+	if !del.Failed() || del.RequestBody != "" || !del.RequestError.Valid {
+		t.Errorf("delivery isn't failed with the template error: %+v", del.WebhookDelivery)
+	}
+	if !strings.Contains(del.RequestError.String, "Pusher") {
+		t.Errorf("got request error %q, want the template error", del.RequestError.String)
+	}
+}
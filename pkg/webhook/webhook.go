@@ -11,10 +11,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/ssrf"
 	"github.com/charmbracelet/soft-serve/pkg/store"
@@ -35,6 +38,31 @@ type Hook struct {
 type Delivery struct {
 	models.WebhookDelivery
 	Event Event
+
+	// History lists the attempts of the delivery, oldest first.
+	History []models.WebhookDeliveryAttempt
+}
+
+// Pending returns whether the delivery is waiting for an attempt.
+func (d Delivery) Pending() bool {
+	return d.NextAttemptAt.Valid
+}
+
+// Failed returns whether the delivery ran out of attempts without success.
+func (d Delivery) Failed() bool {
+	return !d.Pending() && (d.ResponseStatus < 200 || d.ResponseStatus >= 300)
+}
+
+// SignatureHeader is the header carrying the signature of a webhook payload.
+const SignatureHeader = "X-Soft-Serve-Signature"
+
+// Signature returns the signature of a webhook payload in the "sha256=<hex>"
+// format. It's the hex encoded HMAC-SHA256 of the exact payload bytes sent,
+// keyed by the webhook secret.
+func Signature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload) //nolint: errcheck
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
 }
 
 // secureHTTPClient is an HTTP client with SSRF protection.
@@ -57,93 +85,216 @@ func do(ctx context.Context, url string, method string, headers http.Header, bod
 	return res, nil
 }
 
-// SendWebhook sends a webhook event.
+// SendWebhook queues a webhook event for delivery. Deliveries are sent by the
+// Dispatcher of the server.
 func SendWebhook(ctx context.Context, w models.Webhook, event Event, payload interface{}) error {
-	var buf bytes.Buffer
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
+	body, renderErr := encodePayload(w, payload)
+	if renderErr != nil && w.PayloadTemplate == "" {
+		return renderErr
+	}
+
+	id, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	if renderErr == nil {
+		return db.WrapError(datastore.CreateWebhookDelivery(ctx, dbx, id, w.ID, int(event), w.URL, http.MethodPost, body, time.Now()))
+	}
+
+	// A template that doesn't render fails the delivery right away, instead
+	// of sending an empty body.
+	return db.WrapError(dbx.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := datastore.CreateWebhookDelivery(ctx, tx, id, w.ID, int(event), w.URL, http.MethodPost, "", time.Now()); err != nil {
+			return err
+		}
+		if err := datastore.UpdateWebhookDeliveryByID(ctx, tx, id, 1, renderErr, "", 0, "", "", time.Time{}); err != nil {
+			return err
+		}
+
+		return datastore.CreateWebhookDeliveryAttempt(ctx, tx, id, 1, renderErr, 0, "")
+	}))
+}
+
+// ErrUnrenderedDelivery is returned when redelivering a delivery whose payload
+// template didn't render.
+var ErrUnrenderedDelivery = errors.New("delivery payload wasn't rendered, fix the webhook template instead")
+
+// encodePayload encodes a payload in the content type of a webhook, or
+// renders it with the webhook payload template.
+func encodePayload(w models.Webhook, payload interface{}) (string, error) {
+	if w.PayloadTemplate != "" {
+		return renderTemplate(w, payload)
+	}
 
+	var buf bytes.Buffer
 	contentType := ContentType(w.ContentType) //nolint:gosec
 	switch contentType {
 	case ContentTypeJSON:
 		if err := json.NewEncoder(&buf).Encode(payload); err != nil {
-			return err
+			return "", err
 		}
 	case ContentTypeForm:
 		v, err := query.Values(payload)
 		if err != nil {
-			return err
+			return "", err
 		}
 		buf.WriteString(v.Encode()) //nolint: errcheck
 	default:
-		return ErrInvalidContentType
+		return "", ErrInvalidContentType
 	}
 
+	return buf.String(), nil
+}
+
+// maxResponseBody is the number of bytes of a response body recorded for a
+// delivery attempt.
+const maxResponseBody = 4 << 10
+
+// attempt is the outcome of a delivery attempt.
+type attempt struct {
+	err             error
+	requestHeaders  string
+	responseStatus  int
+	responseHeaders string
+	responseBody    string
+}
+
+// ok returns whether the receiver accepted the delivery.
+func (a attempt) ok() bool {
+	return a.err == nil && a.responseStatus >= 200 && a.responseStatus < 300
+}
+
+// deliver sends a webhook delivery once.
+func deliver(ctx context.Context, w models.Webhook, d models.WebhookDelivery) attempt {
 	headers := http.Header{}
-	headers.Add("Content-Type", contentType.String())
+	headers.Add("Content-Type", payloadContentType(w))
 	headers.Add("User-Agent", "SoftServe/"+version.Version)
-	headers.Add("X-SoftServe-Event", event.String())
+	headers.Add("X-SoftServe-Event", Event(d.Event).String())
+	headers.Add("X-SoftServe-Delivery", d.ID.String())
+	if w.Secret != "" {
+		sig := Signature(w.Secret, []byte(d.RequestBody))
+		headers.Add(SignatureHeader, sig)
+		// Kept for receivers verifying the original header.
+		headers.Add("X-SoftServe-Signature", sig)
+	}
 
-	id, err := uuid.NewUUID()
-	if err != nil {
-		return err
+	var a attempt
+	for k, v := range headers {
+		a.requestHeaders += k + ": " + v[0] + "\n"
 	}
 
-	headers.Add("X-SoftServe-Delivery", id.String())
+	res, err := do(ctx, d.RequestURL, d.RequestMethod, headers, strings.NewReader(d.RequestBody))
+	if err != nil {
+		a.err = err
+		return a
+	}
 
-	reqBody := buf.String()
-	if w.Secret != "" {
-		sig := hmac.New(sha256.New, []byte(w.Secret))
-		sig.Write([]byte(reqBody)) //nolint: errcheck
-		headers.Add("X-SoftServe-Signature", "sha256="+hex.EncodeToString(sig.Sum(nil)))
+	defer res.Body.Close() //nolint: errcheck
+	a.responseStatus = res.StatusCode
+	for k, v := range res.Header {
+		a.responseHeaders += k + ": " + v[0] + "\n"
 	}
 
-	res, reqErr := do(ctx, w.URL, http.MethodPost, headers, &buf)
-	var reqHeaders string
-	for k, v := range headers {
-		reqHeaders += k + ": " + v[0] + "\n"
+	b, err := io.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+	if err != nil {
+		a.err = err
 	}
+	a.responseBody = string(b)
 
-	resStatus := 0
-	resHeaders := ""
-	resBody := ""
+	return a
+}
 
-	if res != nil {
-		resStatus = res.StatusCode
-		for k, v := range res.Header {
-			resHeaders += k + ": " + v[0] + "\n"
+// SendEvent queues a webhook event for the webhooks subscribed to it.
+func SendEvent(ctx context.Context, payload EventPayload) error {
+	webhooks, err := subscribedWebhooks(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range webhooks {
+		if err := SendWebhook(ctx, w, payload.Event(), payload); err != nil {
+			return err
 		}
+	}
 
-		if res.Body != nil {
-			defer res.Body.Close() //nolint: errcheck
-			b, err := io.ReadAll(res.Body)
-			if err != nil {
+	return nil
+}
+
+// SendEventNow sends a webhook event once, right away, to the repository
+// webhooks subscribed to it. It's meant for events the webhooks don't outlive,
+// such as the deletion of their repository, so the deliveries aren't recorded.
+// Server webhooks outlive repositories, their deliveries are queued like with
+// [SendEvent].
+func SendEventNow(ctx context.Context, payload EventPayload) error {
+	webhooks, err := subscribedWebhooks(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	logger := logr.Subsystem(ctx, "webhook", "webhook")
+	for _, w := range webhooks {
+		if !w.RepoID.Valid {
+			if err := SendWebhook(ctx, w, payload.Event(), payload); err != nil {
 				return err
 			}
+			continue
+		}
+
+		body, err := encodePayload(w, payload)
+		if err != nil {
+			logger.Error("error encoding webhook payload", "webhook", w.ID, "err", err)
+			continue
+		}
+
+		id, err := uuid.NewUUID()
+		if err != nil {
+			return err
+		}
 
-			resBody = string(b)
+		a := deliver(ctx, w, models.WebhookDelivery{
+			ID:            id,
+			Event:         int(payload.Event()),
+			RequestURL:    w.URL,
+			RequestMethod: http.MethodPost,
+			RequestBody:   body,
+		})
+		if !a.ok() {
+			logger.Info("webhook delivery failed", "webhook", w.ID, "status", a.responseStatus, "err", a.err)
 		}
 	}
 
-	return db.WrapError(datastore.CreateWebhookDelivery(ctx, dbx, id, w.ID, int(event), w.URL, http.MethodPost, reqErr, reqHeaders, reqBody, resStatus, resHeaders, resBody))
+	return nil
 }
 
-// SendEvent sends a webhook event.
-func SendEvent(ctx context.Context, payload EventPayload) error {
+// subscribedWebhooks returns the webhooks subscribed to the event of a
+// payload, or to its narrower event. The server webhooks are subscribed to
+// the repository events of every repository.
+func subscribedWebhooks(ctx context.Context, payload EventPayload) ([]models.Webhook, error) {
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
-	webhooks, err := datastore.GetWebhooksByRepoIDWhereEvent(ctx, dbx, payload.RepositoryID(), []int{int(payload.Event())})
+	events := []int{int(payload.Event())}
+	if e, ok := narrowEvent(payload); ok {
+		events = append(events, int(e))
+	}
+
+	webhooks, err := datastore.GetWebhooksByRepoIDWhereEvent(ctx, dbx, payload.RepositoryID(), events)
 	if err != nil {
-		return db.WrapError(err)
+		return nil, db.WrapError(err)
 	}
 
-	for _, w := range webhooks {
-		if err := SendWebhook(ctx, w, payload.Event(), payload); err != nil {
-			return err
+	switch payload.(type) {
+	case RepositoryEvent, LFSEvent:
+		server, err := datastore.GetWebhooksByRepoIDWhereEvent(ctx, dbx, 0, events)
+		if err != nil {
+			return nil, db.WrapError(err)
 		}
+		webhooks = append(webhooks, server...)
 	}
 
-	return nil
+	return webhooks, nil
 }
 
 func repoURL(publicURL string, repo string) string {
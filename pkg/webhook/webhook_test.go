@@ -0,0 +1,34 @@
+package webhook
+
+import "testing"
+
+func TestSignature(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		payload string
+		want    string
+	}{
+		{
+			// Test vector from the GitHub webhook documentation.
+			name:    "GitHub",
+			secret:  "It's a Secret to Everybody",
+			payload: "Hello, World!",
+			want:    "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+		},
+		{
+			name:    "JSON",
+			secret:  "secret",
+			payload: "{\"event\":\"push\"}\n",
+			want:    "sha256=1e5b1c72f22cb7dfc29be2305039b634b24f3ae91eea470a2aaa99ff6be607b2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Signature(tt.secret, []byte(tt.payload)); got != tt.want {
+				t.Errorf("Signature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package testscript
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// screen is a minimal terminal emulator rendering the output of the UI, so
+// scripts can wait for text to show up on the screen rather than match the
+// escape sequences the renderer happens to write. It only knows about the
+// cursor movements and erasures the UI uses; colors and modes are ignored.
+type screen struct {
+	mu     sync.Mutex
+	out    []byte
+	width  int
+	height int
+}
+
+// newScreen returns a screen of the given size.
+func newScreen(width, height int) *screen {
+	return &screen{width: width, height: height}
+}
+
+// Write implements io.Writer.
+func (s *screen) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out = append(s.out, p...)
+	return len(p), nil
+}
+
+// String returns the lines of the screen as they'd be displayed, without
+// trailing spaces.
+func (s *screen) String() string {
+	s.mu.Lock()
+	out := string(s.out)
+	s.mu.Unlock()
+
+	t := newTerm(s.width, s.height)
+	t.write(out)
+	return t.String()
+}
+
+// term is the state of the emulated terminal.
+type term struct {
+	cells          [][]rune
+	width, height  int
+	x, y           int
+	savedX, savedY int
+	top, bottom    int
+	last           rune
+}
+
+func newTerm(width, height int) *term {
+	t := &term{width: width, height: height, bottom: height - 1}
+	t.cells = make([][]rune, height)
+	for i := range t.cells {
+		t.cells[i] = t.blankLine()
+	}
+	return t
+}
+
+func (t *term) blankLine() []rune {
+	return []rune(strings.Repeat(" ", t.width))
+}
+
+// String implements fmt.Stringer.
+func (t *term) String() string {
+	lines := make([]string, len(t.cells))
+	for i, l := range t.cells {
+		lines[i] = strings.TrimRight(string(l), " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func (t *term) write(s string) {
+	for len(s) > 0 {
+		switch c := s[0]; {
+		case c == ansi.ESC:
+			s = t.escape(s[1:])
+		case c == '\r':
+			t.x = 0
+			s = s[1:]
+		case c == '\n':
+			t.lineFeed()
+			s = s[1:]
+		case c == '\b':
+			t.x = max(t.x-1, 0)
+			s = s[1:]
+		case c == '\t':
+			t.x = min((t.x/8+1)*8, t.width-1)
+			s = s[1:]
+		case c < ' ' || c == 0x7f:
+			s = s[1:]
+		default:
+			r, n := utf8.DecodeRuneInString(s)
+			t.print(r)
+			s = s[n:]
+		}
+	}
+}
+
+func (t *term) print(r rune) {
+	w := ansi.StringWidth(string(r))
+	if w == 0 {
+		return
+	}
+	if t.x+w > t.width {
+		t.x = 0
+		t.lineFeed()
+	}
+	t.cells[t.y][t.x] = r
+	for i := 1; i < w; i++ {
+		t.cells[t.y][t.x+i] = ' '
+	}
+	// The cursor stays past the last column until the next character wraps.
+	t.x += w
+	t.last = r
+}
+
+func (t *term) lineFeed() {
+	if t.y == t.bottom {
+		t.scrollUp(1)
+		return
+	}
+	t.y = min(t.y+1, t.height-1)
+}
+
+func (t *term) scrollUp(n int) {
+	for range n {
+		copy(t.cells[t.top:t.bottom], t.cells[t.top+1:t.bottom+1])
+		t.cells[t.bottom] = t.blankLine()
+	}
+}
+
+func (t *term) scrollDown(n int) {
+	for range n {
+		copy(t.cells[t.top+1:t.bottom+1], t.cells[t.top:t.bottom])
+		t.cells[t.top] = t.blankLine()
+	}
+}
+
+// escape handles the escape sequence starting after ESC in s and returns
+// the rest of s.
+func (t *term) escape(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	switch s[0] {
+	case '[':
+		return t.csi(s[1:])
+	case ']', 'P', '_', '^', 'X':
+		// Strings end with BEL or ST.
+		for i := 1; i < len(s); i++ {
+			if s[i] == ansi.BEL {
+				return s[i+1:]
+			}
+			if s[i] == ansi.ESC && i+1 < len(s) && s[i+1] == '\\' {
+				return s[i+2:]
+			}
+		}
+		return ""
+	case '7':
+		t.savedX, t.savedY = t.x, t.y
+	case '8':
+		t.x, t.y = t.savedX, t.savedY
+	case 'M':
+		if t.y == t.top {
+			t.scrollDown(1)
+		} else {
+			t.y = max(t.y-1, 0)
+		}
+	case 'D':
+		t.lineFeed()
+	case 'E':
+		t.x = 0
+		t.lineFeed()
+	case '(', ')', '*', '+', '#', ' ':
+		if len(s) > 1 {
+			return s[2:]
+		}
+		return ""
+	}
+	return s[1:]
+}
+
+// csi handles the control sequence starting after CSI in s and returns the
+// rest of s.
+func (t *term) csi(s string) string {
+	i := 0
+	for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+		i++
+	}
+	if i == len(s) {
+		return ""
+	}
+	params, final, rest := s[:i], s[i], s[i+1:]
+
+	// Private modes and sequences with intermediates don't move the cursor
+	// or change the text, but the alternate screen starts blank.
+	if strings.IndexAny(params, "?<=>$ \"'") >= 0 {
+		if params == "?1049" && final == 'h' {
+			for i := range t.cells {
+				t.cells[i] = t.blankLine()
+			}
+			t.x, t.y = 0, 0
+		}
+		return rest
+	}
+
+	var args []int
+	for _, p := range strings.Split(params, ";") {
+		p, _, _ = strings.Cut(p, ":")
+		n, _ := strconv.Atoi(p)
+		args = append(args, n)
+	}
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	switch final {
+	case 'A':
+		t.y = max(t.y-arg(0, 1), 0)
+	case 'B', 'e':
+		t.y = min(t.y+arg(0, 1), t.height-1)
+	case 'C', 'a':
+		t.x = min(t.x+arg(0, 1), t.width-1)
+	case 'D':
+		t.x = max(t.x-arg(0, 1), 0)
+	case 'E':
+		t.x, t.y = 0, min(t.y+arg(0, 1), t.height-1)
+	case 'F':
+		t.x, t.y = 0, max(t.y-arg(0, 1), 0)
+	case 'G', '`':
+		t.x = min(arg(0, 1)-1, t.width-1)
+	case 'd':
+		t.y = min(arg(0, 1)-1, t.height-1)
+	case 'H', 'f':
+		t.y = min(arg(0, 1)-1, t.height-1)
+		t.x = min(arg(1, 1)-1, t.width-1)
+	case 'J':
+		switch arg(0, 0) {
+		case 0:
+			t.eraseLine(t.y, t.x, t.width)
+			for y := t.y + 1; y < t.height; y++ {
+				t.cells[y] = t.blankLine()
+			}
+		case 1:
+			t.eraseLine(t.y, 0, t.x+1)
+			for y := 0; y < t.y; y++ {
+				t.cells[y] = t.blankLine()
+			}
+		default:
+			for y := range t.cells {
+				t.cells[y] = t.blankLine()
+			}
+		}
+	case 'K':
+		switch arg(0, 0) {
+		case 0:
+			t.eraseLine(t.y, t.x, t.width)
+		case 1:
+			t.eraseLine(t.y, 0, t.x+1)
+		default:
+			t.eraseLine(t.y, 0, t.width)
+		}
+	case 'X':
+		t.eraseLine(t.y, t.x, t.x+arg(0, 1))
+	case 'P':
+		n := min(arg(0, 1), t.width-t.x)
+		line := t.cells[t.y]
+		copy(line[t.x:], line[t.x+n:])
+		t.eraseLine(t.y, t.width-n, t.width)
+	case '@':
+		n := min(arg(0, 1), t.width-t.x)
+		line := t.cells[t.y]
+		copy(line[t.x+n:], line[t.x:])
+		t.eraseLine(t.y, t.x, t.x+n)
+	case 'L':
+		if t.y >= t.top && t.y <= t.bottom {
+			top := t.top
+			t.top = t.y
+			t.scrollDown(arg(0, 1))
+			t.top = top
+		}
+	case 'M':
+		if t.y >= t.top && t.y <= t.bottom {
+			top := t.top
+			t.top = t.y
+			t.scrollUp(arg(0, 1))
+			t.top = top
+		}
+	case 'S':
+		t.scrollUp(arg(0, 1))
+	case 'T':
+		t.scrollDown(arg(0, 1))
+	case 'b':
+		for range arg(0, 1) {
+			t.print(t.last)
+		}
+	case 'r':
+		t.top = arg(0, 1) - 1
+		t.bottom = min(arg(1, t.height), t.height) - 1
+		t.x, t.y = 0, 0
+	case 's':
+		t.savedX, t.savedY = t.x, t.y
+	case 'u':
+		t.x, t.y = t.savedX, t.savedY
+	}
+
+	return rest
+}
+
+func (t *term) eraseLine(y, from, to int) {
+	to = min(to, t.width)
+	for x := max(from, 0); x < to; x++ {
+		t.cells[y][x] = ' '
+	}
+}
@@ -3,9 +3,15 @@ package testscript
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
 	"io"
 	"math/rand"
 	"net"
@@ -14,7 +20,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -100,6 +108,7 @@ func TestScript(t *testing.T) {
 			"agit":                   cmdGit(attackerKey),
 			"curl":                   cmdCurl,
 			"mkfile":                 cmdMkfile,
+			"mkimage":                cmdMkimage,
 			"envfile":                cmdEnvfile,
 			"readfile":               cmdReadfile,
 			"dos2unix":               cmdDos2Unix,
@@ -107,6 +116,7 @@ func TestScript(t *testing.T) {
 			"ensureserverrunning":    cmdEnsureServerRunning,
 			"ensureservernotrunning": cmdEnsureServerNotRunning,
 			"stopserver":             cmdStopserver,
+			"waitfor":                cmdWaitfor("admin", admin1.Signer()),
 			"ui":                     cmdUI(admin1.Signer()),
 			"uui":                    cmdUI(user1.Signer()),
 		},
@@ -198,36 +208,169 @@ func TestScript(t *testing.T) {
 
 func cmdSoft(user string, keys ...ssh.Signer) func(ts *testscript.TestScript, neg bool, args []string) {
 	return func(ts *testscript.TestScript, neg bool, args []string) {
-		cli, err := ssh.Dial(
-			"tcp",
-			net.JoinHostPort("localhost", ts.Getenv("SSH_PORT")),
-			&ssh.ClientConfig{
-				User:            user,
-				Auth:            []ssh.AuthMethod{ssh.PublicKeys(keys...)},
-				HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-			},
-		)
-		ts.Check(err)
-		defer cli.Close()
+		// Dropped connections are expected by negated commands.
+		check(ts, runSoft(ts, user, keys, args, ts.Stdout(), ts.Stderr()), neg)
+	}
+}
 
-		sess, err := cli.NewSession()
-		ts.Check(err)
-		defer sess.Close()
+// runSoft runs a soft command over SSH as user, writing its output to stdout
+// and stderr.
+func runSoft(ts *testscript.TestScript, user string, keys []ssh.Signer, args []string, stdout, stderr io.Writer) error {
+	cli, err := ssh.Dial(
+		"tcp",
+		net.JoinHostPort("localhost", ts.Getenv("SSH_PORT")),
+		&ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(keys...)},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		},
+	)
+	if err != nil {
+		ts.Logf("dial: %v", err)
+		return err
+	}
+	defer cli.Close()
 
-		sess.Stdout = ts.Stdout()
-		sess.Stderr = ts.Stderr()
+	sess, err := cli.NewSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	sess.Stdout = stdout
+	sess.Stderr = stderr
 
-		check(ts, sess.Run(strings.Join(args, " ")), neg)
+	// A trailing "< FILE" sends the file as the command input.
+	if n := len(args); n > 2 && args[n-2] == "<" {
+		sess.Stdin = strings.NewReader(ts.ReadFile(args[n-1]))
+		args = args[:n-2]
 	}
+
+	return sess.Run(strings.Join(args, " "))
 }
 
+// waitforTimeout is how long the waitfor command retries its command.
+const waitforTimeout = 30 * time.Second
+
+// cmdWaitfor runs a soft or exec command, or checks a file exists, until it
+// succeeds and its output matches the optional pattern, e.g.
+//
+//	waitfor stdout 'push' soft repo webhook deliver list repo1 1
+//	waitfor exists $DATA_PATH/repos/repo1.git/objects/info/commit-graph
+//
+// The output of the last run is kept for the following commands. It's meant
+// for waiting on the work the server does in the background.
+func cmdWaitfor(user string, keys ...ssh.Signer) func(ts *testscript.TestScript, neg bool, args []string) {
+	return func(ts *testscript.TestScript, neg bool, args []string) {
+		if neg {
+			ts.Fatalf("unsupported: ! waitfor")
+		}
+
+		var re *regexp.Regexp
+		var stream string
+		if len(args) > 2 && (args[0] == "stdout" || args[0] == "stderr") {
+			var err error
+			stream = args[0]
+			re, err = regexp.Compile("(?m)" + args[1])
+			ts.Check(err)
+			args = args[2:]
+		}
+		if len(args) < 2 {
+			ts.Fatalf("usage: waitfor [stdout|stderr pattern] soft|exec|exists args...")
+		}
+		if re != nil && args[0] == "exists" {
+			ts.Fatalf("waitfor exists has no output to match")
+		}
+
+		var stdout, stderr strings.Builder
+		run := func() error {
+			switch args[0] {
+			case "soft":
+				stdout.Reset()
+				stderr.Reset()
+				return runSoft(ts, user, keys, args[1:], &stdout, &stderr)
+			case "exec":
+				err := ts.Exec(args[1], args[2:]...)
+				stdout.Reset()
+				stderr.Reset()
+				stdout.WriteString(ts.ReadFile("stdout"))
+				stderr.WriteString(ts.ReadFile("stderr"))
+				return err
+			case "exists":
+				_, err := os.Stat(ts.MkAbs(args[1]))
+				return err
+			default:
+				return fmt.Errorf("unknown command %q", args[0])
+			}
+		}
+
+		deadline := time.Now().Add(waitforTimeout)
+		for {
+			err := run()
+			if err == nil && re != nil {
+				out := stdout.String()
+				if stream == "stderr" {
+					out = stderr.String()
+				}
+				if !re.MatchString(out) {
+					err = fmt.Errorf("no match for %q in %s", re, stream)
+				}
+			}
+			if err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				ts.Fatalf("timed out waiting for %s: %v", strings.Join(args, " "), err)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		if args[0] == "soft" {
+			ts.Stdout().Write([]byte(stdout.String())) //nolint: errcheck
+			ts.Stderr().Write([]byte(stderr.String())) //nolint: errcheck
+		}
+	}
+}
+
+// uiWaitTimeout is how long the ui command waits for a pattern to show up on
+// the screen.
+const uiWaitTimeout = 30 * time.Second
+
+// cmdUI runs the UI, typing the quoted inputs in its arguments. The other
+// arguments are regular expressions the screen must match before the next
+// input is typed, or must not match once they're reached when they start with
+// "!", e.g.
+//
+//	ui '"\r"' 'repo1' '!error' '"q"'
 func cmdUI(key ssh.Signer) func(ts *testscript.TestScript, neg bool, args []string) {
 	return func(ts *testscript.TestScript, neg bool, args []string) {
 		if len(args) < 1 {
-			ts.Fatalf("usage: ui <quoted string input>")
+			ts.Fatalf("usage: ui [<quoted string input> | pattern]...")
 			return
 		}
 
+		type pattern struct {
+			*regexp.Regexp
+			not bool
+		}
+		steps := make([]any, len(args))
+		for i, arg := range args {
+			if strings.HasPrefix(arg, `"`) {
+				in, err := strconv.Unquote(arg)
+				if err != nil {
+					ts.Fatalf("invalid input %s: %v", arg, err)
+				}
+				steps[i] = in
+				continue
+			}
+			expr, not := strings.CutPrefix(arg, "!")
+			re, err := regexp.Compile("(?m)" + expr)
+			if err != nil {
+				ts.Fatalf("invalid pattern %q: %v", arg, err)
+			}
+			steps[i] = pattern{re, not}
+		}
+
 		cli, err := ssh.Dial(
 			"tcp",
 			net.JoinHostPort("localhost", ts.Getenv("SSH_PORT")),
@@ -249,7 +392,8 @@ func cmdUI(key ssh.Signer) func(ts *testscript.TestScript, neg bool, args []stri
 		// in the output
 		defer ts.Stdout().Write([]byte("\n"))
 
-		sess.Stdout = ts.Stdout()
+		scr := newScreen(80, 40)
+		sess.Stdout = io.MultiWriter(ts.Stdout(), scr)
 		sess.Stderr = ts.Stderr()
 
 		stdin, err := sess.StdinPipe()
@@ -259,25 +403,46 @@ func cmdUI(key ssh.Signer) func(ts *testscript.TestScript, neg bool, args []stri
 		check(ts, err, neg)
 		check(ts, sess.Start(""), neg)
 
-		in, err := strconv.Unquote(args[0])
-		check(ts, err, neg)
-		reader := strings.NewReader(in)
+		typed := make(chan error, 1)
 		go func() {
 			defer stdin.Close()
-			for {
-				r, _, err := reader.ReadRune()
-				if err == io.EOF {
-					break
+			for _, step := range steps {
+				switch step := step.(type) {
+				case string:
+					for _, r := range step {
+						_, _ = io.WriteString(stdin, string(r))
+
+						// Wait for the UI to process the input
+						time.Sleep(100 * time.Millisecond)
+					}
+				case pattern:
+					if step.not {
+						if s := scr.String(); step.MatchString(s) {
+							typed <- fmt.Errorf("unexpected %q, the screen shows:\n%s", step, s)
+							_ = sess.Close()
+							return
+						}
+						continue
+					}
+					deadline := time.Now().Add(uiWaitTimeout)
+					for !step.MatchString(scr.String()) {
+						if time.Now().After(deadline) {
+							typed <- fmt.Errorf("timed out waiting for %q, the screen shows:\n%s", step, scr)
+							_ = sess.Close()
+							return
+						}
+						time.Sleep(50 * time.Millisecond)
+					}
 				}
-				check(ts, err, neg)
-				_, _ = io.WriteString(stdin, string(r))
-
-				// Wait for the UI to process the input
-				time.Sleep(100 * time.Millisecond)
 			}
+			typed <- nil
 		}()
 
-		check(ts, sess.Wait(), neg)
+		err = sess.Wait()
+		if werr := <-typed; werr != nil {
+			err = werr
+		}
+		check(ts, err, neg)
 	}
 }
 
@@ -349,6 +514,42 @@ func cmdMkfile(ts *testscript.TestScript, neg bool, args []string) {
 	), neg)
 }
 
+// cmdMkimage writes a PNG image with text metadata, or a JPEG image.
+func cmdMkimage(ts *testscript.TestScript, neg bool, args []string) {
+	if len(args) != 3 {
+		ts.Fatalf("usage: mkimage path width height")
+	}
+	width, err := strconv.Atoi(args[1])
+	ts.Check(err)
+	height, err := strconv.Atoi(args[2])
+	ts.Check(err)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		for y := range height {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+		}
+	}
+
+	var buf bytes.Buffer
+	if filepath.Ext(args[0]) == ".jpg" {
+		ts.Check(jpeg.Encode(&buf, img, nil))
+		check(ts, os.WriteFile(ts.MkAbs(args[0]), buf.Bytes(), 0o644), neg)
+		return
+	}
+
+	// Insert a tEXt chunk after the IHDR one, which ends at byte 33.
+	ts.Check(png.Encode(&buf, img))
+	text := []byte("Comment\x00secret metadata")
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(text)))
+	chunk = append(chunk, "tEXt"...)
+	chunk = append(chunk, text...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	b := slices.Concat(buf.Bytes()[:33], chunk, buf.Bytes()[33:])
+
+	check(ts, os.WriteFile(ts.MkAbs(args[0]), b, 0o644), neg)
+}
+
 func check(ts *testscript.TestScript, err error, neg bool) {
 	if neg && err == nil {
 		ts.Fatalf("expected error, got nil")
@@ -421,8 +622,17 @@ func cmdCurl(ts *testscript.TestScript, neg bool, args []string) {
 				return err
 			}
 
+			// Like curl, @file sends the contents of file.
+			if name, ok := strings.CutPrefix(data, "@"); ok {
+				b, err := os.ReadFile(ts.MkAbs(name))
+				if err != nil {
+					return err
+				}
+				data = string(b)
+			}
 			if data != "" {
 				req.Body = io.NopCloser(strings.NewReader(data))
+				req.ContentLength = int64(len(data))
 			}
 
 			if verbose {